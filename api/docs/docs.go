@@ -17,7 +17,7 @@ const docTemplate = `{
     "paths": {
         "/barcode": {
             "post": {
-                "description": "barcode",
+                "description": "add a product to the sale basket by any of its barcodes",
                 "consumes": [
                     "application/json"
                 ],
@@ -67,9 +67,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/basket": {
+        "/barcode-format": {
             "post": {
-                "description": "create a new basket",
+                "description": "register an in-store barcode format of scales, e.g. prefix 22, product code at 2 with length 5, weight at 7 with length 5 and 3 decimals",
                 "consumes": [
                     "application/json"
                 ],
@@ -77,16 +77,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "basket"
+                    "barcode"
                 ],
-                "summary": "Create a new basket",
+                "summary": "Create a new barcode format",
                 "parameters": [
                     {
-                        "description": "basket",
-                        "name": "basket",
+                        "description": "barcode-format",
+                        "name": "barcode-format",
                         "in": "body",
+                        "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.CreateBasket"
+                            "$ref": "#/definitions/models.CreateBarcodeFormat"
                         }
                     }
                 ],
@@ -94,7 +95,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.Basket"
+                            "$ref": "#/definitions/models.BarcodeFormat"
                         }
                     },
                     "400": {
@@ -118,9 +119,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/basket/{id}": {
-            "get": {
-                "description": "get basket by id",
+        "/barcode-format/{id}": {
+            "delete": {
+                "description": "delete barcode format",
                 "consumes": [
                     "application/json"
                 ],
@@ -128,13 +129,13 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "basket"
+                    "barcode"
                 ],
-                "summary": "Get basket by id",
+                "summary": "Delete barcode format",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "basket_id",
+                        "description": "barcode_format_id",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -144,7 +145,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.Basket"
+                            "$ref": "#/definitions/models.Response"
                         }
                     },
                     "400": {
@@ -166,9 +167,11 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "put": {
-                "description": "get basket",
+            }
+        },
+        "/barcode-formats": {
+            "get": {
+                "description": "get in-store barcode formats",
                 "consumes": [
                     "application/json"
                 ],
@@ -176,31 +179,14 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "basket"
-                ],
-                "summary": "Update basket",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "basket_id",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "basket",
-                        "name": "basket",
-                        "in": "body",
-                        "schema": {
-                            "$ref": "#/definitions/models.UpdateBasket"
-                        }
-                    }
+                    "barcode"
                 ],
+                "summary": "Get barcode formats",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.Basket"
+                            "$ref": "#/definitions/models.BarcodeFormatsResponse"
                         }
                     },
                     "400": {
@@ -222,9 +208,11 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "delete basket",
+            }
+        },
+        "/barcode/{barcode}": {
+            "get": {
+                "description": "find a product by any of its barcodes",
                 "consumes": [
                     "application/json"
                 ],
@@ -232,23 +220,29 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "basket"
+                    "product"
                 ],
-                "summary": "Delete basket",
+                "summary": "Get product by barcode",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "basket_id",
-                        "name": "id",
+                        "description": "barcode",
+                        "name": "barcode",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "language",
+                        "name": "Accept-Language",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.Response"
+                            "$ref": "#/definitions/models.Product"
                         }
                     },
                     "400": {
@@ -272,36 +266,41 @@ const docTemplate = `{
                 }
             }
         },
-        "/baskets": {
+        "/barcode/{barcode}/image": {
             "get": {
-                "description": "get basket list",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "render a barcode as EAN-13, EAN-8, UPC-A or Code128 image, format is png (default) or svg",
                 "produces": [
-                    "application/json"
+                    "image/png",
+                    "image/svg+xml"
                 ],
                 "tags": [
-                    "basket"
+                    "label"
                 ],
-                "summary": "Get basket list",
+                "summary": "Barcode image",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "page",
-                        "name": "page",
+                        "description": "barcode",
+                        "name": "barcode",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "format",
+                        "name": "format",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "limit",
-                        "name": "limit",
+                        "description": "module width in pixels",
+                        "name": "scale",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "search",
-                        "name": "search",
+                        "description": "bar height in pixels",
+                        "name": "height",
                         "in": "query"
                     }
                 ],
@@ -309,7 +308,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.BasketsResponse"
+                            "type": "file"
                         }
                     },
                     "400": {
@@ -318,12 +317,6 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.Response"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
-                        "schema": {
-                            "$ref": "#/definitions/models.Response"
-                        }
-                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -333,9 +326,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/branch": {
+        "/basket": {
             "post": {
-                "description": "create a new branch",
+                "description": "create a new basket",
                 "consumes": [
                     "application/json"
                 ],
@@ -343,16 +336,16 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "branch"
+                    "basket"
                 ],
-                "summary": "Create a new branch",
+                "summary": "Create a new basket",
                 "parameters": [
                     {
-                        "description": "branch",
-                        "name": "branch",
+                        "description": "basket",
+                        "name": "basket",
                         "in": "body",
                         "schema": {
-                            "$ref": "#/definitions/models.CreateBranch"
+                            "$ref": "#/definitions/models.CreateBasket"
                         }
                     }
                 ],
@@ -360,7 +353,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.Branch"
+                            "$ref": "#/definitions/models.Basket"
                         }
                     },
                     "400": {
@@ -384,9 +377,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/branch/{id}": {
+        "/basket/{id}": {
             "get": {
-                "description": "get branch by id",
+                "description": "get basket by id",
                 "consumes": [
                     "application/json"
                 ],
@@ -394,13 +387,13 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "branch"
+                    "basket"
                 ],
-                "summary": "Get branch by id",
+                "summary": "Get basket by id",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "branch_id",
+                        "description": "basket_id",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -410,7 +403,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.Branch"
+                            "$ref": "#/definitions/models.Basket"
                         }
                     },
                     "400": {
@@ -434,7 +427,7 @@ const docTemplate = `{
                 }
             },
             "put": {
-                "description": "update branch",
+                "description": "get basket",
                 "consumes": [
                     "application/json"
                 ],
@@ -442,23 +435,23 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "branch"
+                    "basket"
                 ],
-                "summary": "Update branch",
+                "summary": "Update basket",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "branch_id",
+                        "description": "basket_id",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "branch",
-                        "name": "branch",
+                        "description": "basket",
+                        "name": "basket",
                         "in": "body",
                         "schema": {
-                            "$ref": "#/definitions/models.UpdateBranch"
+                            "$ref": "#/definitions/models.UpdateBasket"
                         }
                     }
                 ],
@@ -466,7 +459,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.Branch"
+                            "$ref": "#/definitions/models.Basket"
                         }
                     },
                     "400": {
@@ -490,7 +483,7 @@ const docTemplate = `{
                 }
             },
             "delete": {
-                "description": "delete branch",
+                "description": "delete basket",
                 "consumes": [
                     "application/json"
                 ],
@@ -498,13 +491,13 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "branch"
+                    "basket"
                 ],
-                "summary": "Delete branch",
+                "summary": "Delete basket",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "branch_id",
+                        "description": "basket_id",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -538,9 +531,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/branches": {
+        "/baskets": {
             "get": {
-                "description": "get branch list",
+                "description": "get basket list",
                 "consumes": [
                     "application/json"
                 ],
@@ -548,9 +541,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "branch"
+                    "basket"
                 ],
-                "summary": "Get branch list",
+                "summary": "Get basket list",
                 "parameters": [
                     {
                         "type": "string",
@@ -575,7 +568,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.BranchResponse"
+                            "$ref": "#/definitions/models.BasketsResponse"
                         }
                     },
                     "400": {
@@ -599,9 +592,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/categories": {
+        "/batches": {
             "get": {
-                "description": "get category list",
+                "description": "get batches with stock left ordered by expiry date",
                 "consumes": [
                     "application/json"
                 ],
@@ -609,9 +602,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "category"
+                    "batch"
                 ],
-                "summary": "Get category list",
+                "summary": "Get batch list",
                 "parameters": [
                     {
                         "type": "string",
@@ -627,8 +620,14 @@ const docTemplate = `{
                     },
                     {
                         "type": "string",
-                        "description": "search",
-                        "name": "search",
+                        "description": "branch_id",
+                        "name": "branch_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "product_id",
+                        "name": "product_id",
                         "in": "query"
                     }
                 ],
@@ -636,7 +635,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.CategoryResponse"
+                            "$ref": "#/definitions/models.BatchResponse"
                         }
                     },
                     "400": {
@@ -660,9 +659,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/category": {
-            "post": {
-                "description": "create a new category",
+        "/batches/expiring": {
+            "get": {
+                "description": "get batches of a branch expiring within the given number of days",
                 "consumes": [
                     "application/json"
                 ],
@@ -670,24 +669,28 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "category"
+                    "batch"
                 ],
-                "summary": "Create a new category",
+                "summary": "Get expiring batches",
                 "parameters": [
                     {
-                        "description": "category",
-                        "name": "category",
-                        "in": "body",
-                        "schema": {
-                            "$ref": "#/definitions/models.CreateCategory"
-                        }
+                        "type": "string",
+                        "description": "branch_id",
+                        "name": "branch_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "days",
+                        "name": "days",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.Category"
+                            "$ref": "#/definitions/models.ExpiringBatchesResponse"
                         }
                     },
                     "400": {
@@ -711,9 +714,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/category/{id}": {
-            "get": {
-                "description": "get category by id",
+        "/branch": {
+            "post": {
+                "description": "create a new branch",
                 "consumes": [
                     "application/json"
                 ],
@@ -721,23 +724,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "category"
+                    "branch"
                 ],
-                "summary": "Get category by id",
+                "summary": "Create a new branch",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "category_id",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "branch",
+                        "name": "branch",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateBranch"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.Category"
+                            "$ref": "#/definitions/models.Branch"
                         }
                     },
                     "400": {
@@ -759,9 +763,11 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "put": {
-                "description": "get category",
+            }
+        },
+        "/branch/{id}": {
+            "get": {
+                "description": "get branch by id",
                 "consumes": [
                     "application/json"
                 ],
@@ -769,31 +775,23 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "category"
+                    "branch"
                 ],
-                "summary": "Update category",
+                "summary": "Get branch by id",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "category_id",
+                        "description": "branch_id",
                         "name": "id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "description": "category",
-                        "name": "category",
-                        "in": "body",
-                        "schema": {
-                            "$ref": "#/definitions/models.UpdateCategory"
-                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.Category"
+                            "$ref": "#/definitions/models.Branch"
                         }
                     },
                     "400": {
@@ -816,8 +814,8 @@ const docTemplate = `{
                     }
                 }
             },
-            "delete": {
-                "description": "delete category",
+            "put": {
+                "description": "update branch",
                 "consumes": [
                     "application/json"
                 ],
@@ -825,23 +823,31 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "category"
+                    "branch"
                 ],
-                "summary": "Delete category",
+                "summary": "Update branch",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "category_id",
+                        "description": "branch_id",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "branch",
+                        "name": "branch",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdateBranch"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.Response"
+                            "$ref": "#/definitions/models.Branch"
                         }
                     },
                     "400": {
@@ -863,11 +869,9 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/end-sell/{id}": {
-            "put": {
-                "description": "end sell",
+            },
+            "delete": {
+                "description": "delete branch",
                 "consumes": [
                     "application/json"
                 ],
@@ -875,25 +879,16 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "sell"
+                    "branch"
                 ],
-                "summary": "end sell",
+                "summary": "Delete branch",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "sale_id",
+                        "description": "branch_id",
                         "name": "id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "description": "status",
-                        "name": "status",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.SaleRequest"
-                        }
                     }
                 ],
                 "responses": {
@@ -924,9 +919,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/income": {
-            "post": {
-                "description": "create a new income",
+        "/branches": {
+            "get": {
+                "description": "get branch list",
                 "consumes": [
                     "application/json"
                 ],
@@ -934,24 +929,34 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "income"
+                    "branch"
                 ],
-                "summary": "Create a new income",
+                "summary": "Get branch list",
                 "parameters": [
                     {
-                        "description": "income",
-                        "name": "income",
-                        "in": "body",
-                        "schema": {
-                            "$ref": "#/definitions/models.CreateIncome"
-                        }
+                        "type": "string",
+                        "description": "page",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "limit",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "search",
+                        "name": "search",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.Income"
+                            "$ref": "#/definitions/models.BranchResponse"
                         }
                     },
                     "400": {
@@ -975,9 +980,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/income-product": {
+        "/categories": {
             "get": {
-                "description": "get income product list",
+                "description": "get category list",
                 "consumes": [
                     "application/json"
                 ],
@@ -985,9 +990,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "income-product"
+                    "category"
                 ],
-                "summary": "Get income product list",
+                "summary": "Get category list",
                 "parameters": [
                     {
                         "type": "string",
@@ -1003,16 +1008,22 @@ const docTemplate = `{
                     },
                     {
                         "type": "string",
-                        "description": "branch_id",
-                        "name": "branch_id",
+                        "description": "search",
+                        "name": "search",
                         "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "language",
+                        "name": "Accept-Language",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.IncomeProductsResponse"
+                            "$ref": "#/definitions/models.CategoryResponse"
                         }
                     },
                     "400": {
@@ -1034,9 +1045,11 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
+            }
+        },
+        "/category": {
             "post": {
-                "description": "create a new income products",
+                "description": "create a new category",
                 "consumes": [
                     "application/json"
                 ],
@@ -1044,16 +1057,16 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "income-product"
+                    "category"
                 ],
-                "summary": "Create a new income products",
+                "summary": "Create a new category",
                 "parameters": [
                     {
-                        "description": "income-product",
-                        "name": "income-product",
+                        "description": "category",
+                        "name": "category",
                         "in": "body",
                         "schema": {
-                            "$ref": "#/definitions/models.CreateIncomeProduct"
+                            "$ref": "#/definitions/models.CreateCategory"
                         }
                     }
                 ],
@@ -1061,7 +1074,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.IncomeProduct"
+                            "$ref": "#/definitions/models.Category"
                         }
                     },
                     "400": {
@@ -1085,9 +1098,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/income-product/{id}": {
+        "/category/tree": {
             "get": {
-                "description": "get income product",
+                "description": "get all root categories with their subcategories, product_count counts products of the category itself\nand total_product_count includes the products of all subcategories",
                 "consumes": [
                     "application/json"
                 ],
@@ -1095,23 +1108,25 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "income-product"
+                    "category"
                 ],
-                "summary": "Get income product",
+                "summary": "Get category tree",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "id",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "language",
+                        "name": "Accept-Language",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.IncomeProduct"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.CategoryTree"
+                            }
                         }
                     },
                     "400": {
@@ -1133,9 +1148,11 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "put": {
-                "description": "update income product",
+            }
+        },
+        "/category/{id}": {
+            "get": {
+                "description": "get category by id",
                 "consumes": [
                     "application/json"
                 ],
@@ -1143,31 +1160,29 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "income-product"
+                    "category"
                 ],
-                "summary": "Update income product",
+                "summary": "Get category by id",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "id",
+                        "description": "category_id",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "income-product",
-                        "name": "income-product",
-                        "in": "body",
-                        "schema": {
-                            "$ref": "#/definitions/models.UpdateIncomeProduct"
-                        }
+                        "type": "string",
+                        "description": "language",
+                        "name": "Accept-Language",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.IncomeProduct"
+                            "$ref": "#/definitions/models.Category"
                         }
                     },
                     "400": {
@@ -1190,8 +1205,8 @@ const docTemplate = `{
                     }
                 }
             },
-            "delete": {
-                "description": "delete income product",
+            "put": {
+                "description": "get category",
                 "consumes": [
                     "application/json"
                 ],
@@ -1199,23 +1214,31 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "income-product"
+                    "category"
                 ],
-                "summary": "Delete income product",
+                "summary": "Update category",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "id",
+                        "description": "category_id",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "category",
+                        "name": "category",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdateCategory"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.Response"
+                            "$ref": "#/definitions/models.Category"
                         }
                     },
                     "400": {
@@ -1237,11 +1260,9 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/income/{id}": {
-            "get": {
-                "description": "get income",
+            },
+            "delete": {
+                "description": "delete category. mode refuse (default) keeps a category which has subcategories or products,\ncascade deletes the subtree with all its products, reparent moves subcategories and products to the parent category",
                 "consumes": [
                     "application/json"
                 ],
@@ -1249,23 +1270,29 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "income"
+                    "category"
                 ],
-                "summary": "Get income",
+                "summary": "Delete category",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "id",
+                        "description": "category_id",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "refuse, cascade or reparent",
+                        "name": "mode",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.Income"
+                            "$ref": "#/definitions/models.Response"
                         }
                     },
                     "400": {
@@ -1287,9 +1314,11 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
+            }
+        },
+        "/category/{id}/move": {
             "put": {
-                "description": "update income",
+                "description": "move the category with all its subcategories under parent_id, an empty parent_id makes it a root category",
                 "consumes": [
                     "application/json"
                 ],
@@ -1297,23 +1326,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "income"
+                    "category"
                 ],
-                "summary": "Update income",
+                "summary": "Move category",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "id",
+                        "description": "category_id",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "income",
-                        "name": "income",
+                        "description": "parent",
+                        "name": "category",
                         "in": "body",
+                        "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.UpdateIncome"
+                            "$ref": "#/definitions/models.MoveCategory"
                         }
                     }
                 ],
@@ -1321,7 +1351,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.Income"
+                            "$ref": "#/definitions/models.CategoryTree"
                         }
                     },
                     "400": {
@@ -1343,9 +1373,11 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "delete income",
+            }
+        },
+        "/category/{id}/products": {
+            "get": {
+                "description": "get products of the category and of all its subcategories",
                 "consumes": [
                     "application/json"
                 ],
@@ -1353,23 +1385,47 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "income"
+                    "category"
                 ],
-                "summary": "Delete income",
+                "summary": "Get category products",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "income_id",
+                        "description": "category_id",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "page",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "limit",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "search",
+                        "name": "search",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "language",
+                        "name": "Accept-Language",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.Response"
+                            "$ref": "#/definitions/models.ProductResponse"
                         }
                     },
                     "400": {
@@ -1393,9 +1449,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/incomes": {
-            "get": {
-                "description": "get income list",
+        "/category/{id}/tax-rate": {
+            "put": {
+                "description": "tax products of the category and of its subcategories without a rate of their own at tax_rate_id,\nan empty tax_rate_id removes the rate of the category",
                 "consumes": [
                     "application/json"
                 ],
@@ -1403,34 +1459,32 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "income"
+                    "tax"
                 ],
-                "summary": "Get income list",
+                "summary": "Set category tax rate",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "page",
-                        "name": "page",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "limit",
-                        "name": "limit",
-                        "in": "query"
+                        "description": "category_id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     },
                     {
-                        "type": "string",
-                        "description": "branch_id",
-                        "name": "branch_id",
-                        "in": "query"
+                        "description": "tax-rate",
+                        "name": "tax-rate",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.SetTaxRate"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.IncomeResponse"
+                            "$ref": "#/definitions/models.Response"
                         }
                     },
                     "400": {
@@ -1454,9 +1508,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/product": {
-            "post": {
-                "description": "create a new product",
+        "/category/{id}/tree": {
+            "get": {
+                "description": "get the category with all its subcategories and product counts",
                 "consumes": [
                     "application/json"
                 ],
@@ -1464,24 +1518,29 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "product"
+                    "category"
                 ],
-                "summary": "Create a new product",
+                "summary": "Get category subtree",
                 "parameters": [
                     {
-                        "description": "sale",
-                        "name": "product",
-                        "in": "body",
-                        "schema": {
-                            "$ref": "#/definitions/models.CreateProduct"
-                        }
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "OK",
+                        "type": "string",
+                        "description": "category_id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "language",
+                        "name": "Accept-Language",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.Product"
+                            "$ref": "#/definitions/models.CategoryTree"
                         }
                     },
                     "400": {
@@ -1505,9 +1564,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/product/{id}": {
-            "get": {
-                "description": "get product by id",
+        "/end-sell/{id}": {
+            "put": {
+                "description": "end a sale in progress with status success or cancel. On success the tax of every line is calculated at the rates of now and kept with the sale,\nthe price of the sale is the total to pay with the tax added when prices do not include it.\nA successful sale is registered with the fiscal module, its fiscal sign is empty while the module is down",
                 "consumes": [
                     "application/json"
                 ],
@@ -1515,23 +1574,32 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "product"
+                    "sell"
                 ],
-                "summary": "Get product by id",
+                "summary": "end sell",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "product_id",
+                        "description": "sale_id",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "status",
+                        "name": "status",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.SaleRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.Product"
+                            "$ref": "#/definitions/models.Response"
                         }
                     },
                     "400": {
@@ -1553,9 +1621,11 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "put": {
-                "description": "update",
+            }
+        },
+        "/exchange-rate": {
+            "post": {
+                "description": "set how many units of the base currency one unit of currency costs. The rate starts at starts_at\n(RFC3339, in the future) or now when starts_at is empty, earlier rates are kept as history",
                 "consumes": [
                     "application/json"
                 ],
@@ -1563,31 +1633,25 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "product"
+                    "exchange rate"
                 ],
-                "summary": "Update product",
+                "summary": "Schedule exchange rate",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "product_id",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "product",
-                        "name": "product",
+                        "description": "rate",
+                        "name": "rate",
                         "in": "body",
+                        "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.UpdateProduct"
+                            "$ref": "#/definitions/models.CreateExchangeRate"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/models.Product"
+                            "$ref": "#/definitions/models.ExchangeRatesResponse"
                         }
                     },
                     "400": {
@@ -1609,9 +1673,11 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "delete product",
+            }
+        },
+        "/exchange-rate/{currency}": {
+            "get": {
+                "description": "get the rate of the currency at the moment at (RFC3339, now by default) and amount converted\nto the base currency at that rate, amounts are rounded to the hundredth half away from zero",
                 "consumes": [
                     "application/json"
                 ],
@@ -1619,23 +1685,35 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "product"
+                    "exchange rate"
                 ],
-                "summary": "Delete product",
+                "summary": "Convert to the base currency",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "product_id",
-                        "name": "id",
+                        "description": "currency",
+                        "name": "currency",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "at",
+                        "name": "at",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "amount",
+                        "name": "amount",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.Product"
+                            "$ref": "#/definitions/models.Conversion"
                         }
                     },
                     "400": {
@@ -1659,9 +1737,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/products": {
-            "get": {
-                "description": "get product list",
+        "/exchange-rate/{id}": {
+            "delete": {
+                "description": "cancel a rate which has not started yet, started rates stay in the history",
                 "consumes": [
                     "application/json"
                 ],
@@ -1669,40 +1747,23 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "product"
+                    "exchange rate"
                 ],
-                "summary": "Get product list",
+                "summary": "Cancel scheduled exchange rate",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "page",
-                        "name": "page",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "limit",
-                        "name": "limit",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "name",
-                        "name": "name",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "barcode",
-                        "name": "barcode",
-                        "in": "query"
+                        "description": "exchange_rate_id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.ProductResponse"
+                            "$ref": "#/definitions/models.Response"
                         }
                     },
                     "400": {
@@ -1726,9 +1787,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/repositories": {
+        "/exchange-rates": {
             "get": {
-                "description": "get repository list",
+                "description": "get started and scheduled rates, latest first, of all currencies or of currency only",
                 "consumes": [
                     "application/json"
                 ],
@@ -1736,9 +1797,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "repository"
+                    "exchange rate"
                 ],
-                "summary": "Get repository list",
+                "summary": "Get exchange rate history",
                 "parameters": [
                     {
                         "type": "string",
@@ -1754,8 +1815,8 @@ const docTemplate = `{
                     },
                     {
                         "type": "string",
-                        "description": "search",
-                        "name": "search",
+                        "description": "currency",
+                        "name": "currency",
                         "in": "query"
                     }
                 ],
@@ -1763,7 +1824,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.RepositoriesResponse"
+                            "$ref": "#/definitions/models.ExchangeRatesResponse"
                         }
                     },
                     "400": {
@@ -1787,9 +1848,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/repository": {
-            "post": {
-                "description": "create a new repository",
+        "/fiscal-outbox": {
+            "get": {
+                "description": "get sales and refunds waiting to be registered with the fiscal module (pending), registered (sent)\nor given up after FISCAL_MAX_ATTEMPTS attempts (failed), latest first",
                 "consumes": [
                     "application/json"
                 ],
@@ -1797,24 +1858,34 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "repository"
+                    "fiscal"
                 ],
-                "summary": "Create a new repository",
+                "summary": "Get fiscal outbox",
                 "parameters": [
                     {
-                        "description": "repository",
-                        "name": "repository",
-                        "in": "body",
-                        "schema": {
-                            "$ref": "#/definitions/models.CreateRepository"
-                        }
+                        "type": "string",
+                        "description": "page",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "limit",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "pending, sent or failed",
+                        "name": "status",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.Repository"
+                            "$ref": "#/definitions/models.FiscalEntriesResponse"
                         }
                     },
                     "400": {
@@ -1838,9 +1909,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/repository/{id}": {
-            "get": {
-                "description": "get repository by id",
+        "/fiscal-outbox/{id}/retry": {
+            "put": {
+                "description": "send a failed or waiting entry of the fiscal outbox again now with all its attempts,\nregistered entries can not be sent again",
                 "consumes": [
                     "application/json"
                 ],
@@ -1848,13 +1919,13 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "repository"
+                    "fiscal"
                 ],
-                "summary": "Get repository by id",
+                "summary": "Retry fiscal registration",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "repository_id",
+                        "description": "fiscal_outbox_id",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -1864,7 +1935,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.Repository"
+                            "$ref": "#/definitions/models.Response"
                         }
                     },
                     "400": {
@@ -1886,9 +1957,11 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "put": {
-                "description": "get repository",
+            }
+        },
+        "/income": {
+            "post": {
+                "description": "create a new income in currency (the base currency when empty) at its exchange rate of now",
                 "consumes": [
                     "application/json"
                 ],
@@ -1896,23 +1969,16 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "repository"
+                    "income"
                 ],
-                "summary": "Update repository",
+                "summary": "Create a new income",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "repository_id",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "repository",
-                        "name": "repository",
+                        "description": "income",
+                        "name": "income",
                         "in": "body",
                         "schema": {
-                            "$ref": "#/definitions/models.UpdateRepository"
+                            "$ref": "#/definitions/models.CreateIncome"
                         }
                     }
                 ],
@@ -1920,7 +1986,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.Repository"
+                            "$ref": "#/definitions/models.Income"
                         }
                     },
                     "400": {
@@ -1942,9 +2008,11 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "delete repository",
+            }
+        },
+        "/income-product": {
+            "get": {
+                "description": "get income product list",
                 "consumes": [
                     "application/json"
                 ],
@@ -1952,23 +2020,34 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "repository"
+                    "income-product"
                 ],
-                "summary": "Delete repository",
+                "summary": "Get income product list",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "repository_id",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "page",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "limit",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "branch_id",
+                        "name": "branch_id",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.Response"
+                            "$ref": "#/definitions/models.IncomeProductsResponse"
                         }
                     },
                     "400": {
@@ -1990,11 +2069,9 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/rtransaction": {
+            },
             "post": {
-                "description": "create a new rtransaction",
+                "description": "add a line to an income in process, stock is added when the income is finished",
                 "consumes": [
                     "application/json"
                 ],
@@ -2002,16 +2079,16 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "rtransaction"
+                    "income-product"
                 ],
-                "summary": "Create a new rtransaction",
+                "summary": "Create a new income products",
                 "parameters": [
                     {
-                        "description": "rtransaction",
-                        "name": "rtransaction",
+                        "description": "income-product",
+                        "name": "income-product",
                         "in": "body",
                         "schema": {
-                            "$ref": "#/definitions/models.CreateRepositoryTransaction"
+                            "$ref": "#/definitions/models.CreateIncomeProduct"
                         }
                     }
                 ],
@@ -2019,7 +2096,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.RepositoryTransaction"
+                            "$ref": "#/definitions/models.IncomeProduct"
                         }
                     },
                     "400": {
@@ -2043,9 +2120,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/rtransaction/{id}": {
+        "/income-product/{id}": {
             "get": {
-                "description": "get rtransaction by id",
+                "description": "get income product",
                 "consumes": [
                     "application/json"
                 ],
@@ -2053,13 +2130,13 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "rtransaction"
+                    "income-product"
                 ],
-                "summary": "Get rtransaction by id",
+                "summary": "Get income product",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "rtransaction_id",
+                        "description": "id",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -2069,7 +2146,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.RepositoryTransaction"
+                            "$ref": "#/definitions/models.IncomeProduct"
                         }
                     },
                     "400": {
@@ -2093,7 +2170,7 @@ const docTemplate = `{
                 }
             },
             "put": {
-                "description": "get rtransaction",
+                "description": "update income product",
                 "consumes": [
                     "application/json"
                 ],
@@ -2101,23 +2178,23 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "rtransaction"
+                    "income-product"
                 ],
-                "summary": "Update rtransaction",
+                "summary": "Update income product",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "rtransaction_id",
+                        "description": "id",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "rtransaction",
-                        "name": "rtransaction",
+                        "description": "income-product",
+                        "name": "income-product",
                         "in": "body",
                         "schema": {
-                            "$ref": "#/definitions/models.UpdateRepositoryTransaction"
+                            "$ref": "#/definitions/models.UpdateIncomeProduct"
                         }
                     }
                 ],
@@ -2125,7 +2202,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.Repository"
+                            "$ref": "#/definitions/models.IncomeProduct"
                         }
                     },
                     "400": {
@@ -2149,7 +2226,7 @@ const docTemplate = `{
                 }
             },
             "delete": {
-                "description": "delete rtransaction",
+                "description": "delete income product",
                 "consumes": [
                     "application/json"
                 ],
@@ -2157,13 +2234,13 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "rtransaction"
+                    "income-product"
                 ],
-                "summary": "Delete rtransaction",
+                "summary": "Delete income product",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "rtransaction_id",
+                        "description": "id",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -2197,9 +2274,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/rtransactions": {
+        "/income/{id}": {
             "get": {
-                "description": "get rtransaction list",
+                "description": "get income",
                 "consumes": [
                     "application/json"
                 ],
@@ -2207,34 +2284,23 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "rtransaction"
+                    "income"
                 ],
-                "summary": "Get rtransaction list",
+                "summary": "Get income",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "page",
-                        "name": "page",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "limit",
-                        "name": "limit",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "search",
-                        "name": "search",
-                        "in": "query"
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.RepositoryTransactionsResponse"
+                            "$ref": "#/definitions/models.Income"
                         }
                     },
                     "400": {
@@ -2256,11 +2322,9 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/sale": {
-            "post": {
-                "description": "create a new sale",
+            },
+            "put": {
+                "description": "update income, the exchange rate of currency is taken again at the moment of the update",
                 "consumes": [
                     "application/json"
                 ],
@@ -2268,16 +2332,23 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "sale"
+                    "income"
                 ],
-                "summary": "Create a new sale",
+                "summary": "Update income",
                 "parameters": [
                     {
-                        "description": "sale",
-                        "name": "sale",
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "income",
+                        "name": "income",
                         "in": "body",
                         "schema": {
-                            "$ref": "#/definitions/models.CreateSale"
+                            "$ref": "#/definitions/models.UpdateIncome"
                         }
                     }
                 ],
@@ -2285,7 +2356,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.Sale"
+                            "$ref": "#/definitions/models.Income"
                         }
                     },
                     "400": {
@@ -2307,11 +2378,9 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/sale/{id}": {
-            "get": {
-                "description": "get sale by id",
+            },
+            "delete": {
+                "description": "delete income",
                 "consumes": [
                     "application/json"
                 ],
@@ -2319,13 +2388,13 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "sale"
+                    "income"
                 ],
-                "summary": "Get sale by id",
+                "summary": "Delete income",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "sale_id",
+                        "description": "income_id",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -2335,7 +2404,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.Sale"
+                            "$ref": "#/definitions/models.Response"
                         }
                     },
                     "400": {
@@ -2357,9 +2426,11 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
+            }
+        },
+        "/income/{id}/finish": {
             "put": {
-                "description": "update sale",
+                "description": "add all income products to the branch repository and lock the income",
                 "consumes": [
                     "application/json"
                 ],
@@ -2367,31 +2438,23 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "sale"
+                    "income"
                 ],
-                "summary": "Update sale",
+                "summary": "Finish income",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "sale_id",
+                        "description": "income_id",
                         "name": "id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "description": "sale",
-                        "name": "sale",
-                        "in": "body",
-                        "schema": {
-                            "$ref": "#/definitions/models.UpdateSale"
-                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.Response"
+                            "$ref": "#/definitions/models.Income"
                         }
                     },
                     "400": {
@@ -2413,9 +2476,11 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "delete sale",
+            }
+        },
+        "/incomes": {
+            "get": {
+                "description": "get income list",
                 "consumes": [
                     "application/json"
                 ],
@@ -2423,23 +2488,50 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "sale"
+                    "income"
                 ],
-                "summary": "Delete sale",
+                "summary": "Get income list",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "sale_id",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "page",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "limit",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "branch_id",
+                        "name": "branch_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "supplier_id",
+                        "name": "supplier_id",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "in_process",
+                            "finished"
+                        ],
+                        "type": "string",
+                        "description": "status",
+                        "name": "status",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.Sale"
+                            "$ref": "#/definitions/models.IncomeResponse"
                         }
                     },
                     "400": {
@@ -2463,44 +2555,36 @@ const docTemplate = `{
                 }
             }
         },
-        "/sales": {
-            "get": {
-                "description": "get sale list",
+        "/labels": {
+            "post": {
+                "description": "render price labels with name, price and barcode for the given products or for all lines of an income.\nLines of an income get a label per unit for products sold by piece and one label for weighed goods.\nformat is pdf (A4 sheet, default) or svg, width and height are the label size in mm (58x40 by default).\npdf labels print only Windows-1252 characters, products with other names, like Cyrillic ones, should be printed as svg.\nPrices are those of branch_id, labels of an income use the income branch when branch_id is not given",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/pdf",
+                    "image/svg+xml"
                 ],
                 "tags": [
-                    "sale"
+                    "label"
                 ],
-                "summary": "Get sale list",
+                "summary": "Render price labels",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "page",
-                        "name": "page",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "limit",
-                        "name": "limit",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "search",
-                        "name": "search",
-                        "in": "query"
+                        "description": "labels",
+                        "name": "labels",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.LabelRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.Sale"
+                            "type": "file"
                         }
                     },
                     "400": {
@@ -2524,9 +2608,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/sell": {
+        "/product": {
             "post": {
-                "description": "sell",
+                "description": "create a new product",
                 "consumes": [
                     "application/json"
                 ],
@@ -2534,16 +2618,16 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "sell"
+                    "product"
                 ],
-                "summary": "sell",
+                "summary": "Create a new product",
                 "parameters": [
                     {
-                        "description": "sell",
-                        "name": "sell",
+                        "description": "sale",
+                        "name": "product",
                         "in": "body",
                         "schema": {
-                            "$ref": "#/definitions/models.CreateSale"
+                            "$ref": "#/definitions/models.CreateProduct"
                         }
                     }
                 ],
@@ -2551,7 +2635,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.Sale"
+                            "$ref": "#/definitions/models.Product"
                         }
                     },
                     "400": {
@@ -2575,34 +2659,30 @@ const docTemplate = `{
                 }
             }
         },
-        "/staff": {
-            "post": {
-                "description": "create a new staff",
-                "consumes": [
-                    "application/json"
-                ],
+        "/product/export": {
+            "get": {
+                "description": "export all products with name, price, barcode and category path as csv (default) or xlsx,\nthe file can be imported back",
                 "produces": [
-                    "application/json"
+                    "text/csv",
+                    "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
                 ],
                 "tags": [
-                    "staff"
+                    "product"
                 ],
-                "summary": "Create a new staff",
+                "summary": "Export products",
                 "parameters": [
                     {
-                        "description": "staff",
-                        "name": "staff",
-                        "in": "body",
-                        "schema": {
-                            "$ref": "#/definitions/models.CreateStaff"
-                        }
+                        "type": "string",
+                        "description": "csv or xlsx",
+                        "name": "format",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.Staff"
+                            "type": "file"
                         }
                     },
                     "400": {
@@ -2611,12 +2691,6 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.Response"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
-                        "schema": {
-                            "$ref": "#/definitions/models.Response"
-                        }
-                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -2626,34 +2700,39 @@ const docTemplate = `{
                 }
             }
         },
-        "/staff-tariff": {
+        "/product/import": {
             "post": {
-                "description": "create a new staff tariff",
+                "description": "import products from a csv or xlsx file with the header row name, price, barcode, category.\nA product with the barcode of the row is updated, other rows create products. category is a path like Food/Dairy/Milk,\nmissing categories are created. Rows with errors are skipped and reported, with dry_run nothing is saved.\nBarcodes of xlsx number cells get back the leading zeros of EAN-8, UPC-A and EAN-13 codes",
                 "consumes": [
-                    "application/json"
+                    "multipart/form-data"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "staff-tariff"
+                    "product"
                 ],
-                "summary": "Create a new staff tariff",
+                "summary": "Import products",
                 "parameters": [
                     {
-                        "description": "staff-Tariff",
-                        "name": "staffTariff",
-                        "in": "body",
-                        "schema": {
-                            "$ref": "#/definitions/models.CreateStaffTariff"
-                        }
+                        "type": "file",
+                        "description": "csv or xlsx file",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "dry_run",
+                        "name": "dry_run",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.StaffTariff"
+                            "$ref": "#/definitions/models.ProductImportResponse"
                         }
                     },
                     "400": {
@@ -2677,9 +2756,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/staff-tariff/{id}": {
+        "/product/search": {
             "get": {
-                "description": "get staff tariff by id",
+                "description": "search products for the checkout screen by barcode, name prefix, words of the name or category\nand misspelled names. The best matches come first, price and stock are those of branch_id",
                 "consumes": [
                     "application/json"
                 ],
@@ -2687,23 +2766,41 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "staff-tariff"
+                    "product"
                 ],
-                "summary": "Get staff tariff by id",
+                "summary": "Search products",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "staff-tariff_id",
-                        "name": "id",
-                        "in": "path",
+                        "description": "search text or barcode",
+                        "name": "q",
+                        "in": "query",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "branch_id",
+                        "name": "branch_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "limit",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "language",
+                        "name": "Accept-Language",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.StaffTariff"
+                            "$ref": "#/definitions/models.ProductSearchResponse"
                         }
                     },
                     "400": {
@@ -2725,9 +2822,11 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "put": {
-                "description": "get staff tariff",
+            }
+        },
+        "/product/{id}": {
+            "get": {
+                "description": "get product by id",
                 "consumes": [
                     "application/json"
                 ],
@@ -2735,31 +2834,29 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "staff-tariff"
+                    "product"
                 ],
-                "summary": "Update staff tariff",
+                "summary": "Get product by id",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "staff-tariff_id",
+                        "description": "product_id",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "staff-tariff",
-                        "name": "staff-tariff",
-                        "in": "body",
-                        "schema": {
-                            "$ref": "#/definitions/models.UpdateStaffTariff"
-                        }
+                        "type": "string",
+                        "description": "language",
+                        "name": "Accept-Language",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.StaffTariff"
+                            "$ref": "#/definitions/models.Product"
                         }
                     },
                     "400": {
@@ -2782,8 +2879,8 @@ const docTemplate = `{
                     }
                 }
             },
-            "delete": {
-                "description": "delete staff tariff",
+            "put": {
+                "description": "update product, a variant sent with price 0 is sold at the parent price again.\nunit and precision are kept when they are not sent, they can not be changed while the stock of the product does not fit them",
                 "consumes": [
                     "application/json"
                 ],
@@ -2791,84 +2888,31 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "staff-tariff"
+                    "product"
                 ],
-                "summary": "Delete staff tariff",
+                "summary": "Update product",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "staff-tariff_id",
+                        "description": "product_id",
                         "name": "id",
                         "in": "path",
                         "required": true
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/models.Response"
-                        }
-                    },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "$ref": "#/definitions/models.Response"
-                        }
-                    },
-                    "404": {
-                        "description": "Not Found",
-                        "schema": {
-                            "$ref": "#/definitions/models.Response"
-                        }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    {
+                        "description": "product",
+                        "name": "product",
+                        "in": "body",
                         "schema": {
-                            "$ref": "#/definitions/models.Response"
+                            "$ref": "#/definitions/models.UpdateProduct"
                         }
                     }
-                }
-            }
-        },
-        "/staff-tariffs": {
-            "get": {
-                "description": "get staff tariff list",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "staff-tariff"
-                ],
-                "summary": "Get staff tariff list",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "page",
-                        "name": "page",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "limit",
-                        "name": "limit",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "search",
-                        "name": "search",
-                        "in": "query"
-                    }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.StaffTariffResponse"
+                            "$ref": "#/definitions/models.Product"
                         }
                     },
                     "400": {
@@ -2890,11 +2934,9 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/staff/{id}": {
-            "get": {
-                "description": "get staff by id",
+            },
+            "delete": {
+                "description": "delete product",
                 "consumes": [
                     "application/json"
                 ],
@@ -2902,13 +2944,13 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "staff"
+                    "product"
                 ],
-                "summary": "Get staff by id",
+                "summary": "Delete product",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "staff_id",
+                        "description": "product_id",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -2918,7 +2960,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.Staff"
+                            "$ref": "#/definitions/models.Product"
                         }
                     },
                     "400": {
@@ -2940,9 +2982,11 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "put": {
-                "description": "get staff",
+            }
+        },
+        "/product/{id}/barcode": {
+            "post": {
+                "description": "add a barcode to a product, quantity is the number of units the barcode stands for (1 for a unit, more for a pack)",
                 "consumes": [
                     "application/json"
                 ],
@@ -2950,23 +2994,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "staff"
+                    "product"
                 ],
-                "summary": "Update staff",
+                "summary": "Add product barcode",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "staff_id",
+                        "description": "product_id",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "staff",
-                        "name": "staff",
+                        "description": "barcode",
+                        "name": "barcode",
                         "in": "body",
+                        "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.UpdateStaff"
+                            "$ref": "#/definitions/models.CreateProductBarcode"
                         }
                     }
                 ],
@@ -2974,7 +3019,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.Staff"
+                            "$ref": "#/definitions/models.Product"
                         }
                     },
                     "400": {
@@ -2996,9 +3041,11 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
+            }
+        },
+        "/product/{id}/barcode/{barcode_id}": {
             "delete": {
-                "description": "delete staff",
+                "description": "delete a barcode of a product",
                 "consumes": [
                     "application/json"
                 ],
@@ -3006,23 +3053,30 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "staff"
+                    "product"
                 ],
-                "summary": "Delete staff",
+                "summary": "Delete product barcode",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "staff_id",
+                        "description": "product_id",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "barcode_id",
+                        "name": "barcode_id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.Response"
+                            "$ref": "#/definitions/models.Product"
                         }
                     },
                     "400": {
@@ -3044,42 +3098,42 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "patch": {
-                "description": "update staff password",
+            }
+        },
+        "/product/{id}/image": {
+            "post": {
+                "description": "upload a jpeg, png or gif image of the product up to 10 MB, a thumbnail is made for it.\nThe first image of a product is its main image",
                 "consumes": [
-                    "application/json"
+                    "multipart/form-data"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "staff"
+                    "product"
                 ],
-                "summary": "Update staff password",
+                "summary": "Upload product image",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "staff_id",
+                        "description": "product_id",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "staff",
-                        "name": "staff",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.UpdateStaffPassword"
-                        }
+                        "type": "file",
+                        "description": "image",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/models.Response"
+                            "$ref": "#/definitions/models.ProductImage"
                         }
                     },
                     "400": {
@@ -3103,9 +3157,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/staffs": {
-            "get": {
-                "description": "get staff list",
+        "/product/{id}/image/{image_id}": {
+            "delete": {
+                "description": "delete an image of the product with its thumbnail",
                 "consumes": [
                     "application/json"
                 ],
@@ -3113,34 +3167,30 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "staff"
+                    "product"
                 ],
-                "summary": "Get staff list",
+                "summary": "Delete product image",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "page",
-                        "name": "page",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "limit",
-                        "name": "limit",
-                        "in": "query"
+                        "description": "product_id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     },
                     {
                         "type": "string",
-                        "description": "search",
-                        "name": "search",
-                        "in": "query"
+                        "description": "image_id",
+                        "name": "image_id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.StaffsResponse"
+                            "$ref": "#/definitions/models.Response"
                         }
                     },
                     "400": {
@@ -3164,9 +3214,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/transaction": {
-            "post": {
-                "description": "create a new transaction",
+        "/product/{id}/images": {
+            "get": {
+                "description": "get images of the product, the main image first",
                 "consumes": [
                     "application/json"
                 ],
@@ -3174,24 +3224,26 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "transaction"
+                    "product"
                 ],
-                "summary": "Create a new transaction",
+                "summary": "Get product images",
                 "parameters": [
                     {
-                        "description": "sale",
-                        "name": "transaction",
-                        "in": "body",
-                        "schema": {
-                            "$ref": "#/definitions/models.CreateTransaction"
-                        }
+                        "type": "string",
+                        "description": "product_id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.Transaction"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.ProductImage"
+                            }
                         }
                     },
                     "400": {
@@ -3215,9 +3267,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/transaction/{id}": {
+        "/product/{id}/price": {
             "get": {
-                "description": "get transaction by id",
+                "description": "get the price of the product in branch_id (all branches when empty) at the moment at (RFC3339, now by default)",
                 "consumes": [
                     "application/json"
                 ],
@@ -3225,23 +3277,35 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "transaction"
+                    "product"
                 ],
-                "summary": "Get transaction by id",
+                "summary": "Get effective product price",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "transaction_id",
+                        "description": "product_id",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "branch_id",
+                        "name": "branch_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "at",
+                        "name": "at",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.Transaction"
+                            "$ref": "#/definitions/models.EffectivePrice"
                         }
                     },
                     "400": {
@@ -3264,8 +3328,8 @@ const docTemplate = `{
                     }
                 }
             },
-            "put": {
-                "description": "update transaction",
+            "post": {
+                "description": "set a price of the product for all branches or for branch_id only. The price starts at starts_at\n(RFC3339, in the future) or now when starts_at is empty, earlier prices are kept as history.\nThe latest started price is used, a price of the branch wins over one for all branches starting at once",
                 "consumes": [
                     "application/json"
                 ],
@@ -3273,31 +3337,32 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "transaction"
+                    "product"
                 ],
-                "summary": "Update transaction",
+                "summary": "Schedule product price",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "transaction_id",
+                        "description": "product_id",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "sale",
-                        "name": "transaction",
+                        "description": "price",
+                        "name": "price",
                         "in": "body",
+                        "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.UpdateTransaction"
+                            "$ref": "#/definitions/models.CreateProductPrice"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/models.Transaction"
+                            "$ref": "#/definitions/models.ProductPricesResponse"
                         }
                     },
                     "400": {
@@ -3319,9 +3384,11 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
+            }
+        },
+        "/product/{id}/price/{price_id}": {
             "delete": {
-                "description": "delete transaction",
+                "description": "cancel a price which has not started yet, started prices stay in the history",
                 "consumes": [
                     "application/json"
                 ],
@@ -3329,16 +3396,23 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "transaction"
+                    "product"
                 ],
-                "summary": "Delete transaction",
+                "summary": "Cancel scheduled product price",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "transaction_id",
+                        "description": "product_id",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "price_id",
+                        "name": "price_id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
@@ -3369,9 +3443,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/transactions": {
+        "/product/{id}/prices": {
             "get": {
-                "description": "get transaction list",
+                "description": "get started and scheduled prices of the product, latest first. With branch_id prices of\nthe branch and prices for all branches are listed",
                 "consumes": [
                     "application/json"
                 ],
@@ -3379,10 +3453,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "transaction"
+                    "product"
                 ],
-                "summary": "Get transaction list",
+                "summary": "Get product price history",
                 "parameters": [
+                    {
+                        "type": "string",
+                        "description": "product_id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
                     {
                         "type": "string",
                         "description": "page",
@@ -3397,105 +3478,5728 @@ const docTemplate = `{
                     },
                     {
                         "type": "string",
-                        "description": "from-amount",
-                        "name": "from-amount",
+                        "description": "branch_id",
+                        "name": "branch_id",
                         "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ProductPricesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
                     },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/product/{id}/tax-rate": {
+            "get": {
+                "description": "get the rate the product is taxed at and whether it is the rate of the product,\nof the product it is a variant of (parent) or of a category",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tax"
+                ],
+                "summary": "Get product tax rate",
+                "parameters": [
                     {
                         "type": "string",
-                        "description": "to-amount",
-                        "name": "to-amount",
-                        "in": "query"
+                        "description": "product_id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ProductTaxRate"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "tax the product at tax_rate_id, an empty tax_rate_id makes the product taxed\nat the rate of the product it is a variant of or of its category",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tax"
+                ],
+                "summary": "Set product tax rate",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "product_id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "tax-rate",
+                        "name": "tax-rate",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.SetTaxRate"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ProductTaxRate"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/product/{id}/unit": {
+            "post": {
+                "description": "add a pack the product is received in, quantity is the number of product units in the pack (e.g. a box of 12)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "product"
+                ],
+                "summary": "Add product unit",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "product_id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "unit",
+                        "name": "unit",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateProductUnit"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Product"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/product/{id}/unit/{unit_id}": {
+            "delete": {
+                "description": "delete a pack of a product",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "product"
+                ],
+                "summary": "Delete product unit",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "product_id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "unit_id",
+                        "name": "unit_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Product"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/product/{id}/variant": {
+            "post": {
+                "description": "add a variant (e.g. a size or a colour) to a product. The variant has its own barcode and stock,\nwithout price_override it is sold at the parent price",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "product"
+                ],
+                "summary": "Create product variant",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "product_id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "variant",
+                        "name": "variant",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateProductVariant"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Product"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/products": {
+            "get": {
+                "description": "get product list",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "product"
+                ],
+                "summary": "Get product list",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "page",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "limit",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "name",
+                        "name": "name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "barcode",
+                        "name": "barcode",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "list parent products with their variants",
+                        "name": "grouped",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "products of the category and its subcategories",
+                        "name": "category_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "language",
+                        "name": "Accept-Language",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ProductResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/purchase-order": {
+            "post": {
+                "description": "order products from a supplier with expected prices in currency, the base currency when empty",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "purchase-order"
+                ],
+                "summary": "Create a new purchase order",
+                "parameters": [
+                    {
+                        "description": "purchase-order",
+                        "name": "purchase-order",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.CreatePurchaseOrder"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.PurchaseOrder"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/purchase-order/{id}": {
+            "get": {
+                "description": "get purchase order with ordered and received counts",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "purchase-order"
+                ],
+                "summary": "Get purchase order",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "purchase_order_id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.PurchaseOrder"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/purchase-order/{id}/cancel": {
+            "put": {
+                "description": "cancel a purchase order nothing was received for",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "purchase-order"
+                ],
+                "summary": "Cancel purchase order",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "purchase_order_id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.PurchaseOrder"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/purchase-order/{id}/discrepancies": {
+            "get": {
+                "description": "compare ordered and received counts and prices of a purchase order",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "purchase-order"
+                ],
+                "summary": "Get purchase order discrepancies",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "purchase_order_id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.PurchaseOrderDiscrepancies"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/purchase-order/{id}/receive": {
+            "post": {
+                "description": "receive all or a part of a purchase order, an income with income products is created\nin the currency of the order at its exchange rate of now",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "purchase-order"
+                ],
+                "summary": "Receive purchase order",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "purchase_order_id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "receive",
+                        "name": "receive",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ReceivePurchaseOrder"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Income"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/purchase-orders": {
+            "get": {
+                "description": "get purchase order list",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "purchase-order"
+                ],
+                "summary": "Get purchase order list",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "page",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "limit",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "supplier_id",
+                        "name": "supplier_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "branch_id",
+                        "name": "branch_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "status",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.PurchaseOrderResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/refund/{id}": {
+            "get": {
+                "description": "get refund with its products",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "refund"
+                ],
+                "summary": "Get refund",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "refund_id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Refund"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/repositories": {
+            "get": {
+                "description": "get repository list",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "repository"
+                ],
+                "summary": "Get repository list",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "page",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "limit",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "search",
+                        "name": "search",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.RepositoriesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/repositories/low-stock": {
+            "get": {
+                "description": "get products whose count is below the minimum stock level",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "repository"
+                ],
+                "summary": "Get low stock list",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "branch_id",
+                        "name": "branch_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.LowStockResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/repositories/reconcile": {
+            "get": {
+                "description": "compare repository counts with counts expected from repository transactions",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "repository"
+                ],
+                "summary": "Get stock reconciliation",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "branch_id",
+                        "name": "branch_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ReconcileResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "fix every difference between repository counts and repository transactions. mode ledger trusts the counts\nand posts reconciliation movements, mode counts trusts the movements and sets the counts from them",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "repository"
+                ],
+                "summary": "Reconcile stock",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "branch_id",
+                        "name": "branch_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ledger or counts",
+                        "name": "mode",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ReconcileResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/repositories/reorder": {
+            "get": {
+                "description": "suggest quantities to order using average daily sales over the last days",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "repository"
+                ],
+                "summary": "Get reorder suggestions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "branch_id",
+                        "name": "branch_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "sales window in days",
+                        "name": "days",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "days the order should cover",
+                        "name": "cover_days",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ReorderSuggestionsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/repository": {
+            "post": {
+                "description": "create a new repository",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "repository"
+                ],
+                "summary": "Create a new repository",
+                "parameters": [
+                    {
+                        "description": "repository",
+                        "name": "repository",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateRepository"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Repository"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/repository/{id}": {
+            "get": {
+                "description": "get repository by id",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "repository"
+                ],
+                "summary": "Get repository by id",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "repository_id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Repository"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "update repository, the change of the count is recorded as an adjustment movement",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "repository"
+                ],
+                "summary": "Update repository",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "repository_id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "repository",
+                        "name": "repository",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdateRepository"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Repository"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "delete repository",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "repository"
+                ],
+                "summary": "Delete repository",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "repository_id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/repository/{id}/levels": {
+            "put": {
+                "description": "set minimum and target stock levels of a product in a branch",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "repository"
+                ],
+                "summary": "Update repository stock levels",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "repository_id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "levels",
+                        "name": "levels",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdateRepositoryLevels"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Repository"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/rtransaction": {
+            "post": {
+                "description": "post a movement by hand. repository counts do not change, reconcile shows the difference",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rtransaction"
+                ],
+                "summary": "Create a new rtransaction",
+                "parameters": [
+                    {
+                        "description": "rtransaction",
+                        "name": "rtransaction",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateRepositoryTransaction"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.RepositoryTransaction"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/rtransaction/{id}": {
+            "get": {
+                "description": "get rtransaction by id",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rtransaction"
+                ],
+                "summary": "Get rtransaction by id",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "rtransaction_id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.RepositoryTransaction"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "correct a movement: it is reversed and the corrected movement is posted with a new id, which is returned",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rtransaction"
+                ],
+                "summary": "Update rtransaction",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "rtransaction_id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "rtransaction",
+                        "name": "rtransaction",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdateRepositoryTransaction"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.RepositoryTransaction"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "cancel a movement with a reversing movement, the movement stays in the history",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rtransaction"
+                ],
+                "summary": "Delete rtransaction",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "rtransaction_id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/rtransactions": {
+            "get": {
+                "description": "get rtransaction list",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rtransaction"
+                ],
+                "summary": "Get rtransaction list",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "page",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "limit",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "product_id",
+                        "name": "search",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "branch_id",
+                        "name": "branch_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "sale, income, transfer, write_off, stocktake, refund or adjustment",
+                        "name": "source_type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "source_id",
+                        "name": "source_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.RepositoryTransactionsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/rtransactions/history": {
+            "get": {
+                "description": "get movements of a product in a branch with opening and running balance",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rtransaction"
+                ],
+                "summary": "Get stock movement history",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "product_id",
+                        "name": "product_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "branch_id",
+                        "name": "branch_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "from date, 2006-01-02",
+                        "name": "from_date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "to date, 2006-01-02",
+                        "name": "to_date",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.MovementHistory"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/sale": {
+            "post": {
+                "description": "create a new sale",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "sale"
+                ],
+                "summary": "Create a new sale",
+                "parameters": [
+                    {
+                        "description": "sale",
+                        "name": "sale",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateSale"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Sale"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/sale/{id}": {
+            "get": {
+                "description": "get sale by id",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "sale"
+                ],
+                "summary": "Get sale by id",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "sale_id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Sale"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "update sale",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "sale"
+                ],
+                "summary": "Update sale",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "sale_id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "sale",
+                        "name": "sale",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdateSale"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "delete sale",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "sale"
+                ],
+                "summary": "Delete sale",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "sale_id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Sale"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/sale/{id}/payment": {
+            "post": {
+                "description": "register money received for a sale in progress in currency (the base currency when empty).\nThe amount is converted to the base currency at the exchange rate of now, change is given in the base currency",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "sale"
+                ],
+                "summary": "Pay for a sale",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "sale_id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "payment",
+                        "name": "payment",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateSalePayment"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.SalePayments"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/sale/{id}/payments": {
+            "get": {
+                "description": "get payments of a sale with the total of its lines, the amount paid, what is left to pay and the change",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "sale"
+                ],
+                "summary": "Get sale payments",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "sale_id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SalePayments"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/sale/{id}/receipt": {
+            "get": {
+                "description": "get the receipt of a sale with its lines, product names are in the language of Accept-Language",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "sale"
+                ],
+                "summary": "Get sale receipt",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "sale_id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "language",
+                        "name": "Accept-Language",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Receipt"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/sale/{id}/refund": {
+            "post": {
+                "description": "give back quantity of basket lines of a successful sale, all that is left of the sale when products\nare empty. The goods go back to the branch and the refund is registered with the fiscal module,\nthe money paid back is the share of the line price and tax",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "refund"
+                ],
+                "summary": "Refund a sale",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "sale_id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "refund",
+                        "name": "refund",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateRefund"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.Refund"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/sale/{id}/refunds": {
+            "get": {
+                "description": "get refunds of the sale, latest first",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "refund"
+                ],
+                "summary": "Get sale refunds",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "sale_id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "page",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "limit",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.RefundsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/sales": {
+            "get": {
+                "description": "get sale list",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "sale"
+                ],
+                "summary": "Get sale list",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "page",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "limit",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "search",
+                        "name": "search",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Sale"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/sell": {
+            "post": {
+                "description": "sell",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "sell"
+                ],
+                "summary": "sell",
+                "parameters": [
+                    {
+                        "description": "sell",
+                        "name": "sell",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateSale"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Sale"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/staff": {
+            "post": {
+                "description": "create a new staff",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "staff"
+                ],
+                "summary": "Create a new staff",
+                "parameters": [
+                    {
+                        "description": "staff",
+                        "name": "staff",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateStaff"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Staff"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/staff-tariff": {
+            "post": {
+                "description": "create a new staff tariff",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "staff-tariff"
+                ],
+                "summary": "Create a new staff tariff",
+                "parameters": [
+                    {
+                        "description": "staff-Tariff",
+                        "name": "staffTariff",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateStaffTariff"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.StaffTariff"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/staff-tariff/{id}": {
+            "get": {
+                "description": "get staff tariff by id",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "staff-tariff"
+                ],
+                "summary": "Get staff tariff by id",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "staff-tariff_id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.StaffTariff"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "get staff tariff",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "staff-tariff"
+                ],
+                "summary": "Update staff tariff",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "staff-tariff_id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "staff-tariff",
+                        "name": "staff-tariff",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdateStaffTariff"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.StaffTariff"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "delete staff tariff",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "staff-tariff"
+                ],
+                "summary": "Delete staff tariff",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "staff-tariff_id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/staff-tariffs": {
+            "get": {
+                "description": "get staff tariff list",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "staff-tariff"
+                ],
+                "summary": "Get staff tariff list",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "page",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "limit",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "search",
+                        "name": "search",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.StaffTariffResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/staff/{id}": {
+            "get": {
+                "description": "get staff by id",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "staff"
+                ],
+                "summary": "Get staff by id",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "staff_id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Staff"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "get staff",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "staff"
+                ],
+                "summary": "Update staff",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "staff_id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "staff",
+                        "name": "staff",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdateStaff"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Staff"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "delete staff",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "staff"
+                ],
+                "summary": "Delete staff",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "staff_id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "update staff password",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "staff"
+                ],
+                "summary": "Update staff password",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "staff_id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "staff",
+                        "name": "staff",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdateStaffPassword"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/staffs": {
+            "get": {
+                "description": "get staff list",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "staff"
+                ],
+                "summary": "Get staff list",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "page",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "limit",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "search",
+                        "name": "search",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.StaffsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/stock-snapshot": {
+            "get": {
+                "description": "get stock per branch and product as of a moment, computed from repository transactions",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/csv"
+                ],
+                "tags": [
+                    "rtransaction"
+                ],
+                "summary": "Get stock snapshot",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "2006-01-02 (end of the day) or RFC3339 timestamp, now by default",
+                        "name": "at",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "branch_id",
+                        "name": "branch_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "json or csv",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.StockSnapshot"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/supplier": {
+            "post": {
+                "description": "create a new supplier",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "supplier"
+                ],
+                "summary": "Create a new supplier",
+                "parameters": [
+                    {
+                        "description": "supplier",
+                        "name": "supplier",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateSupplier"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Supplier"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/supplier/{id}": {
+            "get": {
+                "description": "get supplier by id with the current debt balance",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "supplier"
+                ],
+                "summary": "Get supplier by id",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "supplier_id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Supplier"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "update supplier",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "supplier"
+                ],
+                "summary": "Update supplier",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "supplier_id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "supplier",
+                        "name": "supplier",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdateSupplier"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Supplier"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "delete supplier",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "supplier"
+                ],
+                "summary": "Delete supplier",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "supplier_id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/supplier/{id}/payment": {
+            "post": {
+                "description": "register a payment to a supplier in currency (the base currency when empty), it decreases\nthe supplier debt by the amount converted to the base currency at the exchange rate of now",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "supplier"
+                ],
+                "summary": "Pay supplier",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "supplier_id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "payment",
+                        "name": "payment",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateSupplierPayment"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Supplier"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/supplier/{id}/payments": {
+            "get": {
+                "description": "get payments made to a supplier",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "supplier"
+                ],
+                "summary": "Get supplier payments",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "supplier_id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "page",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "limit",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SupplierPaymentResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/suppliers": {
+            "get": {
+                "description": "get supplier list",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "supplier"
+                ],
+                "summary": "Get supplier list",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "page",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "limit",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "search",
+                        "name": "search",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SupplierResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/tax-rate": {
+            "post": {
+                "description": "create a tax rate in percent, 12 is 12 percent. Assign it to products or categories to tax their sales",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tax"
+                ],
+                "summary": "Create a new tax rate",
+                "parameters": [
+                    {
+                        "description": "tax-rate",
+                        "name": "tax-rate",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateTaxRate"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.TaxRate"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/tax-rate/{id}": {
+            "get": {
+                "description": "get tax rate",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tax"
+                ],
+                "summary": "Get tax rate",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "tax_rate_id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.TaxRate"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "update tax rate, sales which were checked out keep the tax they were calculated with",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tax"
+                ],
+                "summary": "Update tax rate",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "tax_rate_id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "tax-rate",
+                        "name": "tax-rate",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdateTaxRate"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.TaxRate"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "delete tax rate, products and categories which had it are taxed as if they had no rate of their own",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tax"
+                ],
+                "summary": "Delete tax rate",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "tax_rate_id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/tax-rates": {
+            "get": {
+                "description": "get tax rates ordered by rate",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tax"
+                ],
+                "summary": "Get tax rate list",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "page",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "limit",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "search",
+                        "name": "search",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.TaxRatesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/tax-report": {
+            "get": {
+                "description": "net, tax and total of sales checked out in a period by branch and tax rate, dates are in 2006-01-02 format",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tax"
+                ],
+                "summary": "Tax report",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "branch_id",
+                        "name": "branch_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "from_date",
+                        "name": "from_date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "to_date",
+                        "name": "to_date",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.TaxReport"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/transaction": {
+            "post": {
+                "description": "create a new transaction",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "transaction"
+                ],
+                "summary": "Create a new transaction",
+                "parameters": [
+                    {
+                        "description": "sale",
+                        "name": "transaction",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateTransaction"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Transaction"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/transaction/{id}": {
+            "get": {
+                "description": "get transaction by id",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "transaction"
+                ],
+                "summary": "Get transaction by id",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "transaction_id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Transaction"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "update transaction",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "transaction"
+                ],
+                "summary": "Update transaction",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "transaction_id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "sale",
+                        "name": "transaction",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdateTransaction"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Transaction"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "delete transaction",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "transaction"
+                ],
+                "summary": "Delete transaction",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "transaction_id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/transactions": {
+            "get": {
+                "description": "get transaction list",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "transaction"
+                ],
+                "summary": "Get transaction list",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "page",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "limit",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "from-amount",
+                        "name": "from-amount",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "to-amount",
+                        "name": "to-amount",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.TransactionResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/write-off": {
+            "post": {
+                "description": "create a new write-off document waiting for manager approval",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "write-off"
+                ],
+                "summary": "Create a new write-off",
+                "parameters": [
+                    {
+                        "description": "write-off",
+                        "name": "write-off",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateWriteOff"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.WriteOff"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/write-off-report": {
+            "get": {
+                "description": "approved write-offs grouped by reason for a period, dates are in 2006-01-02 format",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "write-off"
+                ],
+                "summary": "Write-off report",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "branch_id",
+                        "name": "branch_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "from_date",
+                        "name": "from_date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "to_date",
+                        "name": "to_date",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.WriteOffReport"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/write-off/{id}": {
+            "get": {
+                "description": "get write-off with its products",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "write-off"
+                ],
+                "summary": "Get write-off",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "write_off_id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.WriteOff"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "delete pending write-off",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "write-off"
+                ],
+                "summary": "Delete write-off",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "write_off_id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/write-off/{id}/approve": {
+            "put": {
+                "description": "approve write-off by a manager and deduct its products from the branch repository",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "write-off"
+                ],
+                "summary": "Approve write-off",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "write_off_id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "approve",
+                        "name": "approve",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ApproveWriteOff"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.WriteOff"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/write-off/{id}/reject": {
+            "put": {
+                "description": "reject write-off by a manager, stock is not changed",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "write-off"
+                ],
+                "summary": "Reject write-off",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "write_off_id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "reject",
+                        "name": "reject",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ApproveWriteOff"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.WriteOff"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/write-offs": {
+            "get": {
+                "description": "get write-off list",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "write-off"
+                ],
+                "summary": "Get write-off list",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "page",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "limit",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "branch_id",
+                        "name": "branch_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "reason",
+                        "name": "reason",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "status",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.WriteOffResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.Response"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "models.ApproveWriteOff": {
+            "type": "object",
+            "properties": {
+                "approved_by": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Barcode": {
+            "type": "object",
+            "properties": {
+                "barcode": {
+                    "type": "string"
+                },
+                "count": {
+                    "type": "number"
+                },
+                "sale_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.BarcodeFormat": {
+            "type": "object",
+            "properties": {
+                "code_length": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "prefix": {
+                    "type": "string"
+                },
+                "product_code_length": {
+                    "type": "integer"
+                },
+                "product_code_start": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "value_decimals": {
+                    "type": "integer"
+                },
+                "value_length": {
+                    "type": "integer"
+                },
+                "value_start": {
+                    "type": "integer"
+                },
+                "value_type": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.BarcodeFormatsResponse": {
+            "type": "object",
+            "properties": {
+                "barcode_formats": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.BarcodeFormat"
+                    }
+                },
+                "count": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Basket": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "price": {
+                    "type": "number"
+                },
+                "product_id": {
+                    "type": "string"
+                },
+                "quantity": {
+                    "type": "number"
+                },
+                "sale_id": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.BasketsResponse": {
+            "type": "object",
+            "properties": {
+                "basket": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Basket"
+                    }
+                },
+                "count": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Batch": {
+            "type": "object",
+            "properties": {
+                "batch_number": {
+                    "type": "string"
+                },
+                "branch_id": {
+                    "type": "string"
+                },
+                "count": {
+                    "type": "number"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "expiry_date": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "income_product_id": {
+                    "type": "string"
+                },
+                "product_id": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.BatchResponse": {
+            "type": "object",
+            "properties": {
+                "batches": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Batch"
+                    }
+                },
+                "count": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Branch": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.BranchResponse": {
+            "type": "object",
+            "properties": {
+                "branches": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Branch"
+                    }
+                },
+                "count": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Category": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "name_translations": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "parent_id": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CategoryResponse": {
+            "type": "object",
+            "properties": {
+                "categories": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Category"
+                    }
+                },
+                "count": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.CategoryTree": {
+            "type": "object",
+            "properties": {
+                "children": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.CategoryTree"
+                    }
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "name_translations": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "parent_id": {
+                    "type": "string"
+                },
+                "product_count": {
+                    "type": "integer"
+                },
+                "total_product_count": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Conversion": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number"
+                },
+                "at": {
+                    "type": "string"
+                },
+                "base_amount": {
+                    "type": "number"
+                },
+                "base_currency": {
+                    "type": "string"
+                },
+                "currency": {
+                    "type": "string"
+                },
+                "rate": {
+                    "type": "number"
+                }
+            }
+        },
+        "models.CreateBarcodeFormat": {
+            "type": "object",
+            "properties": {
+                "code_length": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "prefix": {
+                    "type": "string"
+                },
+                "product_code_length": {
+                    "type": "integer"
+                },
+                "product_code_start": {
+                    "type": "integer"
+                },
+                "value_decimals": {
+                    "type": "integer"
+                },
+                "value_length": {
+                    "type": "integer"
+                },
+                "value_start": {
+                    "type": "integer"
+                },
+                "value_type": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CreateBasket": {
+            "type": "object",
+            "properties": {
+                "price": {
+                    "type": "number"
+                },
+                "product_id": {
+                    "type": "string"
+                },
+                "quantity": {
+                    "type": "number"
+                },
+                "sale_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CreateBranch": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CreateCategory": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string"
+                },
+                "name_translations": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "parent_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CreateExchangeRate": {
+            "type": "object",
+            "properties": {
+                "currency": {
+                    "type": "string"
+                },
+                "rate": {
+                    "type": "number"
+                },
+                "starts_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CreateIncome": {
+            "type": "object",
+            "properties": {
+                "branch_id": {
+                    "type": "string"
+                },
+                "currency": {
+                    "type": "string"
+                },
+                "supplier_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CreateIncomeProduct": {
+            "type": "object",
+            "properties": {
+                "batch_number": {
+                    "type": "string"
+                },
+                "count": {
+                    "type": "number"
+                },
+                "expiry_date": {
+                    "type": "string"
+                },
+                "income_id": {
+                    "type": "string"
+                },
+                "price": {
+                    "type": "number"
+                },
+                "product_id": {
+                    "type": "string"
+                },
+                "product_unit_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CreateProduct": {
+            "type": "object",
+            "properties": {
+                "barcode": {
+                    "type": "string"
+                },
+                "barcodes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.CreateProductBarcode"
+                    }
+                },
+                "category_id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "name_translations": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "precision": {
+                    "type": "integer"
+                },
+                "price": {
+                    "type": "number"
+                },
+                "unit": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CreateProductBarcode": {
+            "type": "object",
+            "properties": {
+                "barcode": {
+                    "type": "string"
+                },
+                "quantity": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.CreateProductPrice": {
+            "type": "object",
+            "properties": {
+                "branch_id": {
+                    "type": "string"
+                },
+                "price": {
+                    "type": "number"
+                },
+                "starts_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CreateProductUnit": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string"
+                },
+                "quantity": {
+                    "type": "number"
+                }
+            }
+        },
+        "models.CreateProductVariant": {
+            "type": "object",
+            "properties": {
+                "attributes": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "barcode": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "name_translations": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "price_override": {
+                    "type": "number"
+                }
+            }
+        },
+        "models.CreatePurchaseOrder": {
+            "type": "object",
+            "properties": {
+                "branch_id": {
+                    "type": "string"
+                },
+                "comment": {
+                    "type": "string"
+                },
+                "currency": {
+                    "type": "string"
+                },
+                "products": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.CreatePurchaseOrderProduct"
+                    }
+                },
+                "supplier_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CreatePurchaseOrderProduct": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "number"
+                },
+                "price": {
+                    "type": "number"
+                },
+                "product_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CreateRefund": {
+            "type": "object",
+            "properties": {
+                "products": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.CreateRefundProduct"
+                    }
+                },
+                "reason": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CreateRefundProduct": {
+            "type": "object",
+            "properties": {
+                "basket_id": {
+                    "type": "string"
+                },
+                "quantity": {
+                    "type": "number"
+                }
+            }
+        },
+        "models.CreateRepository": {
+            "type": "object",
+            "properties": {
+                "branch_id": {
+                    "type": "string"
+                },
+                "count": {
+                    "type": "number"
+                },
+                "min_count": {
+                    "type": "number"
+                },
+                "product_id": {
+                    "type": "string"
+                },
+                "target_count": {
+                    "type": "number"
+                }
+            }
+        },
+        "models.CreateRepositoryTransaction": {
+            "type": "object",
+            "properties": {
+                "branch_id": {
+                    "type": "string"
+                },
+                "price": {
+                    "type": "number"
+                },
+                "product_id": {
+                    "type": "string"
+                },
+                "quantity": {
+                    "type": "number"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "repository_transaction_type": {
+                    "type": "string"
+                },
+                "source_id": {
+                    "type": "string"
+                },
+                "source_type": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CreateSale": {
+            "type": "object",
+            "properties": {
+                "branch_id": {
+                    "type": "string"
+                },
+                "cashier_id": {
+                    "type": "string"
+                },
+                "client_name": {
+                    "type": "string"
+                },
+                "payment_type": {
+                    "type": "string"
+                },
+                "price": {
+                    "type": "number"
+                },
+                "shop_assistant_id": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CreateSalePayment": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number"
+                },
+                "currency": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CreateStaff": {
+            "type": "object",
+            "properties": {
+                "balance": {
+                    "type": "number"
+                },
+                "birth_date": {
+                    "type": "string"
+                },
+                "branch_id": {
+                    "type": "string"
+                },
+                "login": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                },
+                "staff_type": {
+                    "type": "string"
+                },
+                "tariff_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CreateStaffTariff": {
+            "type": "object",
+            "properties": {
+                "amount_for_card": {
+                    "type": "number"
+                },
+                "amount_for_cash": {
+                    "type": "number"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "tariff_type": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CreateSupplier": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "phone": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CreateSupplierPayment": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number"
+                },
+                "comment": {
+                    "type": "string"
+                },
+                "currency": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CreateTaxRate": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string"
+                },
+                "rate": {
+                    "type": "number"
+                }
+            }
+        },
+        "models.CreateTransaction": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "sale_id": {
+                    "type": "string"
+                },
+                "source_type": {
+                    "type": "string"
+                },
+                "staff_id": {
+                    "type": "string"
+                },
+                "transaction_type": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CreateWriteOff": {
+            "type": "object",
+            "properties": {
+                "branch_id": {
+                    "type": "string"
+                },
+                "comment": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "string"
+                },
+                "products": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.CreateWriteOffProduct"
+                    }
+                },
+                "reason": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CreateWriteOffProduct": {
+            "type": "object",
+            "properties": {
+                "product_id": {
+                    "type": "string"
+                },
+                "quantity": {
+                    "type": "number"
+                }
+            }
+        },
+        "models.EffectivePrice": {
+            "type": "object",
+            "properties": {
+                "at": {
+                    "type": "string"
+                },
+                "branch_id": {
+                    "type": "string"
+                },
+                "price": {
+                    "type": "number"
+                },
+                "product_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ExchangeRate": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "currency": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "rate": {
+                    "type": "number"
+                },
+                "starts_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ExchangeRatesResponse": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "exchange_rates": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ExchangeRate"
+                    }
+                }
+            }
+        },
+        "models.ExpiringBatch": {
+            "type": "object",
+            "properties": {
+                "batch_number": {
+                    "type": "string"
+                },
+                "branch_id": {
+                    "type": "string"
+                },
+                "count": {
+                    "type": "number"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "days_left": {
+                    "type": "integer"
+                },
+                "expiry_date": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "income_product_id": {
+                    "type": "string"
+                },
+                "product_id": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ExpiringBatchesResponse": {
+            "type": "object",
+            "properties": {
+                "batches": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ExpiringBatch"
+                    }
+                },
+                "count": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.FiscalEntriesResponse": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "entries": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.FiscalEntry"
+                    }
+                }
+            }
+        },
+        "models.FiscalEntry": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "document_id": {
+                    "type": "string"
+                },
+                "document_type": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "last_error": {
+                    "type": "string"
+                },
+                "next_attempt_at": {
+                    "type": "string"
+                },
+                "sent_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Income": {
+            "type": "object",
+            "properties": {
+                "base_price": {
+                    "type": "number"
+                },
+                "branch_id": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "currency": {
+                    "type": "string"
+                },
+                "exchange_rate": {
+                    "type": "number"
+                },
+                "finished_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "price": {
+                    "type": "number"
+                },
+                "purchase_order_id": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "supplier_id": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.IncomeProduct": {
+            "type": "object",
+            "properties": {
+                "batch_number": {
+                    "type": "string"
+                },
+                "count": {
+                    "type": "number"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "expiry_date": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "income_id": {
+                    "type": "string"
+                },
+                "price": {
+                    "type": "number"
+                },
+                "product_id": {
+                    "type": "string"
+                },
+                "product_unit_id": {
+                    "type": "string"
+                },
+                "unit_quantity": {
+                    "type": "number"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.IncomeProductsResponse": {
+            "type": "object",
+            "properties": {
+                "count_of_income_products": {
+                    "type": "integer"
+                },
+                "income_products": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.IncomeProduct"
+                    }
+                }
+            }
+        },
+        "models.IncomeResponse": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "incomes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Income"
+                    }
+                }
+            }
+        },
+        "models.LabelProduct": {
+            "type": "object",
+            "properties": {
+                "copies": {
+                    "type": "integer"
+                },
+                "product_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.LabelRequest": {
+            "type": "object",
+            "properties": {
+                "branch_id": {
+                    "type": "string"
+                },
+                "format": {
+                    "type": "string"
+                },
+                "height": {
+                    "type": "number"
+                },
+                "income_id": {
+                    "type": "string"
+                },
+                "products": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.LabelProduct"
+                    }
+                },
+                "width": {
+                    "type": "number"
+                }
+            }
+        },
+        "models.LowStockItem": {
+            "type": "object",
+            "properties": {
+                "branch_id": {
+                    "type": "string"
+                },
+                "count": {
+                    "type": "number"
+                },
+                "min_count": {
+                    "type": "number"
+                },
+                "product_id": {
+                    "type": "string"
+                },
+                "product_name": {
+                    "type": "string"
+                },
+                "repository_id": {
+                    "type": "string"
+                },
+                "target_count": {
+                    "type": "number"
+                }
+            }
+        },
+        "models.LowStockResponse": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.LowStockItem"
+                    }
+                }
+            }
+        },
+        "models.MoveCategory": {
+            "type": "object",
+            "properties": {
+                "parent_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.MovementHistory": {
+            "type": "object",
+            "properties": {
+                "branch_id": {
+                    "type": "string"
+                },
+                "closing_balance": {
+                    "type": "number"
+                },
+                "from_date": {
+                    "type": "string"
+                },
+                "movements": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.MovementHistoryItem"
+                    }
+                },
+                "opening_balance": {
+                    "type": "number"
+                },
+                "product_id": {
+                    "type": "string"
+                },
+                "to_date": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.MovementHistoryItem": {
+            "type": "object",
+            "properties": {
+                "balance": {
+                    "type": "number"
+                },
+                "branch_id": {
+                    "type": "string"
+                },
+                "change": {
+                    "type": "number"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "price": {
+                    "type": "number"
+                },
+                "product_id": {
+                    "type": "string"
+                },
+                "quantity": {
+                    "type": "number"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "repository_transaction_type": {
+                    "type": "string"
+                },
+                "source_id": {
+                    "type": "string"
+                },
+                "source_type": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Product": {
+            "type": "object",
+            "properties": {
+                "attributes": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "barcode": {
+                    "type": "string"
+                },
+                "barcodes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ProductBarcode"
+                    }
+                },
+                "category_id": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "image_url": {
+                    "type": "string"
+                },
+                "images": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ProductImage"
                     }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/models.TransactionResponse"
-                        }
-                    },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "$ref": "#/definitions/models.Response"
-                        }
-                    },
-                    "404": {
-                        "description": "Not Found",
-                        "schema": {
-                            "$ref": "#/definitions/models.Response"
-                        }
-                    },
-                    "500": {
-                        "description": "Internal Server Error",
-                        "schema": {
-                            "$ref": "#/definitions/models.Response"
-                        }
+                },
+                "name": {
+                    "type": "string"
+                },
+                "name_translations": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "parent_id": {
+                    "type": "string"
+                },
+                "precision": {
+                    "type": "integer"
+                },
+                "price": {
+                    "type": "number"
+                },
+                "price_override": {
+                    "type": "number"
+                },
+                "thumbnail_url": {
+                    "type": "string"
+                },
+                "unit": {
+                    "type": "string"
+                },
+                "units": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ProductUnit"
+                    }
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "variants": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Product"
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "models.Barcode": {
+        },
+        "models.ProductBarcode": {
             "type": "object",
             "properties": {
                 "barcode": {
-                    "type": "integer"
+                    "type": "string"
                 },
-                "count": {
-                    "type": "integer"
+                "created_at": {
+                    "type": "string"
                 },
-                "sale_id": {
+                "id": {
+                    "type": "string"
+                },
+                "product_id": {
                     "type": "string"
+                },
+                "quantity": {
+                    "type": "integer"
                 }
             }
         },
-        "models.Basket": {
+        "models.ProductImage": {
             "type": "object",
             "properties": {
+                "content_type": {
+                    "type": "string"
+                },
                 "created_at": {
                     "type": "string"
                 },
+                "height": {
+                    "type": "integer"
+                },
                 "id": {
                     "type": "string"
                 },
-                "price": {
+                "position": {
                     "type": "integer"
                 },
                 "product_id": {
                     "type": "string"
                 },
-                "quantity": {
+                "size": {
                     "type": "integer"
                 },
-                "sale_id": {
+                "thumbnail_url": {
                     "type": "string"
                 },
-                "updated_at": {
+                "url": {
                     "type": "string"
+                },
+                "width": {
+                    "type": "integer"
                 }
             }
         },
-        "models.BasketsResponse": {
+        "models.ProductImportError": {
             "type": "object",
             "properties": {
-                "basket": {
+                "column": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "row": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ProductImportResponse": {
+            "type": "object",
+            "properties": {
+                "categories_created": {
+                    "type": "integer"
+                },
+                "created": {
+                    "type": "integer"
+                },
+                "dry_run": {
+                    "type": "boolean"
+                },
+                "errors": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/models.Basket"
+                        "$ref": "#/definitions/models.ProductImportError"
                     }
                 },
-                "count": {
+                "failed": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                },
+                "updated": {
                     "type": "integer"
                 }
             }
         },
-        "models.Branch": {
+        "models.ProductPrice": {
             "type": "object",
             "properties": {
-                "address": {
+                "branch_id": {
                     "type": "string"
                 },
                 "created_at": {
@@ -3504,385 +9208,595 @@ const docTemplate = `{
                 "id": {
                     "type": "string"
                 },
-                "name": {
+                "price": {
+                    "type": "number"
+                },
+                "product_id": {
                     "type": "string"
                 },
-                "updated_at": {
+                "starts_at": {
                     "type": "string"
                 }
             }
         },
-        "models.BranchResponse": {
+        "models.ProductPricesResponse": {
             "type": "object",
             "properties": {
-                "branches": {
+                "count": {
+                    "type": "integer"
+                },
+                "product_prices": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/models.Branch"
+                        "$ref": "#/definitions/models.ProductPrice"
                     }
+                }
+            }
+        },
+        "models.ProductResponse": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
                 },
+                "products": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Product"
+                    }
+                }
+            }
+        },
+        "models.ProductSearchResponse": {
+            "type": "object",
+            "properties": {
                 "count": {
                     "type": "integer"
+                },
+                "products": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ProductSearchResult"
+                    }
                 }
             }
         },
-        "models.Category": {
+        "models.ProductSearchResult": {
             "type": "object",
             "properties": {
+                "attributes": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "barcode": {
+                    "type": "string"
+                },
+                "barcodes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ProductBarcode"
+                    }
+                },
+                "category_id": {
+                    "type": "string"
+                },
+                "category_name": {
+                    "type": "string"
+                },
                 "created_at": {
                     "type": "string"
                 },
                 "id": {
                     "type": "string"
                 },
+                "image_url": {
+                    "type": "string"
+                },
+                "images": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ProductImage"
+                    }
+                },
+                "match": {
+                    "type": "string"
+                },
                 "name": {
                     "type": "string"
                 },
+                "name_translations": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
                 "parent_id": {
                     "type": "string"
                 },
+                "precision": {
+                    "type": "integer"
+                },
+                "price": {
+                    "type": "number"
+                },
+                "price_override": {
+                    "type": "number"
+                },
+                "rank": {
+                    "type": "number"
+                },
+                "stock": {
+                    "type": "number"
+                },
+                "thumbnail_url": {
+                    "type": "string"
+                },
+                "unit": {
+                    "type": "string"
+                },
+                "units": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ProductUnit"
+                    }
+                },
                 "updated_at": {
                     "type": "string"
+                },
+                "variants": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Product"
+                    }
                 }
             }
         },
-        "models.CategoryResponse": {
+        "models.ProductTaxRate": {
             "type": "object",
             "properties": {
-                "categories": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/models.Category"
-                    }
+                "name": {
+                    "type": "string"
                 },
-                "count": {
-                    "type": "integer"
+                "product_id": {
+                    "type": "string"
+                },
+                "rate": {
+                    "type": "number"
+                },
+                "source": {
+                    "type": "string"
+                },
+                "tax_rate_id": {
+                    "type": "string"
                 }
             }
         },
-        "models.CreateBasket": {
+        "models.ProductUnit": {
             "type": "object",
             "properties": {
-                "price": {
-                    "type": "integer"
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
                 },
                 "product_id": {
                     "type": "string"
                 },
-                "quantity": {
-                    "type": "integer"
+                "quantity": {
+                    "type": "number"
+                }
+            }
+        },
+        "models.PurchaseOrder": {
+            "type": "object",
+            "properties": {
+                "branch_id": {
+                    "type": "string"
+                },
+                "comment": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "currency": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "price": {
+                    "type": "number"
+                },
+                "products": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.PurchaseOrderProduct"
+                    }
                 },
-                "sale_id": {
+                "status": {
                     "type": "string"
-                }
-            }
-        },
-        "models.CreateBranch": {
-            "type": "object",
-            "properties": {
-                "address": {
+                },
+                "supplier_id": {
                     "type": "string"
                 },
-                "name": {
+                "updated_at": {
                     "type": "string"
                 }
             }
         },
-        "models.CreateCategory": {
+        "models.PurchaseOrderDiscrepancies": {
             "type": "object",
             "properties": {
-                "name": {
+                "count": {
+                    "type": "integer"
+                },
+                "discrepancies": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.PurchaseOrderDiscrepancy"
+                    }
+                },
+                "purchase_order_id": {
                     "type": "string"
                 },
-                "parent_id": {
+                "status": {
                     "type": "string"
                 }
             }
         },
-        "models.CreateIncome": {
+        "models.PurchaseOrderDiscrepancy": {
             "type": "object",
             "properties": {
-                "branch_id": {
+                "count_diff": {
+                    "type": "number"
+                },
+                "expected_price": {
+                    "type": "number"
+                },
+                "ordered_count": {
+                    "type": "number"
+                },
+                "price_diff": {
+                    "type": "number"
+                },
+                "product_id": {
                     "type": "string"
+                },
+                "received_count": {
+                    "type": "number"
+                },
+                "received_price": {
+                    "type": "number"
                 }
             }
         },
-        "models.CreateIncomeProduct": {
+        "models.PurchaseOrderProduct": {
             "type": "object",
             "properties": {
                 "count": {
-                    "type": "integer"
+                    "type": "number"
                 },
-                "income_id": {
+                "id": {
                     "type": "string"
                 },
                 "price": {
-                    "type": "integer"
+                    "type": "number"
                 },
                 "product_id": {
                     "type": "string"
-                }
-            }
-        },
-        "models.CreateProduct": {
-            "type": "object",
-            "properties": {
-                "barcode": {
-                    "type": "integer"
-                },
-                "category_id": {
-                    "type": "string"
                 },
-                "name": {
+                "purchase_order_id": {
                     "type": "string"
                 },
-                "price": {
-                    "type": "integer"
+                "received_count": {
+                    "type": "number"
                 }
             }
         },
-        "models.CreateRepository": {
+        "models.PurchaseOrderResponse": {
             "type": "object",
             "properties": {
-                "branch_id": {
-                    "type": "string"
-                },
                 "count": {
                     "type": "integer"
                 },
-                "product_id": {
-                    "type": "string"
+                "purchase_orders": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.PurchaseOrder"
+                    }
                 }
             }
         },
-        "models.CreateRepositoryTransaction": {
+        "models.Receipt": {
             "type": "object",
             "properties": {
-                "price": {
-                    "type": "integer"
+                "branch_id": {
+                    "type": "string"
                 },
-                "product_id": {
+                "branch_name": {
                     "type": "string"
                 },
-                "quantity": {
-                    "type": "integer"
+                "cashier_id": {
+                    "type": "string"
                 },
-                "repository_transaction_type": {
+                "change": {
+                    "type": "number"
+                },
+                "client_name": {
                     "type": "string"
                 },
-                "staff_id": {
+                "created_at": {
                     "type": "string"
-                }
-            }
-        },
-        "models.CreateSale": {
-            "type": "object",
-            "properties": {
-                "branch_id": {
+                },
+                "currency": {
                     "type": "string"
                 },
-                "cashier_id": {
+                "fiscal_number": {
                     "type": "string"
                 },
-                "client_name": {
+                "fiscal_sign": {
                     "type": "string"
                 },
+                "lines": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ReceiptLine"
+                    }
+                },
+                "net": {
+                    "type": "number"
+                },
+                "paid": {
+                    "type": "number"
+                },
                 "payment_type": {
                     "type": "string"
                 },
-                "price": {
-                    "type": "number"
+                "payments": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.SalePayment"
+                    }
                 },
-                "shop_assistant_id": {
+                "sale_id": {
                     "type": "string"
                 },
                 "status": {
                     "type": "string"
+                },
+                "tax": {
+                    "type": "number"
+                },
+                "tax_included": {
+                    "type": "boolean"
+                },
+                "taxes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ReceiptTax"
+                    }
+                },
+                "total": {
+                    "type": "number"
                 }
             }
         },
-        "models.CreateStaff": {
+        "models.ReceiptLine": {
             "type": "object",
             "properties": {
-                "balance": {
-                    "type": "integer"
-                },
-                "birth_date": {
+                "name": {
                     "type": "string"
                 },
-                "branch_id": {
-                    "type": "string"
+                "price": {
+                    "type": "number"
                 },
-                "login": {
+                "product_id": {
                     "type": "string"
                 },
-                "name": {
-                    "type": "string"
+                "quantity": {
+                    "type": "number"
                 },
-                "password": {
-                    "type": "string"
+                "tax": {
+                    "type": "number"
                 },
-                "staff_type": {
-                    "type": "string"
+                "tax_rate": {
+                    "type": "number"
                 },
-                "tariff_id": {
+                "total": {
+                    "type": "number"
+                },
+                "unit": {
                     "type": "string"
+                },
+                "unit_price": {
+                    "type": "number"
                 }
             }
         },
-        "models.CreateStaffTariff": {
+        "models.ReceiptTax": {
             "type": "object",
             "properties": {
-                "amount_for_card": {
-                    "type": "integer"
+                "net": {
+                    "type": "number"
                 },
-                "amount_for_cash": {
-                    "type": "integer"
+                "rate": {
+                    "type": "number"
                 },
-                "name": {
-                    "type": "string"
+                "tax": {
+                    "type": "number"
                 },
-                "tariff_type": {
-                    "type": "string"
+                "total": {
+                    "type": "number"
                 }
             }
         },
-        "models.CreateTransaction": {
+        "models.ReceivePurchaseOrder": {
             "type": "object",
             "properties": {
-                "amount": {
-                    "type": "number"
-                },
-                "description": {
-                    "type": "string"
-                },
-                "sale_id": {
-                    "type": "string"
+                "products": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.CreateIncomeProduct"
+                    }
+                }
+            }
+        },
+        "models.ReconcileResponse": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
                 },
-                "source_type": {
-                    "type": "string"
+                "discrepancies": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.StockDiscrepancy"
+                    }
                 },
-                "staff_id": {
-                    "type": "string"
+                "fixed": {
+                    "type": "boolean"
                 },
-                "transaction_type": {
-                    "type": "string"
+                "unassigned_movements": {
+                    "type": "integer"
                 }
             }
         },
-        "models.Income": {
+        "models.Refund": {
             "type": "object",
             "properties": {
-                "branch_id": {
+                "created_at": {
                     "type": "string"
                 },
-                "created_at": {
+                "fiscal_number": {
+                    "type": "string"
+                },
+                "fiscal_sign": {
+                    "type": "string"
+                },
+                "fiscalized_at": {
                     "type": "string"
                 },
                 "id": {
                     "type": "string"
                 },
                 "price": {
-                    "type": "integer"
+                    "type": "number"
                 },
-                "updated_at": {
+                "products": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.RefundProduct"
+                    }
+                },
+                "reason": {
                     "type": "string"
+                },
+                "sale_id": {
+                    "type": "string"
+                },
+                "tax_amount": {
+                    "type": "number"
                 }
             }
         },
-        "models.IncomeProduct": {
+        "models.RefundProduct": {
             "type": "object",
             "properties": {
-                "count": {
-                    "type": "integer"
-                },
-                "created_at": {
-                    "type": "string"
-                },
-                "deleted_at": {
+                "basket_id": {
                     "type": "string"
                 },
                 "id": {
                     "type": "string"
                 },
-                "income_id": {
-                    "type": "string"
-                },
                 "price": {
-                    "type": "integer"
+                    "type": "number"
                 },
                 "product_id": {
                     "type": "string"
                 },
-                "updated_at": {
+                "quantity": {
+                    "type": "number"
+                },
+                "refund_id": {
                     "type": "string"
-                }
-            }
-        },
-        "models.IncomeProductsResponse": {
-            "type": "object",
-            "properties": {
-                "count_of_income_products": {
-                    "type": "integer"
                 },
-                "income_products": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/models.IncomeProduct"
-                    }
+                "tax_amount": {
+                    "type": "number"
+                },
+                "tax_rate": {
+                    "type": "number"
                 }
             }
         },
-        "models.IncomeResponse": {
+        "models.RefundsResponse": {
             "type": "object",
             "properties": {
                 "count": {
                     "type": "integer"
                 },
-                "incomes": {
+                "refunds": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/models.Income"
+                        "$ref": "#/definitions/models.Refund"
                     }
                 }
             }
         },
-        "models.Product": {
+        "models.ReorderSuggestion": {
             "type": "object",
             "properties": {
-                "barcode": {
-                    "type": "integer"
+                "average_daily_sales": {
+                    "type": "number"
                 },
-                "category_id": {
+                "branch_id": {
                     "type": "string"
                 },
-                "created_at": {
+                "count": {
+                    "type": "number"
+                },
+                "min_count": {
+                    "type": "number"
+                },
+                "product_id": {
                     "type": "string"
                 },
-                "id": {
+                "product_name": {
                     "type": "string"
                 },
-                "name": {
+                "repository_id": {
                     "type": "string"
                 },
-                "price": {
-                    "type": "integer"
+                "suggested_quantity": {
+                    "type": "number"
                 },
-                "updated_at": {
-                    "type": "string"
+                "target_count": {
+                    "type": "number"
                 }
             }
         },
-        "models.ProductResponse": {
+        "models.ReorderSuggestionsResponse": {
             "type": "object",
             "properties": {
                 "count": {
                     "type": "integer"
                 },
-                "products": {
+                "cover_days": {
+                    "type": "integer"
+                },
+                "days": {
+                    "type": "integer"
+                },
+                "suggestions": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/models.Product"
+                        "$ref": "#/definitions/models.ReorderSuggestion"
                     }
                 }
             }
@@ -3908,7 +9822,7 @@ const docTemplate = `{
                     "type": "string"
                 },
                 "count": {
-                    "type": "integer"
+                    "type": "number"
                 },
                 "created_at": {
                     "type": "string"
@@ -3916,9 +9830,15 @@ const docTemplate = `{
                 "id": {
                     "type": "string"
                 },
+                "min_count": {
+                    "type": "number"
+                },
                 "product_id": {
                     "type": "string"
                 },
+                "target_count": {
+                    "type": "number"
+                },
                 "updated_at": {
                     "type": "string"
                 }
@@ -3927,6 +9847,9 @@ const docTemplate = `{
         "models.RepositoryTransaction": {
             "type": "object",
             "properties": {
+                "branch_id": {
+                    "type": "string"
+                },
                 "created_at": {
                     "type": "string"
                 },
@@ -3934,18 +9857,24 @@ const docTemplate = `{
                     "type": "string"
                 },
                 "price": {
-                    "type": "integer"
+                    "type": "number"
                 },
                 "product_id": {
                     "type": "string"
                 },
                 "quantity": {
-                    "type": "integer"
+                    "type": "number"
+                },
+                "reason": {
+                    "type": "string"
                 },
                 "repository_transaction_type": {
                     "type": "string"
                 },
-                "staff_id": {
+                "source_id": {
+                    "type": "string"
+                },
+                "source_type": {
                     "type": "string"
                 },
                 "updated_at": {
@@ -3994,6 +9923,21 @@ const docTemplate = `{
                 "created_at": {
                     "type": "string"
                 },
+                "currency": {
+                    "type": "string"
+                },
+                "finished_at": {
+                    "type": "string"
+                },
+                "fiscal_number": {
+                    "type": "string"
+                },
+                "fiscal_sign": {
+                    "type": "string"
+                },
+                "fiscalized_at": {
+                    "type": "string"
+                },
                 "id": {
                     "type": "string"
                 },
@@ -4009,11 +9953,72 @@ const docTemplate = `{
                 "status": {
                     "type": "string"
                 },
+                "tax_amount": {
+                    "type": "number"
+                },
+                "tax_included": {
+                    "type": "boolean"
+                },
                 "updated_at": {
                     "type": "string"
                 }
             }
         },
+        "models.SalePayment": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number"
+                },
+                "base_amount": {
+                    "type": "number"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "currency": {
+                    "type": "string"
+                },
+                "exchange_rate": {
+                    "type": "number"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "sale_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.SalePayments": {
+            "type": "object",
+            "properties": {
+                "change": {
+                    "type": "number"
+                },
+                "currency": {
+                    "type": "string"
+                },
+                "due": {
+                    "type": "number"
+                },
+                "paid": {
+                    "type": "number"
+                },
+                "payments": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.SalePayment"
+                    }
+                },
+                "sale_id": {
+                    "type": "string"
+                },
+                "total": {
+                    "type": "number"
+                }
+            }
+        },
         "models.SaleRequest": {
             "type": "object",
             "properties": {
@@ -4022,6 +10027,14 @@ const docTemplate = `{
                 }
             }
         },
+        "models.SetTaxRate": {
+            "type": "object",
+            "properties": {
+                "tax_rate_id": {
+                    "type": "string"
+                }
+            }
+        },
         "models.Staff": {
             "type": "object",
             "properties": {
@@ -4029,7 +10042,7 @@ const docTemplate = `{
                     "type": "integer"
                 },
                 "balance": {
-                    "type": "integer"
+                    "type": "number"
                 },
                 "birth_date": {
                     "type": "string"
@@ -4037,41 +10050,238 @@ const docTemplate = `{
                 "branch_id": {
                     "type": "string"
                 },
-                "created_at": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "login": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                },
+                "staff_type": {
+                    "type": "string"
+                },
+                "tariff_id": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.StaffTariff": {
+            "type": "object",
+            "properties": {
+                "amount_for_card": {
+                    "type": "number"
+                },
+                "amount_for_cash": {
+                    "type": "number"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "tariff_type": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.StaffTariffResponse": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "staff_tariffs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.StaffTariff"
+                    }
+                }
+            }
+        },
+        "models.StaffsResponse": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "staffs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Staff"
+                    }
+                }
+            }
+        },
+        "models.StockDiscrepancy": {
+            "type": "object",
+            "properties": {
+                "branch_id": {
+                    "type": "string"
+                },
+                "count": {
+                    "type": "number"
+                },
+                "difference": {
+                    "type": "number"
+                },
+                "expected_count": {
+                    "type": "number"
+                },
+                "product_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.StockSnapshot": {
+            "type": "object",
+            "properties": {
+                "at": {
+                    "type": "string"
+                },
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.StockSnapshotItem"
+                    }
+                },
+                "total_quantity": {
+                    "type": "number"
+                },
+                "total_value": {
+                    "type": "number"
+                }
+            }
+        },
+        "models.StockSnapshotItem": {
+            "type": "object",
+            "properties": {
+                "branch_id": {
+                    "type": "string"
+                },
+                "branch_name": {
+                    "type": "string"
+                },
+                "product_id": {
+                    "type": "string"
+                },
+                "product_name": {
+                    "type": "string"
+                },
+                "quantity": {
+                    "type": "number"
+                },
+                "unit_cost": {
+                    "type": "number"
+                },
+                "value": {
+                    "type": "number"
+                }
+            }
+        },
+        "models.Supplier": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "type": "string"
+                },
+                "balance": {
+                    "type": "number"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "phone": {
                     "type": "string"
                 },
-                "id": {
+                "updated_at": {
                     "type": "string"
+                }
+            }
+        },
+        "models.SupplierPayment": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number"
                 },
-                "login": {
-                    "type": "string"
+                "base_amount": {
+                    "type": "number"
                 },
-                "name": {
+                "comment": {
                     "type": "string"
                 },
-                "password": {
+                "created_at": {
                     "type": "string"
                 },
-                "staff_type": {
+                "currency": {
                     "type": "string"
                 },
-                "tariff_id": {
+                "exchange_rate": {
+                    "type": "number"
+                },
+                "id": {
                     "type": "string"
                 },
-                "updated_at": {
+                "supplier_id": {
                     "type": "string"
                 }
             }
         },
-        "models.StaffTariff": {
+        "models.SupplierPaymentResponse": {
             "type": "object",
             "properties": {
-                "amount_for_card": {
+                "count": {
                     "type": "integer"
                 },
-                "amount_for_cash": {
+                "payments": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.SupplierPayment"
+                    }
+                }
+            }
+        },
+        "models.SupplierResponse": {
+            "type": "object",
+            "properties": {
+                "count": {
                     "type": "integer"
                 },
+                "suppliers": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Supplier"
+                    }
+                }
+            }
+        },
+        "models.TaxRate": {
+            "type": "object",
+            "properties": {
                 "created_at": {
                     "type": "string"
                 },
@@ -4081,39 +10291,71 @@ const docTemplate = `{
                 "name": {
                     "type": "string"
                 },
-                "tariff_type": {
-                    "type": "string"
+                "rate": {
+                    "type": "number"
                 },
                 "updated_at": {
                     "type": "string"
                 }
             }
         },
-        "models.StaffTariffResponse": {
+        "models.TaxRatesResponse": {
             "type": "object",
             "properties": {
                 "count": {
                     "type": "integer"
                 },
-                "staff_tariffs": {
+                "tax_rates": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/models.StaffTariff"
+                        "$ref": "#/definitions/models.TaxRate"
                     }
                 }
             }
         },
-        "models.StaffsResponse": {
+        "models.TaxReport": {
             "type": "object",
             "properties": {
-                "count": {
-                    "type": "integer"
-                },
-                "staffs": {
+                "rows": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/models.Staff"
+                        "$ref": "#/definitions/models.TaxReportRow"
                     }
+                },
+                "total": {
+                    "type": "number"
+                },
+                "total_net": {
+                    "type": "number"
+                },
+                "total_tax": {
+                    "type": "number"
+                }
+            }
+        },
+        "models.TaxReportRow": {
+            "type": "object",
+            "properties": {
+                "branch_id": {
+                    "type": "string"
+                },
+                "branch_name": {
+                    "type": "string"
+                },
+                "net": {
+                    "type": "number"
+                },
+                "sales": {
+                    "type": "integer"
+                },
+                "tax": {
+                    "type": "number"
+                },
+                "tax_rate": {
+                    "type": "number"
+                },
+                "total": {
+                    "type": "number"
                 }
             }
         },
@@ -4167,13 +10409,13 @@ const docTemplate = `{
             "type": "object",
             "properties": {
                 "price": {
-                    "type": "integer"
+                    "type": "number"
                 },
                 "product_id": {
                     "type": "string"
                 },
                 "quantity": {
-                    "type": "integer"
+                    "type": "number"
                 },
                 "sale_id": {
                     "type": "string"
@@ -4197,6 +10439,12 @@ const docTemplate = `{
                 "name": {
                     "type": "string"
                 },
+                "name_translations": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
                 "parent_id": {
                     "type": "string"
                 }
@@ -4208,39 +10456,69 @@ const docTemplate = `{
                 "branch_id": {
                     "type": "string"
                 },
-                "price": {
-                    "type": "integer"
+                "currency": {
+                    "type": "string"
+                },
+                "supplier_id": {
+                    "type": "string"
                 }
             }
         },
         "models.UpdateIncomeProduct": {
             "type": "object",
             "properties": {
+                "batch_number": {
+                    "type": "string"
+                },
                 "count": {
-                    "type": "integer"
+                    "type": "number"
+                },
+                "expiry_date": {
+                    "type": "string"
                 },
                 "income_id": {
                     "type": "string"
                 },
                 "price": {
-                    "type": "integer"
+                    "type": "number"
                 },
                 "product_id": {
                     "type": "string"
+                },
+                "product_unit_id": {
+                    "type": "string"
                 }
             }
         },
         "models.UpdateProduct": {
             "type": "object",
             "properties": {
+                "attributes": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
                 "category_id": {
                     "type": "string"
                 },
                 "name": {
                     "type": "string"
                 },
-                "price": {
+                "name_translations": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "precision": {
                     "type": "integer"
+                },
+                "price": {
+                    "type": "number"
+                },
+                "unit": {
+                    "type": "string"
                 }
             }
         },
@@ -4251,29 +10529,49 @@ const docTemplate = `{
                     "type": "string"
                 },
                 "count": {
-                    "type": "integer"
+                    "type": "number"
                 },
                 "product_id": {
                     "type": "string"
                 }
             }
         },
+        "models.UpdateRepositoryLevels": {
+            "type": "object",
+            "properties": {
+                "min_count": {
+                    "type": "number"
+                },
+                "target_count": {
+                    "type": "number"
+                }
+            }
+        },
         "models.UpdateRepositoryTransaction": {
             "type": "object",
             "properties": {
+                "branch_id": {
+                    "type": "string"
+                },
                 "price": {
-                    "type": "integer"
+                    "type": "number"
                 },
                 "product_id": {
                     "type": "string"
                 },
                 "quantity": {
-                    "type": "integer"
+                    "type": "number"
+                },
+                "reason": {
+                    "type": "string"
                 },
                 "repository_transaction_type": {
                     "type": "string"
                 },
-                "staff_id": {
+                "source_id": {
+                    "type": "string"
+                },
+                "source_type": {
                     "type": "string"
                 }
             }
@@ -4308,7 +10606,7 @@ const docTemplate = `{
             "type": "object",
             "properties": {
                 "balance": {
-                    "type": "integer"
+                    "type": "number"
                 },
                 "branch_id": {
                     "type": "string"
@@ -4342,10 +10640,10 @@ const docTemplate = `{
             "type": "object",
             "properties": {
                 "amount_for_card": {
-                    "type": "integer"
+                    "type": "number"
                 },
                 "amount_for_cash": {
-                    "type": "integer"
+                    "type": "number"
                 },
                 "name": {
                     "type": "string"
@@ -4355,6 +10653,31 @@ const docTemplate = `{
                 }
             }
         },
+        "models.UpdateSupplier": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "phone": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.UpdateTaxRate": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string"
+                },
+                "rate": {
+                    "type": "number"
+                }
+            }
+        },
         "models.UpdateTransaction": {
             "type": "object",
             "properties": {
@@ -4377,6 +10700,121 @@ const docTemplate = `{
                     "type": "string"
                 }
             }
+        },
+        "models.WriteOff": {
+            "type": "object",
+            "properties": {
+                "approved_at": {
+                    "type": "string"
+                },
+                "approved_by": {
+                    "type": "string"
+                },
+                "branch_id": {
+                    "type": "string"
+                },
+                "comment": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "price": {
+                    "type": "number"
+                },
+                "products": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.WriteOffProduct"
+                    }
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.WriteOffProduct": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "price": {
+                    "type": "number"
+                },
+                "product_id": {
+                    "type": "string"
+                },
+                "quantity": {
+                    "type": "number"
+                },
+                "write_off_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.WriteOffReport": {
+            "type": "object",
+            "properties": {
+                "reasons": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.WriteOffReportRow"
+                    }
+                },
+                "total_price": {
+                    "type": "number"
+                },
+                "total_quantity": {
+                    "type": "number"
+                }
+            }
+        },
+        "models.WriteOffReportRow": {
+            "type": "object",
+            "properties": {
+                "documents": {
+                    "type": "integer"
+                },
+                "price": {
+                    "type": "number"
+                },
+                "quantity": {
+                    "type": "number"
+                },
+                "reason": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.WriteOffResponse": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "write_offs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.WriteOff"
+                    }
+                }
+            }
         }
     }
 }`
@@ -0,0 +1,102 @@
+package handler
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"sell/api/models"
+)
+
+// CreateBarcodeFormat godoc
+// @Router       /barcode-format [POST]
+// @Summary      Create a new barcode format
+// @Description  register an in-store barcode format of scales, e.g. prefix 22, product code at 2 with length 5, weight at 7 with length 5 and 3 decimals
+// @Tags         barcode
+// @Accept       json
+// @Produce      json
+// @Param 		 barcode-format body models.CreateBarcodeFormat true "barcode-format"
+// @Success      200  {object}  models.BarcodeFormat
+// @Failure      400  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) CreateBarcodeFormat(c *gin.Context) {
+	format := models.CreateBarcodeFormat{}
+	if err := c.ShouldBindJSON(&format); err != nil {
+		handleResponse(c, "error is while reading body", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if format.CodeLength == 0 {
+		format.CodeLength = 13
+	}
+
+	if format.ValueType != "weight" && format.ValueType != "price" {
+		handleResponse(c, "invalid value type", http.StatusBadRequest, "value_type should be weight or price")
+		return
+	}
+
+	if format.Prefix == "" || len(format.Prefix) > 5 ||
+		format.ProductCodeStart < len(format.Prefix) || format.ProductCodeLength <= 0 ||
+		format.ValueStart < len(format.Prefix) || format.ValueLength <= 0 || format.ValueDecimals < 0 ||
+		format.ProductCodeStart+format.ProductCodeLength > format.CodeLength ||
+		format.ValueStart+format.ValueLength > format.CodeLength {
+		handleResponse(c, "invalid barcode format", http.StatusBadRequest, "product code and value should be inside the code after the prefix")
+		return
+	}
+
+	id, err := h.storage.BarcodeFormat().Create(context.Background(), format)
+	if err != nil {
+		handleResponse(c, "error is while creating barcode format", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	createdFormat, err := h.storage.BarcodeFormat().GetByID(context.Background(), id)
+	if err != nil {
+		handleResponse(c, "error is while getting by id", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	handleResponse(c, "", http.StatusCreated, createdFormat)
+}
+
+// GetBarcodeFormatList godoc
+// @Router       /barcode-formats [GET]
+// @Summary      Get barcode formats
+// @Description  get in-store barcode formats
+// @Tags         barcode
+// @Accept       json
+// @Produce      json
+// @Success      200  {object}  models.BarcodeFormatsResponse
+// @Failure      400  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) GetBarcodeFormatList(c *gin.Context) {
+	formats, err := h.storage.BarcodeFormat().GetList(context.Background())
+	if err != nil {
+		handleResponse(c, "error is while getting barcode formats", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	handleResponse(c, "", http.StatusOK, formats)
+}
+
+// DeleteBarcodeFormat godoc
+// @Router       /barcode-format/{id} [DELETE]
+// @Summary      Delete barcode format
+// @Description  delete barcode format
+// @Tags         barcode
+// @Accept       json
+// @Produce      json
+// @Param 		 id path string true "barcode_format_id"
+// @Success      200  {object}  models.Response
+// @Failure      400  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) DeleteBarcodeFormat(c *gin.Context) {
+	if err := h.storage.BarcodeFormat().Delete(context.Background(), c.Param("id")); err != nil {
+		handleResponse(c, "error is while deleting barcode format", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	handleResponse(c, "", http.StatusOK, "barcode format deleted!")
+}
@@ -28,85 +28,13 @@ func (h Handler) CreateBasket(c *gin.Context) {
 		return
 	}
 
-	product, err := h.storage.Product().GetByID(context.Background(), basket.ProductID)
+	updatedBasket, err := h.service.AddItem(context.Background(), basket)
 	if err != nil {
-		handleResponse(c, "error is while getting product by id", http.StatusInternalServerError, err.Error())
-	}
-
-	totalSum := product.Price * basket.Quantity
-
-	repo, err := h.storage.Repository().GetList(context.Background(), models.GetListRequest{
-		Page:   1,
-		Limit:  10,
-		Search: basket.ProductID,
-	})
-	if err != nil {
-		handleResponse(c, "error while getting repo", http.StatusInternalServerError, err.Error())
+		handleServiceError(c, "error is while adding product to basket", err)
 		return
 	}
 
-	for _, repository := range repo.Repositories {
-		if repository.Count < basket.Quantity {
-			handleResponse(c, "not enough product", 300, "not enough product")
-			return
-		}
-	}
-
-	baskets, err := h.storage.Basket().GetList(context.Background(), models.GetListRequest{
-		Page:   1,
-		Limit:  10,
-		Search: basket.SaleID,
-	})
-
-	isTrue := false
-
-	for _, value := range baskets.Baskets {
-		if basket.ProductID == value.ProductID {
-			for _, repository := range repo.Repositories {
-				if repository.Count < basket.Quantity+value.Quantity {
-					handleResponse(c, "not enough product", 300, "not enough product")
-					return
-				}
-			}
-			isTrue = true
-			// Update
-			id, err := h.storage.Basket().Update(context.Background(), models.UpdateBasket{
-				ID:        value.ID,
-				SaleID:    value.SaleID,
-				ProductID: value.ProductID,
-				Quantity:  value.Quantity + basket.Quantity,
-				Price:     value.Price + totalSum,
-			})
-			if err != nil {
-				handleResponse(c, "error is while updating basket", http.StatusInternalServerError, err.Error())
-				return
-			}
-			updatedBasket, err := h.storage.Basket().GetByID(context.Background(), models.PrimaryKey{ID: id})
-			if err != nil {
-				handleResponse(c, "error is while getting basket by id", http.StatusInternalServerError, err.Error())
-				return
-			}
-			handleResponse(c, "updated", http.StatusOK, updatedBasket)
-		}
-
-	}
-
-	if !isTrue {
-		//Create
-		basket.Price = totalSum
-		id, err := h.storage.Basket().Create(context.Background(), basket)
-		if err != nil {
-			handleResponse(c, "error while creating basket", http.StatusInternalServerError, err.Error())
-			return
-		}
-		createdBasket, err := h.storage.Basket().GetByID(context.Background(), models.PrimaryKey{ID: id})
-		if err != nil {
-			handleResponse(c, "error is while getting basket by id", http.StatusInternalServerError, err.Error())
-			return
-		}
-		handleResponse(c, "created", http.StatusOK, createdBasket)
-	}
-
+	handleResponse(c, "updated", http.StatusOK, updatedBasket)
 }
 
 // GetBasket godoc
@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"sell/api/models"
+	"strconv"
+)
+
+// GetBatchList godoc
+// @Router       /batches [GET]
+// @Summary      Get batch list
+// @Description  get batches with stock left ordered by expiry date
+// @Tags         batch
+// @Accept       json
+// @Produce      json
+// @Param 		 page query string false "page"
+// @Param 		 limit query string false "limit"
+// @Param 		 branch_id query string false "branch_id"
+// @Param 		 product_id query string false "product_id"
+// @Success      200  {object}  models.BatchResponse
+// @Failure      400  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) GetBatchList(c *gin.Context) {
+	var (
+		page, limit int
+		err         error
+	)
+
+	pageStr := c.DefaultQuery("page", "1")
+	page, err = strconv.Atoi(pageStr)
+	if err != nil {
+		handleResponse(c, "error is while converting page", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	limitStr := c.DefaultQuery("limit", "10")
+	limit, err = strconv.Atoi(limitStr)
+	if err != nil {
+		handleResponse(c, "error is while converting limit", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	resp, err := h.storage.Batch().GetList(context.Background(), models.BatchGetListRequest{
+		Page:      page,
+		Limit:     limit,
+		BranchID:  c.Query("branch_id"),
+		ProductID: c.Query("product_id"),
+	})
+	if err != nil {
+		handleResponse(c, "error is while getting batch list", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	handleResponse(c, "success", http.StatusOK, resp)
+}
+
+// GetExpiringBatches godoc
+// @Router       /batches/expiring [GET]
+// @Summary      Get expiring batches
+// @Description  get batches of a branch expiring within the given number of days
+// @Tags         batch
+// @Accept       json
+// @Produce      json
+// @Param 		 branch_id query string false "branch_id"
+// @Param 		 days query string false "days"
+// @Success      200  {object}  models.ExpiringBatchesResponse
+// @Failure      400  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) GetExpiringBatches(c *gin.Context) {
+	days, err := strconv.Atoi(c.DefaultQuery("days", "7"))
+	if err != nil {
+		handleResponse(c, "error is while converting days", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	resp, err := h.storage.Batch().GetExpiring(context.Background(), models.ExpiringBatchesRequest{
+		BranchID: c.Query("branch_id"),
+		Days:     days,
+	})
+	if err != nil {
+		handleResponse(c, "error is while getting expiring batches", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	handleResponse(c, "success", http.StatusOK, resp)
+}
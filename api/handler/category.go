@@ -2,9 +2,12 @@ package handler
 
 import (
 	"context"
+	"errors"
 	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
 	"net/http"
 	"sell/api/models"
+	"sell/storage"
 	"strconv"
 )
 
@@ -27,6 +30,11 @@ func (h Handler) CreateCategory(c *gin.Context) {
 		return
 	}
 
+	if err := h.validateTranslations(category.NameTranslations, 30); err != nil {
+		handleResponse(c, "invalid name translations", http.StatusBadRequest, err.Error())
+		return
+	}
+
 	id, err := h.storage.Category().Create(c.Request.Context(), category)
 	if err != nil {
 		handleResponse(c, "error is while creating category", http.StatusInternalServerError, err.Error())
@@ -50,6 +58,7 @@ func (h Handler) CreateCategory(c *gin.Context) {
 // @Accept       json
 // @Produce      json
 // @Param 		 id path string true "category_id"
+// @Param 		 Accept-Language header string false "language"
 // @Success      200  {object}  models.Category
 // @Failure      400  {object}  models.Response
 // @Failure      404  {object}  models.Response
@@ -62,6 +71,8 @@ func (h Handler) GetCategory(c *gin.Context) {
 		return
 	}
 
+	category.Name = translate(category.Name, category.NameTranslations, h.language(c))
+
 	handleResponse(c, "", http.StatusOK, category)
 }
 
@@ -75,6 +86,7 @@ func (h Handler) GetCategory(c *gin.Context) {
 // @Param 		 page query string false "page"
 // @Param 		 limit query string false "limit"
 // @Param 		 search query string false "search"
+// @Param 		 Accept-Language header string false "language"
 // @Success      200  {object}  models.CategoryResponse
 // @Failure      400  {object}  models.Response
 // @Failure      404  {object}  models.Response
@@ -111,6 +123,11 @@ func (h Handler) GetCategoryList(c *gin.Context) {
 		return
 	}
 
+	language := h.language(c)
+	for i, category := range categories.Categories {
+		categories.Categories[i].Name = translate(category.Name, category.NameTranslations, language)
+	}
+
 	handleResponse(c, "", http.StatusOK, categories)
 }
 
@@ -136,10 +153,23 @@ func (h Handler) UpdateCategory(c *gin.Context) {
 		return
 	}
 
+	if err := h.validateTranslations(category.NameTranslations, 30); err != nil {
+		handleResponse(c, "invalid name translations", http.StatusBadRequest, err.Error())
+		return
+	}
+
 	category.ID = uid
 
 	id, err := h.storage.Category().Update(context.Background(), category)
 	if err != nil {
+		if errors.Is(err, storage.ErrCategoryCycle) {
+			handleResponse(c, "invalid parent", http.StatusBadRequest, err.Error())
+			return
+		}
+		if errors.Is(err, pgx.ErrNoRows) {
+			handleResponse(c, "parent category not found", http.StatusNotFound, err.Error())
+			return
+		}
 		handleResponse(c, "error is while updating category", http.StatusInternalServerError, err.Error())
 		return
 	}
@@ -156,21 +186,183 @@ func (h Handler) UpdateCategory(c *gin.Context) {
 // DeleteCategory godoc
 // @Router       /category/{id} [DELETE]
 // @Summary      Delete category
-// @Description  delete category
+// @Description  delete category. mode refuse (default) keeps a category which has subcategories or products,
+// @Description  cascade deletes the subtree with all its products, reparent moves subcategories and products to the parent category
 // @Tags         category
 // @Accept       json
 // @Produce      json
 // @Param 		 id path string true "category_id"
+// @Param 		 mode query string false "refuse, cascade or reparent"
 // @Success      200  {object}  models.Response
 // @Failure      400  {object}  models.Response
 // @Failure      404  {object}  models.Response
 // @Failure      500  {object}  models.Response
 func (h Handler) DeleteCategory(c *gin.Context) {
 	uid := c.Param("id")
-	if err := h.storage.Category().Delete(context.Background(), uid); err != nil {
+
+	mode := c.DefaultQuery("mode", "refuse")
+	if mode != "refuse" && mode != "cascade" && mode != "reparent" {
+		handleResponse(c, "invalid mode", http.StatusBadRequest, "mode should be refuse, cascade or reparent")
+		return
+	}
+
+	if err := h.storage.Category().Delete(context.Background(), uid, mode); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			handleResponse(c, "category not found", http.StatusNotFound, err.Error())
+			return
+		}
+		if errors.Is(err, storage.ErrCategoryNotEmpty) {
+			handleResponse(c, "category is not empty", http.StatusBadRequest, err.Error())
+			return
+		}
 		handleResponse(c, "error is while deleting", http.StatusInternalServerError, err.Error())
 		return
 	}
 
 	handleResponse(c, "", http.StatusOK, "category deleted!")
 }
+
+// GetCategoryTree godoc
+// @Router       /category/tree [GET]
+// @Summary      Get category tree
+// @Description  get all root categories with their subcategories, product_count counts products of the category itself
+// @Description  and total_product_count includes the products of all subcategories
+// @Tags         category
+// @Accept       json
+// @Produce      json
+// @Param 		 Accept-Language header string false "language"
+// @Success      200  {array}   models.CategoryTree
+// @Failure      400  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) GetCategoryTree(c *gin.Context) {
+	tree, err := h.storage.Category().Tree(context.Background(), "")
+	if err != nil {
+		handleResponse(c, "error is while getting category tree", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	localizeCategoryTree(tree, h.language(c))
+
+	handleResponse(c, "", http.StatusOK, tree)
+}
+
+// GetCategorySubtree godoc
+// @Router       /category/{id}/tree [GET]
+// @Summary      Get category subtree
+// @Description  get the category with all its subcategories and product counts
+// @Tags         category
+// @Accept       json
+// @Produce      json
+// @Param 		 id path string true "category_id"
+// @Param 		 Accept-Language header string false "language"
+// @Success      200  {object}  models.CategoryTree
+// @Failure      400  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) GetCategorySubtree(c *gin.Context) {
+	tree, err := h.storage.Category().Tree(context.Background(), c.Param("id"))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			handleResponse(c, "category not found", http.StatusNotFound, err.Error())
+			return
+		}
+		handleResponse(c, "error is while getting category tree", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	localizeCategoryTree(tree, h.language(c))
+
+	handleResponse(c, "", http.StatusOK, tree[0])
+}
+
+// MoveCategory godoc
+// @Router       /category/{id}/move [PUT]
+// @Summary      Move category
+// @Description  move the category with all its subcategories under parent_id, an empty parent_id makes it a root category
+// @Tags         category
+// @Accept       json
+// @Produce      json
+// @Param 		 id path string true "category_id"
+// @Param 		 category body models.MoveCategory true "parent"
+// @Success      200  {object}  models.CategoryTree
+// @Failure      400  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) MoveCategory(c *gin.Context) {
+	move := models.MoveCategory{}
+	if err := c.ShouldBindJSON(&move); err != nil {
+		handleResponse(c, "error is while reading body", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	id := c.Param("id")
+	if err := h.storage.Category().Move(context.Background(), id, move.ParentID); err != nil {
+		if errors.Is(err, storage.ErrCategoryCycle) {
+			handleResponse(c, "invalid parent", http.StatusBadRequest, err.Error())
+			return
+		}
+		if errors.Is(err, pgx.ErrNoRows) {
+			handleResponse(c, "category not found", http.StatusNotFound, "category or parent category not found")
+			return
+		}
+		handleResponse(c, "error is while moving category", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	tree, err := h.storage.Category().Tree(context.Background(), id)
+	if err != nil {
+		handleResponse(c, "error is while getting category tree", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	handleResponse(c, "", http.StatusOK, tree[0])
+}
+
+// GetCategoryProducts godoc
+// @Router       /category/{id}/products [GET]
+// @Summary      Get category products
+// @Description  get products of the category and of all its subcategories
+// @Tags         category
+// @Accept       json
+// @Produce      json
+// @Param 		 id path string true "category_id"
+// @Param 		 page query string false "page"
+// @Param 		 limit query string false "limit"
+// @Param 		 search query string false "search"
+// @Param 		 Accept-Language header string false "language"
+// @Success      200  {object}  models.ProductResponse
+// @Failure      400  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) GetCategoryProducts(c *gin.Context) {
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil {
+		handleResponse(c, "error is while converting page", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil {
+		handleResponse(c, "error is while converting limit", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	products, err := h.storage.Product().GetList(context.Background(), models.ProductGetListRequest{
+		Page:       page,
+		Limit:      limit,
+		Name:       c.Query("search"),
+		CategoryID: c.Param("id"),
+	})
+	if err != nil {
+		handleResponse(c, "error is while getting list", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	language := h.language(c)
+	for i := range products.Products {
+		localizeProduct(&products.Products[i], language)
+	}
+
+	handleResponse(c, "", http.StatusOK, products)
+}
@@ -0,0 +1,216 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"net/http"
+	"sell/api/models"
+	"sell/pkg/money"
+	"sell/service"
+	"sell/storage"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CreateExchangeRate godoc
+// @Router       /exchange-rate [POST]
+// @Summary      Schedule exchange rate
+// @Description  set how many units of the base currency one unit of currency costs. The rate starts at starts_at
+// @Description  (RFC3339, in the future) or now when starts_at is empty, earlier rates are kept as history
+// @Tags         exchange rate
+// @Accept       json
+// @Produce      json
+// @Param 		 rate body models.CreateExchangeRate true "rate"
+// @Success      201  {object}  models.ExchangeRatesResponse
+// @Failure      400  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) CreateExchangeRate(c *gin.Context) {
+	rate := models.CreateExchangeRate{}
+	if err := c.ShouldBindJSON(&rate); err != nil {
+		handleResponse(c, "error is while reading body", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	currency, err := h.service.Currency(rate.Currency)
+	if err != nil {
+		handleResponse(c, "invalid currency", http.StatusBadRequest, err.Error())
+		return
+	}
+	if currency == h.cfg.BaseCurrency {
+		handleResponse(c, "invalid currency", http.StatusBadRequest, "the base currency always has the rate 1")
+		return
+	}
+	rate.Currency = currency
+
+	if rate.Rate <= 0 {
+		handleResponse(c, "invalid rate", http.StatusBadRequest, "rate should be positive")
+		return
+	}
+
+	if rate.StartsAt != "" {
+		startsAt, err := time.Parse(time.RFC3339, rate.StartsAt)
+		if err != nil {
+			handleResponse(c, "error while parsing starts_at", http.StatusBadRequest, err.Error())
+			return
+		}
+		if !startsAt.After(time.Now()) {
+			handleResponse(c, "invalid starts_at", http.StatusBadRequest, "starts_at should be in the future")
+			return
+		}
+		rate.StartsAt = startsAt.Local().Format("2006-01-02 15:04:05.999999")
+	}
+
+	if _, err := h.storage.ExchangeRate().Create(context.Background(), rate); err != nil {
+		handleResponse(c, "error is while creating exchange rate", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	rates, err := h.storage.ExchangeRate().GetList(context.Background(), models.ExchangeRateGetListRequest{
+		Page:     1,
+		Limit:    10,
+		Currency: rate.Currency,
+	})
+	if err != nil {
+		handleResponse(c, "error is while getting exchange rates", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	handleResponse(c, "", http.StatusCreated, rates)
+}
+
+// GetExchangeRateList godoc
+// @Router       /exchange-rates [GET]
+// @Summary      Get exchange rate history
+// @Description  get started and scheduled rates, latest first, of all currencies or of currency only
+// @Tags         exchange rate
+// @Accept       json
+// @Produce      json
+// @Param 		 page query string false "page"
+// @Param 		 limit query string false "limit"
+// @Param 		 currency query string false "currency"
+// @Success      200  {object}  models.ExchangeRatesResponse
+// @Failure      400  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) GetExchangeRateList(c *gin.Context) {
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil {
+		handleResponse(c, "error is while converting page", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil {
+		handleResponse(c, "error is while converting limit", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	rates, err := h.storage.ExchangeRate().GetList(context.Background(), models.ExchangeRateGetListRequest{
+		Page:     page,
+		Limit:    limit,
+		Currency: strings.ToUpper(c.Query("currency")),
+	})
+	if err != nil {
+		handleResponse(c, "error is while getting exchange rates", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	handleResponse(c, "", http.StatusOK, rates)
+}
+
+// GetExchangeRate godoc
+// @Router       /exchange-rate/{currency} [GET]
+// @Summary      Convert to the base currency
+// @Description  get the rate of the currency at the moment at (RFC3339, now by default) and amount converted
+// @Description  to the base currency at that rate, amounts are rounded to the hundredth half away from zero
+// @Tags         exchange rate
+// @Accept       json
+// @Produce      json
+// @Param 		 currency path string true "currency"
+// @Param 		 at query string false "at"
+// @Param 		 amount query string false "amount"
+// @Success      200  {object}  models.Conversion
+// @Failure      400  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) GetExchangeRate(c *gin.Context) {
+	currency, err := h.service.Currency(c.Param("currency"))
+	if err != nil {
+		handleResponse(c, "invalid currency", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	at := time.Now()
+	if atStr := c.Query("at"); atStr != "" {
+		if at, err = time.Parse(time.RFC3339, atStr); err != nil {
+			handleResponse(c, "error while parsing at", http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	amount := money.Unit
+	if amountStr := c.Query("amount"); amountStr != "" {
+		if amount, err = money.Parse(amountStr); err != nil {
+			handleResponse(c, "error while parsing amount", http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	rate, err := h.service.ExchangeRate(context.Background(), currency, at.Local())
+	if err != nil {
+		if errors.Is(err, service.ErrNoExchangeRate) {
+			handleResponse(c, "exchange rate not found", http.StatusNotFound, err.Error())
+			return
+		}
+		handleResponse(c, "error is while getting exchange rate", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	baseAmount, err := amount.Convert(rate)
+	if err != nil {
+		handleResponse(c, "error while converting amount", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	handleResponse(c, "", http.StatusOK, models.Conversion{
+		Currency:     currency,
+		BaseCurrency: h.cfg.BaseCurrency,
+		At:           at,
+		Rate:         rate,
+		Amount:       amount,
+		BaseAmount:   baseAmount,
+	})
+}
+
+// DeleteExchangeRate godoc
+// @Router       /exchange-rate/{id} [DELETE]
+// @Summary      Cancel scheduled exchange rate
+// @Description  cancel a rate which has not started yet, started rates stay in the history
+// @Tags         exchange rate
+// @Accept       json
+// @Produce      json
+// @Param 		 id path string true "exchange_rate_id"
+// @Success      200  {object}  models.Response
+// @Failure      400  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) DeleteExchangeRate(c *gin.Context) {
+	if err := h.storage.ExchangeRate().Delete(context.Background(), c.Param("id")); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			handleResponse(c, "exchange rate not found", http.StatusNotFound, err.Error())
+			return
+		}
+		if errors.Is(err, storage.ErrRateStarted) {
+			handleResponse(c, "exchange rate started", http.StatusBadRequest, err.Error())
+			return
+		}
+		handleResponse(c, "error is while deleting exchange rate", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	handleResponse(c, "", http.StatusOK, "exchange rate cancelled")
+}
@@ -0,0 +1,89 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"net/http"
+	"sell/api/models"
+	"sell/storage"
+	"strconv"
+)
+
+// GetFiscalOutboxList godoc
+// @Router       /fiscal-outbox [GET]
+// @Summary      Get fiscal outbox
+// @Description  get sales and refunds waiting to be registered with the fiscal module (pending), registered (sent)
+// @Description  or given up after FISCAL_MAX_ATTEMPTS attempts (failed), latest first
+// @Tags         fiscal
+// @Accept       json
+// @Produce      json
+// @Param 		 page query string false "page"
+// @Param 		 limit query string false "limit"
+// @Param 		 status query string false "pending, sent or failed"
+// @Success      200  {object}  models.FiscalEntriesResponse
+// @Failure      400  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) GetFiscalOutboxList(c *gin.Context) {
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil {
+		handleResponse(c, "error is while converting page", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil {
+		handleResponse(c, "error is while converting limit", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	status := c.Query("status")
+	if status != "" && status != "pending" && status != "sent" && status != "failed" {
+		handleResponse(c, "invalid status", http.StatusBadRequest, "status should be pending, sent or failed")
+		return
+	}
+
+	entries, err := h.storage.Fiscal().GetList(context.Background(), models.FiscalEntryGetListRequest{
+		Page:   page,
+		Limit:  limit,
+		Status: status,
+	})
+	if err != nil {
+		handleResponse(c, "error is while getting fiscal outbox", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	handleResponse(c, "", http.StatusOK, entries)
+}
+
+// RetryFiscalEntry godoc
+// @Router       /fiscal-outbox/{id}/retry [PUT]
+// @Summary      Retry fiscal registration
+// @Description  send a failed or waiting entry of the fiscal outbox again now with all its attempts,
+// @Description  registered entries can not be sent again
+// @Tags         fiscal
+// @Accept       json
+// @Produce      json
+// @Param 		 id path string true "fiscal_outbox_id"
+// @Success      200  {object}  models.Response
+// @Failure      400  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) RetryFiscalEntry(c *gin.Context) {
+	if err := h.service.RetryFiscal(context.Background(), c.Param("id")); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			handleResponse(c, "fiscal outbox entry not found", http.StatusNotFound, err.Error())
+			return
+		}
+		if errors.Is(err, storage.ErrFiscalSent) {
+			handleResponse(c, "fiscal outbox entry sent", http.StatusBadRequest, err.Error())
+			return
+		}
+		handleResponse(c, "error is while retrying fiscal outbox entry", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	handleResponse(c, "", http.StatusOK, "fiscal outbox entry retried")
+}
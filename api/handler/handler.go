@@ -1,18 +1,33 @@
 package handler
 
 import (
+	"errors"
 	"fmt"
 	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"net/http"
 	"sell/api/models"
+	"sell/config"
+	"sell/pkg/media"
+	"sell/service"
 	"sell/storage"
 )
 
 type Handler struct {
 	storage storage.IStorage
+	service service.Service
+	media   media.Storage
+	cfg     config.Config
 }
 
-func New(store storage.IStorage) Handler {
-	return Handler{storage: store}
+// New returns a handler of the api, uploaded files like product images are kept in files.
+func New(cfg config.Config, store storage.IStorage, svc service.Service, files media.Storage) Handler {
+	return Handler{
+		storage: store,
+		service: svc,
+		media:   files,
+		cfg:     cfg,
+	}
 }
 
 func handleResponse(c *gin.Context, msg string, statusCode int, data interface{}) {
@@ -34,3 +49,40 @@ func handleResponse(c *gin.Context, msg string, statusCode int, data interface{}
 
 	c.JSON(resp.StatusCode, resp)
 }
+
+// handleServiceError responds to an error of a use case. Requests the service or the storage turned down
+// are bad requests, a sale or a document the request points to which does not exist is not found.
+func handleServiceError(c *gin.Context, msg string, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, service.ErrSaleNotFound), errors.Is(err, pgx.ErrNoRows):
+		status = http.StatusNotFound
+	case errors.Is(err, service.ErrNotEnoughStock):
+		// the cash desk tells a shortage from a rejected request by this status
+		status = http.StatusMultipleChoices
+	case errors.Is(err, service.ErrProductNotFound),
+		errors.Is(err, service.ErrSaleClosed),
+		errors.Is(err, service.ErrSaleNotPaid),
+		errors.Is(err, service.ErrInvalidQuantity),
+		errors.Is(err, service.ErrInvalidAmount),
+		errors.Is(err, service.ErrInvalidDate),
+		errors.Is(err, service.ErrInvalidReason),
+		errors.Is(err, service.ErrNoPrice),
+		errors.Is(err, service.ErrCurrency),
+		errors.Is(err, service.ErrNoExchangeRate),
+		errors.Is(err, service.ErrEmptyDocument),
+		errors.Is(err, storage.ErrIncomeFinished),
+		errors.Is(err, storage.ErrEmptyIncome),
+		errors.Is(err, storage.ErrPurchaseOrderClosed),
+		errors.Is(err, storage.ErrSaleNotRefundable),
+		errors.Is(err, storage.ErrRefundQuantity),
+		errors.Is(err, storage.ErrFiscalSent),
+		errors.Is(err, storage.ErrRepositoryExists),
+		errors.Is(err, storage.ErrBarcodeExists),
+		errors.Is(err, storage.ErrFractionalStock),
+		errors.Is(err, storage.ErrMovementReversed):
+		status = http.StatusBadRequest
+	}
+
+	handleResponse(c, msg, status, err.Error())
+}
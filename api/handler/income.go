@@ -2,10 +2,13 @@ package handler
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/gin-gonic/gin"
 	"net/http"
 	"sell/api/models"
+	"sell/pkg/check"
+	"sell/storage"
 	"strconv"
 	"time"
 )
@@ -13,7 +16,7 @@ import (
 // CreateIncome godoc
 // @Router       /income [POST]
 // @Summary      Create a new income
-// @Description  create a new income
+// @Description  create a new income in currency (the base currency when empty) at its exchange rate of now
 // @Tags         income
 // @Accept       json
 // @Produce      json
@@ -32,15 +35,9 @@ func (h Handler) CreateIncome(c *gin.Context) {
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
 	defer cancel()
 
-	id, err := h.storage.Income().Create(ctx, income)
+	createdIncome, err := h.service.CreateIncome(ctx, income)
 	if err != nil {
-		handleResponse(c, "error is while creating income", http.StatusInternalServerError, err.Error())
-		return
-	}
-
-	createdIncome, err := h.storage.Income().GetByID(ctx, id)
-	if err != nil {
-		handleResponse(c, "error is while getting by id", http.StatusInternalServerError, err.Error())
+		handleServiceError(c, "error is while creating income", err)
 		return
 	}
 
@@ -84,6 +81,8 @@ func (h Handler) GetIncome(c *gin.Context) {
 // @Param 		 page query string false "page"
 // @Param 		 limit query string false "limit"
 // @Param 		 branch_id query string false "branch_id"
+// @Param 		 supplier_id query string false "supplier_id"
+// @Param 		 status query string false "status" Enums(in_process, finished)
 // @Success      200  {object}  models.IncomeResponse
 // @Failure      400  {object}  models.Response
 // @Failure      404  {object}  models.Response
@@ -108,11 +107,21 @@ func (h Handler) GetIncomeList(c *gin.Context) {
 		return
 	}
 
+	status := c.Query("status")
+	if status != "" {
+		if err := check.ValidateIncomeStatus(status); err != nil {
+			handleResponse(c, "error is while validating status", http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
 	branchID = c.Query("branch_id")
 	resp, err := h.storage.Income().GetList(context.Background(), models.IncomeGetListRequest{
-		Page:     page,
-		Limit:    limit,
-		BranchID: branchID,
+		Page:       page,
+		Limit:      limit,
+		BranchID:   branchID,
+		SupplierID: c.Query("supplier_id"),
+		Status:     status,
 	})
 	if err != nil {
 		handleResponse(c, "error is while getting incomes list", http.StatusInternalServerError, err.Error())
@@ -125,7 +134,7 @@ func (h Handler) GetIncomeList(c *gin.Context) {
 // UpdateIncome godoc
 // @Router       /income/{id} [PUT]
 // @Summary      Update income
-// @Description  update income
+// @Description  update income, the exchange rate of currency is taken again at the moment of the update
 // @Tags         income
 // @Accept       json
 // @Produce      json
@@ -148,17 +157,9 @@ func (h Handler) UpdateIncome(c *gin.Context) {
 	defer cancel()
 
 	income.ID = id
-	incomeID, err := h.storage.Income().Update(ctx, income)
-	if err != nil {
-		handleResponse(c, "error is while updating income", http.StatusInternalServerError, err.Error())
-		return
-	}
-
-	fmt.Println("icomeID", incomeID)
-
-	updatedIncome, err := h.storage.Income().GetByID(ctx, incomeID)
+	updatedIncome, err := h.service.UpdateIncome(ctx, income)
 	if err != nil {
-		handleResponse(c, "error is while getting income", http.StatusInternalServerError, err.Error())
+		handleServiceError(c, "error is while updating income", err)
 		return
 	}
 
@@ -180,9 +181,40 @@ func (h Handler) UpdateIncome(c *gin.Context) {
 func (h Handler) DeleteIncome(c *gin.Context) {
 	id := c.Param("id")
 	if err := h.storage.Income().Delete(context.Background(), id); err != nil {
+		if errors.Is(err, storage.ErrIncomeFinished) {
+			handleResponse(c, "income is finished", http.StatusBadRequest, err.Error())
+			return
+		}
 		handleResponse(c, "error is while deleting inocme", http.StatusInternalServerError, err.Error())
 		return
 	}
 
 	handleResponse(c, "success", http.StatusOK, "income deleted!")
 }
+
+// FinishIncome godoc
+// @Router       /income/{id}/finish [PUT]
+// @Summary      Finish income
+// @Description  add all income products to the branch repository and lock the income
+// @Tags         income
+// @Accept       json
+// @Produce      json
+// @Param 		 id path string true "income_id"
+// @Success      200  {object}  models.Income
+// @Failure      400  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) FinishIncome(c *gin.Context) {
+	id := c.Param("id")
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+
+	income, err := h.service.ReceiveIncome(ctx, id)
+	if err != nil {
+		handleServiceError(c, "error is while finishing income", err)
+		return
+	}
+
+	handleResponse(c, "finished", http.StatusOK, income)
+}
@@ -2,9 +2,11 @@ package handler
 
 import (
 	"context"
+	"errors"
 	"github.com/gin-gonic/gin"
 	"net/http"
 	"sell/api/models"
+	"sell/storage"
 	"strconv"
 	"time"
 )
@@ -12,7 +14,7 @@ import (
 // CreateIncomeProduct godoc
 // @Router       /income-product [POST]
 // @Summary      Create a new income products
-// @Description  create a new income products
+// @Description  add a line to an income in process, stock is added when the income is finished
 // @Tags         income-product
 // @Accept       json
 // @Produce      json
@@ -31,87 +33,9 @@ func (h Handler) CreateIncomeProduct(c *gin.Context) {
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
 	defer cancel()
 
-	id, err := h.storage.IncomeProducts().Create(ctx, incomeProduct)
+	createdIncomeProduct, err := h.service.AddIncomeProduct(ctx, incomeProduct)
 	if err != nil {
-		handleResponse(c, "error is while creating income Product", http.StatusInternalServerError, err.Error())
-		return
-	}
-
-	incomeProductsList, err := h.storage.IncomeProducts().GetList(ctx, models.IncomeProductRequest{
-		Page:     1,
-		Limit:    10,
-		IncomeID: incomeProduct.IncomeID,
-	})
-	if err != nil {
-		handleResponse(c, "error is while getting list income products", http.StatusInternalServerError, err.Error())
-		return
-	}
-
-	incomePrice := 0
-	for _, i := range incomeProductsList.IncomeProducts {
-		incomePrice += i.Price
-	}
-
-	repo, err := h.storage.Repository().GetList(ctx, models.GetListRequest{
-		Page:   1,
-		Limit:  10,
-		Search: incomeProduct.ProductID,
-	})
-	if err != nil {
-		handleResponse(c, "error is while getting repo list", http.StatusInternalServerError, err.Error())
-		return
-	}
-
-	var (
-		repoID       string
-		repoBranchID string
-		inRepoCount  int
-	)
-
-	for _, r := range repo.Repositories {
-		repoID = r.ID
-		repoBranchID = r.BranchID
-		inRepoCount = r.Count
-	}
-
-	_, err = h.storage.Repository().Update(ctx, models.UpdateRepository{
-		ID:        repoID,
-		ProductID: incomeProduct.ProductID,
-		BranchID:  repoBranchID,
-		Count:     inRepoCount + incomeProduct.Count,
-	})
-	if err != nil {
-		handleResponse(c, "error is while updating repo", http.StatusInternalServerError, err.Error())
-		return
-	}
-
-	_, err = h.storage.Income().Update(ctx, models.UpdateIncome{
-		ID:       incomeProduct.IncomeID,
-		BranchID: repoBranchID,
-		Price:    incomePrice,
-	})
-	if err != nil {
-		handleResponse(c, "error is while updating income", http.StatusInternalServerError, err.Error())
-		return
-	}
-
-	for _, value := range incomeProductsList.IncomeProducts {
-		//fmt.Println("staffID", staffID[value.ProductID])
-		_, err := h.storage.RTransaction().Create(ctx, models.CreateRepositoryTransaction{
-			ProductID:                 value.ProductID,
-			RepositoryTransactionType: "plus",
-			Price:                     value.Price,
-			Quantity:                  value.Count,
-		})
-		if err != nil {
-			handleResponse(c, "error is while creating repo transaction", http.StatusInternalServerError, err.Error())
-			return
-		}
-	}
-
-	createdIncomeProduct, err := h.storage.IncomeProducts().GetByID(ctx, id)
-	if err != nil {
-		handleResponse(c, "error is while getting by id", http.StatusInternalServerError, err.Error())
+		handleServiceError(c, "error is while creating income Product", err)
 		return
 	}
 
@@ -219,15 +143,9 @@ func (h Handler) UpdateIncomeProduct(c *gin.Context) {
 	defer cancel()
 
 	incomeProduct.ID = id
-	incomeID, err := h.storage.IncomeProducts().Update(ctx, incomeProduct)
-	if err != nil {
-		handleResponse(c, "error is while updating income", http.StatusInternalServerError, err.Error())
-		return
-	}
-
-	updatedIncomeProduct, err := h.storage.IncomeProducts().GetByID(ctx, incomeID)
+	updatedIncomeProduct, err := h.service.UpdateIncomeProduct(ctx, incomeProduct)
 	if err != nil {
-		handleResponse(c, "error is while getting income", http.StatusInternalServerError, err.Error())
+		handleServiceError(c, "error is while updating income", err)
 		return
 	}
 
@@ -249,6 +167,10 @@ func (h Handler) UpdateIncomeProduct(c *gin.Context) {
 func (h Handler) DeleteIncomeProduct(c *gin.Context) {
 	id := c.Param("id")
 	if err := h.storage.IncomeProducts().Delete(context.Background(), id); err != nil {
+		if errors.Is(err, storage.ErrIncomeFinished) {
+			handleResponse(c, "income is finished", http.StatusBadRequest, err.Error())
+			return
+		}
 		handleResponse(c, "error is while deleting income", http.StatusInternalServerError, err.Error())
 		return
 	}
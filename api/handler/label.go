@@ -0,0 +1,255 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"math"
+	"net/http"
+	"sell/api/models"
+	"sell/pkg/barcode"
+	"sell/pkg/label"
+	"sell/pkg/money"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxLabels limits the labels rendered by one request
+const maxLabels = 1000
+
+// CreateLabels godoc
+// @Router       /labels [POST]
+// @Summary      Render price labels
+// @Description  render price labels with name, price and barcode for the given products or for all lines of an income.
+// @Description  Lines of an income get a label per unit for products sold by piece and one label for weighed goods.
+// @Description  format is pdf (A4 sheet, default) or svg, width and height are the label size in mm (58x40 by default).
+// @Description  pdf labels print only Windows-1252 characters, products with other names, like Cyrillic ones, should be printed as svg.
+// @Description  Prices are those of branch_id, labels of an income use the income branch when branch_id is not given
+// @Tags         label
+// @Accept       json
+// @Produce      application/pdf
+// @Produce      image/svg+xml
+// @Param 		 labels body models.LabelRequest true "labels"
+// @Success      200  {file}  file
+// @Failure      400  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) CreateLabels(c *gin.Context) {
+	request := models.LabelRequest{}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		handleResponse(c, "error is while reading body", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if request.Format == "" {
+		request.Format = "pdf"
+	}
+	if request.Format != "pdf" && request.Format != "svg" {
+		handleResponse(c, "invalid format", http.StatusBadRequest, "format should be pdf or svg")
+		return
+	}
+
+	size := label.Size{Width: request.Width, Height: request.Height}
+	if size.Width == 0 {
+		size.Width = 58
+	}
+	if size.Height == 0 {
+		size.Height = 40
+	}
+	if size.Width < 20 || size.Width > 200 || size.Height < 15 || size.Height > 200 {
+		handleResponse(c, "invalid label size", http.StatusBadRequest, "width should be from 20 to 200 mm and height from 15 to 200 mm")
+		return
+	}
+
+	if len(request.Products) == 0 && request.IncomeID == "" {
+		handleResponse(c, "nothing to print", http.StatusBadRequest, "products or income_id should be given")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	products := request.Products
+	if request.IncomeID != "" {
+		if request.BranchID == "" {
+			income, err := h.storage.Income().GetByID(ctx, request.IncomeID)
+			if err != nil {
+				if errors.Is(err, pgx.ErrNoRows) {
+					handleResponse(c, "income not found", http.StatusNotFound, request.IncomeID)
+					return
+				}
+				handleResponse(c, "error is while getting income by id", http.StatusInternalServerError, err.Error())
+				return
+			}
+			request.BranchID = income.BranchID
+		}
+
+		lines, err := h.incomeLabelProducts(ctx, request.IncomeID)
+		if err != nil {
+			handleResponse(c, "error is while getting income products", http.StatusInternalServerError, err.Error())
+			return
+		}
+		products = append(products, lines...)
+	}
+
+	labels := []label.Label{}
+	for _, item := range products {
+		product, err := h.storage.Product().GetByID(ctx, item.ProductID)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				handleResponse(c, "product not found", http.StatusNotFound, item.ProductID)
+				return
+			}
+			handleResponse(c, "error is while getting product by id", http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		if product.Price, err = h.service.BranchPrice(ctx, product.ID, request.BranchID); err != nil {
+			handleResponse(c, "error is while getting product price", http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		if request.Format == "pdf" {
+			if err := label.Printable(product.Name); err != nil {
+				handleResponse(c, "product name can't be printed in pdf, use the svg format", http.StatusBadRequest, fmt.Sprintf("%s: %s", product.Name, err.Error()))
+				return
+			}
+		}
+
+		if item.Copies <= 0 {
+			item.Copies = 1
+		}
+
+		if len(labels)+item.Copies > maxLabels {
+			handleResponse(c, "too many labels", http.StatusBadRequest, fmt.Sprintf("at most %d labels can be printed at once", maxLabels))
+			return
+		}
+
+		for i := 0; i < item.Copies; i++ {
+			labels = append(labels, label.Label{
+				Name:    product.Name,
+				Price:   formatPrice(product.Price),
+				Barcode: product.Barcode,
+			})
+		}
+	}
+
+	if request.Format == "svg" {
+		c.Data(http.StatusOK, "image/svg+xml", []byte(label.SVG(labels, size)))
+		return
+	}
+
+	c.Header("Content-Disposition", `inline; filename="labels.pdf"`)
+	c.Data(http.StatusOK, "application/pdf", label.PDF(labels, size))
+}
+
+// GetBarcodeImage godoc
+// @Router       /barcode/{barcode}/image [GET]
+// @Summary      Barcode image
+// @Description  render a barcode as EAN-13, EAN-8, UPC-A or Code128 image, format is png (default) or svg
+// @Tags         label
+// @Produce      image/png
+// @Produce      image/svg+xml
+// @Param 		 barcode path string true "barcode"
+// @Param 		 format query string false "format"
+// @Param 		 scale query string false "module width in pixels"
+// @Param 		 height query string false "bar height in pixels"
+// @Success      200  {file}  file
+// @Failure      400  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) GetBarcodeImage(c *gin.Context) {
+	code := c.Param("barcode")
+
+	scale, err := strconv.Atoi(c.DefaultQuery("scale", "2"))
+	if err != nil || scale < 1 || scale > 10 {
+		handleResponse(c, "invalid scale", http.StatusBadRequest, "scale should be from 1 to 10")
+		return
+	}
+
+	height, err := strconv.Atoi(c.DefaultQuery("height", "80"))
+	if err != nil || height < 10 || height > 1000 {
+		handleResponse(c, "invalid height", http.StatusBadRequest, "height should be from 10 to 1000")
+		return
+	}
+
+	modules, err := barcode.Encode(code)
+	if err != nil {
+		handleResponse(c, "invalid barcode", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	switch c.DefaultQuery("format", "png") {
+	case "png":
+		buf := bytes.Buffer{}
+		if err := barcode.PNG(&buf, modules, scale, height); err != nil {
+			handleResponse(c, "error is while rendering barcode", http.StatusInternalServerError, err.Error())
+			return
+		}
+		c.Data(http.StatusOK, "image/png", buf.Bytes())
+	case "svg":
+		c.Data(http.StatusOK, "image/svg+xml", []byte(barcode.SVG(modules, code, scale, height)))
+	default:
+		handleResponse(c, "invalid format", http.StatusBadRequest, "format should be png or svg")
+	}
+}
+
+// incomeLabelProducts returns a label line for every line of the income.
+func (h Handler) incomeLabelProducts(ctx context.Context, incomeID string) ([]models.LabelProduct, error) {
+	incomeProducts, err := h.storage.IncomeProducts().GetList(ctx, models.IncomeProductRequest{
+		Page:     1,
+		Limit:    maxLabels,
+		IncomeID: incomeID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	products := []models.LabelProduct{}
+	for _, line := range incomeProducts.IncomeProducts {
+		product, err := h.storage.Product().GetByID(ctx, line.ProductID)
+		if err != nil {
+			return nil, err
+		}
+
+		copies := 1
+		// every piece gets a label, weighed goods are labelled once
+		if product.Precision == 0 {
+			copies = int(math.Ceil(line.Count * math.Max(line.UnitQuantity, 1)))
+		}
+
+		products = append(products, models.LabelProduct{
+			ProductID: line.ProductID,
+			Copies:    copies,
+		})
+	}
+
+	return products, nil
+}
+
+// formatPrice groups the digits of a price by thousands: 1250000 is printed as 1 250 000,
+// hundredths are printed only when there are any: 1 250.50.
+func formatPrice(price money.Money) string {
+	units, cents, _ := strings.Cut(price.String(), ".")
+	digits, sign := strings.TrimPrefix(units, "-"), ""
+	if price < 0 {
+		sign = "-"
+	}
+	if cents == "00" {
+		cents = ""
+	} else {
+		cents = "." + cents
+	}
+
+	groups := []string{}
+	for len(digits) > 3 {
+		groups = append([]string{digits[len(digits)-3:]}, groups...)
+		digits = digits[:len(digits)-3]
+	}
+	groups = append([]string{digits}, groups...)
+
+	return sign + strings.Join(groups, " ") + cents
+}
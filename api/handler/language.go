@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"sell/api/models"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// language picks the response language from the Accept-Language header by preference,
+// "ru-RU;q=0.9, en;q=0.8" prefers ru. The default language is used when none of them is supported.
+func (h Handler) language(c *gin.Context) string {
+	type preference struct {
+		language string
+		quality  float64
+	}
+
+	preferences := []preference{}
+	for _, part := range strings.Split(c.GetHeader("Accept-Language"), ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		language, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(tag)), "-")
+		if language == "" {
+			continue
+		}
+
+		quality := 1.0
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if value, err := strconv.ParseFloat(q, 64); err == nil {
+				quality = value
+			}
+		}
+		if quality > 0 {
+			preferences = append(preferences, preference{language: language, quality: quality})
+		}
+	}
+
+	sort.SliceStable(preferences, func(i, j int) bool {
+		return preferences[i].quality > preferences[j].quality
+	})
+
+	language := h.cfg.DefaultLanguage
+	for _, p := range preferences {
+		if h.supportedLanguage(p.language) {
+			language = p.language
+			break
+		}
+	}
+
+	c.Header("Content-Language", language)
+	return language
+}
+
+func (h Handler) supportedLanguage(language string) bool {
+	for _, supported := range h.cfg.Languages {
+		if strings.TrimSpace(supported) == language {
+			return true
+		}
+	}
+	return false
+}
+
+// validateTranslations checks that names are given for supported languages only.
+func (h Handler) validateTranslations(translations map[string]string, maxLength int) error {
+	for language, name := range translations {
+		if !h.supportedLanguage(language) {
+			return fmt.Errorf("language %q is not supported, languages are %s", language, strings.Join(h.cfg.Languages, ", "))
+		}
+		if name == "" || utf8.RuneCountInString(name) > maxLength {
+			return fmt.Errorf("name in %s should be 1 to %d characters", language, maxLength)
+		}
+	}
+	return nil
+}
+
+// translate returns the name in the language, or the name in the default language when there is no translation.
+func translate(name string, translations map[string]string, language string) string {
+	if translated := translations[language]; translated != "" {
+		return translated
+	}
+	return name
+}
+
+func localizeProduct(product *models.Product, language string) {
+	product.Name = translate(product.Name, product.NameTranslations, language)
+	for i := range product.Variants {
+		localizeProduct(&product.Variants[i], language)
+	}
+}
+
+func localizeCategoryTree(tree []models.CategoryTree, language string) {
+	for i := range tree {
+		tree[i].Name = translate(tree[i].Name, tree[i].NameTranslations, language)
+		localizeCategoryTree(tree[i].Children, language)
+	}
+}
@@ -2,10 +2,15 @@ package handler
 
 import (
 	"context"
+	"errors"
 	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
 	"net/http"
 	"sell/api/models"
+	"sell/pkg/check"
+	"sell/storage"
 	"strconv"
+	"strings"
 )
 
 // CreateProduct godoc
@@ -28,9 +33,42 @@ func (h Handler) CreateProduct(c *gin.Context) {
 		return
 	}
 
+	if err := h.validateTranslations(product.NameTranslations, 100); err != nil {
+		handleResponse(c, "invalid name translations", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if product.Barcode != "" {
+		if err := check.ValidateBarcode(product.Barcode); err != nil {
+			handleResponse(c, "invalid barcode", http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	for _, barcode := range product.Barcodes {
+		if err := check.ValidateBarcode(barcode.Barcode); err != nil {
+			handleResponse(c, "invalid barcode", http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	if product.Unit == "" {
+		product.Unit = "piece"
+	}
+
+	if product.Precision == nil {
+		precision := defaultPrecision(product.Unit)
+		product.Precision = &precision
+	}
+
+	if err := validateUnit(product.Unit, *product.Precision); err != nil {
+		handleResponse(c, "invalid unit", http.StatusBadRequest, err.Error())
+		return
+	}
+
 	id, err := h.storage.Product().Create(context.Background(), product)
 	if err != nil {
-		handleResponse(c, "error is while creating product", http.StatusInternalServerError, err.Error())
+		handleServiceError(c, "error is while creating product", err)
 		return
 	}
 
@@ -51,6 +89,7 @@ func (h Handler) CreateProduct(c *gin.Context) {
 // @Accept       json
 // @Produce      json
 // @Param 		 id path string true "product_id"
+// @Param 		 Accept-Language header string false "language"
 // @Success      200  {object}  models.Product
 // @Failure      400  {object}  models.Response
 // @Failure      404  {object}  models.Response
@@ -63,6 +102,8 @@ func (h Handler) GetProduct(c *gin.Context) {
 		return
 	}
 
+	localizeProduct(&product, h.language(c))
+
 	handleResponse(c, "", http.StatusOK, product)
 }
 
@@ -76,7 +117,10 @@ func (h Handler) GetProduct(c *gin.Context) {
 // @Param 		 page query string false "page"
 // @Param 		 limit query string false "limit"
 // @Param 		 name query string false "name"
-// @Param 		 barcode query int false "barcode"
+// @Param 		 barcode query string false "barcode"
+// @Param 		 grouped query bool false "list parent products with their variants"
+// @Param 		 category_id query string false "products of the category and its subcategories"
+// @Param 		 Accept-Language header string false "language"
 // @Success      200  {object}  models.ProductResponse
 // @Failure      400  {object}  models.Response
 // @Failure      404  {object}  models.Response
@@ -85,7 +129,6 @@ func (h Handler) GetProductList(c *gin.Context) {
 	var (
 		page, limit int
 		name        string
-		barcode     int
 		err         error
 	)
 
@@ -105,30 +148,38 @@ func (h Handler) GetProductList(c *gin.Context) {
 
 	name = c.Query("search")
 
-	barcode, err = strconv.Atoi(c.DefaultQuery("barcode", "0"))
+	grouped, err := strconv.ParseBool(c.DefaultQuery("grouped", "false"))
 	if err != nil {
-		handleResponse(c, "error is while converting barcode", http.StatusBadRequest, err.Error())
+		handleResponse(c, "error is while converting grouped", http.StatusBadRequest, err.Error())
 		return
 	}
 
 	products, err := h.storage.Product().GetList(context.Background(), models.ProductGetListRequest{
-		Page:    page,
-		Limit:   limit,
-		Name:    name,
-		Barcode: barcode,
+		Page:       page,
+		Limit:      limit,
+		Name:       name,
+		Barcode:    c.Query("barcode"),
+		CategoryID: c.Query("category_id"),
+		Grouped:    grouped,
 	})
 	if err != nil {
 		handleResponse(c, "error is while getting list", http.StatusInternalServerError, err.Error())
 		return
 	}
 
+	language := h.language(c)
+	for i := range products.Products {
+		localizeProduct(&products.Products[i], language)
+	}
+
 	handleResponse(c, "", http.StatusOK, products)
 }
 
 // UpdateProduct godoc
 // @Router       /product/{id} [PUT]
 // @Summary      Update product
-// @Description  update
+// @Description  update product, a variant sent with price 0 is sold at the parent price again.
+// @Description  unit and precision are kept when they are not sent, they can not be changed while the stock of the product does not fit them
 // @Tags         product
 // @Accept       json
 // @Produce      json
@@ -147,10 +198,38 @@ func (h Handler) UpdateProduct(c *gin.Context) {
 		return
 	}
 
+	if err := h.validateTranslations(product.NameTranslations, 100); err != nil {
+		handleResponse(c, "invalid name translations", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	current, err := h.storage.Product().GetByID(context.Background(), uid)
+	if err != nil {
+		handleServiceError(c, "error is while getting by id", err)
+		return
+	}
+
+	// clients which do not send the unit keep the unit and the precision of the product
+	if product.Unit == "" {
+		product.Unit = current.Unit
+	}
+	if product.Precision == nil {
+		precision := current.Precision
+		if product.Unit != current.Unit {
+			precision = defaultPrecision(product.Unit)
+		}
+		product.Precision = &precision
+	}
+
+	if err := validateUnit(product.Unit, *product.Precision); err != nil {
+		handleResponse(c, "invalid unit", http.StatusBadRequest, err.Error())
+		return
+	}
+
 	product.ID = uid
 	id, err := h.storage.Product().Update(context.Background(), product)
 	if err != nil {
-		handleResponse(c, "error is while updating", http.StatusInternalServerError, err.Error())
+		handleServiceError(c, "error is while updating", err)
 		return
 	}
 
@@ -184,3 +263,336 @@ func (h Handler) DeleteProduct(c *gin.Context) {
 
 	handleResponse(c, "", http.StatusOK, "product deleted!")
 }
+
+// AddProductBarcode godoc
+// @Router       /product/{id}/barcode [POST]
+// @Summary      Add product barcode
+// @Description  add a barcode to a product, quantity is the number of units the barcode stands for (1 for a unit, more for a pack)
+// @Tags         product
+// @Accept       json
+// @Produce      json
+// @Param 		 id path string true "product_id"
+// @Param 		 barcode body models.CreateProductBarcode true "barcode"
+// @Success      200  {object}  models.Product
+// @Failure      400  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) AddProductBarcode(c *gin.Context) {
+	barcode := models.CreateProductBarcode{}
+	if err := c.ShouldBindJSON(&barcode); err != nil {
+		handleResponse(c, "error is while reading body", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := check.ValidateBarcode(barcode.Barcode); err != nil {
+		handleResponse(c, "invalid barcode", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if barcode.Quantity == 0 {
+		barcode.Quantity = 1
+	}
+	if barcode.Quantity < 0 {
+		handleResponse(c, "invalid quantity", http.StatusBadRequest, "quantity should be positive, 1 when it is not given")
+		return
+	}
+
+	barcode.ProductID = c.Param("id")
+	id, err := h.storage.Product().AddBarcode(context.Background(), barcode)
+	if err != nil {
+		handleServiceError(c, "error is while adding barcode", err)
+		return
+	}
+
+	product, err := h.storage.Product().GetByID(context.Background(), id)
+	if err != nil {
+		handleResponse(c, "error is while getting by id", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	handleResponse(c, "", http.StatusCreated, product)
+}
+
+// DeleteProductBarcode godoc
+// @Router       /product/{id}/barcode/{barcode_id} [DELETE]
+// @Summary      Delete product barcode
+// @Description  delete a barcode of a product
+// @Tags         product
+// @Accept       json
+// @Produce      json
+// @Param 		 id path string true "product_id"
+// @Param 		 barcode_id path string true "barcode_id"
+// @Success      200  {object}  models.Product
+// @Failure      400  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) DeleteProductBarcode(c *gin.Context) {
+	productID := c.Param("id")
+	if err := h.storage.Product().DeleteBarcode(context.Background(), productID, c.Param("barcode_id")); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			handleResponse(c, "barcode not found", http.StatusNotFound, err.Error())
+			return
+		}
+		handleResponse(c, "error is while deleting barcode", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	product, err := h.storage.Product().GetByID(context.Background(), productID)
+	if err != nil {
+		handleResponse(c, "error is while getting by id", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	handleResponse(c, "", http.StatusOK, product)
+}
+
+// CreateProductVariant godoc
+// @Router       /product/{id}/variant [POST]
+// @Summary      Create product variant
+// @Description  add a variant (e.g. a size or a colour) to a product. The variant has its own barcode and stock,
+// @Description  without price_override it is sold at the parent price
+// @Tags         product
+// @Accept       json
+// @Produce      json
+// @Param 		 id path string true "product_id"
+// @Param 		 variant body models.CreateProductVariant true "variant"
+// @Success      200  {object}  models.Product
+// @Failure      400  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) CreateProductVariant(c *gin.Context) {
+	variant := models.CreateProductVariant{}
+	if err := c.ShouldBindJSON(&variant); err != nil {
+		handleResponse(c, "error is while reading body", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.validateTranslations(variant.NameTranslations, 100); err != nil {
+		handleResponse(c, "invalid name translations", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if len(variant.Attributes) == 0 {
+		handleResponse(c, "invalid variant", http.StatusBadRequest, "attributes should not be empty")
+		return
+	}
+
+	if variant.PriceOverride != nil && *variant.PriceOverride < 0 {
+		handleResponse(c, "invalid variant", http.StatusBadRequest, "price should not be negative")
+		return
+	}
+
+	if variant.Barcode != "" {
+		if err := check.ValidateBarcode(variant.Barcode); err != nil {
+			handleResponse(c, "invalid barcode", http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	variant.ParentID = c.Param("id")
+	id, err := h.storage.Product().CreateVariant(context.Background(), variant)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			handleResponse(c, "product not found", http.StatusNotFound, err.Error())
+			return
+		}
+		if errors.Is(err, storage.ErrVariantParent) {
+			handleResponse(c, "invalid parent product", http.StatusBadRequest, err.Error())
+			return
+		}
+		handleServiceError(c, "error is while creating variant", err)
+		return
+	}
+
+	createdVariant, err := h.storage.Product().GetByID(context.Background(), id)
+	if err != nil {
+		handleResponse(c, "error is while getting by id", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	handleResponse(c, "", http.StatusCreated, createdVariant)
+}
+
+// AddProductUnit godoc
+// @Router       /product/{id}/unit [POST]
+// @Summary      Add product unit
+// @Description  add a pack the product is received in, quantity is the number of product units in the pack (e.g. a box of 12)
+// @Tags         product
+// @Accept       json
+// @Produce      json
+// @Param 		 id path string true "product_id"
+// @Param 		 unit body models.CreateProductUnit true "unit"
+// @Success      200  {object}  models.Product
+// @Failure      400  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) AddProductUnit(c *gin.Context) {
+	unit := models.CreateProductUnit{}
+	if err := c.ShouldBindJSON(&unit); err != nil {
+		handleResponse(c, "error is while reading body", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if unit.Name == "" {
+		handleResponse(c, "invalid unit", http.StatusBadRequest, "name should not be empty")
+		return
+	}
+
+	if err := check.ValidateQuantity(unit.Quantity, 3); err != nil {
+		handleResponse(c, "invalid quantity", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	unit.ProductID = c.Param("id")
+	id, err := h.storage.Product().AddUnit(context.Background(), unit)
+	if err != nil {
+		handleResponse(c, "error is while adding unit", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	product, err := h.storage.Product().GetByID(context.Background(), id)
+	if err != nil {
+		handleResponse(c, "error is while getting by id", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	handleResponse(c, "", http.StatusCreated, product)
+}
+
+// DeleteProductUnit godoc
+// @Router       /product/{id}/unit/{unit_id} [DELETE]
+// @Summary      Delete product unit
+// @Description  delete a pack of a product
+// @Tags         product
+// @Accept       json
+// @Produce      json
+// @Param 		 id path string true "product_id"
+// @Param 		 unit_id path string true "unit_id"
+// @Success      200  {object}  models.Product
+// @Failure      400  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) DeleteProductUnit(c *gin.Context) {
+	productID := c.Param("id")
+	if err := h.storage.Product().DeleteUnit(context.Background(), productID, c.Param("unit_id")); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			handleResponse(c, "unit not found", http.StatusNotFound, err.Error())
+			return
+		}
+		handleResponse(c, "error is while deleting unit", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	product, err := h.storage.Product().GetByID(context.Background(), productID)
+	if err != nil {
+		handleResponse(c, "error is while getting by id", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	handleResponse(c, "", http.StatusOK, product)
+}
+
+// GetProductByBarcode godoc
+// @Router       /barcode/{barcode} [GET]
+// @Summary      Get product by barcode
+// @Description  find a product by any of its barcodes
+// @Tags         product
+// @Accept       json
+// @Produce      json
+// @Param 		 barcode path string true "barcode"
+// @Param 		 Accept-Language header string false "language"
+// @Success      200  {object}  models.Product
+// @Failure      400  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) GetProductByBarcode(c *gin.Context) {
+	barcode, err := h.storage.Product().GetByBarcode(context.Background(), c.Param("barcode"))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			handleResponse(c, "product not found", http.StatusNotFound, err.Error())
+			return
+		}
+		handleResponse(c, "error is while getting product by barcode", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	product, err := h.storage.Product().GetByID(context.Background(), barcode.ProductID)
+	if err != nil {
+		handleResponse(c, "error is while getting by id", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	localizeProduct(&product, h.language(c))
+
+	handleResponse(c, "", http.StatusOK, product)
+}
+
+// defaultPrecision is the number of decimals a quantity of the unit may have when none is given:
+// pieces are whole, weight, volume and length are measured to thousandths.
+func defaultPrecision(unit string) int {
+	if unit == "piece" {
+		return 0
+	}
+
+	return 3
+}
+
+func validateUnit(unit string, precision int) error {
+	if err := check.ValidateUnit(unit); err != nil {
+		return err
+	}
+
+	// stock is stored with 3 decimals
+	if precision < 0 || precision > 3 {
+		return errors.New("precision should be from 0 to 3")
+	}
+
+	return nil
+}
+
+// SearchProducts godoc
+// @Router       /product/search [GET]
+// @Summary      Search products
+// @Description  search products for the checkout screen by barcode, name prefix, words of the name or category
+// @Description  and misspelled names. The best matches come first, price and stock are those of branch_id
+// @Tags         product
+// @Accept       json
+// @Produce      json
+// @Param 		 q query string true "search text or barcode"
+// @Param 		 branch_id query string false "branch_id"
+// @Param 		 limit query string false "limit"
+// @Param 		 Accept-Language header string false "language"
+// @Success      200  {object}  models.ProductSearchResponse
+// @Failure      400  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) SearchProducts(c *gin.Context) {
+	query := strings.TrimSpace(c.Query("q"))
+	if query == "" {
+		handleResponse(c, "invalid search", http.StatusBadRequest, "q should not be empty")
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit < 1 || limit > 100 {
+		handleResponse(c, "invalid limit", http.StatusBadRequest, "limit should be from 1 to 100")
+		return
+	}
+
+	products, err := h.storage.Product().Search(context.Background(), models.ProductSearchRequest{
+		Query:    query,
+		BranchID: c.Query("branch_id"),
+		Limit:    limit,
+	})
+	if err != nil {
+		handleResponse(c, "error is while searching products", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	language := h.language(c)
+	for i := range products.Products {
+		localizeProduct(&products.Products[i].Product, language)
+	}
+
+	handleResponse(c, "", http.StatusOK, products)
+}
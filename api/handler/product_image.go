@@ -0,0 +1,216 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"image"
+	_ "image/gif"
+	"image/jpeg"
+	_ "image/png"
+	"io"
+	"net/http"
+	"sell/api/models"
+	"sell/pkg/media"
+	"time"
+)
+
+const (
+	// maxImageSize limits the size of an uploaded image file
+	maxImageSize = 10 << 20
+	// maxImagePixels limits the decoded size of an image, a small file may hold a huge picture
+	maxImagePixels = 40_000_000
+	// thumbnailSize is the longest side of a thumbnail in pixels
+	thumbnailSize = 256
+)
+
+// imageExtensions are the accepted image types with the extension of their files
+var imageExtensions = map[string]string{
+	"image/jpeg": "jpg",
+	"image/png":  "png",
+	"image/gif":  "gif",
+}
+
+// AddProductImage godoc
+// @Router       /product/{id}/image [POST]
+// @Summary      Upload product image
+// @Description  upload a jpeg, png or gif image of the product up to 10 MB, a thumbnail is made for it.
+// @Description  The first image of a product is its main image
+// @Tags         product
+// @Accept       multipart/form-data
+// @Produce      json
+// @Param 		 id path string true "product_id"
+// @Param 		 file formData file true "image"
+// @Success      201  {object}  models.ProductImage
+// @Failure      400  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) AddProductImage(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
+	defer cancel()
+
+	productID := c.Param("id")
+	if _, err := h.storage.Product().GetByID(ctx, productID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			handleResponse(c, "product not found", http.StatusNotFound, err.Error())
+			return
+		}
+		handleResponse(c, "error is while getting product by id", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImageSize+1<<20)
+	file, _, err := c.Request.FormFile("file")
+	if err != nil {
+		handleResponse(c, "error is while reading file", http.StatusBadRequest, err.Error())
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxImageSize+1))
+	if err != nil {
+		handleResponse(c, "error is while reading file", http.StatusBadRequest, err.Error())
+		return
+	}
+	if len(data) > maxImageSize {
+		handleResponse(c, "image is too large", http.StatusBadRequest, "image should be at most 10 MB")
+		return
+	}
+
+	contentType := http.DetectContentType(data)
+	extension, ok := imageExtensions[contentType]
+	if !ok {
+		handleResponse(c, "unsupported image", http.StatusBadRequest, "image should be jpeg, png or gif")
+		return
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		handleResponse(c, "invalid image", http.StatusBadRequest, err.Error())
+		return
+	}
+	if config.Width*config.Height > maxImagePixels {
+		handleResponse(c, "image is too large", http.StatusBadRequest, fmt.Sprintf("image should have at most %d pixels", maxImagePixels))
+		return
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		handleResponse(c, "invalid image", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	thumbnail := bytes.Buffer{}
+	if err := jpeg.Encode(&thumbnail, media.Thumbnail(img, thumbnailSize), &jpeg.Options{Quality: 85}); err != nil {
+		handleResponse(c, "error is while making thumbnail", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	name := uuid.New().String()
+	productImage := models.CreateProductImage{
+		ProductID:    productID,
+		Key:          fmt.Sprintf("products/%s/%s.%s", productID, name, extension),
+		ThumbnailKey: fmt.Sprintf("products/%s/%s_thumb.jpg", productID, name),
+		ContentType:  contentType,
+		Width:        config.Width,
+		Height:       config.Height,
+		Size:         int64(len(data)),
+	}
+
+	if productImage.URL, err = h.media.Save(ctx, productImage.Key, contentType, bytes.NewReader(data)); err != nil {
+		handleResponse(c, "error is while saving image", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if productImage.ThumbnailURL, err = h.media.Save(ctx, productImage.ThumbnailKey, "image/jpeg", &thumbnail); err != nil {
+		h.deleteMedia(productImage.Key)
+		handleResponse(c, "error is while saving thumbnail", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	id, err := h.storage.Product().AddImage(ctx, productImage)
+	if err != nil {
+		h.deleteMedia(productImage.Key, productImage.ThumbnailKey)
+		handleResponse(c, "error is while adding image", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	images, err := h.storage.Product().GetImages(ctx, productID)
+	if err != nil {
+		handleResponse(c, "error is while getting images", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	for _, created := range images {
+		if created.ID == id {
+			handleResponse(c, "", http.StatusCreated, created)
+			return
+		}
+	}
+
+	handleResponse(c, "image not found", http.StatusInternalServerError, id)
+}
+
+// GetProductImages godoc
+// @Router       /product/{id}/images [GET]
+// @Summary      Get product images
+// @Description  get images of the product, the main image first
+// @Tags         product
+// @Accept       json
+// @Produce      json
+// @Param 		 id path string true "product_id"
+// @Success      200  {array}   models.ProductImage
+// @Failure      400  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) GetProductImages(c *gin.Context) {
+	images, err := h.storage.Product().GetImages(context.Background(), c.Param("id"))
+	if err != nil {
+		handleResponse(c, "error is while getting images", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	handleResponse(c, "", http.StatusOK, images)
+}
+
+// DeleteProductImage godoc
+// @Router       /product/{id}/image/{image_id} [DELETE]
+// @Summary      Delete product image
+// @Description  delete an image of the product with its thumbnail
+// @Tags         product
+// @Accept       json
+// @Produce      json
+// @Param 		 id path string true "product_id"
+// @Param 		 image_id path string true "image_id"
+// @Success      200  {object}  models.Response
+// @Failure      400  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) DeleteProductImage(c *gin.Context) {
+	image, err := h.storage.Product().DeleteImage(context.Background(), c.Param("id"), c.Param("image_id"))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			handleResponse(c, "image not found", http.StatusNotFound, err.Error())
+			return
+		}
+		handleResponse(c, "error is while deleting image", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.deleteMedia(image.Key, image.ThumbnailKey)
+
+	handleResponse(c, "", http.StatusOK, "image deleted")
+}
+
+// deleteMedia removes files which are not referenced anymore, a failure only leaves an unused file behind.
+func (h Handler) deleteMedia(keys ...string) {
+	for _, key := range keys {
+		if err := h.media.Delete(context.Background(), key); err != nil {
+			fmt.Println("error is while deleting media", key, err.Error())
+		}
+	}
+}
@@ -0,0 +1,348 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"path/filepath"
+	"sell/api/models"
+	"sell/pkg/check"
+	"sell/pkg/money"
+	"sell/pkg/xlsx"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// maxImportSize limits the size of an uploaded catalogue file
+	maxImportSize = 10 << 20
+	// maxImportRows limits the products imported by one request
+	maxImportRows = 10000
+)
+
+// importColumns are the columns of import and export files, in export order
+var importColumns = []string{"name", "price", "barcode", "category"}
+
+// ImportProducts godoc
+// @Router       /product/import [POST]
+// @Summary      Import products
+// @Description  import products from a csv or xlsx file with the header row name, price, barcode, category.
+// @Description  A product with the barcode of the row is updated, other rows create products. category is a path like Food/Dairy/Milk,
+// @Description  missing categories are created. Rows with errors are skipped and reported, with dry_run nothing is saved.
+// @Description  Barcodes of xlsx number cells get back the leading zeros of EAN-8, UPC-A and EAN-13 codes
+// @Tags         product
+// @Accept       multipart/form-data
+// @Produce      json
+// @Param 		 file formData file true "csv or xlsx file"
+// @Param 		 dry_run query bool false "dry_run"
+// @Success      200  {object}  models.ProductImportResponse
+// @Failure      400  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) ImportProducts(c *gin.Context) {
+	dryRun, err := strconv.ParseBool(c.DefaultQuery("dry_run", "false"))
+	if err != nil {
+		handleResponse(c, "error is while converting dry_run", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportSize)
+	file, header, err := c.Request.FormFile("file")
+	if err != nil {
+		handleResponse(c, "error is while reading file", http.StatusBadRequest, err.Error())
+		return
+	}
+	defer file.Close()
+
+	records, err := readImportFile(file, header)
+	if err != nil {
+		handleResponse(c, "error is while reading file", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// spreadsheets keep barcodes typed into number cells as numbers, dropping their leading zeros
+	spreadsheet := strings.EqualFold(filepath.Ext(header.Filename), ".xlsx")
+	rows, rowErrors, err := parseImportRows(records, spreadsheet)
+	if err != nil {
+		handleResponse(c, "invalid file", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if len(rows)+len(rowErrors) > maxImportRows {
+		handleResponse(c, "too many rows", http.StatusBadRequest, fmt.Sprintf("at most %d products can be imported at once", maxImportRows))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	response, err := h.storage.Product().Import(ctx, rows, dryRun)
+	if err != nil {
+		handleResponse(c, "error is while importing products", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	failedRows := map[int]bool{}
+	for _, rowError := range rowErrors {
+		failedRows[rowError.Row] = true
+	}
+
+	response.Total += len(failedRows)
+	response.Failed += len(failedRows)
+	response.Errors = append(response.Errors, rowErrors...)
+	sort.SliceStable(response.Errors, func(i, j int) bool {
+		return response.Errors[i].Row < response.Errors[j].Row
+	})
+
+	handleResponse(c, "", http.StatusOK, response)
+}
+
+// ExportProducts godoc
+// @Router       /product/export [GET]
+// @Summary      Export products
+// @Description  export all products with name, price, barcode and category path as csv (default) or xlsx,
+// @Description  the file can be imported back
+// @Tags         product
+// @Produce      text/csv
+// @Produce      application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param 		 format query string false "csv or xlsx"
+// @Success      200  {file}  file
+// @Failure      400  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) ExportProducts(c *gin.Context) {
+	format := c.DefaultQuery("format", "csv")
+	if format != "csv" && format != "xlsx" {
+		handleResponse(c, "invalid format", http.StatusBadRequest, "format should be csv or xlsx")
+		return
+	}
+
+	products, err := h.storage.Product().Export(context.Background())
+	if err != nil {
+		handleResponse(c, "error is while exporting products", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	records := [][]string{importColumns}
+	for _, product := range products {
+		records = append(records, []string{product.Name, product.Price.String(), product.Barcode, product.Category})
+	}
+
+	buf := bytes.Buffer{}
+	contentType := "text/csv"
+	if format == "xlsx" {
+		contentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+		err = xlsx.Write(&buf, records)
+	} else {
+		w := csv.NewWriter(&buf)
+		err = w.WriteAll(records)
+	}
+	if err != nil {
+		handleResponse(c, "error is while writing file", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="products.%s"`, format))
+	c.Data(http.StatusOK, contentType, buf.Bytes())
+}
+
+// readImportFile returns the rows of a csv or xlsx file by the file extension,
+// records[i] is the line i+1 of the file.
+func readImportFile(file multipart.File, header *multipart.FileHeader) ([][]string, error) {
+	switch strings.ToLower(filepath.Ext(header.Filename)) {
+	case ".xlsx":
+		return xlsx.Read(file, header.Size)
+	case ".csv", ".txt":
+	default:
+		return nil, fmt.Errorf("unsupported file %s, should be csv or xlsx", header.Filename)
+	}
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return nil, err
+	}
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+
+	r := csv.NewReader(bytes.NewReader(data))
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+
+	// spreadsheet applications of many locales save csv files with semicolons
+	firstLine, _, _ := bytes.Cut(data, []byte("\n"))
+	if bytes.Count(firstLine, []byte(";")) > bytes.Count(firstLine, []byte(",")) {
+		r.Comma = ';'
+	}
+
+	records := [][]string{}
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		line, _ := r.FieldPos(0)
+		for len(records) < line-1 {
+			records = append(records, nil)
+		}
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+// parseImportRows reads products from the records, the first non empty record is the header.
+// Invalid rows are returned as errors with the line number of the file.
+// With padBarcodes the leading zeros a spreadsheet dropped from EAN and UPC codes are restored.
+func parseImportRows(records [][]string, padBarcodes bool) ([]models.ProductImportRow, []models.ProductImportError, error) {
+	var (
+		rows      = []models.ProductImportRow{}
+		rowErrors = []models.ProductImportError{}
+		columns   = map[string]int{}
+		barcodes  = map[string]int{}
+		headerRow = -1
+	)
+
+	for i, record := range records {
+		if isEmptyRecord(record) {
+			continue
+		}
+		for j, name := range record {
+			columns[strings.ToLower(strings.TrimSpace(name))] = j
+		}
+		headerRow = i
+		break
+	}
+
+	if headerRow < 0 {
+		return nil, nil, fmt.Errorf("file is empty")
+	}
+	if _, ok := columns["name"]; !ok {
+		return nil, nil, fmt.Errorf("header should have the column name, columns are %s", strings.Join(importColumns, ", "))
+	}
+
+	cell := func(record []string, column string) string {
+		if j, ok := columns[column]; ok && j < len(record) {
+			return strings.TrimSpace(record[j])
+		}
+		return ""
+	}
+
+	for i := headerRow + 1; i < len(records); i++ {
+		record := records[i]
+		if isEmptyRecord(record) {
+			continue
+		}
+
+		var (
+			errs = []models.ProductImportError{}
+			row  = models.ProductImportRow{
+				Row:     i + 1,
+				Name:    cell(record, "name"),
+				Barcode: cell(record, "barcode"),
+			}
+		)
+		fail := func(column, message string) {
+			errs = append(errs, models.ProductImportError{Row: row.Row, Column: column, Message: message})
+		}
+
+		if row.Name == "" {
+			fail("name", "name is required")
+		} else if utf8.RuneCountInString(row.Name) > 100 {
+			fail("name", "name should be at most 100 characters")
+		}
+
+		if price := strings.ReplaceAll(cell(record, "price"), " ", ""); price != "" {
+			// spreadsheets of many locales write a decimal comma
+			if !strings.Contains(price, ".") {
+				price = strings.Replace(price, ",", ".", 1)
+			}
+			value, err := money.Parse(price)
+			if err != nil || value < 0 || value > money.Max {
+				fail("price", fmt.Sprintf("invalid price %q, should be a non negative number with at most %d decimal places", price, money.Scale))
+			} else {
+				row.Price = &value
+			}
+		}
+
+		if padBarcodes {
+			row.Barcode = padBarcode(row.Barcode)
+		}
+
+		if row.Barcode != "" {
+			if err := check.ValidateBarcode(row.Barcode); err != nil {
+				fail("barcode", err.Error())
+			} else if first, ok := barcodes[row.Barcode]; ok {
+				fail("barcode", fmt.Sprintf("barcode is repeated, first used in row %d", first))
+			} else {
+				barcodes[row.Barcode] = row.Row
+			}
+		}
+
+		if path := cell(record, "category"); path != "" {
+			for _, name := range strings.Split(path, "/") {
+				name = strings.TrimSpace(name)
+				if name == "" || utf8.RuneCountInString(name) > 30 {
+					fail("category", fmt.Sprintf("invalid category %q, names in the path should be 1 to 30 characters", path))
+					break
+				}
+				row.Category = append(row.Category, name)
+			}
+		}
+
+		if len(errs) > 0 {
+			rowErrors = append(rowErrors, errs...)
+			continue
+		}
+		rows = append(rows, row)
+	}
+
+	return rows, rowErrors, nil
+}
+
+// padBarcode restores the leading zeros of an EAN-8, UPC-A or EAN-13 code written as a number,
+// the code is padded with one or two zeros to the shortest of these lengths with a correct check digit,
+// so short item numbers are not mistaken for codes. Codes which are already valid EAN codes
+// or have no such padding are returned as they are.
+func padBarcode(barcode string) string {
+	if barcode == "" || len(barcode) >= 13 || strings.Trim(barcode, "0123456789") != "" {
+		return barcode
+	}
+
+	lengths := []int{8, 12, 13}
+	for _, length := range lengths {
+		if len(barcode) == length && check.ValidateBarcode(barcode) == nil {
+			return barcode
+		}
+	}
+
+	for _, length := range lengths {
+		if length <= len(barcode) || length-len(barcode) > 2 {
+			continue
+		}
+		padded := strings.Repeat("0", length-len(barcode)) + barcode
+		if check.ValidateBarcode(padded) == nil {
+			return padded
+		}
+	}
+
+	return barcode
+}
+
+func isEmptyRecord(record []string) bool {
+	for _, value := range record {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,196 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"net/http"
+	"sell/api/models"
+	"sell/storage"
+	"strconv"
+	"time"
+)
+
+// CreateProductPrice godoc
+// @Router       /product/{id}/price [POST]
+// @Summary      Schedule product price
+// @Description  set a price of the product for all branches or for branch_id only. The price starts at starts_at
+// @Description  (RFC3339, in the future) or now when starts_at is empty, earlier prices are kept as history.
+// @Description  The latest started price is used, a price of the branch wins over one for all branches starting at once
+// @Tags         product
+// @Accept       json
+// @Produce      json
+// @Param 		 id path string true "product_id"
+// @Param 		 price body models.CreateProductPrice true "price"
+// @Success      201  {object}  models.ProductPricesResponse
+// @Failure      400  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) CreateProductPrice(c *gin.Context) {
+	price := models.CreateProductPrice{}
+	if err := c.ShouldBindJSON(&price); err != nil {
+		handleResponse(c, "error is while reading body", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if price.Price <= 0 {
+		handleResponse(c, "invalid price", http.StatusBadRequest, "price should be positive")
+		return
+	}
+
+	if price.StartsAt != "" {
+		startsAt, err := time.Parse(time.RFC3339, price.StartsAt)
+		if err != nil {
+			handleResponse(c, "error while parsing starts_at", http.StatusBadRequest, err.Error())
+			return
+		}
+		if !startsAt.After(time.Now()) {
+			handleResponse(c, "invalid starts_at", http.StatusBadRequest, "starts_at should be in the future")
+			return
+		}
+		price.StartsAt = startsAt.Local().Format("2006-01-02 15:04:05.999999")
+	}
+
+	price.ProductID = c.Param("id")
+	if _, err := h.storage.Product().GetByID(context.Background(), price.ProductID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			handleResponse(c, "product not found", http.StatusNotFound, err.Error())
+			return
+		}
+		handleResponse(c, "error is while getting product by id", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if _, err := h.storage.ProductPrice().Create(context.Background(), price); err != nil {
+		handleResponse(c, "error is while creating product price", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	prices, err := h.storage.ProductPrice().GetList(context.Background(), models.ProductPriceGetListRequest{
+		Page:      1,
+		Limit:     10,
+		ProductID: price.ProductID,
+		BranchID:  price.BranchID,
+	})
+	if err != nil {
+		handleResponse(c, "error is while getting product prices", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	handleResponse(c, "", http.StatusCreated, prices)
+}
+
+// GetProductPriceList godoc
+// @Router       /product/{id}/prices [GET]
+// @Summary      Get product price history
+// @Description  get started and scheduled prices of the product, latest first. With branch_id prices of
+// @Description  the branch and prices for all branches are listed
+// @Tags         product
+// @Accept       json
+// @Produce      json
+// @Param 		 id path string true "product_id"
+// @Param 		 page query string false "page"
+// @Param 		 limit query string false "limit"
+// @Param 		 branch_id query string false "branch_id"
+// @Success      200  {object}  models.ProductPricesResponse
+// @Failure      400  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) GetProductPriceList(c *gin.Context) {
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil {
+		handleResponse(c, "error is while converting page", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil {
+		handleResponse(c, "error is while converting limit", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	prices, err := h.storage.ProductPrice().GetList(context.Background(), models.ProductPriceGetListRequest{
+		Page:      page,
+		Limit:     limit,
+		ProductID: c.Param("id"),
+		BranchID:  c.Query("branch_id"),
+	})
+	if err != nil {
+		handleResponse(c, "error is while getting product prices", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	handleResponse(c, "", http.StatusOK, prices)
+}
+
+// GetProductPrice godoc
+// @Router       /product/{id}/price [GET]
+// @Summary      Get effective product price
+// @Description  get the price of the product in branch_id (all branches when empty) at the moment at (RFC3339, now by default)
+// @Tags         product
+// @Accept       json
+// @Produce      json
+// @Param 		 id path string true "product_id"
+// @Param 		 branch_id query string false "branch_id"
+// @Param 		 at query string false "at"
+// @Success      200  {object}  models.EffectivePrice
+// @Failure      400  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) GetProductPrice(c *gin.Context) {
+	at := time.Now()
+	if atStr := c.Query("at"); atStr != "" {
+		var err error
+		if at, err = time.Parse(time.RFC3339, atStr); err != nil {
+			handleResponse(c, "error while parsing at", http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	price, err := h.storage.ProductPrice().GetEffective(context.Background(), models.EffectivePriceRequest{
+		ProductID: c.Param("id"),
+		BranchID:  c.Query("branch_id"),
+		At:        at.Local(),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			handleResponse(c, "product not found", http.StatusNotFound, err.Error())
+			return
+		}
+		handleResponse(c, "error is while getting product price", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	handleResponse(c, "", http.StatusOK, price)
+}
+
+// DeleteProductPrice godoc
+// @Router       /product/{id}/price/{price_id} [DELETE]
+// @Summary      Cancel scheduled product price
+// @Description  cancel a price which has not started yet, started prices stay in the history
+// @Tags         product
+// @Accept       json
+// @Produce      json
+// @Param 		 id path string true "product_id"
+// @Param 		 price_id path string true "price_id"
+// @Success      200  {object}  models.Response
+// @Failure      400  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) DeleteProductPrice(c *gin.Context) {
+	if err := h.storage.ProductPrice().Delete(context.Background(), c.Param("id"), c.Param("price_id")); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			handleResponse(c, "price not found", http.StatusNotFound, err.Error())
+			return
+		}
+		if errors.Is(err, storage.ErrPriceStarted) {
+			handleResponse(c, "price started", http.StatusBadRequest, err.Error())
+			return
+		}
+		handleResponse(c, "error is while deleting product price", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	handleResponse(c, "", http.StatusOK, "price cancelled")
+}
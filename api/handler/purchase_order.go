@@ -0,0 +1,207 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"sell/api/models"
+	"sell/storage"
+	"strconv"
+	"time"
+)
+
+// CreatePurchaseOrder godoc
+// @Router       /purchase-order [POST]
+// @Summary      Create a new purchase order
+// @Description  order products from a supplier with expected prices in currency, the base currency when empty
+// @Tags         purchase-order
+// @Accept       json
+// @Produce      json
+// @Param 		 purchase-order body models.CreatePurchaseOrder false "purchase-order"
+// @Success      200  {object}  models.PurchaseOrder
+// @Failure      400  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) CreatePurchaseOrder(c *gin.Context) {
+	order := models.CreatePurchaseOrder{}
+	if err := c.ShouldBindJSON(&order); err != nil {
+		handleResponse(c, "error is while reading body", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+
+	createdOrder, err := h.service.CreatePurchaseOrder(ctx, order)
+	if err != nil {
+		handleServiceError(c, "error is while creating purchase order", err)
+		return
+	}
+
+	handleResponse(c, "success", http.StatusCreated, createdOrder)
+}
+
+// GetPurchaseOrder godoc
+// @Router       /purchase-order/{id} [GET]
+// @Summary      Get purchase order
+// @Description  get purchase order with ordered and received counts
+// @Tags         purchase-order
+// @Accept       json
+// @Produce      json
+// @Param 		 id path string true "purchase_order_id"
+// @Success      200  {object}  models.PurchaseOrder
+// @Failure      400  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) GetPurchaseOrder(c *gin.Context) {
+	id := c.Param("id")
+
+	order, err := h.storage.PurchaseOrder().GetByID(context.Background(), id)
+	if err != nil {
+		handleResponse(c, "error is while getting by id", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	handleResponse(c, "success", http.StatusOK, order)
+}
+
+// GetPurchaseOrderList godoc
+// @Router       /purchase-orders [GET]
+// @Summary      Get purchase order list
+// @Description  get purchase order list
+// @Tags         purchase-order
+// @Accept       json
+// @Produce      json
+// @Param 		 page query string false "page"
+// @Param 		 limit query string false "limit"
+// @Param 		 supplier_id query string false "supplier_id"
+// @Param 		 branch_id query string false "branch_id"
+// @Param 		 status query string false "status"
+// @Success      200  {object}  models.PurchaseOrderResponse
+// @Failure      400  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) GetPurchaseOrderList(c *gin.Context) {
+	var (
+		page, limit int
+		err         error
+	)
+
+	pageStr := c.DefaultQuery("page", "1")
+	page, err = strconv.Atoi(pageStr)
+	if err != nil {
+		handleResponse(c, "error is while converting page", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	limitStr := c.DefaultQuery("limit", "10")
+	limit, err = strconv.Atoi(limitStr)
+	if err != nil {
+		handleResponse(c, "error is while converting limit", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	resp, err := h.storage.PurchaseOrder().GetList(context.Background(), models.PurchaseOrderGetListRequest{
+		Page:       page,
+		Limit:      limit,
+		SupplierID: c.Query("supplier_id"),
+		BranchID:   c.Query("branch_id"),
+		Status:     c.Query("status"),
+	})
+	if err != nil {
+		handleResponse(c, "error is while getting purchase order list", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	handleResponse(c, "success", http.StatusOK, resp)
+}
+
+// CancelPurchaseOrder godoc
+// @Router       /purchase-order/{id}/cancel [PUT]
+// @Summary      Cancel purchase order
+// @Description  cancel a purchase order nothing was received for
+// @Tags         purchase-order
+// @Accept       json
+// @Produce      json
+// @Param 		 id path string true "purchase_order_id"
+// @Success      200  {object}  models.PurchaseOrder
+// @Failure      400  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) CancelPurchaseOrder(c *gin.Context) {
+	id := c.Param("id")
+
+	if err := h.storage.PurchaseOrder().Cancel(context.Background(), id); err != nil {
+		if errors.Is(err, storage.ErrPurchaseOrderClosed) {
+			handleResponse(c, "purchase order cannot be cancelled", http.StatusBadRequest, err.Error())
+			return
+		}
+		handleResponse(c, "error is while cancelling purchase order", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	order, err := h.storage.PurchaseOrder().GetByID(context.Background(), id)
+	if err != nil {
+		handleResponse(c, "error is while getting by id", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	handleResponse(c, "cancelled", http.StatusOK, order)
+}
+
+// ReceivePurchaseOrder godoc
+// @Router       /purchase-order/{id}/receive [POST]
+// @Summary      Receive purchase order
+// @Description  receive all or a part of a purchase order, an income with income products is created
+// @Description  in the currency of the order at its exchange rate of now
+// @Tags         purchase-order
+// @Accept       json
+// @Produce      json
+// @Param 		 id path string true "purchase_order_id"
+// @Param 		 receive body models.ReceivePurchaseOrder true "receive"
+// @Success      200  {object}  models.Income
+// @Failure      400  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) ReceivePurchaseOrder(c *gin.Context) {
+	request := models.ReceivePurchaseOrder{}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		handleResponse(c, "error is while reading body", http.StatusBadRequest, err.Error())
+		return
+	}
+	request.ID = c.Param("id")
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+
+	income, err := h.service.ReceivePurchaseOrder(ctx, request)
+	if err != nil {
+		handleServiceError(c, "error is while receiving purchase order", err)
+		return
+	}
+
+	handleResponse(c, "received", http.StatusOK, income)
+}
+
+// GetPurchaseOrderDiscrepancies godoc
+// @Router       /purchase-order/{id}/discrepancies [GET]
+// @Summary      Get purchase order discrepancies
+// @Description  compare ordered and received counts and prices of a purchase order
+// @Tags         purchase-order
+// @Accept       json
+// @Produce      json
+// @Param 		 id path string true "purchase_order_id"
+// @Success      200  {object}  models.PurchaseOrderDiscrepancies
+// @Failure      400  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) GetPurchaseOrderDiscrepancies(c *gin.Context) {
+	discrepancies, err := h.storage.PurchaseOrder().GetDiscrepancies(context.Background(), c.Param("id"))
+	if err != nil {
+		handleResponse(c, "error is while getting purchase order discrepancies", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	handleResponse(c, "success", http.StatusOK, discrepancies)
+}
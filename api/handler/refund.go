@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"net/http"
+	"sell/api/models"
+	"strconv"
+)
+
+// CreateRefund godoc
+// @Router       /sale/{id}/refund [POST]
+// @Summary      Refund a sale
+// @Description  give back quantity of basket lines of a successful sale, all that is left of the sale when products
+// @Description  are empty. The goods go back to the branch and the refund is registered with the fiscal module,
+// @Description  the money paid back is the share of the line price and tax
+// @Tags         refund
+// @Accept       json
+// @Produce      json
+// @Param 		 id path string true "sale_id"
+// @Param 		 refund body models.CreateRefund true "refund"
+// @Success      201  {object}  models.Refund
+// @Failure      400  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) CreateRefund(c *gin.Context) {
+	refund := models.CreateRefund{}
+	if err := c.ShouldBindJSON(&refund); err != nil {
+		handleResponse(c, "error is while reading body", http.StatusBadRequest, err.Error())
+		return
+	}
+	refund.SaleID = c.Param("id")
+
+	createdRefund, err := h.service.RefundSale(context.Background(), refund)
+	if err != nil {
+		handleServiceError(c, "error is while creating refund", err)
+		return
+	}
+
+	handleResponse(c, "", http.StatusCreated, createdRefund)
+}
+
+// GetRefund godoc
+// @Router       /refund/{id} [GET]
+// @Summary      Get refund
+// @Description  get refund with its products
+// @Tags         refund
+// @Accept       json
+// @Produce      json
+// @Param 		 id path string true "refund_id"
+// @Success      200  {object}  models.Refund
+// @Failure      400  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) GetRefund(c *gin.Context) {
+	refund, err := h.storage.Refund().GetByID(context.Background(), c.Param("id"))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			handleResponse(c, "refund not found", http.StatusNotFound, err.Error())
+			return
+		}
+		handleResponse(c, "error is while getting refund", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	handleResponse(c, "", http.StatusOK, refund)
+}
+
+// GetSaleRefunds godoc
+// @Router       /sale/{id}/refunds [GET]
+// @Summary      Get sale refunds
+// @Description  get refunds of the sale, latest first
+// @Tags         refund
+// @Accept       json
+// @Produce      json
+// @Param 		 id path string true "sale_id"
+// @Param 		 page query string false "page"
+// @Param 		 limit query string false "limit"
+// @Success      200  {object}  models.RefundsResponse
+// @Failure      400  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) GetSaleRefunds(c *gin.Context) {
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil {
+		handleResponse(c, "error is while converting page", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil {
+		handleResponse(c, "error is while converting limit", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	refunds, err := h.storage.Refund().GetList(context.Background(), models.RefundGetListRequest{
+		Page:   page,
+		Limit:  limit,
+		SaleID: c.Param("id"),
+	})
+	if err != nil {
+		handleResponse(c, "error is while getting refunds", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	handleResponse(c, "", http.StatusOK, refunds)
+}
@@ -31,7 +31,7 @@ func (h Handler) CreateRepository(c *gin.Context) {
 
 	id, err := h.storage.Repository().Create(context.Background(), repository)
 	if err != nil {
-		handleResponse(c, "error while creating repository", http.StatusInternalServerError, err.Error())
+		handleServiceError(c, "error while creating repository", err)
 		return
 	}
 
@@ -122,7 +122,7 @@ func (h Handler) GetRepositoryList(c *gin.Context) {
 // UpdateRepository godoc
 // @Router       /repository/{id} [PUT]
 // @Summary      Update repository
-// @Description  get repository
+// @Description  update repository, the change of the count is recorded as an adjustment movement
 // @Tags         repository
 // @Accept       json
 // @Produce      json
@@ -142,8 +142,8 @@ func (h Handler) UpdateRepository(c *gin.Context) {
 	}
 
 	repository.ID = uid
-	if _, err := h.storage.Repository().Update(context.Background(), repository); err != nil {
-		handleResponse(c, "error while updating repository ", http.StatusInternalServerError, err.Error())
+	if _, err := h.storage.Repository().Adjust(context.Background(), repository); err != nil {
+		handleServiceError(c, "error while updating repository ", err)
 		return
 	}
 
@@ -172,9 +172,172 @@ func (h Handler) DeleteRepository(c *gin.Context) {
 	uid := c.Param("id")
 
 	if err := h.storage.Repository().Delete(context.Background(), uid); err != nil {
-		handleResponse(c, "error while deleting repository ", http.StatusInternalServerError, err.Error())
+		handleServiceError(c, "error while deleting repository ", err)
 		return
 	}
 
 	handleResponse(c, "", http.StatusOK, "repository deleted")
 }
+
+// UpdateRepositoryLevels godoc
+// @Router       /repository/{id}/levels [PUT]
+// @Summary      Update repository stock levels
+// @Description  set minimum and target stock levels of a product in a branch
+// @Tags         repository
+// @Accept       json
+// @Produce      json
+// @Param 		 id path string true "repository_id"
+// @Param 		 levels body models.UpdateRepositoryLevels true "levels"
+// @Success      200  {object}  models.Repository
+// @Failure      400  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) UpdateRepositoryLevels(c *gin.Context) {
+	uid := c.Param("id")
+
+	levels := models.UpdateRepositoryLevels{}
+	if err := c.ShouldBindJSON(&levels); err != nil {
+		handleResponse(c, "error while reading from body", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if levels.MinCount < 0 || levels.TargetCount < levels.MinCount {
+		handleResponse(c, "invalid stock levels", http.StatusBadRequest, "target_count should not be less than min_count")
+		return
+	}
+
+	levels.ID = uid
+	if _, err := h.storage.Repository().UpdateLevels(context.Background(), levels); err != nil {
+		handleResponse(c, "error while updating repository levels", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	updatedRepository, err := h.storage.Repository().GetByID(context.Background(), models.PrimaryKey{ID: uid})
+	if err != nil {
+		handleResponse(c, "error while getting by ID", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	handleResponse(c, "", http.StatusOK, updatedRepository)
+}
+
+// GetLowStockList godoc
+// @Router       /repositories/low-stock [GET]
+// @Summary      Get low stock list
+// @Description  get products whose count is below the minimum stock level
+// @Tags         repository
+// @Accept       json
+// @Produce      json
+// @Param 		 branch_id query string false "branch_id"
+// @Success      200  {object}  models.LowStockResponse
+// @Failure      400  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) GetLowStockList(c *gin.Context) {
+	response, err := h.storage.Repository().GetLowStock(context.Background(), models.StockLevelRequest{
+		BranchID: c.Query("branch_id"),
+	})
+	if err != nil {
+		handleResponse(c, "error while getting low stock list", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	handleResponse(c, "", http.StatusOK, response)
+}
+
+// GetReorderSuggestions godoc
+// @Router       /repositories/reorder [GET]
+// @Summary      Get reorder suggestions
+// @Description  suggest quantities to order using average daily sales over the last days
+// @Tags         repository
+// @Accept       json
+// @Produce      json
+// @Param 		 branch_id query string false "branch_id"
+// @Param 		 days query string false "sales window in days"
+// @Param 		 cover_days query string false "days the order should cover"
+// @Success      200  {object}  models.ReorderSuggestionsResponse
+// @Failure      400  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) GetReorderSuggestions(c *gin.Context) {
+	days, err := strconv.Atoi(c.DefaultQuery("days", strconv.Itoa(h.cfg.ReorderWindowDays)))
+	if err != nil || days <= 0 {
+		handleResponse(c, "error while converting days", http.StatusBadRequest, "days should be a positive number")
+		return
+	}
+
+	coverDays, err := strconv.Atoi(c.DefaultQuery("cover_days", strconv.Itoa(h.cfg.ReorderCoverDays)))
+	if err != nil || coverDays < 0 {
+		handleResponse(c, "error while converting cover days", http.StatusBadRequest, "cover_days should be a positive number")
+		return
+	}
+
+	response, err := h.storage.Repository().GetReorderSuggestions(context.Background(), models.StockLevelRequest{
+		BranchID:  c.Query("branch_id"),
+		Days:      days,
+		CoverDays: coverDays,
+	})
+	if err != nil {
+		handleResponse(c, "error while getting reorder suggestions", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	handleResponse(c, "", http.StatusOK, response)
+}
+
+// GetStockReconciliation godoc
+// @Router       /repositories/reconcile [GET]
+// @Summary      Get stock reconciliation
+// @Description  compare repository counts with counts expected from repository transactions
+// @Tags         repository
+// @Accept       json
+// @Produce      json
+// @Param 		 branch_id query string false "branch_id"
+// @Success      200  {object}  models.ReconcileResponse
+// @Failure      400  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) GetStockReconciliation(c *gin.Context) {
+	response, err := h.storage.Repository().Reconcile(context.Background(), models.ReconcileRequest{
+		BranchID: c.Query("branch_id"),
+	})
+	if err != nil {
+		handleResponse(c, "error while reconciling stock", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	handleResponse(c, "", http.StatusOK, response)
+}
+
+// ReconcileStock godoc
+// @Router       /repositories/reconcile [POST]
+// @Summary      Reconcile stock
+// @Description  fix every difference between repository counts and repository transactions. mode ledger trusts the counts
+// @Description  and posts reconciliation movements, mode counts trusts the movements and sets the counts from them
+// @Tags         repository
+// @Accept       json
+// @Produce      json
+// @Param 		 branch_id query string false "branch_id"
+// @Param 		 mode query string true "ledger or counts"
+// @Success      200  {object}  models.ReconcileResponse
+// @Failure      400  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) ReconcileStock(c *gin.Context) {
+	mode := c.Query("mode")
+	if mode != "ledger" && mode != "counts" {
+		handleResponse(c, "invalid mode", http.StatusBadRequest, "mode should be ledger or counts")
+		return
+	}
+
+	response, err := h.storage.Repository().Reconcile(context.Background(), models.ReconcileRequest{
+		BranchID: c.Query("branch_id"),
+		Mode:     mode,
+	})
+	if err != nil {
+		handleResponse(c, "error while reconciling stock", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	handleResponse(c, "", http.StatusOK, response)
+}
@@ -2,9 +2,13 @@ package handler
 
 import (
 	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
 	"net/http"
 	"sell/api/models"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -12,12 +16,12 @@ import (
 // CreateRepositoryTransaction godoc
 // @Router       /rtransaction [POST]
 // @Summary      Create a new rtransaction
-// @Description  create a new rtransaction
+// @Description  post a movement by hand. repository counts do not change, reconcile shows the difference
 // @Tags         rtransaction
 // @Accept       json
 // @Produce      json
 // @Param 		 rtransaction body models.CreateRepositoryTransaction false "rtransaction"
-// @Success      200  {object}  models.RepositoryTransaction
+// @Success      201  {object}  models.RepositoryTransaction
 // @Failure      400  {object}  models.Response
 // @Failure      404  {object}  models.Response
 // @Failure      500  {object}  models.Response
@@ -29,6 +33,11 @@ func (h Handler) CreateRepositoryTransaction(c *gin.Context) {
 		return
 	}
 
+	if err := validateMovement(rtransaction.RepositoryTransactionType, rtransaction.Quantity); err != nil {
+		handleResponse(c, "error while validating repository transaction", http.StatusBadRequest, err.Error())
+		return
+	}
+
 	id, err := h.storage.RTransaction().Create(context.Background(), rtransaction)
 	if err != nil {
 		handleResponse(c, "error while creating repository transaction", http.StatusInternalServerError, err.Error())
@@ -79,7 +88,10 @@ func (h Handler) GetRepositoryTransaction(c *gin.Context) {
 // @Produce      json
 // @Param 		 page query string false "page"
 // @Param 		 limit query string false "limit"
-// @Param 		 search query string false "search"
+// @Param 		 search query string false "product_id"
+// @Param 		 branch_id query string false "branch_id"
+// @Param 		 source_type query string false "sale, income, transfer, write_off, stocktake, refund or adjustment"
+// @Param 		 source_id query string false "source_id"
 // @Success      200  {object}  models.RepositoryTransactionsResponse
 // @Failure      400  {object}  models.Response
 // @Failure      404  {object}  models.Response
@@ -106,10 +118,13 @@ func (h Handler) GetRepositoryTransactionList(c *gin.Context) {
 
 	search := c.Query("search")
 
-	response, err := h.storage.RTransaction().GetList(context.Background(), models.GetListRequest{
-		Page:   page,
-		Limit:  limit,
-		Search: search,
+	response, err := h.storage.RTransaction().GetList(context.Background(), models.RepositoryTransactionGetListRequest{
+		Page:       page,
+		Limit:      limit,
+		ProductID:  search,
+		BranchID:   c.Query("branch_id"),
+		SourceType: c.Query("source_type"),
+		SourceID:   c.Query("source_id"),
 	})
 	if err != nil {
 		handleResponse(c, "error while getting repository list", http.StatusInternalServerError, err.Error())
@@ -122,13 +137,13 @@ func (h Handler) GetRepositoryTransactionList(c *gin.Context) {
 // UpdateRepositoryTransaction godoc
 // @Router       /rtransaction/{id} [PUT]
 // @Summary      Update rtransaction
-// @Description  get rtransaction
+// @Description  correct a movement: it is reversed and the corrected movement is posted with a new id, which is returned
 // @Tags         rtransaction
 // @Accept       json
 // @Produce      json
 // @Param 		 id path string true "rtransaction_id"
 // @Param 		 rtransaction body models.UpdateRepositoryTransaction false "rtransaction"
-// @Success      200  {object}  models.Repository
+// @Success      200  {object}  models.RepositoryTransaction
 // @Failure      400  {object}  models.Response
 // @Failure      404  {object}  models.Response
 // @Failure      500  {object}  models.Response
@@ -141,13 +156,19 @@ func (h Handler) UpdateRepositoryTransaction(c *gin.Context) {
 		return
 	}
 
+	if err := validateMovement(rTransaction.RepositoryTransactionType, rTransaction.Quantity); err != nil {
+		handleResponse(c, "error while validating repository transaction", http.StatusBadRequest, err.Error())
+		return
+	}
+
 	rTransaction.ID = uid
-	if _, err := h.storage.RTransaction().Update(context.Background(), rTransaction); err != nil {
-		handleResponse(c, "error while updating repository transaction ", http.StatusInternalServerError, err.Error())
+	id, err := h.storage.RTransaction().Update(context.Background(), rTransaction)
+	if err != nil {
+		handleServiceError(c, "error while updating repository transaction", err)
 		return
 	}
 
-	updatedRTransaction, err := h.storage.RTransaction().GetByID(context.Background(), models.PrimaryKey{ID: uid})
+	updatedRTransaction, err := h.storage.RTransaction().GetByID(context.Background(), models.PrimaryKey{ID: id})
 	if err != nil {
 		handleResponse(c, "error while getting by ID", http.StatusInternalServerError, err.Error())
 		return
@@ -159,7 +180,7 @@ func (h Handler) UpdateRepositoryTransaction(c *gin.Context) {
 // DeleteRepositoryTransaction godoc
 // @Router       /rtransaction/{id} [DELETE]
 // @Summary      Delete rtransaction
-// @Description  delete rtransaction
+// @Description  cancel a movement with a reversing movement, the movement stays in the history
 // @Tags         rtransaction
 // @Accept       json
 // @Produce      json
@@ -172,9 +193,143 @@ func (h Handler) DeleteRepositoryTransaction(c *gin.Context) {
 	uid := c.Param("id")
 
 	if err := h.storage.RTransaction().Delete(context.Background(), uid); err != nil {
-		handleResponse(c, "error while deleting repository transaction ", http.StatusInternalServerError, err.Error())
+		handleServiceError(c, "error while deleting repository transaction", err)
+		return
+	}
+
+	handleResponse(c, "", http.StatusOK, "repository transaction reversed")
+}
+
+func validateMovement(transactionType string, quantity float64) error {
+	if transactionType != "plus" && transactionType != "minus" {
+		return errors.New("repository_transaction_type should be plus or minus")
+	}
+	if quantity <= 0 {
+		return errors.New("quantity should be positive")
+	}
+
+	return nil
+}
+
+// GetMovementHistory godoc
+// @Router       /rtransactions/history [GET]
+// @Summary      Get stock movement history
+// @Description  get movements of a product in a branch with opening and running balance
+// @Tags         rtransaction
+// @Accept       json
+// @Produce      json
+// @Param 		 product_id query string true "product_id"
+// @Param 		 branch_id query string true "branch_id"
+// @Param 		 from_date query string false "from date, 2006-01-02"
+// @Param 		 to_date query string false "to date, 2006-01-02"
+// @Success      200  {object}  models.MovementHistory
+// @Failure      400  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) GetMovementHistory(c *gin.Context) {
+	request := models.MovementHistoryRequest{
+		ProductID: c.Query("product_id"),
+		BranchID:  c.Query("branch_id"),
+		FromDate:  c.DefaultQuery("from_date", time.Now().AddDate(0, -1, 0).Format("2006-01-02")),
+		ToDate:    c.DefaultQuery("to_date", time.Now().Format("2006-01-02")),
+	}
+
+	if request.ProductID == "" || request.BranchID == "" {
+		handleResponse(c, "product and branch are required", http.StatusBadRequest, "product_id and branch_id should not be empty")
+		return
+	}
+
+	fromDate, err := time.Parse("2006-01-02", request.FromDate)
+	if err != nil {
+		handleResponse(c, "error while parsing from date", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	toDate, err := time.Parse("2006-01-02", request.ToDate)
+	if err != nil {
+		handleResponse(c, "error while parsing to date", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if toDate.Before(fromDate) {
+		handleResponse(c, "invalid period", http.StatusBadRequest, "to_date should not be before from_date")
+		return
+	}
+
+	response, err := h.storage.RTransaction().History(context.Background(), request)
+	if err != nil {
+		handleResponse(c, "error while getting movement history", http.StatusInternalServerError, err.Error())
 		return
 	}
 
-	handleResponse(c, "", http.StatusOK, "repository transaction deleted")
+	handleResponse(c, "", http.StatusOK, response)
+}
+
+// GetStockSnapshot godoc
+// @Router       /stock-snapshot [GET]
+// @Summary      Get stock snapshot
+// @Description  get stock per branch and product as of a moment, computed from repository transactions
+// @Tags         rtransaction
+// @Accept       json
+// @Produce      json
+// @Produce      text/csv
+// @Param 		 at query string false "2006-01-02 (end of the day) or RFC3339 timestamp, now by default"
+// @Param 		 branch_id query string false "branch_id"
+// @Param 		 format query string false "json or csv"
+// @Success      200  {object}  models.StockSnapshot
+// @Failure      400  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) GetStockSnapshot(c *gin.Context) {
+	at := time.Now()
+	if atStr := c.Query("at"); atStr != "" {
+		if day, err := time.ParseInLocation("2006-01-02", atStr, time.Local); err == nil {
+			at = day.Add(24*time.Hour - time.Microsecond)
+		} else if at, err = time.Parse(time.RFC3339, atStr); err != nil {
+			handleResponse(c, "error while parsing at", http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	// movements are stamped with the local time of the server, a moment given with another offset is moved to it
+	at = at.Local()
+
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "csv" {
+		handleResponse(c, "invalid format", http.StatusBadRequest, "format should be json or csv")
+		return
+	}
+
+	snapshot, err := h.storage.RTransaction().Snapshot(context.Background(), models.StockSnapshotRequest{
+		At:       at.Format("2006-01-02 15:04:05.999999"),
+		BranchID: c.Query("branch_id"),
+	})
+	if err != nil {
+		handleResponse(c, "error while getting stock snapshot", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if format == "json" {
+		handleResponse(c, "", http.StatusOK, snapshot)
+		return
+	}
+
+	c.Header("Content-Type", "text/csv")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="stock-%s.csv"`, at.Format("2006-01-02")))
+	c.Status(http.StatusOK)
+
+	w := csv.NewWriter(c.Writer)
+	w.Write([]string{"branch_id", "branch_name", "product_id", "product_name", "quantity", "unit_cost", "value"})
+	for _, item := range snapshot.Items {
+		w.Write([]string{
+			item.BranchID,
+			item.BranchName,
+			item.ProductID,
+			item.ProductName,
+			strconv.FormatFloat(item.Quantity, 'f', -1, 64),
+			item.UnitCost.String(),
+			item.Value.String(),
+		})
+	}
+	w.Write([]string{"", "", "", "total", strconv.FormatFloat(snapshot.TotalQuantity, 'f', -1, 64), "", snapshot.TotalValue.String()})
+	w.Flush()
 }
@@ -2,7 +2,9 @@ package handler
 
 import (
 	"context"
+	"errors"
 	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
 	"net/http"
 	"sell/api/models"
 	"strconv"
@@ -27,15 +29,9 @@ func (h Handler) CreateSale(c *gin.Context) {
 		return
 	}
 
-	id, err := h.storage.Sale().Create(context.Background(), sale)
+	createdSale, err := h.service.StartSale(context.Background(), sale)
 	if err != nil {
-		handleResponse(c, "error is while creating sale", http.StatusInternalServerError, err.Error())
-		return
-	}
-
-	createdBranch, err := h.storage.Sale().GetByID(context.Background(), id)
-	if err != nil {
-		handleResponse(c, "error is while getting by id", http.StatusInternalServerError, err.Error())
+		handleServiceError(c, "error is while creating sale", err)
 		return
 	}
 
@@ -43,7 +39,7 @@ func (h Handler) CreateSale(c *gin.Context) {
 		c,
 		"",
 		http.StatusCreated,
-		createdBranch,
+		createdSale,
 	)
 }
 
@@ -176,3 +172,92 @@ func (h Handler) DeleteSale(c *gin.Context) {
 
 	handleResponse(c, "", http.StatusOK, "sale deleted!")
 }
+
+// GetSaleReceipt godoc
+// @Router       /sale/{id}/receipt [GET]
+// @Summary      Get sale receipt
+// @Description  get the receipt of a sale with its lines, product names are in the language of Accept-Language
+// @Tags         sale
+// @Accept       json
+// @Produce      json
+// @Param 		 id path string true "sale_id"
+// @Param 		 Accept-Language header string false "language"
+// @Success      200  {object}  models.Receipt
+// @Failure      400  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) GetSaleReceipt(c *gin.Context) {
+	receipt, err := h.storage.Sale().Receipt(context.Background(), c.Param("id"))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			handleResponse(c, "sale not found", http.StatusNotFound, err.Error())
+			return
+		}
+		handleResponse(c, "error is while getting receipt", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	language := h.language(c)
+	for i, line := range receipt.Lines {
+		receipt.Lines[i].Name = translate(line.Name, line.NameTranslations, language)
+	}
+
+	handleResponse(c, "", http.StatusOK, receipt)
+}
+
+// CreateSalePayment godoc
+// @Router       /sale/{id}/payment [POST]
+// @Summary      Pay for a sale
+// @Description  register money received for a sale in progress in currency (the base currency when empty).
+// @Description  The amount is converted to the base currency at the exchange rate of now, change is given in the base currency
+// @Tags         sale
+// @Accept       json
+// @Produce      json
+// @Param 		 id path string true "sale_id"
+// @Param 		 payment body models.CreateSalePayment true "payment"
+// @Success      201  {object}  models.SalePayments
+// @Failure      400  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) CreateSalePayment(c *gin.Context) {
+	payment := models.CreateSalePayment{}
+	if err := c.ShouldBindJSON(&payment); err != nil {
+		handleResponse(c, "error is while reading body", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	payment.SaleID = c.Param("id")
+	payments, err := h.service.AddSalePayment(context.Background(), payment)
+	if err != nil {
+		handleServiceError(c, "error is while creating sale payment", err)
+		return
+	}
+
+	handleResponse(c, "", http.StatusCreated, payments)
+}
+
+// GetSalePayments godoc
+// @Router       /sale/{id}/payments [GET]
+// @Summary      Get sale payments
+// @Description  get payments of a sale with the total of its lines, the amount paid, what is left to pay and the change
+// @Tags         sale
+// @Accept       json
+// @Produce      json
+// @Param 		 id path string true "sale_id"
+// @Success      200  {object}  models.SalePayments
+// @Failure      400  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) GetSalePayments(c *gin.Context) {
+	payments, err := h.storage.Sale().GetPayments(context.Background(), c.Param("id"))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			handleResponse(c, "sale not found", http.StatusNotFound, err.Error())
+			return
+		}
+		handleResponse(c, "error is while getting sale payments", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	handleResponse(c, "", http.StatusOK, payments)
+}
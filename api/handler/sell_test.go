@@ -0,0 +1,563 @@
+package handler_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"sell/api"
+	"sell/api/handler"
+	"sell/api/models"
+	"sell/config"
+	"sell/pkg/fiscal"
+	"sell/pkg/media"
+	"sell/pkg/money"
+	"sell/pkg/xlsx"
+	"sell/service"
+	"sell/storage/memory"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// newServer serves the api over an in-memory storage, sales are fiscalized into a temporary directory.
+func newServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	cfg := config.Config{
+		MediaPath:           t.TempDir(),
+		DefaultLanguage:     "uz",
+		Languages:           []string{"uz", "ru", "en"},
+		BaseCurrency:        "UZS",
+		Currencies:          []string{"UZS", "USD"},
+		PricesIncludeTax:    true,
+		FiscalPath:          t.TempDir(),
+		FiscalRetryInterval: time.Second,
+		FiscalMaxAttempts:   3,
+	}
+
+	store := memory.New()
+	svc := service.New(cfg, store, fiscal.NewFile(cfg.FiscalPath))
+
+	server := httptest.NewServer(api.Router(cfg, handler.New(cfg, store, svc, media.NewLocal(cfg.MediaPath, cfg.MediaURL))))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// call sends body as JSON, checks the status of the response and returns the data of a successful one.
+func call[T any](t *testing.T, server *httptest.Server, method, path string, body any, status int) T {
+	t.Helper()
+
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	request, err := http.NewRequest(method, server.URL+path, reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	response, err := server.Client().Do(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer response.Body.Close()
+
+	data, err := io.ReadAll(response.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if response.StatusCode != status {
+		t.Fatalf("%s %s: status %d, want %d: %s", method, path, response.StatusCode, status, data)
+	}
+
+	result := struct {
+		Data T `json:"data"`
+	}{}
+	// failed requests have the error text for data
+	if status >= http.StatusMultipleChoices {
+		return result.Data
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		t.Fatalf("%s %s: %v: %s", method, path, err, data)
+	}
+	return result.Data
+}
+
+type shop struct {
+	branch     models.Branch
+	cashier    models.Staff
+	product    models.Product
+	repository models.Repository
+}
+
+// openShop creates a branch with a cashier and ten pieces of a product priced 12 000.
+func openShop(t *testing.T, server *httptest.Server) shop {
+	t.Helper()
+
+	branch := call[models.Branch](t, server, http.MethodPost, "/branch", models.CreateBranch{
+		Name:    "Chilonzor",
+		Address: "Bunyodkor 1",
+	}, http.StatusCreated)
+
+	tariff := call[models.StaffTariff](t, server, http.MethodPost, "/staff-tariff", models.CreateStaffTariff{
+		Name:          "cashier",
+		TariffType:    "fixed",
+		AmountForCash: 1000 * money.Unit,
+		AmountForCard: 500 * money.Unit,
+	}, http.StatusCreated)
+
+	cashier := call[models.Staff](t, server, http.MethodPost, "/staff", models.CreateStaff{
+		BranchID:  branch.ID,
+		TariffID:  tariff.ID,
+		StaffType: "cashier",
+		Name:      "Aziz",
+		BirthDate: "1995-04-12",
+		Login:     "aziz",
+		Password:  "secret123",
+	}, http.StatusCreated)
+
+	product := call[models.Product](t, server, http.MethodPost, "/product", models.CreateProduct{
+		Name:    "Milk",
+		Price:   12000 * money.Unit,
+		Barcode: "4006381333931",
+	}, http.StatusCreated)
+
+	repository := call[models.Repository](t, server, http.MethodPost, "/repository", models.CreateRepository{
+		ProductID: product.ID,
+		BranchID:  branch.ID,
+		Count:     10,
+	}, http.StatusCreated)
+
+	return shop{branch: branch, cashier: cashier, product: product, repository: repository}
+}
+
+func (s shop) startSale(t *testing.T, server *httptest.Server) models.Sale {
+	t.Helper()
+
+	return call[models.Sale](t, server, http.MethodPost, "/sell", models.CreateSale{
+		BranchID:    s.branch.ID,
+		CashierID:   s.cashier.ID,
+		PaymentType: "cash",
+		Status:      "in_process",
+		ClientName:  "Dilshod",
+	}, http.StatusOK)
+}
+
+func TestSellFlow(t *testing.T) {
+	server := newServer(t)
+	shop := openShop(t, server)
+
+	sale := shop.startSale(t, server)
+	if sale.Currency != "UZS" || !sale.TaxIncluded {
+		t.Fatalf("sale is in %s with tax included %v, want UZS with tax included", sale.Currency, sale.TaxIncluded)
+	}
+
+	call[models.Basket](t, server, http.MethodPost, "/barcode", models.Barcode{
+		SaleID:  sale.ID,
+		Barcode: "4006381333931",
+	}, http.StatusOK)
+	basket := call[models.Basket](t, server, http.MethodPost, "/barcode", models.Barcode{
+		SaleID:  sale.ID,
+		Barcode: "4006381333931",
+	}, http.StatusOK)
+	if basket.Quantity != 2 || basket.Price != 24000*money.Unit {
+		t.Fatalf("basket has %g for %s, want 2 for 24000.00", basket.Quantity, basket.Price)
+	}
+
+	payments := call[models.SalePayments](t, server, http.MethodPost, "/sale/"+sale.ID+"/payment", models.CreateSalePayment{
+		Currency: "UZS",
+		Amount:   30000 * money.Unit,
+	}, http.StatusCreated)
+	if payments.Due != 0 || payments.Change != 6000*money.Unit {
+		t.Fatalf("payments are due %s with change %s, want 0.00 and 6000.00", payments.Due, payments.Change)
+	}
+
+	finished := call[models.Sale](t, server, http.MethodPut, "/end-sell/"+sale.ID, models.SaleRequest{
+		Status: "success",
+	}, http.StatusOK)
+	if finished.Status != "success" || finished.Price != 24000*money.Unit {
+		t.Fatalf("sale is %s for %s, want success for 24000.00", finished.Status, finished.Price)
+	}
+	if finished.FiscalSign == "" || finished.FiscalizedAt == nil {
+		t.Fatal("finished sale is not fiscalized")
+	}
+
+	receipt := call[models.Receipt](t, server, http.MethodGet, "/sale/"+sale.ID+"/receipt", nil, http.StatusOK)
+	if len(receipt.Lines) != 1 || receipt.Lines[0].Quantity != 2 || receipt.Lines[0].UnitPrice != 12000*money.Unit {
+		t.Fatalf("receipt lines are %+v, want 2 of milk at 12000.00", receipt.Lines)
+	}
+	if receipt.Total != 24000*money.Unit || receipt.Paid != 30000*money.Unit || receipt.Change != 6000*money.Unit {
+		t.Fatalf("receipt total %s paid %s change %s, want 24000.00, 30000.00 and 6000.00", receipt.Total, receipt.Paid, receipt.Change)
+	}
+	if receipt.BranchName != shop.branch.Name {
+		t.Fatalf("receipt branch is %q, want %q", receipt.BranchName, shop.branch.Name)
+	}
+
+	repository := call[models.Repository](t, server, http.MethodGet, "/repository/"+shop.repository.ID, nil, http.StatusOK)
+	if repository.Count != 8 {
+		t.Fatalf("repository has %g after the sale, want 8", repository.Count)
+	}
+
+	call[models.Basket](t, server, http.MethodPost, "/barcode", models.Barcode{
+		SaleID:  sale.ID,
+		Barcode: "4006381333931",
+	}, http.StatusMultipleChoices)
+
+	refund := call[models.Refund](t, server, http.MethodPost, "/sale/"+sale.ID+"/refund", models.CreateRefund{
+		Reason:   "damaged",
+		Products: []models.CreateRefundProduct{{BasketID: basket.ID, Quantity: 1}},
+	}, http.StatusCreated)
+	if refund.Price != 12000*money.Unit || len(refund.Products) != 1 {
+		t.Fatalf("refund is %s for %d products, want 12000.00 for 1", refund.Price, len(refund.Products))
+	}
+	if refund.FiscalSign == "" {
+		t.Fatal("refund is not fiscalized")
+	}
+
+	repository = call[models.Repository](t, server, http.MethodGet, "/repository/"+shop.repository.ID, nil, http.StatusOK)
+	if repository.Count != 9 {
+		t.Fatalf("repository has %g after the refund, want 9", repository.Count)
+	}
+
+	outbox := call[models.FiscalEntriesResponse](t, server, http.MethodGet, "/fiscal-outbox?status=sent", nil, http.StatusOK)
+	if outbox.Count != 2 {
+		t.Fatalf("fiscal outbox has %d sent entries, want 2", outbox.Count)
+	}
+}
+
+func TestSellNotEnoughStock(t *testing.T) {
+	server := newServer(t)
+	shop := openShop(t, server)
+
+	sale := shop.startSale(t, server)
+	call[models.Basket](t, server, http.MethodPost, "/barcode", models.Barcode{
+		SaleID:  sale.ID,
+		Barcode: "4006381333931",
+		Count:   11,
+	}, http.StatusMultipleChoices)
+
+	call[models.Basket](t, server, http.MethodPost, "/barcode", models.Barcode{
+		SaleID:  sale.ID,
+		Barcode: "4000000000000",
+	}, http.StatusNotFound)
+}
+
+func TestSellOversizedCount(t *testing.T) {
+	server := newServer(t)
+	shop := openShop(t, server)
+
+	sale := shop.startSale(t, server)
+	call[models.Basket](t, server, http.MethodPost, "/barcode", models.Barcode{
+		SaleID:  sale.ID,
+		Barcode: "4006381333931",
+		Count:   1e18,
+	}, http.StatusBadRequest)
+
+	// the server is still up and nothing was added by the rejected scan
+	basket := call[models.Basket](t, server, http.MethodPost, "/barcode", models.Barcode{
+		SaleID:  sale.ID,
+		Barcode: "4006381333931",
+	}, http.StatusOK)
+	if basket.Quantity != 1 {
+		t.Errorf("basket quantity is %g, want 1", basket.Quantity)
+	}
+}
+
+func TestSellCancel(t *testing.T) {
+	server := newServer(t)
+	shop := openShop(t, server)
+
+	sale := shop.startSale(t, server)
+	call[models.Basket](t, server, http.MethodPost, "/barcode", models.Barcode{
+		SaleID:  sale.ID,
+		Barcode: "4006381333931",
+		Count:   3,
+	}, http.StatusOK)
+
+	call[string](t, server, http.MethodPut, "/end-sell/"+sale.ID, models.SaleRequest{Status: "cancel"}, http.StatusOK)
+	call[models.Sale](t, server, http.MethodPut, "/end-sell/"+sale.ID, models.SaleRequest{Status: "success"}, http.StatusBadRequest)
+
+	repository := call[models.Repository](t, server, http.MethodGet, "/repository/"+shop.repository.ID, nil, http.StatusOK)
+	if repository.Count != 10 {
+		t.Fatalf("repository has %g after a canceled sale, want 10", repository.Count)
+	}
+
+	outbox := call[models.FiscalEntriesResponse](t, server, http.MethodGet, "/fiscal-outbox", nil, http.StatusOK)
+	if outbox.Count != 0 {
+		t.Fatalf("fiscal outbox has %d entries for a canceled sale, want 0", outbox.Count)
+	}
+}
+
+func TestSellFinishTwice(t *testing.T) {
+	server := newServer(t)
+	shop := openShop(t, server)
+
+	sale := shop.startSale(t, server)
+	call[models.Basket](t, server, http.MethodPost, "/barcode", models.Barcode{
+		SaleID:  sale.ID,
+		Barcode: "4006381333931",
+		Count:   4,
+	}, http.StatusOK)
+
+	call[models.Sale](t, server, http.MethodPut, "/end-sell/"+sale.ID, models.SaleRequest{Status: "success"}, http.StatusOK)
+	call[models.Sale](t, server, http.MethodPut, "/end-sell/"+sale.ID, models.SaleRequest{Status: "success"}, http.StatusBadRequest)
+	call[string](t, server, http.MethodPut, "/end-sell/"+sale.ID, models.SaleRequest{Status: "cancel"}, http.StatusBadRequest)
+
+	repository := call[models.Repository](t, server, http.MethodGet, "/repository/"+shop.repository.ID, nil, http.StatusOK)
+	if repository.Count != 6 {
+		t.Fatalf("repository has %g after a sale finished twice, want 6", repository.Count)
+	}
+
+	outbox := call[models.FiscalEntriesResponse](t, server, http.MethodGet, "/fiscal-outbox", nil, http.StatusOK)
+	if outbox.Count != 1 {
+		t.Fatalf("fiscal outbox has %d entries for a sale finished twice, want 1", outbox.Count)
+	}
+}
+
+func TestSellFinishShortage(t *testing.T) {
+	server := newServer(t)
+	shop := openShop(t, server)
+
+	sale := shop.startSale(t, server)
+	call[models.Basket](t, server, http.MethodPost, "/barcode", models.Barcode{
+		SaleID:  sale.ID,
+		Barcode: "4006381333931",
+		Count:   5,
+	}, http.StatusOK)
+
+	// the stock is sold at another desk while the sale is open
+	call[models.Repository](t, server, http.MethodPut, "/repository/"+shop.repository.ID, models.UpdateRepository{
+		ProductID: shop.product.ID,
+		BranchID:  shop.branch.ID,
+		Count:     3,
+	}, http.StatusOK)
+
+	call[models.Sale](t, server, http.MethodPut, "/end-sell/"+sale.ID, models.SaleRequest{Status: "success"}, http.StatusMultipleChoices)
+
+	repository := call[models.Repository](t, server, http.MethodGet, "/repository/"+shop.repository.ID, nil, http.StatusOK)
+	if repository.Count != 3 {
+		t.Fatalf("repository has %g after a sale short of stock, want 3", repository.Count)
+	}
+
+	receipt := call[models.Receipt](t, server, http.MethodGet, "/sale/"+sale.ID+"/receipt", nil, http.StatusOK)
+	if receipt.Status != "in_process" {
+		t.Fatalf("sale short of stock is %s, want in_process", receipt.Status)
+	}
+
+	outbox := call[models.FiscalEntriesResponse](t, server, http.MethodGet, "/fiscal-outbox", nil, http.StatusOK)
+	if outbox.Count != 0 {
+		t.Fatalf("fiscal outbox has %d entries for a sale short of stock, want 0", outbox.Count)
+	}
+}
+
+func TestSaleNotFound(t *testing.T) {
+	server := newServer(t)
+
+	call[models.Sale](t, server, http.MethodPut, "/end-sell/00000000-0000-0000-0000-000000000000", models.SaleRequest{
+		Status: "success",
+	}, http.StatusNotFound)
+	call[models.Receipt](t, server, http.MethodGet, "/sale/00000000-0000-0000-0000-000000000000/receipt", nil, http.StatusNotFound)
+}
+
+func TestUpdateProductKeepsUnit(t *testing.T) {
+	server := newServer(t)
+	shop := openShop(t, server)
+
+	precision := 3
+	product := call[models.Product](t, server, http.MethodPost, "/product", models.CreateProduct{
+		Name:      "Cheese",
+		Price:     90000 * money.Unit,
+		Unit:      "kg",
+		Precision: &precision,
+	}, http.StatusCreated)
+	call[models.Repository](t, server, http.MethodPost, "/repository", models.CreateRepository{
+		ProductID: product.ID,
+		BranchID:  shop.branch.ID,
+		Count:     1.25,
+	}, http.StatusCreated)
+
+	// a client which does not know about units renames the product
+	updated := call[models.Product](t, server, http.MethodPut, "/product/"+product.ID, models.UpdateProduct{
+		Name:  "Gouda",
+		Price: 90000 * money.Unit,
+	}, http.StatusOK)
+	if updated.Unit != "kg" || updated.Precision != 3 {
+		t.Fatalf("product is sold by %s with precision %d, want kg with precision 3", updated.Unit, updated.Precision)
+	}
+
+	call[models.Product](t, server, http.MethodPut, "/product/"+product.ID, models.UpdateProduct{
+		Name:  "Gouda",
+		Price: 90000 * money.Unit,
+		Unit:  "piece",
+	}, http.StatusBadRequest)
+
+	precision = 1
+	call[models.Product](t, server, http.MethodPut, "/product/"+product.ID, models.UpdateProduct{
+		Name:      "Gouda",
+		Price:     90000 * money.Unit,
+		Precision: &precision,
+	}, http.StatusBadRequest)
+}
+
+func TestReverseMovement(t *testing.T) {
+	server := newServer(t)
+	shop := openShop(t, server)
+
+	movement := call[models.RepositoryTransaction](t, server, http.MethodPost, "/rtransaction", models.CreateRepositoryTransaction{
+		BranchID:                  shop.branch.ID,
+		ProductID:                 shop.product.ID,
+		RepositoryTransactionType: "plus",
+		SourceType:                "adjustment",
+		Quantity:                  2,
+	}, http.StatusCreated)
+
+	corrected := call[models.RepositoryTransaction](t, server, http.MethodPut, "/rtransaction/"+movement.ID, models.UpdateRepositoryTransaction{
+		BranchID:                  shop.branch.ID,
+		ProductID:                 shop.product.ID,
+		RepositoryTransactionType: "plus",
+		SourceType:                "adjustment",
+		Quantity:                  3,
+	}, http.StatusOK)
+	if corrected.ID == movement.ID || corrected.Quantity != 3 {
+		t.Fatalf("correction is %+v, want a new movement of 3", corrected)
+	}
+
+	call[string](t, server, http.MethodDelete, "/rtransaction/"+movement.ID, nil, http.StatusBadRequest)
+	call[string](t, server, http.MethodDelete, "/rtransaction/"+corrected.ID, nil, http.StatusOK)
+	call[string](t, server, http.MethodDelete, "/rtransaction/"+uuid.NewString(), nil, http.StatusNotFound)
+
+	// fixing a difference needs to know whether the counts or the ledger are right
+	call[string](t, server, http.MethodPost, "/repositories/reconcile", nil, http.StatusBadRequest)
+	reconcile := call[models.ReconcileResponse](t, server, http.MethodPost, "/repositories/reconcile?mode=counts", nil, http.StatusOK)
+	if !reconcile.Fixed {
+		t.Fatalf("reconcile %+v is not fixed", reconcile)
+	}
+}
+
+func TestUpdateIncomeProductExpiryDate(t *testing.T) {
+	server := newServer(t)
+	shop := openShop(t, server)
+
+	income := call[models.Income](t, server, http.MethodPost, "/income", models.CreateIncome{
+		BranchID: shop.branch.ID,
+	}, http.StatusOK)
+
+	line := models.CreateIncomeProduct{
+		IncomeID:   income.ID,
+		ProductID:  shop.product.ID,
+		Price:      9000 * money.Unit,
+		Count:      4,
+		ExpiryDate: "2030-03-31",
+	}
+	incomeProduct := call[models.IncomeProduct](t, server, http.MethodPost, "/income-product", line, http.StatusOK)
+
+	update := models.UpdateIncomeProduct{
+		IncomeID:   income.ID,
+		ProductID:  shop.product.ID,
+		Price:      9000 * money.Unit,
+		Count:      4,
+		ExpiryDate: "2030-31-03",
+	}
+	call[string](t, server, http.MethodPut, "/income-product/"+incomeProduct.ID, update, http.StatusBadRequest)
+
+	update.ExpiryDate = "2030-04-30"
+	call[models.IncomeProduct](t, server, http.MethodPut, "/income-product/"+incomeProduct.ID, update, http.StatusOK)
+}
+
+func TestLabelUnprintableName(t *testing.T) {
+	server := newServer(t)
+	shop := openShop(t, server)
+
+	product := call[models.Product](t, server, http.MethodPost, "/product", models.CreateProduct{
+		Name:  "Молоко",
+		Price: 12000 * money.Unit,
+	}, http.StatusCreated)
+
+	// the pdf fonts have no Cyrillic letters, such names would be printed as question marks
+	call[string](t, server, http.MethodPost, "/labels", models.LabelRequest{
+		Products: []models.LabelProduct{{ProductID: shop.product.ID}, {ProductID: product.ID}},
+	}, http.StatusBadRequest)
+
+	data, err := json.Marshal(models.LabelRequest{
+		Products: []models.LabelProduct{{ProductID: product.ID}},
+		Format:   "svg",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	response, err := server.Client().Post(server.URL+"/labels", "application/json", bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer response.Body.Close()
+
+	svg, err := io.ReadAll(response.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if response.StatusCode != http.StatusOK || !bytes.Contains(svg, []byte("Молоко")) {
+		t.Fatalf("svg label: status %d: %s", response.StatusCode, svg)
+	}
+}
+
+func TestImportProductsXLSX(t *testing.T) {
+	server := newServer(t)
+
+	workbook := bytes.Buffer{}
+	if err := xlsx.Write(&workbook, [][]string{
+		{"name", "price", "barcode"},
+		// a UPC-A typed into a number cell loses its leading zero
+		{"Cola", "9000", "36000291452"},
+		{"Bread", "4000", "1234"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	body := bytes.Buffer{}
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile("file", "products.xlsx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := part.Write(workbook.Bytes()); err != nil {
+		t.Fatal(err)
+	}
+	if err := form.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	response, err := server.Client().Post(server.URL+"/product/import", form.FormDataContentType(), &body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer response.Body.Close()
+
+	result := struct {
+		Data models.ProductImportResponse `json:"data"`
+	}{}
+	if err := json.NewDecoder(response.Body).Decode(&result); err != nil {
+		t.Fatal(err)
+	}
+	if response.StatusCode != http.StatusOK || result.Data.Created != 2 {
+		t.Fatalf("import: status %d: %+v", response.StatusCode, result.Data)
+	}
+
+	cola := call[models.Product](t, server, http.MethodGet, "/barcode/036000291452", nil, http.StatusOK)
+	if cola.Name != "Cola" {
+		t.Fatalf("barcode 036000291452 is of %q, want Cola", cola.Name)
+	}
+	// short item numbers are not taken for codes which lost their zeros
+	call[models.Product](t, server, http.MethodGet, "/barcode/1234", nil, http.StatusOK)
+}
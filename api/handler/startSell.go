@@ -27,15 +27,9 @@ func (h Handler) StartSell(c *gin.Context) {
 		return
 	}
 
-	saleID, err := h.storage.Sale().Create(context.Background(), sell)
+	sale, err := h.service.StartSale(context.Background(), sell)
 	if err != nil {
-		handleResponse(c, "error is while creating sale", http.StatusInternalServerError, err.Error())
-		return
-	}
-
-	sale, err := h.storage.Sale().GetByID(context.Background(), saleID)
-	if err != nil {
-		handleResponse(c, "error is while getting sale by id", http.StatusInternalServerError, err.Error())
+		handleServiceError(c, "error is while creating sale", err)
 		return
 	}
 
@@ -0,0 +1,252 @@
+package handler
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"sell/api/models"
+	"strconv"
+)
+
+// CreateSupplier godoc
+// @Router       /supplier [POST]
+// @Summary      Create a new supplier
+// @Description  create a new supplier
+// @Tags         supplier
+// @Accept       json
+// @Produce      json
+// @Param 		 supplier body models.CreateSupplier false "supplier"
+// @Success      200  {object}  models.Supplier
+// @Failure      400  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) CreateSupplier(c *gin.Context) {
+	supplier := models.CreateSupplier{}
+	if err := c.ShouldBindJSON(&supplier); err != nil {
+		handleResponse(c, "error is while reading body", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	id, err := h.storage.Supplier().Create(context.Background(), supplier)
+	if err != nil {
+		handleResponse(c, "error is while creating supplier", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	createdSupplier, err := h.storage.Supplier().GetByID(context.Background(), id)
+	if err != nil {
+		handleResponse(c, "error is while getting by id", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	handleResponse(c, "", http.StatusCreated, createdSupplier)
+}
+
+// GetSupplier godoc
+// @Router       /supplier/{id} [GET]
+// @Summary      Get supplier by id
+// @Description  get supplier by id with the current debt balance
+// @Tags         supplier
+// @Accept       json
+// @Produce      json
+// @Param 		 id path string true "supplier_id"
+// @Success      200  {object}  models.Supplier
+// @Failure      400  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) GetSupplier(c *gin.Context) {
+	uid := c.Param("id")
+
+	supplier, err := h.storage.Supplier().GetByID(context.Background(), uid)
+	if err != nil {
+		handleResponse(c, "error is while getting by id", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	handleResponse(c, "", http.StatusOK, supplier)
+}
+
+// GetSupplierList godoc
+// @Router       /suppliers [GET]
+// @Summary      Get supplier list
+// @Description  get supplier list
+// @Tags         supplier
+// @Accept       json
+// @Produce      json
+// @Param 		 page query string false "page"
+// @Param 		 limit query string false "limit"
+// @Param 		 search query string false "search"
+// @Success      200  {object}  models.SupplierResponse
+// @Failure      400  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) GetSupplierList(c *gin.Context) {
+	var (
+		page, limit int
+		err         error
+	)
+
+	pageStr := c.DefaultQuery("page", "1")
+	page, err = strconv.Atoi(pageStr)
+	if err != nil {
+		handleResponse(c, "error is while converting page", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	limitStr := c.DefaultQuery("limit", "10")
+	limit, err = strconv.Atoi(limitStr)
+	if err != nil {
+		handleResponse(c, "error is while converting limit", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	suppliers, err := h.storage.Supplier().GetList(context.Background(), models.GetListRequest{
+		Page:   page,
+		Limit:  limit,
+		Search: c.Query("search"),
+	})
+	if err != nil {
+		handleResponse(c, "error is while getting list", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	handleResponse(c, "", http.StatusOK, suppliers)
+}
+
+// UpdateSupplier godoc
+// @Router       /supplier/{id} [PUT]
+// @Summary      Update supplier
+// @Description  update supplier
+// @Tags         supplier
+// @Accept       json
+// @Produce      json
+// @Param 		 id path string true "supplier_id"
+// @Param 		 supplier body models.UpdateSupplier false "supplier"
+// @Success      200  {object}  models.Supplier
+// @Failure      400  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) UpdateSupplier(c *gin.Context) {
+	uid := c.Param("id")
+
+	supplier := models.UpdateSupplier{}
+	if err := c.ShouldBindJSON(&supplier); err != nil {
+		handleResponse(c, "error is while reading body", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	supplier.ID = uid
+	id, err := h.storage.Supplier().Update(context.Background(), supplier)
+	if err != nil {
+		handleResponse(c, "error is while updating", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	updatedSupplier, err := h.storage.Supplier().GetByID(context.Background(), id)
+	if err != nil {
+		handleResponse(c, "error is while getting by id", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	handleResponse(c, "", http.StatusOK, updatedSupplier)
+}
+
+// DeleteSupplier godoc
+// @Router       /supplier/{id} [DELETE]
+// @Summary      Delete supplier
+// @Description  delete supplier
+// @Tags         supplier
+// @Accept       json
+// @Produce      json
+// @Param 		 id path string true "supplier_id"
+// @Success      200  {object}  models.Response
+// @Failure      400  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) DeleteSupplier(c *gin.Context) {
+	uid := c.Param("id")
+	if err := h.storage.Supplier().Delete(context.Background(), uid); err != nil {
+		handleResponse(c, "error is while deleting", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	handleResponse(c, "", http.StatusOK, "supplier deleted!")
+}
+
+// CreateSupplierPayment godoc
+// @Router       /supplier/{id}/payment [POST]
+// @Summary      Pay supplier
+// @Description  register a payment to a supplier in currency (the base currency when empty), it decreases
+// @Description  the supplier debt by the amount converted to the base currency at the exchange rate of now
+// @Tags         supplier
+// @Accept       json
+// @Produce      json
+// @Param 		 id path string true "supplier_id"
+// @Param 		 payment body models.CreateSupplierPayment true "payment"
+// @Success      200  {object}  models.Supplier
+// @Failure      400  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) CreateSupplierPayment(c *gin.Context) {
+	payment := models.CreateSupplierPayment{}
+	if err := c.ShouldBindJSON(&payment); err != nil {
+		handleResponse(c, "error is while reading body", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	payment.SupplierID = c.Param("id")
+	supplier, err := h.service.PaySupplier(context.Background(), payment)
+	if err != nil {
+		handleServiceError(c, "error is while creating supplier payment", err)
+		return
+	}
+
+	handleResponse(c, "", http.StatusCreated, supplier)
+}
+
+// GetSupplierPaymentList godoc
+// @Router       /supplier/{id}/payments [GET]
+// @Summary      Get supplier payments
+// @Description  get payments made to a supplier
+// @Tags         supplier
+// @Accept       json
+// @Produce      json
+// @Param 		 id path string true "supplier_id"
+// @Param 		 page query string false "page"
+// @Param 		 limit query string false "limit"
+// @Success      200  {object}  models.SupplierPaymentResponse
+// @Failure      400  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) GetSupplierPaymentList(c *gin.Context) {
+	var (
+		page, limit int
+		err         error
+	)
+
+	pageStr := c.DefaultQuery("page", "1")
+	page, err = strconv.Atoi(pageStr)
+	if err != nil {
+		handleResponse(c, "error is while converting page", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	limitStr := c.DefaultQuery("limit", "10")
+	limit, err = strconv.Atoi(limitStr)
+	if err != nil {
+		handleResponse(c, "error is while converting limit", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	payments, err := h.storage.Supplier().GetPaymentList(context.Background(), models.SupplierPaymentGetListRequest{
+		Page:       page,
+		Limit:      limit,
+		SupplierID: c.Param("id"),
+	})
+	if err != nil {
+		handleResponse(c, "error is while getting supplier payments", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	handleResponse(c, "", http.StatusOK, payments)
+}
@@ -0,0 +1,366 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"net/http"
+	"sell/api/models"
+	"sell/pkg/money"
+	"strconv"
+	"time"
+)
+
+// CreateTaxRate godoc
+// @Router       /tax-rate [POST]
+// @Summary      Create a new tax rate
+// @Description  create a tax rate in percent, 12 is 12 percent. Assign it to products or categories to tax their sales
+// @Tags         tax
+// @Accept       json
+// @Produce      json
+// @Param 		 tax-rate body models.CreateTaxRate true "tax-rate"
+// @Success      201  {object}  models.TaxRate
+// @Failure      400  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) CreateTaxRate(c *gin.Context) {
+	rate := models.CreateTaxRate{}
+	if err := c.ShouldBindJSON(&rate); err != nil {
+		handleResponse(c, "error is while reading body", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if status, err := checkTaxRate(rate.Name, rate.Rate); err != nil {
+		handleResponse(c, "invalid tax rate", status, err.Error())
+		return
+	}
+
+	id, err := h.storage.TaxRate().Create(context.Background(), rate)
+	if err != nil {
+		handleResponse(c, "error is while creating tax rate", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	createdRate, err := h.storage.TaxRate().GetByID(context.Background(), id)
+	if err != nil {
+		handleResponse(c, "error is while getting by id", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	handleResponse(c, "", http.StatusCreated, createdRate)
+}
+
+// GetTaxRate godoc
+// @Router       /tax-rate/{id} [GET]
+// @Summary      Get tax rate
+// @Description  get tax rate
+// @Tags         tax
+// @Accept       json
+// @Produce      json
+// @Param 		 id path string true "tax_rate_id"
+// @Success      200  {object}  models.TaxRate
+// @Failure      400  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) GetTaxRate(c *gin.Context) {
+	rate, err := h.storage.TaxRate().GetByID(context.Background(), c.Param("id"))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			handleResponse(c, "tax rate not found", http.StatusNotFound, err.Error())
+			return
+		}
+		handleResponse(c, "error is while getting by id", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	handleResponse(c, "", http.StatusOK, rate)
+}
+
+// GetTaxRateList godoc
+// @Router       /tax-rates [GET]
+// @Summary      Get tax rate list
+// @Description  get tax rates ordered by rate
+// @Tags         tax
+// @Accept       json
+// @Produce      json
+// @Param 		 page query string false "page"
+// @Param 		 limit query string false "limit"
+// @Param 		 search query string false "search"
+// @Success      200  {object}  models.TaxRatesResponse
+// @Failure      400  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) GetTaxRateList(c *gin.Context) {
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil {
+		handleResponse(c, "error is while converting page", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil {
+		handleResponse(c, "error is while converting limit", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	rates, err := h.storage.TaxRate().GetList(context.Background(), models.GetListRequest{
+		Page:   page,
+		Limit:  limit,
+		Search: c.Query("search"),
+	})
+	if err != nil {
+		handleResponse(c, "error is while getting list", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	handleResponse(c, "", http.StatusOK, rates)
+}
+
+// UpdateTaxRate godoc
+// @Router       /tax-rate/{id} [PUT]
+// @Summary      Update tax rate
+// @Description  update tax rate, sales which were checked out keep the tax they were calculated with
+// @Tags         tax
+// @Accept       json
+// @Produce      json
+// @Param 		 id path string true "tax_rate_id"
+// @Param 		 tax-rate body models.UpdateTaxRate true "tax-rate"
+// @Success      200  {object}  models.TaxRate
+// @Failure      400  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) UpdateTaxRate(c *gin.Context) {
+	rate := models.UpdateTaxRate{}
+	if err := c.ShouldBindJSON(&rate); err != nil {
+		handleResponse(c, "error is while reading body", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if status, err := checkTaxRate(rate.Name, rate.Rate); err != nil {
+		handleResponse(c, "invalid tax rate", status, err.Error())
+		return
+	}
+
+	rate.ID = c.Param("id")
+	id, err := h.storage.TaxRate().Update(context.Background(), rate)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			handleResponse(c, "tax rate not found", http.StatusNotFound, err.Error())
+			return
+		}
+		handleResponse(c, "error is while updating tax rate", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	updatedRate, err := h.storage.TaxRate().GetByID(context.Background(), id)
+	if err != nil {
+		handleResponse(c, "error is while getting by id", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	handleResponse(c, "", http.StatusOK, updatedRate)
+}
+
+// DeleteTaxRate godoc
+// @Router       /tax-rate/{id} [DELETE]
+// @Summary      Delete tax rate
+// @Description  delete tax rate, products and categories which had it are taxed as if they had no rate of their own
+// @Tags         tax
+// @Accept       json
+// @Produce      json
+// @Param 		 id path string true "tax_rate_id"
+// @Success      200  {object}  models.Response
+// @Failure      400  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) DeleteTaxRate(c *gin.Context) {
+	if err := h.storage.TaxRate().Delete(context.Background(), c.Param("id")); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			handleResponse(c, "tax rate not found", http.StatusNotFound, err.Error())
+			return
+		}
+		handleResponse(c, "error is while deleting tax rate", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	handleResponse(c, "", http.StatusOK, "tax rate deleted")
+}
+
+// SetProductTaxRate godoc
+// @Router       /product/{id}/tax-rate [PUT]
+// @Summary      Set product tax rate
+// @Description  tax the product at tax_rate_id, an empty tax_rate_id makes the product taxed
+// @Description  at the rate of the product it is a variant of or of its category
+// @Tags         tax
+// @Accept       json
+// @Produce      json
+// @Param 		 id path string true "product_id"
+// @Param 		 tax-rate body models.SetTaxRate true "tax-rate"
+// @Success      200  {object}  models.ProductTaxRate
+// @Failure      400  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) SetProductTaxRate(c *gin.Context) {
+	request := models.SetTaxRate{}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		handleResponse(c, "error is while reading body", http.StatusBadRequest, err.Error())
+		return
+	}
+	request.ID = c.Param("id")
+
+	if !h.taxRateExists(c, request.TaxRateID) {
+		return
+	}
+
+	if err := h.storage.TaxRate().SetProductTaxRate(context.Background(), request); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			handleResponse(c, "product not found", http.StatusNotFound, err.Error())
+			return
+		}
+		handleResponse(c, "error is while setting product tax rate", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	rate, err := h.storage.TaxRate().GetProductTaxRate(context.Background(), request.ID)
+	if err != nil {
+		handleResponse(c, "error is while getting product tax rate", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	handleResponse(c, "", http.StatusOK, rate)
+}
+
+// GetProductTaxRate godoc
+// @Router       /product/{id}/tax-rate [GET]
+// @Summary      Get product tax rate
+// @Description  get the rate the product is taxed at and whether it is the rate of the product,
+// @Description  of the product it is a variant of (parent) or of a category
+// @Tags         tax
+// @Accept       json
+// @Produce      json
+// @Param 		 id path string true "product_id"
+// @Success      200  {object}  models.ProductTaxRate
+// @Failure      400  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) GetProductTaxRate(c *gin.Context) {
+	rate, err := h.storage.TaxRate().GetProductTaxRate(context.Background(), c.Param("id"))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			handleResponse(c, "product not found", http.StatusNotFound, err.Error())
+			return
+		}
+		handleResponse(c, "error is while getting product tax rate", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	handleResponse(c, "", http.StatusOK, rate)
+}
+
+// SetCategoryTaxRate godoc
+// @Router       /category/{id}/tax-rate [PUT]
+// @Summary      Set category tax rate
+// @Description  tax products of the category and of its subcategories without a rate of their own at tax_rate_id,
+// @Description  an empty tax_rate_id removes the rate of the category
+// @Tags         tax
+// @Accept       json
+// @Produce      json
+// @Param 		 id path string true "category_id"
+// @Param 		 tax-rate body models.SetTaxRate true "tax-rate"
+// @Success      200  {object}  models.Response
+// @Failure      400  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) SetCategoryTaxRate(c *gin.Context) {
+	request := models.SetTaxRate{}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		handleResponse(c, "error is while reading body", http.StatusBadRequest, err.Error())
+		return
+	}
+	request.ID = c.Param("id")
+
+	if !h.taxRateExists(c, request.TaxRateID) {
+		return
+	}
+
+	if err := h.storage.TaxRate().SetCategoryTaxRate(context.Background(), request); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			handleResponse(c, "category not found", http.StatusNotFound, err.Error())
+			return
+		}
+		handleResponse(c, "error is while setting category tax rate", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	handleResponse(c, "", http.StatusOK, "tax rate set")
+}
+
+// GetTaxReport godoc
+// @Router       /tax-report [GET]
+// @Summary      Tax report
+// @Description  net, tax and total of sales checked out in a period by branch and tax rate, dates are in 2006-01-02 format
+// @Tags         tax
+// @Accept       json
+// @Produce      json
+// @Param 		 branch_id query string false "branch_id"
+// @Param 		 from_date query string false "from_date"
+// @Param 		 to_date query string false "to_date"
+// @Success      200  {object}  models.TaxReport
+// @Failure      400  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) GetTaxReport(c *gin.Context) {
+	request := models.TaxReportRequest{
+		BranchID: c.Query("branch_id"),
+		FromDate: c.Query("from_date"),
+		ToDate:   c.Query("to_date"),
+	}
+
+	for _, date := range []string{request.FromDate, request.ToDate} {
+		if date == "" {
+			continue
+		}
+		if _, err := time.Parse("2006-01-02", date); err != nil {
+			handleResponse(c, "error is while parsing date", http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	report, err := h.storage.Sale().TaxReport(context.Background(), request)
+	if err != nil {
+		handleResponse(c, "error is while getting tax report", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	handleResponse(c, "success", http.StatusOK, report)
+}
+
+// checkTaxRate returns the status to respond with when the name or the rate are not valid.
+func checkTaxRate(name string, rate money.Money) (int, error) {
+	if name == "" {
+		return http.StatusBadRequest, errors.New("name should not be empty")
+	}
+	if rate < 0 || rate >= 100*money.Unit {
+		return http.StatusBadRequest, errors.New("rate should be a percent from 0 to 100")
+	}
+	return http.StatusOK, nil
+}
+
+// taxRateExists writes a response and returns false when id is not empty and is not a tax rate.
+func (h Handler) taxRateExists(c *gin.Context, id string) bool {
+	if id == "" {
+		return true
+	}
+
+	if _, err := h.storage.TaxRate().GetByID(context.Background(), id); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			handleResponse(c, "tax rate not found", http.StatusBadRequest, err.Error())
+			return false
+		}
+		handleResponse(c, "error is while getting tax rate", http.StatusInternalServerError, err.Error())
+		return false
+	}
+
+	return true
+}
@@ -2,11 +2,10 @@ package handler
 
 import (
 	"context"
-	"fmt"
 	"github.com/gin-gonic/gin"
-	"math"
 	"net/http"
 	"sell/api/models"
+	"sell/pkg/money"
 	"strconv"
 )
 
@@ -86,8 +85,8 @@ func (h Handler) GetTransaction(c *gin.Context) {
 func (h Handler) GetTransactionList(c *gin.Context) {
 	var (
 		page, limit int
-		fromAmount  float64
-		toAmount    float64
+		fromAmount  money.Money
+		toAmount    money.Money
 		err         error
 	)
 
@@ -106,14 +105,14 @@ func (h Handler) GetTransactionList(c *gin.Context) {
 	}
 
 	fromAmountStr := c.DefaultQuery("from-amount", "0")
-	fromAmount, err = strconv.ParseFloat(fromAmountStr, 64)
+	fromAmount, err = money.Parse(fromAmountStr)
 	if err != nil {
 		handleResponse(c, "error is while converting from amount", http.StatusBadRequest, err.Error())
 		return
 	}
 
-	toAmountStr := c.DefaultQuery("to-amount", fmt.Sprintf("%f", math.MaxFloat64))
-	toAmount, err = strconv.ParseFloat(toAmountStr, 64)
+	toAmountStr := c.DefaultQuery("to-amount", money.Max.String())
+	toAmount, err = money.Parse(toAmountStr)
 	if err != nil {
 		handleResponse(c, "error is while converting to amount", http.StatusBadRequest, err.Error())
 		return
@@ -0,0 +1,293 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"sell/api/models"
+	"sell/storage"
+	"strconv"
+	"time"
+)
+
+// CreateWriteOff godoc
+// @Router       /write-off [POST]
+// @Summary      Create a new write-off
+// @Description  create a new write-off document waiting for manager approval
+// @Tags         write-off
+// @Accept       json
+// @Produce      json
+// @Param 		 write-off body models.CreateWriteOff false "write-off"
+// @Success      200  {object}  models.WriteOff
+// @Failure      400  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) CreateWriteOff(c *gin.Context) {
+	writeOff := models.CreateWriteOff{}
+	if err := c.ShouldBindJSON(&writeOff); err != nil {
+		handleResponse(c, "error is while reading body", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+
+	createdWriteOff, err := h.service.CreateWriteOff(ctx, writeOff)
+	if err != nil {
+		handleServiceError(c, "error is while creating write-off", err)
+		return
+	}
+
+	handleResponse(c, "success", http.StatusCreated, createdWriteOff)
+}
+
+// GetWriteOff godoc
+// @Router       /write-off/{id} [GET]
+// @Summary      Get write-off
+// @Description  get write-off with its products
+// @Tags         write-off
+// @Accept       json
+// @Produce      json
+// @Param 		 id path string true "write_off_id"
+// @Success      200  {object}  models.WriteOff
+// @Failure      400  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) GetWriteOff(c *gin.Context) {
+	id := c.Param("id")
+
+	writeOff, err := h.storage.WriteOff().GetByID(context.Background(), id)
+	if err != nil {
+		handleResponse(c, "error is while getting by id", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	handleResponse(c, "success", http.StatusOK, writeOff)
+}
+
+// GetWriteOffList godoc
+// @Router       /write-offs [GET]
+// @Summary      Get write-off list
+// @Description  get write-off list
+// @Tags         write-off
+// @Accept       json
+// @Produce      json
+// @Param 		 page query string false "page"
+// @Param 		 limit query string false "limit"
+// @Param 		 branch_id query string false "branch_id"
+// @Param 		 reason query string false "reason"
+// @Param 		 status query string false "status"
+// @Success      200  {object}  models.WriteOffResponse
+// @Failure      400  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) GetWriteOffList(c *gin.Context) {
+	var (
+		page, limit int
+		err         error
+	)
+
+	pageStr := c.DefaultQuery("page", "1")
+	page, err = strconv.Atoi(pageStr)
+	if err != nil {
+		handleResponse(c, "error is while converting page", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	limitStr := c.DefaultQuery("limit", "10")
+	limit, err = strconv.Atoi(limitStr)
+	if err != nil {
+		handleResponse(c, "error is while converting limit", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	resp, err := h.storage.WriteOff().GetList(context.Background(), models.WriteOffGetListRequest{
+		Page:     page,
+		Limit:    limit,
+		BranchID: c.Query("branch_id"),
+		Reason:   c.Query("reason"),
+		Status:   c.Query("status"),
+	})
+	if err != nil {
+		handleResponse(c, "error is while getting write-off list", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	handleResponse(c, "success", http.StatusOK, resp)
+}
+
+// ApproveWriteOff godoc
+// @Router       /write-off/{id}/approve [PUT]
+// @Summary      Approve write-off
+// @Description  approve write-off by a manager and deduct its products from the branch repository
+// @Tags         write-off
+// @Accept       json
+// @Produce      json
+// @Param 		 id path string true "write_off_id"
+// @Param 		 approve body models.ApproveWriteOff true "approve"
+// @Success      200  {object}  models.WriteOff
+// @Failure      400  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) ApproveWriteOff(c *gin.Context) {
+	request := models.ApproveWriteOff{}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		handleResponse(c, "error is while reading body", http.StatusBadRequest, err.Error())
+		return
+	}
+	request.ID = c.Param("id")
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+
+	if !h.isManager(ctx, c, request.ApprovedBy) {
+		return
+	}
+
+	if err := h.storage.WriteOff().Approve(ctx, request); err != nil {
+		if errors.Is(err, storage.ErrWriteOffNotPending) || errors.Is(err, storage.ErrNotEnoughProduct) {
+			handleResponse(c, "write-off cannot be approved", http.StatusBadRequest, err.Error())
+			return
+		}
+		handleResponse(c, "error is while approving write-off", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeOff, err := h.storage.WriteOff().GetByID(ctx, request.ID)
+	if err != nil {
+		handleResponse(c, "error is while getting by id", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	handleResponse(c, "approved", http.StatusOK, writeOff)
+}
+
+// RejectWriteOff godoc
+// @Router       /write-off/{id}/reject [PUT]
+// @Summary      Reject write-off
+// @Description  reject write-off by a manager, stock is not changed
+// @Tags         write-off
+// @Accept       json
+// @Produce      json
+// @Param 		 id path string true "write_off_id"
+// @Param 		 reject body models.ApproveWriteOff true "reject"
+// @Success      200  {object}  models.WriteOff
+// @Failure      400  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) RejectWriteOff(c *gin.Context) {
+	request := models.ApproveWriteOff{}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		handleResponse(c, "error is while reading body", http.StatusBadRequest, err.Error())
+		return
+	}
+	request.ID = c.Param("id")
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+
+	if !h.isManager(ctx, c, request.ApprovedBy) {
+		return
+	}
+
+	if err := h.storage.WriteOff().Reject(ctx, request); err != nil {
+		if errors.Is(err, storage.ErrWriteOffNotPending) {
+			handleResponse(c, "write-off cannot be rejected", http.StatusBadRequest, err.Error())
+			return
+		}
+		handleResponse(c, "error is while rejecting write-off", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	writeOff, err := h.storage.WriteOff().GetByID(ctx, request.ID)
+	if err != nil {
+		handleResponse(c, "error is while getting by id", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	handleResponse(c, "rejected", http.StatusOK, writeOff)
+}
+
+// DeleteWriteOff godoc
+// @Router       /write-off/{id} [DELETE]
+// @Summary      Delete write-off
+// @Description  delete pending write-off
+// @Tags         write-off
+// @Accept       json
+// @Produce      json
+// @Param 		 id path string true "write_off_id"
+// @Success      200  {object}  models.Response
+// @Failure      400  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) DeleteWriteOff(c *gin.Context) {
+	id := c.Param("id")
+	if err := h.storage.WriteOff().Delete(context.Background(), id); err != nil {
+		handleResponse(c, "error is while deleting write-off", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	handleResponse(c, "success", http.StatusOK, "write-off deleted!")
+}
+
+// GetWriteOffReport godoc
+// @Router       /write-off-report [GET]
+// @Summary      Write-off report
+// @Description  approved write-offs grouped by reason for a period, dates are in 2006-01-02 format
+// @Tags         write-off
+// @Accept       json
+// @Produce      json
+// @Param 		 branch_id query string false "branch_id"
+// @Param 		 from_date query string false "from_date"
+// @Param 		 to_date query string false "to_date"
+// @Success      200  {object}  models.WriteOffReport
+// @Failure      400  {object}  models.Response
+// @Failure      404  {object}  models.Response
+// @Failure      500  {object}  models.Response
+func (h Handler) GetWriteOffReport(c *gin.Context) {
+	request := models.WriteOffReportRequest{
+		BranchID: c.Query("branch_id"),
+		FromDate: c.Query("from_date"),
+		ToDate:   c.Query("to_date"),
+	}
+
+	for _, date := range []string{request.FromDate, request.ToDate} {
+		if date == "" {
+			continue
+		}
+		if _, err := time.Parse("2006-01-02", date); err != nil {
+			handleResponse(c, "error is while parsing date", http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	report, err := h.storage.WriteOff().Report(context.Background(), request)
+	if err != nil {
+		handleResponse(c, "error is while getting write-off report", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	handleResponse(c, "success", http.StatusOK, report)
+}
+
+// isManager writes a bad request response and returns false when staffID is not a manager.
+func (h Handler) isManager(ctx context.Context, c *gin.Context, staffID string) bool {
+	if staffID == "" {
+		handleResponse(c, "approver is required", http.StatusBadRequest, "approved_by should not be empty")
+		return false
+	}
+
+	staff, err := h.storage.Staff().StaffByID(ctx, models.PrimaryKey{ID: staffID})
+	if err != nil {
+		handleResponse(c, "error is while getting staff by id", http.StatusBadRequest, err.Error())
+		return false
+	}
+
+	if staff.StaffType != "manager" {
+		handleResponse(c, "staff is not a manager", http.StatusBadRequest, "only managers can approve write-offs")
+		return false
+	}
+
+	return true
+}
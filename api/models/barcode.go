@@ -1,7 +1,7 @@
 package models
 
 type Barcode struct {
-	SaleID  string `json:"sale_id"`
-	Barcode int    `json:"barcode"`
-	Count   int    `json:"count"`
+	SaleID  string  `json:"sale_id"`
+	Barcode string  `json:"barcode"`
+	Count   float64 `json:"count"`
 }
@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+// BarcodeFormat describes an in-store barcode printed by scales: the barcode starts with Prefix,
+// holds a product code and a weight or a price. Positions are zero based and include the prefix.
+type BarcodeFormat struct {
+	ID                string    `json:"id"`
+	Name              string    `json:"name"`
+	Prefix            string    `json:"prefix"`
+	CodeLength        int       `json:"code_length"`
+	ProductCodeStart  int       `json:"product_code_start"`
+	ProductCodeLength int       `json:"product_code_length"`
+	ValueStart        int       `json:"value_start"`
+	ValueLength       int       `json:"value_length"`
+	ValueType         string    `json:"value_type"`
+	ValueDecimals     int       `json:"value_decimals"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
+type CreateBarcodeFormat struct {
+	Name              string `json:"name"`
+	Prefix            string `json:"prefix"`
+	CodeLength        int    `json:"code_length"`
+	ProductCodeStart  int    `json:"product_code_start"`
+	ProductCodeLength int    `json:"product_code_length"`
+	ValueStart        int    `json:"value_start"`
+	ValueLength       int    `json:"value_length"`
+	ValueType         string `json:"value_type"`
+	ValueDecimals     int    `json:"value_decimals"`
+}
+
+type BarcodeFormatsResponse struct {
+	BarcodeFormats []BarcodeFormat `json:"barcode_formats"`
+	Count          int             `json:"count"`
+}
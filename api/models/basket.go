@@ -1,31 +1,34 @@
 package models
 
-import "time"
+import (
+	"sell/pkg/money"
+	"time"
+)
 
 type Basket struct {
-	ID        string     `json:"id"`
-	SaleID    string     `json:"sale_id"`
-	ProductID string     `json:"product_id"`
-	Quantity  int        `json:"quantity"`
-	Price     int        `json:"price"`
-	CreatedAt time.Time  `json:"created_at"`
-	UpdatedAt time.Time  `json:"updated_at"`
-	DeletedAt *time.Time `json:"-"`
+	ID        string      `json:"id"`
+	SaleID    string      `json:"sale_id"`
+	ProductID string      `json:"product_id"`
+	Quantity  float64     `json:"quantity"`
+	Price     money.Money `json:"price"`
+	CreatedAt time.Time   `json:"created_at"`
+	UpdatedAt time.Time   `json:"updated_at"`
+	DeletedAt *time.Time  `json:"-"`
 }
 
 type CreateBasket struct {
-	SaleID    string `json:"sale_id"`
-	ProductID string `json:"product_id"`
-	Quantity  int    `json:"quantity"`
-	Price     int    `json:"price"`
+	SaleID    string      `json:"sale_id"`
+	ProductID string      `json:"product_id"`
+	Quantity  float64     `json:"quantity"`
+	Price     money.Money `json:"price"`
 }
 
 type UpdateBasket struct {
-	ID        string `json:"-"`
-	SaleID    string `json:"sale_id"`
-	ProductID string `json:"product_id"`
-	Quantity  int    `json:"quantity"`
-	Price     int    `json:"price"`
+	ID        string      `json:"-"`
+	SaleID    string      `json:"sale_id"`
+	ProductID string      `json:"product_id"`
+	Quantity  float64     `json:"quantity"`
+	Price     money.Money `json:"price"`
 }
 
 type BasketsResponse struct {
@@ -0,0 +1,51 @@
+package models
+
+import "time"
+
+type Batch struct {
+	ID              string    `json:"id"`
+	BranchID        string    `json:"branch_id"`
+	ProductID       string    `json:"product_id"`
+	IncomeProductID string    `json:"income_product_id"`
+	BatchNumber     string    `json:"batch_number"`
+	ExpiryDate      string    `json:"expiry_date"`
+	Count           float64   `json:"count"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+type CreateBatch struct {
+	BranchID        string  `json:"branch_id"`
+	ProductID       string  `json:"product_id"`
+	IncomeProductID string  `json:"income_product_id"`
+	BatchNumber     string  `json:"batch_number"`
+	ExpiryDate      string  `json:"expiry_date"`
+	Count           float64 `json:"count"`
+}
+
+type BatchResponse struct {
+	Batches []Batch `json:"batches"`
+	Count   int     `json:"count"`
+}
+
+type BatchGetListRequest struct {
+	Page      int    `json:"page"`
+	Limit     int    `json:"limit"`
+	BranchID  string `json:"branch_id"`
+	ProductID string `json:"product_id"`
+}
+
+type ExpiringBatchesRequest struct {
+	BranchID string `json:"branch_id"`
+	Days     int    `json:"days"`
+}
+
+type ExpiringBatch struct {
+	Batch
+	DaysLeft int `json:"days_left"`
+}
+
+type ExpiringBatchesResponse struct {
+	Batches []ExpiringBatch `json:"batches"`
+	Count   int             `json:"count"`
+}
@@ -3,26 +3,43 @@ package models
 import "time"
 
 type Category struct {
-	ID        string    `json:"id"`
-	Name      string    `json:"name"`
-	ParentID  string    `json:"parent_id"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
-	DeletedAt string    `json:"-"`
+	ID               string            `json:"id"`
+	Name             string            `json:"name"`
+	NameTranslations map[string]string `json:"name_translations"`
+	ParentID         string            `json:"parent_id"`
+	CreatedAt        time.Time         `json:"created_at"`
+	UpdatedAt        time.Time         `json:"updated_at"`
+	DeletedAt        string            `json:"-"`
 }
 
 type CreateCategory struct {
-	Name     string `json:"name"`
-	ParentID string `json:"parent_id"`
+	Name             string            `json:"name"`
+	NameTranslations map[string]string `json:"name_translations"`
+	ParentID         string            `json:"parent_id"`
 }
 
 type UpdateCategory struct {
-	ID       string `json:"-"`
-	Name     string `json:"name"`
-	ParentID string `json:"parent_id"`
+	ID               string            `json:"-"`
+	Name             string            `json:"name"`
+	NameTranslations map[string]string `json:"name_translations"`
+	ParentID         string            `json:"parent_id"`
 }
 
 type CategoryResponse struct {
 	Categories []Category
 	Count      int
 }
+
+type CategoryTree struct {
+	ID                string            `json:"id"`
+	Name              string            `json:"name"`
+	NameTranslations  map[string]string `json:"name_translations"`
+	ParentID          string            `json:"parent_id"`
+	ProductCount      int               `json:"product_count"`
+	TotalProductCount int               `json:"total_product_count"`
+	Children          []CategoryTree    `json:"children"`
+}
+
+type MoveCategory struct {
+	ParentID string `json:"parent_id"`
+}
@@ -0,0 +1,46 @@
+package models
+
+import (
+	"sell/pkg/money"
+	"time"
+)
+
+type ExchangeRate struct {
+	ID        string     `json:"id"`
+	Currency  string     `json:"currency"`
+	Rate      money.Rate `json:"rate"`
+	StartsAt  time.Time  `json:"starts_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+type CreateExchangeRate struct {
+	Currency string     `json:"currency"`
+	Rate     money.Rate `json:"rate"`
+	StartsAt string     `json:"starts_at"`
+}
+
+type ExchangeRateGetListRequest struct {
+	Page     int    `json:"page"`
+	Limit    int    `json:"limit"`
+	Currency string `json:"currency"`
+}
+
+type ExchangeRatesResponse struct {
+	ExchangeRates []ExchangeRate `json:"exchange_rates"`
+	Count         int            `json:"count"`
+}
+
+type EffectiveRateRequest struct {
+	Currency string    `json:"currency"`
+	At       time.Time `json:"at"`
+}
+
+// Conversion is an amount of a currency in the base currency at the rate of a moment.
+type Conversion struct {
+	Currency     string      `json:"currency"`
+	BaseCurrency string      `json:"base_currency"`
+	At           time.Time   `json:"at"`
+	Rate         money.Rate  `json:"rate"`
+	Amount       money.Money `json:"amount"`
+	BaseAmount   money.Money `json:"base_amount"`
+}
@@ -0,0 +1,51 @@
+package models
+
+import "time"
+
+// FiscalEntry is a sale or a refund in the fiscal outbox. Status is pending until the fiscal module registers
+// the document, then sent, or failed when every attempt failed.
+type FiscalEntry struct {
+	ID            string     `json:"id"`
+	DocumentType  string     `json:"document_type"`
+	DocumentID    string     `json:"document_id"`
+	Status        string     `json:"status"`
+	Attempts      int        `json:"attempts"`
+	LastError     string     `json:"last_error"`
+	NextAttemptAt time.Time  `json:"next_attempt_at"`
+	SentAt        *time.Time `json:"sent_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+type FiscalEntryGetListRequest struct {
+	Page   int    `json:"page"`
+	Limit  int    `json:"limit"`
+	Status string `json:"status"`
+}
+
+type FiscalEntriesResponse struct {
+	Entries []FiscalEntry `json:"entries"`
+	Count   int           `json:"count"`
+}
+
+// ClaimFiscalEntries takes up to Limit pending entries which are due, or only the entry ID or the entry of one
+// document when they are given, for Lease so nobody else sends them meanwhile.
+type ClaimFiscalEntries struct {
+	ID           string
+	DocumentType string
+	DocumentID   string
+	Limit        int
+	Lease        time.Duration
+}
+
+type FiscalResult struct {
+	EntryID string
+	Sign    string
+	Number  string
+}
+
+// FiscalFailure records a failed attempt, the entry is tried again at RetryAt or is failed when RetryAt is nil.
+type FiscalFailure struct {
+	EntryID string
+	Error   string
+	RetryAt *time.Time
+}
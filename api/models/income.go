@@ -1,23 +1,38 @@
 package models
 
-import "time"
+import (
+	"sell/pkg/money"
+	"time"
+)
 
 type Income struct {
-	ID        string    `json:"id"`
-	BranchID  string    `json:"branch_id"`
-	Price     int       `json:"price"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID              string      `json:"id"`
+	BranchID        string      `json:"branch_id"`
+	SupplierID      string      `json:"supplier_id"`
+	PurchaseOrderID string      `json:"purchase_order_id"`
+	Status          string      `json:"status"`
+	Currency        string      `json:"currency"`
+	ExchangeRate    money.Rate  `json:"exchange_rate"`
+	Price           money.Money `json:"price"`
+	BasePrice       money.Money `json:"base_price"`
+	FinishedAt      *time.Time  `json:"finished_at"`
+	CreatedAt       time.Time   `json:"created_at"`
+	UpdatedAt       time.Time   `json:"updated_at"`
 }
 
 type CreateIncome struct {
-	BranchID string `json:"branch_id"`
+	BranchID     string     `json:"branch_id"`
+	SupplierID   string     `json:"supplier_id"`
+	Currency     string     `json:"currency"`
+	ExchangeRate money.Rate `json:"-"`
 }
 
 type UpdateIncome struct {
-	ID       string `json:"-"`
-	BranchID string `json:"branch_id"`
-	Price    int    `json:"price"`
+	ID           string     `json:"-"`
+	BranchID     string     `json:"branch_id"`
+	SupplierID   string     `json:"supplier_id"`
+	Currency     string     `json:"currency"`
+	ExchangeRate money.Rate `json:"-"`
 }
 
 type IncomeResponse struct {
@@ -26,7 +41,9 @@ type IncomeResponse struct {
 }
 
 type IncomeGetListRequest struct {
-	Page     int    `json:"page"`
-	Limit    int    `json:"limit"`
-	BranchID string `json:"branch_id"`
+	Page       int    `json:"page"`
+	Limit      int    `json:"limit"`
+	BranchID   string `json:"branch_id"`
+	SupplierID string `json:"supplier_id"`
+	Status     string `json:"status"`
 }
@@ -1,28 +1,40 @@
 package models
 
+import "sell/pkg/money"
+
 type IncomeProduct struct {
-	ID        string `json:"id"`
-	IncomeID  string `json:"income_id"`
-	ProductID string `json:"product_id"`
-	Price     int    `json:"price"`
-	Count     int    `json:"count"`
-	CreatedAt string `json:"created_at"`
-	UpdatedAt string `json:"updated_at"`
+	ID            string      `json:"id"`
+	IncomeID      string      `json:"income_id"`
+	ProductID     string      `json:"product_id"`
+	ProductUnitID string      `json:"product_unit_id"`
+	UnitQuantity  float64     `json:"unit_quantity"`
+	Price         money.Money `json:"price"`
+	Count         float64     `json:"count"`
+	BatchNumber   string      `json:"batch_number"`
+	ExpiryDate    string      `json:"expiry_date"`
+	CreatedAt     string      `json:"created_at"`
+	UpdatedAt     string      `json:"updated_at"`
 }
 
 type CreateIncomeProduct struct {
-	IncomeID  string `json:"income_id"`
-	ProductID string `json:"product_id"`
-	Price     int    `json:"price"`
-	Count     int    `json:"count"`
+	IncomeID      string      `json:"income_id"`
+	ProductID     string      `json:"product_id"`
+	ProductUnitID string      `json:"product_unit_id"`
+	Price         money.Money `json:"price"`
+	Count         float64     `json:"count"`
+	BatchNumber   string      `json:"batch_number"`
+	ExpiryDate    string      `json:"expiry_date"`
 }
 
 type UpdateIncomeProduct struct {
-	ID        string `json:"-"`
-	IncomeID  string `json:"income_id"`
-	ProductID string `json:"product_id"`
-	Price     int    `json:"price"`
-	Count     int    `json:"count"`
+	ID            string      `json:"-"`
+	IncomeID      string      `json:"income_id"`
+	ProductID     string      `json:"product_id"`
+	ProductUnitID string      `json:"product_unit_id"`
+	Price         money.Money `json:"price"`
+	Count         float64     `json:"count"`
+	BatchNumber   string      `json:"batch_number"`
+	ExpiryDate    string      `json:"expiry_date"`
 }
 
 type IncomeProductsResponse struct {
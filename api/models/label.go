@@ -0,0 +1,15 @@
+package models
+
+type LabelRequest struct {
+	Products []LabelProduct `json:"products"`
+	IncomeID string         `json:"income_id"`
+	BranchID string         `json:"branch_id"`
+	Format   string         `json:"format"`
+	Width    float64        `json:"width"`
+	Height   float64        `json:"height"`
+}
+
+type LabelProduct struct {
+	ProductID string `json:"product_id"`
+	Copies    int    `json:"copies"`
+}
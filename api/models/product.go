@@ -1,30 +1,53 @@
 package models
 
-import "time"
+import (
+	"sell/pkg/money"
+	"time"
+)
 
 type Product struct {
-	ID         string    `json:"id"`
-	Name       string    `json:"name"`
-	Price      int       `json:"price"`
-	Barcode    int       `json:"barcode"`
-	CategoryID string    `json:"category_id"`
-	CreatedAt  time.Time `json:"created_at"`
-	UpdatedAt  time.Time `json:"updated_at"`
-	DeletedAt  time.Time `json:"-"`
+	ID               string            `json:"id"`
+	Name             string            `json:"name"`
+	NameTranslations map[string]string `json:"name_translations"`
+	Price            money.Money       `json:"price"`
+	PriceOverride    *money.Money      `json:"price_override,omitempty"`
+	ParentID         string            `json:"parent_id,omitempty"`
+	Attributes       map[string]string `json:"attributes,omitempty"`
+	Variants         []Product         `json:"variants,omitempty"`
+	Barcode          string            `json:"barcode"`
+	Barcodes         []ProductBarcode  `json:"barcodes,omitempty"`
+	Unit             string            `json:"unit"`
+	Precision        int               `json:"precision"`
+	Units            []ProductUnit     `json:"units,omitempty"`
+	ImageURL         string            `json:"image_url,omitempty"`
+	ThumbnailURL     string            `json:"thumbnail_url,omitempty"`
+	Images           []ProductImage    `json:"images,omitempty"`
+	CategoryID       string            `json:"category_id"`
+	CreatedAt        time.Time         `json:"created_at"`
+	UpdatedAt        time.Time         `json:"updated_at"`
+	DeletedAt        time.Time         `json:"-"`
 }
 
 type CreateProduct struct {
-	Name       string `json:"name"`
-	Price      int    `json:"price"`
-	Barcode    int    `json:"barcode"`
-	CategoryID string `json:"category_id"`
+	Name             string                 `json:"name"`
+	NameTranslations map[string]string      `json:"name_translations"`
+	Price            money.Money            `json:"price"`
+	Barcode          string                 `json:"barcode"`
+	Barcodes         []CreateProductBarcode `json:"barcodes"`
+	Unit             string                 `json:"unit"`
+	Precision        *int                   `json:"precision"`
+	CategoryID       string                 `json:"category_id"`
 }
 
 type UpdateProduct struct {
-	ID         string `json:"-"`
-	Name       string `json:"name"`
-	Price      int    `json:"price"`
-	CategoryID string `json:"category_id"`
+	ID               string            `json:"-"`
+	Name             string            `json:"name"`
+	NameTranslations map[string]string `json:"name_translations"`
+	Price            money.Money       `json:"price"`
+	Attributes       map[string]string `json:"attributes"`
+	Unit             string            `json:"unit"`
+	Precision        *int              `json:"precision"`
+	CategoryID       string            `json:"category_id"`
 }
 
 type ProductResponse struct {
@@ -33,8 +56,124 @@ type ProductResponse struct {
 }
 
 type ProductGetListRequest struct {
-	Page    int    `json:"page"`
-	Limit   int    `json:"limit"`
-	Name    string `json:"name"`
-	Barcode int    `json:"barcode"`
+	Page       int    `json:"page"`
+	Limit      int    `json:"limit"`
+	Name       string `json:"name"`
+	Barcode    string `json:"barcode"`
+	CategoryID string `json:"category_id"`
+	Grouped    bool   `json:"grouped"`
+}
+
+type ProductBarcode struct {
+	ID        string    `json:"id"`
+	ProductID string    `json:"product_id"`
+	Barcode   string    `json:"barcode"`
+	Quantity  int       `json:"quantity"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type CreateProductBarcode struct {
+	ProductID string `json:"-"`
+	Barcode   string `json:"barcode"`
+	Quantity  int    `json:"quantity"`
+}
+
+type ProductUnit struct {
+	ID        string    `json:"id"`
+	ProductID string    `json:"product_id"`
+	Name      string    `json:"name"`
+	Quantity  float64   `json:"quantity"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type CreateProductUnit struct {
+	ProductID string  `json:"-"`
+	Name      string  `json:"name"`
+	Quantity  float64 `json:"quantity"`
+}
+
+type CreateProductVariant struct {
+	ParentID         string            `json:"-"`
+	Name             string            `json:"name"`
+	NameTranslations map[string]string `json:"name_translations"`
+	Barcode          string            `json:"barcode"`
+	PriceOverride    *money.Money      `json:"price_override"`
+	Attributes       map[string]string `json:"attributes"`
+}
+
+type ProductImportRow struct {
+	Row      int
+	Name     string
+	Price    *money.Money
+	Barcode  string
+	Category []string
+}
+
+type ProductImportError struct {
+	Row     int    `json:"row"`
+	Column  string `json:"column,omitempty"`
+	Message string `json:"message"`
+}
+
+type ProductImportResponse struct {
+	DryRun            bool                 `json:"dry_run"`
+	Total             int                  `json:"total"`
+	Created           int                  `json:"created"`
+	Updated           int                  `json:"updated"`
+	Failed            int                  `json:"failed"`
+	CategoriesCreated int                  `json:"categories_created"`
+	Errors            []ProductImportError `json:"errors"`
+}
+
+type ProductExportRow struct {
+	Name     string      `json:"name"`
+	Price    money.Money `json:"price"`
+	Barcode  string      `json:"barcode"`
+	Category string      `json:"category"`
+}
+
+type ProductSearchRequest struct {
+	Query    string `json:"query"`
+	BranchID string `json:"branch_id"`
+	Limit    int    `json:"limit"`
+}
+
+type ProductSearchResult struct {
+	Product
+	CategoryName string  `json:"category_name"`
+	Stock        float64 `json:"stock"`
+	Match        string  `json:"match"`
+	Rank         float64 `json:"rank"`
+}
+
+type ProductSearchResponse struct {
+	Products []ProductSearchResult `json:"products"`
+	Count    int                   `json:"count"`
+}
+
+type ProductImage struct {
+	ID           string    `json:"id"`
+	ProductID    string    `json:"product_id"`
+	URL          string    `json:"url"`
+	ThumbnailURL string    `json:"thumbnail_url"`
+	Key          string    `json:"-"`
+	ThumbnailKey string    `json:"-"`
+	ContentType  string    `json:"content_type"`
+	Width        int       `json:"width"`
+	Height       int       `json:"height"`
+	Size         int64     `json:"size"`
+	Position     int       `json:"position"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type CreateProductImage struct {
+	ProductID    string
+	Key          string
+	URL          string
+	ThumbnailKey string
+	ThumbnailURL string
+	ContentType  string
+	Width        int
+	Height       int
+	Size         int64
 }
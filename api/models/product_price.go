@@ -0,0 +1,47 @@
+package models
+
+import (
+	"sell/pkg/money"
+	"time"
+)
+
+type ProductPrice struct {
+	ID        string       `json:"id"`
+	ProductID string       `json:"product_id"`
+	BranchID  string       `json:"branch_id"`
+	Price     *money.Money `json:"price"`
+	StartsAt  time.Time    `json:"starts_at"`
+	CreatedAt time.Time    `json:"created_at"`
+}
+
+type CreateProductPrice struct {
+	ProductID string      `json:"-"`
+	BranchID  string      `json:"branch_id"`
+	Price     money.Money `json:"price"`
+	StartsAt  string      `json:"starts_at"`
+}
+
+type ProductPriceGetListRequest struct {
+	Page      int    `json:"page"`
+	Limit     int    `json:"limit"`
+	ProductID string `json:"product_id"`
+	BranchID  string `json:"branch_id"`
+}
+
+type ProductPricesResponse struct {
+	ProductPrices []ProductPrice `json:"product_prices"`
+	Count         int            `json:"count"`
+}
+
+type EffectivePriceRequest struct {
+	ProductID string    `json:"product_id"`
+	BranchID  string    `json:"branch_id"`
+	At        time.Time `json:"at"`
+}
+
+type EffectivePrice struct {
+	ProductID string      `json:"product_id"`
+	BranchID  string      `json:"branch_id"`
+	At        time.Time   `json:"at"`
+	Price     money.Money `json:"price"`
+}
@@ -0,0 +1,78 @@
+package models
+
+import (
+	"sell/pkg/money"
+	"time"
+)
+
+type PurchaseOrder struct {
+	ID         string                 `json:"id"`
+	SupplierID string                 `json:"supplier_id"`
+	BranchID   string                 `json:"branch_id"`
+	Status     string                 `json:"status"`
+	Currency   string                 `json:"currency"`
+	Price      money.Money            `json:"price"`
+	Comment    string                 `json:"comment"`
+	Products   []PurchaseOrderProduct `json:"products"`
+	CreatedAt  time.Time              `json:"created_at"`
+	UpdatedAt  time.Time              `json:"updated_at"`
+}
+
+type PurchaseOrderProduct struct {
+	ID              string      `json:"id"`
+	PurchaseOrderID string      `json:"purchase_order_id"`
+	ProductID       string      `json:"product_id"`
+	Price           money.Money `json:"price"`
+	Count           float64     `json:"count"`
+	ReceivedCount   float64     `json:"received_count"`
+}
+
+type CreatePurchaseOrder struct {
+	SupplierID string                       `json:"supplier_id"`
+	BranchID   string                       `json:"branch_id"`
+	Currency   string                       `json:"currency"`
+	Comment    string                       `json:"comment"`
+	Products   []CreatePurchaseOrderProduct `json:"products"`
+}
+
+type CreatePurchaseOrderProduct struct {
+	ProductID string      `json:"product_id"`
+	Price     money.Money `json:"price"`
+	Count     float64     `json:"count"`
+}
+
+type PurchaseOrderResponse struct {
+	PurchaseOrders []PurchaseOrder `json:"purchase_orders"`
+	Count          int             `json:"count"`
+}
+
+type PurchaseOrderGetListRequest struct {
+	Page       int    `json:"page"`
+	Limit      int    `json:"limit"`
+	SupplierID string `json:"supplier_id"`
+	BranchID   string `json:"branch_id"`
+	Status     string `json:"status"`
+}
+
+type ReceivePurchaseOrder struct {
+	ID           string                `json:"-"`
+	ExchangeRate money.Rate            `json:"-"`
+	Products     []CreateIncomeProduct `json:"products"`
+}
+
+type PurchaseOrderDiscrepancy struct {
+	ProductID     string      `json:"product_id"`
+	OrderedCount  float64     `json:"ordered_count"`
+	ReceivedCount float64     `json:"received_count"`
+	CountDiff     float64     `json:"count_diff"`
+	ExpectedPrice money.Money `json:"expected_price"`
+	ReceivedPrice money.Money `json:"received_price"`
+	PriceDiff     money.Money `json:"price_diff"`
+}
+
+type PurchaseOrderDiscrepancies struct {
+	PurchaseOrderID string                     `json:"purchase_order_id"`
+	Status          string                     `json:"status"`
+	Discrepancies   []PurchaseOrderDiscrepancy `json:"discrepancies"`
+	Count           int                        `json:"count"`
+}
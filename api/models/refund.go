@@ -0,0 +1,54 @@
+package models
+
+import (
+	"sell/pkg/money"
+	"time"
+)
+
+// Refund gives back a part of a successful sale. Price is the money paid back and TaxAmount the tax in it.
+type Refund struct {
+	ID           string          `json:"id"`
+	SaleID       string          `json:"sale_id"`
+	Reason       string          `json:"reason"`
+	Price        money.Money     `json:"price"`
+	TaxAmount    money.Money     `json:"tax_amount"`
+	FiscalSign   string          `json:"fiscal_sign"`
+	FiscalNumber string          `json:"fiscal_number"`
+	FiscalizedAt *time.Time      `json:"fiscalized_at"`
+	Products     []RefundProduct `json:"products"`
+	CreatedAt    time.Time       `json:"created_at"`
+}
+
+// RefundProduct is a part of a basket line given back, Price and TaxAmount are the share of the line price and tax.
+type RefundProduct struct {
+	ID        string      `json:"id"`
+	RefundID  string      `json:"refund_id"`
+	BasketID  string      `json:"basket_id"`
+	ProductID string      `json:"product_id"`
+	Quantity  float64     `json:"quantity"`
+	Price     money.Money `json:"price"`
+	TaxRate   money.Money `json:"tax_rate"`
+	TaxAmount money.Money `json:"tax_amount"`
+}
+
+type CreateRefund struct {
+	SaleID   string                `json:"-"`
+	Reason   string                `json:"reason"`
+	Products []CreateRefundProduct `json:"products"`
+}
+
+type CreateRefundProduct struct {
+	BasketID string  `json:"basket_id"`
+	Quantity float64 `json:"quantity"`
+}
+
+type RefundGetListRequest struct {
+	Page   int    `json:"page"`
+	Limit  int    `json:"limit"`
+	SaleID string `json:"sale_id"`
+}
+
+type RefundsResponse struct {
+	Refunds []Refund `json:"refunds"`
+	Count   int      `json:"count"`
+}
@@ -3,29 +3,93 @@ package models
 import "time"
 
 type Repository struct {
-	ID        string     `json:"id"`
-	ProductID string     `json:"product_id"`
-	BranchID  string     `json:"branch_id"`
-	Count     int        `json:"count"`
-	CreatedAt time.Time  `json:"created_at"`
-	UpdatedAt time.Time  `json:"updated_at"`
-	DeletedAt *time.Time `json:"-"`
+	ID          string     `json:"id"`
+	ProductID   string     `json:"product_id"`
+	BranchID    string     `json:"branch_id"`
+	Count       float64    `json:"count"`
+	MinCount    float64    `json:"min_count"`
+	TargetCount float64    `json:"target_count"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	DeletedAt   *time.Time `json:"-"`
 }
 
 type CreateRepository struct {
-	ProductID string `json:"product_id"`
-	BranchID  string `json:"branch_id"`
-	Count     int    `json:"count"`
+	ProductID   string  `json:"product_id"`
+	BranchID    string  `json:"branch_id"`
+	Count       float64 `json:"count"`
+	MinCount    float64 `json:"min_count"`
+	TargetCount float64 `json:"target_count"`
 }
 
 type UpdateRepository struct {
-	ID        string `json:"-"`
-	ProductID string `json:"product_id"`
-	BranchID  string `json:"branch_id"`
-	Count     int    `json:"count"`
+	ID        string  `json:"-"`
+	ProductID string  `json:"product_id"`
+	BranchID  string  `json:"branch_id"`
+	Count     float64 `json:"count"`
+}
+
+type UpdateRepositoryLevels struct {
+	ID          string  `json:"-"`
+	MinCount    float64 `json:"min_count"`
+	TargetCount float64 `json:"target_count"`
 }
 
 type RepositoriesResponse struct {
 	Repositories []Repository `json:"repositories"`
 	Count        int          `json:"count"`
 }
+
+type StockLevelRequest struct {
+	BranchID  string `json:"branch_id"`
+	Days      int    `json:"days"`
+	CoverDays int    `json:"cover_days"`
+}
+
+type LowStockItem struct {
+	RepositoryID string  `json:"repository_id"`
+	BranchID     string  `json:"branch_id"`
+	ProductID    string  `json:"product_id"`
+	ProductName  string  `json:"product_name"`
+	Count        float64 `json:"count"`
+	MinCount     float64 `json:"min_count"`
+	TargetCount  float64 `json:"target_count"`
+}
+
+type LowStockResponse struct {
+	Items []LowStockItem `json:"items"`
+	Count int            `json:"count"`
+}
+
+type ReorderSuggestion struct {
+	LowStockItem
+	AverageDailySales float64 `json:"average_daily_sales"`
+	SuggestedQuantity float64 `json:"suggested_quantity"`
+}
+
+type ReorderSuggestionsResponse struct {
+	Days        int                 `json:"days"`
+	CoverDays   int                 `json:"cover_days"`
+	Suggestions []ReorderSuggestion `json:"suggestions"`
+	Count       int                 `json:"count"`
+}
+
+type ReconcileRequest struct {
+	BranchID string `json:"branch_id"`
+	Mode     string `json:"mode"`
+}
+
+type StockDiscrepancy struct {
+	BranchID      string  `json:"branch_id"`
+	ProductID     string  `json:"product_id"`
+	Count         float64 `json:"count"`
+	ExpectedCount float64 `json:"expected_count"`
+	Difference    float64 `json:"difference"`
+}
+
+type ReconcileResponse struct {
+	Discrepancies       []StockDiscrepancy `json:"discrepancies"`
+	Count               int                `json:"count"`
+	UnassignedMovements int                `json:"unassigned_movements"`
+	Fixed               bool               `json:"fixed"`
+}
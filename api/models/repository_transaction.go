@@ -1,34 +1,103 @@
 package models
 
-import "time"
+import (
+	"sell/pkg/money"
+	"time"
+)
 
 type RepositoryTransaction struct {
-	ID                        string     `json:"id"`
-	ProductID                 string     `json:"product_id"`
-	RepositoryTransactionType string     `json:"repository_transaction_type"`
-	Price                     int        `json:"price"`
-	Quantity                  int        `json:"quantity"`
-	CreatedAt                 time.Time  `json:"created_at"`
-	UpdatedAt                 time.Time  `json:"updated_at"`
-	DeletedAt                 *time.Time `json:"-"`
+	ID                        string      `json:"id"`
+	BranchID                  string      `json:"branch_id"`
+	ProductID                 string      `json:"product_id"`
+	RepositoryTransactionType string      `json:"repository_transaction_type"`
+	Reason                    string      `json:"reason"`
+	SourceType                string      `json:"source_type"`
+	SourceID                  string      `json:"source_id"`
+	Price                     money.Money `json:"price"`
+	Quantity                  float64     `json:"quantity"`
+	CreatedAt                 time.Time   `json:"created_at"`
+	UpdatedAt                 time.Time   `json:"updated_at"`
+	DeletedAt                 *time.Time  `json:"-"`
 }
 
 type CreateRepositoryTransaction struct {
-	ProductID                 string `json:"product_id"`
-	RepositoryTransactionType string `json:"repository_transaction_type"`
-	Price                     int    `json:"price"`
-	Quantity                  int    `json:"quantity"`
+	BranchID                  string      `json:"branch_id"`
+	ProductID                 string      `json:"product_id"`
+	RepositoryTransactionType string      `json:"repository_transaction_type"`
+	Reason                    string      `json:"reason"`
+	SourceType                string      `json:"source_type"`
+	SourceID                  string      `json:"source_id"`
+	Price                     money.Money `json:"price"`
+	Quantity                  float64     `json:"quantity"`
 }
 
 type UpdateRepositoryTransaction struct {
-	ID                        string `json:"-"`
-	ProductID                 string `json:"product_id"`
-	RepositoryTransactionType string `json:"repository_transaction_type"`
-	Price                     int    `json:"price"`
-	Quantity                  int    `json:"quantity"`
+	ID                        string      `json:"-"`
+	BranchID                  string      `json:"branch_id"`
+	ProductID                 string      `json:"product_id"`
+	RepositoryTransactionType string      `json:"repository_transaction_type"`
+	Reason                    string      `json:"reason"`
+	SourceType                string      `json:"source_type"`
+	SourceID                  string      `json:"source_id"`
+	Price                     money.Money `json:"price"`
+	Quantity                  float64     `json:"quantity"`
 }
 
 type RepositoryTransactionsResponse struct {
 	RepositoryTransactions []RepositoryTransaction `json:"repository_transactions"`
 	Count                  int                     `json:"count"`
 }
+
+type RepositoryTransactionGetListRequest struct {
+	Page       int    `json:"page"`
+	Limit      int    `json:"limit"`
+	ProductID  string `json:"product_id"`
+	BranchID   string `json:"branch_id"`
+	SourceType string `json:"source_type"`
+	SourceID   string `json:"source_id"`
+}
+
+type MovementHistoryRequest struct {
+	ProductID string `json:"product_id"`
+	BranchID  string `json:"branch_id"`
+	FromDate  string `json:"from_date"`
+	ToDate    string `json:"to_date"`
+}
+
+type MovementHistoryItem struct {
+	RepositoryTransaction
+	Change  float64 `json:"change"`
+	Balance float64 `json:"balance"`
+}
+
+type MovementHistory struct {
+	ProductID      string                `json:"product_id"`
+	BranchID       string                `json:"branch_id"`
+	FromDate       string                `json:"from_date"`
+	ToDate         string                `json:"to_date"`
+	OpeningBalance float64               `json:"opening_balance"`
+	Movements      []MovementHistoryItem `json:"movements"`
+	ClosingBalance float64               `json:"closing_balance"`
+}
+
+type StockSnapshotRequest struct {
+	At       string `json:"at"`
+	BranchID string `json:"branch_id"`
+}
+
+type StockSnapshotItem struct {
+	BranchID    string      `json:"branch_id"`
+	BranchName  string      `json:"branch_name"`
+	ProductID   string      `json:"product_id"`
+	ProductName string      `json:"product_name"`
+	Quantity    float64     `json:"quantity"`
+	UnitCost    money.Money `json:"unit_cost"`
+	Value       money.Money `json:"value"`
+}
+
+type StockSnapshot struct {
+	At            string              `json:"at"`
+	Items         []StockSnapshotItem `json:"items"`
+	TotalQuantity float64             `json:"total_quantity"`
+	TotalValue    money.Money         `json:"total_value"`
+}
@@ -1,39 +1,51 @@
 package models
 
-import "time"
+import (
+	"sell/pkg/money"
+	"time"
+)
 
 type Sale struct {
-	ID              string    `json:"id"`
-	BranchID        string    `json:"branch_id"`
-	ShopAssistantID string    `json:"shop_assistant_id"`
-	CashierID       string    `json:"cashier_id"`
-	PaymentType     string    `json:"payment_type"`
-	Price           float32   `json:"price"`
-	Status          string    `json:"status"`
-	ClientName      string    `json:"client_name"`
-	CreatedAt       time.Time `json:"created_at"`
-	UpdatedAt       time.Time `json:"updated_at"`
+	ID              string      `json:"id"`
+	BranchID        string      `json:"branch_id"`
+	ShopAssistantID string      `json:"shop_assistant_id"`
+	CashierID       string      `json:"cashier_id"`
+	PaymentType     string      `json:"payment_type"`
+	Currency        string      `json:"currency"`
+	Price           money.Money `json:"price"`
+	TaxIncluded     bool        `json:"tax_included"`
+	TaxAmount       money.Money `json:"tax_amount"`
+	Status          string      `json:"status"`
+	ClientName      string      `json:"client_name"`
+	FiscalSign      string      `json:"fiscal_sign"`
+	FiscalNumber    string      `json:"fiscal_number"`
+	FiscalizedAt    *time.Time  `json:"fiscalized_at"`
+	FinishedAt      *time.Time  `json:"finished_at"`
+	CreatedAt       time.Time   `json:"created_at"`
+	UpdatedAt       time.Time   `json:"updated_at"`
 }
 
 type CreateSale struct {
-	BranchID        string  `json:"branch_id"`
-	ShopAssistantID string  `json:"shop_assistant_id"`
-	CashierID       string  `json:"cashier_id"`
-	PaymentType     string  `json:"payment_type"`
-	Price           float32 `json:"price"`
-	Status          string  `json:"status"`
-	ClientName      string  `json:"client_name"`
+	BranchID        string      `json:"branch_id"`
+	ShopAssistantID string      `json:"shop_assistant_id"`
+	CashierID       string      `json:"cashier_id"`
+	PaymentType     string      `json:"payment_type"`
+	Currency        string      `json:"-"`
+	TaxIncluded     bool        `json:"-"`
+	Price           money.Money `json:"price"`
+	Status          string      `json:"status"`
+	ClientName      string      `json:"client_name"`
 }
 
 type UpdateSale struct {
-	ID              string  `json:"-"`
-	BranchID        string  `json:"branch_id"`
-	ShopAssistantID string  `json:"shop_assistant_id"`
-	CashierID       string  `json:"cashier_id"`
-	PaymentType     string  `json:"payment_type"`
-	Price           float32 `json:"price"`
-	Status          string  `json:"status"`
-	ClientName      string  `json:"client_name"`
+	ID              string      `json:"-"`
+	BranchID        string      `json:"branch_id"`
+	ShopAssistantID string      `json:"shop_assistant_id"`
+	CashierID       string      `json:"cashier_id"`
+	PaymentType     string      `json:"payment_type"`
+	Price           money.Money `json:"price"`
+	Status          string      `json:"status"`
+	ClientName      string      `json:"client_name"`
 }
 
 type SaleResponse struct {
@@ -42,7 +54,81 @@ type SaleResponse struct {
 }
 
 type SaleRequest struct {
-	SaleID     string `json:"-"`
-	TotalPrice int    `json:"-"`
-	Status     string `json:"status"`
+	SaleID     string      `json:"-"`
+	TotalPrice money.Money `json:"-"`
+	Status     string      `json:"status"`
+}
+
+type Receipt struct {
+	SaleID       string        `json:"sale_id"`
+	BranchID     string        `json:"branch_id"`
+	BranchName   string        `json:"branch_name"`
+	CashierID    string        `json:"cashier_id"`
+	PaymentType  string        `json:"payment_type"`
+	Status       string        `json:"status"`
+	ClientName   string        `json:"client_name"`
+	Currency     string        `json:"currency"`
+	TaxIncluded  bool          `json:"tax_included"`
+	Lines        []ReceiptLine `json:"lines"`
+	Taxes        []ReceiptTax  `json:"taxes"`
+	Net          money.Money   `json:"net"`
+	Tax          money.Money   `json:"tax"`
+	Total        money.Money   `json:"total"`
+	Payments     []SalePayment `json:"payments"`
+	Paid         money.Money   `json:"paid"`
+	Change       money.Money   `json:"change"`
+	FiscalSign   string        `json:"fiscal_sign"`
+	FiscalNumber string        `json:"fiscal_number"`
+	CreatedAt    time.Time     `json:"created_at"`
+}
+
+type ReceiptLine struct {
+	ProductID        string            `json:"product_id"`
+	Name             string            `json:"name"`
+	NameTranslations map[string]string `json:"-"`
+	Unit             string            `json:"unit"`
+	Quantity         float64           `json:"quantity"`
+	UnitPrice        money.Money       `json:"unit_price"`
+	Price            money.Money       `json:"price"`
+	TaxRate          money.Money       `json:"tax_rate"`
+	Tax              money.Money       `json:"tax"`
+	Total            money.Money       `json:"total"`
+}
+
+// ReceiptTax sums up the lines of a receipt taxed at one rate.
+type ReceiptTax struct {
+	Rate  money.Money `json:"rate"`
+	Net   money.Money `json:"net"`
+	Tax   money.Money `json:"tax"`
+	Total money.Money `json:"total"`
+}
+
+// SalePayment is money received for a sale. BaseAmount is Amount in the currency of the sale.
+type SalePayment struct {
+	ID           string      `json:"id"`
+	SaleID       string      `json:"sale_id"`
+	Currency     string      `json:"currency"`
+	Amount       money.Money `json:"amount"`
+	ExchangeRate money.Rate  `json:"exchange_rate"`
+	BaseAmount   money.Money `json:"base_amount"`
+	CreatedAt    time.Time   `json:"created_at"`
+}
+
+type CreateSalePayment struct {
+	SaleID       string      `json:"-"`
+	Currency     string      `json:"currency"`
+	Amount       money.Money `json:"amount"`
+	ExchangeRate money.Rate  `json:"-"`
+}
+
+// SalePayments sums up the payments of a sale: Due is what is left to pay and Change is
+// what is given back, both in the currency of the sale.
+type SalePayments struct {
+	SaleID   string        `json:"sale_id"`
+	Currency string        `json:"currency"`
+	Total    money.Money   `json:"total"`
+	Paid     money.Money   `json:"paid"`
+	Due      money.Money   `json:"due"`
+	Change   money.Money   `json:"change"`
+	Payments []SalePayment `json:"payments"`
 }
@@ -1,41 +1,44 @@
 package models
 
-import "time"
+import (
+	"sell/pkg/money"
+	"time"
+)
 
 type Staff struct {
-	ID        string    `json:"id"`
-	BranchID  string    `json:"branch_id"`
-	TariffID  string    `json:"tariff_id"`
-	StaffType string    `json:"staff_type"`
-	Name      string    `json:"name"`
-	Balance   uint      `json:"balance"`
-	Age       uint      `json:"age"`
-	BirthDate string    `json:"birth_date"`
-	Login     string    `json:"login"`
-	Password  string    `json:"password"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID        string      `json:"id"`
+	BranchID  string      `json:"branch_id"`
+	TariffID  string      `json:"tariff_id"`
+	StaffType string      `json:"staff_type"`
+	Name      string      `json:"name"`
+	Balance   money.Money `json:"balance"`
+	Age       uint        `json:"age"`
+	BirthDate string      `json:"birth_date"`
+	Login     string      `json:"login"`
+	Password  string      `json:"password"`
+	CreatedAt time.Time   `json:"created_at"`
+	UpdatedAt time.Time   `json:"updated_at"`
 }
 
 type CreateStaff struct {
-	BranchID  string `json:"branch_id"`
-	TariffID  string `json:"tariff_id"`
-	StaffType string `json:"staff_type"`
-	Name      string `json:"name"`
-	Balance   uint   `json:"balance"`
-	BirthDate string `json:"birth_date"`
-	Login     string `json:"login"`
-	Password  string `json:"password"`
+	BranchID  string      `json:"branch_id"`
+	TariffID  string      `json:"tariff_id"`
+	StaffType string      `json:"staff_type"`
+	Name      string      `json:"name"`
+	Balance   money.Money `json:"balance"`
+	BirthDate string      `json:"birth_date"`
+	Login     string      `json:"login"`
+	Password  string      `json:"password"`
 }
 
 type UpdateStaff struct {
-	ID        string `json:"-"`
-	BranchID  string `json:"branch_id"`
-	TariffID  string `json:"tariff_id"`
-	StaffType string `json:"staff_type"`
-	Name      string `json:"name"`
-	Balance   uint   `json:"balance"`
-	Login     string `json:"login"`
+	ID        string      `json:"-"`
+	BranchID  string      `json:"branch_id"`
+	TariffID  string      `json:"tariff_id"`
+	StaffType string      `json:"staff_type"`
+	Name      string      `json:"name"`
+	Balance   money.Money `json:"balance"`
+	Login     string      `json:"login"`
 }
 
 type StaffsResponse struct {
@@ -50,8 +53,8 @@ type UpdateStaffPassword struct {
 }
 
 type StaffType struct {
-	ID      string `json:"id"`
-	Balance uint   `json:"balance"`
+	ID      string      `json:"id"`
+	Balance money.Money `json:"balance"`
 }
 type UpdateBalanceRequest struct {
 	TransactionType string    `json:"transaction_type"`
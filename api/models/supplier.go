@@ -0,0 +1,65 @@
+package models
+
+import (
+	"sell/pkg/money"
+	"time"
+)
+
+// Supplier balance is what the shop owes the supplier: received incomes minus payments.
+type Supplier struct {
+	ID        string      `json:"id"`
+	Name      string      `json:"name"`
+	Phone     string      `json:"phone"`
+	Address   string      `json:"address"`
+	Balance   money.Money `json:"balance"`
+	CreatedAt time.Time   `json:"created_at"`
+	UpdatedAt time.Time   `json:"updated_at"`
+}
+
+type CreateSupplier struct {
+	Name    string `json:"name"`
+	Phone   string `json:"phone"`
+	Address string `json:"address"`
+}
+
+type UpdateSupplier struct {
+	ID      string `json:"-"`
+	Name    string `json:"name"`
+	Phone   string `json:"phone"`
+	Address string `json:"address"`
+}
+
+type SupplierResponse struct {
+	Suppliers []Supplier `json:"suppliers"`
+	Count     int        `json:"count"`
+}
+
+type SupplierPayment struct {
+	ID           string      `json:"id"`
+	SupplierID   string      `json:"supplier_id"`
+	Currency     string      `json:"currency"`
+	Amount       money.Money `json:"amount"`
+	ExchangeRate money.Rate  `json:"exchange_rate"`
+	BaseAmount   money.Money `json:"base_amount"`
+	Comment      string      `json:"comment"`
+	CreatedAt    time.Time   `json:"created_at"`
+}
+
+type CreateSupplierPayment struct {
+	SupplierID   string      `json:"-"`
+	Currency     string      `json:"currency"`
+	Amount       money.Money `json:"amount"`
+	ExchangeRate money.Rate  `json:"-"`
+	Comment      string      `json:"comment"`
+}
+
+type SupplierPaymentResponse struct {
+	Payments []SupplierPayment `json:"payments"`
+	Count    int               `json:"count"`
+}
+
+type SupplierPaymentGetListRequest struct {
+	Page       int    `json:"page"`
+	Limit      int    `json:"limit"`
+	SupplierID string `json:"supplier_id"`
+}
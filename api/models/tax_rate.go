@@ -0,0 +1,79 @@
+package models
+
+import (
+	"sell/pkg/money"
+	"time"
+)
+
+// TaxRate is a tax in percent, written like money with two decimal places: 12 percent is 12.00.
+type TaxRate struct {
+	ID        string      `json:"id"`
+	Name      string      `json:"name"`
+	Rate      money.Money `json:"rate"`
+	CreatedAt time.Time   `json:"created_at"`
+	UpdatedAt time.Time   `json:"updated_at"`
+}
+
+type CreateTaxRate struct {
+	Name string      `json:"name"`
+	Rate money.Money `json:"rate"`
+}
+
+type UpdateTaxRate struct {
+	ID   string      `json:"-"`
+	Name string      `json:"name"`
+	Rate money.Money `json:"rate"`
+}
+
+type TaxRatesResponse struct {
+	TaxRates []TaxRate `json:"tax_rates"`
+	Count    int       `json:"count"`
+}
+
+// SetTaxRate assigns a tax rate to a product or a category, an empty TaxRateID removes it.
+type SetTaxRate struct {
+	ID        string `json:"-"`
+	TaxRateID string `json:"tax_rate_id"`
+}
+
+// ProductTaxRate is the rate a product is taxed at. Source is "product", "parent" for the product
+// it is a variant of or "category", it is empty and the rate is 0 when no rate applies.
+type ProductTaxRate struct {
+	ProductID string      `json:"product_id"`
+	TaxRateID string      `json:"tax_rate_id"`
+	Name      string      `json:"name"`
+	Rate      money.Money `json:"rate"`
+	Source    string      `json:"source"`
+}
+
+// SaleTax is the tax of a sale calculated at checkout, Net plus Tax is Total.
+type SaleTax struct {
+	SaleID      string      `json:"sale_id"`
+	TaxIncluded bool        `json:"tax_included"`
+	Net         money.Money `json:"net"`
+	Tax         money.Money `json:"tax"`
+	Total       money.Money `json:"total"`
+}
+
+type TaxReportRequest struct {
+	BranchID string `json:"branch_id"`
+	FromDate string `json:"from_date"`
+	ToDate   string `json:"to_date"`
+}
+
+type TaxReportRow struct {
+	BranchID   string      `json:"branch_id"`
+	BranchName string      `json:"branch_name"`
+	TaxRate    money.Money `json:"tax_rate"`
+	Sales      int         `json:"sales"`
+	Net        money.Money `json:"net"`
+	Tax        money.Money `json:"tax"`
+	Total      money.Money `json:"total"`
+}
+
+type TaxReport struct {
+	Rows     []TaxReportRow `json:"rows"`
+	TotalNet money.Money    `json:"total_net"`
+	TotalTax money.Money    `json:"total_tax"`
+	Total    money.Money    `json:"total"`
+}
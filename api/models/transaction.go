@@ -1,39 +1,40 @@
 package models
 
 import (
+	"sell/pkg/money"
 	"time"
 )
 
 type Transaction struct {
-	ID              string    `json:"id"`
-	SaleID          string    `json:"sale_id"`
-	StaffID         string    `json:"staff_id"`
-	TransactionType string    `json:"transaction_type"`
-	SourceType      string    `json:"source_type"`
-	Amount          float64   `json:"amount"`
-	Description     string    `json:"description"`
-	CreatedAt       time.Time `json:"created_at"`
-	UpdatedAt       time.Time `json:"updated_at"`
-	DeletedAt       string    `json:"-"`
+	ID              string      `json:"id"`
+	SaleID          string      `json:"sale_id"`
+	StaffID         string      `json:"staff_id"`
+	TransactionType string      `json:"transaction_type"`
+	SourceType      string      `json:"source_type"`
+	Amount          money.Money `json:"amount"`
+	Description     string      `json:"description"`
+	CreatedAt       time.Time   `json:"created_at"`
+	UpdatedAt       time.Time   `json:"updated_at"`
+	DeletedAt       string      `json:"-"`
 }
 
 type CreateTransaction struct {
-	SaleID          string  `json:"sale_id"`
-	StaffID         string  `json:"staff_id"`
-	TransactionType string  `json:"transaction_type"`
-	SourceType      string  `json:"source_type"`
-	Amount          float64 `json:"amount"`
-	Description     string  `json:"description"`
+	SaleID          string      `json:"sale_id"`
+	StaffID         string      `json:"staff_id"`
+	TransactionType string      `json:"transaction_type"`
+	SourceType      string      `json:"source_type"`
+	Amount          money.Money `json:"amount"`
+	Description     string      `json:"description"`
 }
 
 type UpdateTransaction struct {
-	ID              string  `json:"-"`
-	SaleID          string  `json:"sale_id"`
-	StaffID         string  `json:"staff_id"`
-	TransactionType string  `json:"transaction_type"`
-	SourceType      string  `json:"source_type"`
-	Amount          float64 `json:"amount"`
-	Description     string  `json:"description"`
+	ID              string      `json:"-"`
+	SaleID          string      `json:"sale_id"`
+	StaffID         string      `json:"staff_id"`
+	TransactionType string      `json:"transaction_type"`
+	SourceType      string      `json:"source_type"`
+	Amount          money.Money `json:"amount"`
+	Description     string      `json:"description"`
 }
 
 type TransactionResponse struct {
@@ -42,8 +43,8 @@ type TransactionResponse struct {
 }
 
 type TransactionGetListRequest struct {
-	Page       int     `json:"page"`
-	Limit      int     `json:"limit"`
-	FromAmount float64 `json:"from_amount"`
-	ToAmount   float64 `json:"to_amount"`
+	Page       int         `json:"page"`
+	Limit      int         `json:"limit"`
+	FromAmount money.Money `json:"from_amount"`
+	ToAmount   money.Money `json:"to_amount"`
 }
@@ -0,0 +1,80 @@
+package models
+
+import (
+	"sell/pkg/money"
+	"time"
+)
+
+type WriteOff struct {
+	ID         string            `json:"id"`
+	BranchID   string            `json:"branch_id"`
+	Reason     string            `json:"reason"`
+	Status     string            `json:"status"`
+	Price      money.Money       `json:"price"`
+	Comment    string            `json:"comment"`
+	CreatedBy  string            `json:"created_by"`
+	ApprovedBy string            `json:"approved_by"`
+	ApprovedAt *time.Time        `json:"approved_at"`
+	Products   []WriteOffProduct `json:"products"`
+	CreatedAt  time.Time         `json:"created_at"`
+	UpdatedAt  time.Time         `json:"updated_at"`
+}
+
+type WriteOffProduct struct {
+	ID         string      `json:"id"`
+	WriteOffID string      `json:"write_off_id"`
+	ProductID  string      `json:"product_id"`
+	Price      money.Money `json:"price"`
+	Quantity   float64     `json:"quantity"`
+	CreatedAt  time.Time   `json:"created_at"`
+}
+
+type CreateWriteOff struct {
+	BranchID  string                  `json:"branch_id"`
+	Reason    string                  `json:"reason"`
+	Comment   string                  `json:"comment"`
+	CreatedBy string                  `json:"created_by"`
+	Products  []CreateWriteOffProduct `json:"products"`
+}
+
+type CreateWriteOffProduct struct {
+	ProductID string  `json:"product_id"`
+	Quantity  float64 `json:"quantity"`
+}
+
+type ApproveWriteOff struct {
+	ID         string `json:"-"`
+	ApprovedBy string `json:"approved_by"`
+}
+
+type WriteOffResponse struct {
+	WriteOffs []WriteOff `json:"write_offs"`
+	Count     int        `json:"count"`
+}
+
+type WriteOffGetListRequest struct {
+	Page     int    `json:"page"`
+	Limit    int    `json:"limit"`
+	BranchID string `json:"branch_id"`
+	Reason   string `json:"reason"`
+	Status   string `json:"status"`
+}
+
+type WriteOffReportRequest struct {
+	BranchID string `json:"branch_id"`
+	FromDate string `json:"from_date"`
+	ToDate   string `json:"to_date"`
+}
+
+type WriteOffReportRow struct {
+	Reason    string      `json:"reason"`
+	Documents int         `json:"documents"`
+	Quantity  float64     `json:"quantity"`
+	Price     money.Money `json:"price"`
+}
+
+type WriteOffReport struct {
+	Reasons       []WriteOffReportRow `json:"reasons"`
+	TotalQuantity float64             `json:"total_quantity"`
+	TotalPrice    money.Money         `json:"total_price"`
+}
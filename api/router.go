@@ -1,40 +1,90 @@
 package api
 
 import (
+	"context"
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 	_ "sell/api/docs"
 	"sell/api/handler"
+	"sell/config"
+	"sell/pkg/fiscal"
+	"sell/pkg/media"
+	"sell/service"
 	"sell/storage"
+	"strings"
 )
 
 // New ...
 // @title           Swagger Example API
 // @version         1.0
 // @description     This is a sample server celler server.
-func New(storage storage.IStorage) *gin.Engine {
-	h := handler.New(storage)
+func New(cfg config.Config, storage storage.IStorage, files media.Storage) *gin.Engine {
+	svc := service.New(cfg, storage, fiscal.NewFile(cfg.FiscalPath))
+	h := handler.New(cfg, storage, svc, files)
 
+	// sales and refunds the fiscal module did not take at once are sent again in the background
+	go svc.RunFiscalOutbox(context.Background())
+
+	r := Router(cfg, h)
+	r.Run(":8080")
+	return r
+}
+
+// Router returns an engine with the routes of the api served by h.
+func Router(cfg config.Config, h handler.Handler) *gin.Engine {
 	r := gin.New()
 
 	r.Use(gin.Logger())
 
+	// uploaded files are served by the api when they are kept on the local disk
+	if strings.HasPrefix(cfg.MediaURL, "/") {
+		r.Static(cfg.MediaURL, cfg.MediaPath)
+	}
+
 	r.POST("/sell", h.StartSell)
 	r.PUT("/end-sell/:id", h.EndSell)
 	r.POST("/barcode", h.Barcode)
+	r.POST("/barcode-format", h.CreateBarcodeFormat)
+	r.GET("/barcode-formats", h.GetBarcodeFormatList)
+	r.DELETE("/barcode-format/:id", h.DeleteBarcodeFormat)
 
 	r.POST("/category", h.CreateCategory)
 	r.GET("/category/:id", h.GetCategory)
 	r.GET("/categories", h.GetCategoryList)
 	r.PUT("/category/:id", h.UpdateCategory)
 	r.DELETE("/category/:id", h.DeleteCategory)
+	r.GET("/category/tree", h.GetCategoryTree)
+	r.GET("/category/:id/tree", h.GetCategorySubtree)
+	r.PUT("/category/:id/move", h.MoveCategory)
+	r.PUT("/category/:id/tax-rate", h.SetCategoryTaxRate)
+	r.GET("/category/:id/products", h.GetCategoryProducts)
 
 	r.POST("/product", h.CreateProduct)
 	r.GET("/product/:id", h.GetProduct)
 	r.GET("/products", h.GetProductList)
 	r.PUT("/product/:id", h.UpdateProduct)
 	r.DELETE("/product/:id", h.DeleteProduct)
+	r.POST("/product/:id/barcode", h.AddProductBarcode)
+	r.DELETE("/product/:id/barcode/:barcode_id", h.DeleteProductBarcode)
+	r.POST("/product/:id/unit", h.AddProductUnit)
+	r.DELETE("/product/:id/unit/:unit_id", h.DeleteProductUnit)
+	r.POST("/product/:id/variant", h.CreateProductVariant)
+	r.POST("/product/:id/price", h.CreateProductPrice)
+	r.GET("/product/:id/price", h.GetProductPrice)
+	r.GET("/product/:id/prices", h.GetProductPriceList)
+	r.DELETE("/product/:id/price/:price_id", h.DeleteProductPrice)
+	r.PUT("/product/:id/tax-rate", h.SetProductTaxRate)
+	r.GET("/product/:id/tax-rate", h.GetProductTaxRate)
+	r.POST("/product/import", h.ImportProducts)
+	r.GET("/product/export", h.ExportProducts)
+	r.GET("/product/search", h.SearchProducts)
+	r.POST("/product/:id/image", h.AddProductImage)
+	r.GET("/product/:id/images", h.GetProductImages)
+	r.DELETE("/product/:id/image/:image_id", h.DeleteProductImage)
+	r.GET("/barcode/:barcode", h.GetProductByBarcode)
+	r.GET("/barcode/:barcode/image", h.GetBarcodeImage)
+	r.POST("/labels", h.CreateLabels)
 
 	r.POST("/branch", h.CreateBranch)
 	r.GET("/branch/:id", h.GetBranch)
@@ -46,13 +96,27 @@ func New(storage storage.IStorage) *gin.Engine {
 	r.GET("/repository/:id", h.GetRepository)
 	r.GET("/repositories", h.GetRepositoryList)
 	r.PUT("/repository/:id", h.UpdateRepository)
+	r.PUT("/repository/:id/levels", h.UpdateRepositoryLevels)
 	r.DELETE("/repository/:id", h.DeleteRepository)
+	r.GET("/repositories/low-stock", h.GetLowStockList)
+	r.GET("/repositories/reorder", h.GetReorderSuggestions)
+	r.GET("/repositories/reconcile", h.GetStockReconciliation)
+	r.POST("/repositories/reconcile", h.ReconcileStock)
 
 	r.POST("/sale", h.CreateSale)
 	r.GET("/sale/:id", h.GetSale)
 	r.GET("/sales", h.GetSaleList)
 	r.PUT("/sale/:id", h.UpdateSale)
 	r.DELETE("/sale/:id", h.DeleteSale)
+	r.GET("/sale/:id/receipt", h.GetSaleReceipt)
+	r.POST("/sale/:id/payment", h.CreateSalePayment)
+	r.GET("/sale/:id/payments", h.GetSalePayments)
+	r.POST("/sale/:id/refund", h.CreateRefund)
+	r.GET("/sale/:id/refunds", h.GetSaleRefunds)
+	r.GET("/refund/:id", h.GetRefund)
+
+	r.GET("/fiscal-outbox", h.GetFiscalOutboxList)
+	r.PUT("/fiscal-outbox/:id/retry", h.RetryFiscalEntry)
 
 	r.POST("/basket", h.CreateBasket)
 	r.GET("/basket/:id", h.GetBasket)
@@ -79,9 +143,12 @@ func New(storage storage.IStorage) *gin.Engine {
 	r.PUT("/transaction/:id", h.UpdateTransaction)
 	r.DELETE("/transaction/:id", h.DeleteTransaction)
 
+	// the movement ledger is append-only, corrections and deletions post reversing movements
 	r.POST("/rtransaction", h.CreateRepositoryTransaction)
 	r.GET("/rtransaction/:id", h.GetRepositoryTransaction)
 	r.GET("/rtransactions", h.GetRepositoryTransactionList)
+	r.GET("/rtransactions/history", h.GetMovementHistory)
+	r.GET("/stock-snapshot", h.GetStockSnapshot)
 	r.PUT("/rtransaction/:id", h.UpdateRepositoryTransaction)
 	r.DELETE("/rtransaction/:id", h.DeleteRepositoryTransaction)
 
@@ -90,6 +157,7 @@ func New(storage storage.IStorage) *gin.Engine {
 	r.GET("/incomes", h.GetIncomeList)
 	r.PUT("/income/:id", h.UpdateIncome)
 	r.DELETE("/income/:id", h.DeleteIncome)
+	r.PUT("/income/:id/finish", h.FinishIncome)
 
 	r.POST("/income-product", h.CreateIncomeProduct)
 	r.GET("/income-product/:id", h.GetIncomeProduct)
@@ -97,7 +165,44 @@ func New(storage storage.IStorage) *gin.Engine {
 	r.PUT("/income-product/:id", h.UpdateIncomeProduct)
 	r.DELETE("/income-product/:id", h.DeleteIncomeProduct)
 
+	r.POST("/write-off", h.CreateWriteOff)
+	r.GET("/write-off/:id", h.GetWriteOff)
+	r.GET("/write-offs", h.GetWriteOffList)
+	r.PUT("/write-off/:id/approve", h.ApproveWriteOff)
+	r.PUT("/write-off/:id/reject", h.RejectWriteOff)
+	r.DELETE("/write-off/:id", h.DeleteWriteOff)
+	r.GET("/write-off-report", h.GetWriteOffReport)
+
+	r.POST("/supplier", h.CreateSupplier)
+	r.GET("/supplier/:id", h.GetSupplier)
+	r.GET("/suppliers", h.GetSupplierList)
+	r.PUT("/supplier/:id", h.UpdateSupplier)
+	r.DELETE("/supplier/:id", h.DeleteSupplier)
+	r.POST("/supplier/:id/payment", h.CreateSupplierPayment)
+	r.GET("/supplier/:id/payments", h.GetSupplierPaymentList)
+
+	r.POST("/purchase-order", h.CreatePurchaseOrder)
+	r.GET("/purchase-order/:id", h.GetPurchaseOrder)
+	r.GET("/purchase-orders", h.GetPurchaseOrderList)
+	r.PUT("/purchase-order/:id/cancel", h.CancelPurchaseOrder)
+	r.POST("/purchase-order/:id/receive", h.ReceivePurchaseOrder)
+	r.GET("/purchase-order/:id/discrepancies", h.GetPurchaseOrderDiscrepancies)
+
+	r.GET("/batches", h.GetBatchList)
+	r.GET("/batches/expiring", h.GetExpiringBatches)
+
+	r.POST("/exchange-rate", h.CreateExchangeRate)
+	r.GET("/exchange-rate/:currency", h.GetExchangeRate)
+	r.GET("/exchange-rates", h.GetExchangeRateList)
+	r.DELETE("/exchange-rate/:id", h.DeleteExchangeRate)
+
+	r.POST("/tax-rate", h.CreateTaxRate)
+	r.GET("/tax-rate/:id", h.GetTaxRate)
+	r.GET("/tax-rates", h.GetTaxRateList)
+	r.PUT("/tax-rate/:id", h.UpdateTaxRate)
+	r.DELETE("/tax-rate/:id", h.DeleteTaxRate)
+	r.GET("/tax-report", h.GetTaxReport)
+
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
-	r.Run(":8080")
 	return r
 }
@@ -6,6 +6,7 @@ import (
 	"log"
 	"sell/api"
 	"sell/config"
+	"sell/pkg/media"
 	"sell/storage/postgres"
 )
 
@@ -18,7 +19,10 @@ func main() {
 	}
 	defer store.Close()
 
-	server := api.New(store)
+	// uploaded files are kept on the local disk, another media.Storage can be passed instead
+	files := media.NewLocal(cfg.MediaPath, cfg.MediaURL)
+
+	server := api.New(cfg, store, files)
 
 	if err := server.Run("localhost:8080"); err != nil {
 		fmt.Printf("error while running server: %v\n", err)
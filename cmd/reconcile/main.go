@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"sell/api/models"
+	"sell/config"
+	"sell/storage/postgres"
+)
+
+func main() {
+	branchID := flag.String("branch", "", "reconcile only this branch")
+	fix := flag.String("fix", "", "ledger to post reconciliation movements trusting the counts, counts to set the counts from the ledger")
+	flag.Parse()
+
+	if *fix != "" && *fix != "ledger" && *fix != "counts" {
+		log.Fatalf("fix should be ledger or counts")
+	}
+
+	cfg := config.Load()
+
+	store, err := postgres.New(context.Background(), cfg)
+	if err != nil {
+		log.Fatalf("error while connecting to db: %v", err)
+	}
+	defer store.Close()
+
+	response, err := store.Repository().Reconcile(context.Background(), models.ReconcileRequest{
+		BranchID: *branchID,
+		Mode:     *fix,
+	})
+	if err != nil {
+		log.Fatalf("error while reconciling stock: %v", err)
+	}
+
+	for _, d := range response.Discrepancies {
+		fmt.Printf("branch %s product %s: count %g, expected %g, difference %g\n",
+			d.BranchID, d.ProductID, d.Count, d.ExpectedCount, d.Difference)
+	}
+
+	fmt.Printf("discrepancies: %d, movements without branch: %d, fixed: %t\n",
+		response.Count, response.UnassignedMovements, response.Fixed)
+}
@@ -5,6 +5,8 @@ import (
 	"github.com/joho/godotenv"
 	"github.com/spf13/cast"
 	"os"
+	"strings"
+	"time"
 )
 
 type Config struct {
@@ -13,6 +15,24 @@ type Config struct {
 	PostgresUser     string
 	PostgresPassword string
 	PostgresDB       string
+
+	ReorderWindowDays int
+	ReorderCoverDays  int
+
+	MediaPath string
+	MediaURL  string
+
+	DefaultLanguage string
+	Languages       []string
+
+	BaseCurrency string
+	Currencies   []string
+
+	PricesIncludeTax bool
+
+	FiscalPath          string
+	FiscalRetryInterval time.Duration
+	FiscalMaxAttempts   int
 }
 
 func Load() Config {
@@ -26,6 +46,24 @@ func Load() Config {
 	cfg.PostgresUser = cast.ToString(getOrReturnDefault("POSTGRES_USER", "your user"))
 	cfg.PostgresPassword = cast.ToString(getOrReturnDefault("POSTGRES_PASSWORD", "your password"))
 	cfg.PostgresDB = cast.ToString(getOrReturnDefault("POSTGRES_DB", "your database"))
+
+	cfg.ReorderWindowDays = cast.ToInt(getOrReturnDefault("REORDER_WINDOW_DAYS", 30))
+	cfg.ReorderCoverDays = cast.ToInt(getOrReturnDefault("REORDER_COVER_DAYS", 7))
+
+	cfg.MediaPath = cast.ToString(getOrReturnDefault("MEDIA_PATH", "./media"))
+	cfg.MediaURL = cast.ToString(getOrReturnDefault("MEDIA_URL", "/media"))
+
+	cfg.DefaultLanguage = cast.ToString(getOrReturnDefault("DEFAULT_LANGUAGE", "uz"))
+	cfg.Languages = strings.Split(cast.ToString(getOrReturnDefault("LANGUAGES", "uz,ru,en")), ",")
+
+	cfg.BaseCurrency = strings.ToUpper(cast.ToString(getOrReturnDefault("BASE_CURRENCY", "UZS")))
+	cfg.Currencies = strings.Split(strings.ToUpper(cast.ToString(getOrReturnDefault("CURRENCIES", "UZS,USD,EUR"))), ",")
+
+	cfg.PricesIncludeTax = cast.ToBool(getOrReturnDefault("PRICES_INCLUDE_TAX", true))
+
+	cfg.FiscalPath = cast.ToString(getOrReturnDefault("FISCAL_PATH", "./fiscal"))
+	cfg.FiscalRetryInterval = time.Duration(cast.ToInt(getOrReturnDefault("FISCAL_RETRY_SECONDS", 30))) * time.Second
+	cfg.FiscalMaxAttempts = cast.ToInt(getOrReturnDefault("FISCAL_MAX_ATTEMPTS", 20))
 	return cfg
 }
 
@@ -0,0 +1,15 @@
+alter table repository_transactions drop column if exists reason;
+
+drop table if exists write_off_products;
+
+drop table if exists write_offs;
+
+drop type if exists write_off_status_enum;
+drop type if exists write_off_reason_enum;
+
+-- values can not be removed from an enum, it is created again without manager.
+-- the migration fails while managers are left, they have to be deleted or given another type first
+alter type staff_type_enum rename to staff_type_enum_old;
+create type staff_type_enum as enum ('shop_assistant', 'cashier');
+alter table staffs alter column staff_type type staff_type_enum using staff_type::text::staff_type_enum;
+drop type staff_type_enum_old;
@@ -0,0 +1,32 @@
+alter type staff_type_enum add value if not exists 'manager';
+
+create type write_off_reason_enum as enum ('damage', 'expiry', 'theft', 'internal_use');
+create type write_off_status_enum as enum ('pending', 'approved', 'rejected');
+
+create table if not exists write_offs(
+    id uuid primary key ,
+    branch_id uuid references branches(id),
+    reason write_off_reason_enum not null,
+    status write_off_status_enum default 'pending',
+    price int default 0,
+    comment text,
+    created_by uuid references staffs(id),
+    approved_by uuid references staffs(id) default null,
+    approved_at TIMESTAMP DEFAULT NULL,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW(),
+    deleted_at TIMESTAMP DEFAULT NULL
+);
+
+create table if not exists write_off_products(
+    id uuid primary key ,
+    write_off_id uuid references write_offs(id),
+    product_id uuid references products(id),
+    price int,
+    quantity int,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW(),
+    deleted_at TIMESTAMP DEFAULT NULL
+);
+
+alter table repository_transactions add column if not exists reason varchar(30) default null;
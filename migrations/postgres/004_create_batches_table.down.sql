@@ -0,0 +1,4 @@
+drop table if exists repository_batches;
+
+alter table income_products drop column if exists expiry_date;
+alter table income_products drop column if exists batch_number;
@@ -0,0 +1,17 @@
+alter table income_products add column if not exists batch_number varchar(50) default null;
+alter table income_products add column if not exists expiry_date date default null;
+
+create table if not exists repository_batches(
+    id uuid primary key ,
+    branch_id uuid references branches(id),
+    product_id uuid references products(id),
+    income_product_id uuid references income_products(id) default null,
+    batch_number varchar(50),
+    expiry_date date default null,
+    count int default 0,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW(),
+    deleted_at TIMESTAMP DEFAULT NULL
+);
+
+create index if not exists repository_batches_branch_product_idx on repository_batches(branch_id, product_id, expiry_date);
@@ -0,0 +1,2 @@
+alter table repositories drop column if exists target_count;
+alter table repositories drop column if exists min_count;
@@ -0,0 +1,2 @@
+alter table repositories add column if not exists min_count int default 0;
+alter table repositories add column if not exists target_count int default 0;
@@ -0,0 +1,9 @@
+alter table incomes drop column if exists purchase_order_id;
+alter table incomes drop column if exists supplier_id;
+
+drop table if exists purchase_order_products;
+drop table if exists purchase_orders;
+drop table if exists supplier_payments;
+drop table if exists suppliers;
+
+drop type if exists purchase_order_status_enum;
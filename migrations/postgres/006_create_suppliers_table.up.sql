@@ -0,0 +1,48 @@
+create type purchase_order_status_enum as enum ('ordered', 'partially_received', 'received', 'cancelled');
+
+create table if not exists suppliers(
+    id uuid primary key ,
+    name varchar(50) not null,
+    phone varchar(20),
+    address varchar(100),
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW(),
+    deleted_at TIMESTAMP DEFAULT NULL
+);
+
+create table if not exists supplier_payments(
+    id uuid primary key ,
+    supplier_id uuid references suppliers(id),
+    amount int not null,
+    comment text,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW(),
+    deleted_at TIMESTAMP DEFAULT NULL
+);
+
+create table if not exists purchase_orders(
+    id uuid primary key ,
+    supplier_id uuid references suppliers(id),
+    branch_id uuid references branches(id),
+    status purchase_order_status_enum default 'ordered',
+    price int default 0,
+    comment text,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW(),
+    deleted_at TIMESTAMP DEFAULT NULL
+);
+
+create table if not exists purchase_order_products(
+    id uuid primary key ,
+    purchase_order_id uuid references purchase_orders(id),
+    product_id uuid references products(id),
+    price int,
+    count int,
+    received_count int default 0,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW(),
+    deleted_at TIMESTAMP DEFAULT NULL
+);
+
+alter table incomes add column if not exists supplier_id uuid references suppliers(id) default null;
+alter table incomes add column if not exists purchase_order_id uuid references purchase_orders(id) default null;
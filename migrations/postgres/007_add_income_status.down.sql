@@ -0,0 +1,4 @@
+alter table incomes drop column if exists finished_at;
+alter table incomes drop column if exists status;
+
+drop type if exists income_status_enum;
@@ -0,0 +1,10 @@
+create type income_status_enum as enum ('in_process', 'finished');
+
+alter table incomes add column if not exists status income_status_enum default 'in_process';
+alter table incomes add column if not exists finished_at timestamp default null;
+
+-- incomes created before finalizing existed have already been added to repositories
+update incomes set status = 'finished', finished_at = updated_at where deleted_at is null;
+update incomes set price = (
+    select coalesce(sum(price * count), 0) from income_products where income_id = incomes.id and deleted_at is null
+);
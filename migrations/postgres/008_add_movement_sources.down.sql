@@ -0,0 +1,6 @@
+drop index if exists repository_transactions_branch_product_idx;
+
+alter table repository_transactions drop column if exists source_id;
+alter table repository_transactions drop column if exists source_type;
+
+drop type if exists movement_source_type_enum;
@@ -0,0 +1,12 @@
+create type movement_source_type_enum as enum ('sale', 'income', 'transfer', 'write_off', 'stocktake', 'adjustment');
+
+alter table repository_transactions add column if not exists source_type movement_source_type_enum default null;
+alter table repository_transactions add column if not exists source_id uuid default null;
+
+update repository_transactions set source_type = 'write_off'
+    where reason in ('damage', 'expiry', 'theft', 'internal_use');
+update repository_transactions set source_type = 'adjustment'
+    where reason in ('adjustment', 'reconciliation');
+
+create index if not exists repository_transactions_branch_product_idx
+    on repository_transactions (branch_id, product_id, created_at);
@@ -0,0 +1,3 @@
+drop table if exists product_barcodes;
+
+alter table products alter column barcode type int using nullif(regexp_replace(barcode, '\D', '', 'g'), '')::int;
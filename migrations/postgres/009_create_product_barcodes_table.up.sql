@@ -0,0 +1,16 @@
+alter table products alter column barcode type varchar(50) using barcode::text;
+
+create table if not exists product_barcodes(
+    id uuid primary key,
+    product_id uuid references products(id),
+    barcode varchar(50) not null,
+    quantity int default 1,
+    created_at timestamp default now(),
+    updated_at timestamp default now(),
+    deleted_at timestamp default null
+);
+
+create unique index if not exists product_barcodes_barcode_idx on product_barcodes (barcode) where deleted_at is null;
+
+insert into product_barcodes (id, product_id, barcode, quantity)
+    select gen_random_uuid(), id, barcode, 1 from products where barcode is not null and deleted_at is null;
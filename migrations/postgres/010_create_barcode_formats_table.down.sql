@@ -0,0 +1,5 @@
+alter table baskets alter column quantity type int using round(quantity)::int;
+
+drop table if exists barcode_formats;
+
+drop type if exists barcode_value_type_enum;
@@ -0,0 +1,19 @@
+create type barcode_value_type_enum as enum ('weight', 'price');
+
+create table if not exists barcode_formats(
+    id uuid primary key,
+    name varchar(50),
+    prefix varchar(5) not null,
+    code_length int default 13,
+    product_code_start int not null,
+    product_code_length int not null,
+    value_start int not null,
+    value_length int not null,
+    value_type barcode_value_type_enum not null,
+    value_decimals int default 0,
+    created_at timestamp default now(),
+    updated_at timestamp default now(),
+    deleted_at timestamp default null
+);
+
+alter table baskets alter column quantity type numeric(14, 3);
@@ -0,0 +1,20 @@
+alter table purchase_order_products alter column received_count type int using round(received_count)::int;
+alter table purchase_order_products alter column count type int using round(count)::int;
+alter table write_off_products alter column quantity type int using round(quantity)::int;
+
+alter table income_products drop column if exists unit_quantity;
+alter table income_products drop column if exists product_unit_id;
+alter table income_products alter column count type int using round(count)::int;
+
+alter table repository_batches alter column count type int using round(count)::int;
+alter table repository_transactions alter column quantity type int using round(quantity)::int;
+alter table repositories alter column target_count type int using round(target_count)::int;
+alter table repositories alter column min_count type int using round(min_count)::int;
+alter table repositories alter column count type int using round(count)::int;
+
+drop table if exists product_units;
+
+alter table products drop column if exists unit_precision;
+alter table products drop column if exists unit;
+
+drop type if exists unit_enum;
@@ -0,0 +1,29 @@
+create type unit_enum as enum ('piece', 'kg', 'litre', 'metre');
+
+alter table products add column if not exists unit unit_enum default 'piece';
+alter table products add column if not exists unit_precision int default 0;
+
+-- packs a product is received or counted in, e.g. a box of 12 pieces
+create table if not exists product_units(
+    id uuid primary key,
+    product_id uuid references products(id),
+    name varchar(30) not null,
+    quantity numeric(14, 3) not null,
+    created_at timestamp default now(),
+    updated_at timestamp default now(),
+    deleted_at timestamp default null
+);
+
+alter table repositories alter column count type numeric(14, 3);
+alter table repositories alter column min_count type numeric(14, 3);
+alter table repositories alter column target_count type numeric(14, 3);
+alter table repository_transactions alter column quantity type numeric(14, 3);
+alter table repository_batches alter column count type numeric(14, 3);
+
+alter table income_products alter column count type numeric(14, 3);
+alter table income_products add column if not exists product_unit_id uuid references product_units(id) default null;
+alter table income_products add column if not exists unit_quantity numeric(14, 3) default 1;
+
+alter table write_off_products alter column quantity type numeric(14, 3);
+alter table purchase_order_products alter column count type numeric(14, 3);
+alter table purchase_order_products alter column received_count type numeric(14, 3);
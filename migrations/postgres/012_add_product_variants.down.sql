@@ -0,0 +1,9 @@
+drop index if exists products_variant_attributes_idx;
+drop index if exists products_parent_id_idx;
+
+update products p set price = pp.price from products pp where pp.id = p.parent_id and p.price is null;
+
+alter table products drop column if exists attributes;
+alter table products drop column if exists parent_id;
+
+alter table products alter column name type varchar(30) using left(name, 30);
@@ -0,0 +1,10 @@
+alter table products alter column name type varchar(100);
+
+alter table products add column if not exists parent_id uuid references products(id) default null;
+alter table products add column if not exists attributes jsonb not null default '{}';
+
+create index if not exists products_parent_id_idx on products (parent_id);
+
+-- two variants of a product can not have the same attributes
+create unique index if not exists products_variant_attributes_idx on products (parent_id, attributes)
+    where parent_id is not null and deleted_at is null;
@@ -0,0 +1,17 @@
+-- prices of a product for all branches (branch_id is null) or for one branch, valid from starts_at
+-- until the next price of the same scope starts. A variant price of null means the parent price is used.
+create table if not exists product_prices(
+    id uuid primary key,
+    product_id uuid references products(id),
+    branch_id uuid references branches(id) default null,
+    price int,
+    starts_at timestamp not null default now(),
+    created_at timestamp default now(),
+    updated_at timestamp default now(),
+    deleted_at timestamp default null
+);
+
+create index if not exists product_prices_product_id_idx on product_prices (product_id, starts_at);
+
+insert into product_prices (id, product_id, price, starts_at)
+    select gen_random_uuid(), id, price, created_at from products where price is not null and deleted_at is null;
@@ -0,0 +1,2 @@
+drop index if exists products_category_id_idx;
+drop index if exists categories_parent_id_idx;
@@ -0,0 +1,2 @@
+create index if not exists categories_parent_id_idx on categories (parent_id) where deleted_at is null;
+create index if not exists products_category_id_idx on products (category_id) where deleted_at is null;
@@ -0,0 +1,5 @@
+drop index if exists product_barcodes_barcode_prefix_idx;
+drop index if exists products_name_fts_idx;
+drop index if exists products_name_trgm_idx;
+
+drop extension if exists pg_trgm;
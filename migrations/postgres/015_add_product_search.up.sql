@@ -0,0 +1,5 @@
+create extension if not exists pg_trgm;
+
+create index if not exists products_name_trgm_idx on products using gin (lower(name) gin_trgm_ops) where deleted_at is null;
+create index if not exists products_name_fts_idx on products using gin (to_tsvector('simple', name)) where deleted_at is null;
+create index if not exists product_barcodes_barcode_prefix_idx on product_barcodes (barcode text_pattern_ops) where deleted_at is null;
@@ -0,0 +1,18 @@
+create table if not exists product_images(
+    id uuid primary key,
+    product_id uuid references products(id),
+    key varchar(255) not null,
+    url varchar(500) not null,
+    thumbnail_key varchar(255) not null,
+    thumbnail_url varchar(500) not null,
+    content_type varchar(50) not null,
+    width int not null,
+    height int not null,
+    size bigint not null,
+    position int not null default 0,
+    created_at timestamp default now(),
+    updated_at timestamp default now(),
+    deleted_at timestamp default null
+);
+
+create index if not exists product_images_product_id_idx on product_images (product_id, position) where deleted_at is null;
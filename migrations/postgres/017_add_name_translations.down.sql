@@ -0,0 +1,2 @@
+alter table categories drop column if exists name_translations;
+alter table products drop column if exists name_translations;
@@ -0,0 +1,3 @@
+-- names in other languages by language code, name keeps the name in the default language
+alter table products add column if not exists name_translations jsonb not null default '{}';
+alter table categories add column if not exists name_translations jsonb not null default '{}';
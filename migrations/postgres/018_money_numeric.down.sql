@@ -0,0 +1,22 @@
+alter table purchase_order_products alter column price type int using round(price)::int;
+alter table purchase_orders alter column price type int using round(price)::int;
+alter table supplier_payments alter column amount type int using round(amount)::int;
+
+alter table write_off_products alter column price type int using round(price)::int;
+alter table write_offs alter column price type int using round(price)::int;
+
+alter table income_products alter column price type int using round(price)::int;
+alter table incomes alter column price type int using round(price)::int;
+
+alter table repository_transactions alter column price type int using round(price)::int;
+
+alter table transactions alter column amount type numeric;
+alter table staffs alter column balance type int using round(balance)::int;
+alter table staff_tariffs alter column amount_for_card type int using round(amount_for_card)::int;
+alter table staff_tariffs alter column amount_for_cash type int using round(amount_for_cash)::int;
+
+alter table baskets alter column price type int using round(price)::int;
+alter table sales alter column price type numeric;
+
+alter table product_prices alter column price type int using round(price)::int;
+alter table products alter column price type int using round(price)::int;
@@ -0,0 +1,23 @@
+-- amounts of money are numeric(18, 2): whole currency units with two decimal places
+alter table products alter column price type numeric(18, 2);
+alter table product_prices alter column price type numeric(18, 2);
+
+alter table sales alter column price type numeric(18, 2) using round(price, 2);
+alter table baskets alter column price type numeric(18, 2);
+
+alter table staff_tariffs alter column amount_for_cash type numeric(18, 2);
+alter table staff_tariffs alter column amount_for_card type numeric(18, 2);
+alter table staffs alter column balance type numeric(18, 2);
+alter table transactions alter column amount type numeric(18, 2) using round(amount, 2);
+
+alter table repository_transactions alter column price type numeric(18, 2);
+
+alter table incomes alter column price type numeric(18, 2);
+alter table income_products alter column price type numeric(18, 2);
+
+alter table write_offs alter column price type numeric(18, 2);
+alter table write_off_products alter column price type numeric(18, 2);
+
+alter table supplier_payments alter column amount type numeric(18, 2);
+alter table purchase_orders alter column price type numeric(18, 2);
+alter table purchase_order_products alter column price type numeric(18, 2);
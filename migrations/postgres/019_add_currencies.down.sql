@@ -0,0 +1,13 @@
+drop table if exists sale_payments;
+
+alter table sales drop column if exists currency;
+
+alter table supplier_payments drop column if exists exchange_rate;
+alter table supplier_payments drop column if exists currency;
+
+alter table purchase_orders drop column if exists currency;
+
+alter table incomes drop column if exists exchange_rate;
+alter table incomes drop column if exists currency;
+
+drop table if exists exchange_rates;
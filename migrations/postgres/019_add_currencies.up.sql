@@ -0,0 +1,40 @@
+-- rates of currencies to the base currency: one unit of currency costs rate units of the base currency
+-- from starts_at until the next rate of the currency starts
+create table if not exists exchange_rates(
+    id uuid primary key,
+    currency varchar(3) not null,
+    rate numeric(18, 6) not null,
+    starts_at timestamp not null default now(),
+    created_at timestamp default now(),
+    updated_at timestamp default now(),
+    deleted_at timestamp default null
+);
+
+create index if not exists exchange_rates_currency_idx on exchange_rates (currency, starts_at);
+
+-- documents keep their currency and the rate to the base currency they were converted at,
+-- existing documents are in the base currency
+alter table incomes add column if not exists currency varchar(3) not null default 'UZS';
+alter table incomes add column if not exists exchange_rate numeric(18, 6) not null default 1;
+
+alter table purchase_orders add column if not exists currency varchar(3) not null default 'UZS';
+
+alter table supplier_payments add column if not exists currency varchar(3) not null default 'UZS';
+alter table supplier_payments add column if not exists exchange_rate numeric(18, 6) not null default 1;
+
+alter table sales add column if not exists currency varchar(3) not null default 'UZS';
+
+-- money received for a sale, possibly in another currency. base_amount is the amount in the currency of the sale
+create table if not exists sale_payments(
+    id uuid primary key,
+    sale_id uuid references sales(id),
+    currency varchar(3) not null,
+    amount numeric(18, 2) not null,
+    exchange_rate numeric(18, 6) not null,
+    base_amount numeric(18, 2) not null,
+    created_at timestamp default now(),
+    updated_at timestamp default now(),
+    deleted_at timestamp default null
+);
+
+create index if not exists sale_payments_sale_id_idx on sale_payments (sale_id);
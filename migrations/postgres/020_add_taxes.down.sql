@@ -0,0 +1,13 @@
+drop index if exists sales_finished_at_idx;
+
+alter table sales drop column if exists finished_at;
+alter table sales drop column if exists tax_amount;
+alter table sales drop column if exists tax_included;
+
+alter table baskets drop column if exists tax_amount;
+alter table baskets drop column if exists tax_rate;
+
+alter table categories drop column if exists tax_rate_id;
+alter table products drop column if exists tax_rate_id;
+
+drop table if exists tax_rates;
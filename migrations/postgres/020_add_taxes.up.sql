@@ -0,0 +1,24 @@
+-- rates are percents, a product without a rate of its own is taxed at the rate of the product it is a variant of
+-- or of the nearest category up the tree which has one
+create table if not exists tax_rates(
+    id uuid primary key,
+    name varchar(50) not null,
+    rate numeric(5, 2) not null,
+    created_at timestamp default now(),
+    updated_at timestamp default now(),
+    deleted_at timestamp default null
+);
+
+alter table products add column if not exists tax_rate_id uuid references tax_rates(id);
+alter table categories add column if not exists tax_rate_id uuid references tax_rates(id);
+
+-- the tax is calculated at checkout and kept with the sale, later changes of rates do not change sold receipts.
+-- tax_included tells whether basket prices include the tax or the tax is added on top of them
+alter table baskets add column if not exists tax_rate numeric(5, 2) not null default 0;
+alter table baskets add column if not exists tax_amount numeric(18, 2) not null default 0;
+
+alter table sales add column if not exists tax_included boolean not null default true;
+alter table sales add column if not exists tax_amount numeric(18, 2) not null default 0;
+alter table sales add column if not exists finished_at timestamp default null;
+
+create index if not exists sales_finished_at_idx on sales (branch_id, finished_at) where status = 'success';
@@ -0,0 +1,11 @@
+drop table if exists fiscal_outbox;
+
+alter table sales drop column if exists fiscalized_at;
+alter table sales drop column if exists fiscal_number;
+alter table sales drop column if exists fiscal_sign;
+
+drop table if exists refund_products;
+drop table if exists refunds;
+
+-- values can not be removed from an enum, movements of refunds become adjustments
+update repository_transactions set source_type = 'adjustment' where source_type = 'refund';
@@ -0,0 +1,57 @@
+alter type movement_source_type_enum add value if not exists 'refund';
+
+-- money and goods given back for a successful sale, the lines are parts of its basket lines
+create table if not exists refunds(
+    id uuid primary key,
+    sale_id uuid not null references sales(id),
+    reason varchar(255),
+    price numeric(18, 2) not null default 0,
+    tax_amount numeric(18, 2) not null default 0,
+    fiscal_sign varchar(255),
+    fiscal_number varchar(255),
+    fiscalized_at timestamp default null,
+    created_at timestamp default now(),
+    updated_at timestamp default now(),
+    deleted_at timestamp default null
+);
+
+create index if not exists refunds_sale_id_idx on refunds (sale_id);
+
+create table if not exists refund_products(
+    id uuid primary key,
+    refund_id uuid not null references refunds(id),
+    basket_id uuid not null references baskets(id),
+    product_id uuid references products(id),
+    quantity numeric(14, 3) not null,
+    price numeric(18, 2) not null,
+    tax_rate numeric(5, 2) not null default 0,
+    tax_amount numeric(18, 2) not null default 0,
+    created_at timestamp default now(),
+    updated_at timestamp default now(),
+    deleted_at timestamp default null
+);
+
+create index if not exists refund_products_basket_id_idx on refund_products (basket_id);
+
+alter table sales add column if not exists fiscal_sign varchar(255);
+alter table sales add column if not exists fiscal_number varchar(255);
+alter table sales add column if not exists fiscalized_at timestamp default null;
+
+-- documents waiting to be registered with the fiscal module. An entry is added in the same statement or transaction
+-- as the document, so a sale or refund is never lost when the module is down; it is sent until it succeeds
+-- or runs out of attempts. next_attempt_at of an entry being sent is moved forward so no one else sends it.
+create table if not exists fiscal_outbox(
+    id uuid primary key,
+    document_type varchar(10) not null check (document_type in ('sale', 'refund')),
+    document_id uuid not null,
+    status varchar(10) not null default 'pending' check (status in ('pending', 'sent', 'failed')),
+    attempts int not null default 0,
+    last_error text,
+    next_attempt_at timestamp not null default now(),
+    sent_at timestamp default null,
+    created_at timestamp default now(),
+    updated_at timestamp default now(),
+    unique (document_type, document_id)
+);
+
+create index if not exists fiscal_outbox_pending_idx on fiscal_outbox (next_attempt_at) where status = 'pending';
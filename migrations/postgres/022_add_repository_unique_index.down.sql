@@ -0,0 +1 @@
+drop index if exists repositories_branch_id_product_id_idx;
@@ -0,0 +1,20 @@
+-- a branch keeps one repository row of a product. rows made twice by incomes received at the same time
+-- are merged into the oldest one
+with duplicates as (
+    select id, count, first_value(id) over (partition by branch_id, product_id order by created_at, id) as keep_id
+        from repositories where deleted_at is null
+), moved as (
+    select keep_id, sum(count) as count from duplicates where id <> keep_id group by keep_id
+)
+update repositories set count = repositories.count + moved.count, updated_at = now()
+    from moved where repositories.id = moved.keep_id;
+
+update repositories set deleted_at = now() where id in (
+    select id from (
+        select id, row_number() over (partition by branch_id, product_id order by created_at, id) as n
+            from repositories where deleted_at is null
+    ) numbered where n > 1
+);
+
+create unique index if not exists repositories_branch_id_product_id_idx on repositories (branch_id, product_id)
+    where deleted_at is null;
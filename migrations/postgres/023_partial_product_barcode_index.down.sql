@@ -0,0 +1,4 @@
+drop index if exists products_barcode_idx;
+
+-- fails when a barcode of a deleted product has been given to another product
+alter table products add constraint products_barcode_key unique (barcode);
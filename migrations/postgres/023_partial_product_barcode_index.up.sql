@@ -0,0 +1,6 @@
+-- barcodes of deleted products can be given to new products
+update product_barcodes set deleted_at = now()
+    where deleted_at is null and product_id in (select id from products where deleted_at is not null);
+
+alter table products drop constraint if exists products_barcode_key;
+create unique index if not exists products_barcode_idx on products (barcode) where deleted_at is null;
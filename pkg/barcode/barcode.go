@@ -0,0 +1,149 @@
+package barcode
+
+import (
+	"errors"
+	"sell/pkg/check"
+)
+
+// Encode turns a barcode into its modules, true for a bar and false for a space.
+// EAN-8, UPC-A and EAN-13 codes with a correct check digit are encoded as EAN, anything else as Code128.
+func Encode(code string) ([]bool, error) {
+	if err := check.ValidateBarcode(code); err != nil {
+		return nil, err
+	}
+
+	if isDigits(code) {
+		switch len(code) {
+		case 8:
+			return encodeEAN8(code), nil
+		case 12:
+			// UPC-A is an EAN-13 starting with 0
+			return encodeEAN13("0" + code), nil
+		case 13:
+			return encodeEAN13(code), nil
+		}
+	}
+
+	return encodeCode128(code)
+}
+
+var (
+	eanL = [10]string{"0001101", "0011001", "0010011", "0111101", "0100011", "0110001", "0101111", "0111011", "0110111", "0001011"}
+	eanG = [10]string{"0100111", "0110011", "0011011", "0100001", "0011101", "0111001", "0000101", "0010001", "0001001", "0010111"}
+	eanR = [10]string{"1110010", "1100110", "1101100", "1000010", "1011100", "1001110", "1010000", "1000100", "1001000", "1110100"}
+
+	// ean13Parity tells which of the left digits use the G codes, the first digit of an EAN-13 is encoded by it
+	ean13Parity = [10]string{"LLLLLL", "LLGLGG", "LLGGLG", "LLGGGL", "LGLLGG", "LGGLLG", "LGGGLL", "LGLGLG", "LGLGGL", "LGGLGL"}
+)
+
+func encodeEAN13(code string) []bool {
+	pattern := "101"
+	parity := ean13Parity[code[0]-'0']
+	for i := 1; i <= 6; i++ {
+		if parity[i-1] == 'G' {
+			pattern += eanG[code[i]-'0']
+		} else {
+			pattern += eanL[code[i]-'0']
+		}
+	}
+	pattern += "01010"
+	for i := 7; i <= 12; i++ {
+		pattern += eanR[code[i]-'0']
+	}
+	pattern += "101"
+
+	return modules(pattern)
+}
+
+func encodeEAN8(code string) []bool {
+	pattern := "101"
+	for i := 0; i < 4; i++ {
+		pattern += eanL[code[i]-'0']
+	}
+	pattern += "01010"
+	for i := 4; i < 8; i++ {
+		pattern += eanR[code[i]-'0']
+	}
+	pattern += "101"
+
+	return modules(pattern)
+}
+
+// code128Patterns holds bar and space widths of every Code128 symbol value, 106 is the stop pattern.
+var code128Patterns = [107]string{
+	"212222", "222122", "222221", "121223", "121322", "131222", "122213", "122312", "132212", "221213",
+	"221312", "231212", "112232", "122132", "122231", "113222", "123122", "123221", "223211", "221132",
+	"221231", "213212", "223112", "312131", "311222", "321122", "321221", "312212", "322112", "322211",
+	"212123", "212321", "232121", "111323", "131123", "131321", "112313", "132113", "132311", "211313",
+	"231113", "231311", "112133", "112331", "132131", "113123", "113321", "133121", "313121", "211331",
+	"231131", "213113", "213311", "213131", "311123", "311321", "331121", "312113", "312311", "332111",
+	"314111", "221411", "431111", "111224", "111422", "121124", "121421", "141122", "141221", "112214",
+	"112412", "122114", "122411", "142112", "142211", "241211", "221114", "413111", "241112", "134111",
+	"111242", "121142", "121241", "114212", "124112", "124211", "411212", "421112", "421211", "212141",
+	"214121", "412121", "111143", "111341", "131141", "114113", "114311", "411113", "411311", "113141",
+	"114131", "311141", "411131", "211412", "211214", "211232", "2331112",
+}
+
+const (
+	code128StartB = 104
+	code128StartC = 105
+	code128Stop   = 106
+)
+
+// encodeCode128 uses code set C for codes of an even number of digits, which makes them shorter,
+// and code set B for everything else.
+func encodeCode128(code string) ([]bool, error) {
+	values := []int{}
+	if isDigits(code) && len(code)%2 == 0 {
+		values = append(values, code128StartC)
+		for i := 0; i < len(code); i += 2 {
+			values = append(values, int(code[i]-'0')*10+int(code[i+1]-'0'))
+		}
+	} else {
+		values = append(values, code128StartB)
+		for i := 0; i < len(code); i++ {
+			if code[i] < ' ' || code[i] > '~' {
+				return nil, errors.New("code128 can encode only printable ASCII characters")
+			}
+			values = append(values, int(code[i]-' '))
+		}
+	}
+
+	checksum := values[0]
+	for i := 1; i < len(values); i++ {
+		checksum += i * values[i]
+	}
+	values = append(values, checksum%103, code128Stop)
+
+	result := []bool{}
+	for _, value := range values {
+		bar := true
+		for _, width := range code128Patterns[value] {
+			for j := 0; j < int(width-'0'); j++ {
+				result = append(result, bar)
+			}
+			bar = !bar
+		}
+	}
+
+	return result, nil
+}
+
+func modules(pattern string) []bool {
+	result := make([]bool, len(pattern))
+	for i := range pattern {
+		result[i] = pattern[i] == '1'
+	}
+
+	return result
+}
+
+func isDigits(code string) bool {
+	for i := 0; i < len(code); i++ {
+		if code[i] < '0' || code[i] > '9' {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,142 @@
+package barcode
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestEncodeEAN13(t *testing.T) {
+	// 4006381333931: the first digit 4 gives the LGLLGG parity of the left half
+	want := "101" +
+		"0001101" + "0100111" + "0101111" + "0111101" + "0001001" + "0110011" +
+		"01010" +
+		"1000010" + "1000010" + "1000010" + "1110100" + "1000010" + "1100110" +
+		"101"
+
+	modules, err := Encode("4006381333931")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := pattern(modules); got != want {
+		t.Errorf("Encode(4006381333931) = %s, want %s", got, want)
+	}
+}
+
+func TestEncodeUPCA(t *testing.T) {
+	upc, err := Encode("036000291452")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ean, err := Encode("0036000291452")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(upc, ean) {
+		t.Errorf("UPC-A 036000291452 is encoded as %s, want the EAN-13 %s", pattern(upc), pattern(ean))
+	}
+}
+
+func TestEncodeEAN8(t *testing.T) {
+	want := "101" +
+		"0001011" + "0101111" + "0111101" + "0110111" +
+		"01010" +
+		"1001110" + "1110010" + "1000100" + "1011100" +
+		"101"
+
+	modules, err := Encode("96385074")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := pattern(modules); got != want {
+		t.Errorf("Encode(96385074) = %s, want %s", got, want)
+	}
+}
+
+func TestEncodeCode128(t *testing.T) {
+	tests := []struct {
+		code   string
+		values []int
+	}{
+		// start B, the check value is (104 + 48 + 2*42 + 3*42 + 4*17 + 5*18 + 6*19 + 7*35) % 103
+		{"PJJ123C", []int{104, 48, 42, 42, 17, 18, 19, 35, 55, 106}},
+		// an even number of digits uses code set C, two digits a symbol
+		{"123456", []int{105, 12, 34, 56, 44, 106}},
+		// an odd number of digits stays in code set B
+		{"12345", []int{104, 17, 18, 19, 20, 21, 90, 106}},
+		// ten digits are not an EAN, a leading zero is kept in the first pair
+		{"0123456789", []int{105, 1, 23, 45, 67, 89, 73, 106}},
+	}
+
+	for _, test := range tests {
+		modules, err := Encode(test.code)
+		if err != nil {
+			t.Errorf("Encode(%q): %v", test.code, err)
+			continue
+		}
+		if got := code128Values(t, modules); !reflect.DeepEqual(got, test.values) {
+			t.Errorf("Encode(%q) has symbols %v, want %v", test.code, got, test.values)
+		}
+	}
+}
+
+func TestEncodeInvalid(t *testing.T) {
+	for _, code := range []string{"", "4006381333932", "a\n", "сут", strings.Repeat("1", 49)} {
+		if _, err := Encode(code); err == nil {
+			t.Errorf("Encode(%q) is accepted", code)
+		}
+	}
+}
+
+func pattern(modules []bool) string {
+	sb := strings.Builder{}
+	for _, bar := range modules {
+		if bar {
+			sb.WriteByte('1')
+		} else {
+			sb.WriteByte('0')
+		}
+	}
+
+	return sb.String()
+}
+
+// code128Values reads the symbols back from the modules: every symbol is 11 modules wide, the stop one 13.
+func code128Values(t *testing.T, modules []bool) []int {
+	t.Helper()
+
+	values := []int{}
+	for i := 0; i < len(modules); {
+		n := 11
+		if len(modules)-i == 13 {
+			n = 13
+		}
+		if i+n > len(modules) {
+			t.Fatalf("%d modules are not a whole number of symbols", len(modules))
+		}
+
+		widths := strings.Builder{}
+		for j := i; j < i+n; {
+			k := j
+			for k < i+n && modules[k] == modules[j] {
+				k++
+			}
+			widths.WriteByte(byte('0' + k - j))
+			j = k
+		}
+
+		value := -1
+		for v, p := range code128Patterns {
+			if p == widths.String() {
+				value = v
+			}
+		}
+		if value < 0 {
+			t.Fatalf("unknown symbol %s", widths.String())
+		}
+		values = append(values, value)
+		i += n
+	}
+
+	return values
+}
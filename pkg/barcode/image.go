@@ -0,0 +1,87 @@
+package barcode
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"strings"
+)
+
+// QuietZone is the number of empty modules scanners need on both sides of a barcode.
+const QuietZone = 10
+
+// PNG writes the modules as a black and white image, every module is scale pixels wide.
+func PNG(w io.Writer, modules []bool, scale, height int) error {
+	width := (len(modules) + 2*QuietZone) * scale
+	img := image.NewGray(image.Rect(0, 0, width, height))
+	for i := range img.Pix {
+		img.Pix[i] = 0xff
+	}
+
+	for i, bar := range modules {
+		if !bar {
+			continue
+		}
+		for x := (QuietZone + i) * scale; x < (QuietZone+i+1)*scale; x++ {
+			for y := 0; y < height; y++ {
+				img.SetGray(x, y, color.Gray{})
+			}
+		}
+	}
+
+	return png.Encode(w, img)
+}
+
+// SVG returns an image of the modules with the code written under the bars.
+func SVG(modules []bool, code string, scale, height int) string {
+	width := (len(modules) + 2*QuietZone) * scale
+	fontSize := 10 * scale / 2
+	if fontSize < 8 {
+		fontSize = 8
+	}
+
+	sb := strings.Builder{}
+	fmt.Fprintf(&sb, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`,
+		width, height+fontSize+2, width, height+fontSize+2)
+	fmt.Fprintf(&sb, `<rect width="100%%" height="100%%" fill="#fff"/>`)
+	sb.WriteString(Bars(modules, float64(QuietZone*scale), 0, float64(scale), float64(height)))
+	fmt.Fprintf(&sb, `<text x="%d" y="%d" font-family="monospace" font-size="%d" text-anchor="middle">%s</text>`,
+		width/2, height+fontSize, fontSize, EscapeXML(code))
+	sb.WriteString(`</svg>`)
+
+	return sb.String()
+}
+
+// Bars returns SVG rectangles of the bars starting at x, y.
+func Bars(modules []bool, x, y, moduleWidth, height float64) string {
+	sb := strings.Builder{}
+	for _, run := range Runs(modules) {
+		fmt.Fprintf(&sb, `<rect x="%.3f" y="%.3f" width="%.3f" height="%.3f"/>`,
+			x+float64(run[0])*moduleWidth, y, float64(run[1])*moduleWidth, height)
+	}
+
+	return sb.String()
+}
+
+// Runs joins neighbouring bar modules, it returns the first module and the width of every bar.
+func Runs(modules []bool) [][2]int {
+	runs := [][2]int{}
+	for i := 0; i < len(modules); i++ {
+		if !modules[i] {
+			continue
+		}
+		start := i
+		for i+1 < len(modules) && modules[i+1] {
+			i++
+		}
+		runs = append(runs, [2]int{start, i - start + 1})
+	}
+
+	return runs
+}
+
+func EscapeXML(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;").Replace(s)
+}
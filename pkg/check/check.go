@@ -1,7 +1,13 @@
 package check
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"math"
+)
 
+// MaxQuantity is the largest quantity a numeric(14, 3) column holds.
+const MaxQuantity = 99_999_999_999.999
 
 func ValidatePassword(password string) error {
 	if len(password) < 6 {
@@ -9,4 +15,95 @@ func ValidatePassword(password string) error {
 	}
 
 	return nil
-}
\ No newline at end of file
+}
+
+func ValidateWriteOffReason(reason string) error {
+	switch reason {
+	case "damage", "expiry", "theft", "internal_use":
+		return nil
+	}
+
+	return errors.New("reason should be one of damage, expiry, theft, internal_use")
+}
+
+func ValidateIncomeStatus(status string) error {
+	switch status {
+	case "in_process", "finished":
+		return nil
+	}
+
+	return errors.New("status should be one of in_process, finished")
+}
+
+// ValidateBarcode accepts EAN-8, UPC-A and EAN-13 codes with a correct check digit.
+// Codes of other lengths are treated as Code128 and may hold any printable ASCII characters.
+func ValidateBarcode(barcode string) error {
+	if barcode == "" || len(barcode) > 48 {
+		return errors.New("barcode length should be from 1 to 48")
+	}
+
+	numeric := true
+	for _, r := range barcode {
+		if r < ' ' || r > '~' {
+			return errors.New("barcode should contain only printable ASCII characters")
+		}
+		if r < '0' || r > '9' {
+			numeric = false
+		}
+	}
+
+	if !numeric {
+		return nil
+	}
+
+	switch len(barcode) {
+	case 8, 12, 13:
+		if BarcodeCheckDigit(barcode[:len(barcode)-1]) != int(barcode[len(barcode)-1]-'0') {
+			return errors.New("wrong barcode check digit")
+		}
+	}
+
+	return nil
+}
+
+// BarcodeCheckDigit calculates the GS1 check digit for the digits of an EAN-8, UPC-A or EAN-13 code
+// without its last digit.
+func BarcodeCheckDigit(digits string) int {
+	sum := 0
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := int(digits[i] - '0')
+		// weights go 3, 1, 3, ... starting from the digit next to the check digit
+		if (len(digits)-1-i)%2 == 0 {
+			d *= 3
+		}
+		sum += d
+	}
+
+	return (10 - sum%10) % 10
+}
+
+func ValidateUnit(unit string) error {
+	switch unit {
+	case "piece", "kg", "litre", "metre":
+		return nil
+	}
+
+	return errors.New("unit should be one of piece, kg, litre, metre")
+}
+
+// ValidateQuantity accepts positive quantities with no more decimals than the precision of the product unit.
+func ValidateQuantity(quantity float64, precision int) error {
+	if quantity <= 0 {
+		return errors.New("quantity should be positive")
+	}
+	if quantity > MaxQuantity || math.IsInf(quantity, 0) || math.IsNaN(quantity) {
+		return fmt.Errorf("quantity should be at most %.3f", MaxQuantity)
+	}
+
+	scaled := quantity * math.Pow10(precision)
+	if math.Abs(scaled-math.Round(scaled)) > 1e-6 {
+		return errors.New("quantity has more decimals than the product unit allows")
+	}
+
+	return nil
+}
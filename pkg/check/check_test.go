@@ -0,0 +1,86 @@
+package check
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestBarcodeCheckDigit(t *testing.T) {
+	tests := []struct {
+		digits string
+		want   int
+	}{
+		{"400638133393", 1}, // EAN-13
+		{"590123412345", 7}, // EAN-13
+		{"9638507", 4},      // EAN-8
+		{"03600029145", 2},  // UPC-A
+		{"003600029145", 2}, // the same UPC-A written as EAN-13, a leading zero does not change the digit
+		{"000000000000", 0}, // the sum is a multiple of ten
+		{"0000000", 0},      // EAN-8 of zeros
+		{"00000000001", 7},  // a single digit next to the check digit has the weight 3
+		{"00000000010", 9},  // the next one has the weight 1
+	}
+
+	for _, test := range tests {
+		if got := BarcodeCheckDigit(test.digits); got != test.want {
+			t.Errorf("BarcodeCheckDigit(%q) = %d, want %d", test.digits, got, test.want)
+		}
+	}
+}
+
+func TestValidateBarcode(t *testing.T) {
+	tests := []struct {
+		barcode string
+		valid   bool
+	}{
+		{"4006381333931", true},
+		{"4006381333932", false}, // wrong check digit
+		{"96385074", true},
+		{"96385075", false},
+		{"036000291452", true},
+		{"036000291453", false},
+		{"0036000291452", true},
+		{"0000000000000", true},
+		{"12345", true},         // numeric codes of other lengths have no check digit
+		{"400638133390", true},  // twelve digits are checked as UPC-A
+		{"400638133393", false}, // an EAN-13 without its check digit is not a valid UPC-A
+		{"ABC-123", true},
+		{"", false},
+		{strings.Repeat("1", 48), true},
+		{strings.Repeat("1", 49), false},
+		{"ABC\t123", false},
+		{"ÄBC", false},
+	}
+
+	for _, test := range tests {
+		if err := ValidateBarcode(test.barcode); (err == nil) != test.valid {
+			t.Errorf("ValidateBarcode(%q) = %v, want valid %v", test.barcode, err, test.valid)
+		}
+	}
+}
+
+func TestValidateQuantity(t *testing.T) {
+	tests := []struct {
+		quantity  float64
+		precision int
+		valid     bool
+	}{
+		{1, 0, true},
+		{1.5, 0, false},
+		{1.5, 1, true},
+		{0.125, 3, true},
+		{0, 3, false},
+		{-1, 3, false},
+		{MaxQuantity, 3, true},
+		{1e18, 0, false}, // past numeric(14, 3), the price would not fit in an amount either
+		{math.Inf(1), 0, false},
+		{math.NaN(), 0, false},
+	}
+
+	for _, test := range tests {
+		if err := ValidateQuantity(test.quantity, test.precision); (err == nil) != test.valid {
+			t.Errorf("ValidateQuantity(%g, %d) = %v, want valid %v", test.quantity, test.precision, err, test.valid)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package fiscal
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// File is a FiscalDriver for local testing. It writes every receipt with its result to a JSON file
+// named after the document, signs it with a hash of the receipt and numbers receipts in the order they come.
+type File struct {
+	dir string
+	mu  *sync.Mutex
+}
+
+func NewFile(dir string) File {
+	return File{
+		dir: dir,
+		mu:  &sync.Mutex{},
+	}
+}
+
+type fileReceipt struct {
+	Result
+	Receipt Receipt `json:"receipt"`
+}
+
+func (f File) Register(_ context.Context, receipt Receipt) (Result, error) {
+	if receipt.Type != TypeSale && receipt.Type != TypeRefund {
+		return Result{}, fmt.Errorf("unknown receipt type %q", receipt.Type)
+	}
+	if receipt.DocumentID == "" || strings.ContainsAny(receipt.DocumentID, `/\.`) {
+		return Result{}, fmt.Errorf("invalid document id %q", receipt.DocumentID)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	name := filepath.Join(f.dir, receipt.Type+"-"+receipt.DocumentID+".json")
+	if data, err := os.ReadFile(name); err == nil {
+		saved := fileReceipt{}
+		if err := json.Unmarshal(data, &saved); err != nil {
+			return Result{}, err
+		}
+		return saved.Result, nil
+	} else if !os.IsNotExist(err) {
+		return Result{}, err
+	}
+
+	if err := os.MkdirAll(f.dir, 0o755); err != nil {
+		return Result{}, err
+	}
+
+	files, err := filepath.Glob(filepath.Join(f.dir, "*.json"))
+	if err != nil {
+		return Result{}, err
+	}
+
+	body, err := json.Marshal(receipt)
+	if err != nil {
+		return Result{}, err
+	}
+	sum := sha256.Sum256(body)
+
+	result := Result{
+		Sign:   hex.EncodeToString(sum[:8]),
+		Number: fmt.Sprintf("%08d", len(files)+1),
+	}
+
+	data, err := json.MarshalIndent(fileReceipt{Result: result, Receipt: receipt}, "", "  ")
+	if err != nil {
+		return Result{}, err
+	}
+
+	// the file is written aside and renamed so a receipt is never read half written
+	tmp, err := os.CreateTemp(f.dir, ".receipt-*")
+	if err != nil {
+		return Result{}, err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return Result{}, err
+	}
+	if err := tmp.Close(); err != nil {
+		return Result{}, err
+	}
+
+	if err := os.Rename(tmp.Name(), name); err != nil {
+		return Result{}, err
+	}
+
+	return result, nil
+}
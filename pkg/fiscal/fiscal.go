@@ -0,0 +1,56 @@
+// Package fiscal registers sales and refunds with the fiscal module required by regulation behind
+// a FiscalDriver, so the module of a vendor can be replaced without touching the sale flow.
+package fiscal
+
+import (
+	"context"
+	"sell/pkg/money"
+	"time"
+)
+
+const (
+	TypeSale   = "sale"
+	TypeRefund = "refund"
+)
+
+// FiscalDriver sends receipts to a fiscal module.
+type FiscalDriver interface {
+	// Register registers the receipt and returns the fiscal sign and number the module gave it.
+	// A receipt may be sent again when its result was not saved, so registering the same Type and
+	// DocumentID twice must return the first result instead of registering a second receipt.
+	Register(ctx context.Context, receipt Receipt) (Result, error)
+}
+
+// Receipt is a sale or a refund as the fiscal module sees it. Amounts are in Currency,
+// Total is what the customer paid or got back and Tax is the part of it which is tax.
+type Receipt struct {
+	Type        string      `json:"type"`
+	DocumentID  string      `json:"document_id"`
+	SaleID      string      `json:"sale_id"`
+	BranchID    string      `json:"branch_id"`
+	CashierID   string      `json:"cashier_id"`
+	PaymentType string      `json:"payment_type"`
+	Currency    string      `json:"currency"`
+	TaxIncluded bool        `json:"tax_included"`
+	Lines       []Line      `json:"lines"`
+	Total       money.Money `json:"total"`
+	Tax         money.Money `json:"tax"`
+	// SaleFiscalSign is the sign of the refunded sale, it is empty for sales.
+	SaleFiscalSign string    `json:"sale_fiscal_sign,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+type Line struct {
+	ProductID string      `json:"product_id"`
+	Name      string      `json:"name"`
+	Quantity  float64     `json:"quantity"`
+	Price     money.Money `json:"price"`
+	TaxRate   money.Money `json:"tax_rate"`
+	Tax       money.Money `json:"tax"`
+}
+
+// Result is what the fiscal module gave a registered receipt.
+type Result struct {
+	Sign   string `json:"sign"`
+	Number string `json:"number"`
+}
@@ -0,0 +1,85 @@
+package label
+
+import (
+	"sell/pkg/barcode"
+	"unicode/utf8"
+)
+
+// Label is one price label: the product name, the price as it should be printed and the barcode, if any.
+type Label struct {
+	Name    string
+	Price   string
+	Barcode string
+}
+
+// Size of a label in millimetres.
+type Size struct {
+	Width  float64
+	Height float64
+}
+
+// layout holds positions inside a label in millimetres from its top left corner,
+// text positions are baselines.
+type layout struct {
+	pad                           float64
+	nameSize, priceSize, codeSize float64
+	nameY, priceY, codeY          float64
+	barsX, barsY                  float64
+	barsHeight, moduleWidth       float64
+	nameChars                     int
+	modules                       []bool
+}
+
+func newLayout(l Label, size Size) layout {
+	lt := layout{
+		pad:       clamp(size.Height*0.05, 1, 2),
+		nameSize:  clamp(size.Height*0.08, 2, 3.5),
+		priceSize: clamp(size.Height*0.2, 3, 9),
+		codeSize:  clamp(size.Height*0.08, 2, 3),
+	}
+	lt.nameY = lt.pad + lt.nameSize
+	lt.priceY = lt.nameY + lt.priceSize*1.1
+	lt.codeY = size.Height - lt.pad
+	lt.barsY = lt.priceY + 1.5
+	lt.barsHeight = lt.codeY - lt.codeSize - 0.5 - lt.barsY
+	// the average width of a character is a bit over half of the font size
+	lt.nameChars = int((size.Width - 2*lt.pad) / (lt.nameSize * 0.55))
+
+	if l.Barcode == "" || lt.barsHeight <= 0 {
+		return lt
+	}
+
+	modules, err := barcode.Encode(l.Barcode)
+	if err != nil {
+		return lt
+	}
+
+	lt.modules = modules
+	lt.moduleWidth = (size.Width - 2*lt.pad) / float64(len(modules)+2*barcode.QuietZone)
+	lt.barsX = lt.pad + barcode.QuietZone*lt.moduleWidth
+
+	return lt
+}
+
+// truncate shortens s to at most n characters, marking the cut with dots.
+func truncate(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	if n <= 3 {
+		return string([]rune(s)[:max(n, 0)])
+	}
+
+	return string([]rune(s)[:n-3]) + "..."
+}
+
+func clamp(v, min, max float64) float64 {
+	if v < min {
+		return min
+	}
+	if v > max {
+		return max
+	}
+
+	return v
+}
@@ -0,0 +1,178 @@
+package label
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sell/pkg/barcode"
+	"strings"
+)
+
+const (
+	pointsPerMM = 72 / 25.4
+
+	a4Width  = 210.0
+	a4Height = 297.0
+	margin   = 10.0
+)
+
+// ErrUnprintable is returned for text with characters the PDF fonts do not have.
+var ErrUnprintable = errors.New("text has characters outside of Windows-1252, which pdf labels can't print")
+
+// Printable checks that the text can be printed on a PDF label. The standard fonts cover only Windows-1252,
+// so Latin Uzbek names are printed, but Cyrillic ones are not and should be printed as SVG.
+func Printable(text string) error {
+	for _, r := range text {
+		if _, ok := winAnsi(r); !ok {
+			return ErrUnprintable
+		}
+	}
+
+	return nil
+}
+
+// PDF lays the labels out in a grid on A4 pages. Labels bigger than the printable area of A4
+// get a page of their own of the label size.
+// Text uses the standard Helvetica fonts, characters outside of Windows-1252 are printed as "?",
+// callers check the names with Printable first.
+func PDF(labels []Label, size Size) []byte {
+	page := Size{Width: a4Width, Height: a4Height}
+	columns := int((a4Width - 2*margin) / size.Width)
+	rows := int((a4Height - 2*margin) / size.Height)
+	left, top := margin, margin
+	if columns == 0 || rows == 0 {
+		page, columns, rows, left, top = size, 1, 1, 0, 0
+	}
+
+	contents := []string{}
+	for start := 0; start < len(labels) || start == 0; start += columns * rows {
+		sb := strings.Builder{}
+		for i := start; i < len(labels) && i < start+columns*rows; i++ {
+			n := i - start
+			x := left + float64(n%columns)*size.Width
+			y := top + float64(n/columns)*size.Height
+			writePDFLabel(&sb, labels[i], size, x, page.Height-y)
+		}
+		contents = append(contents, sb.String())
+	}
+
+	return writePDF(contents, page)
+}
+
+// writePDFLabel draws a label with its top left corner at x, top in millimetres from the bottom left of the page.
+func writePDFLabel(sb *strings.Builder, l Label, size Size, x, top float64) {
+	lt := newLayout(l, size)
+
+	fmt.Fprintf(sb, "0.8 G 0.2 w %.2f %.2f %.2f %.2f re S 0 G\n",
+		x*pointsPerMM, (top-size.Height)*pointsPerMM, size.Width*pointsPerMM, size.Height*pointsPerMM)
+	writePDFText(sb, "F1", lt.nameSize, x+lt.pad, top-lt.nameY, truncate(l.Name, lt.nameChars))
+	writePDFText(sb, "F2", lt.priceSize, x+lt.pad, top-lt.priceY, l.Price)
+
+	if lt.modules == nil {
+		return
+	}
+
+	for _, run := range barcode.Runs(lt.modules) {
+		fmt.Fprintf(sb, "%.3f %.3f %.3f %.3f re\n",
+			(x+lt.barsX+float64(run[0])*lt.moduleWidth)*pointsPerMM,
+			(top-lt.barsY-lt.barsHeight)*pointsPerMM,
+			float64(run[1])*lt.moduleWidth*pointsPerMM,
+			lt.barsHeight*pointsPerMM)
+	}
+	sb.WriteString("f\n")
+
+	// Helvetica digits are 0.556 of the font size wide, the code is centred under the bars
+	codeWidth := float64(len(l.Barcode)) * lt.codeSize * 0.556
+	writePDFText(sb, "F1", lt.codeSize, x+(size.Width-codeWidth)/2, top-lt.codeY, l.Barcode)
+}
+
+func writePDFText(sb *strings.Builder, font string, size, x, y float64, text string) {
+	fmt.Fprintf(sb, "BT /%s %.2f Tf %.2f %.2f Td (%s) Tj ET\n",
+		font, size*pointsPerMM, x*pointsPerMM, y*pointsPerMM, pdfString(text))
+}
+
+// pdfString converts text to Windows-1252 and escapes it for a PDF string literal.
+func pdfString(text string) string {
+	sb := strings.Builder{}
+	for _, r := range text {
+		b, ok := winAnsi(r)
+		switch {
+		case !ok:
+			sb.WriteByte('?')
+		case b == '\\' || b == '(' || b == ')':
+			sb.WriteByte('\\')
+			sb.WriteByte(b)
+		default:
+			sb.WriteByte(b)
+		}
+	}
+
+	return sb.String()
+}
+
+// winAnsi returns the Windows-1252 byte of r, quotes, dashes and the euro sign outside of Latin-1 are mapped too.
+func winAnsi(r rune) (byte, bool) {
+	switch {
+	case r >= ' ' && r < 0x7f, r >= 0xa0 && r <= 0xff:
+		return byte(r), true
+	case r == '‘' || r == 'ʻ':
+		// Uzbek o‘ and g‘ are written with a turned comma or a left quote
+		return 0x91, true
+	case r == '’' || r == 'ʼ':
+		return 0x92, true
+	case r == '“':
+		return 0x93, true
+	case r == '”':
+		return 0x94, true
+	case r == '–':
+		return 0x96, true
+	case r == '—':
+		return 0x97, true
+	case r == '€':
+		return 0x80, true
+	}
+
+	return 0, false
+}
+
+// writePDF writes a document with one page per content stream.
+func writePDF(contents []string, page Size) []byte {
+	var (
+		buf     = bytes.Buffer{}
+		offsets = []int{}
+	)
+
+	object := func(body string) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	buf.WriteString("%PDF-1.4\n")
+
+	// objects 1-4 are the catalog, the page tree and the fonts, every page adds a page and a content object
+	kids := make([]string, len(contents))
+	for i := range contents {
+		kids[i] = fmt.Sprintf("%d 0 R", 5+2*i)
+	}
+
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(contents)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+
+	for i, content := range contents {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.2f %.2f] "+
+			"/Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			page.Width*pointsPerMM, page.Height*pointsPerMM, 6+2*i))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content), content))
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	return buf.Bytes()
+}
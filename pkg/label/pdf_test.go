@@ -0,0 +1,114 @@
+package label
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"testing"
+)
+
+func TestPrintable(t *testing.T) {
+	tests := []struct {
+		text string
+		err  error
+	}{
+		{"Milk 3.2%", nil},
+		{"Qo‘y go‘shti", nil},
+		{"Oʻzbekiston choyi – 100 g", nil},
+		{"Café (500 g)", nil},
+		{"Молоко", ErrUnprintable},
+		{"Sut – молоко", ErrUnprintable},
+		{"Ўзбек", ErrUnprintable},
+	}
+
+	for _, test := range tests {
+		if err := Printable(test.text); !errors.Is(err, test.err) {
+			t.Errorf("Printable(%q) = %v, want %v", test.text, err, test.err)
+		}
+	}
+}
+
+func TestPDFString(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"Milk (1 l)", `Milk \(1 l\)`},
+		{`a\b`, `a\\b`},
+		{"Qo‘y", "Qo\x91y"},
+		{"Go’sht", "Go\x92sht"},
+		{"Café", "Caf\xe9"},
+		{"Сут", "???"},
+	}
+
+	for _, test := range tests {
+		if got := pdfString(test.text); got != test.want {
+			t.Errorf("pdfString(%q) = %q, want %q", test.text, got, test.want)
+		}
+	}
+}
+
+func TestPDF(t *testing.T) {
+	labels := make([]Label, 30)
+	for i := range labels {
+		labels[i] = Label{Name: fmt.Sprintf("Product %d", i), Price: "12 000", Barcode: "4006381333931"}
+	}
+
+	// 58x40 labels fit three in a row and six in a column of an A4 page
+	doc := PDF(labels, Size{Width: 58, Height: 40})
+	if !bytes.HasPrefix(doc, []byte("%PDF-1.4\n")) || !bytes.HasSuffix(doc, []byte("%%EOF\n")) {
+		t.Fatal("document has no PDF header or trailer")
+	}
+	if pages := bytes.Count(doc, []byte("/Type /Page ")); pages != 2 {
+		t.Errorf("30 labels are laid out on %d pages, want 2", pages)
+	}
+	for _, name := range []string{"(Product 0)", "(Product 20)", "(Product 29)", "(4006381333931)"} {
+		if !bytes.Contains(doc, []byte(name)) {
+			t.Errorf("document has no %s", name)
+		}
+	}
+
+	// every cross reference entry points to the start of its object
+	xref := regexp.MustCompile(`(?m)^(\d{10}) 00000 n $`).FindAllSubmatch(doc, -1)
+	if len(xref) != 4+2*2 {
+		t.Fatalf("document has %d objects, want 8", len(xref))
+	}
+	for i, entry := range xref {
+		offset, err := strconv.Atoi(string(entry[1]))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if prefix := fmt.Sprintf("%d 0 obj\n", i+1); !bytes.HasPrefix(doc[offset:], []byte(prefix)) {
+			t.Errorf("object %d is not at offset %d", i+1, offset)
+		}
+	}
+}
+
+func TestPDFLargeLabel(t *testing.T) {
+	doc := PDF([]Label{{Name: "Poster", Price: "1 000"}}, Size{Width: 200, Height: 290})
+	if !bytes.Contains(doc, []byte("/MediaBox [0 0 566.93 822.05]")) {
+		t.Error("a label bigger than the printable area of A4 does not get a page of its size")
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		s    string
+		n    int
+		want string
+	}{
+		{"Milk", 10, "Milk"},
+		{"Chocolate bar", 8, "Choco..."},
+		{"Qo‘y go‘shti", 6, "Qo‘..."},
+		{"Milk", 2, "Mi"},
+		{"Milk", -1, ""},
+	}
+
+	for _, test := range tests {
+		if got := truncate(test.s, test.n); got != test.want {
+			t.Errorf("truncate(%q, %d) = %q, want %q", test.s, test.n, got, test.want)
+		}
+	}
+}
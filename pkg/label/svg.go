@@ -0,0 +1,41 @@
+package label
+
+import (
+	"fmt"
+	"sell/pkg/barcode"
+	"strings"
+)
+
+// SVG draws the labels one under another, the image is as wide as a label so it fits label printer rolls.
+func SVG(labels []Label, size Size) string {
+	height := size.Height * float64(len(labels))
+
+	sb := strings.Builder{}
+	fmt.Fprintf(&sb, `<svg xmlns="http://www.w3.org/2000/svg" width="%gmm" height="%gmm" viewBox="0 0 %g %g" font-family="sans-serif">`,
+		size.Width, height, size.Width, height)
+	fmt.Fprintf(&sb, `<rect width="100%%" height="100%%" fill="#fff"/>`)
+
+	for i, l := range labels {
+		lt := newLayout(l, size)
+
+		fmt.Fprintf(&sb, `<g transform="translate(0 %g)">`, size.Height*float64(i))
+		fmt.Fprintf(&sb, `<rect x="0.1" y="0.1" width="%g" height="%g" fill="none" stroke="#ccc" stroke-width="0.2"/>`,
+			size.Width-0.2, size.Height-0.2)
+		fmt.Fprintf(&sb, `<text x="%g" y="%.3f" font-size="%.3f">%s</text>`,
+			lt.pad, lt.nameY, lt.nameSize, barcode.EscapeXML(truncate(l.Name, lt.nameChars)))
+		fmt.Fprintf(&sb, `<text x="%g" y="%.3f" font-size="%.3f" font-weight="bold">%s</text>`,
+			lt.pad, lt.priceY, lt.priceSize, barcode.EscapeXML(l.Price))
+
+		if lt.modules != nil {
+			sb.WriteString(barcode.Bars(lt.modules, lt.barsX, lt.barsY, lt.moduleWidth, lt.barsHeight))
+			fmt.Fprintf(&sb, `<text x="%g" y="%.3f" font-size="%.3f" font-family="monospace" text-anchor="middle">%s</text>`,
+				size.Width/2, lt.codeY, lt.codeSize, barcode.EscapeXML(l.Barcode))
+		}
+
+		sb.WriteString(`</g>`)
+	}
+
+	sb.WriteString(`</svg>`)
+
+	return sb.String()
+}
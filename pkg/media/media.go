@@ -0,0 +1,16 @@
+// Package media stores uploaded files like product images behind a Storage
+// so the local disk can be replaced by another backend.
+package media
+
+import (
+	"context"
+	"io"
+)
+
+// Storage saves files under keys like products/<id>/<name>.jpg.
+type Storage interface {
+	// Save writes the file and returns the URL it is served from.
+	Save(ctx context.Context, key, contentType string, r io.Reader) (string, error)
+	// Delete removes the file, a missing file is not an error.
+	Delete(ctx context.Context, key string) error
+}
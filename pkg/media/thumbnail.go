@@ -0,0 +1,52 @@
+package media
+
+import (
+	"image"
+	"image/color"
+)
+
+// Thumbnail scales img down to fit into size x size pixels keeping its proportions, every pixel
+// of the thumbnail is the average of the pixels it covers. Transparent parts become white.
+// Images which already fit are only flattened.
+func Thumbnail(img image.Image, size int) *image.RGBA {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+
+	thumbWidth, thumbHeight := width, height
+	if width > size || height > size {
+		if width >= height {
+			thumbWidth, thumbHeight = size, max(1, height*size/width)
+		} else {
+			thumbWidth, thumbHeight = max(1, width*size/height), size
+		}
+	}
+
+	thumb := image.NewRGBA(image.Rect(0, 0, thumbWidth, thumbHeight))
+	for ty := 0; ty < thumbHeight; ty++ {
+		y0, y1 := bounds.Min.Y+ty*height/thumbHeight, bounds.Min.Y+(ty+1)*height/thumbHeight
+		for tx := 0; tx < thumbWidth; tx++ {
+			x0, x1 := bounds.Min.X+tx*width/thumbWidth, bounds.Min.X+(tx+1)*width/thumbWidth
+
+			var r, g, b, n uint64
+			for y := y0; y < y1; y++ {
+				for x := x0; x < x1; x++ {
+					cr, cg, cb, ca := img.At(x, y).RGBA()
+					// premultiplied colors over a white background
+					r += uint64(cr + 0xffff - ca)
+					g += uint64(cg + 0xffff - ca)
+					b += uint64(cb + 0xffff - ca)
+					n++
+				}
+			}
+
+			thumb.SetRGBA(tx, ty, color.RGBA{
+				R: uint8(r / n >> 8),
+				G: uint8(g / n >> 8),
+				B: uint8(b / n >> 8),
+				A: 0xff,
+			})
+		}
+	}
+
+	return thumb
+}
@@ -0,0 +1,140 @@
+package money
+
+import (
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v5/pgtype"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// parseDecimal reads a decimal with at most scale digits after the point as a whole number of 10^-scale.
+func parseDecimal(s string, scale int) (int64, error) {
+	text := strings.TrimSpace(s)
+
+	negative := strings.HasPrefix(text, "-")
+	text = strings.TrimPrefix(strings.TrimPrefix(text, "-"), "+")
+
+	units, fraction, hasPoint := strings.Cut(text, ".")
+	if units == "" || hasPoint && fraction == "" || len(fraction) > scale || !isDigits(units) || !isDigits(fraction) {
+		return 0, fmt.Errorf("%w %q, should be a number with at most %d decimal places", ErrInvalid, s, scale)
+	}
+
+	fraction += strings.Repeat("0", scale-len(fraction))
+	value, err := strconv.ParseInt(units+fraction, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w %q: %s", ErrInvalid, s, err.Error())
+	}
+
+	if negative {
+		value = -value
+	}
+	return value, nil
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// formatDecimal writes a whole number of 10^-scale with scale digits after the point.
+func formatDecimal(value int64, scale int) string {
+	sign, digits := "", strconv.FormatUint(uint64(value), 10)
+	if value < 0 {
+		sign, digits = "-", strconv.FormatUint(-uint64(value), 10)
+	}
+	if len(digits) <= scale {
+		digits = strings.Repeat("0", scale-len(digits)+1) + digits
+	}
+	return sign + digits[:len(digits)-scale] + "." + digits[len(digits)-scale:]
+}
+
+// unmarshalDecimal reads a JSON number or a string holding a number, null leaves the value unchanged.
+func unmarshalDecimal(data []byte, scale int, value *int64) error {
+	text := string(data)
+	if text == "null" {
+		return nil
+	}
+	if unquoted, err := strconv.Unquote(text); err == nil {
+		text = unquoted
+	}
+
+	parsed, err := parseDecimal(text, scale)
+	if err != nil {
+		return err
+	}
+	*value = parsed
+	return nil
+}
+
+// scanDecimal reads a numeric as a whole number of 10^-scale, further digits are rounded half away from zero.
+func scanDecimal(v pgtype.Numeric, scale int) (int64, error) {
+	if !v.Valid {
+		return 0, errors.New("can not scan NULL into a decimal")
+	}
+	if v.NaN || v.InfinityModifier != pgtype.Finite {
+		return 0, fmt.Errorf("%w: not a finite number", ErrInvalid)
+	}
+	if v.Int == nil {
+		return 0, nil
+	}
+
+	value := new(big.Int).Set(v.Int)
+	exp := int(v.Exp) + scale
+	if exp >= 0 {
+		value.Mul(value, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(exp)), nil))
+	} else {
+		value = divRound(value, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(-exp)), nil))
+	}
+
+	if !value.IsInt64() {
+		return 0, fmt.Errorf("%w: %s is out of range", ErrInvalid, v.Int.String())
+	}
+	return value.Int64(), nil
+}
+
+// divRound divides by a positive divisor rounding half away from zero.
+func divRound(value, divisor *big.Int) *big.Int {
+	quotient, remainder := new(big.Int).QuoRem(value, divisor, new(big.Int))
+	if remainder.Abs(remainder).Lsh(remainder, 1).Cmp(divisor) >= 0 {
+		quotient.Add(quotient, big.NewInt(int64(value.Sign())))
+	}
+	return quotient
+}
+
+// mulDiv returns value * numerator / denominator rounded half away from zero. A zero denominator or a result
+// which does not fit in int64 is ErrRange, a wrapped amount would be silently wrong.
+func mulDiv(value, numerator, denominator int64) (int64, error) {
+	if denominator == 0 {
+		return 0, fmt.Errorf("%w: %d * %d divided by zero", ErrRange, value, numerator)
+	}
+
+	product := new(big.Int).Mul(big.NewInt(value), big.NewInt(numerator))
+	d := big.NewInt(denominator)
+	if d.Sign() < 0 {
+		product.Neg(product)
+		d.Neg(d)
+	}
+
+	result := divRound(product, d)
+	if !result.IsInt64() {
+		return 0, fmt.Errorf("%w: %d * %d / %d = %s", ErrRange, value, numerator, denominator, result.String())
+	}
+	return result.Int64(), nil
+}
+
+// quantityUnits returns quantity in thousandths, the precision products are counted in. NaN, infinities
+// and quantities which do not fit in int64 are ErrRange.
+func quantityUnits(quantity float64) (int64, error) {
+	scaled := math.Round(quantity * quantityScale)
+	if math.IsNaN(scaled) || scaled < math.MinInt64 || scaled >= math.MaxInt64 {
+		return 0, fmt.Errorf("%w: quantity %g", ErrRange, quantity)
+	}
+	return int64(scaled), nil
+}
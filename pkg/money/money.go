@@ -0,0 +1,157 @@
+// Package money keeps amounts of money exact. An amount is a whole number of hundredths of the currency
+// unit, it is read and written as a decimal with at most two digits after the point and stored in
+// numeric(18, 2) columns.
+//
+// Amounts are never rounded on input, "10.005" is an error. Results of multiplying by a quantity or
+// a percent are rounded to the hundredth half away from zero: 0.125 becomes 0.13 and -0.125 becomes -0.13.
+package money
+
+import (
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v5/pgtype"
+	"math"
+	"math/big"
+)
+
+// Money is an amount in hundredths of the currency unit.
+type Money int64
+
+const (
+	// Scale is the number of digits after the decimal point.
+	Scale = 2
+	// Unit is one currency unit.
+	Unit Money = 100
+	// Max is the largest amount a numeric(18, 2) column holds.
+	Max Money = 999_999_999_999_999_999
+)
+
+// quantityScale is the precision of product quantities, numeric(14, 3) in the database.
+const quantityScale = 1000
+
+var (
+	// ErrInvalid is returned for text which is not an amount of money or a rate.
+	ErrInvalid = errors.New("invalid number")
+	// ErrRange is returned for results of arithmetic which do not fit in an amount and for quantities which
+	// are not finite numbers.
+	ErrRange = errors.New("number is out of range")
+)
+
+// New returns an amount of whole currency units.
+func New(units int64) Money {
+	return Money(units) * Unit
+}
+
+// Parse reads a decimal amount like "1250", "1250.5" or "-0.75".
+func Parse(s string) (Money, error) {
+	amount, err := parseDecimal(s, Scale)
+	return Money(amount), err
+}
+
+// String formats the amount with two decimal places, "1250.50".
+func (m Money) String() string {
+	return formatDecimal(int64(m), Scale)
+}
+
+// Units returns the whole currency units of the amount, the hundredths are dropped.
+func (m Money) Units() int64 {
+	return int64(m / Unit)
+}
+
+// Mul returns the price of quantity items costing m each. The quantity is taken with the
+// three decimal places products are counted in.
+func (m Money) Mul(quantity float64) (Money, error) {
+	q, err := quantityUnits(quantity)
+	if err != nil {
+		return 0, err
+	}
+	return m.MulDiv(q, quantityScale)
+}
+
+// Div returns the price of one item when quantity items cost m.
+func (m Money) Div(quantity float64) (Money, error) {
+	q, err := quantityUnits(quantity)
+	if err != nil || q == 0 {
+		return 0, err
+	}
+	return m.MulDiv(quantityScale, q)
+}
+
+// Share returns the part of the amount which falls on part of quantity items, like the price of 2 of 3 items
+// sold for m together. The quantities are taken with the three decimal places products are counted in.
+func (m Money) Share(part, quantity float64) (Money, error) {
+	q, err := quantityUnits(quantity)
+	if err != nil || q == 0 {
+		return 0, err
+	}
+	p, err := quantityUnits(part)
+	if err != nil {
+		return 0, err
+	}
+	return m.MulDiv(p, q)
+}
+
+// Percent returns percent of the amount. The percent is written like money with
+// two decimal places, so 2.5 percent is Parse("2.5").
+func (m Money) Percent(percent Money) (Money, error) {
+	return m.MulDiv(int64(percent), int64(100*Unit))
+}
+
+// MulDiv returns m * numerator / denominator rounded half away from zero, ErrRange when it is past Max
+// and would not fit in a numeric(18, 2) column.
+func (m Money) MulDiv(numerator, denominator int64) (Money, error) {
+	amount, err := mulDiv(int64(m), numerator, denominator)
+	if err != nil {
+		return 0, err
+	}
+	if amount > int64(Max) || amount < -int64(Max) {
+		return 0, fmt.Errorf("%w: %s is past %s", ErrRange, Money(amount), Max)
+	}
+	return Money(amount), nil
+}
+
+// MarshalJSON writes the amount as a JSON number with two decimal places.
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalJSON reads a JSON number or a string holding a number.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	return unmarshalDecimal(data, Scale, (*int64)(m))
+}
+
+// ScanNumeric reads a numeric column. Values with more than two decimal places are rounded half away from zero.
+func (m *Money) ScanNumeric(v pgtype.Numeric) error {
+	amount, err := scanDecimal(v, Scale)
+	if err != nil {
+		return err
+	}
+	*m = Money(amount)
+	return nil
+}
+
+// NumericValue writes the amount to a numeric column.
+func (m Money) NumericValue() (pgtype.Numeric, error) {
+	return pgtype.Numeric{Int: big.NewInt(int64(m)), Exp: -Scale, Valid: true}, nil
+}
+
+// ScanInt64 reads an integer column, an integer is a number of whole units.
+func (m *Money) ScanInt64(v pgtype.Int8) error {
+	if !v.Valid {
+		return errors.New("can not scan NULL into a decimal")
+	}
+	if v.Int64 > math.MaxInt64/int64(Unit) || v.Int64 < math.MinInt64/int64(Unit) {
+		return fmt.Errorf("%w: %d is out of range", ErrInvalid, v.Int64)
+	}
+
+	*m = New(v.Int64)
+	return nil
+}
+
+// Int64Value writes the amount to an integer column, which is only possible for whole units.
+func (m Money) Int64Value() (pgtype.Int8, error) {
+	if m%Unit != 0 {
+		return pgtype.Int8{}, fmt.Errorf("%s can not be written as a whole number", m)
+	}
+	return pgtype.Int8{Int64: m.Units(), Valid: true}, nil
+}
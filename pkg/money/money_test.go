@@ -0,0 +1,312 @@
+package money
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		text  string
+		want  Money
+		valid bool
+	}{
+		{"1250", 1250 * Unit, true},
+		{"1250.5", 125050, true},
+		{"1250.50", 125050, true},
+		{"0.01", 1, true},
+		{"-0.75", -75, true},
+		{"+3", 3 * Unit, true},
+		{" 12 ", 12 * Unit, true},
+		{"-0", 0, true},
+		{"9999999999999999.99", Max, true},
+		{"10.005", 0, false}, // amounts are never rounded on input
+		{"", 0, false},
+		{"-", 0, false},
+		{".5", 0, false},
+		{"5.", 0, false},
+		{"1,5", 0, false},
+		{"1e3", 0, false},
+		{"abc", 0, false},
+		{"99999999999999999999", 0, false},
+	}
+
+	for _, test := range tests {
+		got, err := Parse(test.text)
+		if (err == nil) != test.valid || got != test.want {
+			t.Errorf("Parse(%q) = %d, %v, want %d, valid %v", test.text, got, err, test.want, test.valid)
+		}
+		if err != nil && !errors.Is(err, ErrInvalid) {
+			t.Errorf("Parse(%q) error %v is not ErrInvalid", test.text, err)
+		}
+	}
+}
+
+func TestString(t *testing.T) {
+	tests := []struct {
+		amount Money
+		want   string
+	}{
+		{0, "0.00"},
+		{1, "0.01"},
+		{-1, "-0.01"},
+		{50, "0.50"},
+		{-75, "-0.75"},
+		{125050, "1250.50"},
+		{New(-3), "-3.00"},
+		{Max, "9999999999999999.99"},
+		{math.MinInt64, "-92233720368547758.08"},
+	}
+
+	for _, test := range tests {
+		if got := test.amount.String(); got != test.want {
+			t.Errorf("Money(%d).String() = %q, want %q", int64(test.amount), got, test.want)
+		}
+		if test.amount == math.MinInt64 {
+			continue
+		}
+		if parsed, err := Parse(test.want); err != nil || parsed != test.amount {
+			t.Errorf("Parse(%q) = %d, %v, want %d", test.want, parsed, err, test.amount)
+		}
+	}
+}
+
+func TestJSON(t *testing.T) {
+	type line struct {
+		Price Money  `json:"price"`
+		Total *Money `json:"total"`
+	}
+
+	for _, amount := range []Money{0, 1, -1, 125050, -75, Max} {
+		data, err := json.Marshal(line{Price: amount, Total: &amount})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got line
+		if err := json.Unmarshal(data, &got); err != nil {
+			t.Fatalf("json.Unmarshal(%s): %v", data, err)
+		}
+		if got.Price != amount || got.Total == nil || *got.Total != amount {
+			t.Errorf("%s is read back as %d, want %d", data, got.Price, amount)
+		}
+	}
+
+	tests := []struct {
+		data  string
+		want  Money
+		valid bool
+	}{
+		{`{"price": 12.5}`, 1250, true},
+		{`{"price": "12.5"}`, 1250, true},
+		{`{"price": -0.01}`, -1, true},
+		{`{"price": null}`, 0, true},
+		{`{"price": 12.345}`, 0, false},
+		{`{"price": 1e2}`, 0, false},
+		{`{"price": "twelve"}`, 0, false},
+	}
+
+	for _, test := range tests {
+		var got line
+		err := json.Unmarshal([]byte(test.data), &got)
+		if (err == nil) != test.valid || got.Price != test.want {
+			t.Errorf("json.Unmarshal(%s) = %d, %v, want %d, valid %v", test.data, got.Price, err, test.want, test.valid)
+		}
+	}
+}
+
+func TestMul(t *testing.T) {
+	tests := []struct {
+		price    Money
+		quantity float64
+		want     Money
+	}{
+		{12000 * Unit, 2, 24000 * Unit},
+		{1999, 1.5, 2999},   // 29.985 is rounded up
+		{1999, -1.5, -2999}, // and a negative half away from zero
+		{-1999, 1.5, -2999}, // whatever side the sign is on
+		{999, 0.333, 333},   // 3.32667
+		{100, 0.0005, 0},    // 0.0005 is taken as 0.001, and 0.001 of 1.00 rounds to 0.00
+		{1000, 0.0005, 1},   // 0.0005 is 0.001 and 0.001 of 10.00 is 0.01
+		{25, 0.5, 13},       // 0.125 becomes 0.13
+		{-25, 0.5, -13},     // and -0.125 becomes -0.13
+		{15, 1.0 / 3, 5},    // 0.333 of 0.15 is 0.04995
+		{Max, 1, Max},
+		{0, 2.5, 0},
+	}
+
+	for _, test := range tests {
+		if got, err := test.price.Mul(test.quantity); err != nil || got != test.want {
+			t.Errorf("%s.Mul(%g) = %s, %v, want %s", test.price, test.quantity, got, err, test.want)
+		}
+	}
+}
+
+func TestDiv(t *testing.T) {
+	tests := []struct {
+		total    Money
+		quantity float64
+		want     Money
+	}{
+		{24000 * Unit, 2, 12000 * Unit},
+		{1000, 3, 333},
+		{2000, 3, 667},
+		{-2000, 3, -667},
+		{2000, -3, -667},
+		{25, 2, 13},
+		{-25, 2, -13},
+		{1000, 0.25, 4000},
+		{1000, 0, 0},
+		{1000, 0.0004, 0}, // less than the smallest quantity is no quantity
+	}
+
+	for _, test := range tests {
+		if got, err := test.total.Div(test.quantity); err != nil || got != test.want {
+			t.Errorf("%s.Div(%g) = %s, %v, want %s", test.total, test.quantity, got, err, test.want)
+		}
+	}
+}
+
+func TestShare(t *testing.T) {
+	tests := []struct {
+		total          Money
+		part, quantity float64
+		want           Money
+	}{
+		{1000, 2, 3, 667},
+		{1000, 1, 3, 333},
+		{-1000, 2, 3, -667},
+		{1000, 3, 3, 1000},
+		{25, 1, 2, 13},
+		{-25, 1, 2, -13},
+		{1000, 0.5, 1.5, 333},
+		{1000, 1, 0, 0},
+	}
+
+	for _, test := range tests {
+		if got, err := test.total.Share(test.part, test.quantity); err != nil || got != test.want {
+			t.Errorf("%s.Share(%g, %g) = %s, %v, want %s", test.total, test.part, test.quantity, got, err, test.want)
+		}
+	}
+}
+
+func TestPercent(t *testing.T) {
+	tests := []struct {
+		amount  Money
+		percent string
+		want    Money
+	}{
+		{10000 * Unit, "12", 1200 * Unit},
+		{1000, "2.5", 25},
+		{50, "25", 13},   // 0.125 becomes 0.13
+		{-50, "25", -13}, // -0.125 becomes -0.13
+		{50, "-25", -13},
+		{1999, "15", 300}, // 2.9985
+		{1999, "0.01", 0},
+		{1000, "100", 1000},
+		{1000, "0", 0},
+	}
+
+	for _, test := range tests {
+		percent, err := Parse(test.percent)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, err := test.amount.Percent(percent); err != nil || got != test.want {
+			t.Errorf("%s.Percent(%s) = %s, %v, want %s", test.amount, test.percent, got, err, test.want)
+		}
+	}
+}
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		amount Money
+		rate   string
+		want   Money
+	}{
+		{New(10), "12650", New(126500)},
+		{1, "12650.35", 12650},    // 126.5035
+		{199, "0.5", 100},         // 0.995 becomes 1.00
+		{-199, "0.5", -100},       // -0.995 becomes -1.00
+		{New(100), "0.000079", 1}, // 0.0079
+		{New(100), "0.00005", 1},  // 0.005 becomes 0.01
+		{New(-100), "0.00005", -1},
+		{New(100), "0.000049", 0},
+		{New(5), "1", New(5)},
+	}
+
+	for _, test := range tests {
+		rate, err := ParseRate(test.rate)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, err := test.amount.Convert(rate); err != nil || got != test.want {
+			t.Errorf("%s.Convert(%s) = %s, %v, want %s", test.amount, test.rate, got, err, test.want)
+		}
+	}
+}
+
+func TestRate(t *testing.T) {
+	tests := []struct {
+		text  string
+		want  string
+		valid bool
+	}{
+		{"12650", "12650", true},
+		{"12650.350000", "12650.35", true},
+		{"0.000001", "0.000001", true},
+		{"-1.5", "-1.5", true},
+		{"1.0000001", "", false},
+		{"", "", false},
+	}
+
+	for _, test := range tests {
+		rate, err := ParseRate(test.text)
+		if (err == nil) != test.valid {
+			t.Errorf("ParseRate(%q) = %v, want valid %v", test.text, err, test.valid)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if got := rate.String(); got != test.want {
+			t.Errorf("ParseRate(%q).String() = %q, want %q", test.text, got, test.want)
+		}
+
+		data, err := json.Marshal(rate)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var back Rate
+		if err := json.Unmarshal(data, &back); err != nil || back != rate {
+			t.Errorf("rate %s is read back from %s as %s, %v", rate, data, back, err)
+		}
+	}
+}
+
+func TestOutOfRange(t *testing.T) {
+	tests := []struct {
+		name string
+		do   func() (Money, error)
+	}{
+		{"MulDiv past int64", func() (Money, error) { return Money(math.MaxInt64).MulDiv(3, 2) }},
+		{"MulDiv by zero", func() (Money, error) { return Unit.MulDiv(1, 0) }},
+		{"Mul by a huge quantity", func() (Money, error) { return Unit.Mul(1e18) }},
+		{"Mul past int64", func() (Money, error) { return Max.Mul(2) }},
+		{"Mul by NaN", func() (Money, error) { return Unit.Mul(math.NaN()) }},
+		{"Mul by infinity", func() (Money, error) { return Unit.Mul(math.Inf(1)) }},
+		{"Div by NaN", func() (Money, error) { return Unit.Div(math.NaN()) }},
+		{"Div by negative infinity", func() (Money, error) { return Unit.Div(math.Inf(-1)) }},
+		{"Share of infinity", func() (Money, error) { return Unit.Share(math.Inf(1), 3) }},
+		{"Share of NaN items", func() (Money, error) { return Unit.Share(1, math.NaN()) }},
+		{"Percent past int64", func() (Money, error) { return Max.Percent(New(200)) }},
+	}
+
+	for _, test := range tests {
+		got, err := test.do()
+		if !errors.Is(err, ErrRange) {
+			t.Errorf("%s = %s, %v, want ErrRange, the amount must not wrap", test.name, got, err)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package money
+
+import (
+	"github.com/jackc/pgx/v5/pgtype"
+	"math/big"
+	"strings"
+)
+
+// Rate is an exchange rate: the amount of the base currency one unit of another currency costs,
+// in millionths. It is stored in numeric(18, 6) columns.
+type Rate int64
+
+const (
+	// RateScale is the number of digits after the decimal point of a rate.
+	RateScale = 6
+	// OneRate is the rate of the base currency to itself.
+	OneRate Rate = 1_000_000
+)
+
+// ParseRate reads a decimal rate like "12650" or "12650.35".
+func ParseRate(s string) (Rate, error) {
+	rate, err := parseDecimal(s, RateScale)
+	return Rate(rate), err
+}
+
+// String formats the rate without trailing zeros, "12650.35".
+func (r Rate) String() string {
+	return strings.TrimSuffix(strings.TrimRight(formatDecimal(int64(r), RateScale), "0"), ".")
+}
+
+// Convert returns the amount in the base currency, rounded to the hundredth half away from zero.
+func (m Money) Convert(rate Rate) (Money, error) {
+	return m.MulDiv(int64(rate), int64(OneRate))
+}
+
+// MarshalJSON writes the rate as a JSON number.
+func (r Rate) MarshalJSON() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+// UnmarshalJSON reads a JSON number or a string holding a number.
+func (r *Rate) UnmarshalJSON(data []byte) error {
+	return unmarshalDecimal(data, RateScale, (*int64)(r))
+}
+
+// ScanNumeric reads a numeric column.
+func (r *Rate) ScanNumeric(v pgtype.Numeric) error {
+	rate, err := scanDecimal(v, RateScale)
+	if err != nil {
+		return err
+	}
+	*r = Rate(rate)
+	return nil
+}
+
+// NumericValue writes the rate to a numeric column.
+func (r Rate) NumericValue() (pgtype.Numeric, error) {
+	return pgtype.Numeric{Int: big.NewInt(int64(r)), Exp: -RateScale, Valid: true}, nil
+}
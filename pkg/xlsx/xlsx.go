@@ -0,0 +1,302 @@
+// Package xlsx reads the first sheet of an Office Open XML workbook as text
+// and writes plain one sheet workbooks. Formulas and dates are not interpreted, of the styles
+// only number formats padding with zeros are, so codes like barcodes keep their leading zeros.
+package xlsx
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// maxSheetSize limits the unpacked size of a sheet so a small archive can not exhaust memory.
+const maxSheetSize = 64 << 20
+
+// maxRows and maxColumns are the sheet limits of spreadsheet applications.
+const (
+	maxRows    = 1 << 20
+	maxColumns = 1 << 14
+)
+
+type workbook struct {
+	Sheets []struct {
+		ID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+type relationships struct {
+	Relationships []struct {
+		ID     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+type richText struct {
+	T    string `xml:"t"`
+	Runs []struct {
+		T string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (t richText) String() string {
+	if len(t.Runs) == 0 {
+		return t.T
+	}
+
+	text := strings.Builder{}
+	for _, run := range t.Runs {
+		text.WriteString(run.T)
+	}
+	return text.String()
+}
+
+type sharedStrings struct {
+	Items []richText `xml:"si"`
+}
+
+type styles struct {
+	NumFmts []struct {
+		ID   int    `xml:"numFmtId,attr"`
+		Code string `xml:"formatCode,attr"`
+	} `xml:"numFmts>numFmt"`
+	CellXfs []struct {
+		NumFmtID int `xml:"numFmtId,attr"`
+	} `xml:"cellXfs>xf"`
+}
+
+// zeroWidths returns the width of the zero padding format of every cell style, 0 for other formats.
+func (s styles) zeroWidths() []int {
+	formats := map[int]int{}
+	for _, format := range s.NumFmts {
+		if code := strings.Trim(format.Code, `"`); code != "" && strings.Trim(code, "0") == "" {
+			formats[format.ID] = len(code)
+		}
+	}
+
+	widths := make([]int, len(s.CellXfs))
+	for i, xf := range s.CellXfs {
+		widths[i] = formats[xf.NumFmtID]
+	}
+	return widths
+}
+
+type worksheet struct {
+	Rows []struct {
+		Number int `xml:"r,attr"`
+		Cells  []struct {
+			Ref    string    `xml:"r,attr"`
+			Type   string    `xml:"t,attr"`
+			Style  int       `xml:"s,attr"`
+			Value  string    `xml:"v"`
+			Inline *richText `xml:"is"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+// Read returns the cells of the first sheet row by row, rows[i] is the row i+1 of the sheet.
+// Missing rows are returned empty and rows are cut after their last non empty cell.
+// Numbers are returned without an exponent and padded as their format shows them: 0000000000000 shows 12345 as 0000000012345.
+func Read(r io.ReaderAt, size int64) ([][]string, error) {
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("not an xlsx file: %w", err)
+	}
+
+	files := map[string]*zip.File{}
+	for _, file := range archive.File {
+		files[file.Name] = file
+	}
+
+	sheetPath, err := firstSheet(files)
+	if err != nil {
+		return nil, err
+	}
+
+	strs := sharedStrings{}
+	if file, ok := files["xl/sharedStrings.xml"]; ok {
+		if err := decode(file, &strs); err != nil {
+			return nil, err
+		}
+	}
+
+	zeroWidths := []int{}
+	if file, ok := files["xl/styles.xml"]; ok {
+		sheetStyles := styles{}
+		if err := decode(file, &sheetStyles); err != nil {
+			return nil, err
+		}
+		zeroWidths = sheetStyles.zeroWidths()
+	}
+
+	file, ok := files[sheetPath]
+	if !ok {
+		return nil, fmt.Errorf("sheet %s not found", sheetPath)
+	}
+
+	sheet := worksheet{}
+	if err := decode(file, &sheet); err != nil {
+		return nil, err
+	}
+
+	rows := [][]string{}
+	for _, sheetRow := range sheet.Rows {
+		row := []string{}
+		for _, cell := range sheetRow.Cells {
+			column := len(row)
+			if cell.Ref != "" {
+				if column, err = columnIndex(cell.Ref); err != nil {
+					return nil, err
+				}
+			}
+
+			value := cell.Value
+			switch cell.Type {
+			case "s":
+				var index int
+				if _, err := fmt.Sscan(cell.Value, &index); err != nil || index < 0 || index >= len(strs.Items) {
+					return nil, fmt.Errorf("cell %s refers to a missing shared string", cell.Ref)
+				}
+				value = strs.Items[index].String()
+			case "inlineStr":
+				if cell.Inline != nil {
+					value = cell.Inline.String()
+				}
+			case "", "n":
+				width := 0
+				if cell.Style >= 0 && cell.Style < len(zeroWidths) {
+					width = zeroWidths[cell.Style]
+				}
+				value = formatNumber(cell.Value, width)
+			}
+
+			for len(row) <= column {
+				row = append(row, "")
+			}
+			row[column] = value
+		}
+
+		for len(row) > 0 && strings.TrimSpace(row[len(row)-1]) == "" {
+			row = row[:len(row)-1]
+		}
+		if len(row) == 0 {
+			continue
+		}
+
+		number := len(rows) + 1
+		if sheetRow.Number > maxRows {
+			return nil, fmt.Errorf("invalid row number %d", sheetRow.Number)
+		}
+		if sheetRow.Number > 0 {
+			number = sheetRow.Number
+		}
+		for len(rows) < number-1 {
+			rows = append(rows, nil)
+		}
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+// formatNumber writes a number without an exponent, whole numbers are padded with zeros to width.
+// Values which are not numbers are returned as they are.
+func formatNumber(value string, width int) string {
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return value
+	}
+
+	if strings.ContainsAny(value, "eE") {
+		value = strconv.FormatFloat(number, 'f', -1, 64)
+	}
+	if width > 0 && number >= 0 && number == float64(int64(number)) && number < 1e15 {
+		value = fmt.Sprintf("%0*d", width, int64(number))
+	}
+	return value
+}
+
+// firstSheet finds the part of the first sheet of the workbook.
+func firstSheet(files map[string]*zip.File) (string, error) {
+	book, rels := workbook{}, relationships{}
+
+	file, ok := files["xl/workbook.xml"]
+	if !ok {
+		return "", errors.New("not an xlsx file: workbook not found")
+	}
+	if err := decode(file, &book); err != nil {
+		return "", err
+	}
+
+	if len(book.Sheets) == 0 {
+		return "", errors.New("workbook has no sheets")
+	}
+
+	file, ok = files["xl/_rels/workbook.xml.rels"]
+	if !ok {
+		return "xl/worksheets/sheet1.xml", nil
+	}
+	if err := decode(file, &rels); err != nil {
+		return "", err
+	}
+
+	for _, rel := range rels.Relationships {
+		if rel.ID != book.Sheets[0].ID {
+			continue
+		}
+		if strings.HasPrefix(rel.Target, "/") {
+			return strings.TrimPrefix(rel.Target, "/"), nil
+		}
+		return path.Join("xl", rel.Target), nil
+	}
+
+	return "", errors.New("first sheet not found")
+}
+
+func decode(file *zip.File, v interface{}) error {
+	if file.UncompressedSize64 > maxSheetSize {
+		return fmt.Errorf("%s is too large", file.Name)
+	}
+
+	r, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	if err := xml.NewDecoder(io.LimitReader(r, maxSheetSize)).Decode(v); err != nil {
+		return fmt.Errorf("error while reading %s: %w", file.Name, err)
+	}
+	return nil
+}
+
+// columnIndex returns the zero based column of a cell reference like "AB12".
+func columnIndex(ref string) (int, error) {
+	column := 0
+	for i, r := range ref {
+		if r >= 'A' && r <= 'Z' {
+			if column = column*26 + int(r-'A') + 1; column > maxColumns {
+				break
+			}
+			continue
+		}
+		if i == 0 || column > maxColumns {
+			break
+		}
+		return column - 1, nil
+	}
+	return 0, fmt.Errorf("invalid cell reference %q", ref)
+}
+
+// columnName returns the letters of a zero based column: 0 is A, 27 is AB.
+func columnName(column int) string {
+	name := ""
+	for column++; column > 0; column = (column - 1) / 26 {
+		name = string(rune('A'+(column-1)%26)) + name
+	}
+	return name
+}
@@ -0,0 +1,154 @@
+package xlsx
+
+import (
+	"archive/zip"
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+// fixture is a catalogue saved the way spreadsheet applications save it: texts are shared strings,
+// prices and barcodes are numbers, cells of the second style show thirteen digits.
+var fixture = map[string]string{
+	"[Content_Types].xml": `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"><Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/><Default Extension="xml" ContentType="application/xml"/></Types>`,
+	"xl/workbook.xml": `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="Products" sheetId="1" r:id="rId3"/><sheet name="Notes" sheetId="2" r:id="rId1"/></sheets></workbook>`,
+	"xl/_rels/workbook.xml.rels": `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet2.xml"/><Relationship Id="rId3" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/></Relationships>`,
+	"xl/sharedStrings.xml": `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" count="6" uniqueCount="6"><si><t>name</t></si><si><t>price</t></si><si><t>barcode</t></si><si><t>category</t></si><si><r><t>Qo‘y </t></r><r><rPr><b/></rPr><t>go‘shti</t></r></si><si><t>Food/Meat</t></si></sst>`,
+	"xl/styles.xml": `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><numFmts count="1"><numFmt numFmtId="164" formatCode="0000000000000"/></numFmts><cellXfs count="3"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/><xf numFmtId="164" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/><xf numFmtId="1" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/></cellXfs></styleSheet>`,
+	"xl/worksheets/sheet1.xml": `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>` +
+		`<row r="1"><c r="A1" t="s"><v>0</v></c><c r="B1" t="s"><v>1</v></c><c r="C1" t="s"><v>2</v></c><c r="D1" t="s"><v>3</v></c></row>` +
+		`<row r="2"><c r="A2" t="s"><v>4</v></c><c r="B2"><v>95000.5</v></c><c r="C2" s="1"><v>36000291452</v></c><c r="D2" t="s"><v>5</v></c></row>` +
+		`<row r="3"><c r="A3" t="inlineStr"><is><t>Milk</t></is></c><c r="B3" s="2"><v>12000</v></c><c r="C3" t="n"><v>4.006381333931E+12</v></c><c r="E3"><v></v></c></row>` +
+		`<row r="5"><c r="C5" s="1"><v>96385074</v></c></row>` +
+		`</sheetData></worksheet>`,
+	"xl/worksheets/sheet2.xml": `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData><row r="1"><c r="A1" t="inlineStr"><is><t>not the first sheet</t></is></c></row></sheetData></worksheet>`,
+}
+
+func TestRead(t *testing.T) {
+	data := archive(t, fixture)
+
+	rows, err := Read(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := [][]string{
+		{"name", "price", "barcode", "category"},
+		{"Qo‘y go‘shti", "95000.5", "0036000291452", "Food/Meat"},
+		{"Milk", "12000", "4006381333931"},
+		nil,
+		{"", "", "0000096385074"},
+	}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("Read = %q, want %q", rows, want)
+	}
+}
+
+func TestReadWritten(t *testing.T) {
+	want := [][]string{
+		{"name", "price", "barcode"},
+		{"Sut <1 l> & \"qaymoq\"", "12000.00", "0012345678905"},
+		nil,
+		{"", "", "", "Z"},
+	}
+
+	buf := bytes.Buffer{}
+	if err := Write(&buf, want); err != nil {
+		t.Fatal(err)
+	}
+
+	rows, err := Read(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("Read(Write(%q)) = %q", want, rows)
+	}
+}
+
+func TestReadInvalid(t *testing.T) {
+	tests := map[string]map[string]string{
+		"no workbook":           {"xl/worksheets/sheet1.xml": fixture["xl/worksheets/sheet1.xml"]},
+		"missing sheet":         {"xl/workbook.xml": fixture["xl/workbook.xml"], "xl/_rels/workbook.xml.rels": fixture["xl/_rels/workbook.xml.rels"]},
+		"missing shared string": {"xl/workbook.xml": fixture["xl/workbook.xml"], "xl/worksheets/sheet1.xml": fixture["xl/worksheets/sheet1.xml"]},
+	}
+
+	for name, parts := range tests {
+		data := archive(t, parts)
+		if _, err := Read(bytes.NewReader(data), int64(len(data))); err == nil {
+			t.Errorf("%s: workbook is read", name)
+		}
+	}
+
+	if _, err := Read(bytes.NewReader([]byte("name,price")), 10); err == nil {
+		t.Error("csv file is read as a workbook")
+	}
+}
+
+func TestFormatNumber(t *testing.T) {
+	tests := []struct {
+		value string
+		width int
+		want  string
+	}{
+		{"12000", 0, "12000"},
+		{"95000.5", 0, "95000.5"},
+		{"4.006381333931E+12", 0, "4006381333931"},
+		{"1.5E-3", 0, "0.0015"},
+		{"36000291452", 12, "036000291452"},
+		{"36000291452", 5, "36000291452"},
+		{"12.5", 13, "12.5"},
+		{"-5", 3, "-5"},
+		{"", 13, ""},
+	}
+
+	for _, test := range tests {
+		if got := formatNumber(test.value, test.width); got != test.want {
+			t.Errorf("formatNumber(%q, %d) = %q, want %q", test.value, test.width, got, test.want)
+		}
+	}
+}
+
+func TestColumns(t *testing.T) {
+	for _, column := range []int{0, 1, 25, 26, 27, 701, 702, maxColumns - 1} {
+		index, err := columnIndex(columnName(column) + "1")
+		if err != nil || index != column {
+			t.Errorf("columnIndex(%s1) = %d, %v, want %d", columnName(column), index, err, column)
+		}
+	}
+
+	for _, ref := range []string{"", "12", "A", "a1", "XFE1"} {
+		if _, err := columnIndex(ref); err == nil {
+			t.Errorf("columnIndex(%q) is accepted", ref)
+		}
+	}
+}
+
+// archive zips the parts of a workbook.
+func archive(t *testing.T, parts map[string]string) []byte {
+	t.Helper()
+
+	buf := bytes.Buffer{}
+	w := zip.NewWriter(&buf)
+	for name, content := range parts {
+		file, err := w.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := file.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"sell/api/models"
+	"sell/pkg/money"
+	"strings"
+	"time"
+)
+
+// Currency returns the upper-case code of a supported currency, the base currency when code is empty.
+func (s Service) Currency(code string) (string, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if code == "" || code == s.cfg.BaseCurrency {
+		return s.cfg.BaseCurrency, nil
+	}
+
+	for _, supported := range s.cfg.Currencies {
+		if strings.TrimSpace(supported) == code {
+			return code, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %q, currencies are %s", ErrCurrency, code, strings.Join(s.cfg.Currencies, ", "))
+}
+
+// ExchangeRate returns the rate of the currency to the base currency at the moment, 1 for the base currency.
+// The error is ErrNoExchangeRate when the currency has no rate yet.
+func (s Service) ExchangeRate(ctx context.Context, currency string, at time.Time) (money.Rate, error) {
+	if currency == s.cfg.BaseCurrency {
+		return money.OneRate, nil
+	}
+
+	rate, err := s.storage.ExchangeRate().GetEffective(ctx, models.EffectiveRateRequest{
+		Currency: currency,
+		At:       at,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, fmt.Errorf("%w: %s has no exchange rate at %s", ErrNoExchangeRate, currency, at.Format(time.RFC3339))
+		}
+		return 0, err
+	}
+
+	return rate.Rate, nil
+}
+
+// BranchPrice returns the price the product is sold at in the branch now.
+func (s Service) BranchPrice(ctx context.Context, productID, branchID string) (money.Money, error) {
+	price, err := s.storage.ProductPrice().GetEffective(ctx, models.EffectivePriceRequest{
+		ProductID: productID,
+		BranchID:  branchID,
+		At:        time.Now(),
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return price.Price, nil
+}
@@ -0,0 +1,206 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sell/api/models"
+	"sell/pkg/fiscal"
+	"time"
+)
+
+const (
+	// fiscalTimeout bounds one call to the fiscal module, the lease of a claimed entry outlives it
+	// so nobody sends the entry again while it is still being registered.
+	fiscalTimeout = 30 * time.Second
+	fiscalLease   = 2 * fiscalTimeout
+	fiscalBatch   = 20
+	// the pause between attempts doubles up to fiscalMaxPause
+	fiscalMaxPause = time.Hour
+)
+
+// errSaleNotFiscalized is returned for a refund whose sale has no sign yet, the refund waits for it
+// without using up its attempts.
+var errSaleNotFiscalized = errors.New("sale is not registered with the fiscal module yet")
+
+// RetryFiscal makes a failed or waiting entry of the fiscal outbox due with all its attempts and sends it now.
+func (s Service) RetryFiscal(ctx context.Context, id string) error {
+	if err := s.storage.Fiscal().Retry(ctx, id); err != nil {
+		return err
+	}
+
+	s.fiscalize(ctx, models.ClaimFiscalEntries{ID: id, Limit: 1})
+
+	return nil
+}
+
+// RunFiscalOutbox sends the due entries of the fiscal outbox every cfg.FiscalRetryInterval until ctx is done.
+func (s Service) RunFiscalOutbox(ctx context.Context) {
+	if s.cfg.FiscalRetryInterval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(s.cfg.FiscalRetryInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			// a full batch means more entries may be due, they are sent before waiting again
+			for {
+				if s.fiscalize(ctx, models.ClaimFiscalEntries{Limit: fiscalBatch}) < fiscalBatch {
+					break
+				}
+			}
+		}
+	}
+}
+
+// fiscalize registers the outbox entries it claims with the fiscal module and returns how many it claimed.
+// Errors are only logged, a failed entry stays in the outbox and is tried again after a pause.
+func (s Service) fiscalize(ctx context.Context, claim models.ClaimFiscalEntries) int {
+	claim.Lease = fiscalLease
+	entries, err := s.storage.Fiscal().Claim(ctx, claim)
+	if err != nil {
+		fmt.Println("error is while claiming fiscal outbox entries", err.Error())
+		return 0
+	}
+
+	for _, entry := range entries {
+		result, err := s.registerFiscal(ctx, entry)
+		if errors.Is(err, errSaleNotFiscalized) {
+			// the claim counted an attempt, waiting for the sale is not one
+			retryAt := time.Now().Add(s.cfg.FiscalRetryInterval)
+			if err := s.storage.Fiscal().Postpone(ctx, models.FiscalFailure{
+				EntryID: entry.ID,
+				Error:   err.Error(),
+				RetryAt: &retryAt,
+			}); err != nil {
+				fmt.Println("error is while postponing fiscal outbox entry", err.Error())
+			}
+			continue
+		}
+		if err != nil {
+			fmt.Println("error is while registering "+entry.DocumentType+" "+entry.DocumentID+" with the fiscal module", err.Error())
+
+			failure := models.FiscalFailure{EntryID: entry.ID, Error: err.Error()}
+			if entry.Attempts < s.cfg.FiscalMaxAttempts {
+				retryAt := time.Now().Add(fiscalPause(s.cfg.FiscalRetryInterval, entry.Attempts))
+				failure.RetryAt = &retryAt
+			}
+			if err := s.storage.Fiscal().Fail(ctx, failure); err != nil {
+				fmt.Println("error is while saving fiscal outbox failure", err.Error())
+			}
+			continue
+		}
+
+		if err := s.storage.Fiscal().Done(ctx, models.FiscalResult{
+			EntryID: entry.ID,
+			Sign:    result.Sign,
+			Number:  result.Number,
+		}); err != nil {
+			fmt.Println("error is while saving fiscal sign", err.Error())
+		}
+	}
+
+	return len(entries)
+}
+
+// fiscalPause returns the pause after the attempt, interval after the first one and twice as long after every next.
+func fiscalPause(interval time.Duration, attempt int) time.Duration {
+	pause := interval
+	for i := 1; i < attempt && pause < fiscalMaxPause; i++ {
+		pause *= 2
+	}
+	if pause > fiscalMaxPause {
+		pause = fiscalMaxPause
+	}
+	return pause
+}
+
+func (s Service) registerFiscal(ctx context.Context, entry models.FiscalEntry) (fiscal.Result, error) {
+	receipt, err := s.fiscalReceipt(ctx, entry)
+	if err != nil {
+		return fiscal.Result{}, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, fiscalTimeout)
+	defer cancel()
+
+	return s.fiscal.Register(ctx, receipt)
+}
+
+// fiscalReceipt builds the receipt of the sale or the refund of the entry. A refund is sent with the header
+// and the product names of its sale.
+func (s Service) fiscalReceipt(ctx context.Context, entry models.FiscalEntry) (fiscal.Receipt, error) {
+	saleID := entry.DocumentID
+
+	refund := models.Refund{}
+	if entry.DocumentType == fiscal.TypeRefund {
+		var err error
+		if refund, err = s.storage.Refund().GetByID(ctx, entry.DocumentID); err != nil {
+			return fiscal.Receipt{}, err
+		}
+		saleID = refund.SaleID
+	}
+
+	sale, err := s.storage.Sale().Receipt(ctx, saleID)
+	if err != nil {
+		return fiscal.Receipt{}, err
+	}
+
+	receipt := fiscal.Receipt{
+		Type:        entry.DocumentType,
+		DocumentID:  entry.DocumentID,
+		SaleID:      sale.SaleID,
+		BranchID:    sale.BranchID,
+		CashierID:   sale.CashierID,
+		PaymentType: sale.PaymentType,
+		Currency:    sale.Currency,
+		TaxIncluded: sale.TaxIncluded,
+		Lines:       []fiscal.Line{},
+		Total:       sale.Total,
+		Tax:         sale.Tax,
+		CreatedAt:   sale.CreatedAt,
+	}
+
+	names := map[string]string{}
+	for _, line := range sale.Lines {
+		names[line.ProductID] = line.Name
+		if entry.DocumentType == fiscal.TypeSale {
+			receipt.Lines = append(receipt.Lines, fiscal.Line{
+				ProductID: line.ProductID,
+				Name:      line.Name,
+				Quantity:  line.Quantity,
+				Price:     line.Price,
+				TaxRate:   line.TaxRate,
+				Tax:       line.Tax,
+			})
+		}
+	}
+
+	if entry.DocumentType == fiscal.TypeRefund {
+		// the module refers to the sale by its sign, the refund waits in the outbox until the sale has one
+		if sale.FiscalSign == "" {
+			return fiscal.Receipt{}, fmt.Errorf("%w: sale %s", errSaleNotFiscalized, sale.SaleID)
+		}
+		for _, product := range refund.Products {
+			receipt.Lines = append(receipt.Lines, fiscal.Line{
+				ProductID: product.ProductID,
+				Name:      names[product.ProductID],
+				Quantity:  product.Quantity,
+				Price:     product.Price,
+				TaxRate:   product.TaxRate,
+				Tax:       product.TaxAmount,
+			})
+		}
+		receipt.Total = refund.Price
+		receipt.Tax = refund.TaxAmount
+		receipt.SaleFiscalSign = sale.FiscalSign
+		receipt.CreatedAt = refund.CreatedAt
+	}
+
+	return receipt, nil
+}
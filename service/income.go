@@ -0,0 +1,148 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"sell/api/models"
+	"sell/pkg/check"
+	"time"
+)
+
+// CreateIncome opens an income in its currency, the base currency when empty, at the exchange rate of now.
+func (s Service) CreateIncome(ctx context.Context, income models.CreateIncome) (models.Income, error) {
+	var err error
+	if income.Currency, err = s.Currency(income.Currency); err != nil {
+		return models.Income{}, err
+	}
+	if income.ExchangeRate, err = s.ExchangeRate(ctx, income.Currency, time.Now()); err != nil {
+		return models.Income{}, err
+	}
+
+	id, err := s.storage.Income().Create(ctx, income)
+	if err != nil {
+		return models.Income{}, err
+	}
+
+	return s.storage.Income().GetByID(ctx, id)
+}
+
+// UpdateIncome changes an income in process, the exchange rate of its currency is taken again at the moment.
+func (s Service) UpdateIncome(ctx context.Context, income models.UpdateIncome) (models.Income, error) {
+	var err error
+	if income.Currency, err = s.Currency(income.Currency); err != nil {
+		return models.Income{}, err
+	}
+	if income.ExchangeRate, err = s.ExchangeRate(ctx, income.Currency, time.Now()); err != nil {
+		return models.Income{}, err
+	}
+
+	id, err := s.storage.Income().Update(ctx, income)
+	if err != nil {
+		return models.Income{}, err
+	}
+
+	return s.storage.Income().GetByID(ctx, id)
+}
+
+// AddIncomeProduct adds a line to an income in process, stock is added when the income is received.
+func (s Service) AddIncomeProduct(ctx context.Context, incomeProduct models.CreateIncomeProduct) (models.IncomeProduct, error) {
+	if incomeProduct.Count <= 0 || incomeProduct.Price < 0 {
+		return models.IncomeProduct{}, fmt.Errorf("%w: count should be positive and price should not be negative", ErrInvalidQuantity)
+	}
+
+	if err := validateExpiryDate(incomeProduct.ExpiryDate); err != nil {
+		return models.IncomeProduct{}, err
+	}
+
+	if err := s.CheckQuantity(ctx, incomeProduct.ProductID, incomeProduct.ProductUnitID, incomeProduct.Count); err != nil {
+		return models.IncomeProduct{}, err
+	}
+
+	id, err := s.storage.IncomeProducts().Create(ctx, incomeProduct)
+	if err != nil {
+		return models.IncomeProduct{}, err
+	}
+
+	return s.storage.IncomeProducts().GetByID(ctx, id)
+}
+
+// UpdateIncomeProduct changes a line of an income in process.
+func (s Service) UpdateIncomeProduct(ctx context.Context, incomeProduct models.UpdateIncomeProduct) (models.IncomeProduct, error) {
+	if incomeProduct.Price < 0 {
+		return models.IncomeProduct{}, fmt.Errorf("%w: price should not be negative", ErrInvalidAmount)
+	}
+
+	if err := validateExpiryDate(incomeProduct.ExpiryDate); err != nil {
+		return models.IncomeProduct{}, err
+	}
+
+	if err := s.CheckQuantity(ctx, incomeProduct.ProductID, incomeProduct.ProductUnitID, incomeProduct.Count); err != nil {
+		return models.IncomeProduct{}, err
+	}
+
+	id, err := s.storage.IncomeProducts().Update(ctx, incomeProduct)
+	if err != nil {
+		return models.IncomeProduct{}, err
+	}
+
+	return s.storage.IncomeProducts().GetByID(ctx, id)
+}
+
+// ReceiveIncome adds every line of the income to the repository of its branch and locks the income.
+func (s Service) ReceiveIncome(ctx context.Context, id string) (models.Income, error) {
+	if err := s.storage.Income().Finish(ctx, id); err != nil {
+		return models.Income{}, err
+	}
+
+	return s.storage.Income().GetByID(ctx, id)
+}
+
+// CheckQuantity checks that count packs of the product unit, or count units of the product when no unit
+// is given, make a quantity the product unit allows.
+func (s Service) CheckQuantity(ctx context.Context, productID, productUnitID string, count float64) error {
+	product, err := s.storage.Product().GetByID(ctx, productID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("%w: %s", ErrProductNotFound, productID)
+		}
+		return err
+	}
+
+	unitQuantity := 1.0
+	if productUnitID != "" {
+		unitQuantity = 0
+		for _, unit := range product.Units {
+			if unit.ID == productUnitID {
+				unitQuantity = unit.Quantity
+			}
+		}
+		if unitQuantity == 0 {
+			return fmt.Errorf("%w: product unit %s not found", ErrInvalidQuantity, productUnitID)
+		}
+	}
+
+	if count <= 0 {
+		return fmt.Errorf("%w: count should be positive", ErrInvalidQuantity)
+	}
+
+	if err := check.ValidateQuantity(count*unitQuantity, product.Precision); err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidQuantity, err.Error())
+	}
+
+	return nil
+}
+
+// validateExpiryDate accepts no expiry date or a day like 2024-03-31, which the date column would take.
+func validateExpiryDate(date string) error {
+	if date == "" {
+		return nil
+	}
+
+	if _, err := time.Parse("2006-01-02", date); err != nil {
+		return fmt.Errorf("%w: expiry date: %s", ErrInvalidDate, err.Error())
+	}
+
+	return nil
+}
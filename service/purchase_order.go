@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sell/api/models"
+	"time"
+)
+
+// CreatePurchaseOrder orders products from a supplier in the currency of the order, the base currency when empty.
+func (s Service) CreatePurchaseOrder(ctx context.Context, order models.CreatePurchaseOrder) (models.PurchaseOrder, error) {
+	if len(order.Products) == 0 {
+		return models.PurchaseOrder{}, ErrEmptyDocument
+	}
+
+	currency, err := s.Currency(order.Currency)
+	if err != nil {
+		return models.PurchaseOrder{}, err
+	}
+	order.Currency = currency
+
+	for _, product := range order.Products {
+		if product.Count <= 0 || product.Price < 0 {
+			return models.PurchaseOrder{}, fmt.Errorf("%w: count should be positive and price should not be negative", ErrInvalidQuantity)
+		}
+		if err := s.CheckQuantity(ctx, product.ProductID, "", product.Count); err != nil {
+			return models.PurchaseOrder{}, err
+		}
+	}
+
+	id, err := s.storage.PurchaseOrder().Create(ctx, order)
+	if err != nil {
+		return models.PurchaseOrder{}, err
+	}
+
+	return s.storage.PurchaseOrder().GetByID(ctx, id)
+}
+
+// ReceivePurchaseOrder turns the received products of the order into a finished income at the exchange rate
+// of the order currency now, and returns the income.
+func (s Service) ReceivePurchaseOrder(ctx context.Context, request models.ReceivePurchaseOrder) (models.Income, error) {
+	if len(request.Products) == 0 {
+		return models.Income{}, ErrEmptyDocument
+	}
+
+	for _, product := range request.Products {
+		if product.Count <= 0 || product.Price < 0 {
+			return models.Income{}, fmt.Errorf("%w: count should be positive and price should not be negative", ErrInvalidQuantity)
+		}
+		if err := s.CheckQuantity(ctx, product.ProductID, product.ProductUnitID, product.Count); err != nil {
+			return models.Income{}, err
+		}
+		if product.ExpiryDate != "" {
+			if _, err := time.Parse("2006-01-02", product.ExpiryDate); err != nil {
+				return models.Income{}, fmt.Errorf("%w: expiry date: %s", ErrInvalidDate, err.Error())
+			}
+		}
+	}
+
+	order, err := s.storage.PurchaseOrder().GetByID(ctx, request.ID)
+	if err != nil {
+		return models.Income{}, err
+	}
+
+	if request.ExchangeRate, err = s.ExchangeRate(ctx, order.Currency, time.Now()); err != nil {
+		return models.Income{}, err
+	}
+
+	incomeID, err := s.storage.PurchaseOrder().Receive(ctx, request)
+	if err != nil {
+		return models.Income{}, err
+	}
+
+	return s.storage.Income().GetByID(ctx, incomeID)
+}
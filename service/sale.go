@@ -0,0 +1,411 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"math"
+	"sell/api/models"
+	"sell/pkg/check"
+	"sell/pkg/fiscal"
+	"sell/pkg/money"
+	"sell/storage"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// StartSale opens a sale. Sales are priced and settled in the base currency, other currencies are accepted
+// as payments only.
+func (s Service) StartSale(ctx context.Context, sale models.CreateSale) (models.Sale, error) {
+	sale.Currency = s.cfg.BaseCurrency
+	sale.TaxIncluded = s.cfg.PricesIncludeTax
+	// a sale is finished or canceled by its own requests only
+	sale.Status = "in_process"
+
+	id, err := s.storage.Sale().Create(ctx, sale)
+	if err != nil {
+		return models.Sale{}, err
+	}
+
+	return s.storage.Sale().GetByID(ctx, id)
+}
+
+// ScanItem puts the product of the barcode into the basket of the sale. Barcodes printed by scales hold
+// the weight or the price of the item, a pack barcode stands for several units of the product and
+// other barcodes for barcode.Count units, one when it is not given.
+func (s Service) ScanItem(ctx context.Context, barcode models.Barcode) (models.Basket, error) {
+	sale, err := s.openSale(ctx, barcode.SaleID)
+	if err != nil {
+		return models.Basket{}, err
+	}
+
+	formats, err := s.storage.BarcodeFormat().GetList(ctx)
+	if err != nil {
+		return models.Basket{}, err
+	}
+
+	var (
+		code        = barcode.Barcode
+		scaleFormat models.BarcodeFormat
+		scaleValue  float64
+		isScale     bool
+	)
+
+	if format, productCode, value, ok := decodeScaleBarcode(barcode.Barcode, formats.BarcodeFormats); ok {
+		code, scaleFormat, scaleValue, isScale = productCode, format, value, true
+	}
+
+	productBarcode, err := s.storage.Product().GetByBarcode(ctx, code)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.Basket{}, fmt.Errorf("%w: no product with barcode %s", ErrProductNotFound, code)
+		}
+		return models.Basket{}, err
+	}
+
+	product, err := s.storage.Product().GetByID(ctx, productBarcode.ProductID)
+	if err != nil {
+		return models.Basket{}, err
+	}
+
+	if product.Price, err = s.BranchPrice(ctx, product.ID, sale.BranchID); err != nil {
+		return models.Basket{}, err
+	}
+
+	var (
+		quantity   = barcode.Count
+		totalPrice money.Money
+		// scale quantities are rounded to the precision of the product unit
+		scale = math.Pow10(product.Precision)
+	)
+
+	switch {
+	case isScale && scaleFormat.ValueType == "weight":
+		quantity = math.Round(scaleValue*scale) / scale
+		totalPrice, err = product.Price.Mul(scaleValue)
+	case isScale:
+		if product.Price <= 0 {
+			return models.Basket{}, fmt.Errorf("%w: cannot calculate quantity of a price barcode", ErrNoPrice)
+		}
+		totalPrice, err = money.Unit.Mul(scaleValue)
+		quantity = math.Round(float64(totalPrice)/float64(product.Price)*scale) / scale
+	default:
+		if quantity <= 0 {
+			quantity = 1
+		}
+		quantity *= float64(productBarcode.Quantity)
+		// the count comes from the client, it is checked before the price is calculated from it
+		if err := check.ValidateQuantity(quantity, product.Precision); err != nil {
+			return models.Basket{}, fmt.Errorf("%w: %s", ErrInvalidQuantity, err.Error())
+		}
+		totalPrice, err = product.Price.Mul(quantity)
+	}
+	if err != nil {
+		return models.Basket{}, fmt.Errorf("%w: %s", ErrInvalidQuantity, err.Error())
+	}
+
+	if err := check.ValidateQuantity(quantity, product.Precision); err != nil {
+		return models.Basket{}, fmt.Errorf("%w: %s", ErrInvalidQuantity, err.Error())
+	}
+
+	return s.addToBasket(ctx, sale, product.ID, quantity, totalPrice)
+}
+
+// AddItem puts quantity of the product into the basket of the sale at the price of the sale branch.
+func (s Service) AddItem(ctx context.Context, item models.CreateBasket) (models.Basket, error) {
+	sale, err := s.openSale(ctx, item.SaleID)
+	if err != nil {
+		return models.Basket{}, err
+	}
+
+	product, err := s.storage.Product().GetByID(ctx, item.ProductID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.Basket{}, fmt.Errorf("%w: %s", ErrProductNotFound, item.ProductID)
+		}
+		return models.Basket{}, err
+	}
+
+	if product.Price, err = s.BranchPrice(ctx, product.ID, sale.BranchID); err != nil {
+		return models.Basket{}, err
+	}
+
+	if err := check.ValidateQuantity(item.Quantity, product.Precision); err != nil {
+		return models.Basket{}, fmt.Errorf("%w: %s", ErrInvalidQuantity, err.Error())
+	}
+
+	price, err := product.Price.Mul(item.Quantity)
+	if err != nil {
+		return models.Basket{}, fmt.Errorf("%w: %s", ErrInvalidQuantity, err.Error())
+	}
+
+	return s.addToBasket(ctx, sale, product.ID, item.Quantity, price)
+}
+
+// addToBasket adds quantity of the product for price to its line in the basket of the sale, or starts the line.
+// The branch of the sale must have the whole quantity of the line when it keeps the product.
+func (s Service) addToBasket(ctx context.Context, sale models.Sale, productID string, quantity float64, price money.Money) (models.Basket, error) {
+	baskets, err := s.saleBaskets(ctx, sale.ID)
+	if err != nil {
+		return models.Basket{}, err
+	}
+
+	line := models.Basket{}
+	for _, basket := range baskets {
+		if basket.ProductID == productID {
+			line = basket
+			break
+		}
+	}
+
+	repositories, err := s.productRepositories(ctx, productID)
+	if err != nil {
+		return models.Basket{}, err
+	}
+
+	for _, repository := range repositories {
+		if repository.BranchID == sale.BranchID && repository.Count < line.Quantity+quantity {
+			return models.Basket{}, fmt.Errorf("%w: %g left in the branch", ErrNotEnoughStock, repository.Count)
+		}
+	}
+
+	id := line.ID
+	if id != "" {
+		if _, err := s.storage.Basket().Update(ctx, models.UpdateBasket{
+			ID:        line.ID,
+			SaleID:    line.SaleID,
+			ProductID: productID,
+			Quantity:  line.Quantity + quantity,
+			Price:     line.Price + price,
+		}); err != nil {
+			return models.Basket{}, err
+		}
+	} else if id, err = s.storage.Basket().Create(ctx, models.CreateBasket{
+		SaleID:    sale.ID,
+		ProductID: productID,
+		Quantity:  quantity,
+		Price:     price,
+	}); err != nil {
+		return models.Basket{}, err
+	}
+
+	return s.storage.Basket().GetByID(ctx, models.PrimaryKey{ID: id})
+}
+
+// AddSalePayment records money received for the sale in progress, the amount is converted to the base
+// currency at the exchange rate of now.
+func (s Service) AddSalePayment(ctx context.Context, payment models.CreateSalePayment) (models.SalePayments, error) {
+	if payment.Amount <= 0 {
+		return models.SalePayments{}, fmt.Errorf("%w: amount should be positive", ErrInvalidAmount)
+	}
+
+	currency, err := s.Currency(payment.Currency)
+	if err != nil {
+		return models.SalePayments{}, err
+	}
+	payment.Currency = currency
+
+	if _, err := s.openSale(ctx, payment.SaleID); err != nil {
+		return models.SalePayments{}, err
+	}
+
+	if payment.ExchangeRate, err = s.ExchangeRate(ctx, payment.Currency, time.Now()); err != nil {
+		return models.SalePayments{}, err
+	}
+
+	if _, err := s.storage.Sale().AddPayment(ctx, payment); err != nil {
+		return models.SalePayments{}, err
+	}
+
+	return s.storage.Sale().GetPayments(ctx, payment.SaleID)
+}
+
+// FinishSale checks the sale out. The tax of its lines is kept with it and its price becomes the total to pay,
+// the goods leave the branch repository and batches and the staff get their commission all at once, then the sale
+// is registered with the fiscal module. A sale paid through sale payments is finished only when they cover its total,
+// a sale without payments is paid in full at the cash desk.
+func (s Service) FinishSale(ctx context.Context, saleID string) (models.Sale, error) {
+	if _, err := s.openSale(ctx, saleID); err != nil {
+		return models.Sale{}, err
+	}
+
+	payments, err := s.storage.Sale().GetPayments(ctx, saleID)
+	if err != nil {
+		return models.Sale{}, err
+	}
+	if len(payments.Payments) > 0 && payments.Due > 0 {
+		return models.Sale{}, fmt.Errorf("%w: %s %s is left to pay", ErrSaleNotPaid, payments.Due, payments.Currency)
+	}
+
+	// a sale finished twice at once is checked out by one of the requests only
+	if _, err := s.storage.Sale().Checkout(ctx, saleID); err != nil {
+		switch {
+		case errors.Is(err, storage.ErrSaleNotOpen):
+			return models.Sale{}, fmt.Errorf("%w: %s", ErrSaleClosed, err.Error())
+		case errors.Is(err, storage.ErrNotEnoughProduct):
+			return models.Sale{}, fmt.Errorf("%w: %s", ErrNotEnoughStock, err.Error())
+		}
+		return models.Sale{}, err
+	}
+
+	sale, err := s.storage.Sale().GetByID(ctx, saleID)
+	if err != nil {
+		return models.Sale{}, err
+	}
+
+	// the sale is registered at once when the fiscal module is up, otherwise it waits in the outbox
+	if s.fiscalize(ctx, models.ClaimFiscalEntries{
+		DocumentType: fiscal.TypeSale,
+		DocumentID:   saleID,
+		Limit:        1,
+	}) > 0 {
+		return s.storage.Sale().GetByID(ctx, saleID)
+	}
+
+	return sale, nil
+}
+
+// CancelSale closes the sale without selling anything.
+func (s Service) CancelSale(ctx context.Context, saleID string) (string, error) {
+	sale, err := s.openSale(ctx, saleID)
+	if err != nil {
+		return "", err
+	}
+
+	id, err := s.storage.Sale().UpdatePrice(ctx, models.SaleRequest{
+		SaleID:     saleID,
+		TotalPrice: 0,
+		Status:     "cancel",
+	})
+	if err != nil {
+		if errors.Is(err, storage.ErrSaleNotOpen) {
+			return "", fmt.Errorf("%w: %s", ErrSaleClosed, err.Error())
+		}
+		return "", err
+	}
+
+	if _, err := s.storage.Transaction().Create(ctx, models.CreateTransaction{
+		SaleID:          saleID,
+		StaffID:         sale.ShopAssistantID,
+		TransactionType: "withdraw",
+		SourceType:      "sales",
+		Amount:          0,
+		Description:     "sale canceled",
+	}); err != nil {
+		return "", err
+	}
+
+	return id, nil
+}
+
+// RefundSale gives back products of a successful sale and registers the refund with the fiscal module.
+func (s Service) RefundSale(ctx context.Context, refund models.CreateRefund) (models.Refund, error) {
+	for _, product := range refund.Products {
+		if product.BasketID == "" || product.Quantity <= 0 {
+			return models.Refund{}, fmt.Errorf("%w: basket_id is required and quantity should be positive", ErrInvalidQuantity)
+		}
+	}
+
+	id, err := s.storage.Refund().Create(ctx, refund)
+	if err != nil {
+		return models.Refund{}, err
+	}
+
+	s.fiscalize(ctx, models.ClaimFiscalEntries{
+		DocumentType: fiscal.TypeRefund,
+		DocumentID:   id,
+		Limit:        1,
+	})
+
+	return s.storage.Refund().GetByID(ctx, id)
+}
+
+// openSale returns the sale while it is in progress, products and payments are added to such sales only.
+func (s Service) openSale(ctx context.Context, id string) (models.Sale, error) {
+	sale, err := s.storage.Sale().GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.Sale{}, fmt.Errorf("%w: %s", ErrSaleNotFound, id)
+		}
+		return models.Sale{}, err
+	}
+
+	if sale.Status == "success" || sale.Status == "cancel" {
+		return models.Sale{}, fmt.Errorf("%w: the sale is %s", ErrSaleClosed, sale.Status)
+	}
+
+	return sale, nil
+}
+
+// saleBaskets returns every line of the sale.
+func (s Service) saleBaskets(ctx context.Context, saleID string) ([]models.Basket, error) {
+	request := models.GetListRequest{Page: 1, Limit: 100, Search: saleID}
+
+	baskets := []models.Basket{}
+	for {
+		response, err := s.storage.Basket().GetList(ctx, request)
+		if err != nil {
+			return nil, err
+		}
+		baskets = append(baskets, response.Baskets...)
+
+		if len(response.Baskets) < request.Limit || len(baskets) >= response.Count {
+			return baskets, nil
+		}
+		request.Page++
+	}
+}
+
+// productRepositories returns the repositories of the product in every branch.
+func (s Service) productRepositories(ctx context.Context, productID string) ([]models.Repository, error) {
+	request := models.GetListRequest{Page: 1, Limit: 100, Search: productID}
+
+	repositories := []models.Repository{}
+	for {
+		response, err := s.storage.Repository().GetList(ctx, request)
+		if err != nil {
+			return nil, err
+		}
+		repositories = append(repositories, response.Repositories...)
+
+		if len(response.Repositories) < request.Limit || len(repositories) >= response.Count {
+			return repositories, nil
+		}
+		request.Page++
+	}
+}
+
+// decodeScaleBarcode finds the first format the barcode matches and returns the product code
+// and the weight or price the barcode holds. Scales print digits only, other codes are never decoded.
+func decodeScaleBarcode(barcode string, formats []models.BarcodeFormat) (models.BarcodeFormat, string, float64, bool) {
+	if strings.Trim(barcode, "0123456789") != "" {
+		return models.BarcodeFormat{}, "", 0, false
+	}
+
+	for _, format := range formats {
+		if len(barcode) != format.CodeLength || !strings.HasPrefix(barcode, format.Prefix) {
+			continue
+		}
+
+		if format.ProductCodeStart+format.ProductCodeLength > len(barcode) || format.ValueStart+format.ValueLength > len(barcode) {
+			continue
+		}
+
+		if check.ValidateBarcode(barcode) != nil {
+			continue
+		}
+
+		value, err := strconv.Atoi(barcode[format.ValueStart : format.ValueStart+format.ValueLength])
+		if err != nil {
+			continue
+		}
+
+		productCode := barcode[format.ProductCodeStart : format.ProductCodeStart+format.ProductCodeLength]
+
+		return format, productCode, float64(value) / math.Pow10(format.ValueDecimals), true
+	}
+
+	return models.BarcodeFormat{}, "", 0, false
+}
@@ -0,0 +1,66 @@
+package service
+
+import (
+	"sell/api/models"
+	"sell/pkg/check"
+	"testing"
+)
+
+// withCheckDigit completes the digits of an EAN-13 code with its check digit.
+func withCheckDigit(digits string) string {
+	return digits + string(rune('0'+check.BarcodeCheckDigit(digits)))
+}
+
+func TestDecodeScaleBarcode(t *testing.T) {
+	weight := models.BarcodeFormat{
+		Name: "weight", Prefix: "21", CodeLength: 13,
+		ProductCodeStart: 2, ProductCodeLength: 5,
+		ValueStart: 7, ValueLength: 5, ValueType: "weight", ValueDecimals: 3,
+	}
+	price := models.BarcodeFormat{
+		Name: "price", Prefix: "22", CodeLength: 13,
+		ProductCodeStart: 2, ProductCodeLength: 5,
+		ValueStart: 7, ValueLength: 5, ValueType: "price", ValueDecimals: 2,
+	}
+	// a format whose value runs past the end of the code is skipped
+	broken := models.BarcodeFormat{
+		Name: "broken", Prefix: "23", CodeLength: 13,
+		ProductCodeStart: 2, ProductCodeLength: 5,
+		ValueStart: 10, ValueLength: 5, ValueType: "weight",
+	}
+	// every in-store prefix from 20 to 29 falls back to a whole-unit weight
+	instore := models.BarcodeFormat{
+		Name: "in-store", Prefix: "2", CodeLength: 13,
+		ProductCodeStart: 1, ProductCodeLength: 6,
+		ValueStart: 7, ValueLength: 5, ValueType: "weight",
+	}
+	formats := []models.BarcodeFormat{weight, price, broken, instore}
+
+	tests := []struct {
+		name    string
+		barcode string
+		format  string
+		code    string
+		value   float64
+		ok      bool
+	}{
+		{"weight", withCheckDigit("211234501250"), "weight", "12345", 1.25, true},
+		{"weight with leading zeros", withCheckDigit("210004200005"), "weight", "00042", 0.005, true},
+		{"price", withCheckDigit("221234504500"), "price", "12345", 45, true},
+		{"zero price", withCheckDigit("221234500000"), "price", "12345", 0, true},
+		{"broken format falls through", withCheckDigit("231234500007"), "in-store", "312345", 7, true},
+		{"other in-store prefix", withCheckDigit("291234500003"), "in-store", "912345", 3, true},
+		{"wrong check digit", "2112345012509", "", "", 0, false},
+		{"not in-store", "4006381333931", "", "", 0, false},
+		{"EAN-8", "20123451", "", "", 0, false},
+		{"letters", "21ABCDE01250X", "", "", 0, false},
+	}
+
+	for _, test := range tests {
+		format, code, value, ok := decodeScaleBarcode(test.barcode, formats)
+		if ok != test.ok || format.Name != test.format || code != test.code || value != test.value {
+			t.Errorf("%s: decodeScaleBarcode(%q) = %q, %q, %g, %v, want %q, %q, %g, %v", test.name, test.barcode,
+				format.Name, code, value, ok, test.format, test.code, test.value, test.ok)
+		}
+	}
+}
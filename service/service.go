@@ -0,0 +1,42 @@
+// Package service holds the use cases of the shop, selling, receiving goods and paying suppliers, on top of
+// storage.IStorage. Handlers only read requests and write responses, so the cli and tests run the same rules.
+package service
+
+import (
+	"errors"
+	"sell/config"
+	"sell/pkg/fiscal"
+	"sell/storage"
+)
+
+// Errors of requests the use cases turn down, they are wrapped with details and compared with errors.Is.
+// Errors of the storage, like storage.ErrIncomeFinished, are returned as they are.
+var (
+	ErrSaleNotFound    = errors.New("sale not found")
+	ErrProductNotFound = errors.New("product not found")
+	ErrSaleClosed      = errors.New("sale is already finished or canceled")
+	ErrSaleNotPaid     = errors.New("sale is not paid")
+	ErrInvalidQuantity = errors.New("invalid quantity")
+	ErrInvalidAmount   = errors.New("invalid amount")
+	ErrInvalidDate     = errors.New("invalid date")
+	ErrInvalidReason   = errors.New("invalid reason")
+	ErrNoPrice         = errors.New("product has no price")
+	ErrNotEnoughStock  = errors.New("not enough product")
+	ErrCurrency        = errors.New("currency is not supported")
+	ErrNoExchangeRate  = errors.New("no exchange rate")
+	ErrEmptyDocument   = errors.New("products should not be empty")
+)
+
+type Service struct {
+	storage storage.IStorage
+	fiscal  fiscal.FiscalDriver
+	cfg     config.Config
+}
+
+func New(cfg config.Config, store storage.IStorage, driver fiscal.FiscalDriver) Service {
+	return Service{
+		storage: store,
+		fiscal:  driver,
+		cfg:     cfg,
+	}
+}
@@ -0,0 +1,30 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sell/api/models"
+	"time"
+)
+
+// PaySupplier records a payment to the supplier in its currency at the exchange rate of now
+// and returns the supplier with the balance left.
+func (s Service) PaySupplier(ctx context.Context, payment models.CreateSupplierPayment) (models.Supplier, error) {
+	if payment.Amount <= 0 {
+		return models.Supplier{}, fmt.Errorf("%w: amount should be positive", ErrInvalidAmount)
+	}
+
+	var err error
+	if payment.Currency, err = s.Currency(payment.Currency); err != nil {
+		return models.Supplier{}, err
+	}
+	if payment.ExchangeRate, err = s.ExchangeRate(ctx, payment.Currency, time.Now()); err != nil {
+		return models.Supplier{}, err
+	}
+
+	if _, err := s.storage.Supplier().CreatePayment(ctx, payment); err != nil {
+		return models.Supplier{}, err
+	}
+
+	return s.storage.Supplier().GetByID(ctx, payment.SupplierID)
+}
@@ -0,0 +1,32 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sell/api/models"
+	"sell/pkg/check"
+)
+
+// CreateWriteOff creates a write-off document, stock is taken out when a manager approves it.
+func (s Service) CreateWriteOff(ctx context.Context, writeOff models.CreateWriteOff) (models.WriteOff, error) {
+	if err := check.ValidateWriteOffReason(writeOff.Reason); err != nil {
+		return models.WriteOff{}, fmt.Errorf("%w: %s", ErrInvalidReason, err.Error())
+	}
+
+	if len(writeOff.Products) == 0 {
+		return models.WriteOff{}, ErrEmptyDocument
+	}
+
+	for _, product := range writeOff.Products {
+		if err := s.CheckQuantity(ctx, product.ProductID, "", product.Quantity); err != nil {
+			return models.WriteOff{}, err
+		}
+	}
+
+	id, err := s.storage.WriteOff().Create(ctx, writeOff)
+	if err != nil {
+		return models.WriteOff{}, err
+	}
+
+	return s.storage.WriteOff().GetByID(ctx, id)
+}
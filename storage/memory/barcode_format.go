@@ -0,0 +1,105 @@
+package memory
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"sell/api/models"
+	"sell/storage"
+	"sort"
+	"time"
+)
+
+type barcodeFormatRow struct {
+	models.BarcodeFormat
+	seq     int64
+	deleted bool
+}
+
+type barcodeFormatRepo struct {
+	db *Store
+}
+
+func NewBarcodeFormatRepo(db *Store) storage.IBarcodeFormatStorage {
+	return &barcodeFormatRepo{db: db}
+}
+
+func (b *barcodeFormatRepo) Create(ctx context.Context, format models.CreateBarcodeFormat) (string, error) {
+	b.db.mu.Lock()
+	defer b.db.mu.Unlock()
+
+	id := uuid.New().String()
+	now := time.Now()
+	b.db.t.barcodeFormats[id] = barcodeFormatRow{
+		BarcodeFormat: models.BarcodeFormat{
+			ID:                id,
+			Name:              format.Name,
+			Prefix:            format.Prefix,
+			CodeLength:        format.CodeLength,
+			ProductCodeStart:  format.ProductCodeStart,
+			ProductCodeLength: format.ProductCodeLength,
+			ValueStart:        format.ValueStart,
+			ValueLength:       format.ValueLength,
+			ValueType:         format.ValueType,
+			ValueDecimals:     format.ValueDecimals,
+			CreatedAt:         now,
+			UpdatedAt:         now,
+		},
+		seq: b.db.next(),
+	}
+
+	return id, nil
+}
+
+func (b *barcodeFormatRepo) GetByID(ctx context.Context, id string) (models.BarcodeFormat, error) {
+	b.db.mu.Lock()
+	defer b.db.mu.Unlock()
+
+	row, ok := b.db.t.barcodeFormats[id]
+	if !ok || row.deleted {
+		return models.BarcodeFormat{}, pgx.ErrNoRows
+	}
+
+	return row.BarcodeFormat, nil
+}
+
+// GetList returns all formats, longer prefixes first so the most specific format is tried first.
+func (b *barcodeFormatRepo) GetList(ctx context.Context) (models.BarcodeFormatsResponse, error) {
+	b.db.mu.Lock()
+	defer b.db.mu.Unlock()
+
+	rows := []barcodeFormatRow{}
+	for _, row := range b.db.t.barcodeFormats {
+		if !row.deleted {
+			rows = append(rows, row)
+		}
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		if len([]rune(rows[i].Prefix)) != len([]rune(rows[j].Prefix)) {
+			return len([]rune(rows[i].Prefix)) > len([]rune(rows[j].Prefix))
+		}
+		return rows[i].seq < rows[j].seq
+	})
+
+	formats := []models.BarcodeFormat{}
+	for _, row := range rows {
+		formats = append(formats, row.BarcodeFormat)
+	}
+
+	return models.BarcodeFormatsResponse{
+		BarcodeFormats: formats,
+		Count:          len(formats),
+	}, nil
+}
+
+func (b *barcodeFormatRepo) Delete(ctx context.Context, id string) error {
+	b.db.mu.Lock()
+	defer b.db.mu.Unlock()
+
+	if row, ok := b.db.t.barcodeFormats[id]; ok {
+		row.deleted = true
+		b.db.t.barcodeFormats[id] = row
+	}
+
+	return nil
+}
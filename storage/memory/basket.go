@@ -0,0 +1,128 @@
+package memory
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"sell/api/models"
+	"sell/pkg/money"
+	"sell/storage"
+	"time"
+)
+
+// basketRow keeps the tax of the line calculated at checkout next to the basket.
+type basketRow struct {
+	models.Basket
+	taxRate   money.Money
+	taxAmount money.Money
+	seq       int64
+	deleted   bool
+}
+
+type basketRepo struct {
+	db *Store
+}
+
+func NewBasketRepo(db *Store) storage.IBasketRepo {
+	return &basketRepo{db: db}
+}
+
+func (s *basketRepo) Create(ctx context.Context, basket models.CreateBasket) (string, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	id := uuid.New().String()
+	now := time.Now()
+	s.db.t.baskets[id] = basketRow{
+		Basket: models.Basket{
+			ID:        id,
+			SaleID:    basket.SaleID,
+			ProductID: basket.ProductID,
+			Quantity:  basket.Quantity,
+			Price:     basket.Price,
+			CreatedAt: now,
+			UpdatedAt: now,
+		},
+		seq: s.db.next(),
+	}
+
+	return id, nil
+}
+
+func (s *basketRepo) GetByID(ctx context.Context, id models.PrimaryKey) (models.Basket, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	row, ok := s.db.t.baskets[id.ID]
+	if !ok || row.deleted {
+		return models.Basket{}, pgx.ErrNoRows
+	}
+
+	return row.Basket, nil
+}
+
+// GetList searches baskets by the id of their sale.
+func (s *basketRepo) GetList(ctx context.Context, request models.GetListRequest) (models.BasketsResponse, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	rows := s.db.saleBaskets(request.Search)
+	sortBySeq(rows, func(row basketRow) int64 { return row.seq }, true)
+
+	page, err := paginate(rows, request.Page, request.Limit)
+	if err != nil {
+		return models.BasketsResponse{}, err
+	}
+
+	baskets := []models.Basket{}
+	for _, row := range page {
+		baskets = append(baskets, row.Basket)
+	}
+
+	return models.BasketsResponse{
+		Baskets: baskets,
+		Count:   len(rows),
+	}, nil
+}
+
+func (s *basketRepo) Update(ctx context.Context, basket models.UpdateBasket) (string, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	if row, ok := s.db.t.baskets[basket.ID]; ok {
+		row.SaleID = basket.SaleID
+		row.ProductID = basket.ProductID
+		row.Quantity = basket.Quantity
+		row.Price = basket.Price
+		row.UpdatedAt = time.Now()
+		s.db.t.baskets[basket.ID] = row
+	}
+
+	return basket.ID, nil
+}
+
+func (s *basketRepo) Delete(ctx context.Context, id string) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	if row, ok := s.db.t.baskets[id]; ok {
+		row.deleted = true
+		row.UpdatedAt = time.Now()
+		s.db.t.baskets[id] = row
+	}
+
+	return nil
+}
+
+// saleBaskets returns the live baskets of the sale in the order they were added, of every sale when saleID is empty.
+func (s *Store) saleBaskets(saleID string) []basketRow {
+	rows := []basketRow{}
+	for _, row := range s.t.baskets {
+		if !row.deleted && (saleID == "" || row.SaleID == saleID) {
+			rows = append(rows, row)
+		}
+	}
+	sortBySeq(rows, func(row basketRow) int64 { return row.seq }, false)
+
+	return rows
+}
@@ -0,0 +1,176 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"sell/api/models"
+	"sell/storage"
+	"sort"
+	"time"
+)
+
+type batchRow struct {
+	models.Batch
+	seq     int64
+	deleted bool
+}
+
+type batchRepo struct {
+	db *Store
+}
+
+func NewBatchRepo(db *Store) storage.IBatchStorage {
+	return &batchRepo{db: db}
+}
+
+func (b *batchRepo) Create(ctx context.Context, batch models.CreateBatch) (string, error) {
+	if batch.ExpiryDate != "" {
+		if _, err := parseDate(batch.ExpiryDate); err != nil {
+			return "", err
+		}
+	}
+
+	b.db.mu.Lock()
+	defer b.db.mu.Unlock()
+
+	return b.db.insertBatch(batch), nil
+}
+
+func (b *batchRepo) GetList(ctx context.Context, request models.BatchGetListRequest) (models.BatchResponse, error) {
+	b.db.mu.Lock()
+	defer b.db.mu.Unlock()
+
+	rows := []batchRow{}
+	for _, row := range b.db.t.batches {
+		if row.deleted || row.Count <= 0 ||
+			request.BranchID != "" && row.BranchID != request.BranchID ||
+			request.ProductID != "" && row.ProductID != request.ProductID {
+			continue
+		}
+		rows = append(rows, row)
+	}
+	sortBatches(rows)
+
+	page, err := paginate(rows, request.Page, request.Limit)
+	if err != nil {
+		return models.BatchResponse{}, err
+	}
+
+	batches := []models.Batch{}
+	for _, row := range page {
+		batches = append(batches, row.Batch)
+	}
+
+	return models.BatchResponse{
+		Batches: batches,
+		Count:   len(rows),
+	}, nil
+}
+
+// GetExpiring returns batches with stock left that expire within the given days,
+// already expired batches are included with a negative days_left.
+func (b *batchRepo) GetExpiring(ctx context.Context, request models.ExpiringBatchesRequest) (models.ExpiringBatchesResponse, error) {
+	b.db.mu.Lock()
+	defer b.db.mu.Unlock()
+
+	today := dateOf(time.Now())
+
+	rows := []batchRow{}
+	daysLeft := map[string]int{}
+	for _, row := range b.db.t.batches {
+		if row.deleted || row.Count <= 0 || row.ExpiryDate == "" ||
+			request.BranchID != "" && row.BranchID != request.BranchID {
+			continue
+		}
+
+		expiry, err := parseDate(row.ExpiryDate)
+		if err != nil {
+			return models.ExpiringBatchesResponse{}, err
+		}
+		if days := daysBetween(today, expiry); days <= request.Days {
+			daysLeft[row.ID] = days
+			rows = append(rows, row)
+		}
+	}
+	sortBatches(rows)
+
+	batches := []models.ExpiringBatch{}
+	for _, row := range rows {
+		batches = append(batches, models.ExpiringBatch{Batch: row.Batch, DaysLeft: daysLeft[row.ID]})
+	}
+
+	return models.ExpiringBatchesResponse{
+		Batches: batches,
+		Count:   len(batches),
+	}, nil
+}
+
+func (s *Store) insertBatch(batch models.CreateBatch) string {
+	id := uuid.New().String()
+	now := time.Now()
+	s.t.batches[id] = batchRow{
+		Batch: models.Batch{
+			ID:              id,
+			BranchID:        batch.BranchID,
+			ProductID:       batch.ProductID,
+			IncomeProductID: batch.IncomeProductID,
+			BatchNumber:     batch.BatchNumber,
+			ExpiryDate:      batch.ExpiryDate,
+			Count:           batch.Count,
+			CreatedAt:       now,
+			UpdatedAt:       now,
+		},
+		seq: s.next(),
+	}
+
+	return id
+}
+
+// deductBatches takes quantity from the earliest expiring batches of a product in a branch.
+// Stock received before batches were tracked has no batch rows, so running out of batches is not an error:
+// the repository count is what limits the movement, the quantity the batches did not cover is only logged.
+func (s *Store) deductBatches(branchID, productID string, quantity float64) {
+	rows := []batchRow{}
+	for _, row := range s.t.batches {
+		if row.BranchID == branchID && row.ProductID == productID && row.Count > 0 && !row.deleted {
+			rows = append(rows, row)
+		}
+	}
+	sortBatches(rows)
+
+	for _, row := range rows {
+		if quantity <= 0 {
+			break
+		}
+
+		taken := min(row.Count, quantity)
+		row.Count -= taken
+		row.UpdatedAt = time.Now()
+		s.t.batches[row.ID] = row
+
+		quantity = roundQuantity(quantity - taken)
+	}
+
+	if quantity > 0 {
+		fmt.Println("batches of product", productID, "in branch", branchID, "are short of", quantity)
+	}
+}
+
+// sortBatches orders batches the earliest expiring first, batches without an expiry date last.
+func sortBatches(rows []batchRow) {
+	sort.SliceStable(rows, func(i, j int) bool {
+		a, b := rows[i].ExpiryDate, rows[j].ExpiryDate
+		if a != b {
+			return b == "" || a != "" && a < b
+		}
+		return rows[i].seq < rows[j].seq
+	})
+}
+
+// daysBetween is the number of days from one date to another, like subtracting dates in postgres.
+func daysBetween(from, to time.Time) int {
+	fromYear, fromMonth, fromDay := from.Date()
+	toYear, toMonth, toDay := to.Date()
+	return int(time.Date(toYear, toMonth, toDay, 0, 0, 0, 0, time.UTC).Sub(time.Date(fromYear, fromMonth, fromDay, 0, 0, 0, 0, time.UTC)).Hours() / 24)
+}
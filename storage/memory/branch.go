@@ -0,0 +1,110 @@
+package memory
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"sell/api/models"
+	"sell/storage"
+	"time"
+)
+
+type branchRow struct {
+	models.Branch
+	seq     int64
+	deleted bool
+}
+
+type branchRepo struct {
+	db *Store
+}
+
+func NewBranchRepo(db *Store) storage.IBranchStorage {
+	return branchRepo{db: db}
+}
+
+func (b branchRepo) Create(ctx context.Context, branch models.CreateBranch) (string, error) {
+	b.db.mu.Lock()
+	defer b.db.mu.Unlock()
+
+	id := uuid.New().String()
+	now := time.Now()
+	b.db.t.branches[id] = branchRow{
+		Branch: models.Branch{
+			ID:        id,
+			Name:      branch.Name,
+			Address:   branch.Address,
+			CreatedAt: now,
+			UpdatedAt: now,
+		},
+		seq: b.db.next(),
+	}
+
+	return id, nil
+}
+
+func (b branchRepo) GetByID(ctx context.Context, id string) (models.Branch, error) {
+	b.db.mu.Lock()
+	defer b.db.mu.Unlock()
+
+	row, ok := b.db.t.branches[id]
+	if !ok || row.deleted {
+		return models.Branch{}, pgx.ErrNoRows
+	}
+
+	return row.Branch, nil
+}
+
+func (b branchRepo) GetList(ctx context.Context, request models.GetListRequest) (models.BranchResponse, error) {
+	b.db.mu.Lock()
+	defer b.db.mu.Unlock()
+
+	rows := []branchRow{}
+	for _, row := range b.db.t.branches {
+		if !row.deleted && (request.Search == "" || ilike(row.Name, request.Search)) {
+			rows = append(rows, row)
+		}
+	}
+	sortBySeq(rows, func(row branchRow) int64 { return row.seq }, true)
+
+	page, err := paginate(rows, request.Page, request.Limit)
+	if err != nil {
+		return models.BranchResponse{}, err
+	}
+
+	branches := []models.Branch{}
+	for _, row := range page {
+		branches = append(branches, row.Branch)
+	}
+
+	return models.BranchResponse{
+		Branches: branches,
+		Count:    len(rows),
+	}, nil
+}
+
+func (b branchRepo) Update(ctx context.Context, branch models.UpdateBranch) (string, error) {
+	b.db.mu.Lock()
+	defer b.db.mu.Unlock()
+
+	if row, ok := b.db.t.branches[branch.ID]; ok {
+		row.Name = branch.Name
+		row.Address = branch.Address
+		row.UpdatedAt = time.Now()
+		b.db.t.branches[branch.ID] = row
+	}
+
+	return branch.ID, nil
+}
+
+func (b branchRepo) Delete(ctx context.Context, id string) error {
+	b.db.mu.Lock()
+	defer b.db.mu.Unlock()
+
+	if row, ok := b.db.t.branches[id]; ok {
+		row.deleted = true
+		b.db.t.branches[id] = row
+	}
+
+	return nil
+}
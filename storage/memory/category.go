@@ -0,0 +1,324 @@
+package memory
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"sell/api/models"
+	"sell/storage"
+	"sort"
+	"time"
+)
+
+type categoryRow struct {
+	models.Category
+	taxRateID string
+	seq       int64
+	deleted   bool
+}
+
+type categoryRepo struct {
+	db *Store
+}
+
+func NewCategoryRepo(db *Store) storage.ICategory {
+	return categoryRepo{db: db}
+}
+
+func (c categoryRepo) Create(ctx context.Context, category models.CreateCategory) (string, error) {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+
+	return c.db.insertCategory(category), nil
+}
+
+func (c categoryRepo) GetByID(ctx context.Context, id string) (models.Category, error) {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+
+	row, ok := c.db.t.categories[id]
+	if !ok || row.deleted {
+		return models.Category{}, pgx.ErrNoRows
+	}
+
+	return row.Category, nil
+}
+
+func (c categoryRepo) GetList(ctx context.Context, request models.GetListRequest) (models.CategoryResponse, error) {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+
+	rows := []categoryRow{}
+	for _, row := range c.db.t.categories {
+		if !row.deleted && (request.Search == "" || ilike(row.Name, "%"+request.Search+"%")) {
+			rows = append(rows, row)
+		}
+	}
+	sortBySeq(rows, func(row categoryRow) int64 { return row.seq }, true)
+
+	page, err := paginate(rows, request.Page, request.Limit)
+	if err != nil {
+		return models.CategoryResponse{}, err
+	}
+
+	categories := []models.Category{}
+	for _, row := range page {
+		categories = append(categories, row.Category)
+	}
+
+	return models.CategoryResponse{
+		Categories: categories,
+		Count:      len(rows),
+	}, nil
+}
+
+func (c categoryRepo) Update(ctx context.Context, category models.UpdateCategory) (string, error) {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+
+	if err := c.db.checkCategoryParent(category.ID, category.ParentID); err != nil {
+		return "", err
+	}
+
+	if row, ok := c.db.t.categories[category.ID]; ok {
+		row.Name = category.Name
+		row.ParentID = category.ParentID
+		if category.NameTranslations != nil {
+			row.NameTranslations = category.NameTranslations
+		}
+		row.UpdatedAt = time.Now()
+		c.db.t.categories[category.ID] = row
+	}
+
+	return category.ID, nil
+}
+
+// Move puts the category with its subtree under parentID, an empty parentID makes it a root category.
+func (c categoryRepo) Move(ctx context.Context, id, parentID string) error {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+
+	if err := c.db.checkCategoryParent(id, parentID); err != nil {
+		return err
+	}
+
+	row, ok := c.db.t.categories[id]
+	if !ok || row.deleted {
+		return pgx.ErrNoRows
+	}
+
+	row.ParentID = parentID
+	row.UpdatedAt = time.Now()
+	c.db.t.categories[id] = row
+
+	return nil
+}
+
+// Delete removes a category. With mode refuse a category with subcategories or products is kept,
+// cascade removes the whole subtree with its products and reparent hands the subcategories
+// and products over to the parent of the category.
+func (c categoryRepo) Delete(ctx context.Context, id, mode string) error {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+
+	category, ok := c.db.t.categories[id]
+	if !ok || category.deleted {
+		return pgx.ErrNoRows
+	}
+
+	now := time.Now()
+	switch mode {
+	case "cascade":
+		subtree := c.db.categorySubtree(id)
+		removed := map[string]bool{}
+		for productID, product := range c.db.t.products {
+			if !product.deleted && subtree[product.categoryID] {
+				removed[productID] = true
+			}
+		}
+		deleted := map[string]bool{}
+		for productID, product := range c.db.t.products {
+			if !product.deleted && (removed[productID] || removed[product.parentID]) {
+				product.deleted = true
+				c.db.t.products[productID] = product
+				deleted[productID] = true
+			}
+		}
+		c.db.deleteBarcodes(deleted)
+
+		for categoryID := range subtree {
+			row := c.db.t.categories[categoryID]
+			row.deleted = true
+			c.db.t.categories[categoryID] = row
+		}
+
+		return nil
+	case "reparent":
+		for categoryID, row := range c.db.t.categories {
+			if !row.deleted && row.ParentID == id {
+				row.ParentID = category.ParentID
+				row.UpdatedAt = now
+				c.db.t.categories[categoryID] = row
+			}
+		}
+
+		for productID, product := range c.db.t.products {
+			if !product.deleted && product.categoryID == id {
+				product.categoryID = category.ParentID
+				product.updatedAt = now
+				c.db.t.products[productID] = product
+			}
+		}
+	default:
+		for _, row := range c.db.t.categories {
+			if !row.deleted && row.ParentID == id {
+				return storage.ErrCategoryNotEmpty
+			}
+		}
+
+		for _, product := range c.db.t.products {
+			if !product.deleted && product.categoryID == id {
+				return storage.ErrCategoryNotEmpty
+			}
+		}
+	}
+
+	category.deleted = true
+	c.db.t.categories[id] = category
+
+	return nil
+}
+
+// Tree returns the root categories, or the category rootID, with all their subcategories.
+func (c categoryRepo) Tree(ctx context.Context, rootID string) ([]models.CategoryTree, error) {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+
+	var nodes []categoryRow
+	if rootID == "" {
+		for _, row := range c.db.t.categories {
+			if !row.deleted {
+				nodes = append(nodes, row)
+			}
+		}
+	} else {
+		for id := range c.db.categorySubtree(rootID) {
+			nodes = append(nodes, c.db.t.categories[id])
+		}
+		if len(nodes) == 0 {
+			return nil, pgx.ErrNoRows
+		}
+	}
+
+	sort.SliceStable(nodes, func(i, j int) bool {
+		if nodes[i].Name != nodes[j].Name {
+			return nodes[i].Name < nodes[j].Name
+		}
+		return nodes[i].seq < nodes[j].seq
+	})
+
+	productCount := map[string]int{}
+	for _, product := range c.db.t.products {
+		if !product.deleted && product.categoryID != "" {
+			productCount[product.categoryID]++
+		}
+	}
+
+	children := map[string][]categoryRow{}
+	for _, node := range nodes {
+		children[node.ParentID] = append(children[node.ParentID], node)
+	}
+
+	// visited stops at a parent loop which may be left in data saved before moves were checked
+	visited := map[string]bool{}
+	var build func(node categoryRow) models.CategoryTree
+	build = func(node categoryRow) models.CategoryTree {
+		visited[node.ID] = true
+		tree := models.CategoryTree{
+			ID:               node.ID,
+			Name:             node.Name,
+			NameTranslations: node.NameTranslations,
+			ParentID:         node.ParentID,
+			ProductCount:     productCount[node.ID],
+			Children:         []models.CategoryTree{},
+		}
+		tree.TotalProductCount = tree.ProductCount
+		for _, child := range children[node.ID] {
+			if visited[child.ID] {
+				continue
+			}
+			subtree := build(child)
+			tree.TotalProductCount += subtree.TotalProductCount
+			tree.Children = append(tree.Children, subtree)
+		}
+		return tree
+	}
+
+	roots := []models.CategoryTree{}
+	for _, node := range nodes {
+		if (rootID == "" && node.ParentID == "") || node.ID == rootID {
+			roots = append(roots, build(node))
+		}
+	}
+
+	return roots, nil
+}
+
+func (s *Store) insertCategory(category models.CreateCategory) string {
+	if category.NameTranslations == nil {
+		category.NameTranslations = map[string]string{}
+	}
+
+	id := uuid.New().String()
+	now := time.Now()
+	s.t.categories[id] = categoryRow{
+		Category: models.Category{
+			ID:               id,
+			Name:             category.Name,
+			NameTranslations: category.NameTranslations,
+			ParentID:         category.ParentID,
+			CreatedAt:        now,
+			UpdatedAt:        now,
+		},
+		seq: s.next(),
+	}
+
+	return id
+}
+
+// checkCategoryParent makes sure parentID exists and is not the category itself or one of its descendants.
+func (s *Store) checkCategoryParent(id, parentID string) error {
+	if parentID == "" {
+		return nil
+	}
+
+	if parent, ok := s.t.categories[parentID]; !ok || parent.deleted {
+		return pgx.ErrNoRows
+	}
+
+	if s.categorySubtree(id)[parentID] {
+		return storage.ErrCategoryCycle
+	}
+
+	return nil
+}
+
+// categorySubtree returns the ids of the category and all its descendants which are not deleted.
+func (s *Store) categorySubtree(id string) map[string]bool {
+	subtree := map[string]bool{}
+	if row, ok := s.t.categories[id]; !ok || row.deleted {
+		return subtree
+	}
+
+	subtree[id] = true
+	for queue := []string{id}; len(queue) > 0; queue = queue[1:] {
+		for childID, row := range s.t.categories {
+			if !row.deleted && row.ParentID == queue[0] && !subtree[childID] {
+				subtree[childID] = true
+				queue = append(queue, childID)
+			}
+		}
+	}
+
+	return subtree
+}
@@ -0,0 +1,132 @@
+package memory
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"sell/api/models"
+	"sell/storage"
+	"sort"
+	"time"
+)
+
+type exchangeRateRow struct {
+	models.ExchangeRate
+	seq     int64
+	deleted bool
+}
+
+type exchangeRateRepo struct {
+	db *Store
+}
+
+func NewExchangeRateRepo(db *Store) storage.IExchangeRateStorage {
+	return &exchangeRateRepo{db: db}
+}
+
+// Create schedules a rate of the currency to the base currency.
+// The rate is used from rate.StartsAt, which is now when it is not given.
+func (e *exchangeRateRepo) Create(ctx context.Context, rate models.CreateExchangeRate) (string, error) {
+	now := time.Now()
+	startsAt := now
+	if rate.StartsAt != "" {
+		var err error
+		if startsAt, err = parseTimestamp(rate.StartsAt); err != nil {
+			return "", err
+		}
+	}
+
+	e.db.mu.Lock()
+	defer e.db.mu.Unlock()
+
+	id := uuid.New().String()
+	e.db.t.exchangeRates[id] = exchangeRateRow{
+		ExchangeRate: models.ExchangeRate{
+			ID:        id,
+			Currency:  rate.Currency,
+			Rate:      rate.Rate,
+			StartsAt:  startsAt,
+			CreatedAt: now,
+		},
+		seq: e.db.next(),
+	}
+
+	return id, nil
+}
+
+// GetList returns the rate history with scheduled rates first, of one currency when request.Currency is given.
+func (e *exchangeRateRepo) GetList(ctx context.Context, request models.ExchangeRateGetListRequest) (models.ExchangeRatesResponse, error) {
+	e.db.mu.Lock()
+	defer e.db.mu.Unlock()
+
+	rows := e.db.exchangeRates(request.Currency, nil)
+
+	page, err := paginate(rows, request.Page, request.Limit)
+	if err != nil {
+		return models.ExchangeRatesResponse{}, err
+	}
+
+	rates := []models.ExchangeRate{}
+	for _, row := range page {
+		rates = append(rates, row.ExchangeRate)
+	}
+
+	return models.ExchangeRatesResponse{
+		ExchangeRates: rates,
+		Count:         len(rows),
+	}, nil
+}
+
+// Delete cancels a scheduled rate, rates which already started are history and stay.
+func (e *exchangeRateRepo) Delete(ctx context.Context, id string) error {
+	e.db.mu.Lock()
+	defer e.db.mu.Unlock()
+
+	row, ok := e.db.t.exchangeRates[id]
+	if !ok || row.deleted {
+		return pgx.ErrNoRows
+	}
+
+	if !row.StartsAt.After(time.Now()) {
+		return storage.ErrRateStarted
+	}
+
+	row.deleted = true
+	e.db.t.exchangeRates[id] = row
+
+	return nil
+}
+
+// GetEffective returns the rate of the currency at request.At, pgx.ErrNoRows when the currency had none yet.
+func (e *exchangeRateRepo) GetEffective(ctx context.Context, request models.EffectiveRateRequest) (models.ExchangeRate, error) {
+	e.db.mu.Lock()
+	defer e.db.mu.Unlock()
+
+	rows := e.db.exchangeRates(request.Currency, &request.At)
+	if request.Currency == "" || len(rows) == 0 {
+		return models.ExchangeRate{}, pgx.ErrNoRows
+	}
+
+	return rows[0].ExchangeRate, nil
+}
+
+// exchangeRates returns the rates of the currency, of all currencies when it is empty, which started by at
+// when it is given. The latest start comes first.
+func (s *Store) exchangeRates(currency string, at *time.Time) []exchangeRateRow {
+	rows := []exchangeRateRow{}
+	for _, row := range s.t.exchangeRates {
+		if row.deleted || currency != "" && row.Currency != currency || at != nil && row.StartsAt.After(*at) {
+			continue
+		}
+		rows = append(rows, row)
+	}
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		if !rows[i].StartsAt.Equal(rows[j].StartsAt) {
+			return rows[i].StartsAt.After(rows[j].StartsAt)
+		}
+		return rows[i].seq > rows[j].seq
+	})
+
+	return rows
+}
@@ -0,0 +1,217 @@
+package memory
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"sell/api/models"
+	"sell/storage"
+	"sort"
+	"time"
+)
+
+type fiscalEntryRow struct {
+	models.FiscalEntry
+	seq int64
+}
+
+type fiscalRepo struct {
+	db *Store
+}
+
+func NewFiscalRepo(db *Store) storage.IFiscalStorage {
+	return &fiscalRepo{db: db}
+}
+
+// Claim counts an attempt for every entry it returns and moves it request.Lease ahead, so another worker
+// skips the entries until the lease runs out.
+func (f *fiscalRepo) Claim(ctx context.Context, request models.ClaimFiscalEntries) ([]models.FiscalEntry, error) {
+	f.db.mu.Lock()
+	defer f.db.mu.Unlock()
+
+	now := time.Now()
+	rows := []fiscalEntryRow{}
+	for _, row := range f.db.t.fiscalOutbox {
+		if row.Status != "pending" || row.NextAttemptAt.After(now) ||
+			request.ID != "" && row.ID != request.ID ||
+			request.DocumentID != "" && (row.DocumentType != request.DocumentType || row.DocumentID != request.DocumentID) {
+			continue
+		}
+		rows = append(rows, row)
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		if !rows[i].NextAttemptAt.Equal(rows[j].NextAttemptAt) {
+			return rows[i].NextAttemptAt.Before(rows[j].NextAttemptAt)
+		}
+		return rows[i].seq < rows[j].seq
+	})
+
+	rows, err := paginate(rows, 1, request.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := []models.FiscalEntry{}
+	for _, row := range rows {
+		row.Attempts++
+		row.NextAttemptAt = now.Add(time.Duration(int64(request.Lease.Seconds())) * time.Second)
+		f.db.t.fiscalOutbox[row.ID] = row
+		entries = append(entries, row.FiscalEntry)
+	}
+
+	return entries, nil
+}
+
+// Done marks the entry sent and saves the sign and the number of the fiscal module on its sale or refund.
+func (f *fiscalRepo) Done(ctx context.Context, result models.FiscalResult) error {
+	f.db.mu.Lock()
+	defer f.db.mu.Unlock()
+
+	row, ok := f.db.t.fiscalOutbox[result.EntryID]
+	if !ok {
+		return pgx.ErrNoRows
+	}
+
+	now := time.Now()
+	row.Status = "sent"
+	row.LastError = ""
+	row.SentAt = &now
+	f.db.t.fiscalOutbox[row.ID] = row
+
+	if row.DocumentType == "refund" {
+		if refund, ok := f.db.t.refunds[row.DocumentID]; ok {
+			refund.FiscalSign = result.Sign
+			refund.FiscalNumber = result.Number
+			refund.FiscalizedAt = &now
+			refund.updatedAt = now
+			f.db.t.refunds[row.DocumentID] = refund
+		}
+		return nil
+	}
+
+	if sale, ok := f.db.t.sales[row.DocumentID]; ok {
+		sale.FiscalSign = result.Sign
+		sale.FiscalNumber = result.Number
+		sale.FiscalizedAt = &now
+		sale.UpdatedAt = now
+		f.db.t.sales[row.DocumentID] = sale
+	}
+
+	return nil
+}
+
+// Fail records the error of an attempt, the entry is failed for good when failure.RetryAt is nil.
+func (f *fiscalRepo) Fail(ctx context.Context, failure models.FiscalFailure) error {
+	f.db.mu.Lock()
+	defer f.db.mu.Unlock()
+
+	row, ok := f.db.t.fiscalOutbox[failure.EntryID]
+	if !ok {
+		return pgx.ErrNoRows
+	}
+
+	row.LastError = failure.Error
+	if failure.RetryAt != nil {
+		row.NextAttemptAt = *failure.RetryAt
+	} else {
+		row.Status = "failed"
+	}
+	f.db.t.fiscalOutbox[row.ID] = row
+
+	return nil
+}
+
+// Postpone moves the entry to failure.RetryAt, or makes it due now, and gives back the attempt Claim counted:
+// the entry could not be sent yet but nothing failed.
+func (f *fiscalRepo) Postpone(ctx context.Context, failure models.FiscalFailure) error {
+	f.db.mu.Lock()
+	defer f.db.mu.Unlock()
+
+	row, ok := f.db.t.fiscalOutbox[failure.EntryID]
+	if !ok {
+		return pgx.ErrNoRows
+	}
+
+	row.Attempts = max(row.Attempts-1, 0)
+	row.LastError = failure.Error
+	row.NextAttemptAt = time.Now()
+	if failure.RetryAt != nil {
+		row.NextAttemptAt = *failure.RetryAt
+	}
+	f.db.t.fiscalOutbox[row.ID] = row
+
+	return nil
+}
+
+// Retry makes a failed or waiting entry due now with all its attempts again.
+func (f *fiscalRepo) Retry(ctx context.Context, id string) error {
+	f.db.mu.Lock()
+	defer f.db.mu.Unlock()
+
+	row, ok := f.db.t.fiscalOutbox[id]
+	if !ok {
+		return pgx.ErrNoRows
+	}
+	if row.Status == "sent" {
+		return storage.ErrFiscalSent
+	}
+
+	row.Status = "pending"
+	row.Attempts = 0
+	row.NextAttemptAt = time.Now()
+	f.db.t.fiscalOutbox[id] = row
+
+	return nil
+}
+
+// GetList returns the entries, latest first, with one status when request.Status is given.
+func (f *fiscalRepo) GetList(ctx context.Context, request models.FiscalEntryGetListRequest) (models.FiscalEntriesResponse, error) {
+	f.db.mu.Lock()
+	defer f.db.mu.Unlock()
+
+	rows := []fiscalEntryRow{}
+	for _, row := range f.db.t.fiscalOutbox {
+		if request.Status == "" || row.Status == request.Status {
+			rows = append(rows, row)
+		}
+	}
+	sortBySeq(rows, func(row fiscalEntryRow) int64 { return row.seq }, true)
+
+	page, err := paginate(rows, request.Page, request.Limit)
+	if err != nil {
+		return models.FiscalEntriesResponse{}, err
+	}
+
+	entries := []models.FiscalEntry{}
+	for _, row := range page {
+		entries = append(entries, row.FiscalEntry)
+	}
+
+	return models.FiscalEntriesResponse{
+		Entries: entries,
+		Count:   len(rows),
+	}, nil
+}
+
+// enqueueFiscal puts the document into the fiscal outbox unless it is there already.
+func (s *Store) enqueueFiscal(documentType, documentID string) {
+	for _, row := range s.t.fiscalOutbox {
+		if row.DocumentType == documentType && row.DocumentID == documentID {
+			return
+		}
+	}
+
+	id := uuid.New().String()
+	now := time.Now()
+	s.t.fiscalOutbox[id] = fiscalEntryRow{
+		FiscalEntry: models.FiscalEntry{
+			ID:            id,
+			DocumentType:  documentType,
+			DocumentID:    documentID,
+			Status:        "pending",
+			NextAttemptAt: now,
+			CreatedAt:     now,
+		},
+		seq: s.next(),
+	}
+}
@@ -0,0 +1,219 @@
+package memory
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"sell/api/models"
+	"sell/pkg/money"
+	"sell/storage"
+	"time"
+)
+
+type incomeRow struct {
+	models.Income
+	seq     int64
+	deleted bool
+}
+
+type incomeRepo struct {
+	db *Store
+}
+
+func NewIncomeRepo(db *Store) storage.IIncomeStorage {
+	return &incomeRepo{db: db}
+}
+
+func (i *incomeRepo) Create(ctx context.Context, income models.CreateIncome) (string, error) {
+	i.db.mu.Lock()
+	defer i.db.mu.Unlock()
+
+	return i.db.insertIncome(models.Income{
+		BranchID:     income.BranchID,
+		SupplierID:   income.SupplierID,
+		Currency:     income.Currency,
+		ExchangeRate: income.ExchangeRate,
+	}), nil
+}
+
+func (i *incomeRepo) GetByID(ctx context.Context, id string) (models.Income, error) {
+	i.db.mu.Lock()
+	defer i.db.mu.Unlock()
+
+	row, ok := i.db.t.incomes[id]
+	if !ok || row.deleted {
+		return models.Income{}, pgx.ErrNoRows
+	}
+
+	return row.income()
+}
+
+func (i *incomeRepo) GetList(ctx context.Context, request models.IncomeGetListRequest) (models.IncomeResponse, error) {
+	i.db.mu.Lock()
+	defer i.db.mu.Unlock()
+
+	rows := []incomeRow{}
+	for _, row := range i.db.t.incomes {
+		if row.deleted ||
+			request.BranchID != "" && row.BranchID != request.BranchID ||
+			request.SupplierID != "" && row.SupplierID != request.SupplierID ||
+			request.Status != "" && row.Status != request.Status {
+			continue
+		}
+		rows = append(rows, row)
+	}
+	sortBySeq(rows, func(row incomeRow) int64 { return row.seq }, true)
+
+	page, err := paginate(rows, request.Page, request.Limit)
+	if err != nil {
+		return models.IncomeResponse{}, err
+	}
+
+	var incomes []models.Income
+	for _, row := range page {
+		income, err := row.income()
+		if err != nil {
+			return models.IncomeResponse{}, err
+		}
+		incomes = append(incomes, income)
+	}
+
+	return models.IncomeResponse{
+		Incomes: incomes,
+		Count:   len(rows),
+	}, nil
+}
+
+func (i *incomeRepo) Update(ctx context.Context, income models.UpdateIncome) (string, error) {
+	i.db.mu.Lock()
+	defer i.db.mu.Unlock()
+
+	row, ok := i.db.t.incomes[income.ID]
+	if !ok || row.deleted || row.Status != "in_process" {
+		return "", storage.ErrIncomeFinished
+	}
+
+	row.BranchID = income.BranchID
+	row.SupplierID = income.SupplierID
+	row.Currency = income.Currency
+	row.ExchangeRate = income.ExchangeRate
+	row.UpdatedAt = time.Now()
+	i.db.t.incomes[income.ID] = row
+
+	return income.ID, nil
+}
+
+func (i *incomeRepo) Delete(ctx context.Context, id string) error {
+	i.db.mu.Lock()
+	defer i.db.mu.Unlock()
+
+	row, ok := i.db.t.incomes[id]
+	if !ok || row.Status != "in_process" {
+		return storage.ErrIncomeFinished
+	}
+
+	row.deleted = true
+	i.db.t.incomes[id] = row
+
+	return nil
+}
+
+// Finish adds every line of the income to the income branch repository and locks the income.
+// Finishing an already finished income changes nothing, so every line is added exactly once.
+func (i *incomeRepo) Finish(ctx context.Context, id string) error {
+	return i.db.tx(func() error {
+		row, ok := i.db.t.incomes[id]
+		if !ok || row.deleted {
+			return pgx.ErrNoRows
+		}
+
+		if row.Status == "finished" {
+			return nil
+		}
+
+		incomeProducts := i.db.incomeProducts(id)
+		if len(incomeProducts) == 0 {
+			return storage.ErrEmptyIncome
+		}
+
+		for _, incomeProduct := range incomeProducts {
+			if err := i.db.addIncomeStock(row.BranchID, row.ExchangeRate, incomeProduct.IncomeProduct); err != nil {
+				return err
+			}
+		}
+
+		if err := i.db.updateIncomePrice(id); err != nil {
+			return err
+		}
+
+		row = i.db.t.incomes[id]
+		now := time.Now()
+		row.Status = "finished"
+		row.FinishedAt = &now
+		row.UpdatedAt = now
+		i.db.t.incomes[id] = row
+
+		return nil
+	})
+}
+
+// insertIncome adds an income in process with no lines yet and returns its id.
+func (s *Store) insertIncome(income models.Income) string {
+	now := time.Now()
+	income.ID = uuid.New().String()
+	income.Status = "in_process"
+	income.Price = 0
+	income.CreatedAt = now
+	income.UpdatedAt = now
+	s.t.incomes[income.ID] = incomeRow{Income: income, seq: s.next()}
+
+	return income.ID
+}
+
+// openIncome fails with storage.ErrIncomeFinished when the income can not be changed anymore.
+func (s *Store) openIncome(id string) error {
+	row, ok := s.t.incomes[id]
+	if !ok || row.deleted {
+		return pgx.ErrNoRows
+	}
+
+	if row.Status != "in_process" {
+		return storage.ErrIncomeFinished
+	}
+
+	return nil
+}
+
+// updateIncomePrice sets the income price to the sum of price x count of its lines, every line rounded to the hundredth.
+func (s *Store) updateIncomePrice(id string) error {
+	row, ok := s.t.incomes[id]
+	if !ok {
+		return nil
+	}
+
+	var price money.Money
+	for _, incomeProduct := range s.incomeProducts(id) {
+		amount, err := incomeProduct.Price.Mul(incomeProduct.Count)
+		if err != nil {
+			return err
+		}
+		price += amount
+	}
+
+	row.Price = price
+	row.UpdatedAt = time.Now()
+	s.t.incomes[id] = row
+
+	return nil
+}
+
+// income returns the income with its price in the base currency.
+func (row incomeRow) income() (models.Income, error) {
+	income := row.Income
+	basePrice, err := income.Price.Convert(income.ExchangeRate)
+	if err != nil {
+		return models.Income{}, err
+	}
+	income.BasePrice = basePrice
+	return income, nil
+}
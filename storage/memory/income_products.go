@@ -0,0 +1,303 @@
+package memory
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"sell/api/models"
+	"sell/pkg/money"
+	"sell/storage"
+	"time"
+)
+
+// timestampText is how postgres writes a timestamp cast to text.
+const timestampText = "2006-01-02 15:04:05.999999"
+
+type incomeProductRow struct {
+	models.IncomeProduct
+	seq     int64
+	deleted bool
+}
+
+type incomeProductRepo struct {
+	db *Store
+}
+
+func NewIncomeProductsRepo(db *Store) storage.IIncomeProductsStorage {
+	return incomeProductRepo{db: db}
+}
+
+// Create adds a line to an income which is not finished yet and recalculates the income price.
+// Stock is not changed until the income is finished.
+func (i incomeProductRepo) Create(ctx context.Context, incomeProduct models.CreateIncomeProduct) (string, error) {
+	if incomeProduct.ExpiryDate != "" {
+		if _, err := parseDate(incomeProduct.ExpiryDate); err != nil {
+			return "", err
+		}
+	}
+
+	var id string
+	err := i.db.tx(func() error {
+		if err := i.db.openIncome(incomeProduct.IncomeID); err != nil {
+			return err
+		}
+
+		unitQuantity, err := i.db.productUnitQuantity(incomeProduct.ProductID, incomeProduct.ProductUnitID)
+		if err != nil {
+			return err
+		}
+
+		id = i.db.insertIncomeProduct(models.IncomeProduct{
+			IncomeID:      incomeProduct.IncomeID,
+			ProductID:     incomeProduct.ProductID,
+			ProductUnitID: incomeProduct.ProductUnitID,
+			UnitQuantity:  unitQuantity,
+			Price:         incomeProduct.Price,
+			Count:         incomeProduct.Count,
+			BatchNumber:   incomeProduct.BatchNumber,
+			ExpiryDate:    incomeProduct.ExpiryDate,
+		})
+
+		return i.db.updateIncomePrice(incomeProduct.IncomeID)
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return id, nil
+}
+
+func (i incomeProductRepo) GetByID(ctx context.Context, id string) (models.IncomeProduct, error) {
+	i.db.mu.Lock()
+	defer i.db.mu.Unlock()
+
+	row, ok := i.db.t.incomeProducts[id]
+	if !ok || row.deleted {
+		return models.IncomeProduct{}, pgx.ErrNoRows
+	}
+
+	return row.IncomeProduct, nil
+}
+
+func (i incomeProductRepo) GetList(ctx context.Context, request models.IncomeProductRequest) (models.IncomeProductsResponse, error) {
+	i.db.mu.Lock()
+	defer i.db.mu.Unlock()
+
+	rows := []incomeProductRow{}
+	for _, row := range i.db.t.incomeProducts {
+		if row.deleted ||
+			request.ProductID != "" && row.ProductID != request.ProductID ||
+			request.IncomeID != "" && row.IncomeID != request.IncomeID {
+			continue
+		}
+		rows = append(rows, row)
+	}
+	sortBySeq(rows, func(row incomeProductRow) int64 { return row.seq }, true)
+
+	page, err := paginate(rows, request.Page, request.Limit)
+	if err != nil {
+		return models.IncomeProductsResponse{}, err
+	}
+
+	var incomeProducts []models.IncomeProduct
+	for _, row := range page {
+		incomeProducts = append(incomeProducts, row.IncomeProduct)
+	}
+
+	return models.IncomeProductsResponse{
+		IncomeProducts:        incomeProducts,
+		CountOfIncomeProducts: len(rows),
+	}, nil
+}
+
+func (i incomeProductRepo) Update(ctx context.Context, income models.UpdateIncomeProduct) (string, error) {
+	if income.ExpiryDate != "" {
+		if _, err := parseDate(income.ExpiryDate); err != nil {
+			return "", err
+		}
+	}
+
+	err := i.db.tx(func() error {
+		row, ok := i.db.t.incomeProducts[income.ID]
+		if !ok || row.deleted {
+			return pgx.ErrNoRows
+		}
+		oldIncomeID := row.IncomeID
+
+		if err := i.db.openIncome(oldIncomeID); err != nil {
+			return err
+		}
+
+		if income.IncomeID != oldIncomeID {
+			if err := i.db.openIncome(income.IncomeID); err != nil {
+				return err
+			}
+		}
+
+		unitQuantity, err := i.db.productUnitQuantity(income.ProductID, income.ProductUnitID)
+		if err != nil {
+			return err
+		}
+
+		row.IncomeID = income.IncomeID
+		row.ProductID = income.ProductID
+		row.ProductUnitID = income.ProductUnitID
+		row.UnitQuantity = unitQuantity
+		row.Price = income.Price
+		row.Count = income.Count
+		row.BatchNumber = income.BatchNumber
+		row.ExpiryDate = income.ExpiryDate
+		row.UpdatedAt = time.Now().Format(timestampText)
+		i.db.t.incomeProducts[income.ID] = row
+
+		if err := i.db.updateIncomePrice(oldIncomeID); err != nil {
+			return err
+		}
+		if income.IncomeID != oldIncomeID {
+			return i.db.updateIncomePrice(income.IncomeID)
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return income.ID, nil
+}
+
+func (i incomeProductRepo) Delete(ctx context.Context, id string) error {
+	i.db.mu.Lock()
+	defer i.db.mu.Unlock()
+
+	row, ok := i.db.t.incomeProducts[id]
+	if !ok || row.deleted {
+		return pgx.ErrNoRows
+	}
+
+	if err := i.db.openIncome(row.IncomeID); err != nil {
+		return err
+	}
+
+	row.deleted = true
+	i.db.t.incomeProducts[id] = row
+
+	// less lines can only lower the price
+	return i.db.updateIncomePrice(row.IncomeID)
+}
+
+func (s *Store) insertIncomeProduct(incomeProduct models.IncomeProduct) string {
+	now := time.Now().Format(timestampText)
+	incomeProduct.ID = uuid.New().String()
+	incomeProduct.CreatedAt = now
+	incomeProduct.UpdatedAt = now
+	s.t.incomeProducts[incomeProduct.ID] = incomeProductRow{IncomeProduct: incomeProduct, seq: s.next()}
+
+	return incomeProduct.ID
+}
+
+// incomeProducts returns the live lines of the income in the order they were added.
+func (s *Store) incomeProducts(incomeID string) []incomeProductRow {
+	rows := []incomeProductRow{}
+	for _, row := range s.t.incomeProducts {
+		if row.IncomeID == incomeID && !row.deleted {
+			rows = append(rows, row)
+		}
+	}
+	sortBySeq(rows, func(row incomeProductRow) int64 { return row.seq }, false)
+
+	return rows
+}
+
+// addIncomeStock puts a received income line into the branch repository, creating the repository
+// row when the branch has none for the product, and records the plus movement and the batch.
+func (s *Store) addIncomeStock(branchID string, rate money.Rate, incomeProduct models.IncomeProduct) error {
+	quantity := incomeProduct.Count
+	if incomeProduct.UnitQuantity > 0 {
+		quantity = roundQuantity(incomeProduct.Count * incomeProduct.UnitQuantity)
+	}
+
+	price, err := incomeProduct.Price.Mul(incomeProduct.Count)
+	if err != nil {
+		return err
+	}
+	if price, err = price.Convert(rate); err != nil {
+		return err
+	}
+
+	s.addStock(branchID, incomeProduct.ProductID, quantity)
+
+	s.insertMovement(models.CreateRepositoryTransaction{
+		BranchID:                  branchID,
+		ProductID:                 incomeProduct.ProductID,
+		RepositoryTransactionType: "plus",
+		SourceType:                "income",
+		SourceID:                  incomeProduct.IncomeID,
+		Price:                     price,
+		Quantity:                  quantity,
+	})
+
+	s.insertBatch(models.CreateBatch{
+		BranchID:        branchID,
+		ProductID:       incomeProduct.ProductID,
+		IncomeProductID: incomeProduct.ID,
+		BatchNumber:     incomeProduct.BatchNumber,
+		ExpiryDate:      incomeProduct.ExpiryDate,
+		Count:           quantity,
+	})
+
+	return nil
+}
+
+// addStock adds quantity to the repository of the product in the branch, a repository is created
+// when the branch has none.
+func (s *Store) addStock(branchID, productID string, quantity float64) {
+	if row, ok := s.firstRepository(branchID, productID); ok {
+		row.Count += quantity
+		row.UpdatedAt = time.Now()
+		s.t.repositories[row.ID] = row
+		return
+	}
+
+	id := uuid.New().String()
+	now := time.Now()
+	s.t.repositories[id] = repositoryRow{
+		Repository: models.Repository{
+			ID:        id,
+			ProductID: productID,
+			BranchID:  branchID,
+			Count:     quantity,
+			CreatedAt: now,
+			UpdatedAt: now,
+		},
+		seq: s.next(),
+	}
+}
+
+// firstRepository returns the oldest live repository of the product in the branch.
+func (s *Store) firstRepository(branchID, productID string) (repositoryRow, bool) {
+	var (
+		first repositoryRow
+		found bool
+	)
+	for _, row := range s.t.repositories {
+		if row.BranchID == branchID && row.ProductID == productID && !row.deleted && (!found || row.seq < first.seq) {
+			first, found = row, true
+		}
+	}
+
+	return first, found
+}
+
+// productUnitQuantity returns how many units of the product the pack holds, 1 when no pack is given.
+func (s *Store) productUnitQuantity(productID, productUnitID string) (float64, error) {
+	if productUnitID == "" {
+		return 1, nil
+	}
+
+	row, ok := s.t.productUnits[productUnitID]
+	if !ok || row.ProductID != productID || row.deleted {
+		return 0, pgx.ErrNoRows
+	}
+
+	return row.Quantity, nil
+}
@@ -0,0 +1,309 @@
+// Package memory keeps storage.IStorage in memory for tests. It behaves like storage/postgres: rows are soft
+// deleted, lists are paged and searched the same way, missing rows are pgx.ErrNoRows and unique indexes are
+// checked, so the handlers and the service see no difference.
+package memory
+
+import (
+	"github.com/jackc/pgx/v5/pgconn"
+	"maps"
+	"math"
+	"regexp"
+	"sell/storage"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+type Store struct {
+	mu  sync.Mutex
+	seq int64
+	t   tables
+}
+
+// tables are the rows of every table by id. Rows are values, a repo changes a row by putting it back,
+// so a copy of the maps is a snapshot of the store.
+type tables struct {
+	staffTariffs          map[string]staffTariffRow
+	staffs                map[string]staffRow
+	branches              map[string]branchRow
+	categories            map[string]categoryRow
+	products              map[string]productRow
+	productBarcodes       map[string]productBarcodeRow
+	productUnits          map[string]productUnitRow
+	productImages         map[string]productImageRow
+	productPrices         map[string]productPriceRow
+	barcodeFormats        map[string]barcodeFormatRow
+	repositories          map[string]repositoryRow
+	repositoryMovements   map[string]repositoryTransactionRow
+	baskets               map[string]basketRow
+	sales                 map[string]saleRow
+	salePayments          map[string]salePaymentRow
+	transactions          map[string]transactionRow
+	incomes               map[string]incomeRow
+	incomeProducts        map[string]incomeProductRow
+	writeOffs             map[string]writeOffRow
+	writeOffProducts      map[string]writeOffProductRow
+	batches               map[string]batchRow
+	suppliers             map[string]supplierRow
+	supplierPayments      map[string]supplierPaymentRow
+	purchaseOrders        map[string]purchaseOrderRow
+	purchaseOrderProducts map[string]purchaseOrderProductRow
+	exchangeRates         map[string]exchangeRateRow
+	taxRates              map[string]taxRateRow
+	refunds               map[string]refundRow
+	refundProducts        map[string]refundProductRow
+	fiscalOutbox          map[string]fiscalEntryRow
+}
+
+func New() storage.IStorage {
+	return &Store{t: tables{}.clone()}
+}
+
+func (t tables) clone() tables {
+	return tables{
+		staffTariffs:          cloneTable(t.staffTariffs),
+		staffs:                cloneTable(t.staffs),
+		branches:              cloneTable(t.branches),
+		categories:            cloneTable(t.categories),
+		products:              cloneTable(t.products),
+		productBarcodes:       cloneTable(t.productBarcodes),
+		productUnits:          cloneTable(t.productUnits),
+		productImages:         cloneTable(t.productImages),
+		productPrices:         cloneTable(t.productPrices),
+		barcodeFormats:        cloneTable(t.barcodeFormats),
+		repositories:          cloneTable(t.repositories),
+		repositoryMovements:   cloneTable(t.repositoryMovements),
+		baskets:               cloneTable(t.baskets),
+		sales:                 cloneTable(t.sales),
+		salePayments:          cloneTable(t.salePayments),
+		transactions:          cloneTable(t.transactions),
+		incomes:               cloneTable(t.incomes),
+		incomeProducts:        cloneTable(t.incomeProducts),
+		writeOffs:             cloneTable(t.writeOffs),
+		writeOffProducts:      cloneTable(t.writeOffProducts),
+		batches:               cloneTable(t.batches),
+		suppliers:             cloneTable(t.suppliers),
+		supplierPayments:      cloneTable(t.supplierPayments),
+		purchaseOrders:        cloneTable(t.purchaseOrders),
+		purchaseOrderProducts: cloneTable(t.purchaseOrderProducts),
+		exchangeRates:         cloneTable(t.exchangeRates),
+		taxRates:              cloneTable(t.taxRates),
+		refunds:               cloneTable(t.refunds),
+		refundProducts:        cloneTable(t.refundProducts),
+		fiscalOutbox:          cloneTable(t.fiscalOutbox),
+	}
+}
+
+func cloneTable[T any](table map[string]T) map[string]T {
+	if table == nil {
+		return map[string]T{}
+	}
+	return maps.Clone(table)
+}
+
+// tx runs fn alone on the store, what fn changed is undone when it fails like a rolled back transaction.
+func (s *Store) tx(fn func() error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	saved := s.t.clone()
+	if err := fn(); err != nil {
+		s.t = saved
+		return err
+	}
+
+	return nil
+}
+
+// next numbers rows in the order they are written, it breaks ties of created_at which postgres breaks by chance.
+func (s *Store) next() int64 {
+	s.seq++
+	return s.seq
+}
+
+func (s *Store) Close() {}
+
+func (s *Store) StaffTariff() storage.IStaffTariffRepo {
+	return NewStaffTariffRepo(s)
+}
+
+func (s *Store) Category() storage.ICategory {
+	return NewCategoryRepo(s)
+}
+
+func (s *Store) Product() storage.IProducts {
+	return NewProductRepo(s)
+}
+
+func (s *Store) Branch() storage.IBranchStorage {
+	return NewBranchRepo(s)
+}
+
+func (s *Store) Sale() storage.ISaleStorage {
+	return NewSaleRepo(s)
+}
+
+func (s *Store) Transaction() storage.ITransactionStorage {
+	return NewTransactionRepo(s)
+}
+
+func (s *Store) Staff() storage.IStaffRepo {
+	return NewStaffRepo(s)
+}
+
+func (s *Store) Repository() storage.IRepositoryRepo {
+	return NewRepositoryRepo(s)
+}
+
+func (s *Store) Basket() storage.IBasketRepo {
+	return NewBasketRepo(s)
+}
+
+func (s *Store) RTransaction() storage.IRepositoryTransactionRepo {
+	return NewRepositoryTransactionRepo(s)
+}
+
+func (s *Store) Income() storage.IIncomeStorage {
+	return NewIncomeRepo(s)
+}
+
+func (s *Store) IncomeProducts() storage.IIncomeProductsStorage {
+	return NewIncomeProductsRepo(s)
+}
+
+func (s *Store) WriteOff() storage.IWriteOffStorage {
+	return NewWriteOffRepo(s)
+}
+
+func (s *Store) Batch() storage.IBatchStorage {
+	return NewBatchRepo(s)
+}
+
+func (s *Store) Supplier() storage.ISupplierStorage {
+	return NewSupplierRepo(s)
+}
+
+func (s *Store) PurchaseOrder() storage.IPurchaseOrderStorage {
+	return NewPurchaseOrderRepo(s)
+}
+
+func (s *Store) BarcodeFormat() storage.IBarcodeFormatStorage {
+	return NewBarcodeFormatRepo(s)
+}
+
+func (s *Store) ProductPrice() storage.IProductPriceStorage {
+	return NewProductPriceRepo(s)
+}
+
+func (s *Store) ExchangeRate() storage.IExchangeRateStorage {
+	return NewExchangeRateRepo(s)
+}
+
+func (s *Store) TaxRate() storage.ITaxRateStorage {
+	return NewTaxRateRepo(s)
+}
+
+func (s *Store) Refund() storage.IRefundStorage {
+	return NewRefundRepo(s)
+}
+
+func (s *Store) Fiscal() storage.IFiscalStorage {
+	return NewFiscalRepo(s)
+}
+
+// paginate returns the page of rows LIMIT limit OFFSET (page-1)*limit returns, postgres refuses negative values.
+func paginate[T any](rows []T, page, limit int) ([]T, error) {
+	offset := (page - 1) * limit
+	if limit < 0 {
+		return nil, &pgconn.PgError{Code: "2201W", Message: "LIMIT must not be negative"}
+	}
+	if offset < 0 {
+		return nil, &pgconn.PgError{Code: "2201X", Message: "OFFSET must not be negative"}
+	}
+
+	if offset >= len(rows) {
+		return rows[:0], nil
+	}
+	return rows[offset:min(offset+limit, len(rows))], nil
+}
+
+// sortBySeq orders rows as they were written, or latest first when desc is true.
+func sortBySeq[T any](rows []T, seq func(T) int64, desc bool) {
+	sort.SliceStable(rows, func(i, j int) bool {
+		if desc {
+			return seq(rows[i]) > seq(rows[j])
+		}
+		return seq(rows[i]) < seq(rows[j])
+	})
+}
+
+var likePatterns sync.Map
+
+// ilike tells whether value matches the ILIKE pattern: % is any text, _ is one character and \ escapes them.
+func ilike(value, pattern string) bool {
+	re, ok := likePatterns.Load(pattern)
+	if !ok {
+		expr := strings.Builder{}
+		expr.WriteString(`(?is)^`)
+		runes := []rune(pattern)
+		for i := 0; i < len(runes); i++ {
+			switch r := runes[i]; {
+			case r == '\\' && i+1 < len(runes):
+				i++
+				expr.WriteString(regexp.QuoteMeta(string(runes[i])))
+			case r == '%':
+				expr.WriteString(`.*`)
+			case r == '_':
+				expr.WriteString(`.`)
+			default:
+				expr.WriteString(regexp.QuoteMeta(string(r)))
+			}
+		}
+		expr.WriteString(`$`)
+		re, _ = likePatterns.LoadOrStore(pattern, regexp.MustCompile(expr.String()))
+	}
+
+	return re.(*regexp.Regexp).MatchString(value)
+}
+
+// roundQuantity rounds a quantity to the precision of numeric(14, 3) columns.
+func roundQuantity(quantity float64) float64 {
+	return math.Round(quantity*1000) / 1000
+}
+
+// uniqueViolation is the error postgres returns when a row breaks the unique index.
+func uniqueViolation(index string) error {
+	return &pgconn.PgError{
+		Code:           "23505",
+		Message:        `duplicate key value violates unique constraint "` + index + `"`,
+		ConstraintName: index,
+	}
+}
+
+// parseTimestamp reads a timestamp like postgres casts text to timestamp, a date alone is its midnight.
+func parseTimestamp(value string) (time.Time, error) {
+	for _, layout := range []string{"2006-01-02 15:04:05.999999", "2006-01-02T15:04:05.999999", "2006-01-02"} {
+		if at, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return at, nil
+		}
+	}
+
+	return time.Time{}, &pgconn.PgError{Code: "22007", Message: `invalid input syntax for type timestamp: "` + value + `"`}
+}
+
+// parseDate reads a date column, an invalid date is an error like the cast in postgres.
+func parseDate(value string) (time.Time, error) {
+	at, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return time.Time{}, &pgconn.PgError{Code: "22007", Message: `invalid input syntax for type date: "` + value + `"`}
+	}
+
+	return at, nil
+}
+
+// dateOf truncates a moment to its day, like ::date.
+func dateOf(at time.Time) time.Time {
+	year, month, day := at.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, at.Location())
+}
@@ -0,0 +1,11 @@
+package memory_test
+
+import (
+	"sell/storage/memory"
+	"sell/storage/storagetest"
+	"testing"
+)
+
+func TestStorage(t *testing.T) {
+	storagetest.Run(t, memory.New())
+}
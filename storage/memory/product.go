@@ -0,0 +1,667 @@
+package memory
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"maps"
+	"math"
+	"sell/api/models"
+	"sell/pkg/money"
+	"sell/storage"
+	"sort"
+	"time"
+)
+
+type productRow struct {
+	id               string
+	name             string
+	nameTranslations map[string]string
+	price            *money.Money
+	barcode          string
+	unit             string
+	precision        int
+	categoryID       string
+	parentID         string
+	attributes       map[string]string
+	taxRateID        string
+	createdAt        time.Time
+	updatedAt        time.Time
+	seq              int64
+	deleted          bool
+}
+
+type productBarcodeRow struct {
+	models.ProductBarcode
+	seq     int64
+	deleted bool
+}
+
+type productUnitRow struct {
+	models.ProductUnit
+	seq     int64
+	deleted bool
+}
+
+type productRepo struct {
+	db *Store
+}
+
+func NewProductRepo(db *Store) storage.IProducts {
+	return productRepo{db: db}
+}
+
+// Create inserts the product with its main barcode and the additional pack barcodes.
+func (p productRepo) Create(ctx context.Context, product models.CreateProduct) (string, error) {
+	id := uuid.New().String()
+
+	err := p.db.tx(func() error {
+		if product.Barcode != "" && p.db.productBarcodeTaken(product.Barcode) {
+			return storage.ErrBarcodeExists
+		}
+
+		precision := 0
+		if product.Precision != nil {
+			precision = *product.Precision
+		}
+		if product.NameTranslations == nil {
+			product.NameTranslations = map[string]string{}
+		}
+
+		price := product.Price
+		now := time.Now()
+		p.db.t.products[id] = productRow{
+			id:               id,
+			name:             product.Name,
+			nameTranslations: product.NameTranslations,
+			price:            &price,
+			barcode:          product.Barcode,
+			unit:             product.Unit,
+			precision:        precision,
+			categoryID:       product.CategoryID,
+			attributes:       map[string]string{},
+			createdAt:        now,
+			updatedAt:        now,
+			seq:              p.db.next(),
+		}
+
+		p.db.insertProductPrice(id, &price)
+
+		barcodes := product.Barcodes
+		if product.Barcode != "" {
+			barcodes = append([]models.CreateProductBarcode{{Barcode: product.Barcode, Quantity: 1}}, barcodes...)
+		}
+
+		for _, barcode := range barcodes {
+			barcode.ProductID = id
+			if err := p.db.insertProductBarcode(barcode); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return id, nil
+}
+
+func (p productRepo) GetByID(ctx context.Context, id string) (models.Product, error) {
+	p.db.mu.Lock()
+	defer p.db.mu.Unlock()
+
+	row, ok := p.db.t.products[id]
+	if !ok || row.deleted {
+		return models.Product{}, pgx.ErrNoRows
+	}
+	product := p.db.product(row)
+
+	product.Barcodes = []models.ProductBarcode{}
+	for _, barcode := range p.db.productBarcodes(id) {
+		product.Barcodes = append(product.Barcodes, barcode.ProductBarcode)
+	}
+
+	units := []productUnitRow{}
+	for _, unit := range p.db.t.productUnits {
+		if unit.ProductID == id && !unit.deleted {
+			units = append(units, unit)
+		}
+	}
+	sort.SliceStable(units, func(i, j int) bool {
+		if units[i].Quantity != units[j].Quantity {
+			return units[i].Quantity < units[j].Quantity
+		}
+		return units[i].seq < units[j].seq
+	})
+
+	product.Units = []models.ProductUnit{}
+	for _, unit := range units {
+		product.Units = append(product.Units, unit.ProductUnit)
+	}
+
+	product.Images = p.db.productImages(id)
+
+	if product.ParentID != "" {
+		return product, nil
+	}
+	product.Variants = p.db.variants(product.ID)
+
+	return product, nil
+}
+
+// GetList returns products and variants as separate rows. With request.Grouped only parent products
+// are listed, each with its variants, and a parent is found when the name or the barcode of any of its variants matches.
+func (p productRepo) GetList(ctx context.Context, request models.ProductGetListRequest) (models.ProductResponse, error) {
+	p.db.mu.Lock()
+	defer p.db.mu.Unlock()
+
+	var (
+		subtree  map[string]bool
+		barcoded = map[string]bool{}
+	)
+	if request.CategoryID != "" {
+		subtree = p.db.categorySubtree(request.CategoryID)
+	}
+	if request.Barcode != "" {
+		for _, barcode := range p.db.t.productBarcodes {
+			if barcode.Barcode != request.Barcode || barcode.deleted {
+				continue
+			}
+			barcoded[barcode.ProductID] = true
+			if variant, ok := p.db.t.products[barcode.ProductID]; ok && request.Grouped && variant.parentID != "" {
+				barcoded[variant.parentID] = true
+			}
+		}
+	}
+
+	rows := []productRow{}
+	for _, row := range p.db.t.products {
+		if row.deleted || request.Grouped && row.parentID != "" {
+			continue
+		}
+		if request.Name != "" && !ilike(row.name, request.Name) && !(request.Grouped && p.db.variantNamed(row.id, request.Name)) {
+			continue
+		}
+		if request.Barcode != "" && !barcoded[row.id] {
+			continue
+		}
+		if request.CategoryID != "" && !subtree[row.categoryID] {
+			continue
+		}
+		rows = append(rows, row)
+	}
+	sortBySeq(rows, func(row productRow) int64 { return row.seq }, true)
+
+	page, err := paginate(rows, request.Page, request.Limit)
+	if err != nil {
+		return models.ProductResponse{}, err
+	}
+
+	products := []models.Product{}
+	for _, row := range page {
+		product := p.db.product(row)
+		if request.Grouped {
+			product.Variants = p.db.variants(row.id)
+		}
+		products = append(products, product)
+	}
+
+	return models.ProductResponse{
+		Products: products,
+		Count:    len(rows),
+	}, nil
+}
+
+// Update changes a product. A variant sent with price 0 takes the price of its parent again.
+// A changed price starts now for all branches and is kept in the price history.
+func (p productRepo) Update(ctx context.Context, product models.UpdateProduct) (string, error) {
+	p.db.mu.Lock()
+	defer p.db.mu.Unlock()
+
+	row, ok := p.db.t.products[product.ID]
+	if !ok {
+		return "", pgx.ErrNoRows
+	}
+
+	attributes := row.attributes
+	if product.Attributes != nil {
+		attributes = product.Attributes
+	}
+	if row.parentID != "" && !maps.Equal(attributes, row.attributes) && p.db.variantAttributesTaken(row.parentID, attributes, row.id) {
+		return "", uniqueViolation("products_variant_attributes_idx")
+	}
+
+	unit, precision := row.unit, row.precision
+	if product.Unit != "" {
+		unit = product.Unit
+	}
+	if product.Precision != nil {
+		precision = *product.Precision
+	}
+
+	if unit != row.unit || precision < row.precision {
+		// stock of every branch must stay whole for a new unit and fit a smaller precision
+		for _, repository := range p.db.t.repositories {
+			if repository.ProductID != product.ID || repository.deleted {
+				continue
+			}
+			if !fitsPrecision(repository.Count, precision) || unit != row.unit && !fitsPrecision(repository.Count, 0) {
+				return "", storage.ErrFractionalStock
+			}
+		}
+	}
+
+	oldPrice := row.price
+	var newPrice *money.Money
+	if row.parentID == "" || product.Price != 0 {
+		price := product.Price
+		newPrice = &price
+	}
+
+	row.name = product.Name
+	row.price = newPrice
+	row.unit = unit
+	row.precision = precision
+	row.categoryID = product.CategoryID
+	row.attributes = attributes
+	if product.NameTranslations != nil {
+		row.nameTranslations = product.NameTranslations
+	}
+	row.updatedAt = time.Now()
+	p.db.t.products[product.ID] = row
+
+	if (oldPrice == nil) != (newPrice == nil) || (oldPrice != nil && *oldPrice != *newPrice) {
+		p.db.insertProductPrice(product.ID, newPrice)
+	}
+
+	return product.ID, nil
+}
+
+// Delete removes a product together with its variants.
+func (p productRepo) Delete(ctx context.Context, id string) error {
+	p.db.mu.Lock()
+	defer p.db.mu.Unlock()
+
+	deleted := map[string]bool{}
+	for productID, row := range p.db.t.products {
+		if (productID == id || row.parentID == id) && !row.deleted {
+			row.deleted = true
+			p.db.t.products[productID] = row
+			deleted[productID] = true
+		}
+	}
+
+	p.db.deleteBarcodes(deleted)
+
+	return nil
+}
+
+// CreateVariant adds a variant to a parent product. The variant gets the category and the unit of the parent,
+// its name and the names in other languages are made of the parent names and the attribute values when the name is not given.
+func (p productRepo) CreateVariant(ctx context.Context, variant models.CreateProductVariant) (string, error) {
+	id := uuid.New().String()
+
+	err := p.db.tx(func() error {
+		parent, ok := p.db.t.products[variant.ParentID]
+		if !ok || parent.deleted {
+			return pgx.ErrNoRows
+		}
+
+		if parent.parentID != "" {
+			return storage.ErrVariantParent
+		}
+
+		if variant.Name == "" {
+			variant.Name = variantName(parent.name, variant.Attributes)
+			if variant.NameTranslations == nil {
+				variant.NameTranslations = map[string]string{}
+				for language, name := range parent.nameTranslations {
+					variant.NameTranslations[language] = variantName(name, variant.Attributes)
+				}
+			}
+		}
+		if variant.NameTranslations == nil {
+			variant.NameTranslations = map[string]string{}
+		}
+		if variant.Attributes == nil {
+			variant.Attributes = map[string]string{}
+		}
+
+		if variant.Barcode != "" && p.db.productBarcodeTaken(variant.Barcode) {
+			return storage.ErrBarcodeExists
+		}
+		if p.db.variantAttributesTaken(parent.id, variant.Attributes, "") {
+			return uniqueViolation("products_variant_attributes_idx")
+		}
+
+		var price *money.Money
+		if variant.PriceOverride != nil {
+			override := *variant.PriceOverride
+			price = &override
+		}
+
+		now := time.Now()
+		p.db.t.products[id] = productRow{
+			id:               id,
+			name:             variant.Name,
+			nameTranslations: variant.NameTranslations,
+			price:            price,
+			barcode:          variant.Barcode,
+			unit:             parent.unit,
+			precision:        parent.precision,
+			categoryID:       parent.categoryID,
+			parentID:         parent.id,
+			attributes:       variant.Attributes,
+			createdAt:        now,
+			updatedAt:        now,
+			seq:              p.db.next(),
+		}
+
+		if price != nil {
+			p.db.insertProductPrice(id, price)
+		}
+
+		if variant.Barcode != "" {
+			return p.db.insertProductBarcode(models.CreateProductBarcode{
+				ProductID: id,
+				Barcode:   variant.Barcode,
+				Quantity:  1,
+			})
+		}
+
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return id, nil
+}
+
+func (p productRepo) AddBarcode(ctx context.Context, barcode models.CreateProductBarcode) (string, error) {
+	err := p.db.tx(func() error {
+		if err := p.db.insertProductBarcode(barcode); err != nil {
+			return err
+		}
+
+		// the first barcode of a product without one becomes its main barcode
+		if row, ok := p.db.t.products[barcode.ProductID]; ok && row.barcode == "" {
+			if p.db.productBarcodeTaken(barcode.Barcode) {
+				return storage.ErrBarcodeExists
+			}
+			row.barcode = barcode.Barcode
+			row.updatedAt = time.Now()
+			p.db.t.products[barcode.ProductID] = row
+		}
+
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return barcode.ProductID, nil
+}
+
+func (p productRepo) DeleteBarcode(ctx context.Context, productID, barcodeID string) error {
+	p.db.mu.Lock()
+	defer p.db.mu.Unlock()
+
+	barcode, ok := p.db.t.productBarcodes[barcodeID]
+	if !ok || barcode.ProductID != productID || barcode.deleted {
+		return pgx.ErrNoRows
+	}
+	barcode.deleted = true
+	p.db.t.productBarcodes[barcodeID] = barcode
+
+	// when the main barcode is removed another barcode of the product takes its place
+	if row, ok := p.db.t.products[productID]; ok && row.barcode == barcode.Barcode {
+		row.barcode = ""
+		if barcodes := p.db.productBarcodes(productID); len(barcodes) > 0 {
+			row.barcode = barcodes[0].Barcode
+		}
+		row.updatedAt = time.Now()
+		p.db.t.products[productID] = row
+	}
+
+	return nil
+}
+
+func (p productRepo) GetByBarcode(ctx context.Context, barcode string) (models.ProductBarcode, error) {
+	p.db.mu.Lock()
+	defer p.db.mu.Unlock()
+
+	row, ok := p.db.barcode(barcode)
+	if !ok {
+		return models.ProductBarcode{}, pgx.ErrNoRows
+	}
+
+	return row.ProductBarcode, nil
+}
+
+func (p productRepo) AddUnit(ctx context.Context, unit models.CreateProductUnit) (string, error) {
+	p.db.mu.Lock()
+	defer p.db.mu.Unlock()
+
+	id := uuid.New().String()
+	p.db.t.productUnits[id] = productUnitRow{
+		ProductUnit: models.ProductUnit{
+			ID:        id,
+			ProductID: unit.ProductID,
+			Name:      unit.Name,
+			Quantity:  roundQuantity(unit.Quantity),
+			CreatedAt: time.Now(),
+		},
+		seq: p.db.next(),
+	}
+
+	return unit.ProductID, nil
+}
+
+func (p productRepo) DeleteUnit(ctx context.Context, productID, unitID string) error {
+	p.db.mu.Lock()
+	defer p.db.mu.Unlock()
+
+	unit, ok := p.db.t.productUnits[unitID]
+	if !ok || unit.ProductID != productID || unit.deleted {
+		return pgx.ErrNoRows
+	}
+
+	unit.deleted = true
+	p.db.t.productUnits[unitID] = unit
+
+	return nil
+}
+
+// deleteBarcodes removes the barcodes of the deleted products, barcodes go with the products
+// so they can be given to other products.
+func (s *Store) deleteBarcodes(deleted map[string]bool) {
+	for barcodeID, row := range s.t.productBarcodes {
+		if deleted[row.ProductID] && !row.deleted {
+			row.deleted = true
+			s.t.productBarcodes[barcodeID] = row
+		}
+	}
+}
+
+// product reads a product row like productColumns: the price is the price valid now for all branches
+// and only a variant has a price override.
+func (s *Store) product(row productRow) models.Product {
+	product := models.Product{
+		ID:               row.id,
+		Name:             row.name,
+		NameTranslations: row.nameTranslations,
+		Price:            s.effectivePrice(row.id, "", time.Now()),
+		Barcode:          row.barcode,
+		Unit:             row.unit,
+		Precision:        row.precision,
+		CategoryID:       row.categoryID,
+		ParentID:         row.parentID,
+		Attributes:       row.attributes,
+		CreatedAt:        row.createdAt,
+		UpdatedAt:        row.updatedAt,
+	}
+
+	if row.parentID != "" {
+		product.PriceOverride = s.priceAt(row.id, "", time.Now())
+		if product.PriceOverride == nil && row.price != nil {
+			price := *row.price
+			product.PriceOverride = &price
+		}
+	}
+
+	if images := s.productImages(row.id); len(images) > 0 {
+		product.ImageURL = images[0].URL
+		product.ThumbnailURL = images[0].ThumbnailURL
+	}
+
+	return product
+}
+
+// variants returns the variants of a parent product in the order they were added, nil when it has none.
+func (s *Store) variants(parentID string) []models.Product {
+	rows := []productRow{}
+	for _, row := range s.t.products {
+		if row.parentID == parentID && !row.deleted {
+			rows = append(rows, row)
+		}
+	}
+	sortBySeq(rows, func(row productRow) int64 { return row.seq }, false)
+
+	var variants []models.Product
+	for _, row := range rows {
+		variants = append(variants, s.product(row))
+	}
+
+	return variants
+}
+
+func (s *Store) variantNamed(parentID, pattern string) bool {
+	for _, row := range s.t.products {
+		if row.parentID == parentID && !row.deleted && ilike(row.name, pattern) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s *Store) variantAttributesTaken(parentID string, attributes map[string]string, exceptID string) bool {
+	for _, row := range s.t.products {
+		if row.parentID == parentID && row.id != exceptID && !row.deleted && maps.Equal(row.attributes, attributes) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// productBarcodes returns the barcodes of a product, single items first.
+func (s *Store) productBarcodes(productID string) []productBarcodeRow {
+	barcodes := []productBarcodeRow{}
+	for _, barcode := range s.t.productBarcodes {
+		if barcode.ProductID == productID && !barcode.deleted {
+			barcodes = append(barcodes, barcode)
+		}
+	}
+	sort.SliceStable(barcodes, func(i, j int) bool {
+		if barcodes[i].Quantity != barcodes[j].Quantity {
+			return barcodes[i].Quantity < barcodes[j].Quantity
+		}
+		return barcodes[i].seq < barcodes[j].seq
+	})
+
+	return barcodes
+}
+
+// barcode finds a barcode of a product which is not deleted.
+func (s *Store) barcode(barcode string) (productBarcodeRow, bool) {
+	for _, row := range s.t.productBarcodes {
+		if row.Barcode != barcode || row.deleted {
+			continue
+		}
+		if product, ok := s.t.products[row.ProductID]; ok && !product.deleted {
+			return row, true
+		}
+	}
+
+	return productBarcodeRow{}, false
+}
+
+// productBarcodeTaken tells whether a product, deleted ones too, already has the main barcode.
+// fitsPrecision tells whether a quantity has no more decimals than the precision, like count = round(count, precision).
+func fitsPrecision(quantity float64, precision int) bool {
+	scale := math.Pow10(precision)
+	return math.Round(quantity*scale)/scale == quantity
+}
+
+func (s *Store) productBarcodeTaken(barcode string) bool {
+	for _, row := range s.t.products {
+		if row.barcode == barcode && !row.deleted {
+			return true
+		}
+	}
+
+	return false
+}
+
+// insertProductPrice starts a price of the product for all branches now, a nil price
+// is kept for a variant which goes back to the price of its parent.
+func (s *Store) insertProductPrice(productID string, price *money.Money) {
+	id := uuid.New().String()
+	now := time.Now()
+	s.t.productPrices[id] = productPriceRow{
+		ProductPrice: models.ProductPrice{
+			ID:        id,
+			ProductID: productID,
+			Price:     price,
+			StartsAt:  now,
+			CreatedAt: now,
+		},
+		seq: s.next(),
+	}
+}
+
+func (s *Store) insertProductBarcode(barcode models.CreateProductBarcode) error {
+	if barcode.Quantity <= 0 {
+		barcode.Quantity = 1
+	}
+
+	for _, row := range s.t.productBarcodes {
+		if row.Barcode == barcode.Barcode && !row.deleted {
+			return storage.ErrBarcodeExists
+		}
+	}
+
+	id := uuid.New().String()
+	s.t.productBarcodes[id] = productBarcodeRow{
+		ProductBarcode: models.ProductBarcode{
+			ID:        id,
+			ProductID: barcode.ProductID,
+			Barcode:   barcode.Barcode,
+			Quantity:  barcode.Quantity,
+			CreatedAt: time.Now(),
+		},
+		seq: s.next(),
+	}
+
+	return nil
+}
+
+// variantName joins the parent name and the attribute values ordered by attribute name, e.g. "T-shirt M red".
+func variantName(parentName string, attributes map[string]string) string {
+	keys := make([]string, 0, len(attributes))
+	for key := range attributes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	name := parentName
+	for _, key := range keys {
+		name += " " + attributes[key]
+	}
+
+	return name
+}
@@ -0,0 +1,96 @@
+package memory
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"sell/api/models"
+	"sort"
+	"time"
+)
+
+type productImageRow struct {
+	models.ProductImage
+	seq     int64
+	deleted bool
+}
+
+// AddImage adds an image after the other images of the product, the first image is the main one.
+func (p productRepo) AddImage(ctx context.Context, image models.CreateProductImage) (string, error) {
+	p.db.mu.Lock()
+	defer p.db.mu.Unlock()
+
+	position := 0
+	for _, row := range p.db.t.productImages {
+		if row.ProductID == image.ProductID && !row.deleted && row.Position+1 > position {
+			position = row.Position + 1
+		}
+	}
+
+	id := uuid.New().String()
+	p.db.t.productImages[id] = productImageRow{
+		ProductImage: models.ProductImage{
+			ID:           id,
+			ProductID:    image.ProductID,
+			URL:          image.URL,
+			ThumbnailURL: image.ThumbnailURL,
+			Key:          image.Key,
+			ThumbnailKey: image.ThumbnailKey,
+			ContentType:  image.ContentType,
+			Width:        image.Width,
+			Height:       image.Height,
+			Size:         image.Size,
+			Position:     position,
+			CreatedAt:    time.Now(),
+		},
+		seq: p.db.next(),
+	}
+
+	return id, nil
+}
+
+func (p productRepo) GetImages(ctx context.Context, productID string) ([]models.ProductImage, error) {
+	p.db.mu.Lock()
+	defer p.db.mu.Unlock()
+
+	return p.db.productImages(productID), nil
+}
+
+// DeleteImage removes the image and returns it so its files can be deleted.
+func (p productRepo) DeleteImage(ctx context.Context, productID, imageID string) (models.ProductImage, error) {
+	p.db.mu.Lock()
+	defer p.db.mu.Unlock()
+
+	row, ok := p.db.t.productImages[imageID]
+	if !ok || row.ProductID != productID || row.deleted {
+		return models.ProductImage{}, pgx.ErrNoRows
+	}
+
+	row.deleted = true
+	p.db.t.productImages[imageID] = row
+
+	return row.ProductImage, nil
+}
+
+// productImages returns the images of a product in their order.
+func (s *Store) productImages(productID string) []models.ProductImage {
+	rows := []productImageRow{}
+	for _, row := range s.t.productImages {
+		if row.ProductID == productID && !row.deleted {
+			rows = append(rows, row)
+		}
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		if rows[i].Position != rows[j].Position {
+			return rows[i].Position < rows[j].Position
+		}
+		return rows[i].seq < rows[j].seq
+	})
+
+	images := []models.ProductImage{}
+	for _, row := range rows {
+		images = append(images, row.ProductImage)
+	}
+
+	return images
+}
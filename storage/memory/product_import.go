@@ -0,0 +1,232 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"sell/api/models"
+	"sell/storage"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Import upserts products by barcode in one transaction, rows without a barcode are always created.
+// Categories of the path are found by name under their parent and created when missing.
+// A failing row is reported and skipped, with dryRun nothing is saved but every row is checked against the store.
+func (p productRepo) Import(ctx context.Context, rows []models.ProductImportRow, dryRun bool) (models.ProductImportResponse, error) {
+	response := models.ProductImportResponse{
+		DryRun: dryRun,
+		Total:  len(rows),
+		Errors: []models.ProductImportError{},
+	}
+
+	p.db.mu.Lock()
+	defer p.db.mu.Unlock()
+
+	saved := p.db.t.clone()
+	for _, row := range rows {
+		// every row works on its own snapshot so a failing row does not change the others
+		rowSaved := p.db.t.clone()
+
+		created, newCategories, rowErr := p.db.importProductRow(row)
+		if rowErr != nil {
+			p.db.t = rowSaved
+
+			message := rowErr.Error()
+			if pgErr := (&pgconn.PgError{}); errors.As(rowErr, &pgErr) {
+				message = pgErr.Message
+			}
+			response.Failed++
+			response.Errors = append(response.Errors, models.ProductImportError{Row: row.Row, Message: message})
+			continue
+		}
+
+		response.CategoriesCreated += newCategories
+		if created {
+			response.Created++
+		} else {
+			response.Updated++
+		}
+	}
+
+	if dryRun {
+		p.db.t = saved
+	}
+
+	return response, nil
+}
+
+// importProductRow creates or updates the product of one row. It returns whether the product is new
+// and the number of categories it created.
+func (s *Store) importProductRow(row models.ProductImportRow) (bool, int, error) {
+	categoryID, newCategories := s.importCategoryPath(row.Category)
+
+	var productID string
+	if row.Barcode != "" {
+		if barcode, ok := s.barcode(row.Barcode); ok {
+			productID = barcode.ProductID
+			if barcode.Quantity > 1 {
+				return false, newCategories, fmt.Errorf("barcode %s is a pack barcode of product %s", row.Barcode, productID)
+			}
+		}
+	}
+
+	now := time.Now()
+	if productID == "" {
+		if row.Price == nil {
+			return false, newCategories, errors.New("price is required for a new product")
+		}
+
+		if row.Barcode != "" && s.productBarcodeTaken(row.Barcode) {
+			return false, newCategories, storage.ErrBarcodeExists
+		}
+
+		id := uuid.New().String()
+		price := *row.Price
+		s.t.products[id] = productRow{
+			id:               id,
+			name:             row.Name,
+			nameTranslations: map[string]string{},
+			price:            &price,
+			barcode:          row.Barcode,
+			unit:             "piece",
+			categoryID:       categoryID,
+			attributes:       map[string]string{},
+			createdAt:        now,
+			updatedAt:        now,
+			seq:              s.next(),
+		}
+
+		s.insertProductPrice(id, &price)
+
+		if row.Barcode != "" {
+			if err := s.insertProductBarcode(models.CreateProductBarcode{
+				ProductID: id,
+				Barcode:   row.Barcode,
+				Quantity:  1,
+			}); err != nil {
+				return false, newCategories, err
+			}
+		}
+
+		return true, newCategories, nil
+	}
+
+	// an empty category keeps the category of the product
+	product := s.t.products[productID]
+	product.name = row.Name
+	if categoryID != "" {
+		product.categoryID = categoryID
+	}
+	product.updatedAt = now
+
+	if row.Price != nil && (product.price == nil || *product.price != *row.Price) {
+		price := *row.Price
+		product.price = &price
+		s.insertProductPrice(productID, &price)
+	}
+	s.t.products[productID] = product
+
+	return false, newCategories, nil
+}
+
+// importCategoryPath returns the id of the last category of the path creating the missing ones
+// and the number of categories it created. Names are compared case insensitively.
+func (s *Store) importCategoryPath(path []string) (string, int) {
+	var (
+		parentID      string
+		newCategories int
+	)
+
+	for _, name := range path {
+		found := []categoryRow{}
+		for _, row := range s.t.categories {
+			if !row.deleted && row.ParentID == parentID && strings.EqualFold(row.Name, name) {
+				found = append(found, row)
+			}
+		}
+
+		if len(found) == 0 {
+			parentID = s.insertCategory(models.CreateCategory{Name: name, ParentID: parentID})
+			newCategories++
+			continue
+		}
+
+		sortBySeq(found, func(row categoryRow) int64 { return row.seq }, false)
+		parentID = found[0].ID
+	}
+
+	return parentID, newCategories
+}
+
+// Export returns all products with their current price and the path of their category, like Food/Dairy/Milk.
+func (p productRepo) Export(ctx context.Context) ([]models.ProductExportRow, error) {
+	p.db.mu.Lock()
+	defer p.db.mu.Unlock()
+
+	type exportRow struct {
+		models.ProductExportRow
+		hasPath bool
+	}
+
+	now := time.Now()
+	rows := []exportRow{}
+	for _, row := range p.db.t.products {
+		if row.deleted {
+			continue
+		}
+
+		path, ok := p.db.liveCategoryPath(row.categoryID)
+		rows = append(rows, exportRow{
+			ProductExportRow: models.ProductExportRow{
+				Name:     row.name,
+				Price:    p.db.effectivePrice(row.id, "", now),
+				Barcode:  row.barcode,
+				Category: path,
+			},
+			hasPath: ok,
+		})
+	}
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		if rows[i].hasPath != rows[j].hasPath {
+			return !rows[i].hasPath
+		}
+		if rows[i].Category != rows[j].Category {
+			return rows[i].Category < rows[j].Category
+		}
+		return rows[i].Name < rows[j].Name
+	})
+
+	products := []models.ProductExportRow{}
+	for _, row := range rows {
+		products = append(products, row.ProductExportRow)
+	}
+
+	return products, nil
+}
+
+// liveCategoryPath is the path of the category from its root, it is not found when the category
+// or one of its parents is deleted.
+func (s *Store) liveCategoryPath(id string) (string, bool) {
+	names := []string{}
+	visited := map[string]bool{}
+	for id != "" {
+		row, ok := s.t.categories[id]
+		if !ok || row.deleted || visited[id] {
+			return "", false
+		}
+		visited[id] = true
+		names = append([]string{row.Name}, names...)
+		id = row.ParentID
+	}
+
+	if len(names) == 0 {
+		return "", false
+	}
+
+	return strings.Join(names, "/"), true
+}
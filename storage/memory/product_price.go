@@ -0,0 +1,203 @@
+package memory
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"sell/api/models"
+	"sell/pkg/money"
+	"sell/storage"
+	"sort"
+	"time"
+)
+
+type productPriceRow struct {
+	models.ProductPrice
+	seq     int64
+	deleted bool
+}
+
+type productPriceRepo struct {
+	db *Store
+}
+
+func NewProductPriceRepo(db *Store) storage.IProductPriceStorage {
+	return &productPriceRepo{db: db}
+}
+
+// Create schedules a price of the product for all branches or for one branch.
+// The price is used from price.StartsAt, which is now when it is not given.
+func (p *productPriceRepo) Create(ctx context.Context, price models.CreateProductPrice) (string, error) {
+	now := time.Now()
+	startsAt := now
+	if price.StartsAt != "" {
+		var err error
+		if startsAt, err = parseTimestamp(price.StartsAt); err != nil {
+			return "", err
+		}
+	}
+
+	p.db.mu.Lock()
+	defer p.db.mu.Unlock()
+
+	id := uuid.New().String()
+	amount := price.Price
+	p.db.t.productPrices[id] = productPriceRow{
+		ProductPrice: models.ProductPrice{
+			ID:        id,
+			ProductID: price.ProductID,
+			BranchID:  price.BranchID,
+			Price:     &amount,
+			StartsAt:  startsAt,
+			CreatedAt: now,
+		},
+		seq: p.db.next(),
+	}
+
+	return id, nil
+}
+
+// GetList returns the price history of a product with scheduled prices first.
+// With request.BranchID prices of that branch and prices for all branches are returned.
+func (p *productPriceRepo) GetList(ctx context.Context, request models.ProductPriceGetListRequest) (models.ProductPricesResponse, error) {
+	p.db.mu.Lock()
+	defer p.db.mu.Unlock()
+
+	rows := []productPriceRow{}
+	for _, row := range p.db.t.productPrices {
+		if row.ProductID != request.ProductID || row.deleted {
+			continue
+		}
+		if request.BranchID != "" && row.BranchID != request.BranchID && row.BranchID != "" {
+			continue
+		}
+		rows = append(rows, row)
+	}
+	sortPrices(rows)
+
+	page, err := paginate(rows, request.Page, request.Limit)
+	if err != nil {
+		return models.ProductPricesResponse{}, err
+	}
+
+	prices := []models.ProductPrice{}
+	for _, row := range page {
+		prices = append(prices, row.ProductPrice)
+	}
+
+	return models.ProductPricesResponse{
+		ProductPrices: prices,
+		Count:         len(rows),
+	}, nil
+}
+
+// Delete cancels a scheduled price, prices which already started are history and stay.
+func (p *productPriceRepo) Delete(ctx context.Context, productID, id string) error {
+	p.db.mu.Lock()
+	defer p.db.mu.Unlock()
+
+	row, ok := p.db.t.productPrices[id]
+	if !ok || row.ProductID != productID || row.deleted {
+		return pgx.ErrNoRows
+	}
+
+	if !row.StartsAt.After(time.Now()) {
+		return storage.ErrPriceStarted
+	}
+
+	row.deleted = true
+	p.db.t.productPrices[id] = row
+
+	return nil
+}
+
+// GetEffective returns the price the product had in the branch at request.At.
+func (p *productPriceRepo) GetEffective(ctx context.Context, request models.EffectivePriceRequest) (models.EffectivePrice, error) {
+	p.db.mu.Lock()
+	defer p.db.mu.Unlock()
+
+	if _, ok := p.db.t.products[request.ProductID]; !ok {
+		return models.EffectivePrice{}, pgx.ErrNoRows
+	}
+
+	return models.EffectivePrice{
+		ProductID: request.ProductID,
+		BranchID:  request.BranchID,
+		At:        request.At,
+		Price:     p.db.effectivePrice(request.ProductID, request.BranchID, request.At),
+	}, nil
+}
+
+// priceAt returns the price of a product valid at a moment: the latest started price of the branch or
+// of all branches, the price of the branch when both start at once. A later price for all branches ends
+// an override of the branch. It is nil when the product has no price of its own.
+func (s *Store) priceAt(productID, branchID string, at time.Time) *money.Money {
+	rows := []productPriceRow{}
+	for _, row := range s.t.productPrices {
+		if row.ProductID != productID || row.deleted || row.StartsAt.After(at) {
+			continue
+		}
+		if row.BranchID != "" && (branchID == "" || row.BranchID != branchID) {
+			continue
+		}
+		rows = append(rows, row)
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		if !rows[i].StartsAt.Equal(rows[j].StartsAt) {
+			return rows[i].StartsAt.After(rows[j].StartsAt)
+		}
+		if (rows[i].BranchID == "") != (rows[j].BranchID == "") {
+			return rows[j].BranchID == ""
+		}
+		return rows[i].seq > rows[j].seq
+	})
+
+	if rows[0].Price == nil {
+		return nil
+	}
+	price := *rows[0].Price
+	return &price
+}
+
+// effectivePrice is the price of the product in the branch at the moment. A variant without a price of its own
+// is sold at the parent price, the price of the product row is used for products without any price history.
+func (s *Store) effectivePrice(productID, branchID string, at time.Time) money.Money {
+	if price := s.priceAt(productID, branchID, at); price != nil {
+		return *price
+	}
+
+	product, ok := s.t.products[productID]
+	if !ok {
+		return 0
+	}
+
+	if product.parentID != "" {
+		if price := s.priceAt(product.parentID, branchID, at); price != nil {
+			return *price
+		}
+	}
+
+	if product.price != nil {
+		return *product.price
+	}
+
+	if parent, ok := s.t.products[product.parentID]; ok && parent.price != nil {
+		return *parent.price
+	}
+
+	return 0
+}
+
+// sortPrices orders prices with the latest start first and the latest added first among prices starting together.
+func sortPrices(rows []productPriceRow) {
+	sort.SliceStable(rows, func(i, j int) bool {
+		if !rows[i].StartsAt.Equal(rows[j].StartsAt) {
+			return rows[i].StartsAt.After(rows[j].StartsAt)
+		}
+		return rows[i].seq > rows[j].seq
+	})
+}
@@ -0,0 +1,346 @@
+package memory
+
+import (
+	"context"
+	"math"
+	"sell/api/models"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// thresholds of pg_trgm for the % and <% operators
+const (
+	similarityThreshold     = 0.3
+	wordSimilarityThreshold = 0.6
+)
+
+// Search finds products by barcode prefix, name prefix, words of the name or category and by similar
+// spelling. The best matches come first: an exact barcode, then names starting with the query, then
+// products ranked by text relevance and similarity. Price and stock are those of request.BranchID,
+// without a branch the stock of all branches is summed. Text rank and similarity are calculated
+// after ts_rank and pg_trgm, ranks may differ from postgres in the last digits.
+func (p productRepo) Search(ctx context.Context, request models.ProductSearchRequest) (models.ProductSearchResponse, error) {
+	p.db.mu.Lock()
+	defer p.db.mu.Unlock()
+
+	var (
+		search  = strings.ToLower(strings.TrimSpace(request.Query))
+		words   = searchWords(search)
+		now     = time.Now()
+		results = []models.ProductSearchResult{}
+	)
+
+	for _, row := range p.db.t.products {
+		if row.deleted {
+			continue
+		}
+
+		var categoryName string
+		if category, ok := p.db.t.categories[row.categoryID]; ok {
+			categoryName = category.Name
+		}
+		name := strings.ToLower(row.name)
+
+		barcodeMatch, barcodePrefix := false, false
+		for _, barcode := range p.db.productBarcodes(row.id) {
+			barcodeMatch = barcodeMatch || barcode.Barcode == search
+			barcodePrefix = barcodePrefix || strings.HasPrefix(barcode.Barcode, search)
+		}
+		namePrefix := strings.HasPrefix(name, search)
+
+		vector := textVector(row.name, categoryName)
+		textMatch := len(words) > 0 && (textVector(row.name, "").matches(words) || textVector(categoryName, "").matches(words))
+
+		translationMatch := false
+		similarity := math.Max(wordSimilarity(search, name), trigramSimilarity(search, strings.ToLower(categoryName))/2)
+		for _, translation := range row.nameTranslations {
+			translation = strings.ToLower(translation)
+			similarity = math.Max(similarity, wordSimilarity(search, translation))
+			translationMatch = translationMatch || strings.HasPrefix(translation, search) ||
+				trigramSimilarity(translation, search) >= similarityThreshold
+		}
+
+		if !barcodePrefix && !namePrefix && !textMatch && !translationMatch &&
+			trigramSimilarity(name, search) < similarityThreshold &&
+			wordSimilarity(search, name) < wordSimilarityThreshold &&
+			(categoryName == "" || trigramSimilarity(strings.ToLower(categoryName), search) < similarityThreshold) {
+			continue
+		}
+
+		rank := vector.rank(words) + similarity
+		match := "fuzzy"
+		switch {
+		case barcodeMatch:
+			rank += 4
+		case namePrefix:
+			rank += 3
+		case barcodePrefix:
+			rank += 2
+		}
+		switch {
+		case barcodeMatch || barcodePrefix:
+			match = "barcode"
+		case namePrefix:
+			match = "prefix"
+		case textMatch:
+			match = "text"
+		}
+
+		var stock float64
+		for _, repository := range p.db.t.repositories {
+			if repository.ProductID == row.id && !repository.deleted && (request.BranchID == "" || repository.BranchID == request.BranchID) {
+				stock += repository.Count
+			}
+		}
+
+		product := p.db.product(row)
+		product.Price = p.db.effectivePrice(row.id, request.BranchID, now)
+		product.PriceOverride = nil
+		if row.parentID != "" && row.price != nil {
+			price := *row.price
+			product.PriceOverride = &price
+		}
+
+		results = append(results, models.ProductSearchResult{
+			Product:      product,
+			CategoryName: categoryName,
+			Stock:        roundQuantity(stock),
+			Match:        match,
+			Rank:         rank,
+		})
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Rank != results[j].Rank {
+			return results[i].Rank > results[j].Rank
+		}
+		return results[i].Name < results[j].Name
+	})
+
+	results, err := paginate(results, 1, request.Limit)
+	if err != nil {
+		return models.ProductSearchResponse{}, err
+	}
+
+	return models.ProductSearchResponse{
+		Products: results,
+		Count:    len(results),
+	}, nil
+}
+
+// searchWords splits the search into the words of its text query, characters other than letters and digits
+// separate words.
+func searchWords(search string) []string {
+	return strings.FieldsFunc(search, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// weights of the text of the product name (A) and of the category name (C) for ts_rank
+const (
+	nameWeight     = 1.0
+	categoryWeight = 0.2
+)
+
+type lexemePosition struct {
+	position int
+	weight   float64
+}
+
+// tsVector is to_tsvector('simple') of the name with weight A followed by the category with weight C.
+type tsVector map[string][]lexemePosition
+
+func textVector(name, category string) tsVector {
+	vector := tsVector{}
+	position := 0
+	for _, part := range []struct {
+		text   string
+		weight float64
+	}{{name, nameWeight}, {category, categoryWeight}} {
+		for _, word := range searchWords(strings.ToLower(part.text)) {
+			position++
+			vector[word] = append(vector[word], lexemePosition{position: position, weight: part.weight})
+		}
+	}
+
+	return vector
+}
+
+// entries returns the positions of every lexeme the word is a prefix of, like word:* in a tsquery.
+func (v tsVector) entries(word string) [][]lexemePosition {
+	lexemes := []string{}
+	for lexeme := range v {
+		if strings.HasPrefix(lexeme, word) {
+			lexemes = append(lexemes, lexeme)
+		}
+	}
+	sort.Strings(lexemes)
+
+	entries := [][]lexemePosition{}
+	for _, lexeme := range lexemes {
+		entries = append(entries, v[lexeme])
+	}
+
+	return entries
+}
+
+func (v tsVector) matches(words []string) bool {
+	for _, word := range words {
+		if len(v.entries(word)) == 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// rank is ts_rank of the vector and the query of all words with the default normalization.
+func (v tsVector) rank(words []string) float64 {
+	words = uniqueWords(words)
+	if len(words) == 0 {
+		return 0
+	}
+
+	if len(words) < 2 {
+		return v.rankOr(words)
+	}
+
+	rank := -1.0
+	positions := make([][]lexemePosition, len(words))
+	for i, word := range words {
+		for _, entry := range v.entries(word) {
+			positions[i] = entry
+			for k := 0; k < i; k++ {
+				for _, a := range entry {
+					for _, b := range positions[k] {
+						distance := a.position - b.position
+						if distance < 0 {
+							distance = -distance
+						}
+						if distance == 0 {
+							continue
+						}
+						weight := math.Sqrt(a.weight * b.weight * wordDistance(distance))
+						if rank < 0 {
+							rank = weight
+						} else {
+							rank = 1 - (1-rank)*(1-weight)
+						}
+					}
+				}
+			}
+		}
+	}
+
+	if rank < 0 {
+		return 1e-20
+	}
+	return rank
+}
+
+func (v tsVector) rankOr(words []string) float64 {
+	var rank float64
+	for _, word := range words {
+		for _, entry := range v.entries(word) {
+			var sum, maxWeight float64 = 0, -1
+			maxAt := 0
+			for j, position := range entry {
+				sum += position.weight / float64((j+1)*(j+1))
+				if position.weight > maxWeight {
+					maxWeight, maxAt = position.weight, j
+				}
+			}
+			rank += (maxWeight + sum - maxWeight/float64((maxAt+1)*(maxAt+1))) / 1.64493406685
+		}
+	}
+
+	return rank / float64(len(words))
+}
+
+func wordDistance(distance int) float64 {
+	if distance > 100 {
+		return 1e-30
+	}
+	return 1 / (1.005 + 0.05*math.Exp(float64(distance)/1.5-2))
+}
+
+func uniqueWords(words []string) []string {
+	seen := map[string]bool{}
+	unique := []string{}
+	for _, word := range words {
+		if !seen[word] {
+			seen[word] = true
+			unique = append(unique, word)
+		}
+	}
+	sort.Strings(unique)
+
+	return unique
+}
+
+// trigrams returns the trigrams of the words of text in order like pg_trgm: every word is padded
+// with two spaces in front and one behind.
+func trigrams(text string) []string {
+	result := []string{}
+	for _, word := range searchWords(strings.ToLower(text)) {
+		padded := []rune("  " + word + " ")
+		for i := 0; i+3 <= len(padded); i++ {
+			result = append(result, string(padded[i:i+3]))
+		}
+	}
+
+	return result
+}
+
+func trigramSet(trigrams []string) map[string]bool {
+	set := map[string]bool{}
+	for _, trigram := range trigrams {
+		set[trigram] = true
+	}
+	return set
+}
+
+// trigramSimilarity is similarity() of pg_trgm: the shared trigrams of both texts by all their trigrams.
+func trigramSimilarity(a, b string) float64 {
+	setA, setB := trigramSet(trigrams(a)), trigramSet(trigrams(b))
+	if len(setA) == 0 || len(setB) == 0 {
+		return 0
+	}
+
+	shared := 0
+	for trigram := range setA {
+		if setB[trigram] {
+			shared++
+		}
+	}
+
+	return float64(shared) / float64(len(setA)+len(setB)-shared)
+}
+
+// wordSimilarity is word_similarity() of pg_trgm: the greatest similarity of the trigrams of a
+// and any continuous extent of the trigrams of b.
+func wordSimilarity(a, b string) float64 {
+	setA, ordered := trigramSet(trigrams(a)), trigrams(b)
+	if len(setA) == 0 || len(ordered) == 0 {
+		return 0
+	}
+
+	best := 0.0
+	for lower := range ordered {
+		extent := map[string]bool{}
+		shared := 0
+		for upper := lower; upper < len(ordered); upper++ {
+			if !extent[ordered[upper]] {
+				extent[ordered[upper]] = true
+				if setA[ordered[upper]] {
+					shared++
+				}
+			}
+			best = math.Max(best, float64(shared)/float64(len(setA)+len(extent)-shared))
+		}
+	}
+
+	return best
+}
@@ -0,0 +1,320 @@
+package memory
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"sell/api/models"
+	"sell/pkg/money"
+	"sell/storage"
+	"sort"
+	"time"
+)
+
+type purchaseOrderRow struct {
+	models.PurchaseOrder
+	seq     int64
+	deleted bool
+}
+
+type purchaseOrderProductRow struct {
+	models.PurchaseOrderProduct
+	seq     int64
+	deleted bool
+}
+
+type purchaseOrderRepo struct {
+	db *Store
+}
+
+func NewPurchaseOrderRepo(db *Store) storage.IPurchaseOrderStorage {
+	return &purchaseOrderRepo{db: db}
+}
+
+func (p *purchaseOrderRepo) Create(ctx context.Context, order models.CreatePurchaseOrder) (string, error) {
+	p.db.mu.Lock()
+	defer p.db.mu.Unlock()
+
+	price := money.Money(0)
+	for _, product := range order.Products {
+		amount, err := product.Price.Mul(product.Count)
+		if err != nil {
+			return "", err
+		}
+		price += amount
+	}
+
+	id := uuid.New().String()
+	now := time.Now()
+	p.db.t.purchaseOrders[id] = purchaseOrderRow{
+		PurchaseOrder: models.PurchaseOrder{
+			ID:         id,
+			SupplierID: order.SupplierID,
+			BranchID:   order.BranchID,
+			Status:     "ordered",
+			Currency:   order.Currency,
+			Price:      price,
+			Comment:    order.Comment,
+			CreatedAt:  now,
+			UpdatedAt:  now,
+		},
+		seq: p.db.next(),
+	}
+
+	for _, product := range order.Products {
+		line := purchaseOrderProductRow{
+			PurchaseOrderProduct: models.PurchaseOrderProduct{
+				ID:              uuid.New().String(),
+				PurchaseOrderID: id,
+				ProductID:       product.ProductID,
+				Price:           product.Price,
+				Count:           product.Count,
+			},
+			seq: p.db.next(),
+		}
+		p.db.t.purchaseOrderProducts[line.ID] = line
+	}
+
+	return id, nil
+}
+
+func (p *purchaseOrderRepo) GetByID(ctx context.Context, id string) (models.PurchaseOrder, error) {
+	p.db.mu.Lock()
+	defer p.db.mu.Unlock()
+
+	row, ok := p.db.t.purchaseOrders[id]
+	if !ok || row.deleted {
+		return models.PurchaseOrder{}, pgx.ErrNoRows
+	}
+
+	order := row.PurchaseOrder
+	order.Products = []models.PurchaseOrderProduct{}
+	for _, product := range p.db.purchaseOrderProducts(id) {
+		order.Products = append(order.Products, product.PurchaseOrderProduct)
+	}
+
+	return order, nil
+}
+
+func (p *purchaseOrderRepo) GetList(ctx context.Context, request models.PurchaseOrderGetListRequest) (models.PurchaseOrderResponse, error) {
+	p.db.mu.Lock()
+	defer p.db.mu.Unlock()
+
+	rows := []purchaseOrderRow{}
+	for _, row := range p.db.t.purchaseOrders {
+		if row.deleted ||
+			request.SupplierID != "" && row.SupplierID != request.SupplierID ||
+			request.BranchID != "" && row.BranchID != request.BranchID ||
+			request.Status != "" && row.Status != request.Status {
+			continue
+		}
+		rows = append(rows, row)
+	}
+	sortBySeq(rows, func(row purchaseOrderRow) int64 { return row.seq }, true)
+
+	page, err := paginate(rows, request.Page, request.Limit)
+	if err != nil {
+		return models.PurchaseOrderResponse{}, err
+	}
+
+	orders := []models.PurchaseOrder{}
+	for _, row := range page {
+		orders = append(orders, row.PurchaseOrder)
+	}
+
+	return models.PurchaseOrderResponse{
+		PurchaseOrders: orders,
+		Count:          len(rows),
+	}, nil
+}
+
+func (p *purchaseOrderRepo) Cancel(ctx context.Context, id string) error {
+	p.db.mu.Lock()
+	defer p.db.mu.Unlock()
+
+	row, ok := p.db.t.purchaseOrders[id]
+	if !ok || row.deleted || row.Status != "ordered" {
+		return storage.ErrPurchaseOrderClosed
+	}
+
+	row.Status = "cancelled"
+	row.UpdatedAt = time.Now()
+	p.db.t.purchaseOrders[id] = row
+
+	return nil
+}
+
+// Receive creates an income of the purchase order branch and supplier from the received lines,
+// adds them to the branch repository and moves the order to partially received or received.
+func (p *purchaseOrderRepo) Receive(ctx context.Context, request models.ReceivePurchaseOrder) (string, error) {
+	var incomeID string
+	err := p.db.tx(func() error {
+		order, ok := p.db.t.purchaseOrders[request.ID]
+		if !ok || order.deleted {
+			return pgx.ErrNoRows
+		}
+
+		if order.Status != "ordered" && order.Status != "partially_received" {
+			return storage.ErrPurchaseOrderClosed
+		}
+
+		price := money.Money(0)
+		for _, product := range request.Products {
+			amount, err := product.Price.Mul(product.Count)
+			if err != nil {
+				return err
+			}
+			price += amount
+		}
+
+		// the received goods are added to the repository right away, so the income is created finished
+		now := time.Now()
+		incomeID = p.db.insertIncome(models.Income{
+			BranchID:        order.BranchID,
+			SupplierID:      order.SupplierID,
+			PurchaseOrderID: request.ID,
+			Currency:        order.Currency,
+			ExchangeRate:    request.ExchangeRate,
+		})
+		income := p.db.t.incomes[incomeID]
+		income.Price = price
+		income.Status = "finished"
+		income.FinishedAt = &now
+		p.db.t.incomes[incomeID] = income
+
+		for _, product := range request.Products {
+			if product.ExpiryDate != "" {
+				if _, err := parseDate(product.ExpiryDate); err != nil {
+					return err
+				}
+			}
+
+			unitQuantity, err := p.db.productUnitQuantity(product.ProductID, product.ProductUnitID)
+			if err != nil {
+				return err
+			}
+
+			incomeProduct := models.IncomeProduct{
+				IncomeID:      incomeID,
+				ProductID:     product.ProductID,
+				ProductUnitID: product.ProductUnitID,
+				UnitQuantity:  unitQuantity,
+				Price:         product.Price,
+				Count:         product.Count,
+				BatchNumber:   product.BatchNumber,
+				ExpiryDate:    product.ExpiryDate,
+			}
+			incomeProduct.ID = p.db.insertIncomeProduct(incomeProduct)
+
+			// products the supplier sent without an order line show up as discrepancies only
+			for _, line := range p.db.purchaseOrderProducts(request.ID) {
+				if line.ProductID == product.ProductID {
+					line.ReceivedCount += roundQuantity(product.Count * unitQuantity)
+					p.db.t.purchaseOrderProducts[line.ID] = line
+					break
+				}
+			}
+
+			if err := p.db.addIncomeStock(order.BranchID, request.ExchangeRate, incomeProduct); err != nil {
+				return err
+			}
+		}
+
+		order.Status = "received"
+		for _, line := range p.db.purchaseOrderProducts(request.ID) {
+			if line.ReceivedCount < line.Count {
+				order.Status = "partially_received"
+			}
+		}
+		order.UpdatedAt = now
+		p.db.t.purchaseOrders[request.ID] = order
+
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return incomeID, nil
+}
+
+// GetDiscrepancies compares ordered lines with everything received by the incomes of the order.
+// Only lines where the count or the unit price differ are returned.
+func (p *purchaseOrderRepo) GetDiscrepancies(ctx context.Context, id string) (models.PurchaseOrderDiscrepancies, error) {
+	p.db.mu.Lock()
+	defer p.db.mu.Unlock()
+
+	order, ok := p.db.t.purchaseOrders[id]
+	if !ok || order.deleted {
+		return models.PurchaseOrderDiscrepancies{}, pgx.ErrNoRows
+	}
+
+	result := models.PurchaseOrderDiscrepancies{
+		PurchaseOrderID: id,
+		Status:          order.Status,
+		Discrepancies:   []models.PurchaseOrderDiscrepancy{},
+	}
+
+	lines := map[string]*models.PurchaseOrderDiscrepancy{}
+	line := func(productID string) *models.PurchaseOrderDiscrepancy {
+		if lines[productID] == nil {
+			lines[productID] = &models.PurchaseOrderDiscrepancy{ProductID: productID}
+		}
+		return lines[productID]
+	}
+
+	for _, product := range p.db.purchaseOrderProducts(id) {
+		l := line(product.ProductID)
+		l.OrderedCount += product.Count
+		l.ExpectedPrice = max(l.ExpectedPrice, product.Price)
+	}
+
+	amounts := map[string]money.Money{}
+	for _, incomeProduct := range p.db.t.incomeProducts {
+		income, ok := p.db.t.incomes[incomeProduct.IncomeID]
+		if !ok || income.PurchaseOrderID != id || income.deleted || incomeProduct.deleted {
+			continue
+		}
+		line(incomeProduct.ProductID).ReceivedCount += incomeProduct.Count * incomeProduct.UnitQuantity
+		amount, err := incomeProduct.Price.Mul(incomeProduct.Count)
+		if err != nil {
+			return models.PurchaseOrderDiscrepancies{}, err
+		}
+		amounts[incomeProduct.ProductID] += amount
+	}
+
+	for productID, l := range lines {
+		var err error
+		if l.ReceivedPrice, err = amounts[productID].Div(l.ReceivedCount); err != nil {
+			return models.PurchaseOrderDiscrepancies{}, err
+		}
+		l.CountDiff = roundQuantity(l.ReceivedCount - l.OrderedCount)
+		if l.ReceivedCount != 0 && l.OrderedCount != 0 {
+			l.PriceDiff = l.ReceivedPrice - l.ExpectedPrice
+		}
+
+		if l.CountDiff == 0 && l.PriceDiff == 0 {
+			continue
+		}
+		result.Discrepancies = append(result.Discrepancies, *l)
+	}
+	sort.Slice(result.Discrepancies, func(i, j int) bool {
+		return result.Discrepancies[i].ProductID < result.Discrepancies[j].ProductID
+	})
+	result.Count = len(result.Discrepancies)
+
+	return result, nil
+}
+
+// purchaseOrderProducts returns the live lines of the order in the order they were added.
+func (s *Store) purchaseOrderProducts(orderID string) []purchaseOrderProductRow {
+	rows := []purchaseOrderProductRow{}
+	for _, row := range s.t.purchaseOrderProducts {
+		if row.PurchaseOrderID == orderID && !row.deleted {
+			rows = append(rows, row)
+		}
+	}
+	sortBySeq(rows, func(row purchaseOrderProductRow) int64 { return row.seq }, false)
+
+	return rows
+}
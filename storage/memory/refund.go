@@ -0,0 +1,239 @@
+package memory
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"sell/api/models"
+	"sell/pkg/money"
+	"sell/storage"
+	"time"
+)
+
+type refundRow struct {
+	models.Refund
+	updatedAt time.Time
+	seq       int64
+	deleted   bool
+}
+
+type refundProductRow struct {
+	models.RefundProduct
+	seq     int64
+	deleted bool
+}
+
+type refundRepo struct {
+	db *Store
+}
+
+func NewRefundRepo(db *Store) storage.IRefundStorage {
+	return &refundRepo{db: db}
+}
+
+// refundLine is a basket line of the sale with the part of it which was already refunded.
+type refundLine struct {
+	basket                           basketRow
+	refunded                         float64
+	refundedPrice, refundedTaxAmount money.Money
+}
+
+// Create gives back request.Products of a successful sale, every line which is left when no products are given.
+// The goods go back to the branch repository and the refund is put into the fiscal outbox with it.
+// The last part of a line takes what is left of its price and tax, so a line refunded in parts sums up to the line.
+func (r *refundRepo) Create(ctx context.Context, request models.CreateRefund) (string, error) {
+	id := uuid.New().String()
+	err := r.db.tx(func() error {
+		sale, ok := r.db.t.sales[request.SaleID]
+		if !ok || sale.deleted {
+			return pgx.ErrNoRows
+		}
+
+		if sale.Status != "success" {
+			return storage.ErrSaleNotRefundable
+		}
+
+		lines := r.db.refundLines(request.SaleID)
+
+		products := request.Products
+		if len(products) == 0 {
+			for _, basket := range r.db.saleBaskets(request.SaleID) {
+				line := lines[basket.ID]
+				if left := roundQuantity(line.basket.Quantity - line.refunded); left > 0 {
+					products = append(products, models.CreateRefundProduct{BasketID: line.basket.ID, Quantity: left})
+				}
+			}
+		}
+		if len(products) == 0 {
+			return storage.ErrRefundQuantity
+		}
+
+		now := time.Now()
+		refund := refundRow{
+			Refund: models.Refund{
+				ID:        id,
+				SaleID:    request.SaleID,
+				Reason:    request.Reason,
+				CreatedAt: now,
+			},
+			updatedAt: now,
+			seq:       r.db.next(),
+		}
+
+		for _, product := range products {
+			line, ok := lines[product.BasketID]
+			if !ok {
+				return pgx.ErrNoRows
+			}
+
+			quantity := roundQuantity(product.Quantity)
+			left := roundQuantity(line.basket.Quantity - line.refunded)
+			if quantity <= 0 || quantity > left {
+				return storage.ErrRefundQuantity
+			}
+
+			linePrice, err := line.basket.Price.Share(quantity, line.basket.Quantity)
+			if err != nil {
+				return err
+			}
+			lineTax, err := line.basket.taxAmount.Share(quantity, line.basket.Quantity)
+			if err != nil {
+				return err
+			}
+			if quantity == left {
+				linePrice = line.basket.Price - line.refundedPrice
+				lineTax = line.basket.taxAmount - line.refundedTaxAmount
+			}
+
+			refundProduct := refundProductRow{
+				RefundProduct: models.RefundProduct{
+					ID:        uuid.New().String(),
+					RefundID:  id,
+					BasketID:  line.basket.ID,
+					ProductID: line.basket.ProductID,
+					Quantity:  quantity,
+					Price:     linePrice,
+					TaxRate:   line.basket.taxRate,
+					TaxAmount: lineTax,
+				},
+				seq: r.db.next(),
+			}
+			r.db.t.refundProducts[refundProduct.ID] = refundProduct
+
+			r.db.returnRefundStock(sale.BranchID, id, line.basket.ProductID, quantity, linePrice)
+
+			line.refunded = roundQuantity(line.refunded + quantity)
+			line.refundedPrice += linePrice
+			line.refundedTaxAmount += lineTax
+			lines[product.BasketID] = line
+
+			refund.Price += linePrice
+			if !sale.TaxIncluded {
+				refund.Price += lineTax
+			}
+			refund.TaxAmount += lineTax
+		}
+
+		r.db.t.refunds[id] = refund
+		r.db.enqueueFiscal("refund", id)
+
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return id, nil
+}
+
+func (r *refundRepo) GetByID(ctx context.Context, id string) (models.Refund, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	row, ok := r.db.t.refunds[id]
+	if !ok || row.deleted {
+		return models.Refund{}, pgx.ErrNoRows
+	}
+
+	products := []refundProductRow{}
+	for _, product := range r.db.t.refundProducts {
+		if product.RefundID == id && !product.deleted {
+			products = append(products, product)
+		}
+	}
+	sortBySeq(products, func(row refundProductRow) int64 { return row.seq }, false)
+
+	refund := row.Refund
+	refund.Products = []models.RefundProduct{}
+	for _, product := range products {
+		refund.Products = append(refund.Products, product.RefundProduct)
+	}
+
+	return refund, nil
+}
+
+// GetList returns refunds without their products, latest first, of one sale when request.SaleID is given.
+func (r *refundRepo) GetList(ctx context.Context, request models.RefundGetListRequest) (models.RefundsResponse, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	rows := []refundRow{}
+	for _, row := range r.db.t.refunds {
+		if !row.deleted && (request.SaleID == "" || row.SaleID == request.SaleID) {
+			rows = append(rows, row)
+		}
+	}
+	sortBySeq(rows, func(row refundRow) int64 { return row.seq }, true)
+
+	page, err := paginate(rows, request.Page, request.Limit)
+	if err != nil {
+		return models.RefundsResponse{}, err
+	}
+
+	refunds := []models.Refund{}
+	for _, row := range page {
+		refunds = append(refunds, row.Refund)
+	}
+
+	return models.RefundsResponse{
+		Refunds: refunds,
+		Count:   len(rows),
+	}, nil
+}
+
+// refundLines returns the basket lines of the sale by id with what was refunded of each of them.
+func (s *Store) refundLines(saleID string) map[string]refundLine {
+	lines := map[string]refundLine{}
+	for _, basket := range s.saleBaskets(saleID) {
+		lines[basket.ID] = refundLine{basket: basket}
+	}
+
+	for _, product := range s.t.refundProducts {
+		line, ok := lines[product.BasketID]
+		refund, live := s.t.refunds[product.RefundID]
+		if !ok || product.deleted || !live || refund.deleted {
+			continue
+		}
+		line.refunded += product.Quantity
+		line.refundedPrice += product.Price
+		line.refundedTaxAmount += product.TaxAmount
+		lines[product.BasketID] = line
+	}
+
+	return lines
+}
+
+// returnRefundStock puts refunded goods back into the branch repository and records the plus movement of the refund.
+func (s *Store) returnRefundStock(branchID, refundID, productID string, quantity float64, price money.Money) {
+	s.addStock(branchID, productID, quantity)
+
+	s.insertMovement(models.CreateRepositoryTransaction{
+		BranchID:                  branchID,
+		ProductID:                 productID,
+		RepositoryTransactionType: "plus",
+		SourceType:                "refund",
+		SourceID:                  refundID,
+		Price:                     price,
+		Quantity:                  quantity,
+	})
+}
@@ -0,0 +1,395 @@
+package memory
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"math"
+	"sell/api/models"
+	"sell/storage"
+	"sort"
+	"time"
+)
+
+type repositoryRow struct {
+	models.Repository
+	seq     int64
+	deleted bool
+}
+
+type repositoryRepo struct {
+	db *Store
+}
+
+func NewRepositoryRepo(db *Store) storage.IRepositoryRepo {
+	return &repositoryRepo{db: db}
+}
+
+// Create adds a repository row, the initial count is recorded as an adjustment movement.
+func (s *repositoryRepo) Create(ctx context.Context, repository models.CreateRepository) (string, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	if s.db.repositoryExists(repository.BranchID, repository.ProductID, "") {
+		return "", storage.ErrRepositoryExists
+	}
+
+	id := uuid.New().String()
+	now := time.Now()
+	s.db.t.repositories[id] = repositoryRow{
+		Repository: models.Repository{
+			ID:          id,
+			ProductID:   repository.ProductID,
+			BranchID:    repository.BranchID,
+			Count:       repository.Count,
+			MinCount:    repository.MinCount,
+			TargetCount: repository.TargetCount,
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		},
+		seq: s.db.next(),
+	}
+
+	s.db.postStockMovement(repository.BranchID, repository.ProductID, id, "adjustment", repository.Count)
+
+	return id, nil
+}
+
+func (s *repositoryRepo) GetByID(ctx context.Context, id models.PrimaryKey) (models.Repository, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	row, ok := s.db.t.repositories[id.ID]
+	if !ok || row.deleted {
+		return models.Repository{}, pgx.ErrNoRows
+	}
+
+	return row.Repository, nil
+}
+
+func (s *repositoryRepo) GetList(ctx context.Context, request models.GetListRequest) (models.RepositoriesResponse, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	rows := []repositoryRow{}
+	for _, row := range s.db.t.repositories {
+		if !row.deleted && (request.Search == "" || row.ProductID == request.Search) {
+			rows = append(rows, row)
+		}
+	}
+	sortBySeq(rows, func(row repositoryRow) int64 { return row.seq }, true)
+
+	page, err := paginate(rows, request.Page, request.Limit)
+	if err != nil {
+		return models.RepositoriesResponse{}, err
+	}
+
+	repositories := []models.Repository{}
+	for _, row := range page {
+		repositories = append(repositories, row.Repository)
+	}
+
+	return models.RepositoriesResponse{
+		Repositories: repositories,
+		Count:        len(rows),
+	}, nil
+}
+
+func (s *repositoryRepo) Update(ctx context.Context, repository models.UpdateRepository) (string, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	if s.db.repositoryExists(repository.BranchID, repository.ProductID, repository.ID) {
+		return "", storage.ErrRepositoryExists
+	}
+
+	if row, ok := s.db.t.repositories[repository.ID]; ok {
+		row.BranchID = repository.BranchID
+		row.ProductID = repository.ProductID
+		row.Count = repository.Count
+		row.UpdatedAt = time.Now()
+		s.db.t.repositories[repository.ID] = row
+	}
+
+	return repository.ID, nil
+}
+
+// Adjust is a manual edit of a repository row. The change of the count is recorded as an adjustment
+// movement, so the movement ledger keeps matching repository counts.
+func (s *repositoryRepo) Adjust(ctx context.Context, repository models.UpdateRepository) (string, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	row, ok := s.db.t.repositories[repository.ID]
+	if !ok || row.deleted {
+		return "", pgx.ErrNoRows
+	}
+	if s.db.repositoryExists(repository.BranchID, repository.ProductID, repository.ID) {
+		return "", storage.ErrRepositoryExists
+	}
+	old := row.Repository
+
+	row.BranchID = repository.BranchID
+	row.ProductID = repository.ProductID
+	row.Count = repository.Count
+	row.UpdatedAt = time.Now()
+	s.db.t.repositories[repository.ID] = row
+
+	if old.BranchID == repository.BranchID && old.ProductID == repository.ProductID {
+		s.db.postStockMovement(repository.BranchID, repository.ProductID, repository.ID, "adjustment", repository.Count-old.Count)
+		return repository.ID, nil
+	}
+
+	s.db.postStockMovement(old.BranchID, old.ProductID, repository.ID, "adjustment", -old.Count)
+	s.db.postStockMovement(repository.BranchID, repository.ProductID, repository.ID, "adjustment", repository.Count)
+
+	return repository.ID, nil
+}
+
+func (s *repositoryRepo) UpdateLevels(ctx context.Context, request models.UpdateRepositoryLevels) (string, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	if row, ok := s.db.t.repositories[request.ID]; ok && !row.deleted {
+		row.MinCount = request.MinCount
+		row.TargetCount = request.TargetCount
+		row.UpdatedAt = time.Now()
+		s.db.t.repositories[request.ID] = row
+	}
+
+	return request.ID, nil
+}
+
+// Delete soft deletes a repository row. What is left in it is taken out with an adjustment movement,
+// so the movement ledger keeps matching repository counts.
+func (s *repositoryRepo) Delete(ctx context.Context, id string) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	row, ok := s.db.t.repositories[id]
+	if !ok || row.deleted {
+		return pgx.ErrNoRows
+	}
+
+	row.deleted = true
+	s.db.t.repositories[id] = row
+
+	s.db.postStockMovement(row.BranchID, row.ProductID, id, "adjustment", -row.Count)
+
+	return nil
+}
+
+func (s *repositoryRepo) GetLowStock(ctx context.Context, request models.StockLevelRequest) (models.LowStockResponse, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	items := []models.LowStockItem{}
+	for _, row := range s.db.levelRows(request.BranchID) {
+		if row.Count < row.MinCount {
+			items = append(items, s.db.lowStockItem(row))
+		}
+	}
+
+	return models.LowStockResponse{
+		Items: items,
+		Count: len(items),
+	}, nil
+}
+
+// GetReorderSuggestions proposes quantities for products that are below their minimum now
+// or will be after request.CoverDays of selling at the average daily pace of the last request.Days.
+func (s *repositoryRepo) GetReorderSuggestions(ctx context.Context, request models.StockLevelRequest) (models.ReorderSuggestionsResponse, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	since := time.Now().AddDate(0, 0, -request.Days)
+	sold := map[[2]string]float64{}
+	for _, basket := range s.db.t.baskets {
+		sale, ok := s.db.t.sales[basket.SaleID]
+		if !ok || basket.deleted || sale.deleted || sale.Status != "success" || sale.CreatedAt.Before(since) {
+			continue
+		}
+		sold[[2]string{sale.BranchID, basket.ProductID}] += basket.Quantity
+	}
+
+	suggestions := []models.ReorderSuggestion{}
+	for _, row := range s.db.levelRows(request.BranchID) {
+		quantity := sold[[2]string{row.BranchID, row.ProductID}]
+		if row.MinCount <= 0 && row.TargetCount <= 0 && quantity <= 0 {
+			continue
+		}
+
+		suggestion := models.ReorderSuggestion{
+			LowStockItem:      s.db.lowStockItem(row),
+			AverageDailySales: quantity / float64(request.Days),
+		}
+
+		demand := math.Ceil(suggestion.AverageDailySales * float64(request.CoverDays))
+		if suggestion.Count-demand >= suggestion.MinCount {
+			continue
+		}
+
+		need := suggestion.TargetCount
+		if demand+suggestion.MinCount > need {
+			need = demand + suggestion.MinCount
+		}
+
+		suggestion.SuggestedQuantity = need - suggestion.Count
+		if suggestion.SuggestedQuantity <= 0 {
+			continue
+		}
+
+		suggestions = append(suggestions, suggestion)
+	}
+
+	return models.ReorderSuggestionsResponse{
+		Days:        request.Days,
+		CoverDays:   request.CoverDays,
+		Suggestions: suggestions,
+		Count:       len(suggestions),
+	}, nil
+}
+
+// Reconcile compares repository counts with the counts expected from the movement ledger. Mode ledger posts
+// a reconciliation movement for every discrepancy, mode counts sets the counts to what the ledger expects.
+func (s *repositoryRepo) Reconcile(ctx context.Context, request models.ReconcileRequest) (models.ReconcileResponse, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	response := models.ReconcileResponse{Discrepancies: []models.StockDiscrepancy{}}
+
+	counts := map[[2]string]float64{}
+	for _, row := range s.db.t.repositories {
+		if !row.deleted && (request.BranchID == "" || row.BranchID == request.BranchID) {
+			counts[[2]string{row.BranchID, row.ProductID}] += row.Count
+		}
+	}
+
+	ledger := map[[2]string]float64{}
+	for _, row := range s.db.t.repositoryMovements {
+		if row.deleted {
+			continue
+		}
+		if row.BranchID == "" {
+			response.UnassignedMovements++
+			continue
+		}
+		if request.BranchID == "" || row.BranchID == request.BranchID {
+			ledger[[2]string{row.BranchID, row.ProductID}] += row.change()
+		}
+	}
+
+	for key := range ledger {
+		if _, ok := counts[key]; !ok {
+			counts[key] = 0
+		}
+	}
+	for key, count := range counts {
+		if count != ledger[key] {
+			response.Discrepancies = append(response.Discrepancies, models.StockDiscrepancy{
+				BranchID:      key[0],
+				ProductID:     key[1],
+				Count:         count,
+				ExpectedCount: ledger[key],
+				Difference:    count - ledger[key],
+			})
+		}
+	}
+	sort.Slice(response.Discrepancies, func(i, j int) bool {
+		a, b := response.Discrepancies[i], response.Discrepancies[j]
+		if a.BranchID != b.BranchID {
+			return a.BranchID < b.BranchID
+		}
+		return a.ProductID < b.ProductID
+	})
+	response.Count = len(response.Discrepancies)
+
+	switch request.Mode {
+	case "ledger":
+		for _, discrepancy := range response.Discrepancies {
+			s.db.postStockMovement(discrepancy.BranchID, discrepancy.ProductID, "", "reconciliation", discrepancy.Difference)
+		}
+	case "counts":
+		for _, discrepancy := range response.Discrepancies {
+			row, ok := s.db.firstRepository(discrepancy.BranchID, discrepancy.ProductID)
+			if !ok {
+				s.db.addStock(discrepancy.BranchID, discrepancy.ProductID, roundQuantity(discrepancy.ExpectedCount))
+				continue
+			}
+			row.Count = roundQuantity(discrepancy.ExpectedCount)
+			row.UpdatedAt = time.Now()
+			s.db.t.repositories[row.ID] = row
+		}
+	default:
+		return response, nil
+	}
+	response.Fixed = true
+
+	return response, nil
+}
+
+// levelRows returns the live repositories of the branch, or of all branches, the furthest below
+// their minimum first.
+// repositoryExists tells whether the branch has a live repository of the product other than the row except.
+func (s *Store) repositoryExists(branchID, productID, except string) bool {
+	for _, row := range s.t.repositories {
+		if row.BranchID == branchID && row.ProductID == productID && row.ID != except && !row.deleted {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Store) levelRows(branchID string) []repositoryRow {
+	rows := []repositoryRow{}
+	for _, row := range s.t.repositories {
+		if _, ok := s.t.products[row.ProductID]; ok && !row.deleted && (branchID == "" || row.BranchID == branchID) {
+			rows = append(rows, row)
+		}
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		if a, b := rows[i].Count-rows[i].MinCount, rows[j].Count-rows[j].MinCount; a != b {
+			return a < b
+		}
+		return s.t.products[rows[i].ProductID].name < s.t.products[rows[j].ProductID].name
+	})
+
+	return rows
+}
+
+func (s *Store) lowStockItem(row repositoryRow) models.LowStockItem {
+	return models.LowStockItem{
+		RepositoryID: row.ID,
+		BranchID:     row.BranchID,
+		ProductID:    row.ProductID,
+		ProductName:  s.t.products[row.ProductID].name,
+		Count:        row.Count,
+		MinCount:     row.MinCount,
+		TargetCount:  row.TargetCount,
+	}
+}
+
+// postStockMovement records an adjustment of stock with no money value: a positive quantity
+// as a plus movement and a negative one as a minus movement. sourceID is the adjusted repository, if any.
+func (s *Store) postStockMovement(branchID, productID, sourceID, reason string, quantity float64) {
+	quantity = roundQuantity(quantity)
+	if quantity == 0 {
+		return
+	}
+
+	transactionType := "plus"
+	if quantity < 0 {
+		transactionType = "minus"
+		quantity = -quantity
+	}
+
+	s.insertMovement(models.CreateRepositoryTransaction{
+		BranchID:                  branchID,
+		ProductID:                 productID,
+		RepositoryTransactionType: transactionType,
+		Reason:                    reason,
+		SourceType:                "adjustment",
+		SourceID:                  sourceID,
+		Quantity:                  quantity,
+	})
+}
@@ -0,0 +1,314 @@
+package memory
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"sell/api/models"
+	"sell/pkg/money"
+	"sell/storage"
+	"sort"
+	"time"
+)
+
+type repositoryTransactionRow struct {
+	models.RepositoryTransaction
+	seq     int64
+	deleted bool
+}
+
+type repositoryTransactionRepo struct {
+	db *Store
+}
+
+func NewRepositoryTransactionRepo(db *Store) storage.IRepositoryTransactionRepo {
+	return &repositoryTransactionRepo{db: db}
+}
+
+// Create posts a movement by hand. It changes the ledger only, the repository counts stay and reconcile shows the difference.
+func (s *repositoryTransactionRepo) Create(ctx context.Context, rtransaction models.CreateRepositoryTransaction) (string, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	return s.db.insertMovement(rtransaction), nil
+}
+
+func (s *repositoryTransactionRepo) GetByID(ctx context.Context, id models.PrimaryKey) (models.RepositoryTransaction, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	row, ok := s.db.t.repositoryMovements[id.ID]
+	if !ok || row.deleted {
+		return models.RepositoryTransaction{}, pgx.ErrNoRows
+	}
+
+	return row.RepositoryTransaction, nil
+}
+
+func (s *repositoryTransactionRepo) GetList(ctx context.Context, req models.RepositoryTransactionGetListRequest) (models.RepositoryTransactionsResponse, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	rows := []repositoryTransactionRow{}
+	for _, row := range s.db.t.repositoryMovements {
+		if row.deleted ||
+			req.ProductID != "" && row.ProductID != req.ProductID ||
+			req.BranchID != "" && row.BranchID != req.BranchID ||
+			req.SourceType != "" && row.SourceType != req.SourceType ||
+			req.SourceID != "" && row.SourceID != req.SourceID {
+			continue
+		}
+		rows = append(rows, row)
+	}
+	sortBySeq(rows, func(row repositoryTransactionRow) int64 { return row.seq }, true)
+
+	page, err := paginate(rows, req.Page, req.Limit)
+	if err != nil {
+		return models.RepositoryTransactionsResponse{}, err
+	}
+
+	var rtransactions []models.RepositoryTransaction
+	for _, row := range page {
+		rtransactions = append(rtransactions, row.RepositoryTransaction)
+	}
+
+	return models.RepositoryTransactionsResponse{
+		RepositoryTransactions: rtransactions,
+		Count:                  len(rows),
+	}, nil
+}
+
+// Update corrects a movement with reversing entries: the movement is reversed and the corrected one is posted
+// as a new movement, whose id is returned. The ledger is append-only, so the history shows what was corrected.
+func (s *repositoryTransactionRepo) Update(ctx context.Context, transaction models.UpdateRepositoryTransaction) (string, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	if err := s.db.reverseMovement(transaction.ID); err != nil {
+		return "", err
+	}
+
+	return s.db.insertMovement(models.CreateRepositoryTransaction{
+		BranchID:                  transaction.BranchID,
+		ProductID:                 transaction.ProductID,
+		RepositoryTransactionType: transaction.RepositoryTransactionType,
+		Reason:                    transaction.Reason,
+		SourceType:                transaction.SourceType,
+		SourceID:                  transaction.SourceID,
+		Price:                     transaction.Price,
+		Quantity:                  transaction.Quantity,
+	}), nil
+}
+
+// Delete cancels a movement with a reversing entry, the movement itself stays in the ledger.
+func (s *repositoryTransactionRepo) Delete(ctx context.Context, id string) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	return s.db.reverseMovement(id)
+}
+
+// reverseMovement posts the opposite of the movement id, a minus for a plus and a plus for a minus of the same
+// quantity and price in the same branch. The reversal is an adjustment with the movement as its source,
+// so a movement is reversed once.
+func (s *Store) reverseMovement(id string) error {
+	movement, ok := s.t.repositoryMovements[id]
+	if !ok || movement.deleted {
+		return pgx.ErrNoRows
+	}
+
+	for _, row := range s.t.repositoryMovements {
+		if !row.deleted && row.SourceType == "adjustment" && row.Reason == "reversal" && row.SourceID == id {
+			return storage.ErrMovementReversed
+		}
+	}
+
+	transactionType := "minus"
+	if movement.RepositoryTransactionType == "minus" {
+		transactionType = "plus"
+	}
+
+	s.insertMovement(models.CreateRepositoryTransaction{
+		BranchID:                  movement.BranchID,
+		ProductID:                 movement.ProductID,
+		RepositoryTransactionType: transactionType,
+		Reason:                    "reversal",
+		SourceType:                "adjustment",
+		SourceID:                  id,
+		Price:                     movement.Price,
+		Quantity:                  movement.Quantity,
+	})
+
+	return nil
+}
+
+// History lists movements of a product in a branch between request.FromDate and request.ToDate (inclusive)
+// with the balance before the period and the running balance after every movement.
+func (s *repositoryTransactionRepo) History(ctx context.Context, request models.MovementHistoryRequest) (models.MovementHistory, error) {
+	from, err := parseDate(request.FromDate)
+	if err != nil {
+		return models.MovementHistory{}, err
+	}
+	to, err := parseDate(request.ToDate)
+	if err != nil {
+		return models.MovementHistory{}, err
+	}
+	to = to.AddDate(0, 0, 1)
+
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	history := models.MovementHistory{
+		ProductID: request.ProductID,
+		BranchID:  request.BranchID,
+		FromDate:  request.FromDate,
+		ToDate:    request.ToDate,
+		Movements: []models.MovementHistoryItem{},
+	}
+
+	rows := []repositoryTransactionRow{}
+	for _, row := range s.db.t.repositoryMovements {
+		if row.deleted || row.ProductID != request.ProductID || row.BranchID != request.BranchID {
+			continue
+		}
+		if row.CreatedAt.Before(from) {
+			history.OpeningBalance += row.change()
+		} else if row.CreatedAt.Before(to) {
+			rows = append(rows, row)
+		}
+	}
+	sortBySeq(rows, func(row repositoryTransactionRow) int64 { return row.seq }, false)
+
+	balance := history.OpeningBalance
+	for _, row := range rows {
+		item := models.MovementHistoryItem{
+			RepositoryTransaction: row.RepositoryTransaction,
+			Change:                row.change(),
+		}
+		balance = roundQuantity(balance + item.Change)
+		item.Balance = balance
+
+		history.Movements = append(history.Movements, item)
+	}
+	history.ClosingBalance = balance
+
+	return history, nil
+}
+
+// Snapshot computes stock per branch and product as of request.At from the movement ledger.
+// Stock is valued at the average cost of the income movements up to that moment,
+// products which were never received are valued at the price the branch had at that moment.
+func (s *repositoryTransactionRepo) Snapshot(ctx context.Context, request models.StockSnapshotRequest) (models.StockSnapshot, error) {
+	at, err := parseTimestamp(request.At)
+	if err != nil {
+		return models.StockSnapshot{}, err
+	}
+
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	type ledger struct {
+		branchID, productID string
+		quantity            float64
+		cost                money.Money
+		costQuantity        float64
+	}
+
+	ledgers := map[[2]string]*ledger{}
+	for _, row := range s.db.t.repositoryMovements {
+		if row.deleted || row.BranchID == "" || row.CreatedAt.After(at) ||
+			request.BranchID != "" && row.BranchID != request.BranchID {
+			continue
+		}
+
+		key := [2]string{row.BranchID, row.ProductID}
+		if ledgers[key] == nil {
+			ledgers[key] = &ledger{branchID: row.BranchID, productID: row.ProductID}
+		}
+		ledgers[key].quantity += row.change()
+		if row.RepositoryTransactionType == "plus" && row.SourceType == "income" {
+			ledgers[key].cost += row.Price
+			ledgers[key].costQuantity += row.Quantity
+		}
+	}
+
+	snapshot := models.StockSnapshot{At: request.At, Items: []models.StockSnapshotItem{}}
+	for _, l := range ledgers {
+		branch, hasBranch := s.db.t.branches[l.branchID]
+		product, hasProduct := s.db.t.products[l.productID]
+		if !hasBranch || !hasProduct || l.quantity == 0 {
+			continue
+		}
+
+		item := models.StockSnapshotItem{
+			BranchID:    l.branchID,
+			BranchName:  branch.Name,
+			ProductID:   l.productID,
+			ProductName: product.name,
+			Quantity:    l.quantity,
+		}
+		if l.costQuantity > 0 {
+			var err error
+			if item.UnitCost, err = l.cost.Div(l.costQuantity); err != nil {
+				return models.StockSnapshot{}, err
+			}
+		} else {
+			item.UnitCost = s.db.effectivePrice(l.productID, l.branchID, at)
+		}
+		snapshot.Items = append(snapshot.Items, item)
+	}
+	sort.Slice(snapshot.Items, func(i, j int) bool {
+		a, b := snapshot.Items[i], snapshot.Items[j]
+		if a.BranchName != b.BranchName {
+			return a.BranchName < b.BranchName
+		}
+		if a.ProductName != b.ProductName {
+			return a.ProductName < b.ProductName
+		}
+		return a.BranchID+a.ProductID < b.BranchID+b.ProductID
+	})
+
+	for i, item := range snapshot.Items {
+		value, err := item.UnitCost.Mul(item.Quantity)
+		if err != nil {
+			return models.StockSnapshot{}, err
+		}
+		snapshot.Items[i].Value = value
+		snapshot.TotalQuantity = roundQuantity(snapshot.TotalQuantity + item.Quantity)
+		snapshot.TotalValue += snapshot.Items[i].Value
+	}
+
+	return snapshot, nil
+}
+
+// insertMovement adds a row to the movement ledger and returns its id.
+func (s *Store) insertMovement(movement models.CreateRepositoryTransaction) string {
+	id := uuid.New().String()
+	now := time.Now()
+	s.t.repositoryMovements[id] = repositoryTransactionRow{
+		RepositoryTransaction: models.RepositoryTransaction{
+			ID:                        id,
+			BranchID:                  movement.BranchID,
+			ProductID:                 movement.ProductID,
+			RepositoryTransactionType: movement.RepositoryTransactionType,
+			Reason:                    movement.Reason,
+			SourceType:                movement.SourceType,
+			SourceID:                  movement.SourceID,
+			Price:                     movement.Price,
+			Quantity:                  movement.Quantity,
+			CreatedAt:                 now,
+			UpdatedAt:                 now,
+		},
+		seq: s.next(),
+	}
+
+	return id
+}
+
+// change is the quantity the movement adds to the stock, negative for minus movements.
+func (row repositoryTransactionRow) change() float64 {
+	if row.RepositoryTransactionType == "minus" {
+		return -row.Quantity
+	}
+	return row.Quantity
+}
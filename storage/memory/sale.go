@@ -0,0 +1,555 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"sell/api/models"
+	"sell/pkg/money"
+	"sell/storage"
+	"sort"
+	"time"
+)
+
+type saleRow struct {
+	models.Sale
+	seq     int64
+	deleted bool
+}
+
+type salePaymentRow struct {
+	models.SalePayment
+	seq     int64
+	deleted bool
+}
+
+type saleRepo struct {
+	db *Store
+}
+
+func NewSaleRepo(db *Store) storage.ISaleStorage {
+	return saleRepo{db: db}
+}
+
+func (s saleRepo) Create(ctx context.Context, sale models.CreateSale) (string, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	id := uuid.New().String()
+	now := time.Now()
+	s.db.t.sales[id] = saleRow{
+		Sale: models.Sale{
+			ID:              id,
+			BranchID:        sale.BranchID,
+			ShopAssistantID: sale.ShopAssistantID,
+			CashierID:       sale.CashierID,
+			PaymentType:     sale.PaymentType,
+			Currency:        sale.Currency,
+			Price:           sale.Price,
+			TaxIncluded:     sale.TaxIncluded,
+			Status:          sale.Status,
+			ClientName:      sale.ClientName,
+			CreatedAt:       now,
+			UpdatedAt:       now,
+		},
+		seq: s.db.next(),
+	}
+
+	return id, nil
+}
+
+func (s saleRepo) GetByID(ctx context.Context, id string) (models.Sale, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	row, ok := s.db.t.sales[id]
+	if !ok || row.deleted {
+		return models.Sale{}, pgx.ErrNoRows
+	}
+
+	return row.Sale, nil
+}
+
+func (s saleRepo) GetList(ctx context.Context, request models.GetListRequest) (models.SaleResponse, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	rows := []saleRow{}
+	for _, row := range s.db.t.sales {
+		if !row.deleted && (request.Search == "" || ilike(row.ClientName, "%"+request.Search+"%")) {
+			rows = append(rows, row)
+		}
+	}
+	sortBySeq(rows, func(row saleRow) int64 { return row.seq }, true)
+
+	page, err := paginate(rows, request.Page, request.Limit)
+	if err != nil {
+		return models.SaleResponse{}, err
+	}
+
+	sales := []models.Sale{}
+	for _, row := range page {
+		sales = append(sales, row.Sale)
+	}
+
+	return models.SaleResponse{
+		Sales: sales,
+		Count: len(rows),
+	}, nil
+}
+
+func (s saleRepo) Update(ctx context.Context, sale models.UpdateSale) (string, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	if row, ok := s.db.t.sales[sale.ID]; ok {
+		row.BranchID = sale.BranchID
+		row.ShopAssistantID = sale.ShopAssistantID
+		row.CashierID = sale.CashierID
+		row.PaymentType = sale.PaymentType
+		row.Price = sale.Price
+		row.Status = sale.Status
+		row.ClientName = sale.ClientName
+		row.UpdatedAt = time.Now()
+		s.db.t.sales[sale.ID] = row
+	}
+
+	return sale.ID, nil
+}
+
+func (s saleRepo) Delete(ctx context.Context, id string) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	if row, ok := s.db.t.sales[id]; ok {
+		row.deleted = true
+		s.db.t.sales[id] = row
+	}
+
+	return nil
+}
+
+// UpdatePrice sets the price and the status of the sale, a successful sale is put into the fiscal outbox with it.
+func (s saleRepo) UpdatePrice(ctx context.Context, request models.SaleRequest) (string, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	row, ok := s.db.t.sales[request.SaleID]
+	if !ok || row.Status != "in_process" {
+		return "", storage.ErrSaleNotOpen
+	}
+
+	row.Price = request.TotalPrice
+	row.Status = request.Status
+	row.UpdatedAt = time.Now()
+	s.db.t.sales[request.SaleID] = row
+
+	return request.SaleID, nil
+}
+
+// Receipt returns the sale with its basket lines, taxes and payments, the unit price of a line is its price divided by the quantity.
+// The tax is the one calculated at checkout, it is zero for a sale which was not checked out yet.
+func (s saleRepo) Receipt(ctx context.Context, id string) (models.Receipt, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	sale, ok := s.db.t.sales[id]
+	if !ok || sale.deleted {
+		return models.Receipt{}, pgx.ErrNoRows
+	}
+
+	receipt := models.Receipt{
+		SaleID:       sale.ID,
+		BranchID:     sale.BranchID,
+		BranchName:   s.db.t.branches[sale.BranchID].Name,
+		CashierID:    sale.CashierID,
+		PaymentType:  sale.PaymentType,
+		Status:       sale.Status,
+		ClientName:   sale.ClientName,
+		Currency:     sale.Currency,
+		TaxIncluded:  sale.TaxIncluded,
+		FiscalSign:   sale.FiscalSign,
+		FiscalNumber: sale.FiscalNumber,
+		CreatedAt:    sale.CreatedAt,
+		Lines:        []models.ReceiptLine{},
+	}
+
+	for _, basket := range s.db.saleBaskets(id) {
+		line := models.ReceiptLine{
+			ProductID:        basket.ProductID,
+			NameTranslations: map[string]string{},
+			Unit:             "piece",
+			Quantity:         basket.Quantity,
+			Price:            basket.Price,
+			TaxRate:          basket.taxRate,
+			Tax:              basket.taxAmount,
+		}
+		if product, ok := s.db.t.products[basket.ProductID]; ok {
+			line.Name = product.name
+			line.Unit = product.unit
+			if product.nameTranslations != nil {
+				line.NameTranslations = product.nameTranslations
+			}
+		}
+
+		if line.Quantity > 0 {
+			unitPrice, err := line.Price.Div(line.Quantity)
+			if err != nil {
+				return models.Receipt{}, err
+			}
+			line.UnitPrice = unitPrice
+		}
+		line.Total = line.Price
+		if !receipt.TaxIncluded {
+			line.Total += line.Tax
+		}
+		receipt.Total += line.Total
+		receipt.Tax += line.Tax
+		receipt.Lines = append(receipt.Lines, line)
+	}
+	receipt.Net = receipt.Total - receipt.Tax
+
+	taxes := map[money.Money]*models.ReceiptTax{}
+	for _, line := range receipt.Lines {
+		tax := taxes[line.TaxRate]
+		if tax == nil {
+			tax = &models.ReceiptTax{Rate: line.TaxRate}
+			taxes[line.TaxRate] = tax
+		}
+		tax.Net += line.Total - line.Tax
+		tax.Tax += line.Tax
+		tax.Total += line.Total
+	}
+
+	receipt.Taxes = []models.ReceiptTax{}
+	for _, tax := range taxes {
+		receipt.Taxes = append(receipt.Taxes, *tax)
+	}
+	sort.Slice(receipt.Taxes, func(i, j int) bool {
+		return receipt.Taxes[i].Rate < receipt.Taxes[j].Rate
+	})
+
+	receipt.Payments = s.db.salePayments(id)
+	for _, payment := range receipt.Payments {
+		receipt.Paid += payment.BaseAmount
+	}
+	if receipt.Paid > receipt.Total {
+		receipt.Change = receipt.Paid - receipt.Total
+	}
+
+	return receipt, nil
+}
+
+// AddPayment records money received for the sale, the amount is converted to the currency of the sale at payment.ExchangeRate.
+func (s saleRepo) AddPayment(ctx context.Context, payment models.CreateSalePayment) (string, error) {
+	baseAmount, err := payment.Amount.Convert(payment.ExchangeRate)
+	if err != nil {
+		return "", err
+	}
+
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	id := uuid.New().String()
+	s.db.t.salePayments[id] = salePaymentRow{
+		SalePayment: models.SalePayment{
+			ID:           id,
+			SaleID:       payment.SaleID,
+			Currency:     payment.Currency,
+			Amount:       payment.Amount,
+			ExchangeRate: payment.ExchangeRate,
+			BaseAmount:   baseAmount,
+			CreatedAt:    time.Now(),
+		},
+		seq: s.db.next(),
+	}
+
+	return id, nil
+}
+
+// GetPayments sums up the payments of the sale against the total to pay: the price of a sale which was checked out,
+// the price of its basket lines with the tax at the rates of now added when prices do not include it otherwise.
+func (s saleRepo) GetPayments(ctx context.Context, saleID string) (models.SalePayments, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	sale, ok := s.db.t.sales[saleID]
+	if !ok || sale.deleted {
+		return models.SalePayments{}, pgx.ErrNoRows
+	}
+
+	result := models.SalePayments{SaleID: saleID, Currency: sale.Currency, Total: sale.Price}
+	if sale.Status != "success" {
+		result.Total = 0
+		for _, basket := range s.db.saleBaskets(saleID) {
+			result.Total += basket.Price
+			if !sale.TaxIncluded {
+				tax, err := basketTax(basket.Price, s.db.taxRateOf(basket.ProductID).Rate, false)
+				if err != nil {
+					return models.SalePayments{}, err
+				}
+				result.Total += tax
+			}
+		}
+	}
+
+	result.Payments = s.db.salePayments(saleID)
+	for _, payment := range result.Payments {
+		result.Paid += payment.BaseAmount
+	}
+	if result.Paid < result.Total {
+		result.Due = result.Total - result.Paid
+	} else {
+		result.Change = result.Paid - result.Total
+	}
+
+	return result, nil
+}
+
+// Checkout finishes the sale in one transaction. The tax of every line is calculated at the rates of its products now
+// and kept with the sale, whose price becomes the total to pay; the tax is added to the lines when prices do not include it.
+// The lines leave the branch repository with a minus movement each and the batches of the branch, the staff get their
+// commission and the sale waits in the fiscal outbox. A sale which is not in process or a line the repository does not
+// hold enough of leaves everything as it was.
+func (s saleRepo) Checkout(ctx context.Context, id string) (models.SaleTax, error) {
+	var result models.SaleTax
+	err := s.db.tx(func() error {
+		sale, ok := s.db.t.sales[id]
+		if !ok || sale.deleted {
+			return pgx.ErrNoRows
+		}
+		if sale.Status != "in_process" {
+			return storage.ErrSaleNotOpen
+		}
+
+		result = models.SaleTax{SaleID: id, TaxIncluded: sale.TaxIncluded}
+		now := time.Now()
+
+		baskets := s.db.saleBaskets(id)
+		var price money.Money
+		for _, basket := range baskets {
+			basket.taxRate = s.db.taxRateOf(basket.ProductID).Rate
+			taxAmount, err := basketTax(basket.Price, basket.taxRate, sale.TaxIncluded)
+			if err != nil {
+				return err
+			}
+			basket.taxAmount = taxAmount
+			basket.UpdatedAt = now
+			s.db.t.baskets[basket.ID] = basket
+
+			price += basket.Price
+			result.Tax += basket.taxAmount
+		}
+
+		result.Total = price
+		if !result.TaxIncluded {
+			result.Total += result.Tax
+		}
+		result.Net = result.Total - result.Tax
+
+		sale.Price = result.Total
+		sale.TaxAmount = result.Tax
+		sale.Status = "success"
+		sale.FinishedAt = &now
+		sale.UpdatedAt = now
+		s.db.t.sales[id] = sale
+
+		for _, basket := range baskets {
+			if err := s.db.takeStock(sale.BranchID, basket.ProductID, basket.Quantity); err != nil {
+				return fmt.Errorf("%w: %g of product %s", err, basket.Quantity, basket.ProductID)
+			}
+
+			s.db.insertMovement(models.CreateRepositoryTransaction{
+				BranchID:                  sale.BranchID,
+				ProductID:                 basket.ProductID,
+				RepositoryTransactionType: "minus",
+				SourceType:                "sale",
+				SourceID:                  id,
+				Price:                     basket.Price,
+				Quantity:                  basket.Quantity,
+			})
+
+			s.db.deductBatches(sale.BranchID, basket.ProductID, basket.Quantity)
+		}
+
+		if err := s.db.paySaleCommission(sale.Sale, result.Total); err != nil {
+			return err
+		}
+
+		s.db.enqueueFiscal("sale", id)
+		return nil
+	})
+	if err != nil {
+		return models.SaleTax{}, err
+	}
+
+	return result, nil
+}
+
+// paySaleCommission tops up the balance of the cashier and the shop assistant of the sale by their tariffs and
+// records a transaction for each of them. Fixed tariffs pay an amount for a sale, percent tariffs a share of total
+// rounded to the hundredth. Sales without a shop assistant pay no commission.
+func (s *Store) paySaleCommission(sale models.Sale, total money.Money) error {
+	cashierCommission, err := s.staffCommission(sale.CashierID, sale.PaymentType, total)
+	if err != nil {
+		return err
+	}
+
+	if sale.ShopAssistantID == "" {
+		return nil
+	}
+
+	shopAssistantCommission, err := s.staffCommission(sale.ShopAssistantID, sale.PaymentType, total)
+	if err != nil {
+		return err
+	}
+
+	request := models.UpdateBalanceRequest{
+		TransactionType: "topup",
+		Source:          "sales",
+		Text:            "some",
+		SaleID:          sale.ID,
+	}
+	balance := cashierCommission + shopAssistantCommission
+	s.addBalance(request, models.StaffType{ID: sale.CashierID, Balance: balance})
+	s.addBalance(request, models.StaffType{ID: sale.ShopAssistantID, Balance: balance})
+
+	return nil
+}
+
+// staffCommission returns what the tariff of the staff pays for a sale of total paid in cash or by card.
+func (s *Store) staffCommission(staffID, paymentType string, total money.Money) (money.Money, error) {
+	staff, ok := s.t.staffs[staffID]
+	if !ok || staff.deleted {
+		return 0, pgx.ErrNoRows
+	}
+	tariff, ok := s.t.staffTariffs[staff.TariffID]
+	if !ok || tariff.DeletedAt != nil {
+		return 0, pgx.ErrNoRows
+	}
+
+	amount := tariff.AmountForCard
+	if paymentType == "cash" {
+		amount = tariff.AmountForCash
+	}
+
+	switch tariff.TariffType {
+	case "fixed":
+		return amount, nil
+	case "percent":
+		return total.Percent(amount)
+	}
+	return 0, nil
+}
+
+// TaxReport sums up the tax of sales checked out in the period by branch and tax rate, dates are days of finished_at.
+func (s saleRepo) TaxReport(ctx context.Context, request models.TaxReportRequest) (models.TaxReport, error) {
+	var from, to *time.Time
+	if request.FromDate != "" {
+		at, err := parseDate(request.FromDate)
+		if err != nil {
+			return models.TaxReport{}, err
+		}
+		from = &at
+	}
+	if request.ToDate != "" {
+		at, err := parseDate(request.ToDate)
+		if err != nil {
+			return models.TaxReport{}, err
+		}
+		at = at.AddDate(0, 0, 1)
+		to = &at
+	}
+
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	type group struct {
+		row   models.TaxReportRow
+		sales map[string]bool
+	}
+
+	groups := map[string]*group{}
+	for _, basket := range s.db.saleBaskets("") {
+		sale, ok := s.db.t.sales[basket.SaleID]
+		if !ok || sale.deleted || sale.Status != "success" ||
+			request.BranchID != "" && sale.BranchID != request.BranchID ||
+			from != nil && (sale.FinishedAt == nil || sale.FinishedAt.Before(*from)) ||
+			to != nil && (sale.FinishedAt == nil || !sale.FinishedAt.Before(*to)) {
+			continue
+		}
+
+		key := sale.BranchID + "/" + basket.taxRate.String()
+		if groups[key] == nil {
+			groups[key] = &group{
+				row: models.TaxReportRow{
+					BranchID:   sale.BranchID,
+					BranchName: s.db.t.branches[sale.BranchID].Name,
+					TaxRate:    basket.taxRate,
+				},
+				sales: map[string]bool{},
+			}
+		}
+
+		g := groups[key]
+		g.sales[sale.ID] = true
+		g.row.Net += basket.Price
+		if sale.TaxIncluded {
+			g.row.Net -= basket.taxAmount
+		}
+		g.row.Tax += basket.taxAmount
+	}
+
+	report := models.TaxReport{Rows: []models.TaxReportRow{}}
+	for _, g := range groups {
+		g.row.Sales = len(g.sales)
+		g.row.Total = g.row.Net + g.row.Tax
+		report.Rows = append(report.Rows, g.row)
+	}
+	sort.Slice(report.Rows, func(i, j int) bool {
+		a, b := report.Rows[i], report.Rows[j]
+		if a.BranchName != b.BranchName {
+			return a.BranchName < b.BranchName
+		}
+		if a.BranchID != b.BranchID {
+			return a.BranchID < b.BranchID
+		}
+		return a.TaxRate < b.TaxRate
+	})
+
+	for _, row := range report.Rows {
+		report.TotalNet += row.Net
+		report.TotalTax += row.Tax
+		report.Total += row.Total
+	}
+
+	return report, nil
+}
+
+// salePayments returns the live payments of the sale in the order they were made.
+func (s *Store) salePayments(saleID string) []models.SalePayment {
+	rows := []salePaymentRow{}
+	for _, row := range s.t.salePayments {
+		if row.SaleID == saleID && !row.deleted {
+			rows = append(rows, row)
+		}
+	}
+	sortBySeq(rows, func(row salePaymentRow) int64 { return row.seq }, false)
+
+	payments := []models.SalePayment{}
+	for _, row := range rows {
+		payments = append(payments, row.SalePayment)
+	}
+
+	return payments
+}
+
+// basketTax is the tax of a line price at the rate in percent: the part of the price which is tax
+// when prices include it, the tax on top of the price otherwise.
+func basketTax(price, rate money.Money, taxIncluded bool) (money.Money, error) {
+	if taxIncluded {
+		return price.MulDiv(int64(rate), int64(100*money.Unit+rate))
+	}
+	return price.Percent(rate)
+}
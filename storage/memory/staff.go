@@ -0,0 +1,195 @@
+package memory
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"sell/api/models"
+	"sell/storage"
+	"time"
+)
+
+type staffRow struct {
+	models.Staff
+	seq     int64
+	deleted bool
+}
+
+type staffRepo struct {
+	db *Store
+}
+
+func NewStaffRepo(db *Store) storage.IStaffRepo {
+	return &staffRepo{db: db}
+}
+
+func (s *staffRepo) Create(ctx context.Context, staff models.CreateStaff) (string, error) {
+	birthDate, err := time.Parse("2006-01-02", staff.BirthDate)
+	if err != nil {
+		return "", err
+	}
+
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	id := uuid.New().String()
+	now := time.Now()
+	s.db.t.staffs[id] = staffRow{
+		Staff: models.Staff{
+			ID:        id,
+			BranchID:  staff.BranchID,
+			TariffID:  staff.TariffID,
+			StaffType: staff.StaffType,
+			Name:      staff.Name,
+			Balance:   staff.Balance,
+			Age:       uint(time.Since(birthDate).Hours() / 24 / 365),
+			BirthDate: birthDate.Format("2006-01-02"),
+			Login:     staff.Login,
+			Password:  staff.Password,
+			CreatedAt: now,
+			UpdatedAt: now,
+		},
+		seq: s.db.next(),
+	}
+
+	return id, nil
+}
+
+func (s *staffRepo) StaffByID(ctx context.Context, id models.PrimaryKey) (models.Staff, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	row, ok := s.db.t.staffs[id.ID]
+	if !ok || row.deleted {
+		return models.Staff{}, pgx.ErrNoRows
+	}
+
+	return row.withoutPassword(), nil
+}
+
+func (s *staffRepo) GetStaffTList(ctx context.Context, request models.GetListRequest) (models.StaffsResponse, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	rows := []staffRow{}
+	for _, row := range s.db.t.staffs {
+		if !row.deleted && (request.Search == "" || ilike(row.Name, request.Search)) {
+			rows = append(rows, row)
+		}
+	}
+	sortBySeq(rows, func(row staffRow) int64 { return row.seq }, true)
+
+	page, err := paginate(rows, request.Page, request.Limit)
+	if err != nil {
+		return models.StaffsResponse{}, err
+	}
+
+	var staffs []models.Staff
+	for _, row := range page {
+		staffs = append(staffs, row.withoutPassword())
+	}
+
+	return models.StaffsResponse{
+		Staffs: staffs,
+		Count:  len(rows),
+	}, nil
+}
+
+func (s *staffRepo) UpdateStaff(ctx context.Context, staff models.UpdateStaff) (string, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	if row, ok := s.db.t.staffs[staff.ID]; ok {
+		row.BranchID = staff.BranchID
+		row.TariffID = staff.TariffID
+		row.StaffType = staff.StaffType
+		row.Name = staff.Name
+		row.Balance = staff.Balance
+		row.Login = staff.Login
+		row.UpdatedAt = time.Now()
+		s.db.t.staffs[staff.ID] = row
+	}
+
+	return staff.ID, nil
+}
+
+func (s *staffRepo) DeleteStaff(ctx context.Context, id string) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	if row, ok := s.db.t.staffs[id]; ok {
+		row.deleted = true
+		s.db.t.staffs[id] = row
+	}
+
+	return nil
+}
+
+// GetPassword reads the password of deleted staff too, like the query it stands for.
+func (s *staffRepo) GetPassword(ctx context.Context, id string) (string, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	row, ok := s.db.t.staffs[id]
+	if !ok {
+		return "", pgx.ErrNoRows
+	}
+
+	return row.Password, nil
+}
+
+func (s *staffRepo) UpdatePassword(ctx context.Context, request models.UpdateStaffPassword) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	if row, ok := s.db.t.staffs[request.ID]; ok {
+		row.Password = request.NewPassword
+		s.db.t.staffs[request.ID] = row
+	}
+
+	return nil
+}
+
+// UpdateBalance adds to the balances of the cashier and the shop assistant, when there is one,
+// and records a transaction for each of them.
+func (s *staffRepo) UpdateBalance(ctx context.Context, request models.UpdateBalanceRequest) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	s.db.addBalance(request, request.Cashier)
+	if request.ShopAssistant.ID != "" {
+		s.db.addBalance(request, request.ShopAssistant)
+	}
+
+	return nil
+}
+
+func (s *Store) addBalance(request models.UpdateBalanceRequest, staff models.StaffType) {
+	if row, ok := s.t.staffs[staff.ID]; ok {
+		row.Balance += staff.Balance
+		s.t.staffs[staff.ID] = row
+	}
+
+	id := uuid.New().String()
+	now := time.Now()
+	s.t.transactions[id] = transactionRow{
+		Transaction: models.Transaction{
+			ID:              id,
+			SaleID:          request.SaleID,
+			StaffID:         staff.ID,
+			TransactionType: request.TransactionType,
+			SourceType:      request.Source,
+			Amount:          staff.Balance,
+			Description:     request.Text,
+			CreatedAt:       now,
+			UpdatedAt:       now,
+		},
+		seq: s.next(),
+	}
+}
+
+func (row staffRow) withoutPassword() models.Staff {
+	staff := row.Staff
+	staff.Password = ""
+	return staff
+}
@@ -0,0 +1,136 @@
+package memory
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"sell/api/models"
+	"sell/storage"
+	"time"
+)
+
+type staffTariffRow struct {
+	models.StaffTariff
+	seq int64
+}
+
+type staffTariffRepo struct {
+	db *Store
+}
+
+func NewStaffTariffRepo(db *Store) storage.IStaffTariffRepo {
+	return &staffTariffRepo{db: db}
+}
+
+// Create keeps names unique among all tariffs, deleted ones too, like the unique column.
+func (s *staffTariffRepo) Create(ctx context.Context, tariff models.CreateStaffTariff) (string, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	if s.nameTaken(tariff.Name, "") {
+		return "", uniqueViolation("staff_tariffs_name_key")
+	}
+
+	id := uuid.New().String()
+	now := time.Now()
+	s.db.t.staffTariffs[id] = staffTariffRow{
+		StaffTariff: models.StaffTariff{
+			ID:            id,
+			Name:          tariff.Name,
+			TariffType:    tariff.TariffType,
+			AmountForCash: tariff.AmountForCash,
+			AmountForCard: tariff.AmountForCard,
+			CreatedAt:     now,
+			UpdatedAt:     now,
+		},
+		seq: s.db.next(),
+	}
+
+	return id, nil
+}
+
+func (s *staffTariffRepo) GetStaffTariffByID(ctx context.Context, id models.PrimaryKey) (models.StaffTariff, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	row, ok := s.db.t.staffTariffs[id.ID]
+	if !ok || row.DeletedAt != nil {
+		return models.StaffTariff{}, pgx.ErrNoRows
+	}
+
+	return row.StaffTariff, nil
+}
+
+func (s *staffTariffRepo) GetStaffTariffList(ctx context.Context, request models.GetListRequest) (models.StaffTariffResponse, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	rows := []staffTariffRow{}
+	for _, row := range s.db.t.staffTariffs {
+		if row.DeletedAt == nil && (request.Search == "" || ilike(row.Name, request.Search)) {
+			rows = append(rows, row)
+		}
+	}
+	sortBySeq(rows, func(row staffTariffRow) int64 { return row.seq }, true)
+
+	page, err := paginate(rows, request.Page, request.Limit)
+	if err != nil {
+		return models.StaffTariffResponse{}, err
+	}
+
+	var staffTariffs []models.StaffTariff
+	for _, row := range page {
+		staffTariffs = append(staffTariffs, row.StaffTariff)
+	}
+
+	return models.StaffTariffResponse{
+		StaffTariffs: staffTariffs,
+		Count:        len(rows),
+	}, nil
+}
+
+func (s *staffTariffRepo) UpdateStaffTariff(ctx context.Context, tariff models.UpdateStaffTariff) (string, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	row, ok := s.db.t.staffTariffs[tariff.ID]
+	if !ok || row.DeletedAt != nil {
+		return tariff.ID, nil
+	}
+
+	if s.nameTaken(tariff.Name, tariff.ID) {
+		return "", uniqueViolation("staff_tariffs_name_key")
+	}
+
+	row.Name = tariff.Name
+	row.TariffType = tariff.TariffType
+	row.AmountForCash = tariff.AmountForCash
+	row.AmountForCard = tariff.AmountForCard
+	row.UpdatedAt = time.Now()
+	s.db.t.staffTariffs[tariff.ID] = row
+
+	return tariff.ID, nil
+}
+
+func (s *staffTariffRepo) DeleteStaffTariff(ctx context.Context, id string) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	if row, ok := s.db.t.staffTariffs[id]; ok {
+		now := time.Now()
+		row.DeletedAt = &now
+		s.db.t.staffTariffs[id] = row
+	}
+
+	return nil
+}
+
+func (s *staffTariffRepo) nameTaken(name, exceptID string) bool {
+	for id, row := range s.db.t.staffTariffs {
+		if id != exceptID && row.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
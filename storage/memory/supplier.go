@@ -0,0 +1,202 @@
+package memory
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"sell/api/models"
+	"sell/pkg/money"
+	"sell/storage"
+	"time"
+)
+
+type supplierRow struct {
+	models.Supplier
+	seq     int64
+	deleted bool
+}
+
+type supplierPaymentRow struct {
+	models.SupplierPayment
+	seq     int64
+	deleted bool
+}
+
+type supplierRepo struct {
+	db *Store
+}
+
+func NewSupplierRepo(db *Store) storage.ISupplierStorage {
+	return &supplierRepo{db: db}
+}
+
+func (s *supplierRepo) Create(ctx context.Context, supplier models.CreateSupplier) (string, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	id := uuid.New().String()
+	now := time.Now()
+	s.db.t.suppliers[id] = supplierRow{
+		Supplier: models.Supplier{
+			ID:        id,
+			Name:      supplier.Name,
+			Phone:     supplier.Phone,
+			Address:   supplier.Address,
+			CreatedAt: now,
+			UpdatedAt: now,
+		},
+		seq: s.db.next(),
+	}
+
+	return id, nil
+}
+
+func (s *supplierRepo) GetByID(ctx context.Context, id string) (models.Supplier, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	row, ok := s.db.t.suppliers[id]
+	if !ok || row.deleted {
+		return models.Supplier{}, pgx.ErrNoRows
+	}
+
+	return s.db.supplier(row)
+}
+
+func (s *supplierRepo) GetList(ctx context.Context, request models.GetListRequest) (models.SupplierResponse, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	rows := []supplierRow{}
+	for _, row := range s.db.t.suppliers {
+		if !row.deleted && (request.Search == "" || ilike(row.Name, "%"+request.Search+"%")) {
+			rows = append(rows, row)
+		}
+	}
+	sortBySeq(rows, func(row supplierRow) int64 { return row.seq }, true)
+
+	page, err := paginate(rows, request.Page, request.Limit)
+	if err != nil {
+		return models.SupplierResponse{}, err
+	}
+
+	suppliers := []models.Supplier{}
+	for _, row := range page {
+		supplier, err := s.db.supplier(row)
+		if err != nil {
+			return models.SupplierResponse{}, err
+		}
+		suppliers = append(suppliers, supplier)
+	}
+
+	return models.SupplierResponse{
+		Suppliers: suppliers,
+		Count:     len(rows),
+	}, nil
+}
+
+func (s *supplierRepo) Update(ctx context.Context, supplier models.UpdateSupplier) (string, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	if row, ok := s.db.t.suppliers[supplier.ID]; ok {
+		row.Name = supplier.Name
+		row.Phone = supplier.Phone
+		row.Address = supplier.Address
+		row.UpdatedAt = time.Now()
+		s.db.t.suppliers[supplier.ID] = row
+	}
+
+	return supplier.ID, nil
+}
+
+func (s *supplierRepo) Delete(ctx context.Context, id string) error {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	if row, ok := s.db.t.suppliers[id]; ok {
+		row.deleted = true
+		s.db.t.suppliers[id] = row
+	}
+
+	return nil
+}
+
+func (s *supplierRepo) CreatePayment(ctx context.Context, payment models.CreateSupplierPayment) (string, error) {
+	baseAmount, err := payment.Amount.Convert(payment.ExchangeRate)
+	if err != nil {
+		return "", err
+	}
+
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	id := uuid.New().String()
+	s.db.t.supplierPayments[id] = supplierPaymentRow{
+		SupplierPayment: models.SupplierPayment{
+			ID:           id,
+			SupplierID:   payment.SupplierID,
+			Currency:     payment.Currency,
+			Amount:       payment.Amount,
+			ExchangeRate: payment.ExchangeRate,
+			BaseAmount:   baseAmount,
+			Comment:      payment.Comment,
+			CreatedAt:    time.Now(),
+		},
+		seq: s.db.next(),
+	}
+
+	return id, nil
+}
+
+func (s *supplierRepo) GetPaymentList(ctx context.Context, request models.SupplierPaymentGetListRequest) (models.SupplierPaymentResponse, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+
+	rows := []supplierPaymentRow{}
+	for _, row := range s.db.t.supplierPayments {
+		if row.SupplierID == request.SupplierID && !row.deleted {
+			rows = append(rows, row)
+		}
+	}
+	sortBySeq(rows, func(row supplierPaymentRow) int64 { return row.seq }, true)
+
+	page, err := paginate(rows, request.Page, request.Limit)
+	if err != nil {
+		return models.SupplierPaymentResponse{}, err
+	}
+
+	payments := []models.SupplierPayment{}
+	for _, row := range page {
+		payments = append(payments, row.SupplierPayment)
+	}
+
+	return models.SupplierPaymentResponse{
+		Payments: payments,
+		Count:    len(rows),
+	}, nil
+}
+
+// supplier returns the supplier with its balance: everything received from the supplier minus everything paid,
+// every document converted to the base currency at its own rate. Incomes in process are not received yet.
+func (s *Store) supplier(row supplierRow) (models.Supplier, error) {
+	var balance money.Money
+	for _, income := range s.t.incomes {
+		if income.SupplierID == row.ID && income.Status == "finished" && !income.deleted {
+			basePrice, err := income.Price.Convert(income.ExchangeRate)
+			if err != nil {
+				return models.Supplier{}, err
+			}
+			balance += basePrice
+		}
+	}
+	for _, payment := range s.t.supplierPayments {
+		if payment.SupplierID == row.ID && !payment.deleted {
+			balance -= payment.BaseAmount
+		}
+	}
+
+	supplier := row.Supplier
+	supplier.Balance = balance
+	return supplier, nil
+}
@@ -0,0 +1,213 @@
+package memory
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"sell/api/models"
+	"sell/storage"
+	"sort"
+	"time"
+)
+
+type taxRateRow struct {
+	models.TaxRate
+	seq     int64
+	deleted bool
+}
+
+type taxRateRepo struct {
+	db *Store
+}
+
+func NewTaxRateRepo(db *Store) storage.ITaxRateStorage {
+	return &taxRateRepo{db: db}
+}
+
+func (t *taxRateRepo) Create(ctx context.Context, rate models.CreateTaxRate) (string, error) {
+	t.db.mu.Lock()
+	defer t.db.mu.Unlock()
+
+	id := uuid.New().String()
+	now := time.Now()
+	t.db.t.taxRates[id] = taxRateRow{
+		TaxRate: models.TaxRate{
+			ID:        id,
+			Name:      rate.Name,
+			Rate:      rate.Rate,
+			CreatedAt: now,
+			UpdatedAt: now,
+		},
+		seq: t.db.next(),
+	}
+
+	return id, nil
+}
+
+func (t *taxRateRepo) GetByID(ctx context.Context, id string) (models.TaxRate, error) {
+	t.db.mu.Lock()
+	defer t.db.mu.Unlock()
+
+	row, ok := t.db.t.taxRates[id]
+	if !ok || row.deleted {
+		return models.TaxRate{}, pgx.ErrNoRows
+	}
+
+	return row.TaxRate, nil
+}
+
+func (t *taxRateRepo) GetList(ctx context.Context, request models.GetListRequest) (models.TaxRatesResponse, error) {
+	t.db.mu.Lock()
+	defer t.db.mu.Unlock()
+
+	rows := []taxRateRow{}
+	for _, row := range t.db.t.taxRates {
+		if !row.deleted && (request.Search == "" || ilike(row.Name, "%"+request.Search+"%")) {
+			rows = append(rows, row)
+		}
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		if rows[i].Rate != rows[j].Rate {
+			return rows[i].Rate < rows[j].Rate
+		}
+		if rows[i].Name != rows[j].Name {
+			return rows[i].Name < rows[j].Name
+		}
+		return rows[i].seq < rows[j].seq
+	})
+
+	page, err := paginate(rows, request.Page, request.Limit)
+	if err != nil {
+		return models.TaxRatesResponse{}, err
+	}
+
+	rates := []models.TaxRate{}
+	for _, row := range page {
+		rates = append(rates, row.TaxRate)
+	}
+
+	return models.TaxRatesResponse{
+		TaxRates: rates,
+		Count:    len(rows),
+	}, nil
+}
+
+func (t *taxRateRepo) Update(ctx context.Context, rate models.UpdateTaxRate) (string, error) {
+	t.db.mu.Lock()
+	defer t.db.mu.Unlock()
+
+	row, ok := t.db.t.taxRates[rate.ID]
+	if !ok || row.deleted {
+		return "", pgx.ErrNoRows
+	}
+
+	row.Name = rate.Name
+	row.Rate = rate.Rate
+	row.UpdatedAt = time.Now()
+	t.db.t.taxRates[rate.ID] = row
+
+	return rate.ID, nil
+}
+
+func (t *taxRateRepo) Delete(ctx context.Context, id string) error {
+	t.db.mu.Lock()
+	defer t.db.mu.Unlock()
+
+	row, ok := t.db.t.taxRates[id]
+	if !ok || row.deleted {
+		return pgx.ErrNoRows
+	}
+
+	row.deleted = true
+	row.UpdatedAt = time.Now()
+	t.db.t.taxRates[id] = row
+
+	return nil
+}
+
+func (t *taxRateRepo) SetProductTaxRate(ctx context.Context, request models.SetTaxRate) error {
+	t.db.mu.Lock()
+	defer t.db.mu.Unlock()
+
+	row, ok := t.db.t.products[request.ID]
+	if !ok || row.deleted {
+		return pgx.ErrNoRows
+	}
+
+	row.taxRateID = request.TaxRateID
+	row.updatedAt = time.Now()
+	t.db.t.products[request.ID] = row
+
+	return nil
+}
+
+func (t *taxRateRepo) SetCategoryTaxRate(ctx context.Context, request models.SetTaxRate) error {
+	t.db.mu.Lock()
+	defer t.db.mu.Unlock()
+
+	row, ok := t.db.t.categories[request.ID]
+	if !ok || row.deleted {
+		return pgx.ErrNoRows
+	}
+
+	row.taxRateID = request.TaxRateID
+	row.UpdatedAt = time.Now()
+	t.db.t.categories[request.ID] = row
+
+	return nil
+}
+
+// GetProductTaxRate returns the rate the product is taxed at now, a zero rate when none applies.
+func (t *taxRateRepo) GetProductTaxRate(ctx context.Context, productID string) (models.ProductTaxRate, error) {
+	t.db.mu.Lock()
+	defer t.db.mu.Unlock()
+
+	if row, ok := t.db.t.products[productID]; !ok || row.deleted {
+		return models.ProductTaxRate{}, pgx.ErrNoRows
+	}
+
+	return t.db.taxRateOf(productID), nil
+}
+
+// taxRateOf returns the rate of the product, of the product it is a variant of, or of the nearest category
+// up the tree of the product or its parent. Deleted rates are skipped, the rate is zero when none applies.
+func (s *Store) taxRateOf(productID string) models.ProductTaxRate {
+	rate := models.ProductTaxRate{ProductID: productID}
+
+	product, ok := s.t.products[productID]
+	if !ok {
+		return rate
+	}
+	parent, hasParent := s.t.products[product.parentID]
+
+	candidates := []struct{ taxRateID, source string }{{product.taxRateID, "product"}}
+	if hasParent {
+		candidates = append(candidates, struct{ taxRateID, source string }{parent.taxRateID, "parent"})
+	}
+
+	categoryID := product.categoryID
+	if categoryID == "" && hasParent {
+		categoryID = parent.categoryID
+	}
+	// the depth stops at a parent loop which may be left in data saved before moves were checked
+	for depth := 2; categoryID != "" && depth <= 100; depth++ {
+		category, ok := s.t.categories[categoryID]
+		if !ok || category.deleted {
+			break
+		}
+		candidates = append(candidates, struct{ taxRateID, source string }{category.taxRateID, "category"})
+		categoryID = category.ParentID
+	}
+
+	for _, candidate := range candidates {
+		if taxRate, ok := s.t.taxRates[candidate.taxRateID]; ok && !taxRate.deleted {
+			rate.TaxRateID = taxRate.ID
+			rate.Name = taxRate.Name
+			rate.Rate = taxRate.Rate
+			rate.Source = candidate.source
+			return rate
+		}
+	}
+
+	return rate
+}
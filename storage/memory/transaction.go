@@ -0,0 +1,145 @@
+package memory
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"sell/api/models"
+	"sell/storage"
+	"sort"
+	"time"
+)
+
+type transactionRow struct {
+	models.Transaction
+	seq     int64
+	deleted bool
+}
+
+type transactionRepo struct {
+	db *Store
+}
+
+func NewTransactionRepo(db *Store) storage.ITransactionStorage {
+	return transactionRepo{db: db}
+}
+
+func (t transactionRepo) Create(ctx context.Context, trans models.CreateTransaction) (string, error) {
+	t.db.mu.Lock()
+	defer t.db.mu.Unlock()
+
+	id := uuid.New().String()
+	now := time.Now()
+	t.db.t.transactions[id] = transactionRow{
+		Transaction: models.Transaction{
+			ID:              id,
+			SaleID:          trans.SaleID,
+			StaffID:         trans.StaffID,
+			TransactionType: trans.TransactionType,
+			SourceType:      trans.SourceType,
+			Amount:          trans.Amount,
+			Description:     trans.Description,
+			CreatedAt:       now,
+			UpdatedAt:       now,
+		},
+		seq: t.db.next(),
+	}
+
+	return id, nil
+}
+
+func (t transactionRepo) GetByID(ctx context.Context, id string) (models.Transaction, error) {
+	t.db.mu.Lock()
+	defer t.db.mu.Unlock()
+
+	row, ok := t.db.t.transactions[id]
+	if !ok || row.deleted {
+		return models.Transaction{}, pgx.ErrNoRows
+	}
+
+	return row.Transaction, nil
+}
+
+// GetList filters amounts like the postgres repo: between both bounds when both are given, from the lower bound
+// when only it is given and up to the upper bound otherwise, so no bounds at all lists amounts up to 0.
+func (t transactionRepo) GetList(ctx context.Context, request models.TransactionGetListRequest) (models.TransactionResponse, error) {
+	t.db.mu.Lock()
+	defer t.db.mu.Unlock()
+
+	from, to := request.FromAmount, request.ToAmount
+	rows := []transactionRow{}
+	for _, row := range t.db.t.transactions {
+		if row.deleted {
+			continue
+		}
+
+		amount := row.Amount
+		switch {
+		case from != 0 && to != 0:
+			if amount < from || amount > to {
+				continue
+			}
+		case from != 0:
+			if amount < from {
+				continue
+			}
+		default:
+			if amount > to {
+				continue
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		if rows[i].Amount != rows[j].Amount {
+			return rows[i].Amount < rows[j].Amount
+		}
+		return rows[i].seq > rows[j].seq
+	})
+
+	page, err := paginate(rows, request.Page, request.Limit)
+	if err != nil {
+		return models.TransactionResponse{}, err
+	}
+
+	transactions := []models.Transaction{}
+	for _, row := range page {
+		transactions = append(transactions, row.Transaction)
+	}
+
+	return models.TransactionResponse{
+		Transactions: transactions,
+		Count:        len(rows),
+	}, nil
+}
+
+func (t transactionRepo) Update(ctx context.Context, transaction models.UpdateTransaction) (string, error) {
+	t.db.mu.Lock()
+	defer t.db.mu.Unlock()
+
+	if row, ok := t.db.t.transactions[transaction.ID]; ok {
+		row.SaleID = transaction.SaleID
+		row.StaffID = transaction.StaffID
+		row.TransactionType = transaction.TransactionType
+		row.SourceType = transaction.SourceType
+		row.Amount = transaction.Amount
+		row.Description = transaction.Description
+		row.UpdatedAt = time.Now()
+		t.db.t.transactions[transaction.ID] = row
+	}
+
+	return transaction.ID, nil
+}
+
+func (t transactionRepo) Delete(ctx context.Context, id string) error {
+	t.db.mu.Lock()
+	defer t.db.mu.Unlock()
+
+	if row, ok := t.db.t.transactions[id]; ok {
+		row.deleted = true
+		t.db.t.transactions[id] = row
+	}
+
+	return nil
+}
@@ -0,0 +1,343 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"sell/api/models"
+	"sell/storage"
+	"sort"
+	"time"
+)
+
+// writeOffReasons are the values of write_off_reason_enum in the order the enum sorts them.
+var writeOffReasons = []string{"damage", "expiry", "theft", "internal_use"}
+
+type writeOffRow struct {
+	models.WriteOff
+	seq     int64
+	deleted bool
+}
+
+type writeOffProductRow struct {
+	models.WriteOffProduct
+	seq     int64
+	deleted bool
+}
+
+type writeOffRepo struct {
+	db *Store
+}
+
+func NewWriteOffRepo(db *Store) storage.IWriteOffStorage {
+	return &writeOffRepo{db: db}
+}
+
+func (w *writeOffRepo) Create(ctx context.Context, writeOff models.CreateWriteOff) (string, error) {
+	if reasonOrder(writeOff.Reason) < 0 {
+		return "", &pgconn.PgError{
+			Code:    "22P02",
+			Message: `invalid input value for enum write_off_reason_enum: "` + writeOff.Reason + `"`,
+		}
+	}
+
+	id := uuid.New().String()
+	err := w.db.tx(func() error {
+		now := time.Now()
+		row := writeOffRow{
+			WriteOff: models.WriteOff{
+				ID:        id,
+				BranchID:  writeOff.BranchID,
+				Reason:    writeOff.Reason,
+				Status:    "pending",
+				Comment:   writeOff.Comment,
+				CreatedBy: writeOff.CreatedBy,
+				CreatedAt: now,
+				UpdatedAt: now,
+			},
+			seq: w.db.next(),
+		}
+
+		for _, product := range writeOff.Products {
+			if p, ok := w.db.t.products[product.ProductID]; !ok || p.deleted {
+				return fmt.Errorf("product %s not found", product.ProductID)
+			}
+
+			// the line keeps the product price of the branch at the moment of writing off
+			line := writeOffProductRow{
+				WriteOffProduct: models.WriteOffProduct{
+					ID:         uuid.New().String(),
+					WriteOffID: id,
+					ProductID:  product.ProductID,
+					Price:      w.db.effectivePrice(product.ProductID, writeOff.BranchID, now),
+					Quantity:   product.Quantity,
+					CreatedAt:  now,
+				},
+				seq: w.db.next(),
+			}
+			w.db.t.writeOffProducts[line.ID] = line
+
+			price, err := line.Price.Mul(line.Quantity)
+			if err != nil {
+				return err
+			}
+			row.Price += price
+		}
+
+		w.db.t.writeOffs[id] = row
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return id, nil
+}
+
+func (w *writeOffRepo) GetByID(ctx context.Context, id string) (models.WriteOff, error) {
+	w.db.mu.Lock()
+	defer w.db.mu.Unlock()
+
+	row, ok := w.db.t.writeOffs[id]
+	if !ok || row.deleted {
+		return models.WriteOff{}, pgx.ErrNoRows
+	}
+
+	writeOff := row.WriteOff
+	writeOff.Products = []models.WriteOffProduct{}
+	for _, product := range w.db.writeOffProducts(id) {
+		writeOff.Products = append(writeOff.Products, product.WriteOffProduct)
+	}
+
+	return writeOff, nil
+}
+
+func (w *writeOffRepo) GetList(ctx context.Context, request models.WriteOffGetListRequest) (models.WriteOffResponse, error) {
+	w.db.mu.Lock()
+	defer w.db.mu.Unlock()
+
+	rows := []writeOffRow{}
+	for _, row := range w.db.t.writeOffs {
+		if row.deleted ||
+			request.BranchID != "" && row.BranchID != request.BranchID ||
+			request.Reason != "" && row.Reason != request.Reason ||
+			request.Status != "" && row.Status != request.Status {
+			continue
+		}
+		rows = append(rows, row)
+	}
+	sortBySeq(rows, func(row writeOffRow) int64 { return row.seq }, true)
+
+	page, err := paginate(rows, request.Page, request.Limit)
+	if err != nil {
+		return models.WriteOffResponse{}, err
+	}
+
+	writeOffs := []models.WriteOff{}
+	for _, row := range page {
+		writeOffs = append(writeOffs, row.WriteOff)
+	}
+
+	return models.WriteOffResponse{
+		WriteOffs: writeOffs,
+		Count:     len(rows),
+	}, nil
+}
+
+// Approve deducts every line of a pending write-off from the branch repository
+// and records a minus repository transaction tagged with the write-off reason.
+func (w *writeOffRepo) Approve(ctx context.Context, request models.ApproveWriteOff) error {
+	return w.db.tx(func() error {
+		row, ok := w.db.t.writeOffs[request.ID]
+		if !ok || row.deleted {
+			return pgx.ErrNoRows
+		}
+
+		if row.Status != "pending" {
+			return storage.ErrWriteOffNotPending
+		}
+
+		for _, product := range w.db.writeOffProducts(request.ID) {
+			price, err := product.Price.Mul(product.Quantity)
+			if err != nil {
+				return err
+			}
+
+			if err := w.db.takeStock(row.BranchID, product.ProductID, product.Quantity); err != nil {
+				return err
+			}
+
+			w.db.insertMovement(models.CreateRepositoryTransaction{
+				BranchID:                  row.BranchID,
+				ProductID:                 product.ProductID,
+				RepositoryTransactionType: "minus",
+				Reason:                    row.Reason,
+				SourceType:                "write_off",
+				SourceID:                  request.ID,
+				Price:                     price,
+				Quantity:                  product.Quantity,
+			})
+
+			w.db.deductBatches(row.BranchID, product.ProductID, product.Quantity)
+		}
+
+		now := time.Now()
+		row.Status = "approved"
+		row.ApprovedBy = request.ApprovedBy
+		row.ApprovedAt = &now
+		row.UpdatedAt = now
+		w.db.t.writeOffs[request.ID] = row
+
+		return nil
+	})
+}
+
+func (w *writeOffRepo) Reject(ctx context.Context, request models.ApproveWriteOff) error {
+	w.db.mu.Lock()
+	defer w.db.mu.Unlock()
+
+	row, ok := w.db.t.writeOffs[request.ID]
+	if !ok || row.deleted || row.Status != "pending" {
+		return storage.ErrWriteOffNotPending
+	}
+
+	now := time.Now()
+	row.Status = "rejected"
+	row.ApprovedBy = request.ApprovedBy
+	row.ApprovedAt = &now
+	row.UpdatedAt = now
+	w.db.t.writeOffs[request.ID] = row
+
+	return nil
+}
+
+func (w *writeOffRepo) Delete(ctx context.Context, id string) error {
+	w.db.mu.Lock()
+	defer w.db.mu.Unlock()
+
+	row, ok := w.db.t.writeOffs[id]
+	if !ok || row.Status != "pending" {
+		return errors.New("only pending write-offs can be deleted")
+	}
+
+	row.deleted = true
+	w.db.t.writeOffs[id] = row
+
+	return nil
+}
+
+// Report groups approved write-offs by reason, filtered by approval date.
+func (w *writeOffRepo) Report(ctx context.Context, request models.WriteOffReportRequest) (models.WriteOffReport, error) {
+	var from, to *time.Time
+	if request.FromDate != "" {
+		at, err := parseDate(request.FromDate)
+		if err != nil {
+			return models.WriteOffReport{}, err
+		}
+		from = &at
+	}
+	if request.ToDate != "" {
+		at, err := parseDate(request.ToDate)
+		if err != nil {
+			return models.WriteOffReport{}, err
+		}
+		at = at.AddDate(0, 0, 1)
+		to = &at
+	}
+
+	w.db.mu.Lock()
+	defer w.db.mu.Unlock()
+
+	type group struct {
+		row       models.WriteOffReportRow
+		writeOffs map[string]bool
+	}
+
+	groups := map[string]*group{}
+	for _, product := range w.db.t.writeOffProducts {
+		writeOff, ok := w.db.t.writeOffs[product.WriteOffID]
+		if product.deleted || !ok || writeOff.deleted || writeOff.Status != "approved" ||
+			request.BranchID != "" && writeOff.BranchID != request.BranchID ||
+			from != nil && (writeOff.ApprovedAt == nil || writeOff.ApprovedAt.Before(*from)) ||
+			to != nil && (writeOff.ApprovedAt == nil || !writeOff.ApprovedAt.Before(*to)) {
+			continue
+		}
+
+		g := groups[writeOff.Reason]
+		if g == nil {
+			g = &group{row: models.WriteOffReportRow{Reason: writeOff.Reason}, writeOffs: map[string]bool{}}
+			groups[writeOff.Reason] = g
+		}
+		g.writeOffs[writeOff.ID] = true
+		g.row.Quantity += product.Quantity
+		price, err := product.Price.Mul(product.Quantity)
+		if err != nil {
+			return models.WriteOffReport{}, err
+		}
+		g.row.Price += price
+	}
+
+	report := models.WriteOffReport{Reasons: []models.WriteOffReportRow{}}
+	for _, g := range groups {
+		g.row.Documents = len(g.writeOffs)
+		report.Reasons = append(report.Reasons, g.row)
+	}
+	sort.Slice(report.Reasons, func(i, j int) bool {
+		return reasonOrder(report.Reasons[i].Reason) < reasonOrder(report.Reasons[j].Reason)
+	})
+
+	for _, row := range report.Reasons {
+		report.TotalQuantity += row.Quantity
+		report.TotalPrice += row.Price
+	}
+
+	return report, nil
+}
+
+// writeOffProducts returns the live lines of the write-off in the order they were added.
+func (s *Store) writeOffProducts(writeOffID string) []writeOffProductRow {
+	rows := []writeOffProductRow{}
+	for _, row := range s.t.writeOffProducts {
+		if row.WriteOffID == writeOffID && !row.deleted {
+			rows = append(rows, row)
+		}
+	}
+	sortBySeq(rows, func(row writeOffProductRow) int64 { return row.seq }, false)
+
+	return rows
+}
+
+// takeStock deducts quantity from the oldest repository of the product in the branch which holds enough of it.
+func (s *Store) takeStock(branchID, productID string, quantity float64) error {
+	var (
+		found bool
+		first repositoryRow
+	)
+	for _, row := range s.t.repositories {
+		if row.BranchID == branchID && row.ProductID == productID && !row.deleted && row.Count >= quantity &&
+			(!found || row.seq < first.seq) {
+			first, found = row, true
+		}
+	}
+	if !found {
+		return storage.ErrNotEnoughProduct
+	}
+
+	first.Count -= quantity
+	first.UpdatedAt = time.Now()
+	s.t.repositories[first.ID] = first
+
+	return nil
+}
+
+func reasonOrder(reason string) int {
+	for i, r := range writeOffReasons {
+		if r == reason {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,115 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"sell/api/models"
+	"sell/storage"
+)
+
+type barcodeFormatRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewBarcodeFormatRepo(db *pgxpool.Pool) storage.IBarcodeFormatStorage {
+	return &barcodeFormatRepo{db: db}
+}
+
+func (b *barcodeFormatRepo) Create(ctx context.Context, format models.CreateBarcodeFormat) (string, error) {
+	id := uuid.New()
+	query := `insert into barcode_formats (id, name, prefix, code_length, product_code_start, product_code_length,
+                             value_start, value_length, value_type, value_decimals)
+					values($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`
+	if _, err := b.db.Exec(ctx, query,
+		id,
+		format.Name,
+		format.Prefix,
+		format.CodeLength,
+		format.ProductCodeStart,
+		format.ProductCodeLength,
+		format.ValueStart,
+		format.ValueLength,
+		format.ValueType,
+		format.ValueDecimals,
+	); err != nil {
+		fmt.Println("error is while inserting barcode format", err.Error())
+		return "", err
+	}
+	return id.String(), nil
+}
+
+func (b *barcodeFormatRepo) GetByID(ctx context.Context, id string) (models.BarcodeFormat, error) {
+	format := models.BarcodeFormat{}
+	query := `select id, coalesce(name, ''), prefix, code_length, product_code_start, product_code_length,
+       				value_start, value_length, value_type, value_decimals, created_at, updated_at
+						from barcode_formats where id = $1 and deleted_at is null`
+	if err := b.db.QueryRow(ctx, query, id).Scan(
+		&format.ID,
+		&format.Name,
+		&format.Prefix,
+		&format.CodeLength,
+		&format.ProductCodeStart,
+		&format.ProductCodeLength,
+		&format.ValueStart,
+		&format.ValueLength,
+		&format.ValueType,
+		&format.ValueDecimals,
+		&format.CreatedAt,
+		&format.UpdatedAt,
+	); err != nil {
+		fmt.Println("error is while selecting barcode format", err.Error())
+		return models.BarcodeFormat{}, err
+	}
+	return format, nil
+}
+
+// GetList returns all formats, longer prefixes first so the most specific format is tried first.
+func (b *barcodeFormatRepo) GetList(ctx context.Context) (models.BarcodeFormatsResponse, error) {
+	formats := []models.BarcodeFormat{}
+	query := `select id, coalesce(name, ''), prefix, code_length, product_code_start, product_code_length,
+       				value_start, value_length, value_type, value_decimals, created_at, updated_at
+						from barcode_formats where deleted_at is null order by length(prefix) desc, created_at`
+	rows, err := b.db.Query(ctx, query)
+	if err != nil {
+		fmt.Println("error is while selecting barcode formats", err.Error())
+		return models.BarcodeFormatsResponse{}, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		format := models.BarcodeFormat{}
+		if err := rows.Scan(
+			&format.ID,
+			&format.Name,
+			&format.Prefix,
+			&format.CodeLength,
+			&format.ProductCodeStart,
+			&format.ProductCodeLength,
+			&format.ValueStart,
+			&format.ValueLength,
+			&format.ValueType,
+			&format.ValueDecimals,
+			&format.CreatedAt,
+			&format.UpdatedAt,
+		); err != nil {
+			fmt.Println("error is while scanning barcode formats", err.Error())
+			return models.BarcodeFormatsResponse{}, err
+		}
+		formats = append(formats, format)
+	}
+
+	return models.BarcodeFormatsResponse{
+		BarcodeFormats: formats,
+		Count:          len(formats),
+	}, nil
+}
+
+func (b *barcodeFormatRepo) Delete(ctx context.Context, id string) error {
+	if _, err := b.db.Exec(ctx, `update barcode_formats set deleted_at = now() where id = $1`, id); err != nil {
+		fmt.Println("error is while deleting barcode format", err.Error())
+		return err
+	}
+	return nil
+}
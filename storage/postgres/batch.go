@@ -0,0 +1,207 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"sell/api/models"
+	"sell/storage"
+)
+
+type batchRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewBatchRepo(db *pgxpool.Pool) storage.IBatchStorage {
+	return &batchRepo{db: db}
+}
+
+func (b *batchRepo) Create(ctx context.Context, batch models.CreateBatch) (string, error) {
+	id := uuid.New()
+	query := `insert into repository_batches (id, branch_id, product_id, income_product_id, batch_number, expiry_date, count)
+					values($1, $2, $3, nullif($4, '')::uuid, nullif($5, ''), nullif($6, '')::date, $7)`
+	if _, err := b.db.Exec(ctx, query,
+		id,
+		batch.BranchID,
+		batch.ProductID,
+		batch.IncomeProductID,
+		batch.BatchNumber,
+		batch.ExpiryDate,
+		batch.Count,
+	); err != nil {
+		fmt.Println("error is while inserting batch", err.Error())
+		return "", err
+	}
+	return id.String(), nil
+}
+
+func (b *batchRepo) GetList(ctx context.Context, request models.BatchGetListRequest) (models.BatchResponse, error) {
+	var (
+		count   int
+		batches = []models.Batch{}
+		filter  string
+		args    []interface{}
+		offset  = (request.Page - 1) * request.Limit
+	)
+
+	if request.BranchID != "" {
+		args = append(args, request.BranchID)
+		filter += fmt.Sprintf(` and branch_id = $%d`, len(args))
+	}
+
+	if request.ProductID != "" {
+		args = append(args, request.ProductID)
+		filter += fmt.Sprintf(` and product_id = $%d`, len(args))
+	}
+
+	countQuery := `select count(1) from repository_batches where deleted_at is null and count > 0 ` + filter
+	if err := b.db.QueryRow(ctx, countQuery, args...).Scan(&count); err != nil {
+		fmt.Println("error is while selecting count of batches", err.Error())
+		return models.BatchResponse{}, err
+	}
+
+	query := `select id, branch_id, product_id, coalesce(income_product_id::text, ''), coalesce(batch_number, ''),
+       				coalesce(expiry_date::text, ''), count, created_at, updated_at
+						from repository_batches where deleted_at is null and count > 0 ` + filter +
+		fmt.Sprintf(` order by expiry_date nulls last, created_at LIMIT $%d OFFSET $%d`, len(args)+1, len(args)+2)
+
+	rows, err := b.db.Query(ctx, query, append(args, request.Limit, offset)...)
+	if err != nil {
+		fmt.Println("error is while selecting batches", err.Error())
+		return models.BatchResponse{}, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		batch := models.Batch{}
+		if err := rows.Scan(
+			&batch.ID,
+			&batch.BranchID,
+			&batch.ProductID,
+			&batch.IncomeProductID,
+			&batch.BatchNumber,
+			&batch.ExpiryDate,
+			&batch.Count,
+			&batch.CreatedAt,
+			&batch.UpdatedAt,
+		); err != nil {
+			fmt.Println("error is while scanning batches", err.Error())
+			return models.BatchResponse{}, err
+		}
+		batches = append(batches, batch)
+	}
+
+	return models.BatchResponse{
+		Batches: batches,
+		Count:   count,
+	}, nil
+}
+
+// GetExpiring returns batches with stock left that expire within the given days,
+// already expired batches are included with a negative days_left.
+func (b *batchRepo) GetExpiring(ctx context.Context, request models.ExpiringBatchesRequest) (models.ExpiringBatchesResponse, error) {
+	var (
+		batches = []models.ExpiringBatch{}
+		args    = []interface{}{request.Days}
+		filter  string
+	)
+
+	if request.BranchID != "" {
+		args = append(args, request.BranchID)
+		filter += fmt.Sprintf(` and branch_id = $%d`, len(args))
+	}
+
+	query := `select id, branch_id, product_id, coalesce(income_product_id::text, ''), coalesce(batch_number, ''),
+       				expiry_date::text, count, created_at, updated_at, expiry_date - current_date
+						from repository_batches where deleted_at is null and count > 0
+						    and expiry_date is not null and expiry_date <= current_date + $1::int ` + filter + `
+								order by expiry_date, created_at`
+
+	rows, err := b.db.Query(ctx, query, args...)
+	if err != nil {
+		fmt.Println("error is while selecting expiring batches", err.Error())
+		return models.ExpiringBatchesResponse{}, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		batch := models.ExpiringBatch{}
+		if err := rows.Scan(
+			&batch.ID,
+			&batch.BranchID,
+			&batch.ProductID,
+			&batch.IncomeProductID,
+			&batch.BatchNumber,
+			&batch.ExpiryDate,
+			&batch.Count,
+			&batch.CreatedAt,
+			&batch.UpdatedAt,
+			&batch.DaysLeft,
+		); err != nil {
+			fmt.Println("error is while scanning expiring batches", err.Error())
+			return models.ExpiringBatchesResponse{}, err
+		}
+		batches = append(batches, batch)
+	}
+
+	return models.ExpiringBatchesResponse{
+		Batches: batches,
+		Count:   len(batches),
+	}, nil
+}
+
+// deductBatches takes quantity from the earliest expiring batches of a product in a branch.
+// Stock received before batches were tracked has no batch rows, so running out of batches is not an error:
+// the repository count is what limits the movement, the quantity the batches did not cover is only logged.
+func deductBatches(ctx context.Context, tx pgx.Tx, branchID, productID string, quantity float64) error {
+	rows, err := tx.Query(ctx, `select id, count from repository_batches
+					where branch_id = $1 and product_id = $2 and count > 0 and deleted_at is null
+						order by expiry_date nulls last, created_at for update`, branchID, productID)
+	if err != nil {
+		fmt.Println("error is while selecting batches for update", err.Error())
+		return err
+	}
+
+	type batchCount struct {
+		id    string
+		count float64
+	}
+
+	batches := []batchCount{}
+	for rows.Next() {
+		batch := batchCount{}
+		if err := rows.Scan(&batch.id, &batch.count); err != nil {
+			rows.Close()
+			fmt.Println("error is while scanning batches", err.Error())
+			return err
+		}
+		batches = append(batches, batch)
+	}
+	rows.Close()
+
+	for _, batch := range batches {
+		if quantity <= 0 {
+			break
+		}
+
+		taken := batch.count
+		if quantity < taken {
+			taken = quantity
+		}
+
+		if _, err := tx.Exec(ctx, `update repository_batches set count = count - $1, updated_at = now() where id = $2`,
+			taken, batch.id); err != nil {
+			fmt.Println("error is while updating batch count", err.Error())
+			return err
+		}
+		quantity = roundQuantity(quantity - taken)
+	}
+
+	if quantity > 0 {
+		fmt.Println("batches of product", productID, "in branch", branchID, "are short of", quantity)
+	}
+
+	return nil
+}
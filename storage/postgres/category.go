@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"sell/api/models"
 	"sell/storage"
@@ -19,8 +20,8 @@ func NewCategoryRepo(db *pgxpool.Pool) storage.ICategory {
 
 func (c categoryRepo) Create(ctx context.Context, category models.CreateCategory) (string, error) {
 	id := uuid.New()
-	query := `insert into categories (id, name, parent_id) values($1, $2, $3)`
-	if _, err := c.db.Exec(ctx, query, id, category.Name, category.ParentID); err != nil {
+	query := `insert into categories (id, name, parent_id, name_translations) values($1, $2, nullif($3, ''), coalesce($4, '{}'))`
+	if _, err := c.db.Exec(ctx, query, id, category.Name, category.ParentID, category.NameTranslations); err != nil {
 		fmt.Println("error is while inserting data", err.Error())
 		return "", err
 	}
@@ -29,10 +30,11 @@ func (c categoryRepo) Create(ctx context.Context, category models.CreateCategory
 
 func (c categoryRepo) GetByID(ctx context.Context, id string) (models.Category, error) {
 	category := models.Category{}
-	query := `select id, name, parent_id, created_at, updated_at from categories where id = $1 and deleted_at is null`
+	query := `select id, name, name_translations, coalesce(parent_id, ''), created_at, updated_at from categories where id = $1 and deleted_at is null`
 	if err := c.db.QueryRow(ctx, query, id).Scan(
 		&category.ID,
 		&category.Name,
+		&category.NameTranslations,
 		&category.ParentID,
 		&category.CreatedAt,
 		&category.UpdatedAt); err != nil {
@@ -60,7 +62,7 @@ func (c categoryRepo) GetList(ctx context.Context, request models.GetListRequest
 		return models.CategoryResponse{}, err
 	}
 
-	query = `select id, name, parent_id, created_at, updated_at from categories where deleted_at is null `
+	query = `select id, name, name_translations, coalesce(parent_id, ''), created_at, updated_at from categories where deleted_at is null `
 	if search != "" {
 		query += fmt.Sprintf(` and name ilike '%%%s%%'`, search)
 	}
@@ -77,6 +79,7 @@ func (c categoryRepo) GetList(ctx context.Context, request models.GetListRequest
 		if err = rows.Scan(
 			&category.ID,
 			&category.Name,
+			&category.NameTranslations,
 			&category.ParentID,
 			&category.CreatedAt,
 			&category.UpdatedAt); err != nil {
@@ -91,20 +94,261 @@ func (c categoryRepo) GetList(ctx context.Context, request models.GetListRequest
 	}, nil
 }
 
-func (c categoryRepo) Update(ctx context.Context, category models.UpdateCategory) (string, error) {
-	query := `update categories set name = $1, parent_id = $2, updated_at = now() where id = $3`
-	if _, err := c.db.Exec(ctx, query, &category.Name, &category.ParentID, &category.ID); err != nil {
+func (c categoryRepo) Update(ctx context.Context, category models.UpdateCategory) (_ string, err error) {
+	tx, err := c.db.Begin(ctx)
+	if err != nil {
+		fmt.Println("error is while beginning transaction", err.Error())
+		return "", err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+			return
+		}
+		err = tx.Commit(ctx)
+	}()
+
+	if err = checkCategoryParent(ctx, tx, category.ID, category.ParentID); err != nil {
+		return "", err
+	}
+
+	query := `update categories set name = $1, parent_id = nullif($2, ''), name_translations = coalesce($4, name_translations), 
+                      updated_at = now() where id = $3`
+	if _, err = tx.Exec(ctx, query, &category.Name, &category.ParentID, &category.ID, category.NameTranslations); err != nil {
 		fmt.Println("error is while updating", err.Error())
 		return "", err
 	}
 	return category.ID, nil
 }
 
-func (c categoryRepo) Delete(ctx context.Context, id string) error {
-	query := `update categories set deleted_at = now() where id = $1`
-	if _, err := c.db.Exec(ctx, query, id); err != nil {
+// Move puts the category with its subtree under parentID, an empty parentID makes it a root category.
+func (c categoryRepo) Move(ctx context.Context, id, parentID string) (err error) {
+	tx, err := c.db.Begin(ctx)
+	if err != nil {
+		fmt.Println("error is while beginning transaction", err.Error())
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+			return
+		}
+		err = tx.Commit(ctx)
+	}()
+
+	if err = checkCategoryParent(ctx, tx, id, parentID); err != nil {
+		return err
+	}
+
+	tag, err := tx.Exec(ctx, `update categories set parent_id = nullif($1, ''), updated_at = now() 
+                  where id = $2 and deleted_at is null`, parentID, id)
+	if err != nil {
+		fmt.Println("error is while moving category", err.Error())
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		err = pgx.ErrNoRows
+		return err
+	}
+
+	return nil
+}
+
+// Delete removes a category. With mode refuse a category with subcategories or products is kept,
+// cascade removes the whole subtree with its products and reparent hands the subcategories
+// and products over to the parent of the category.
+func (c categoryRepo) Delete(ctx context.Context, id, mode string) (err error) {
+	tx, err := c.db.Begin(ctx)
+	if err != nil {
+		fmt.Println("error is while beginning transaction", err.Error())
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+			return
+		}
+		err = tx.Commit(ctx)
+	}()
+
+	var parentID string
+	if err = tx.QueryRow(ctx, `select coalesce(parent_id, '') from categories where id = $1 and deleted_at is null for update`,
+		id).Scan(&parentID); err != nil {
+		fmt.Println("error is while selecting category", err.Error())
+		return err
+	}
+
+	switch mode {
+	case "cascade":
+		// the barcodes go with the products, so they can be given to other products
+		if _, err = tx.Exec(ctx, `with deleted as (
+				update products set deleted_at = now() 
+                where deleted_at is null and (category_id in (`+categorySubtree("$1")+`) 
+                   or parent_id in (select id from products where category_id in (`+categorySubtree("$1")+`)))
+                returning id
+			)
+			update product_barcodes set deleted_at = now() where product_id in (select id from deleted) and deleted_at is null`, id); err != nil {
+			fmt.Println("error is while deleting category products", err.Error())
+			return err
+		}
+
+		if _, err = tx.Exec(ctx, `update categories set deleted_at = now() where id in (`+categorySubtree("$1")+`)`, id); err != nil {
+			fmt.Println("error is while deleting categories", err.Error())
+			return err
+		}
+
+		return nil
+	case "reparent":
+		if _, err = tx.Exec(ctx, `update categories set parent_id = nullif($1, ''), updated_at = now() 
+                  where parent_id = $2 and deleted_at is null`, parentID, id); err != nil {
+			fmt.Println("error is while reparenting categories", err.Error())
+			return err
+		}
+
+		if _, err = tx.Exec(ctx, `update products set category_id = nullif($1, ''), updated_at = now() 
+                where category_id = $2 and deleted_at is null`, parentID, id); err != nil {
+			fmt.Println("error is while reparenting products", err.Error())
+			return err
+		}
+	default:
+		var children, products int
+		if err = tx.QueryRow(ctx, `select 
+    			(select count(1) from categories where parent_id = $1 and deleted_at is null),
+    			(select count(1) from products where category_id = $1 and deleted_at is null)`, id).Scan(
+			&children,
+			&products,
+		); err != nil {
+			fmt.Println("error is while counting category contents", err.Error())
+			return err
+		}
+
+		if children > 0 || products > 0 {
+			err = storage.ErrCategoryNotEmpty
+			return err
+		}
+	}
+
+	if _, err = tx.Exec(ctx, `update categories set deleted_at = now() where id = $1`, id); err != nil {
 		fmt.Println("error is while deleting", err.Error())
 		return err
 	}
+
+	return nil
+}
+
+// Tree returns the root categories, or the category rootID, with all their subcategories.
+func (c categoryRepo) Tree(ctx context.Context, rootID string) ([]models.CategoryTree, error) {
+	query := `with recursive tree as (
+					select id, name, name_translations, coalesce(parent_id, '') as parent_id, created_at from categories
+						where deleted_at is null and (($1 = '' and parent_id is null) or id = $1)
+					union
+					select c.id, c.name, c.name_translations, c.parent_id, c.created_at from categories c
+						join tree on c.parent_id = tree.id where c.deleted_at is null
+				)
+				select t.id, t.name, t.name_translations, t.parent_id, 
+				       (select count(1) from products p where p.category_id = t.id and p.deleted_at is null)
+					from tree t order by t.name, t.created_at`
+
+	rows, err := c.db.Query(ctx, query, rootID)
+	if err != nil {
+		fmt.Println("error is while selecting category tree", err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	var (
+		nodes    = map[string]*models.CategoryTree{}
+		order    = []*models.CategoryTree{}
+		children = map[string][]*models.CategoryTree{}
+	)
+	for rows.Next() {
+		node := models.CategoryTree{}
+		if err := rows.Scan(
+			&node.ID,
+			&node.Name,
+			&node.NameTranslations,
+			&node.ParentID,
+			&node.ProductCount,
+		); err != nil {
+			fmt.Println("error is while scanning category tree", err.Error())
+			return nil, err
+		}
+		nodes[node.ID] = &node
+		order = append(order, &node)
+		children[node.ParentID] = append(children[node.ParentID], &node)
+	}
+
+	if err := rows.Err(); err != nil {
+		fmt.Println("error is while selecting category tree", err.Error())
+		return nil, err
+	}
+
+	if rootID != "" && nodes[rootID] == nil {
+		return nil, pgx.ErrNoRows
+	}
+
+	// visited stops at a parent loop which may be left in data saved before moves were checked
+	visited := map[string]bool{}
+	var build func(node *models.CategoryTree) models.CategoryTree
+	build = func(node *models.CategoryTree) models.CategoryTree {
+		visited[node.ID] = true
+		tree := *node
+		tree.TotalProductCount = tree.ProductCount
+		tree.Children = []models.CategoryTree{}
+		for _, child := range children[node.ID] {
+			if visited[child.ID] {
+				continue
+			}
+			subtree := build(child)
+			tree.TotalProductCount += subtree.TotalProductCount
+			tree.Children = append(tree.Children, subtree)
+		}
+		return tree
+	}
+
+	roots := []models.CategoryTree{}
+	for _, node := range order {
+		if (rootID == "" && node.ParentID == "") || node.ID == rootID {
+			roots = append(roots, build(node))
+		}
+	}
+
+	return roots, nil
+}
+
+// checkCategoryParent makes sure parentID exists and is not the category itself or one of its descendants.
+func checkCategoryParent(ctx context.Context, tx pgx.Tx, id, parentID string) error {
+	if parentID == "" {
+		return nil
+	}
+
+	// locking the two rows is not enough, moves of A under B and of B under A would each see no cycle,
+	// so parent changes are serialized by a lock held until the transaction ends
+	if _, err := tx.Exec(ctx, `select pg_advisory_xact_lock(hashtext('categories.parent_id'))`); err != nil {
+		fmt.Println("error is while locking category parents", err.Error())
+		return err
+	}
+
+	var inSubtree bool
+	if err := tx.QueryRow(ctx, `select $2 in (`+categorySubtree("$1")+`) from categories where id = $2 and deleted_at is null for update`,
+		id, parentID).Scan(&inSubtree); err != nil {
+		fmt.Println("error is while selecting parent category", err.Error())
+		return err
+	}
+
+	if inSubtree {
+		return storage.ErrCategoryCycle
+	}
+
 	return nil
 }
+
+// categorySubtree selects the ids of the category given by the query parameter and all its descendants.
+func categorySubtree(param string) string {
+	return `with recursive subtree as (
+					select id from categories where id = ` + param + ` and deleted_at is null
+					union
+					select c.id from categories c join subtree on c.parent_id = subtree.id where c.deleted_at is null
+				) select id from subtree`
+}
@@ -0,0 +1,131 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"sell/api/models"
+	"sell/storage"
+	"time"
+)
+
+type exchangeRateRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewExchangeRateRepo(db *pgxpool.Pool) storage.IExchangeRateStorage {
+	return &exchangeRateRepo{db: db}
+}
+
+// Create schedules a rate of the currency to the base currency.
+// The rate is used from rate.StartsAt, which is now when it is not given.
+func (e *exchangeRateRepo) Create(ctx context.Context, rate models.CreateExchangeRate) (string, error) {
+	id := uuid.New()
+	if _, err := e.db.Exec(ctx, `insert into exchange_rates (id, currency, rate, starts_at)
+					values($1, $2, $3, coalesce(nullif($4, '')::timestamp, now()))`,
+		id,
+		rate.Currency,
+		rate.Rate,
+		rate.StartsAt,
+	); err != nil {
+		fmt.Println("error is while inserting exchange rate", err.Error())
+		return "", err
+	}
+
+	return id.String(), nil
+}
+
+// GetList returns the rate history with scheduled rates first, of one currency when request.Currency is given.
+func (e *exchangeRateRepo) GetList(ctx context.Context, request models.ExchangeRateGetListRequest) (models.ExchangeRatesResponse, error) {
+	var (
+		rates  = []models.ExchangeRate{}
+		count  int
+		args   = []interface{}{}
+		filter string
+	)
+
+	if request.Currency != "" {
+		args = append(args, request.Currency)
+		filter += fmt.Sprintf(` and currency = $%d`, len(args))
+	}
+
+	if err := e.db.QueryRow(ctx, `select count(1) from exchange_rates where deleted_at is null `+filter,
+		args...).Scan(&count); err != nil {
+		fmt.Println("error is while selecting count of exchange rates", err.Error())
+		return models.ExchangeRatesResponse{}, err
+	}
+
+	query := `select id, currency, rate, starts_at, created_at from exchange_rates
+					where deleted_at is null ` + filter +
+		fmt.Sprintf(` order by starts_at desc, created_at desc LIMIT $%d OFFSET $%d`, len(args)+1, len(args)+2)
+
+	rows, err := e.db.Query(ctx, query, append(args, request.Limit, (request.Page-1)*request.Limit)...)
+	if err != nil {
+		fmt.Println("error is while selecting exchange rates", err.Error())
+		return models.ExchangeRatesResponse{}, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		rate := models.ExchangeRate{}
+		if err := rows.Scan(
+			&rate.ID,
+			&rate.Currency,
+			&rate.Rate,
+			&rate.StartsAt,
+			&rate.CreatedAt,
+		); err != nil {
+			fmt.Println("error is while scanning exchange rates", err.Error())
+			return models.ExchangeRatesResponse{}, err
+		}
+		rates = append(rates, rate)
+	}
+
+	return models.ExchangeRatesResponse{
+		ExchangeRates: rates,
+		Count:         count,
+	}, nil
+}
+
+// Delete cancels a scheduled rate, rates which already started were used for documents and stay.
+func (e *exchangeRateRepo) Delete(ctx context.Context, id string) error {
+	var startsAt time.Time
+	if err := e.db.QueryRow(ctx, `select starts_at from exchange_rates where id = $1 and deleted_at is null`,
+		id).Scan(&startsAt); err != nil {
+		fmt.Println("error is while selecting exchange rate", err.Error())
+		return err
+	}
+
+	tag, err := e.db.Exec(ctx, `update exchange_rates set deleted_at = now(), updated_at = now()
+                      where id = $1 and starts_at > now() and deleted_at is null`, id)
+	if err != nil {
+		fmt.Println("error is while deleting exchange rate", err.Error())
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return storage.ErrRateStarted
+	}
+
+	return nil
+}
+
+// GetEffective returns the rate of the currency at request.At, pgx.ErrNoRows when the currency had none yet.
+func (e *exchangeRateRepo) GetEffective(ctx context.Context, request models.EffectiveRateRequest) (models.ExchangeRate, error) {
+	rate := models.ExchangeRate{}
+	if err := e.db.QueryRow(ctx, `select id, currency, rate, starts_at, created_at from exchange_rates
+					where currency = $1 and starts_at <= $2 and deleted_at is null
+						order by starts_at desc, created_at desc limit 1`, request.Currency, request.At).Scan(
+		&rate.ID,
+		&rate.Currency,
+		&rate.Rate,
+		&rate.StartsAt,
+		&rate.CreatedAt,
+	); err != nil {
+		fmt.Println("error is while selecting effective exchange rate", err.Error())
+		return models.ExchangeRate{}, err
+	}
+
+	return rate, nil
+}
@@ -0,0 +1,222 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"sell/api/models"
+	"sell/storage"
+)
+
+type fiscalRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewFiscalRepo(db *pgxpool.Pool) storage.IFiscalStorage {
+	return &fiscalRepo{db: db}
+}
+
+const fiscalEntryColumns = `id, document_type, document_id, status, attempts, coalesce(last_error, ''),
+       next_attempt_at, sent_at, created_at`
+
+// Claim counts an attempt for every entry it returns and moves it request.Lease ahead, so another worker
+// skips the entries until the lease runs out. An entry whose sender died is tried again after the lease.
+func (f *fiscalRepo) Claim(ctx context.Context, request models.ClaimFiscalEntries) ([]models.FiscalEntry, error) {
+	var (
+		args   = []interface{}{int64(request.Lease.Seconds()), request.Limit}
+		filter string
+	)
+
+	if request.ID != "" {
+		args = append(args, request.ID)
+		filter += fmt.Sprintf(` and id = $%d`, len(args))
+	}
+
+	if request.DocumentID != "" {
+		args = append(args, request.DocumentType, request.DocumentID)
+		filter += fmt.Sprintf(` and document_type = $%d and document_id = $%d`, len(args)-1, len(args))
+	}
+
+	rows, err := f.db.Query(ctx, `update fiscal_outbox set attempts = attempts + 1,
+                         next_attempt_at = now() + $1::int * interval '1 second', updated_at = now()
+			where id in (select id from fiscal_outbox where status = 'pending' and next_attempt_at <= now() `+filter+`
+				order by next_attempt_at limit $2 for update skip locked)
+					returning `+fiscalEntryColumns, args...)
+	if err != nil {
+		fmt.Println("error is while claiming fiscal outbox entries", err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries, err := scanFiscalEntries(rows)
+	if err != nil {
+		fmt.Println("error is while scanning claimed fiscal outbox entries", err.Error())
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// Done marks the entry sent and saves the sign and the number of the fiscal module on its sale or refund.
+func (f *fiscalRepo) Done(ctx context.Context, result models.FiscalResult) (err error) {
+	tx, err := f.db.Begin(ctx)
+	if err != nil {
+		fmt.Println("error is while beginning transaction", err.Error())
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+			return
+		}
+		err = tx.Commit(ctx)
+	}()
+
+	var documentType, documentID string
+	if err = tx.QueryRow(ctx, `update fiscal_outbox set status = 'sent', last_error = null, sent_at = now(), updated_at = now()
+                     where id = $1 returning document_type, document_id`, result.EntryID).Scan(
+		&documentType,
+		&documentID,
+	); err != nil {
+		fmt.Println("error is while marking fiscal outbox entry sent", err.Error())
+		return err
+	}
+
+	table := "sales"
+	if documentType == "refund" {
+		table = "refunds"
+	}
+
+	if _, err = tx.Exec(ctx, `update `+table+` set fiscal_sign = $1, fiscal_number = $2, fiscalized_at = now(), updated_at = now()
+                     where id = $3`, result.Sign, result.Number, documentID); err != nil {
+		fmt.Println("error is while saving fiscal sign of "+table, err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// Fail records the error of an attempt, the entry is failed for good when failure.RetryAt is nil.
+func (f *fiscalRepo) Fail(ctx context.Context, failure models.FiscalFailure) error {
+	query := `update fiscal_outbox set status = 'failed', last_error = $1, updated_at = now() where id = $2`
+	args := []interface{}{failure.Error, failure.EntryID}
+	if failure.RetryAt != nil {
+		query = `update fiscal_outbox set last_error = $1, next_attempt_at = $3, updated_at = now() where id = $2`
+		args = append(args, *failure.RetryAt)
+	}
+
+	tag, err := f.db.Exec(ctx, query, args...)
+	if err != nil {
+		fmt.Println("error is while recording fiscal outbox failure", err.Error())
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+
+	return nil
+}
+
+// Postpone moves the entry to failure.RetryAt, or makes it due now, and gives back the attempt Claim counted:
+// the entry could not be sent yet but nothing failed.
+func (f *fiscalRepo) Postpone(ctx context.Context, failure models.FiscalFailure) error {
+	tag, err := f.db.Exec(ctx, `update fiscal_outbox set attempts = greatest(attempts - 1, 0), last_error = $1,
+                         next_attempt_at = coalesce($3::timestamp, now()), updated_at = now() where id = $2`, failure.Error, failure.EntryID, failure.RetryAt)
+	if err != nil {
+		fmt.Println("error is while postponing fiscal outbox entry", err.Error())
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+
+	return nil
+}
+
+// Retry makes a failed or waiting entry due now with all its attempts again.
+func (f *fiscalRepo) Retry(ctx context.Context, id string) error {
+	var status string
+	if err := f.db.QueryRow(ctx, `select status from fiscal_outbox where id = $1`, id).Scan(&status); err != nil {
+		fmt.Println("error is while selecting fiscal outbox entry", err.Error())
+		return err
+	}
+
+	tag, err := f.db.Exec(ctx, `update fiscal_outbox set status = 'pending', attempts = 0, next_attempt_at = now(), updated_at = now()
+                     where id = $1 and status <> 'sent'`, id)
+	if err != nil {
+		fmt.Println("error is while retrying fiscal outbox entry", err.Error())
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return storage.ErrFiscalSent
+	}
+
+	return nil
+}
+
+// GetList returns the entries, latest first, with one status when request.Status is given.
+func (f *fiscalRepo) GetList(ctx context.Context, request models.FiscalEntryGetListRequest) (models.FiscalEntriesResponse, error) {
+	var (
+		count  int
+		args   = []interface{}{}
+		filter string
+	)
+
+	if request.Status != "" {
+		args = append(args, request.Status)
+		filter += fmt.Sprintf(` where status = $%d`, len(args))
+	}
+
+	if err := f.db.QueryRow(ctx, `select count(1) from fiscal_outbox`+filter, args...).Scan(&count); err != nil {
+		fmt.Println("error is while selecting count of fiscal outbox entries", err.Error())
+		return models.FiscalEntriesResponse{}, err
+	}
+
+	query := `select ` + fiscalEntryColumns + ` from fiscal_outbox` + filter +
+		fmt.Sprintf(` order by created_at desc LIMIT $%d OFFSET $%d`, len(args)+1, len(args)+2)
+
+	rows, err := f.db.Query(ctx, query, append(args, request.Limit, (request.Page-1)*request.Limit)...)
+	if err != nil {
+		fmt.Println("error is while selecting fiscal outbox entries", err.Error())
+		return models.FiscalEntriesResponse{}, err
+	}
+	defer rows.Close()
+
+	entries, err := scanFiscalEntries(rows)
+	if err != nil {
+		fmt.Println("error is while scanning fiscal outbox entries", err.Error())
+		return models.FiscalEntriesResponse{}, err
+	}
+
+	return models.FiscalEntriesResponse{
+		Entries: entries,
+		Count:   count,
+	}, nil
+}
+
+func scanFiscalEntries(rows pgx.Rows) ([]models.FiscalEntry, error) {
+	entries := []models.FiscalEntry{}
+	for rows.Next() {
+		entry := models.FiscalEntry{}
+		if err := rows.Scan(
+			&entry.ID,
+			&entry.DocumentType,
+			&entry.DocumentID,
+			&entry.Status,
+			&entry.Attempts,
+			&entry.LastError,
+			&entry.NextAttemptAt,
+			&entry.SentAt,
+			&entry.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
@@ -4,8 +4,10 @@ import (
 	"context"
 	"fmt"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"sell/api/models"
+	"sell/pkg/money"
 	"sell/storage"
 )
 
@@ -19,8 +21,8 @@ func NewIncomeRepo(db *pgxpool.Pool) storage.IIncomeStorage {
 
 func (i *incomeRepo) Create(ctx context.Context, income models.CreateIncome) (string, error) {
 	id := uuid.New()
-	query := `insert into incomes(id, branch_id, price) values($1, $2, 0)`
-	if _, err := i.db.Exec(ctx, query, id, income.BranchID); err != nil {
+	query := `insert into incomes(id, branch_id, supplier_id, currency, exchange_rate, price) values($1, $2, nullif($3, '')::uuid, $4, $5, 0)`
+	if _, err := i.db.Exec(ctx, query, id, income.BranchID, income.SupplierID, income.Currency, income.ExchangeRate); err != nil {
 		fmt.Println("error is while inserting income", err.Error())
 		return "", err
 	}
@@ -30,12 +32,19 @@ func (i *incomeRepo) Create(ctx context.Context, income models.CreateIncome) (st
 func (i *incomeRepo) GetByID(ctx context.Context, id string) (models.Income, error) {
 	income := models.Income{}
 
-	query := `select id, branch_id, price, created_at, updated_at from incomes where id = $1 and deleted_at is null`
+	query := `select id, branch_id, coalesce(supplier_id::text, ''), coalesce(purchase_order_id::text, ''), status, currency, exchange_rate, price, round(price * exchange_rate, 2), finished_at, created_at, updated_at from incomes where id = $1 and deleted_at is null`
 
 	if err := i.db.QueryRow(ctx, query, id).Scan(
 		&income.ID,
 		&income.BranchID,
+		&income.SupplierID,
+		&income.PurchaseOrderID,
+		&income.Status,
+		&income.Currency,
+		&income.ExchangeRate,
 		&income.Price,
+		&income.BasePrice,
+		&income.FinishedAt,
 		&income.CreatedAt,
 		&income.UpdatedAt,
 	); err != nil {
@@ -54,21 +63,34 @@ func (i *incomeRepo) GetList(ctx context.Context, request models.IncomeGetListRe
 		page              = request.Page
 		offset            = (page - 1) * request.Limit
 		incomes           []models.Income
+		args              []interface{}
 	)
 
 	if request.BranchID != "" {
-		filter += fmt.Sprintf(` and branch_id = '%s'`, request.BranchID)
+		args = append(args, request.BranchID)
+		filter += fmt.Sprintf(` and branch_id = $%d`, len(args))
 	}
+
+	if request.SupplierID != "" {
+		args = append(args, request.SupplierID)
+		filter += fmt.Sprintf(` and supplier_id = $%d`, len(args))
+	}
+
+	if request.Status != "" {
+		args = append(args, request.Status)
+		filter += fmt.Sprintf(` and status = $%d`, len(args))
+	}
+
 	countQuery = `select count(1) from incomes where deleted_at is null ` + filter
-	if err := i.db.QueryRow(ctx, countQuery).Scan(&count); err != nil {
+	if err := i.db.QueryRow(ctx, countQuery, args...).Scan(&count); err != nil {
 		fmt.Println("error is while selecting count ", err.Error())
 		return models.IncomeResponse{}, err
 	}
 
-	pagination = ` ORDER BY created_at desc LIMIT $1 OFFSET $2 `
-	query = `select id, branch_id, price, created_at, updated_at from incomes where deleted_at is null ` + filter + pagination
+	pagination = fmt.Sprintf(` ORDER BY created_at desc LIMIT $%d OFFSET $%d `, len(args)+1, len(args)+2)
+	query = `select id, branch_id, coalesce(supplier_id::text, ''), coalesce(purchase_order_id::text, ''), status, currency, exchange_rate, price, round(price * exchange_rate, 2), finished_at, created_at, updated_at from incomes where deleted_at is null ` + filter + pagination
 
-	rows, err := i.db.Query(ctx, query, request.Limit, offset)
+	rows, err := i.db.Query(ctx, query, append(args, request.Limit, offset)...)
 	fmt.Println("limit", request.Limit)
 	fmt.Println("rows", rows)
 	if err != nil {
@@ -83,7 +105,14 @@ func (i *incomeRepo) GetList(ctx context.Context, request models.IncomeGetListRe
 		if err := rows.Scan(
 			&income.ID,
 			&income.BranchID,
+			&income.SupplierID,
+			&income.PurchaseOrderID,
+			&income.Status,
+			&income.Currency,
+			&income.ExchangeRate,
 			&income.Price,
+			&income.BasePrice,
+			&income.FinishedAt,
 			&income.CreatedAt,
 			&income.UpdatedAt,
 		); err != nil {
@@ -102,26 +131,146 @@ func (i *incomeRepo) GetList(ctx context.Context, request models.IncomeGetListRe
 }
 
 func (i *incomeRepo) Update(ctx context.Context, income models.UpdateIncome) (string, error) {
-	query := `update incomes set branch_id = $1, price = $2, updated_at = now() where id = $3`
-	rowsAffected, err := i.db.Exec(ctx, query, &income.BranchID, &income.Price, &income.ID)
+	query := `update incomes set branch_id = $1, supplier_id = nullif($2, '')::uuid, currency = $3, exchange_rate = $4, updated_at = now()
+                 where id = $5 and status = 'in_process' and deleted_at is null`
+	rowsAffected, err := i.db.Exec(ctx, query, &income.BranchID, &income.SupplierID, &income.Currency, &income.ExchangeRate, &income.ID)
 	if err != nil {
 		fmt.Println("error is while updating incomes", err.Error())
 		return "", err
 	}
 
 	if r := rowsAffected.RowsAffected(); r == 0 {
-		fmt.Println("error is while rowsAffected", err.Error())
-		return "", err
+		return "", storage.ErrIncomeFinished
 	}
 
-	return income.ID, err
+	return income.ID, nil
 }
 
 func (i *incomeRepo) Delete(ctx context.Context, id string) error {
-	query := `update incomes set deleted_at = now() where id = $1`
-	if _, err := i.db.Exec(ctx, query, id); err != nil {
+	query := `update incomes set deleted_at = now() where id = $1 and status = 'in_process'`
+	rowsAffected, err := i.db.Exec(ctx, query, id)
+	if err != nil {
 		fmt.Println("error is while deleting income", err.Error())
 		return err
 	}
+
+	if rowsAffected.RowsAffected() == 0 {
+		return storage.ErrIncomeFinished
+	}
+
+	return nil
+}
+
+// Finish adds every line of the income to the income branch repository and locks the income.
+// Finishing an already finished income changes nothing, so every line is added exactly once.
+func (i *incomeRepo) Finish(ctx context.Context, id string) (err error) {
+	tx, err := i.db.Begin(ctx)
+	if err != nil {
+		fmt.Println("error is while beginning transaction", err.Error())
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+			return
+		}
+		err = tx.Commit(ctx)
+	}()
+
+	var (
+		branchID, status string
+		rate             money.Rate
+	)
+	if err = tx.QueryRow(ctx, `select branch_id, status, exchange_rate from incomes where id = $1 and deleted_at is null for update`, id).Scan(
+		&branchID,
+		&status,
+		&rate,
+	); err != nil {
+		fmt.Println("error is while selecting income for update", err.Error())
+		return err
+	}
+
+	if status == "finished" {
+		return nil
+	}
+
+	rows, err := tx.Query(ctx, `select id, income_id, product_id, coalesce(product_unit_id::text, ''), unit_quantity, price, count,
+       					coalesce(batch_number, ''), coalesce(expiry_date::text, '')
+						from income_products where income_id = $1 and deleted_at is null order by created_at`, id)
+	if err != nil {
+		fmt.Println("error is while selecting income products", err.Error())
+		return err
+	}
+
+	incomeProducts := []models.IncomeProduct{}
+	for rows.Next() {
+		incomeProduct := models.IncomeProduct{}
+		if err = rows.Scan(
+			&incomeProduct.ID,
+			&incomeProduct.IncomeID,
+			&incomeProduct.ProductID,
+			&incomeProduct.ProductUnitID,
+			&incomeProduct.UnitQuantity,
+			&incomeProduct.Price,
+			&incomeProduct.Count,
+			&incomeProduct.BatchNumber,
+			&incomeProduct.ExpiryDate,
+		); err != nil {
+			rows.Close()
+			fmt.Println("error is while scanning income products", err.Error())
+			return err
+		}
+		incomeProducts = append(incomeProducts, incomeProduct)
+	}
+	rows.Close()
+
+	if len(incomeProducts) == 0 {
+		err = storage.ErrEmptyIncome
+		return err
+	}
+
+	for _, incomeProduct := range incomeProducts {
+		if err = addIncomeStock(ctx, tx, branchID, rate, incomeProduct); err != nil {
+			return err
+		}
+	}
+
+	if err = updateIncomePrice(ctx, tx, id); err != nil {
+		return err
+	}
+
+	if _, err = tx.Exec(ctx, `update incomes set status = 'finished', finished_at = now(), updated_at = now() where id = $1`, id); err != nil {
+		fmt.Println("error is while finishing income", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// lockOpenIncome locks the income row until the end of the transaction
+// and fails with storage.ErrIncomeFinished when the income can not be changed anymore.
+func lockOpenIncome(ctx context.Context, tx pgx.Tx, id string) error {
+	var status string
+	if err := tx.QueryRow(ctx, `select status from incomes where id = $1 and deleted_at is null for update`, id).Scan(&status); err != nil {
+		fmt.Println("error is while selecting income for update", err.Error())
+		return err
+	}
+
+	if status != "in_process" {
+		return storage.ErrIncomeFinished
+	}
+
+	return nil
+}
+
+// updateIncomePrice sets the income price to the sum of price x count of its lines, every line rounded to the hundredth.
+func updateIncomePrice(ctx context.Context, tx pgx.Tx, id string) error {
+	if _, err := tx.Exec(ctx, `update incomes set price = (
+			select coalesce(sum(round(price * count, 2)), 0) from income_products where income_id = $1 and deleted_at is null
+		), updated_at = now() where id = $1`, id); err != nil {
+		fmt.Println("error is while updating income price", err.Error())
+		return err
+	}
+
 	return nil
 }
@@ -4,8 +4,10 @@ import (
 	"context"
 	"fmt"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"sell/api/models"
+	"sell/pkg/money"
 	"sell/storage"
 )
 
@@ -17,33 +19,72 @@ func NewIncomeProductsRepo(db *pgxpool.Pool) storage.IIncomeProductsStorage {
 	return incomeProductRepo{db: db}
 }
 
-func (i incomeProductRepo) Create(ctx context.Context, incomeProduct models.CreateIncomeProduct) (string, error) {
+// Create adds a line to an income which is not finished yet and recalculates the income price.
+// Stock is not changed until the income is finished.
+func (i incomeProductRepo) Create(ctx context.Context, incomeProduct models.CreateIncomeProduct) (_ string, err error) {
 	id := uuid.New()
-	query := `insert into income_products(id, income_id, product_id, price, count) values($1, $2, $3, $4, $5)`
-	if _, err := i.db.Exec(ctx, query,
+
+	tx, err := i.db.Begin(ctx)
+	if err != nil {
+		fmt.Println("error is while beginning transaction", err.Error())
+		return "", err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+			return
+		}
+		err = tx.Commit(ctx)
+	}()
+
+	if err = lockOpenIncome(ctx, tx, incomeProduct.IncomeID); err != nil {
+		return "", err
+	}
+
+	unitQuantity, err := productUnitQuantity(ctx, tx, incomeProduct.ProductID, incomeProduct.ProductUnitID)
+	if err != nil {
+		return "", err
+	}
+
+	query := `insert into income_products(id, income_id, product_id, product_unit_id, unit_quantity, price, count, batch_number, expiry_date) 
+					values($1, $2, $3, nullif($4, '')::uuid, $5, $6, $7, nullif($8, ''), nullif($9, '')::date)`
+	if _, err = tx.Exec(ctx, query,
 		id,
 		incomeProduct.IncomeID,
 		incomeProduct.ProductID,
+		incomeProduct.ProductUnitID,
+		unitQuantity,
 		incomeProduct.Price,
 		incomeProduct.Count,
+		incomeProduct.BatchNumber,
+		incomeProduct.ExpiryDate,
 	); err != nil {
 		fmt.Println("error is while inserting income products", err.Error())
 		return "", err
 	}
+
+	if err = updateIncomePrice(ctx, tx, incomeProduct.IncomeID); err != nil {
+		return "", err
+	}
+
 	return id.String(), nil
 }
 
 func (i incomeProductRepo) GetByID(ctx context.Context, id string) (models.IncomeProduct, error) {
 	incomeProduct := models.IncomeProduct{}
 
-	query := `select id, income_id, product_id, price, count, created_at::text, updated_at::text from income_products where id = $1 and deleted_at is null`
+	query := `select id, income_id, product_id, coalesce(product_unit_id::text, ''), unit_quantity, price, count, coalesce(batch_number, ''), coalesce(expiry_date::text, ''), created_at::text, updated_at::text from income_products where id = $1 and deleted_at is null`
 
 	if err := i.db.QueryRow(ctx, query, id).Scan(
 		&incomeProduct.ID,
 		&incomeProduct.IncomeID,
 		&incomeProduct.ProductID,
+		&incomeProduct.ProductUnitID,
+		&incomeProduct.UnitQuantity,
 		&incomeProduct.Price,
 		&incomeProduct.Count,
+		&incomeProduct.BatchNumber,
+		&incomeProduct.ExpiryDate,
 		&incomeProduct.CreatedAt,
 		&incomeProduct.UpdatedAt,
 	); err != nil {
@@ -79,7 +120,7 @@ func (i incomeProductRepo) GetList(ctx context.Context, request models.IncomePro
 	}
 
 	pagination = ` ORDER BY created_at desc LIMIT $1 OFFSET $2 `
-	query = `select id, income_id, product_id, price, count, created_at::text, updated_at::text from income_products where deleted_at is null ` + filter + pagination
+	query = `select id, income_id, product_id, coalesce(product_unit_id::text, ''), unit_quantity, price, count, coalesce(batch_number, ''), coalesce(expiry_date::text, ''), created_at::text, updated_at::text from income_products where deleted_at is null ` + filter + pagination
 
 	rows, err := i.db.Query(ctx, query, request.Limit, offset)
 	fmt.Println("limit", request.Limit)
@@ -95,8 +136,12 @@ func (i incomeProductRepo) GetList(ctx context.Context, request models.IncomePro
 			&incomeProduct.ID,
 			&incomeProduct.IncomeID,
 			&incomeProduct.ProductID,
+			&incomeProduct.ProductUnitID,
+			&incomeProduct.UnitQuantity,
 			&incomeProduct.Price,
 			&incomeProduct.Count,
+			&incomeProduct.BatchNumber,
+			&incomeProduct.ExpiryDate,
 			&incomeProduct.CreatedAt,
 			&incomeProduct.UpdatedAt,
 		); err != nil {
@@ -113,27 +158,163 @@ func (i incomeProductRepo) GetList(ctx context.Context, request models.IncomePro
 
 }
 
-func (i incomeProductRepo) Update(ctx context.Context, income models.UpdateIncomeProduct) (string, error) {
-	query := `update income_products set income_id = $1, product_id = $2, price = $3, count = $4 , updated_at = now() where id = $5`
-	rowsAffected, err := i.db.Exec(ctx, query, &income.IncomeID, &income.ProductID, &income.Price, &income.Count, &income.ID)
+func (i incomeProductRepo) Update(ctx context.Context, income models.UpdateIncomeProduct) (_ string, err error) {
+	tx, err := i.db.Begin(ctx)
 	if err != nil {
+		fmt.Println("error is while beginning transaction", err.Error())
+		return "", err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+			return
+		}
+		err = tx.Commit(ctx)
+	}()
+
+	var oldIncomeID string
+	if err = tx.QueryRow(ctx, `select income_id from income_products where id = $1 and deleted_at is null`, income.ID).Scan(
+		&oldIncomeID,
+	); err != nil {
+		fmt.Println("error is while selecting income product", err.Error())
+		return "", err
+	}
+
+	if err = lockOpenIncome(ctx, tx, oldIncomeID); err != nil {
+		return "", err
+	}
+
+	if income.IncomeID != oldIncomeID {
+		if err = lockOpenIncome(ctx, tx, income.IncomeID); err != nil {
+			return "", err
+		}
+	}
+
+	unitQuantity, err := productUnitQuantity(ctx, tx, income.ProductID, income.ProductUnitID)
+	if err != nil {
+		return "", err
+	}
+
+	query := `update income_products set income_id = $1, product_id = $2, product_unit_id = nullif($3, '')::uuid, unit_quantity = $4,
+                           price = $5, count = $6, batch_number = nullif($7, ''), expiry_date = nullif($8, '')::date, updated_at = now() where id = $9`
+	if _, err = tx.Exec(ctx, query, &income.IncomeID, &income.ProductID, &income.ProductUnitID, unitQuantity, &income.Price, &income.Count,
+		&income.BatchNumber, &income.ExpiryDate, &income.ID); err != nil {
 		fmt.Println("error is while updating income products", err.Error())
 		return "", err
 	}
 
-	if r := rowsAffected.RowsAffected(); r == 0 {
-		fmt.Println("error is while rowsAffected", err.Error())
+	if err = updateIncomePrice(ctx, tx, oldIncomeID); err != nil {
 		return "", err
 	}
 
-	return income.ID, err
+	if income.IncomeID != oldIncomeID {
+		if err = updateIncomePrice(ctx, tx, income.IncomeID); err != nil {
+			return "", err
+		}
+	}
+
+	return income.ID, nil
 }
 
-func (i incomeProductRepo) Delete(ctx context.Context, id string) error {
-	query := `update income_products set deleted_at = now() where id = $1`
-	if _, err := i.db.Exec(ctx, query, id); err != nil {
+func (i incomeProductRepo) Delete(ctx context.Context, id string) (err error) {
+	tx, err := i.db.Begin(ctx)
+	if err != nil {
+		fmt.Println("error is while beginning transaction", err.Error())
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+			return
+		}
+		err = tx.Commit(ctx)
+	}()
+
+	var incomeID string
+	if err = tx.QueryRow(ctx, `select income_id from income_products where id = $1 and deleted_at is null`, id).Scan(
+		&incomeID,
+	); err != nil {
+		fmt.Println("error is while selecting income product", err.Error())
+		return err
+	}
+
+	if err = lockOpenIncome(ctx, tx, incomeID); err != nil {
+		return err
+	}
+
+	if _, err = tx.Exec(ctx, `update income_products set deleted_at = now() where id = $1`, id); err != nil {
 		fmt.Println("error is while deleting income", err.Error())
 		return err
 	}
+
+	return updateIncomePrice(ctx, tx, incomeID)
+}
+
+// addIncomeStock puts a received income line into the branch repository, creating the repository
+// row when the branch has none for the product, and records the plus movement and the batch.
+// Lines received in packs are converted to units of the product, the cost is converted to the base
+// currency at the rate of the income so the repository is valued in one currency.
+func addIncomeStock(ctx context.Context, tx pgx.Tx, branchID string, rate money.Rate, incomeProduct models.IncomeProduct) error {
+	quantity := incomeProduct.Count
+	if incomeProduct.UnitQuantity > 0 {
+		quantity = roundQuantity(incomeProduct.Count * incomeProduct.UnitQuantity)
+	}
+
+	if err := addRepositoryCount(ctx, tx, branchID, incomeProduct.ProductID, quantity); err != nil {
+		return err
+	}
+
+	price, err := incomeProduct.Price.Mul(incomeProduct.Count)
+	if err != nil {
+		return err
+	}
+	if price, err = price.Convert(rate); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(ctx, `insert into repository_transactions
+    				(id, branch_id, product_id, repository_transaction_type, source_type, source_id, price, quantity)
+						values($1, $2, $3, 'plus', 'income', $4, $5, $6)`,
+		uuid.New(),
+		branchID,
+		incomeProduct.ProductID,
+		incomeProduct.IncomeID,
+		price,
+		quantity,
+	); err != nil {
+		fmt.Println("error is while inserting repository transaction", err.Error())
+		return err
+	}
+
+	if _, err := tx.Exec(ctx, `insert into repository_batches (id, branch_id, product_id, income_product_id, batch_number, expiry_date, count)
+					values($1, $2, $3, $4, nullif($5, ''), nullif($6, '')::date, $7)`,
+		uuid.New(),
+		branchID,
+		incomeProduct.ProductID,
+		incomeProduct.ID,
+		incomeProduct.BatchNumber,
+		incomeProduct.ExpiryDate,
+		quantity,
+	); err != nil {
+		fmt.Println("error is while inserting batch", err.Error())
+		return err
+	}
+
 	return nil
 }
+
+// productUnitQuantity returns how many units of the product the pack holds, 1 when no pack is given.
+func productUnitQuantity(ctx context.Context, tx pgx.Tx, productID, productUnitID string) (float64, error) {
+	if productUnitID == "" {
+		return 1, nil
+	}
+
+	var quantity float64
+	if err := tx.QueryRow(ctx, `select quantity from product_units where id = $1 and product_id = $2 and deleted_at is null`,
+		productUnitID, productID).Scan(&quantity); err != nil {
+		fmt.Println("error is while selecting product unit", err.Error())
+		return 0, err
+	}
+
+	return quantity, nil
+}
@@ -124,3 +124,43 @@ func (s *Store) Income() storage.IIncomeStorage {
 func (s *Store) IncomeProducts() storage.IIncomeProductsStorage {
 	return NewIncomeProductsRepo(s.Pool)
 }
+
+func (s *Store) WriteOff() storage.IWriteOffStorage {
+	return NewWriteOffRepo(s.Pool)
+}
+
+func (s *Store) Batch() storage.IBatchStorage {
+	return NewBatchRepo(s.Pool)
+}
+
+func (s *Store) Supplier() storage.ISupplierStorage {
+	return NewSupplierRepo(s.Pool)
+}
+
+func (s *Store) PurchaseOrder() storage.IPurchaseOrderStorage {
+	return NewPurchaseOrderRepo(s.Pool)
+}
+
+func (s *Store) BarcodeFormat() storage.IBarcodeFormatStorage {
+	return NewBarcodeFormatRepo(s.Pool)
+}
+
+func (s *Store) ProductPrice() storage.IProductPriceStorage {
+	return NewProductPriceRepo(s.Pool)
+}
+
+func (s *Store) ExchangeRate() storage.IExchangeRateStorage {
+	return NewExchangeRateRepo(s.Pool)
+}
+
+func (s *Store) TaxRate() storage.ITaxRateStorage {
+	return NewTaxRateRepo(s.Pool)
+}
+
+func (s *Store) Refund() storage.IRefundStorage {
+	return NewRefundRepo(s.Pool)
+}
+
+func (s *Store) Fiscal() storage.IFiscalStorage {
+	return NewFiscalRepo(s.Pool)
+}
@@ -0,0 +1,35 @@
+package postgres_test
+
+import (
+	"context"
+	"os"
+	"sell/config"
+	"sell/storage/postgres"
+	"sell/storage/storagetest"
+	"testing"
+)
+
+// TestStorage runs the storage cases against the database POSTGRES_TEST_DB names on the server of the usual
+// POSTGRES_* settings. The database is migrated first, the cases leave their rows in it.
+func TestStorage(t *testing.T) {
+	database := os.Getenv("POSTGRES_TEST_DB")
+	if database == "" {
+		t.Skip("POSTGRES_TEST_DB is not set")
+	}
+
+	// migrations are read relative to the module root
+	if err := os.Chdir("../.."); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := config.Load()
+	cfg.PostgresDB = database
+
+	store, err := postgres.New(context.Background(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(store.Close)
+
+	storagetest.Run(t, store)
+}
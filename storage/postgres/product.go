@@ -2,12 +2,16 @@ package postgres
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"sell/api/models"
+	"sell/pkg/money"
 	"sell/storage"
-	"strconv"
+	"sort"
 )
 
 type productRepo struct {
@@ -18,35 +22,128 @@ func NewProductRepo(db *pgxpool.Pool) storage.IProducts {
 	return productRepo{db: db}
 }
 
-func (p productRepo) Create(ctx context.Context, product models.CreateProduct) (string, error) {
+// Create inserts the product with its main barcode and the additional pack barcodes.
+func (p productRepo) Create(ctx context.Context, product models.CreateProduct) (_ string, err error) {
 	id := uuid.New()
-	query := `insert into products (id, name, price, barcode, category_id) values($1, $2, $3, $4, $5)`
-	if _, err := p.db.Exec(ctx, query,
-		id, product.Name, product.Price, product.Barcode, product.CategoryID); err != nil {
+
+	tx, err := p.db.Begin(ctx)
+	if err != nil {
+		fmt.Println("error is while beginning transaction", err.Error())
+		return "", err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+			return
+		}
+		err = tx.Commit(ctx)
+	}()
+
+	query := `insert into products (id, name, price, barcode, unit, unit_precision, category_id, name_translations) 
+					values($1, $2, $3, nullif($4, ''), $5, $6, $7, coalesce($8, '{}'))`
+	if _, err = tx.Exec(ctx, query,
+		id, product.Name, product.Price, product.Barcode, product.Unit, product.Precision, product.CategoryID, product.NameTranslations); err != nil {
 		fmt.Println("error is while inserting data", err.Error())
+		err = barcodeError(err)
+		return "", err
+	}
+
+	if err = insertProductPrice(ctx, tx, id.String(), product.Price); err != nil {
 		return "", err
 	}
+
+	barcodes := product.Barcodes
+	if product.Barcode != "" {
+		barcodes = append([]models.CreateProductBarcode{{Barcode: product.Barcode, Quantity: 1}}, barcodes...)
+	}
+
+	for _, barcode := range barcodes {
+		barcode.ProductID = id.String()
+		if err = insertProductBarcode(ctx, tx, barcode); err != nil {
+			return "", err
+		}
+	}
+
 	return id.String(), nil
 }
 
 func (p productRepo) GetByID(ctx context.Context, id string) (models.Product, error) {
-	product := models.Product{}
-	query := `select id, name, price, barcode, category_id, created_at, updated_at 
-							from products where id = $1 and deleted_at is null`
-	if err := p.db.QueryRow(ctx, query, id).Scan(
-		&product.ID,
-		&product.Name,
-		&product.Price,
-		&product.Barcode,
-		&product.CategoryID,
-		&product.CreatedAt,
-		&product.UpdatedAt); err != nil {
+	query := `select ` + productColumns + ` from ` + productsJoin + ` where p.id = $1 and p.deleted_at is null`
+	product, err := scanProduct(p.db.QueryRow(ctx, query, id))
+	if err != nil {
 		fmt.Println("error is while scanning", err.Error())
 		return models.Product{}, err
 	}
+
+	rows, err := p.db.Query(ctx, `select id, product_id, barcode, quantity, created_at from product_barcodes 
+							where product_id = $1 and deleted_at is null order by quantity, created_at`, id)
+	if err != nil {
+		fmt.Println("error is while selecting product barcodes", err.Error())
+		return models.Product{}, err
+	}
+	defer rows.Close()
+
+	product.Barcodes = []models.ProductBarcode{}
+	for rows.Next() {
+		barcode := models.ProductBarcode{}
+		if err := rows.Scan(
+			&barcode.ID,
+			&barcode.ProductID,
+			&barcode.Barcode,
+			&barcode.Quantity,
+			&barcode.CreatedAt,
+		); err != nil {
+			fmt.Println("error is while scanning product barcodes", err.Error())
+			return models.Product{}, err
+		}
+		product.Barcodes = append(product.Barcodes, barcode)
+	}
+	rows.Close()
+
+	unitRows, err := p.db.Query(ctx, `select id, product_id, name, quantity, created_at from product_units 
+							where product_id = $1 and deleted_at is null order by quantity, created_at`, id)
+	if err != nil {
+		fmt.Println("error is while selecting product units", err.Error())
+		return models.Product{}, err
+	}
+	defer unitRows.Close()
+
+	product.Units = []models.ProductUnit{}
+	for unitRows.Next() {
+		unit := models.ProductUnit{}
+		if err := unitRows.Scan(
+			&unit.ID,
+			&unit.ProductID,
+			&unit.Name,
+			&unit.Quantity,
+			&unit.CreatedAt,
+		); err != nil {
+			fmt.Println("error is while scanning product units", err.Error())
+			return models.Product{}, err
+		}
+		product.Units = append(product.Units, unit)
+	}
+	unitRows.Close()
+
+	if product.Images, err = p.GetImages(ctx, id); err != nil {
+		return models.Product{}, err
+	}
+
+	if product.ParentID != "" {
+		return product, nil
+	}
+
+	variants, err := p.getVariants(ctx, []string{product.ID})
+	if err != nil {
+		return models.Product{}, err
+	}
+	product.Variants = variants[product.ID]
+
 	return product, nil
 }
 
+// GetList returns products and variants as separate rows. With request.Grouped only parent products
+// are listed, each with its variants, and a parent is found when the name or the barcode of any of its variants matches.
 func (p productRepo) GetList(ctx context.Context, request models.ProductGetListRequest) (models.ProductResponse, error) {
 	var (
 		page              = request.Page
@@ -54,57 +151,77 @@ func (p productRepo) GetList(ctx context.Context, request models.ProductGetListR
 		query, countQuery string
 		count             = 0
 		products          = []models.Product{}
-		name              = request.Name
-		barcode           = request.Barcode
+		filter            string
+		args              []interface{}
 	)
-	countQuery = `select count(1) from products where deleted_at is null `
 
-	if name != "" && barcode != 0 {
-		countQuery += fmt.Sprintf(` and name ilike '%s' && barcode = %s`, name, strconv.Itoa(barcode))
-	} else if name != "" {
-		countQuery += fmt.Sprintf(` and name ilike '%s' `, name)
-	} else if barcode != 0 {
-		countQuery += ` and barcode = ` + strconv.Itoa(barcode)
+	if request.Name != "" {
+		args = append(args, request.Name)
+		if request.Grouped {
+			filter += fmt.Sprintf(` and (p.name ilike $%d or exists (select 1 from products v 
+				where v.parent_id = p.id and v.deleted_at is null and v.name ilike $%d))`, len(args), len(args))
+		} else {
+			filter += fmt.Sprintf(` and p.name ilike $%d`, len(args))
+		}
 	}
 
-	if err := p.db.QueryRow(ctx, countQuery).Scan(&count); err != nil {
-		fmt.Println("error is while scanning count ....", err.Error())
-		return models.ProductResponse{}, err
+	if request.Barcode != "" {
+		args = append(args, request.Barcode)
+		if request.Grouped {
+			filter += fmt.Sprintf(` and p.id in (select coalesce(v.parent_id, v.id) from product_barcodes b
+				join products v on v.id = b.product_id where b.barcode = $%d and b.deleted_at is null)`, len(args))
+		} else {
+			filter += fmt.Sprintf(` and p.id in (select product_id from product_barcodes where barcode = $%d and deleted_at is null)`, len(args))
+		}
 	}
 
-	query = `select  id, name, price, barcode, category_id, created_at, updated_at 
-							from products where deleted_at is null `
+	if request.CategoryID != "" {
+		args = append(args, request.CategoryID)
+		filter += ` and p.category_id in (` + categorySubtree(fmt.Sprintf("$%d", len(args))) + `)`
+	}
 
-	if name != "" && barcode != 0 {
-		query += fmt.Sprintf(` and name ilike '%s' && barcode = %s`, name, strconv.Itoa(barcode))
-	} else if name != "" {
-		query += fmt.Sprintf(` and name ilike '%s' `, name)
-	} else if barcode != 0 {
-		query += ` and barcode = ` + strconv.Itoa(barcode)
+	if request.Grouped {
+		filter += ` and p.parent_id is null`
 	}
 
-	query += ` order by created_at desc LIMIT $1 OFFSET $2 `
-	rows, err := p.db.Query(ctx, query, request.Limit, offset)
+	countQuery = `select count(1) from products p where p.deleted_at is null ` + filter
+	if err := p.db.QueryRow(ctx, countQuery, args...).Scan(&count); err != nil {
+		fmt.Println("error is while scanning count ....", err.Error())
+		return models.ProductResponse{}, err
+	}
+
+	query = `select ` + productColumns + ` from ` + productsJoin + ` where p.deleted_at is null ` + filter +
+		fmt.Sprintf(` order by p.created_at desc LIMIT $%d OFFSET $%d `, len(args)+1, len(args)+2)
+
+	rows, err := p.db.Query(ctx, query, append(args, request.Limit, offset)...)
 	if err != nil {
 		fmt.Println("error is while selecting all", err.Error())
 		return models.ProductResponse{}, err
 	}
+	defer rows.Close()
 
+	ids := []string{}
 	for rows.Next() {
-		product := models.Product{}
-		if err = rows.Scan(
-			&product.ID,
-			&product.Name,
-			&product.Price,
-			&product.Barcode,
-			&product.CategoryID,
-			&product.CreatedAt,
-			&product.UpdatedAt); err != nil {
+		product, err := scanProduct(rows)
+		if err != nil {
 			fmt.Println("error is while scanning category", err.Error())
 			return models.ProductResponse{}, err
 		}
 		products = append(products, product)
+		ids = append(ids, product.ID)
 	}
+	rows.Close()
+
+	if request.Grouped && len(ids) > 0 {
+		variants, err := p.getVariants(ctx, ids)
+		if err != nil {
+			return models.ProductResponse{}, err
+		}
+		for i := range products {
+			products[i].Variants = variants[products[i].ID]
+		}
+	}
+
 	return models.ProductResponse{
 		Products: products,
 		Count:    count,
@@ -112,25 +229,412 @@ func (p productRepo) GetList(ctx context.Context, request models.ProductGetListR
 
 }
 
-func (p productRepo) Update(ctx context.Context, product models.UpdateProduct) (string, error) {
-	query := `update products set name = $1, price = $2, category_id = $3, updated_at = now() 
-									where id = $4`
-	if _, err := p.db.Exec(ctx, query,
+// Update changes a product. A variant sent with price 0 takes the price of its parent again.
+// A changed price starts now for all branches and is kept in the price history.
+func (p productRepo) Update(ctx context.Context, product models.UpdateProduct) (_ string, err error) {
+	tx, err := p.db.Begin(ctx)
+	if err != nil {
+		fmt.Println("error is while beginning transaction", err.Error())
+		return "", err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+			return
+		}
+		err = tx.Commit(ctx)
+	}()
+
+	var (
+		oldPrice, newPrice *money.Money
+		oldUnit            string
+		oldPrecision       int
+	)
+	if err = tx.QueryRow(ctx, `select price, unit, unit_precision from products where id = $1 for update`, product.ID).Scan(
+		&oldPrice,
+		&oldUnit,
+		&oldPrecision,
+	); err != nil {
+		fmt.Println("error is while selecting product price", err.Error())
+		return "", err
+	}
+
+	unit, precision := oldUnit, oldPrecision
+	if product.Unit != "" {
+		unit = product.Unit
+	}
+	if product.Precision != nil {
+		precision = *product.Precision
+	}
+
+	if unit != oldUnit || precision < oldPrecision {
+		// stock of every branch must stay whole for a new unit and fit a smaller precision
+		var fractional bool
+		if err = tx.QueryRow(ctx, `select exists(select 1 from repositories where product_id = $1 and deleted_at is null
+							and (count <> round(count, $2) or ($3 and count <> round(count))))`,
+			product.ID, precision, unit != oldUnit).Scan(&fractional); err != nil {
+			fmt.Println("error is while checking product stock", err.Error())
+			return "", err
+		}
+		if fractional {
+			err = storage.ErrFractionalStock
+			return "", err
+		}
+	}
+
+	query := `update products set name = $1, price = case when parent_id is not null and $2::numeric = 0 then null else $2::numeric end, 
+                    unit = $3, unit_precision = $4, category_id = $5, attributes = coalesce($6, attributes), 
+                    name_translations = coalesce($8, name_translations), updated_at = now() 
+									where id = $7 returning price`
+	if err = tx.QueryRow(ctx, query,
 		&product.Name,
 		&product.Price,
+		unit,
+		precision,
 		&product.CategoryID,
-		&product.ID); err != nil {
+		product.Attributes,
+		&product.ID,
+		product.NameTranslations).Scan(&newPrice); err != nil {
 		fmt.Println("error is while updating", err.Error())
 		return "", err
 	}
+
+	if (oldPrice == nil) != (newPrice == nil) || (oldPrice != nil && *oldPrice != *newPrice) {
+		if err = insertProductPrice(ctx, tx, product.ID, newPrice); err != nil {
+			return "", err
+		}
+	}
+
 	return product.ID, nil
 }
 
+// Delete removes a product together with its variants.
 func (p productRepo) Delete(ctx context.Context, id string) error {
-	query := `update products set deleted_at = now() where id = $1`
+	// barcodes go with the products, so they can be given to other products
+	query := `with deleted as (
+			update products set deleted_at = now() where (id = $1 or parent_id = $1) and deleted_at is null returning id
+		)
+		update product_barcodes set deleted_at = now() where product_id in (select id from deleted) and deleted_at is null`
 	if _, err := p.db.Exec(ctx, query, &id); err != nil {
 		fmt.Println("error is while deleting", err.Error())
 		return err
 	}
 	return nil
 }
+
+// CreateVariant adds a variant to a parent product. The variant gets the category and the unit of the parent,
+// its name and the names in other languages are made of the parent names and the attribute values when the name is not given.
+func (p productRepo) CreateVariant(ctx context.Context, variant models.CreateProductVariant) (_ string, err error) {
+	id := uuid.New()
+
+	tx, err := p.db.Begin(ctx)
+	if err != nil {
+		fmt.Println("error is while beginning transaction", err.Error())
+		return "", err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+			return
+		}
+		err = tx.Commit(ctx)
+	}()
+
+	var (
+		parentName, parentID string
+		parentTranslations   map[string]string
+	)
+	if err = tx.QueryRow(ctx, `select name, name_translations, coalesce(parent_id::text, '') from products 
+                                 where id = $1 and deleted_at is null for share`, variant.ParentID).Scan(
+		&parentName,
+		&parentTranslations,
+		&parentID,
+	); err != nil {
+		fmt.Println("error is while selecting parent product", err.Error())
+		return "", err
+	}
+
+	if parentID != "" {
+		err = storage.ErrVariantParent
+		return "", err
+	}
+
+	if variant.Name == "" {
+		variant.Name = variantName(parentName, variant.Attributes)
+		if variant.NameTranslations == nil {
+			variant.NameTranslations = map[string]string{}
+			for language, name := range parentTranslations {
+				variant.NameTranslations[language] = variantName(name, variant.Attributes)
+			}
+		}
+	}
+
+	if _, err = tx.Exec(ctx, `insert into products 
+    			(id, name, price, barcode, unit, unit_precision, category_id, parent_id, attributes, name_translations)
+				select $1, $2, $3, nullif($4, ''), unit, unit_precision, category_id, id, $5, coalesce($7, '{}') 
+					from products where id = $6`,
+		id,
+		variant.Name,
+		variant.PriceOverride,
+		variant.Barcode,
+		variant.Attributes,
+		variant.ParentID,
+		variant.NameTranslations,
+	); err != nil {
+		fmt.Println("error is while inserting product variant", err.Error())
+		err = barcodeError(err)
+		return "", err
+	}
+
+	if variant.PriceOverride != nil {
+		if err = insertProductPrice(ctx, tx, id.String(), *variant.PriceOverride); err != nil {
+			return "", err
+		}
+	}
+
+	if variant.Barcode != "" {
+		if err = insertProductBarcode(ctx, tx, models.CreateProductBarcode{
+			ProductID: id.String(),
+			Barcode:   variant.Barcode,
+			Quantity:  1,
+		}); err != nil {
+			return "", err
+		}
+	}
+
+	return id.String(), nil
+}
+
+// getVariants returns the variants of the given parent products by parent id.
+func (p productRepo) getVariants(ctx context.Context, parentIDs []string) (map[string][]models.Product, error) {
+	rows, err := p.db.Query(ctx, `select `+productColumns+` from `+productsJoin+` 
+							where p.parent_id = any($1::uuid[]) and p.deleted_at is null order by p.created_at`, parentIDs)
+	if err != nil {
+		fmt.Println("error is while selecting product variants", err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	variants := map[string][]models.Product{}
+	for rows.Next() {
+		variant, err := scanProduct(rows)
+		if err != nil {
+			fmt.Println("error is while scanning product variants", err.Error())
+			return nil, err
+		}
+		variants[variant.ParentID] = append(variants[variant.ParentID], variant)
+	}
+
+	return variants, nil
+}
+
+func (p productRepo) AddBarcode(ctx context.Context, barcode models.CreateProductBarcode) (_ string, err error) {
+	tx, err := p.db.Begin(ctx)
+	if err != nil {
+		fmt.Println("error is while beginning transaction", err.Error())
+		return "", err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+			return
+		}
+		err = tx.Commit(ctx)
+	}()
+
+	if err = insertProductBarcode(ctx, tx, barcode); err != nil {
+		return "", err
+	}
+
+	// the first barcode of a product without one becomes its main barcode
+	if _, err = tx.Exec(ctx, `update products set barcode = $1, updated_at = now() where id = $2 and barcode is null`,
+		barcode.Barcode, barcode.ProductID); err != nil {
+		fmt.Println("error is while updating product barcode", err.Error())
+		err = barcodeError(err)
+		return "", err
+	}
+
+	return barcode.ProductID, nil
+}
+
+func (p productRepo) DeleteBarcode(ctx context.Context, productID, barcodeID string) (err error) {
+	tx, err := p.db.Begin(ctx)
+	if err != nil {
+		fmt.Println("error is while beginning transaction", err.Error())
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+			return
+		}
+		err = tx.Commit(ctx)
+	}()
+
+	var barcode string
+	if err = tx.QueryRow(ctx, `update product_barcodes set deleted_at = now() 
+                                  where id = $1 and product_id = $2 and deleted_at is null returning barcode`,
+		barcodeID, productID).Scan(&barcode); err != nil {
+		fmt.Println("error is while deleting product barcode", err.Error())
+		return err
+	}
+
+	// when the main barcode is removed another barcode of the product takes its place
+	if _, err = tx.Exec(ctx, `update products set barcode = (
+			select barcode from product_barcodes where product_id = $1 and deleted_at is null order by quantity, created_at limit 1
+		), updated_at = now() where id = $1 and barcode = $2`, productID, barcode); err != nil {
+		fmt.Println("error is while updating product barcode", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (p productRepo) GetByBarcode(ctx context.Context, barcode string) (models.ProductBarcode, error) {
+	productBarcode := models.ProductBarcode{}
+	query := `select b.id, b.product_id, b.barcode, b.quantity, b.created_at from product_barcodes b
+    				join products p on p.id = b.product_id and p.deleted_at is null
+						where b.barcode = $1 and b.deleted_at is null`
+	if err := p.db.QueryRow(ctx, query, barcode).Scan(
+		&productBarcode.ID,
+		&productBarcode.ProductID,
+		&productBarcode.Barcode,
+		&productBarcode.Quantity,
+		&productBarcode.CreatedAt,
+	); err != nil {
+		fmt.Println("error is while selecting product by barcode", err.Error())
+		return models.ProductBarcode{}, err
+	}
+	return productBarcode, nil
+}
+
+func (p productRepo) AddUnit(ctx context.Context, unit models.CreateProductUnit) (string, error) {
+	if _, err := p.db.Exec(ctx, `insert into product_units (id, product_id, name, quantity) values($1, $2, $3, $4)`,
+		uuid.New(),
+		unit.ProductID,
+		unit.Name,
+		unit.Quantity,
+	); err != nil {
+		fmt.Println("error is while inserting product unit", err.Error())
+		return "", err
+	}
+
+	return unit.ProductID, nil
+}
+
+func (p productRepo) DeleteUnit(ctx context.Context, productID, unitID string) error {
+	tag, err := p.db.Exec(ctx, `update product_units set deleted_at = now() 
+                                  where id = $1 and product_id = $2 and deleted_at is null`, unitID, productID)
+	if err != nil {
+		fmt.Println("error is while deleting product unit", err.Error())
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+
+	return nil
+}
+
+// insertProductPrice starts a price of the product for all branches now, a nil price
+// is kept for a variant which goes back to the price of its parent.
+func insertProductPrice(ctx context.Context, tx pgx.Tx, productID string, price interface{}) error {
+	if _, err := tx.Exec(ctx, `insert into product_prices (id, product_id, price) values($1, $2, $3)`,
+		uuid.New(),
+		productID,
+		price,
+	); err != nil {
+		fmt.Println("error is while inserting product price", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func insertProductBarcode(ctx context.Context, tx pgx.Tx, barcode models.CreateProductBarcode) error {
+	if barcode.Quantity <= 0 {
+		barcode.Quantity = 1
+	}
+
+	if _, err := tx.Exec(ctx, `insert into product_barcodes (id, product_id, barcode, quantity) values($1, $2, $3, $4)`,
+		uuid.New(),
+		barcode.ProductID,
+		barcode.Barcode,
+		barcode.Quantity,
+	); err != nil {
+		fmt.Println("error is while inserting product barcode", err.Error())
+		return barcodeError(err)
+	}
+
+	return nil
+}
+
+// barcodeError turns the violation of a unique barcode into ErrBarcodeExists.
+func barcodeError(err error) error {
+	if pgErr := (&pgconn.PgError{}); errors.As(err, &pgErr) &&
+		(pgErr.ConstraintName == "product_barcodes_barcode_idx" || pgErr.ConstraintName == "products_barcode_idx") {
+		return storage.ErrBarcodeExists
+	}
+	return err
+}
+
+// productColumns are read from productsJoin with the price valid now for all branches,
+// a variant without its own price has the price of its parent.
+var productColumns = `p.id, p.name, p.name_translations, ` + effectivePrice("null::uuid", "now()") + `, 
+       coalesce(` + fmt.Sprintf(priceAt, "p.id", "null::uuid", "now()") + `, p.price), coalesce(p.barcode, ''), p.unit, p.unit_precision,
+       coalesce(p.category_id, ''), coalesce(p.parent_id::text, ''), p.attributes, ` + productImageColumns + `, p.created_at, p.updated_at`
+
+// productImageColumns are the urls of the first image of product p.
+const productImageColumns = `coalesce((select i.url from product_images i 
+                                      where i.product_id = p.id and i.deleted_at is null order by i.position, i.created_at limit 1), ''),
+       coalesce((select i.thumbnail_url from product_images i 
+                                      where i.product_id = p.id and i.deleted_at is null order by i.position, i.created_at limit 1), '')`
+
+const productsJoin = `products p left join products pp on pp.id = p.parent_id`
+
+func scanProduct(row pgx.Row) (models.Product, error) {
+	product := models.Product{}
+	if err := row.Scan(
+		&product.ID,
+		&product.Name,
+		&product.NameTranslations,
+		&product.Price,
+		&product.PriceOverride,
+		&product.Barcode,
+		&product.Unit,
+		&product.Precision,
+		&product.CategoryID,
+		&product.ParentID,
+		&product.Attributes,
+		&product.ImageURL,
+		&product.ThumbnailURL,
+		&product.CreatedAt,
+		&product.UpdatedAt,
+	); err != nil {
+		return models.Product{}, err
+	}
+
+	// the price of a parent product is its own, only variants override a price
+	if product.ParentID == "" {
+		product.PriceOverride = nil
+	}
+
+	return product, nil
+}
+
+// variantName joins the parent name and the attribute values ordered by attribute name, e.g. "T-shirt M red".
+func variantName(parentName string, attributes map[string]string) string {
+	keys := make([]string, 0, len(attributes))
+	for key := range attributes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	name := parentName
+	for _, key := range keys {
+		name += " " + attributes[key]
+	}
+
+	return name
+}
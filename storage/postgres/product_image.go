@@ -0,0 +1,96 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"sell/api/models"
+)
+
+// AddImage adds an image after the other images of the product, the first image is the main one.
+func (p productRepo) AddImage(ctx context.Context, image models.CreateProductImage) (string, error) {
+	id := uuid.New()
+	if _, err := p.db.Exec(ctx, `insert into product_images 
+    		(id, product_id, key, url, thumbnail_key, thumbnail_url, content_type, width, height, size, position)
+				select $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, coalesce(max(position) + 1, 0) 
+					from product_images where product_id = $2 and deleted_at is null`,
+		id,
+		image.ProductID,
+		image.Key,
+		image.URL,
+		image.ThumbnailKey,
+		image.ThumbnailURL,
+		image.ContentType,
+		image.Width,
+		image.Height,
+		image.Size,
+	); err != nil {
+		fmt.Println("error is while inserting product image", err.Error())
+		return "", err
+	}
+
+	return id.String(), nil
+}
+
+func (p productRepo) GetImages(ctx context.Context, productID string) ([]models.ProductImage, error) {
+	rows, err := p.db.Query(ctx, `select id, product_id, url, thumbnail_url, key, thumbnail_key, content_type, 
+       width, height, size, position, created_at from product_images 
+			where product_id = $1 and deleted_at is null order by position, created_at`, productID)
+	if err != nil {
+		fmt.Println("error is while selecting product images", err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	images := []models.ProductImage{}
+	for rows.Next() {
+		image := models.ProductImage{}
+		if err := rows.Scan(
+			&image.ID,
+			&image.ProductID,
+			&image.URL,
+			&image.ThumbnailURL,
+			&image.Key,
+			&image.ThumbnailKey,
+			&image.ContentType,
+			&image.Width,
+			&image.Height,
+			&image.Size,
+			&image.Position,
+			&image.CreatedAt,
+		); err != nil {
+			fmt.Println("error is while scanning product images", err.Error())
+			return nil, err
+		}
+		images = append(images, image)
+	}
+
+	return images, nil
+}
+
+// DeleteImage removes the image and returns it so its files can be deleted.
+func (p productRepo) DeleteImage(ctx context.Context, productID, imageID string) (models.ProductImage, error) {
+	image := models.ProductImage{}
+	if err := p.db.QueryRow(ctx, `update product_images set deleted_at = now(), updated_at = now() 
+                      where id = $1 and product_id = $2 and deleted_at is null 
+                      	returning id, product_id, url, thumbnail_url, key, thumbnail_key, content_type, width, height, size, position, created_at`,
+		imageID, productID).Scan(
+		&image.ID,
+		&image.ProductID,
+		&image.URL,
+		&image.ThumbnailURL,
+		&image.Key,
+		&image.ThumbnailKey,
+		&image.ContentType,
+		&image.Width,
+		&image.Height,
+		&image.Size,
+		&image.Position,
+		&image.CreatedAt,
+	); err != nil {
+		fmt.Println("error is while deleting product image", err.Error())
+		return models.ProductImage{}, err
+	}
+
+	return image, nil
+}
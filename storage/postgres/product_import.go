@@ -0,0 +1,233 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"sell/api/models"
+	"strings"
+)
+
+// Import upserts products by barcode in one transaction, rows without a barcode are always created.
+// Categories of the path are found by name under their parent and created when missing.
+// A failing row is reported and skipped, with dryRun nothing is saved but every row is checked against the database.
+func (p productRepo) Import(ctx context.Context, rows []models.ProductImportRow, dryRun bool) (_ models.ProductImportResponse, err error) {
+	response := models.ProductImportResponse{
+		DryRun: dryRun,
+		Total:  len(rows),
+		Errors: []models.ProductImportError{},
+	}
+
+	tx, err := p.db.Begin(ctx)
+	if err != nil {
+		fmt.Println("error is while beginning transaction", err.Error())
+		return models.ProductImportResponse{}, err
+	}
+	defer func() {
+		if err != nil || dryRun {
+			tx.Rollback(ctx)
+			return
+		}
+		err = tx.Commit(ctx)
+	}()
+
+	categories := map[string]string{}
+	for _, row := range rows {
+		// every row runs in a savepoint so a failing row does not abort the others
+		rowTx, err := tx.Begin(ctx)
+		if err != nil {
+			fmt.Println("error is while creating savepoint", err.Error())
+			return models.ProductImportResponse{}, err
+		}
+
+		created, newCategories, rowErr := importProductRow(ctx, rowTx, row, categories)
+		if rowErr != nil {
+			if err = rowTx.Rollback(ctx); err != nil {
+				fmt.Println("error is while rolling back savepoint", err.Error())
+				return models.ProductImportResponse{}, err
+			}
+			// categories made by the failed row are gone with its savepoint
+			for _, path := range newCategories {
+				delete(categories, path)
+			}
+
+			message := rowErr.Error()
+			if pgErr := (&pgconn.PgError{}); errors.As(rowErr, &pgErr) {
+				message = pgErr.Message
+			}
+			response.Failed++
+			response.Errors = append(response.Errors, models.ProductImportError{Row: row.Row, Message: message})
+			continue
+		}
+
+		if err = rowTx.Commit(ctx); err != nil {
+			fmt.Println("error is while releasing savepoint", err.Error())
+			return models.ProductImportResponse{}, err
+		}
+
+		response.CategoriesCreated += len(newCategories)
+		if created {
+			response.Created++
+		} else {
+			response.Updated++
+		}
+	}
+
+	return response, nil
+}
+
+// importProductRow creates or updates the product of one row. It returns whether the product is new
+// and the paths of the categories it created.
+func importProductRow(ctx context.Context, tx pgx.Tx, row models.ProductImportRow, categories map[string]string) (bool, []string, error) {
+	categoryID, newCategories, err := categoryPath(ctx, tx, row.Category, categories)
+	if err != nil {
+		return false, newCategories, err
+	}
+
+	var productID string
+	if row.Barcode != "" {
+		var quantity int
+		err := tx.QueryRow(ctx, `select b.product_id, b.quantity from product_barcodes b
+    				join products p on p.id = b.product_id and p.deleted_at is null
+						where b.barcode = $1 and b.deleted_at is null`, row.Barcode).Scan(&productID, &quantity)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			fmt.Println("error is while selecting product by barcode", err.Error())
+			return false, newCategories, err
+		}
+		if quantity > 1 {
+			return false, newCategories, fmt.Errorf("barcode %s is a pack barcode of product %s", row.Barcode, productID)
+		}
+	}
+
+	if productID == "" {
+		if row.Price == nil {
+			return false, newCategories, errors.New("price is required for a new product")
+		}
+
+		id := uuid.New().String()
+		if _, err := tx.Exec(ctx, `insert into products (id, name, price, barcode, unit, unit_precision, category_id)
+				values($1, $2, $3, nullif($4, ''), 'piece', 0, nullif($5, ''))`,
+			id, row.Name, *row.Price, row.Barcode, categoryID); err != nil {
+			fmt.Println("error is while inserting imported product", err.Error())
+			return false, newCategories, barcodeError(err)
+		}
+
+		if err := insertProductPrice(ctx, tx, id, *row.Price); err != nil {
+			return false, newCategories, err
+		}
+
+		if row.Barcode != "" {
+			if err := insertProductBarcode(ctx, tx, models.CreateProductBarcode{
+				ProductID: id,
+				Barcode:   row.Barcode,
+				Quantity:  1,
+			}); err != nil {
+				return false, newCategories, err
+			}
+		}
+
+		return true, newCategories, nil
+	}
+
+	// an empty category keeps the category of the product
+	if _, err := tx.Exec(ctx, `update products set name = $1, category_id = coalesce(nullif($2, ''), category_id), updated_at = now()
+                where id = $3`, row.Name, categoryID, productID); err != nil {
+		fmt.Println("error is while updating imported product", err.Error())
+		return false, newCategories, err
+	}
+
+	if row.Price != nil {
+		tag, err := tx.Exec(ctx, `update products set price = $1, updated_at = now() where id = $2 and price is distinct from $1`,
+			*row.Price, productID)
+		if err != nil {
+			fmt.Println("error is while updating imported product price", err.Error())
+			return false, newCategories, err
+		}
+		if tag.RowsAffected() > 0 {
+			if err := insertProductPrice(ctx, tx, productID, *row.Price); err != nil {
+				return false, newCategories, err
+			}
+		}
+	}
+
+	return false, newCategories, nil
+}
+
+// categoryPath returns the id of the last category of the path creating the missing ones,
+// known caches ids by path. Names are compared case insensitively.
+func categoryPath(ctx context.Context, tx pgx.Tx, path []string, known map[string]string) (string, []string, error) {
+	var (
+		parentID      string
+		newCategories []string
+	)
+
+	for i, name := range path {
+		key := strings.ToLower(strings.Join(path[:i+1], "/"))
+		if id, ok := known[key]; ok {
+			parentID = id
+			continue
+		}
+
+		var id string
+		err := tx.QueryRow(ctx, `select id from categories
+                 where lower(name) = lower($1) and coalesce(parent_id, '') = $2 and deleted_at is null
+                 	order by created_at limit 1`, name, parentID).Scan(&id)
+		if errors.Is(err, pgx.ErrNoRows) {
+			id = uuid.New().String()
+			if _, err = tx.Exec(ctx, `insert into categories (id, name, parent_id) values($1, $2, nullif($3, ''))`,
+				id, name, parentID); err != nil {
+				fmt.Println("error is while inserting category", err.Error())
+				return "", newCategories, err
+			}
+			newCategories = append(newCategories, key)
+		} else if err != nil {
+			fmt.Println("error is while selecting category", err.Error())
+			return "", newCategories, err
+		}
+
+		known[key] = id
+		parentID = id
+	}
+
+	return parentID, newCategories, nil
+}
+
+// Export returns all products with their current price and the path of their category, like Food/Dairy/Milk.
+func (p productRepo) Export(ctx context.Context) ([]models.ProductExportRow, error) {
+	query := `with recursive paths as (
+					select id, name::text as path from categories where parent_id is null and deleted_at is null
+					union all
+					select c.id, paths.path || '/' || c.name from categories c
+						join paths on c.parent_id = paths.id where c.deleted_at is null
+				)
+				select p.name, ` + effectivePrice("null::uuid", "now()") + `, coalesce(p.barcode, ''), coalesce(paths.path, '')
+					from ` + productsJoin + ` left join paths on paths.id = p.category_id
+						where p.deleted_at is null order by paths.path nulls first, p.name`
+
+	rows, err := p.db.Query(ctx, query)
+	if err != nil {
+		fmt.Println("error is while selecting products for export", err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	products := []models.ProductExportRow{}
+	for rows.Next() {
+		product := models.ProductExportRow{}
+		if err := rows.Scan(
+			&product.Name,
+			&product.Price,
+			&product.Barcode,
+			&product.Category,
+		); err != nil {
+			fmt.Println("error is while scanning products for export", err.Error())
+			return nil, err
+		}
+		products = append(products, product)
+	}
+
+	return products, nil
+}
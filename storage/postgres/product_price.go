@@ -0,0 +1,148 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"sell/api/models"
+	"sell/storage"
+	"time"
+)
+
+type productPriceRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewProductPriceRepo(db *pgxpool.Pool) storage.IProductPriceStorage {
+	return &productPriceRepo{db: db}
+}
+
+// Create schedules a price of the product for all branches or for one branch.
+// The price is used from price.StartsAt, which is now when it is not given.
+func (p *productPriceRepo) Create(ctx context.Context, price models.CreateProductPrice) (string, error) {
+	id := uuid.New()
+	if _, err := p.db.Exec(ctx, `insert into product_prices (id, product_id, branch_id, price, starts_at)
+					values($1, $2, nullif($3, '')::uuid, $4, coalesce(nullif($5, '')::timestamp, now()))`,
+		id,
+		price.ProductID,
+		price.BranchID,
+		price.Price,
+		price.StartsAt,
+	); err != nil {
+		fmt.Println("error is while inserting product price", err.Error())
+		return "", err
+	}
+
+	return id.String(), nil
+}
+
+// GetList returns the price history of a product with scheduled prices first.
+// With request.BranchID prices of that branch and prices for all branches are returned.
+func (p *productPriceRepo) GetList(ctx context.Context, request models.ProductPriceGetListRequest) (models.ProductPricesResponse, error) {
+	var (
+		prices = []models.ProductPrice{}
+		count  int
+		args   = []interface{}{request.ProductID}
+		filter string
+	)
+
+	if request.BranchID != "" {
+		args = append(args, request.BranchID)
+		filter += fmt.Sprintf(` and (branch_id = $%d or branch_id is null)`, len(args))
+	}
+
+	if err := p.db.QueryRow(ctx, `select count(1) from product_prices where product_id = $1 and deleted_at is null `+filter,
+		args...).Scan(&count); err != nil {
+		fmt.Println("error is while selecting count of product prices", err.Error())
+		return models.ProductPricesResponse{}, err
+	}
+
+	query := `select id, product_id, coalesce(branch_id::text, ''), price, starts_at, created_at from product_prices
+					where product_id = $1 and deleted_at is null ` + filter +
+		fmt.Sprintf(` order by starts_at desc, created_at desc LIMIT $%d OFFSET $%d`, len(args)+1, len(args)+2)
+
+	rows, err := p.db.Query(ctx, query, append(args, request.Limit, (request.Page-1)*request.Limit)...)
+	if err != nil {
+		fmt.Println("error is while selecting product prices", err.Error())
+		return models.ProductPricesResponse{}, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		price := models.ProductPrice{}
+		if err := rows.Scan(
+			&price.ID,
+			&price.ProductID,
+			&price.BranchID,
+			&price.Price,
+			&price.StartsAt,
+			&price.CreatedAt,
+		); err != nil {
+			fmt.Println("error is while scanning product prices", err.Error())
+			return models.ProductPricesResponse{}, err
+		}
+		prices = append(prices, price)
+	}
+
+	return models.ProductPricesResponse{
+		ProductPrices: prices,
+		Count:         count,
+	}, nil
+}
+
+// Delete cancels a scheduled price, prices which already started are history and stay.
+func (p *productPriceRepo) Delete(ctx context.Context, productID, id string) error {
+	var startsAt time.Time
+	if err := p.db.QueryRow(ctx, `select starts_at from product_prices where id = $1 and product_id = $2 and deleted_at is null`,
+		id, productID).Scan(&startsAt); err != nil {
+		fmt.Println("error is while selecting product price", err.Error())
+		return err
+	}
+
+	tag, err := p.db.Exec(ctx, `update product_prices set deleted_at = now(), updated_at = now() 
+                      where id = $1 and starts_at > now() and deleted_at is null`, id)
+	if err != nil {
+		fmt.Println("error is while deleting product price", err.Error())
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return storage.ErrPriceStarted
+	}
+
+	return nil
+}
+
+// GetEffective returns the price the product had in the branch at request.At.
+func (p *productPriceRepo) GetEffective(ctx context.Context, request models.EffectivePriceRequest) (models.EffectivePrice, error) {
+	price := models.EffectivePrice{
+		ProductID: request.ProductID,
+		BranchID:  request.BranchID,
+		At:        request.At,
+	}
+
+	query := `select ` + effectivePrice("nullif($2, '')::uuid", "$3::timestamp") + ` from ` + productsJoin + ` where p.id = $1`
+	if err := p.db.QueryRow(ctx, query, request.ProductID, request.BranchID, request.At).Scan(&price.Price); err != nil {
+		fmt.Println("error is while selecting effective product price", err.Error())
+		return models.EffectivePrice{}, err
+	}
+
+	return price, nil
+}
+
+// priceAt selects the price of a product valid at a moment: the latest started price of the branch or
+// of all branches, the price of the branch when both start at once. A later price for all branches ends
+// an override of the branch. It is null when the product has no price of its own.
+const priceAt = `(select pr.price from product_prices pr 
+			where pr.product_id = %[1]s and pr.deleted_at is null and pr.starts_at <= %[3]s 
+			  and (pr.branch_id = %[2]s or pr.branch_id is null)
+				order by pr.starts_at desc, pr.branch_id is null, pr.created_at desc limit 1)`
+
+// effectivePrice is an SQL expression of the price of product p of productsJoin in the branch at the moment.
+// A variant without a price of its own is sold at the parent price, products.price is used
+// for products without any price history.
+func effectivePrice(branch, at string) string {
+	return `coalesce(` + fmt.Sprintf(priceAt, "p.id", branch, at) + `, ` +
+		fmt.Sprintf(priceAt, "p.parent_id", branch, at) + `, p.price, pp.price, 0)`
+}
@@ -0,0 +1,129 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"sell/api/models"
+	"strings"
+	"unicode"
+)
+
+// Search finds products by barcode prefix, name prefix, words of the name or category and by similar
+// spelling. The best matches come first: an exact barcode, then names starting with the query, then
+// products ranked by text relevance and similarity. Price and stock are those of request.BranchID,
+// without a branch the stock of all branches is summed.
+func (p productRepo) Search(ctx context.Context, request models.ProductSearchRequest) (models.ProductSearchResponse, error) {
+	var (
+		search   = strings.ToLower(strings.TrimSpace(request.Query))
+		pattern  = likeEscaper.Replace(search) + "%"
+		tsQuery  = prefixTSQuery(search)
+		response = models.ProductSearchResponse{Products: []models.ProductSearchResult{}}
+	)
+
+	query := `with candidates as (
+					select p.id, c.name as category_name,
+						exists (select 1 from product_barcodes b
+						   where b.product_id = p.id and b.barcode = $1 and b.deleted_at is null) as barcode_match,
+						exists (select 1 from product_barcodes b
+						   where b.product_id = p.id and b.barcode like $2 and b.deleted_at is null) as barcode_prefix,
+						lower(p.name) like $2 as name_prefix,
+						$3 <> '' and (to_tsvector('simple', p.name) @@ to_tsquery('simple', $3)
+						   or to_tsvector('simple', coalesce(c.name, '')) @@ to_tsquery('simple', $3)) as text_match,
+						case when $3 = '' then 0 else ts_rank(setweight(to_tsvector('simple', p.name), 'A') ||
+						   setweight(to_tsvector('simple', coalesce(c.name, '')), 'C'), to_tsquery('simple', $3)) end as text_rank,
+						greatest(word_similarity($1, lower(p.name)), similarity($1, lower(coalesce(c.name, ''))) / 2,
+						   (select max(word_similarity($1, lower(t.value))) from jsonb_each_text(p.name_translations) t)) as similarity
+					from products p
+						left join categories c on c.id = p.category_id
+							where p.deleted_at is null and (
+							   p.id in (select product_id from product_barcodes where barcode like $2 and deleted_at is null)
+							   or lower(p.name) like $2
+							   or ($3 <> '' and (to_tsvector('simple', p.name) @@ to_tsquery('simple', $3)
+								  or to_tsvector('simple', coalesce(c.name, '')) @@ to_tsquery('simple', $3)))
+							   or lower(p.name) % $1 or $1 <% lower(p.name)
+							   or lower(c.name) % $1
+							   or exists (select 1 from jsonb_each_text(p.name_translations) t
+								  where lower(t.value) like $2 or lower(t.value) % $1))
+				), ranked as (
+					select *,
+						case when barcode_match then 4 when name_prefix then 3 when barcode_prefix then 2 else 0 end
+						   + text_rank + similarity as rank
+						from candidates
+				)
+				select p.id, p.name, p.name_translations, ` + effectivePrice("nullif($4, '')::uuid", "now()") + `, p.price, coalesce(p.barcode, ''),
+				       p.unit, p.unit_precision, coalesce(p.category_id, ''), coalesce(p.parent_id::text, ''), p.attributes,
+				       ` + productImageColumns + `, p.created_at, p.updated_at, coalesce(r.category_name, ''),
+				       coalesce((select sum(s.count) from repositories s where s.product_id = p.id and s.deleted_at is null
+				                    and ($4 = '' or s.branch_id = nullif($4, '')::uuid)), 0),
+				       case when r.barcode_match or r.barcode_prefix then 'barcode' when r.name_prefix then 'prefix'
+				            when r.text_match then 'text' else 'fuzzy' end,
+				       r.rank
+					from ranked r
+						join ` + productsJoin + ` on p.id = r.id
+							order by r.rank desc, p.name limit $5`
+
+	rows, err := p.db.Query(ctx, query, search, pattern, tsQuery, request.BranchID, request.Limit)
+	if err != nil {
+		fmt.Println("error is while searching products", err.Error())
+		return models.ProductSearchResponse{}, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		result := models.ProductSearchResult{}
+		if err := rows.Scan(
+			&result.ID,
+			&result.Name,
+			&result.NameTranslations,
+			&result.Price,
+			&result.PriceOverride,
+			&result.Barcode,
+			&result.Unit,
+			&result.Precision,
+			&result.CategoryID,
+			&result.ParentID,
+			&result.Attributes,
+			&result.ImageURL,
+			&result.ThumbnailURL,
+			&result.CreatedAt,
+			&result.UpdatedAt,
+			&result.CategoryName,
+			&result.Stock,
+			&result.Match,
+			&result.Rank,
+		); err != nil {
+			fmt.Println("error is while scanning searched products", err.Error())
+			return models.ProductSearchResponse{}, err
+		}
+
+		if result.ParentID == "" {
+			result.PriceOverride = nil
+		}
+		response.Products = append(response.Products, result)
+	}
+
+	if err := rows.Err(); err != nil {
+		fmt.Println("error is while searching products", err.Error())
+		return models.ProductSearchResponse{}, err
+	}
+	response.Count = len(response.Products)
+
+	return response, nil
+}
+
+// likeEscaper escapes the wildcards of a like pattern.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// prefixTSQuery makes a text query matching every word of the search as a word prefix:
+// "choc mil" becomes "choc:* & mil:*". Characters other than letters and digits separate words.
+func prefixTSQuery(search string) string {
+	words := strings.FieldsFunc(search, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	for i, word := range words {
+		words[i] = word + ":*"
+	}
+
+	return strings.Join(words, " & ")
+}
@@ -0,0 +1,397 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"sell/api/models"
+	"sell/pkg/money"
+	"sell/storage"
+)
+
+type purchaseOrderRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewPurchaseOrderRepo(db *pgxpool.Pool) storage.IPurchaseOrderStorage {
+	return &purchaseOrderRepo{db: db}
+}
+
+func (p *purchaseOrderRepo) Create(ctx context.Context, order models.CreatePurchaseOrder) (_ string, err error) {
+	id := uuid.New()
+
+	tx, err := p.db.Begin(ctx)
+	if err != nil {
+		fmt.Println("error is while beginning transaction", err.Error())
+		return "", err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+			return
+		}
+		err = tx.Commit(ctx)
+	}()
+
+	price := money.Money(0)
+	for _, product := range order.Products {
+		var amount money.Money
+		if amount, err = product.Price.Mul(product.Count); err != nil {
+			return "", err
+		}
+		price += amount
+	}
+
+	if _, err = tx.Exec(ctx, `insert into purchase_orders (id, supplier_id, branch_id, currency, price, comment)
+						values($1, $2, $3, $4, $5, $6)`,
+		id,
+		order.SupplierID,
+		order.BranchID,
+		order.Currency,
+		price,
+		order.Comment,
+	); err != nil {
+		fmt.Println("error is while inserting purchase order", err.Error())
+		return "", err
+	}
+
+	for _, product := range order.Products {
+		if _, err = tx.Exec(ctx, `insert into purchase_order_products (id, purchase_order_id, product_id, price, count)
+						values($1, $2, $3, $4, $5)`,
+			uuid.New(),
+			id,
+			product.ProductID,
+			product.Price,
+			product.Count,
+		); err != nil {
+			fmt.Println("error is while inserting purchase order product", err.Error())
+			return "", err
+		}
+	}
+
+	return id.String(), nil
+}
+
+func (p *purchaseOrderRepo) GetByID(ctx context.Context, id string) (models.PurchaseOrder, error) {
+	order := models.PurchaseOrder{}
+
+	query := `select id, supplier_id, branch_id, status, currency, price, coalesce(comment, ''), created_at, updated_at
+						from purchase_orders where id = $1 and deleted_at is null`
+	if err := p.db.QueryRow(ctx, query, id).Scan(
+		&order.ID,
+		&order.SupplierID,
+		&order.BranchID,
+		&order.Status,
+		&order.Currency,
+		&order.Price,
+		&order.Comment,
+		&order.CreatedAt,
+		&order.UpdatedAt,
+	); err != nil {
+		fmt.Println("error is while selecting purchase order by id", err.Error())
+		return models.PurchaseOrder{}, err
+	}
+
+	rows, err := p.db.Query(ctx, `select id, purchase_order_id, product_id, price, count, received_count
+						from purchase_order_products where purchase_order_id = $1 and deleted_at is null order by created_at`, id)
+	if err != nil {
+		fmt.Println("error is while selecting purchase order products", err.Error())
+		return models.PurchaseOrder{}, err
+	}
+	defer rows.Close()
+
+	order.Products = []models.PurchaseOrderProduct{}
+	for rows.Next() {
+		product := models.PurchaseOrderProduct{}
+		if err := rows.Scan(
+			&product.ID,
+			&product.PurchaseOrderID,
+			&product.ProductID,
+			&product.Price,
+			&product.Count,
+			&product.ReceivedCount,
+		); err != nil {
+			fmt.Println("error is while scanning purchase order products", err.Error())
+			return models.PurchaseOrder{}, err
+		}
+		order.Products = append(order.Products, product)
+	}
+
+	return order, nil
+}
+
+func (p *purchaseOrderRepo) GetList(ctx context.Context, request models.PurchaseOrderGetListRequest) (models.PurchaseOrderResponse, error) {
+	var (
+		count  int
+		orders = []models.PurchaseOrder{}
+		filter string
+		args   []interface{}
+		offset = (request.Page - 1) * request.Limit
+	)
+
+	if request.SupplierID != "" {
+		args = append(args, request.SupplierID)
+		filter += fmt.Sprintf(` and supplier_id = $%d`, len(args))
+	}
+
+	if request.BranchID != "" {
+		args = append(args, request.BranchID)
+		filter += fmt.Sprintf(` and branch_id = $%d`, len(args))
+	}
+
+	if request.Status != "" {
+		args = append(args, request.Status)
+		filter += fmt.Sprintf(` and status = $%d`, len(args))
+	}
+
+	countQuery := `select count(1) from purchase_orders where deleted_at is null ` + filter
+	if err := p.db.QueryRow(ctx, countQuery, args...).Scan(&count); err != nil {
+		fmt.Println("error is while selecting count of purchase orders", err.Error())
+		return models.PurchaseOrderResponse{}, err
+	}
+
+	query := `select id, supplier_id, branch_id, status, currency, price, coalesce(comment, ''), created_at, updated_at
+						from purchase_orders where deleted_at is null ` + filter +
+		fmt.Sprintf(` order by created_at desc LIMIT $%d OFFSET $%d`, len(args)+1, len(args)+2)
+
+	rows, err := p.db.Query(ctx, query, append(args, request.Limit, offset)...)
+	if err != nil {
+		fmt.Println("error is while selecting purchase orders", err.Error())
+		return models.PurchaseOrderResponse{}, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		order := models.PurchaseOrder{}
+		if err := rows.Scan(
+			&order.ID,
+			&order.SupplierID,
+			&order.BranchID,
+			&order.Status,
+			&order.Currency,
+			&order.Price,
+			&order.Comment,
+			&order.CreatedAt,
+			&order.UpdatedAt,
+		); err != nil {
+			fmt.Println("error is while scanning purchase orders", err.Error())
+			return models.PurchaseOrderResponse{}, err
+		}
+		orders = append(orders, order)
+	}
+
+	return models.PurchaseOrderResponse{
+		PurchaseOrders: orders,
+		Count:          count,
+	}, nil
+}
+
+func (p *purchaseOrderRepo) Cancel(ctx context.Context, id string) error {
+	tag, err := p.db.Exec(ctx, `update purchase_orders set status = 'cancelled', updated_at = now()
+                       where id = $1 and status = 'ordered' and deleted_at is null`, id)
+	if err != nil {
+		fmt.Println("error is while cancelling purchase order", err.Error())
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return storage.ErrPurchaseOrderClosed
+	}
+
+	return nil
+}
+
+// Receive creates an income of the purchase order branch and supplier from the received lines,
+// adds them to the branch repository and moves the order to partially received or received.
+func (p *purchaseOrderRepo) Receive(ctx context.Context, request models.ReceivePurchaseOrder) (_ string, err error) {
+	incomeID := uuid.New()
+
+	tx, err := p.db.Begin(ctx)
+	if err != nil {
+		fmt.Println("error is while beginning transaction", err.Error())
+		return "", err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+			return
+		}
+		err = tx.Commit(ctx)
+	}()
+
+	var supplierID, branchID, status, currency string
+	if err = tx.QueryRow(ctx, `select supplier_id, branch_id, status, currency from purchase_orders
+                                 where id = $1 and deleted_at is null for update`, request.ID).Scan(
+		&supplierID,
+		&branchID,
+		&status,
+		&currency,
+	); err != nil {
+		fmt.Println("error is while selecting purchase order for update", err.Error())
+		return "", err
+	}
+
+	if status != "ordered" && status != "partially_received" {
+		err = storage.ErrPurchaseOrderClosed
+		return "", err
+	}
+
+	price := money.Money(0)
+	for _, product := range request.Products {
+		var amount money.Money
+		if amount, err = product.Price.Mul(product.Count); err != nil {
+			return "", err
+		}
+		price += amount
+	}
+
+	// the received goods are added to the repository right away, so the income is created finished,
+	// it is in the currency of the order at the rate of the day the goods arrived
+	if _, err = tx.Exec(ctx, `insert into incomes (id, branch_id, supplier_id, purchase_order_id, currency, exchange_rate, price, status, finished_at)
+						values($1, $2, $3, $4, $5, $6, $7, 'finished', now())`,
+		incomeID,
+		branchID,
+		supplierID,
+		request.ID,
+		currency,
+		request.ExchangeRate,
+		price,
+	); err != nil {
+		fmt.Println("error is while inserting income", err.Error())
+		return "", err
+	}
+
+	for _, product := range request.Products {
+		var unitQuantity float64
+		if unitQuantity, err = productUnitQuantity(ctx, tx, product.ProductID, product.ProductUnitID); err != nil {
+			return "", err
+		}
+
+		incomeProduct := models.IncomeProduct{
+			ID:            uuid.New().String(),
+			IncomeID:      incomeID.String(),
+			ProductID:     product.ProductID,
+			ProductUnitID: product.ProductUnitID,
+			UnitQuantity:  unitQuantity,
+			Price:         product.Price,
+			Count:         product.Count,
+			BatchNumber:   product.BatchNumber,
+			ExpiryDate:    product.ExpiryDate,
+		}
+
+		if _, err = tx.Exec(ctx, `insert into income_products (id, income_id, product_id, product_unit_id, unit_quantity, price, count, batch_number, expiry_date)
+						values($1, $2, $3, nullif($4, '')::uuid, $5, $6, $7, nullif($8, ''), nullif($9, '')::date)`,
+			incomeProduct.ID,
+			incomeProduct.IncomeID,
+			incomeProduct.ProductID,
+			incomeProduct.ProductUnitID,
+			incomeProduct.UnitQuantity,
+			incomeProduct.Price,
+			incomeProduct.Count,
+			incomeProduct.BatchNumber,
+			incomeProduct.ExpiryDate,
+		); err != nil {
+			fmt.Println("error is while inserting income product", err.Error())
+			return "", err
+		}
+
+		// products the supplier sent without an order line show up as discrepancies only,
+		// orders are made in units of the product while goods may arrive in packs
+		if _, err = tx.Exec(ctx, `update purchase_order_products set received_count = received_count + $1, updated_at = now()
+					where id = (select id from purchase_order_products where purchase_order_id = $2 and product_id = $3
+					                and deleted_at is null order by created_at limit 1)`,
+			roundQuantity(product.Count*unitQuantity),
+			request.ID,
+			product.ProductID,
+		); err != nil {
+			fmt.Println("error is while updating received count", err.Error())
+			return "", err
+		}
+
+		if err = addIncomeStock(ctx, tx, branchID, request.ExchangeRate, incomeProduct); err != nil {
+			return "", err
+		}
+	}
+
+	if _, err = tx.Exec(ctx, `update purchase_orders set updated_at = now(), status = case
+    			when exists (select 1 from purchase_order_products where purchase_order_id = $1
+    			                 and deleted_at is null and received_count < count) then 'partially_received'
+    			else 'received' end::purchase_order_status_enum
+				where id = $1`, request.ID); err != nil {
+		fmt.Println("error is while updating purchase order status", err.Error())
+		return "", err
+	}
+
+	return incomeID.String(), nil
+}
+
+// GetDiscrepancies compares ordered lines with everything received by the incomes of the order.
+// Only lines where the count or the unit price differ are returned.
+func (p *purchaseOrderRepo) GetDiscrepancies(ctx context.Context, id string) (models.PurchaseOrderDiscrepancies, error) {
+	result := models.PurchaseOrderDiscrepancies{
+		PurchaseOrderID: id,
+		Discrepancies:   []models.PurchaseOrderDiscrepancy{},
+	}
+
+	if err := p.db.QueryRow(ctx, `select status from purchase_orders where id = $1 and deleted_at is null`, id).Scan(
+		&result.Status,
+	); err != nil {
+		fmt.Println("error is while selecting purchase order status", err.Error())
+		return models.PurchaseOrderDiscrepancies{}, err
+	}
+
+	query := `with ordered as (
+					select product_id, sum(count) as count, max(price) as price from purchase_order_products
+						where purchase_order_id = $1 and deleted_at is null group by product_id
+				), received as (
+					select ip.product_id, sum(ip.count * ip.unit_quantity) as count, sum(round(ip.price * ip.count, 2)) as amount
+						from income_products ip
+							join incomes i on i.id = ip.income_id
+								where i.purchase_order_id = $1 and i.deleted_at is null and ip.deleted_at is null
+									group by ip.product_id
+				)
+				select coalesce(o.product_id, r.product_id), coalesce(o.count, 0), coalesce(r.count, 0),
+				       coalesce(o.price, 0), coalesce(r.amount, 0)
+					from ordered o full join received r on r.product_id = o.product_id`
+
+	rows, err := p.db.Query(ctx, query, id)
+	if err != nil {
+		fmt.Println("error is while selecting purchase order discrepancies", err.Error())
+		return models.PurchaseOrderDiscrepancies{}, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			line   = models.PurchaseOrderDiscrepancy{}
+			amount money.Money
+		)
+		if err := rows.Scan(
+			&line.ProductID,
+			&line.OrderedCount,
+			&line.ReceivedCount,
+			&line.ExpectedPrice,
+			&amount,
+		); err != nil {
+			fmt.Println("error is while scanning purchase order discrepancies", err.Error())
+			return models.PurchaseOrderDiscrepancies{}, err
+		}
+
+		if line.ReceivedPrice, err = amount.Div(line.ReceivedCount); err != nil {
+			return models.PurchaseOrderDiscrepancies{}, err
+		}
+		line.CountDiff = roundQuantity(line.ReceivedCount - line.OrderedCount)
+		if line.ReceivedCount != 0 && line.OrderedCount != 0 {
+			line.PriceDiff = line.ReceivedPrice - line.ExpectedPrice
+		}
+
+		if line.CountDiff == 0 && line.PriceDiff == 0 {
+			continue
+		}
+		result.Discrepancies = append(result.Discrepancies, line)
+	}
+	result.Count = len(result.Discrepancies)
+
+	return result, nil
+}
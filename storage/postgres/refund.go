@@ -0,0 +1,328 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"sell/api/models"
+	"sell/pkg/money"
+	"sell/storage"
+)
+
+type refundRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewRefundRepo(db *pgxpool.Pool) storage.IRefundStorage {
+	return &refundRepo{db: db}
+}
+
+// refundLine is a basket line of the sale with the part of it which was already refunded.
+type refundLine struct {
+	basket                           models.Basket
+	taxRate, taxAmount               money.Money
+	refunded                         float64
+	refundedPrice, refundedTaxAmount money.Money
+}
+
+// Create gives back request.Products of a successful sale, every line which is left when no products are given.
+// The goods go back to the branch repository and the refund is put into the fiscal outbox in the same transaction.
+// The last part of a line takes what is left of its price and tax, so a line refunded in parts sums up to the line.
+func (r *refundRepo) Create(ctx context.Context, request models.CreateRefund) (_ string, err error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		fmt.Println("error is while beginning transaction", err.Error())
+		return "", err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+			return
+		}
+		err = tx.Commit(ctx)
+	}()
+
+	var (
+		branchID, status string
+		taxIncluded      bool
+	)
+	if err = tx.QueryRow(ctx, `select coalesce(branch_id::text, ''), coalesce(status::text, ''), tax_included from sales
+                                 where id = $1 and deleted_at is null for update`, request.SaleID).Scan(
+		&branchID,
+		&status,
+		&taxIncluded,
+	); err != nil {
+		fmt.Println("error is while selecting sale for update", err.Error())
+		return "", err
+	}
+
+	if status != "success" {
+		err = storage.ErrSaleNotRefundable
+		return "", err
+	}
+
+	lines, err := refundLines(ctx, tx, request.SaleID)
+	if err != nil {
+		return "", err
+	}
+
+	products := request.Products
+	if len(products) == 0 {
+		for _, line := range lines {
+			if left := roundQuantity(line.basket.Quantity - line.refunded); left > 0 {
+				products = append(products, models.CreateRefundProduct{BasketID: line.basket.ID, Quantity: left})
+			}
+		}
+	}
+	if len(products) == 0 {
+		err = storage.ErrRefundQuantity
+		return "", err
+	}
+
+	id := uuid.New()
+	if _, err = tx.Exec(ctx, `insert into refunds (id, sale_id, reason) values($1, $2, nullif($3, ''))`,
+		id, request.SaleID, request.Reason); err != nil {
+		fmt.Println("error is while inserting refund", err.Error())
+		return "", err
+	}
+
+	var price, taxAmount money.Money
+	for _, product := range products {
+		line, ok := lines[product.BasketID]
+		if !ok {
+			err = pgx.ErrNoRows
+			return "", err
+		}
+
+		quantity := roundQuantity(product.Quantity)
+		left := roundQuantity(line.basket.Quantity - line.refunded)
+		if quantity <= 0 || quantity > left {
+			err = storage.ErrRefundQuantity
+			return "", err
+		}
+
+		var linePrice, lineTax money.Money
+		if linePrice, err = line.basket.Price.Share(quantity, line.basket.Quantity); err != nil {
+			return "", err
+		}
+		if lineTax, err = line.taxAmount.Share(quantity, line.basket.Quantity); err != nil {
+			return "", err
+		}
+		if quantity == left {
+			linePrice = line.basket.Price - line.refundedPrice
+			lineTax = line.taxAmount - line.refundedTaxAmount
+		}
+
+		if _, err = tx.Exec(ctx, `insert into refund_products (id, refund_id, basket_id, product_id, quantity, price, tax_rate, tax_amount)
+						values($1, $2, $3, $4, $5, $6, $7, $8)`,
+			uuid.New(),
+			id,
+			line.basket.ID,
+			line.basket.ProductID,
+			quantity,
+			linePrice,
+			line.taxRate,
+			lineTax,
+		); err != nil {
+			fmt.Println("error is while inserting refund product", err.Error())
+			return "", err
+		}
+
+		if err = returnRefundStock(ctx, tx, branchID, id.String(), line.basket.ProductID, quantity, linePrice); err != nil {
+			return "", err
+		}
+
+		line.refunded = roundQuantity(line.refunded + quantity)
+		line.refundedPrice += linePrice
+		line.refundedTaxAmount += lineTax
+		lines[product.BasketID] = line
+
+		price += linePrice
+		if !taxIncluded {
+			price += lineTax
+		}
+		taxAmount += lineTax
+	}
+
+	if _, err = tx.Exec(ctx, `update refunds set price = $1, tax_amount = $2, updated_at = now() where id = $3`,
+		price, taxAmount, id); err != nil {
+		fmt.Println("error is while updating refund price", err.Error())
+		return "", err
+	}
+
+	if _, err = tx.Exec(ctx, `insert into fiscal_outbox (id, document_type, document_id) values($1, 'refund', $2)`,
+		uuid.New(), id); err != nil {
+		fmt.Println("error is while inserting fiscal outbox entry", err.Error())
+		return "", err
+	}
+
+	return id.String(), nil
+}
+
+// refundLines returns the basket lines of the sale by id with what was refunded of each of them.
+func refundLines(ctx context.Context, tx pgx.Tx, saleID string) (map[string]refundLine, error) {
+	rows, err := tx.Query(ctx, `select b.id, b.sale_id, b.product_id, b.quantity, b.price, b.tax_rate, b.tax_amount,
+       					coalesce(rp.quantity, 0), coalesce(rp.price, 0), coalesce(rp.tax_amount, 0)
+						from baskets b left join lateral (
+						    select sum(rp.quantity) as quantity, sum(rp.price) as price, sum(rp.tax_amount) as tax_amount
+								from refund_products rp join refunds r on r.id = rp.refund_id and r.deleted_at is null
+									where rp.basket_id = b.id and rp.deleted_at is null
+						) rp on true where b.sale_id = $1 and b.deleted_at is null`, saleID)
+	if err != nil {
+		fmt.Println("error is while selecting refund lines", err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	lines := map[string]refundLine{}
+	for rows.Next() {
+		line := refundLine{}
+		if err := rows.Scan(
+			&line.basket.ID,
+			&line.basket.SaleID,
+			&line.basket.ProductID,
+			&line.basket.Quantity,
+			&line.basket.Price,
+			&line.taxRate,
+			&line.taxAmount,
+			&line.refunded,
+			&line.refundedPrice,
+			&line.refundedTaxAmount,
+		); err != nil {
+			fmt.Println("error is while scanning refund lines", err.Error())
+			return nil, err
+		}
+		lines[line.basket.ID] = line
+	}
+
+	return lines, rows.Err()
+}
+
+// returnRefundStock puts refunded goods back into the branch repository and records the plus movement of the refund.
+func returnRefundStock(ctx context.Context, tx pgx.Tx, branchID, refundID, productID string, quantity float64, price money.Money) error {
+	if err := addRepositoryCount(ctx, tx, branchID, productID, quantity); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(ctx, `insert into repository_transactions
+    				(id, branch_id, product_id, repository_transaction_type, source_type, source_id, price, quantity)
+						values($1, $2, $3, 'plus', 'refund', $4, $5, $6)`,
+		uuid.New(),
+		branchID,
+		productID,
+		refundID,
+		price,
+		quantity,
+	); err != nil {
+		fmt.Println("error is while inserting repository transaction", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (r *refundRepo) GetByID(ctx context.Context, id string) (models.Refund, error) {
+	refund := models.Refund{Products: []models.RefundProduct{}}
+	if err := r.db.QueryRow(ctx, `select id, sale_id, coalesce(reason, ''), price, tax_amount,
+       coalesce(fiscal_sign, ''), coalesce(fiscal_number, ''), fiscalized_at, created_at
+			from refunds where id = $1 and deleted_at is null`, id).Scan(
+		&refund.ID,
+		&refund.SaleID,
+		&refund.Reason,
+		&refund.Price,
+		&refund.TaxAmount,
+		&refund.FiscalSign,
+		&refund.FiscalNumber,
+		&refund.FiscalizedAt,
+		&refund.CreatedAt,
+	); err != nil {
+		fmt.Println("error is while selecting refund by id", err.Error())
+		return models.Refund{}, err
+	}
+
+	rows, err := r.db.Query(ctx, `select id, refund_id, basket_id, coalesce(product_id::text, ''), quantity, price, tax_rate, tax_amount
+						from refund_products where refund_id = $1 and deleted_at is null order by created_at`, id)
+	if err != nil {
+		fmt.Println("error is while selecting refund products", err.Error())
+		return models.Refund{}, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		product := models.RefundProduct{}
+		if err := rows.Scan(
+			&product.ID,
+			&product.RefundID,
+			&product.BasketID,
+			&product.ProductID,
+			&product.Quantity,
+			&product.Price,
+			&product.TaxRate,
+			&product.TaxAmount,
+		); err != nil {
+			fmt.Println("error is while scanning refund products", err.Error())
+			return models.Refund{}, err
+		}
+		refund.Products = append(refund.Products, product)
+	}
+
+	return refund, nil
+}
+
+// GetList returns refunds without their products, latest first, of one sale when request.SaleID is given.
+func (r *refundRepo) GetList(ctx context.Context, request models.RefundGetListRequest) (models.RefundsResponse, error) {
+	var (
+		refunds = []models.Refund{}
+		count   int
+		args    = []interface{}{}
+		filter  string
+	)
+
+	if request.SaleID != "" {
+		args = append(args, request.SaleID)
+		filter += fmt.Sprintf(` and sale_id = $%d`, len(args))
+	}
+
+	if err := r.db.QueryRow(ctx, `select count(1) from refunds where deleted_at is null `+filter, args...).Scan(&count); err != nil {
+		fmt.Println("error is while selecting count of refunds", err.Error())
+		return models.RefundsResponse{}, err
+	}
+
+	query := `select id, sale_id, coalesce(reason, ''), price, tax_amount,
+       coalesce(fiscal_sign, ''), coalesce(fiscal_number, ''), fiscalized_at, created_at
+			from refunds where deleted_at is null ` + filter +
+		fmt.Sprintf(` order by created_at desc LIMIT $%d OFFSET $%d`, len(args)+1, len(args)+2)
+
+	rows, err := r.db.Query(ctx, query, append(args, request.Limit, (request.Page-1)*request.Limit)...)
+	if err != nil {
+		fmt.Println("error is while selecting refunds", err.Error())
+		return models.RefundsResponse{}, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		refund := models.Refund{}
+		if err := rows.Scan(
+			&refund.ID,
+			&refund.SaleID,
+			&refund.Reason,
+			&refund.Price,
+			&refund.TaxAmount,
+			&refund.FiscalSign,
+			&refund.FiscalNumber,
+			&refund.FiscalizedAt,
+			&refund.CreatedAt,
+		); err != nil {
+			fmt.Println("error is while scanning refunds", err.Error())
+			return models.RefundsResponse{}, err
+		}
+		refunds = append(refunds, refund)
+	}
+
+	return models.RefundsResponse{
+		Refunds: refunds,
+		Count:   count,
+	}, nil
+}
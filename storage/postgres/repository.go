@@ -2,10 +2,14 @@ package postgres
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"log"
+	"math"
 	"sell/api/models"
 	"sell/storage"
 )
@@ -20,18 +24,39 @@ func NewRepositoryRepo(DB *pgxpool.Pool) storage.IRepositoryRepo {
 	}
 }
 
-func (s *repositoryRepo) Create(ctx context.Context, repository models.CreateRepository) (string, error) {
+// Create adds a repository row, the initial count is recorded as an adjustment movement.
+func (s *repositoryRepo) Create(ctx context.Context, repository models.CreateRepository) (_ string, err error) {
 	id := uuid.New()
 
-	if _, err := s.DB.Exec(ctx, `INSERT INTO repositories 
-    (id, product_id, branch_id, count) 
-        VALUES ($1, $2, $3, $4)`,
+	tx, err := s.DB.Begin(ctx)
+	if err != nil {
+		log.Println("Error while beginning transaction:", err)
+		return "", err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+			return
+		}
+		err = tx.Commit(ctx)
+	}()
+
+	if _, err = tx.Exec(ctx, `INSERT INTO repositories 
+    (id, product_id, branch_id, count, min_count, target_count) 
+        VALUES ($1, $2, $3, $4, $5, $6)`,
 		id,
 		repository.ProductID,
 		repository.BranchID,
 		repository.Count,
+		repository.MinCount,
+		repository.TargetCount,
 	); err != nil {
 		log.Println("Error while inserting data:", err)
+		err = repositoryError(err)
+		return "", err
+	}
+
+	if err = postStockMovement(ctx, tx, repository.BranchID, repository.ProductID, id.String(), "adjustment", repository.Count); err != nil {
 		return "", err
 	}
 
@@ -40,7 +65,7 @@ func (s *repositoryRepo) Create(ctx context.Context, repository models.CreateRep
 
 func (s *repositoryRepo) GetByID(ctx context.Context, id models.PrimaryKey) (models.Repository, error) {
 	repository := models.Repository{}
-	query := `SELECT id, product_id, branch_id, count, created_at, updated_at 
+	query := `SELECT id, product_id, branch_id, count, min_count, target_count, created_at, updated_at 
 							FROM repositories WHERE id = $1 and deleted_at is null
 `
 	err := s.DB.QueryRow(ctx, query, id.ID).Scan(
@@ -48,6 +73,8 @@ func (s *repositoryRepo) GetByID(ctx context.Context, id models.PrimaryKey) (mod
 		&repository.ProductID,
 		&repository.BranchID,
 		&repository.Count,
+		&repository.MinCount,
+		&repository.TargetCount,
 		&repository.CreatedAt,
 		&repository.UpdatedAt,
 	)
@@ -75,7 +102,7 @@ func (s *repositoryRepo) GetList(ctx context.Context, request models.GetListRequ
 		return models.RepositoriesResponse{}, err
 	}
 
-	query := `SELECT id, product_id, branch_id, count, created_at, updated_at FROM repositories where deleted_at is null`
+	query := `SELECT id, product_id, branch_id, count, min_count, target_count, created_at, updated_at FROM repositories where deleted_at is null`
 	if request.Search != "" {
 		query += fmt.Sprintf(` and product_id = '%s'`, request.Search)
 	}
@@ -95,6 +122,8 @@ func (s *repositoryRepo) GetList(ctx context.Context, request models.GetListRequ
 			&repository.ProductID,
 			&repository.BranchID,
 			&repository.Count,
+			&repository.MinCount,
+			&repository.TargetCount,
 			&repository.CreatedAt,
 			&repository.UpdatedAt,
 		)
@@ -122,20 +151,411 @@ func (s *repositoryRepo) Update(ctx context.Context, repository models.UpdateRep
 	)
 	if err != nil {
 		log.Println("Error while updating Repository :", err)
+		return "", repositoryError(err)
+	}
+
+	return repository.ID, nil
+}
+
+// Adjust is a manual edit of a repository row. The change of the count is recorded as an adjustment
+// movement, so the movement ledger keeps matching repository counts.
+func (s *repositoryRepo) Adjust(ctx context.Context, repository models.UpdateRepository) (_ string, err error) {
+	tx, err := s.DB.Begin(ctx)
+	if err != nil {
+		log.Println("Error while beginning transaction:", err)
+		return "", err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+			return
+		}
+		err = tx.Commit(ctx)
+	}()
+
+	old := models.Repository{}
+	if err = tx.QueryRow(ctx, `SELECT branch_id, product_id, count FROM repositories 
+                                    WHERE id = $1 and deleted_at is null FOR UPDATE`, repository.ID).Scan(
+		&old.BranchID,
+		&old.ProductID,
+		&old.Count,
+	); err != nil {
+		log.Println("Error while selecting repository for update:", err)
+		return "", err
+	}
+
+	if _, err = tx.Exec(ctx, `UPDATE repositories SET branch_id = $1, product_id = $2, count = $3, updated_at = NOW() WHERE id = $4`,
+		repository.BranchID,
+		repository.ProductID,
+		repository.Count,
+		repository.ID,
+	); err != nil {
+		log.Println("Error while updating Repository :", err)
+		err = repositoryError(err)
+		return "", err
+	}
+
+	if old.BranchID == repository.BranchID && old.ProductID == repository.ProductID {
+		if err = postStockMovement(ctx, tx, repository.BranchID, repository.ProductID, repository.ID, "adjustment", repository.Count-old.Count); err != nil {
+			return "", err
+		}
+		return repository.ID, nil
+	}
+
+	// the row was moved to another branch or product: take the old count out and put the new one in
+	if err = postStockMovement(ctx, tx, old.BranchID, old.ProductID, repository.ID, "adjustment", -old.Count); err != nil {
+		return "", err
+	}
+	if err = postStockMovement(ctx, tx, repository.BranchID, repository.ProductID, repository.ID, "adjustment", repository.Count); err != nil {
 		return "", err
 	}
 
 	return repository.ID, nil
 }
 
-func (s *repositoryRepo) Delete(ctx context.Context, id string) error {
-	query := `UPDATE repositories SET deleted_at = NOW() WHERE id = $1`
+func (s *repositoryRepo) UpdateLevels(ctx context.Context, request models.UpdateRepositoryLevels) (string, error) {
+	query := `UPDATE repositories SET min_count = $1, target_count = $2, updated_at = NOW() WHERE id = $3 and deleted_at is null`
+
+	_, err := s.DB.Exec(ctx, query,
+		&request.MinCount,
+		&request.TargetCount,
+		&request.ID,
+	)
+	if err != nil {
+		log.Println("Error while updating Repository levels :", err)
+		return "", err
+	}
+
+	return request.ID, nil
+}
 
-	_, err := s.DB.Exec(ctx, query, id)
+// Delete soft deletes a repository row. What is left in it is taken out with an adjustment movement,
+// so the movement ledger keeps matching repository counts.
+func (s *repositoryRepo) Delete(ctx context.Context, id string) (err error) {
+	tx, err := s.DB.Begin(ctx)
 	if err != nil {
+		log.Println("Error while beginning transaction:", err)
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+			return
+		}
+		err = tx.Commit(ctx)
+	}()
+
+	old := models.Repository{}
+	if err = tx.QueryRow(ctx, `UPDATE repositories SET deleted_at = NOW() WHERE id = $1 and deleted_at is null
+                                    RETURNING branch_id, product_id, count`, id).Scan(
+		&old.BranchID,
+		&old.ProductID,
+		&old.Count,
+	); err != nil {
 		log.Println("Error while deleting Repository :", err)
 		return err
 	}
 
+	return postStockMovement(ctx, tx, old.BranchID, old.ProductID, id, "adjustment", -old.Count)
+}
+
+func (s *repositoryRepo) GetLowStock(ctx context.Context, request models.StockLevelRequest) (models.LowStockResponse, error) {
+	var (
+		items  = []models.LowStockItem{}
+		filter string
+		args   []interface{}
+	)
+
+	if request.BranchID != "" {
+		args = append(args, request.BranchID)
+		filter += fmt.Sprintf(` and r.branch_id = $%d`, len(args))
+	}
+
+	query := `SELECT r.id, r.branch_id, r.product_id, p.name, r.count, r.min_count, r.target_count
+				FROM repositories r
+					JOIN products p on p.id = r.product_id
+						WHERE r.deleted_at is null and r.count < r.min_count ` + filter + `
+							ORDER BY r.count - r.min_count, p.name`
+
+	rows, err := s.DB.Query(ctx, query, args...)
+	if err != nil {
+		log.Println("Error while querying low stock repositories:", err)
+		return models.LowStockResponse{}, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		item := models.LowStockItem{}
+		if err := rows.Scan(
+			&item.RepositoryID,
+			&item.BranchID,
+			&item.ProductID,
+			&item.ProductName,
+			&item.Count,
+			&item.MinCount,
+			&item.TargetCount,
+		); err != nil {
+			log.Println("Error while scanning low stock repositories:", err)
+			return models.LowStockResponse{}, err
+		}
+		items = append(items, item)
+	}
+
+	return models.LowStockResponse{
+		Items: items,
+		Count: len(items),
+	}, nil
+}
+
+// GetReorderSuggestions proposes quantities for products that are below their minimum now
+// or will be after request.CoverDays of selling at the average daily pace of the last request.Days.
+// The suggestion refills the repository up to the target count, or further if the expected demand
+// for the cover period plus the minimum count needs more.
+func (s *repositoryRepo) GetReorderSuggestions(ctx context.Context, request models.StockLevelRequest) (models.ReorderSuggestionsResponse, error) {
+	var (
+		suggestions = []models.ReorderSuggestion{}
+		args        = []interface{}{request.Days}
+		filter      string
+	)
+
+	if request.BranchID != "" {
+		args = append(args, request.BranchID)
+		filter += fmt.Sprintf(` and r.branch_id = $%d`, len(args))
+	}
+
+	query := `WITH sold AS (
+					SELECT s.branch_id, b.product_id, sum(b.quantity) as quantity
+						FROM baskets b
+							JOIN sales s on s.id = b.sale_id
+								WHERE b.deleted_at is null and s.deleted_at is null and s.status = 'success'
+									and s.created_at >= NOW() - make_interval(days => $1::int)
+										GROUP BY s.branch_id, b.product_id
+				)
+				SELECT r.id, r.branch_id, r.product_id, p.name, r.count, r.min_count, r.target_count,
+				       coalesce(sold.quantity, 0)::float8 / $1::int
+					FROM repositories r
+						JOIN products p on p.id = r.product_id
+						LEFT JOIN sold on sold.branch_id = r.branch_id and sold.product_id = r.product_id
+							WHERE r.deleted_at is null and (r.min_count > 0 or r.target_count > 0 or sold.quantity > 0) ` + filter + `
+								ORDER BY r.count - r.min_count, p.name`
+
+	rows, err := s.DB.Query(ctx, query, args...)
+	if err != nil {
+		log.Println("Error while querying reorder suggestions:", err)
+		return models.ReorderSuggestionsResponse{}, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		suggestion := models.ReorderSuggestion{}
+		if err := rows.Scan(
+			&suggestion.RepositoryID,
+			&suggestion.BranchID,
+			&suggestion.ProductID,
+			&suggestion.ProductName,
+			&suggestion.Count,
+			&suggestion.MinCount,
+			&suggestion.TargetCount,
+			&suggestion.AverageDailySales,
+		); err != nil {
+			log.Println("Error while scanning reorder suggestions:", err)
+			return models.ReorderSuggestionsResponse{}, err
+		}
+
+		demand := math.Ceil(suggestion.AverageDailySales * float64(request.CoverDays))
+		if suggestion.Count-demand >= suggestion.MinCount {
+			continue
+		}
+
+		need := suggestion.TargetCount
+		if demand+suggestion.MinCount > need {
+			need = demand + suggestion.MinCount
+		}
+
+		suggestion.SuggestedQuantity = need - suggestion.Count
+		if suggestion.SuggestedQuantity <= 0 {
+			continue
+		}
+
+		suggestions = append(suggestions, suggestion)
+	}
+
+	return models.ReorderSuggestionsResponse{
+		Days:        request.Days,
+		CoverDays:   request.CoverDays,
+		Suggestions: suggestions,
+		Count:       len(suggestions),
+	}, nil
+}
+
+// Reconcile compares repository counts with the counts expected from the movement ledger
+// (plus movements minus minus movements per branch and product). Mode ledger trusts the counts and
+// posts a reconciliation movement for every discrepancy, so the ledger matches the counts; mode counts
+// trusts the ledger and sets the counts to what it expects. Without a mode nothing is changed.
+// Movements without a branch can not be attributed and are only counted.
+func (s *repositoryRepo) Reconcile(ctx context.Context, request models.ReconcileRequest) (_ models.ReconcileResponse, err error) {
+	var (
+		response = models.ReconcileResponse{Discrepancies: []models.StockDiscrepancy{}}
+		args     []interface{}
+		filter   string
+	)
+
+	if request.BranchID != "" {
+		args = append(args, request.BranchID)
+		filter = fmt.Sprintf(` and branch_id = $%d`, len(args))
+	}
+
+	tx, err := s.DB.Begin(ctx)
+	if err != nil {
+		log.Println("Error while beginning transaction:", err)
+		return models.ReconcileResponse{}, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+			return
+		}
+		err = tx.Commit(ctx)
+	}()
+
+	if request.Mode != "" {
+		// nothing may move stock while the correcting movements are calculated
+		if _, err = tx.Exec(ctx, `LOCK TABLE repositories, repository_transactions IN SHARE ROW EXCLUSIVE MODE`); err != nil {
+			log.Println("Error while locking repositories:", err)
+			return models.ReconcileResponse{}, err
+		}
+	}
+
+	if err = tx.QueryRow(ctx, `SELECT count(1) FROM repository_transactions 
+                                  WHERE deleted_at is null and branch_id is null`).Scan(&response.UnassignedMovements); err != nil {
+		log.Println("Error while counting unassigned movements:", err)
+		return models.ReconcileResponse{}, err
+	}
+
+	query := `WITH counts AS (
+					SELECT branch_id, product_id, sum(count) as count FROM repositories
+						WHERE deleted_at is null ` + filter + `
+							GROUP BY branch_id, product_id
+				), ledger AS (
+					SELECT branch_id, product_id,
+					       sum(CASE WHEN repository_transaction_type = 'plus' THEN quantity ELSE -quantity END) as count
+						FROM repository_transactions
+							WHERE deleted_at is null and branch_id is not null ` + filter + `
+								GROUP BY branch_id, product_id
+				)
+				SELECT coalesce(c.branch_id, l.branch_id), coalesce(c.product_id, l.product_id),
+				       coalesce(c.count, 0), coalesce(l.count, 0), coalesce(c.count, 0) - coalesce(l.count, 0)
+					FROM counts c
+						FULL JOIN ledger l on l.branch_id = c.branch_id and l.product_id = c.product_id
+							WHERE coalesce(c.count, 0) <> coalesce(l.count, 0)
+								ORDER BY 1, 2`
+
+	rows, err := tx.Query(ctx, query, args...)
+	if err != nil {
+		log.Println("Error while querying stock discrepancies:", err)
+		return models.ReconcileResponse{}, err
+	}
+
+	for rows.Next() {
+		discrepancy := models.StockDiscrepancy{}
+		if err = rows.Scan(
+			&discrepancy.BranchID,
+			&discrepancy.ProductID,
+			&discrepancy.Count,
+			&discrepancy.ExpectedCount,
+			&discrepancy.Difference,
+		); err != nil {
+			rows.Close()
+			log.Println("Error while scanning stock discrepancies:", err)
+			return models.ReconcileResponse{}, err
+		}
+		response.Discrepancies = append(response.Discrepancies, discrepancy)
+	}
+	rows.Close()
+	response.Count = len(response.Discrepancies)
+
+	switch request.Mode {
+	case "ledger":
+		for _, discrepancy := range response.Discrepancies {
+			if err = postStockMovement(ctx, tx, discrepancy.BranchID, discrepancy.ProductID, "", "reconciliation", discrepancy.Difference); err != nil {
+				return models.ReconcileResponse{}, err
+			}
+		}
+	case "counts":
+		// a branch without a repository of the product gets one
+		for _, discrepancy := range response.Discrepancies {
+			if err = addRepositoryCount(ctx, tx, discrepancy.BranchID, discrepancy.ProductID, -discrepancy.Difference); err != nil {
+				return models.ReconcileResponse{}, err
+			}
+		}
+	default:
+		return response, nil
+	}
+	response.Fixed = true
+
+	return response, nil
+}
+
+// postStockMovement records an adjustment of stock with no money value: a positive quantity
+// as a plus movement and a negative one as a minus movement. sourceID is the adjusted repository, if any.
+func postStockMovement(ctx context.Context, tx pgx.Tx, branchID, productID, sourceID, reason string, quantity float64) error {
+	quantity = roundQuantity(quantity)
+	if quantity == 0 {
+		return nil
+	}
+
+	transactionType := "plus"
+	if quantity < 0 {
+		transactionType = "minus"
+		quantity = -quantity
+	}
+
+	if _, err := tx.Exec(ctx, `INSERT INTO repository_transactions 
+    	(id, branch_id, product_id, repository_transaction_type, reason, source_type, source_id, price, quantity)
+    		VALUES ($1, $2, $3, $4, $5, 'adjustment', nullif($6, '')::uuid, 0, $7)`,
+		uuid.New(),
+		branchID,
+		productID,
+		transactionType,
+		reason,
+		sourceID,
+		quantity,
+	); err != nil {
+		log.Println("Error while inserting stock movement:", err)
+		return err
+	}
+
 	return nil
 }
+
+// addRepositoryCount adds quantity to the repository of the product in the branch, the repository is created
+// when the branch has none. The unique index keeps one row when two documents add the same product at once.
+func addRepositoryCount(ctx context.Context, tx pgx.Tx, branchID, productID string, quantity float64) error {
+	if _, err := tx.Exec(ctx, `insert into repositories (id, product_id, branch_id, count) values($1, $2, $3, $4)
+					on conflict (branch_id, product_id) where deleted_at is null
+					    do update set count = repositories.count + excluded.count, updated_at = now()`,
+		uuid.New(),
+		productID,
+		branchID,
+		quantity,
+	); err != nil {
+		fmt.Println("error is while adding repository count", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// repositoryError turns the violation of the one repository of a product in a branch into ErrRepositoryExists.
+func repositoryError(err error) error {
+	if pgErr := (&pgconn.PgError{}); errors.As(err, &pgErr) && pgErr.ConstraintName == "repositories_branch_id_product_id_idx" {
+		return storage.ErrRepositoryExists
+	}
+	return err
+}
+
+// roundQuantity rounds a quantity to the 3 decimals stock is stored with,
+// so float arithmetic leftovers never reach the database.
+func roundQuantity(quantity float64) float64 {
+	return math.Round(quantity*1000) / 1000
+}
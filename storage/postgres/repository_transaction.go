@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"log"
 	"sell/api/models"
@@ -20,37 +22,57 @@ func NewRepositoryTransactionRepo(DB *pgxpool.Pool) storage.IRepositoryTransacti
 	}
 }
 
+// execer is the pool or a transaction.
+type execer interface {
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+}
+
+// Create posts a movement by hand. It changes the ledger only, the repository counts stay and reconcile shows the difference.
 func (s *repositoryTransactionRepo) Create(ctx context.Context, rtransaction models.CreateRepositoryTransaction) (string, error) {
 	id := uuid.New().String()
-	fmt.Println("id", id)
-	fmt.Println("prod id", rtransaction.ProductID)
+	if err := insertMovement(ctx, s.DB, id, rtransaction); err != nil {
+		return "", err
+	}
 
-	if _, err := s.DB.Exec(ctx, `INSERT INTO repository_transactions
-		(id, product_id, repository_transaction_type, price, quantity)
-			VALUES($1, $2, $3, $4, $5)`,
+	return id, nil
+}
+
+func insertMovement(ctx context.Context, db execer, id string, rtransaction models.CreateRepositoryTransaction) error {
+	if _, err := db.Exec(ctx, `INSERT INTO repository_transactions
+		(id, branch_id, product_id, repository_transaction_type, reason, source_type, source_id, price, quantity)
+			VALUES($1, nullif($2, '')::uuid, $3, $4, nullif($5, ''), nullif($6, '')::movement_source_type_enum, nullif($7, '')::uuid, $8, $9)`,
 		id,
+		rtransaction.BranchID,
 		rtransaction.ProductID,
 		rtransaction.RepositoryTransactionType,
+		rtransaction.Reason,
+		rtransaction.SourceType,
+		rtransaction.SourceID,
 		rtransaction.Price,
 		rtransaction.Quantity,
 	); err != nil {
 		log.Println("Error while inserting data:", err)
-		return "", err
+		return err
 	}
 
-	return id, nil
+	return nil
 }
 
 func (s *repositoryTransactionRepo) GetByID(ctx context.Context, id models.PrimaryKey) (models.RepositoryTransaction, error) {
 	rtransaction := models.RepositoryTransaction{}
-	query := `SELECT id, product_id, repository_transaction_type, price, quantity, created_at, updated_at 
+	query := `SELECT id, coalesce(branch_id::text, ''), product_id, repository_transaction_type, coalesce(reason, ''), 
+       coalesce(source_type::text, ''), coalesce(source_id::text, ''), price, quantity, created_at, updated_at 
 							FROM repository_transactions WHERE id = $1 and deleted_at is null
 `
 
 	err := s.DB.QueryRow(ctx, query, id.ID).Scan(
 		&rtransaction.ID,
+		&rtransaction.BranchID,
 		&rtransaction.ProductID,
 		&rtransaction.RepositoryTransactionType,
+		&rtransaction.Reason,
+		&rtransaction.SourceType,
+		&rtransaction.SourceID,
 		&rtransaction.Price,
 		&rtransaction.Quantity,
 		&rtransaction.CreatedAt,
@@ -64,36 +86,47 @@ func (s *repositoryTransactionRepo) GetByID(ctx context.Context, id models.Prima
 	return rtransaction, nil
 }
 
-func (s *repositoryTransactionRepo) GetList(ctx context.Context, req models.GetListRequest) (models.RepositoryTransactionsResponse, error) {
+func (s *repositoryTransactionRepo) GetList(ctx context.Context, req models.RepositoryTransactionGetListRequest) (models.RepositoryTransactionsResponse, error) {
 	var (
 		rtransactions []models.RepositoryTransaction
 		count         int
+		filter        string
+		args          []interface{}
 	)
 
-	countQuery := `SELECT COUNT(*) FROM repository_transactions where deleted_at is null `
-	if req.Search != "" {
-		fmt.Println("req.search", req.Search)
-		countQuery += fmt.Sprintf(` and product_id = '%s'`, req.Search)
-		fmt.Println("count query", countQuery)
+	if req.ProductID != "" {
+		args = append(args, req.ProductID)
+		filter += fmt.Sprintf(` and product_id = $%d`, len(args))
 	}
 
-	err := s.DB.QueryRow(ctx, countQuery).Scan(&count)
+	if req.BranchID != "" {
+		args = append(args, req.BranchID)
+		filter += fmt.Sprintf(` and branch_id = $%d`, len(args))
+	}
+
+	if req.SourceType != "" {
+		args = append(args, req.SourceType)
+		filter += fmt.Sprintf(` and source_type = $%d`, len(args))
+	}
+
+	if req.SourceID != "" {
+		args = append(args, req.SourceID)
+		filter += fmt.Sprintf(` and source_id = $%d`, len(args))
+	}
+
+	countQuery := `SELECT COUNT(*) FROM repository_transactions where deleted_at is null ` + filter
+	err := s.DB.QueryRow(ctx, countQuery, args...).Scan(&count)
 	if err != nil {
 		log.Println("Error while scanning count of repository_transactions:", err)
 		return models.RepositoryTransactionsResponse{}, err
 	}
 
-	query := `SELECT id, product_id, repository_transaction_type, price, quantity, created_at, updated_at 
-							FROM repository_transactions where deleted_at is null
-`
-	if req.Search != "" {
-		query += fmt.Sprintf(` and product_id = '%s'`, req.Search)
-	}
-	query += ` order by created_at desc LIMIT $1 OFFSET $2 `
-
-	fmt.Println("query", query)
+	query := `SELECT id, coalesce(branch_id::text, ''), product_id, repository_transaction_type, coalesce(reason, ''), 
+       coalesce(source_type::text, ''), coalesce(source_id::text, ''), price, quantity, created_at, updated_at 
+							FROM repository_transactions where deleted_at is null ` + filter +
+		fmt.Sprintf(` order by created_at desc LIMIT $%d OFFSET $%d `, len(args)+1, len(args)+2)
 
-	rows, err := s.DB.Query(ctx, query, req.Limit, (req.Page-1)*req.Limit)
+	rows, err := s.DB.Query(ctx, query, append(args, req.Limit, (req.Page-1)*req.Limit)...)
 	if err != nil {
 		log.Println("Error while querying repository_transactions:", err)
 		return models.RepositoryTransactionsResponse{}, err
@@ -104,8 +137,12 @@ func (s *repositoryTransactionRepo) GetList(ctx context.Context, req models.GetL
 		rtransaction := models.RepositoryTransaction{}
 		err := rows.Scan(
 			&rtransaction.ID,
+			&rtransaction.BranchID,
 			&rtransaction.ProductID,
 			&rtransaction.RepositoryTransactionType,
+			&rtransaction.Reason,
+			&rtransaction.SourceType,
+			&rtransaction.SourceID,
 			&rtransaction.Price,
 			&rtransaction.Quantity,
 			&rtransaction.CreatedAt,
@@ -124,34 +161,240 @@ func (s *repositoryTransactionRepo) GetList(ctx context.Context, req models.GetL
 	}, nil
 }
 
-func (s *repositoryTransactionRepo) Update(ctx context.Context, transaction models.UpdateRepositoryTransaction) (string, error) {
-	query := `UPDATE repository_transactions SET  product_id = $1, repository_transaction_type = $2, 
-                                   price = $3, quantity = $4, updated_at = NOW() WHERE id = $5
-`
-
-	_, err := s.DB.Exec(ctx, query,
-		&transaction.ProductID,
-		&transaction.RepositoryTransactionType,
-		&transaction.Price,
-		&transaction.Quantity,
-		&transaction.ID,
-	)
+// Update corrects a movement with reversing entries: the movement is reversed and the corrected one is posted
+// as a new movement, whose id is returned. The ledger is append-only, so the history shows what was corrected.
+func (s *repositoryTransactionRepo) Update(ctx context.Context, transaction models.UpdateRepositoryTransaction) (_ string, err error) {
+	tx, err := s.DB.Begin(ctx)
 	if err != nil {
-		log.Println("Error while repository_transactions Repository :", err)
+		log.Println("Error while beginning transaction:", err)
 		return "", err
 	}
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+			return
+		}
+		err = tx.Commit(ctx)
+	}()
 
-	return transaction.ID, nil
-}
+	if err = reverseMovement(ctx, tx, transaction.ID); err != nil {
+		return "", err
+	}
 
-func (s *repositoryTransactionRepo) Delete(ctx context.Context, id string) error {
-	query := `UPDATE repository_transactions SET deleted_at = NOW() WHERE id = $1`
+	id := uuid.New().String()
+	if err = insertMovement(ctx, tx, id, models.CreateRepositoryTransaction{
+		BranchID:                  transaction.BranchID,
+		ProductID:                 transaction.ProductID,
+		RepositoryTransactionType: transaction.RepositoryTransactionType,
+		Reason:                    transaction.Reason,
+		SourceType:                transaction.SourceType,
+		SourceID:                  transaction.SourceID,
+		Price:                     transaction.Price,
+		Quantity:                  transaction.Quantity,
+	}); err != nil {
+		return "", err
+	}
+
+	return id, nil
+}
 
-	_, err := s.DB.Exec(ctx, query, id)
+// Delete cancels a movement with a reversing entry, the movement itself stays in the ledger.
+func (s *repositoryTransactionRepo) Delete(ctx context.Context, id string) (err error) {
+	tx, err := s.DB.Begin(ctx)
 	if err != nil {
-		log.Println("Error while deleting repository_transactions ", err)
+		log.Println("Error while beginning transaction:", err)
 		return err
 	}
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+			return
+		}
+		err = tx.Commit(ctx)
+	}()
 
-	return nil
+	return reverseMovement(ctx, tx, id)
+}
+
+// reverseMovement posts the opposite of the movement id, a minus for a plus and a plus for a minus of the same
+// quantity and price in the same branch. The reversal is an adjustment with the movement as its source,
+// so a movement is reversed once.
+func reverseMovement(ctx context.Context, tx pgx.Tx, id string) error {
+	movement := models.RepositoryTransaction{}
+	if err := tx.QueryRow(ctx, `SELECT coalesce(branch_id::text, ''), product_id, repository_transaction_type, price, quantity
+							FROM repository_transactions WHERE id = $1 and deleted_at is null FOR UPDATE`, id).Scan(
+		&movement.BranchID,
+		&movement.ProductID,
+		&movement.RepositoryTransactionType,
+		&movement.Price,
+		&movement.Quantity,
+	); err != nil {
+		log.Println("Error while selecting repository transaction:", err)
+		return err
+	}
+
+	var reversed bool
+	if err := tx.QueryRow(ctx, `SELECT exists(SELECT 1 FROM repository_transactions 
+                    WHERE source_type = 'adjustment' and reason = 'reversal' and source_id = $1 and deleted_at is null)`,
+		id).Scan(&reversed); err != nil {
+		log.Println("Error while checking reversal of repository transaction:", err)
+		return err
+	}
+	if reversed {
+		return storage.ErrMovementReversed
+	}
+
+	transactionType := "minus"
+	if movement.RepositoryTransactionType == "minus" {
+		transactionType = "plus"
+	}
+
+	return insertMovement(ctx, tx, uuid.New().String(), models.CreateRepositoryTransaction{
+		BranchID:                  movement.BranchID,
+		ProductID:                 movement.ProductID,
+		RepositoryTransactionType: transactionType,
+		Reason:                    "reversal",
+		SourceType:                "adjustment",
+		SourceID:                  id,
+		Price:                     movement.Price,
+		Quantity:                  movement.Quantity,
+	})
+}
+
+// History lists movements of a product in a branch between request.FromDate and request.ToDate (inclusive)
+// with the balance before the period and the running balance after every movement.
+func (s *repositoryTransactionRepo) History(ctx context.Context, request models.MovementHistoryRequest) (models.MovementHistory, error) {
+	history := models.MovementHistory{
+		ProductID: request.ProductID,
+		BranchID:  request.BranchID,
+		FromDate:  request.FromDate,
+		ToDate:    request.ToDate,
+		Movements: []models.MovementHistoryItem{},
+	}
+
+	if err := s.DB.QueryRow(ctx, `SELECT coalesce(sum(CASE WHEN repository_transaction_type = 'plus' THEN quantity ELSE -quantity END), 0)
+							FROM repository_transactions 
+								WHERE deleted_at is null and product_id = $1 and branch_id = $2 and created_at < $3::date`,
+		request.ProductID,
+		request.BranchID,
+		request.FromDate,
+	).Scan(&history.OpeningBalance); err != nil {
+		log.Println("Error while selecting opening balance:", err)
+		return models.MovementHistory{}, err
+	}
+
+	query := `SELECT id, coalesce(branch_id::text, ''), product_id, repository_transaction_type, coalesce(reason, ''), 
+       coalesce(source_type::text, ''), coalesce(source_id::text, ''), price, quantity, created_at, updated_at 
+							FROM repository_transactions 
+								WHERE deleted_at is null and product_id = $1 and branch_id = $2 
+								  and created_at >= $3::date and created_at < $4::date + 1
+									ORDER BY created_at, id`
+
+	rows, err := s.DB.Query(ctx, query, request.ProductID, request.BranchID, request.FromDate, request.ToDate)
+	if err != nil {
+		log.Println("Error while querying movement history:", err)
+		return models.MovementHistory{}, err
+	}
+	defer rows.Close()
+
+	balance := history.OpeningBalance
+	for rows.Next() {
+		item := models.MovementHistoryItem{}
+		if err := rows.Scan(
+			&item.ID,
+			&item.BranchID,
+			&item.ProductID,
+			&item.RepositoryTransactionType,
+			&item.Reason,
+			&item.SourceType,
+			&item.SourceID,
+			&item.Price,
+			&item.Quantity,
+			&item.CreatedAt,
+			&item.UpdatedAt,
+		); err != nil {
+			log.Println("Error while scanning movement history:", err)
+			return models.MovementHistory{}, err
+		}
+
+		item.Change = item.Quantity
+		if item.RepositoryTransactionType == "minus" {
+			item.Change = -item.Quantity
+		}
+		balance = roundQuantity(balance + item.Change)
+		item.Balance = balance
+
+		history.Movements = append(history.Movements, item)
+	}
+	history.ClosingBalance = balance
+
+	return history, nil
+}
+
+// Snapshot computes stock per branch and product as of request.At from the movement ledger.
+// Stock is valued at the average cost of the income movements up to that moment,
+// products which were never received are valued at the price the branch had at that moment.
+func (s *repositoryTransactionRepo) Snapshot(ctx context.Context, request models.StockSnapshotRequest) (models.StockSnapshot, error) {
+	var (
+		snapshot = models.StockSnapshot{At: request.At, Items: []models.StockSnapshotItem{}}
+		args     = []interface{}{request.At}
+		filter   string
+	)
+
+	if request.BranchID != "" {
+		args = append(args, request.BranchID)
+		filter += fmt.Sprintf(` and branch_id = $%d`, len(args))
+	}
+
+	query := `WITH ledger AS (
+					SELECT branch_id, product_id,
+					       sum(CASE WHEN repository_transaction_type = 'plus' THEN quantity ELSE -quantity END) as quantity,
+					       sum(price) FILTER (WHERE repository_transaction_type = 'plus' and source_type = 'income') as cost,
+					       sum(quantity) FILTER (WHERE repository_transaction_type = 'plus' and source_type = 'income') as cost_quantity
+						FROM repository_transactions
+							WHERE deleted_at is null and branch_id is not null and created_at <= $1::timestamp ` + filter + `
+								GROUP BY branch_id, product_id
+				)
+				SELECT l.branch_id, coalesce(b.name, ''), l.product_id, coalesce(p.name, ''), l.quantity,
+				       CASE WHEN l.cost_quantity > 0 THEN round(l.cost / l.cost_quantity, 2) ELSE ` + effectivePrice("l.branch_id", "$1::timestamp") + ` END
+					FROM ledger l
+						JOIN branches b on b.id = l.branch_id
+						JOIN products p on p.id = l.product_id
+						LEFT JOIN products pp on pp.id = p.parent_id
+							WHERE l.quantity <> 0
+								ORDER BY b.name, p.name`
+
+	rows, err := s.DB.Query(ctx, query, args...)
+	if err != nil {
+		log.Println("Error while querying stock snapshot:", err)
+		return models.StockSnapshot{}, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			item = models.StockSnapshotItem{}
+		)
+		if err := rows.Scan(
+			&item.BranchID,
+			&item.BranchName,
+			&item.ProductID,
+			&item.ProductName,
+			&item.Quantity,
+			&item.UnitCost,
+		); err != nil {
+			log.Println("Error while scanning stock snapshot:", err)
+			return models.StockSnapshot{}, err
+		}
+
+		if item.Value, err = item.UnitCost.Mul(item.Quantity); err != nil {
+			return models.StockSnapshot{}, err
+		}
+
+		snapshot.TotalQuantity = roundQuantity(snapshot.TotalQuantity + item.Quantity)
+		snapshot.TotalValue += item.Value
+		snapshot.Items = append(snapshot.Items, item)
+	}
+
+	return snapshot, nil
 }
@@ -4,9 +4,12 @@ import (
 	"context"
 	"fmt"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"sell/api/models"
+	"sell/pkg/money"
 	"sell/storage"
+	"sort"
 )
 
 type saleRepo struct {
@@ -19,14 +22,16 @@ func NewSaleRepo(db *pgxpool.Pool) storage.ISaleStorage {
 
 func (s saleRepo) Create(ctx context.Context, sale models.CreateSale) (string, error) {
 	id := uuid.New()
-	query := `insert into sales (id, branch_id, shop_assistant_id, cashier_id, payment_type, price, status, client_name)
-								values($1, $2, $3, $4, $5, $6, $7, $8)`
+	query := `insert into sales (id, branch_id, shop_assistant_id, cashier_id, payment_type, currency, tax_included, price, status, client_name)
+								values($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`
 
 	if _, err := s.db.Exec(ctx, query, id,
 		sale.BranchID,
 		sale.ShopAssistantID,
 		sale.CashierID,
 		sale.PaymentType,
+		sale.Currency,
+		sale.TaxIncluded,
 		sale.Price,
 		sale.Status,
 		sale.ClientName); err != nil {
@@ -38,8 +43,8 @@ func (s saleRepo) Create(ctx context.Context, sale models.CreateSale) (string, e
 
 func (s saleRepo) GetByID(ctx context.Context, id string) (models.Sale, error) {
 	sale := models.Sale{}
-	query := `select id, branch_id, shop_assistant_id, cashier_id, payment_type, price, status, client_name, 
-					created_at, updated_at from sales where id = $1 and deleted_at is null`
+	query := `select id, branch_id, shop_assistant_id, cashier_id, payment_type, currency, price, tax_included, tax_amount, status, client_name, 
+					coalesce(fiscal_sign, ''), coalesce(fiscal_number, ''), fiscalized_at, finished_at, created_at, updated_at from sales where id = $1 and deleted_at is null`
 
 	if err := s.db.QueryRow(ctx, query, id).Scan(
 		&sale.ID,
@@ -47,9 +52,16 @@ func (s saleRepo) GetByID(ctx context.Context, id string) (models.Sale, error) {
 		&sale.ShopAssistantID,
 		&sale.CashierID,
 		&sale.PaymentType,
+		&sale.Currency,
 		&sale.Price,
+		&sale.TaxIncluded,
+		&sale.TaxAmount,
 		&sale.Status,
 		&sale.ClientName,
+		&sale.FiscalSign,
+		&sale.FiscalNumber,
+		&sale.FiscalizedAt,
+		&sale.FinishedAt,
 		&sale.CreatedAt,
 		&sale.UpdatedAt); err != nil {
 		fmt.Println("error is while selecting by id", err.Error())
@@ -78,8 +90,8 @@ func (s saleRepo) GetList(ctx context.Context, request models.GetListRequest) (m
 		return models.SaleResponse{}, err
 	}
 
-	query = `select id, branch_id, shop_assistant_id, cashier_id, payment_type, price, status, client_name, 
-					created_at, updated_at from sales where deleted_at is null `
+	query = `select id, branch_id, shop_assistant_id, cashier_id, payment_type, currency, price, tax_included, tax_amount, status, client_name, 
+					coalesce(fiscal_sign, ''), coalesce(fiscal_number, ''), fiscalized_at, finished_at, created_at, updated_at from sales where deleted_at is null `
 
 	if search != "" {
 		query += fmt.Sprintf(` AND client_name ilike '%%%s%%' `, search)
@@ -96,9 +108,16 @@ func (s saleRepo) GetList(ctx context.Context, request models.GetListRequest) (m
 			&sale.ShopAssistantID,
 			&sale.CashierID,
 			&sale.PaymentType,
+			&sale.Currency,
 			&sale.Price,
+			&sale.TaxIncluded,
+			&sale.TaxAmount,
 			&sale.Status,
 			&sale.ClientName,
+			&sale.FiscalSign,
+			&sale.FiscalNumber,
+			&sale.FiscalizedAt,
+			&sale.FinishedAt,
 			&sale.CreatedAt,
 			&sale.UpdatedAt); err != nil {
 			fmt.Println("error is while scanning sales", err.Error())
@@ -140,15 +159,474 @@ func (s saleRepo) Delete(ctx context.Context, id string) error {
 	return nil
 }
 
+// UpdatePrice sets the price and the status of the sale. A successful sale is put into the fiscal outbox
+// by the same statement, so it is registered with the fiscal module even when the module is down now.
 func (s saleRepo) UpdatePrice(ctx context.Context, request models.SaleRequest) (string, error) {
-	query := `update sales set price = $1, status = $2 where id = $3`
-	if rowsAffected, err := s.db.Exec(ctx, query, &request.TotalPrice, &request.Status, &request.SaleID); err != nil {
-		if r := rowsAffected.RowsAffected(); r == 0 {
-			fmt.Println("error in rows affected", err.Error())
-			return "", err
-		}
+	tag, err := s.db.Exec(ctx, `update sales set price = $1, status = $2, updated_at = now() where id = $3 and status = 'in_process'`,
+		&request.TotalPrice, &request.Status, &request.SaleID)
+	if err != nil {
 		fmt.Println("error is while updating sale price", err.Error())
 		return "", err
 	}
+	if tag.RowsAffected() == 0 {
+		return "", storage.ErrSaleNotOpen
+	}
 	return request.SaleID, nil
 }
+
+// Receipt returns the sale with its basket lines, taxes and payments, the unit price of a line is its price divided by the quantity.
+// The tax is the one calculated at checkout, it is zero for a sale which was not checked out yet.
+func (s saleRepo) Receipt(ctx context.Context, id string) (models.Receipt, error) {
+	receipt := models.Receipt{Lines: []models.ReceiptLine{}}
+	if err := s.db.QueryRow(ctx, `select s.id, coalesce(s.branch_id::text, ''), coalesce(b.name, ''), coalesce(s.cashier_id::text, ''), 
+       coalesce(s.payment_type::text, ''), coalesce(s.status::text, ''), coalesce(s.client_name, ''), s.currency, s.tax_included, 
+       coalesce(s.fiscal_sign, ''), coalesce(s.fiscal_number, ''), s.created_at 
+			from sales s left join branches b on b.id = s.branch_id where s.id = $1 and s.deleted_at is null`, id).Scan(
+		&receipt.SaleID,
+		&receipt.BranchID,
+		&receipt.BranchName,
+		&receipt.CashierID,
+		&receipt.PaymentType,
+		&receipt.Status,
+		&receipt.ClientName,
+		&receipt.Currency,
+		&receipt.TaxIncluded,
+		&receipt.FiscalSign,
+		&receipt.FiscalNumber,
+		&receipt.CreatedAt,
+	); err != nil {
+		fmt.Println("error is while selecting sale for receipt", err.Error())
+		return models.Receipt{}, err
+	}
+
+	rows, err := s.db.Query(ctx, `select b.product_id, coalesce(p.name, ''), coalesce(p.name_translations, '{}'), 
+       coalesce(p.unit::text, 'piece'), b.quantity, b.price, b.tax_rate, b.tax_amount 
+			from baskets b left join products p on p.id = b.product_id 
+				where b.sale_id = $1 and b.deleted_at is null order by b.created_at`, id)
+	if err != nil {
+		fmt.Println("error is while selecting receipt lines", err.Error())
+		return models.Receipt{}, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		line := models.ReceiptLine{}
+		if err := rows.Scan(
+			&line.ProductID,
+			&line.Name,
+			&line.NameTranslations,
+			&line.Unit,
+			&line.Quantity,
+			&line.Price,
+			&line.TaxRate,
+			&line.Tax,
+		); err != nil {
+			fmt.Println("error is while scanning receipt lines", err.Error())
+			return models.Receipt{}, err
+		}
+
+		if line.Quantity > 0 {
+			if line.UnitPrice, err = line.Price.Div(line.Quantity); err != nil {
+				return models.Receipt{}, err
+			}
+		}
+		line.Total = line.Price
+		if !receipt.TaxIncluded {
+			line.Total += line.Tax
+		}
+		receipt.Total += line.Total
+		receipt.Tax += line.Tax
+		receipt.Lines = append(receipt.Lines, line)
+	}
+	receipt.Net = receipt.Total - receipt.Tax
+
+	taxes := map[money.Money]*models.ReceiptTax{}
+	for _, line := range receipt.Lines {
+		tax := taxes[line.TaxRate]
+		if tax == nil {
+			tax = &models.ReceiptTax{Rate: line.TaxRate}
+			taxes[line.TaxRate] = tax
+		}
+		tax.Net += line.Total - line.Tax
+		tax.Tax += line.Tax
+		tax.Total += line.Total
+	}
+
+	receipt.Taxes = []models.ReceiptTax{}
+	for _, tax := range taxes {
+		receipt.Taxes = append(receipt.Taxes, *tax)
+	}
+	sort.Slice(receipt.Taxes, func(i, j int) bool {
+		return receipt.Taxes[i].Rate < receipt.Taxes[j].Rate
+	})
+
+	if receipt.Payments, err = s.payments(ctx, id); err != nil {
+		return models.Receipt{}, err
+	}
+	for _, payment := range receipt.Payments {
+		receipt.Paid += payment.BaseAmount
+	}
+	if receipt.Paid > receipt.Total {
+		receipt.Change = receipt.Paid - receipt.Total
+	}
+
+	return receipt, nil
+}
+
+// AddPayment records money received for the sale, the amount is converted to the currency of the sale at payment.ExchangeRate.
+func (s saleRepo) AddPayment(ctx context.Context, payment models.CreateSalePayment) (string, error) {
+	baseAmount, err := payment.Amount.Convert(payment.ExchangeRate)
+	if err != nil {
+		return "", err
+	}
+
+	id := uuid.New()
+	if _, err := s.db.Exec(ctx, `insert into sale_payments (id, sale_id, currency, amount, exchange_rate, base_amount)
+					values($1, $2, $3, $4, $5, $6)`,
+		id,
+		payment.SaleID,
+		payment.Currency,
+		payment.Amount,
+		payment.ExchangeRate,
+		baseAmount,
+	); err != nil {
+		fmt.Println("error is while inserting sale payment", err.Error())
+		return "", err
+	}
+
+	return id.String(), nil
+}
+
+// GetPayments sums up the payments of the sale against the total to pay: the price of a sale which was checked out,
+// the price of its basket lines with the tax at the rates of now added when prices do not include it otherwise.
+// The change is given in the currency of the sale whatever currencies were paid in.
+func (s saleRepo) GetPayments(ctx context.Context, saleID string) (models.SalePayments, error) {
+	result := models.SalePayments{SaleID: saleID}
+	if err := s.db.QueryRow(ctx, `select s.currency, case when s.status = 'success' then s.price else 
+       (select coalesce(sum(b.price + case when s.tax_included then 0 else `+basketTax(basketTaxRate)+` end), 0) 
+       		from baskets b where b.sale_id = s.id and b.deleted_at is null) end
+			from sales s where s.id = $1 and s.deleted_at is null`, saleID).Scan(
+		&result.Currency,
+		&result.Total,
+	); err != nil {
+		fmt.Println("error is while selecting sale for payments", err.Error())
+		return models.SalePayments{}, err
+	}
+
+	payments, err := s.payments(ctx, saleID)
+	if err != nil {
+		return models.SalePayments{}, err
+	}
+	result.Payments = payments
+
+	for _, payment := range payments {
+		result.Paid += payment.BaseAmount
+	}
+	if result.Paid < result.Total {
+		result.Due = result.Total - result.Paid
+	} else {
+		result.Change = result.Paid - result.Total
+	}
+
+	return result, nil
+}
+
+func (s saleRepo) payments(ctx context.Context, saleID string) ([]models.SalePayment, error) {
+	payments := []models.SalePayment{}
+	rows, err := s.db.Query(ctx, `select id, sale_id, currency, amount, exchange_rate, base_amount, created_at 
+			from sale_payments where sale_id = $1 and deleted_at is null order by created_at`, saleID)
+	if err != nil {
+		fmt.Println("error is while selecting sale payments", err.Error())
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		payment := models.SalePayment{}
+		if err := rows.Scan(
+			&payment.ID,
+			&payment.SaleID,
+			&payment.Currency,
+			&payment.Amount,
+			&payment.ExchangeRate,
+			&payment.BaseAmount,
+			&payment.CreatedAt,
+		); err != nil {
+			fmt.Println("error is while scanning sale payments", err.Error())
+			return nil, err
+		}
+		payments = append(payments, payment)
+	}
+
+	return payments, nil
+}
+
+// Checkout finishes the sale in one transaction. The tax of every line is calculated at the rates of its products now
+// and kept with the sale, whose price becomes the total to pay; the tax is added to the lines when prices do not include it.
+// The lines leave the branch repository with a minus movement each and the batches of the branch, the staff get their
+// commission and the sale waits in the fiscal outbox. A sale which is not in process or a line the repository does not
+// hold enough of leaves everything as it was.
+func (s saleRepo) Checkout(ctx context.Context, id string) (_ models.SaleTax, err error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		fmt.Println("error is while beginning transaction", err.Error())
+		return models.SaleTax{}, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+			return
+		}
+		err = tx.Commit(ctx)
+	}()
+
+	var (
+		result                               = models.SaleTax{SaleID: id}
+		branchID, cashierID, shopAssistantID string
+		paymentType, status                  string
+	)
+	if err = tx.QueryRow(ctx, `select tax_included, coalesce(branch_id::text, ''), coalesce(cashier_id::text, ''),
+       coalesce(shop_assistant_id::text, ''), coalesce(payment_type::text, ''), coalesce(status::text, '')
+			from sales where id = $1 and deleted_at is null for update`, id).Scan(
+		&result.TaxIncluded,
+		&branchID,
+		&cashierID,
+		&shopAssistantID,
+		&paymentType,
+		&status,
+	); err != nil {
+		fmt.Println("error is while selecting sale for update", err.Error())
+		return models.SaleTax{}, err
+	}
+
+	if status != "in_process" {
+		err = storage.ErrSaleNotOpen
+		return models.SaleTax{}, err
+	}
+
+	if _, err = tx.Exec(ctx, `update baskets b set tax_rate = `+basketTaxRate+`, updated_at = now()
+					where b.sale_id = $1 and b.deleted_at is null`, id); err != nil {
+		fmt.Println("error is while updating basket tax rates", err.Error())
+		return models.SaleTax{}, err
+	}
+
+	if _, err = tx.Exec(ctx, `update baskets b set tax_amount = `+basketTax("b.tax_rate")+`
+					from sales s where s.id = b.sale_id and b.sale_id = $1 and b.deleted_at is null`, id); err != nil {
+		fmt.Println("error is while updating basket taxes", err.Error())
+		return models.SaleTax{}, err
+	}
+
+	var price money.Money
+	if err = tx.QueryRow(ctx, `select coalesce(sum(price), 0), coalesce(sum(tax_amount), 0) from baskets 
+                                    where sale_id = $1 and deleted_at is null`, id).Scan(
+		&price,
+		&result.Tax,
+	); err != nil {
+		fmt.Println("error is while selecting sale tax", err.Error())
+		return models.SaleTax{}, err
+	}
+
+	result.Total = price
+	if !result.TaxIncluded {
+		result.Total += result.Tax
+	}
+	result.Net = result.Total - result.Tax
+
+	if _, err = tx.Exec(ctx, `update sales set price = $1, tax_amount = $2, status = 'success', finished_at = now(), updated_at = now()
+                 where id = $3 and status = 'in_process'`, result.Total, result.Tax, id); err != nil {
+		fmt.Println("error is while updating sale tax", err.Error())
+		return models.SaleTax{}, err
+	}
+
+	if err = takeSaleStock(ctx, tx, id, branchID); err != nil {
+		return models.SaleTax{}, err
+	}
+
+	if err = paySaleCommission(ctx, tx, id, cashierID, shopAssistantID, paymentType, result.Total); err != nil {
+		return models.SaleTax{}, err
+	}
+
+	if _, err = tx.Exec(ctx, `insert into fiscal_outbox (id, document_type, document_id) values($1, 'sale', $2)
+                    on conflict (document_type, document_id) do nothing`, uuid.New(), id); err != nil {
+		fmt.Println("error is while inserting fiscal outbox entry", err.Error())
+		return models.SaleTax{}, err
+	}
+
+	return result, nil
+}
+
+// takeSaleStock takes every line of the sale out of the branch repository, which must hold enough of it,
+// with a minus movement, and out of the batches of the branch, the earliest expiring first.
+func takeSaleStock(ctx context.Context, tx pgx.Tx, saleID, branchID string) error {
+	rows, err := tx.Query(ctx, `select product_id, quantity, price from baskets
+                                 where sale_id = $1 and deleted_at is null order by created_at`, saleID)
+	if err != nil {
+		fmt.Println("error is while selecting sale baskets", err.Error())
+		return err
+	}
+
+	baskets := []models.Basket{}
+	for rows.Next() {
+		basket := models.Basket{}
+		if err := rows.Scan(&basket.ProductID, &basket.Quantity, &basket.Price); err != nil {
+			rows.Close()
+			fmt.Println("error is while scanning sale baskets", err.Error())
+			return err
+		}
+		baskets = append(baskets, basket)
+	}
+	rows.Close()
+
+	for _, basket := range baskets {
+		tag, err := tx.Exec(ctx, `update repositories set count = count - $1, updated_at = now()
+                    where branch_id = $2 and product_id = $3 and deleted_at is null and count >= $1`,
+			basket.Quantity,
+			branchID,
+			basket.ProductID,
+		)
+		if err != nil {
+			fmt.Println("error is while updating repository count", err.Error())
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return fmt.Errorf("%w: %g of product %s", storage.ErrNotEnoughProduct, basket.Quantity, basket.ProductID)
+		}
+
+		if _, err := tx.Exec(ctx, `insert into repository_transactions
+    				(id, branch_id, product_id, repository_transaction_type, source_type, source_id, price, quantity)
+						values($1, $2, $3, 'minus', 'sale', $4, $5, $6)`,
+			uuid.New(),
+			branchID,
+			basket.ProductID,
+			saleID,
+			basket.Price,
+			basket.Quantity,
+		); err != nil {
+			fmt.Println("error is while inserting repository transaction", err.Error())
+			return err
+		}
+
+		if err := deductBatches(ctx, tx, branchID, basket.ProductID, basket.Quantity); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// paySaleCommission tops up the balance of the cashier and the shop assistant of the sale by their tariffs and
+// records a transaction for each of them. Fixed tariffs pay an amount for a sale, percent tariffs a share of total
+// rounded to the hundredth. Sales without a shop assistant pay no commission.
+func paySaleCommission(ctx context.Context, tx pgx.Tx, saleID, cashierID, shopAssistantID, paymentType string, total money.Money) error {
+	cashierCommission, err := staffCommission(ctx, tx, cashierID, paymentType, total)
+	if err != nil {
+		return err
+	}
+
+	if shopAssistantID == "" {
+		return nil
+	}
+
+	shopAssistantCommission, err := staffCommission(ctx, tx, shopAssistantID, paymentType, total)
+	if err != nil {
+		return err
+	}
+
+	balance := cashierCommission + shopAssistantCommission
+	for _, staffID := range []string{cashierID, shopAssistantID} {
+		if _, err := tx.Exec(ctx, `update staffs set balance = balance + $1 where id = $2`, balance, staffID); err != nil {
+			fmt.Println("error is while updating staff balance", err.Error())
+			return err
+		}
+
+		if _, err := tx.Exec(ctx, `insert into transactions (id, sale_id, staff_id, transaction_type, source_type, amount, description) 
+                                  values ($1, $2, $3, 'topup', 'sales', $4, 'some')`,
+			uuid.New(),
+			saleID,
+			staffID,
+			balance,
+		); err != nil {
+			fmt.Println("error is while inserting transaction data", err.Error())
+			return err
+		}
+	}
+
+	return nil
+}
+
+// staffCommission returns what the tariff of the staff pays for a sale of total paid in cash or by card.
+func staffCommission(ctx context.Context, tx pgx.Tx, staffID, paymentType string, total money.Money) (money.Money, error) {
+	var commission money.Money
+	if err := tx.QueryRow(ctx, `select coalesce(case t.tariff_type when 'fixed' then a.amount
+                                       when 'percent' then round($3::numeric * a.amount / 100, 2) end, 0)
+			from staffs s join staff_tariffs t on t.id = s.tariff_id,
+			     lateral (select case when $2 = 'cash' then t.amount_for_cash else t.amount_for_card end as amount) a
+				where s.id = $1 and s.deleted_at is null and t.deleted_at is null`, staffID, paymentType, total).Scan(&commission); err != nil {
+		fmt.Println("error is while selecting staff commission", err.Error())
+		return 0, err
+	}
+	return commission, nil
+}
+
+// TaxReport sums up the tax of sales checked out in the period by branch and tax rate, dates are days of finished_at.
+func (s saleRepo) TaxReport(ctx context.Context, request models.TaxReportRequest) (models.TaxReport, error) {
+	var (
+		report = models.TaxReport{Rows: []models.TaxReportRow{}}
+		filter string
+		args   []interface{}
+	)
+
+	if request.BranchID != "" {
+		args = append(args, request.BranchID)
+		filter += fmt.Sprintf(` and s.branch_id = $%d`, len(args))
+	}
+
+	if request.FromDate != "" {
+		args = append(args, request.FromDate)
+		filter += fmt.Sprintf(` and s.finished_at >= $%d::date`, len(args))
+	}
+
+	if request.ToDate != "" {
+		args = append(args, request.ToDate)
+		filter += fmt.Sprintf(` and s.finished_at < $%d::date + 1`, len(args))
+	}
+
+	query := `select coalesce(s.branch_id::text, ''), coalesce(br.name, ''), b.tax_rate, count(distinct s.id),
+       				coalesce(sum(case when s.tax_included then b.price - b.tax_amount else b.price end), 0), coalesce(sum(b.tax_amount), 0)
+					from sales s
+					    join baskets b on b.sale_id = s.id and b.deleted_at is null
+					    left join branches br on br.id = s.branch_id
+							where s.deleted_at is null and s.status = 'success' ` + filter + `
+								group by s.branch_id, br.name, b.tax_rate order by br.name, s.branch_id, b.tax_rate`
+
+	rows, err := s.db.Query(ctx, query, args...)
+	if err != nil {
+		fmt.Println("error is while selecting tax report", err.Error())
+		return models.TaxReport{}, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		row := models.TaxReportRow{}
+		if err := rows.Scan(&row.BranchID, &row.BranchName, &row.TaxRate, &row.Sales, &row.Net, &row.Tax); err != nil {
+			fmt.Println("error is while scanning tax report", err.Error())
+			return models.TaxReport{}, err
+		}
+		row.Total = row.Net + row.Tax
+		report.TotalNet += row.Net
+		report.TotalTax += row.Tax
+		report.Total += row.Total
+		report.Rows = append(report.Rows, row)
+	}
+
+	return report, nil
+}
+
+// basketTaxRate is the tax rate of the product of basket line b in percent, 0 when no rate applies.
+var basketTaxRate = `coalesce((select t.rate from (` + taxRateOf("b.product_id") + `) t), 0)`
+
+// basketTax is the tax of basket line b of sale s at the rate, rounded to the hundredth half away from zero:
+// the part of the line price which is tax when prices of the sale include it, the tax on top of the price otherwise.
+func basketTax(rate string) string {
+	return fmt.Sprintf(`round(case when s.tax_included then b.price * %[1]s / (100 + %[1]s) else b.price * %[1]s / 100 end, 2)`, rate)
+}
@@ -0,0 +1,181 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"sell/api/models"
+	"sell/storage"
+)
+
+type supplierRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewSupplierRepo(db *pgxpool.Pool) storage.ISupplierStorage {
+	return &supplierRepo{db: db}
+}
+
+// supplierBalance is the debt to a supplier in the base currency: everything received from the supplier
+// minus everything paid, every document converted at its own rate. Incomes in process are not received yet.
+const supplierBalance = `(select coalesce(sum(round(price * exchange_rate, 2)), 0) from incomes
+		where supplier_id = s.id and status = 'finished' and deleted_at is null) -
+	(select coalesce(sum(round(amount * exchange_rate, 2)), 0) from supplier_payments where supplier_id = s.id and deleted_at is null)`
+
+func (s *supplierRepo) Create(ctx context.Context, supplier models.CreateSupplier) (string, error) {
+	id := uuid.New()
+	query := `insert into suppliers (id, name, phone, address) values($1, $2, $3, $4)`
+	if _, err := s.db.Exec(ctx, query, id, supplier.Name, supplier.Phone, supplier.Address); err != nil {
+		fmt.Println("error is while inserting supplier", err.Error())
+		return "", err
+	}
+	return id.String(), nil
+}
+
+func (s *supplierRepo) GetByID(ctx context.Context, id string) (models.Supplier, error) {
+	supplier := models.Supplier{}
+	query := `select s.id, s.name, coalesce(s.phone, ''), coalesce(s.address, ''), ` + supplierBalance + `,
+       				s.created_at, s.updated_at from suppliers s where s.id = $1 and s.deleted_at is null`
+	if err := s.db.QueryRow(ctx, query, id).Scan(
+		&supplier.ID,
+		&supplier.Name,
+		&supplier.Phone,
+		&supplier.Address,
+		&supplier.Balance,
+		&supplier.CreatedAt,
+		&supplier.UpdatedAt,
+	); err != nil {
+		fmt.Println("error is while selecting supplier by id", err.Error())
+		return models.Supplier{}, err
+	}
+	return supplier, nil
+}
+
+func (s *supplierRepo) GetList(ctx context.Context, request models.GetListRequest) (models.SupplierResponse, error) {
+	var (
+		count     int
+		suppliers = []models.Supplier{}
+		filter    string
+		args      []interface{}
+		offset    = (request.Page - 1) * request.Limit
+	)
+
+	if request.Search != "" {
+		args = append(args, "%"+request.Search+"%")
+		filter += fmt.Sprintf(` and s.name ilike $%d`, len(args))
+	}
+
+	countQuery := `select count(1) from suppliers s where s.deleted_at is null ` + filter
+	if err := s.db.QueryRow(ctx, countQuery, args...).Scan(&count); err != nil {
+		fmt.Println("error is while selecting count of suppliers", err.Error())
+		return models.SupplierResponse{}, err
+	}
+
+	query := `select s.id, s.name, coalesce(s.phone, ''), coalesce(s.address, ''), ` + supplierBalance + `,
+       				s.created_at, s.updated_at from suppliers s where s.deleted_at is null ` + filter +
+		fmt.Sprintf(` order by s.created_at desc LIMIT $%d OFFSET $%d`, len(args)+1, len(args)+2)
+
+	rows, err := s.db.Query(ctx, query, append(args, request.Limit, offset)...)
+	if err != nil {
+		fmt.Println("error is while selecting suppliers", err.Error())
+		return models.SupplierResponse{}, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		supplier := models.Supplier{}
+		if err := rows.Scan(
+			&supplier.ID,
+			&supplier.Name,
+			&supplier.Phone,
+			&supplier.Address,
+			&supplier.Balance,
+			&supplier.CreatedAt,
+			&supplier.UpdatedAt,
+		); err != nil {
+			fmt.Println("error is while scanning suppliers", err.Error())
+			return models.SupplierResponse{}, err
+		}
+		suppliers = append(suppliers, supplier)
+	}
+
+	return models.SupplierResponse{
+		Suppliers: suppliers,
+		Count:     count,
+	}, nil
+}
+
+func (s *supplierRepo) Update(ctx context.Context, supplier models.UpdateSupplier) (string, error) {
+	query := `update suppliers set name = $1, phone = $2, address = $3, updated_at = now() where id = $4`
+	if _, err := s.db.Exec(ctx, query, &supplier.Name, &supplier.Phone, &supplier.Address, &supplier.ID); err != nil {
+		fmt.Println("error is while updating supplier", err.Error())
+		return "", err
+	}
+	return supplier.ID, nil
+}
+
+func (s *supplierRepo) Delete(ctx context.Context, id string) error {
+	query := `update suppliers set deleted_at = now() where id = $1`
+	if _, err := s.db.Exec(ctx, query, id); err != nil {
+		fmt.Println("error is while deleting supplier", err.Error())
+		return err
+	}
+	return nil
+}
+
+func (s *supplierRepo) CreatePayment(ctx context.Context, payment models.CreateSupplierPayment) (string, error) {
+	id := uuid.New()
+	query := `insert into supplier_payments (id, supplier_id, currency, amount, exchange_rate, comment) values($1, $2, $3, $4, $5, $6)`
+	if _, err := s.db.Exec(ctx, query, id, payment.SupplierID, payment.Currency, payment.Amount, payment.ExchangeRate, payment.Comment); err != nil {
+		fmt.Println("error is while inserting supplier payment", err.Error())
+		return "", err
+	}
+	return id.String(), nil
+}
+
+func (s *supplierRepo) GetPaymentList(ctx context.Context, request models.SupplierPaymentGetListRequest) (models.SupplierPaymentResponse, error) {
+	var (
+		count    int
+		payments = []models.SupplierPayment{}
+		offset   = (request.Page - 1) * request.Limit
+	)
+
+	countQuery := `select count(1) from supplier_payments where supplier_id = $1 and deleted_at is null`
+	if err := s.db.QueryRow(ctx, countQuery, request.SupplierID).Scan(&count); err != nil {
+		fmt.Println("error is while selecting count of supplier payments", err.Error())
+		return models.SupplierPaymentResponse{}, err
+	}
+
+	query := `select id, supplier_id, currency, amount, exchange_rate, round(amount * exchange_rate, 2), coalesce(comment, ''), created_at from supplier_payments
+						where supplier_id = $1 and deleted_at is null order by created_at desc LIMIT $2 OFFSET $3`
+	rows, err := s.db.Query(ctx, query, request.SupplierID, request.Limit, offset)
+	if err != nil {
+		fmt.Println("error is while selecting supplier payments", err.Error())
+		return models.SupplierPaymentResponse{}, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		payment := models.SupplierPayment{}
+		if err := rows.Scan(
+			&payment.ID,
+			&payment.SupplierID,
+			&payment.Currency,
+			&payment.Amount,
+			&payment.ExchangeRate,
+			&payment.BaseAmount,
+			&payment.Comment,
+			&payment.CreatedAt,
+		); err != nil {
+			fmt.Println("error is while scanning supplier payments", err.Error())
+			return models.SupplierPaymentResponse{}, err
+		}
+		payments = append(payments, payment)
+	}
+
+	return models.SupplierPaymentResponse{
+		Payments: payments,
+		Count:    count,
+	}, nil
+}
@@ -0,0 +1,198 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"sell/api/models"
+	"sell/storage"
+)
+
+type taxRateRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewTaxRateRepo(db *pgxpool.Pool) storage.ITaxRateStorage {
+	return &taxRateRepo{db: db}
+}
+
+func (t *taxRateRepo) Create(ctx context.Context, rate models.CreateTaxRate) (string, error) {
+	id := uuid.New()
+	if _, err := t.db.Exec(ctx, `insert into tax_rates (id, name, rate) values($1, $2, $3)`, id, rate.Name, rate.Rate); err != nil {
+		fmt.Println("error is while inserting tax rate", err.Error())
+		return "", err
+	}
+	return id.String(), nil
+}
+
+func (t *taxRateRepo) GetByID(ctx context.Context, id string) (models.TaxRate, error) {
+	rate := models.TaxRate{}
+	if err := t.db.QueryRow(ctx, `select id, name, rate, created_at, updated_at from tax_rates where id = $1 and deleted_at is null`, id).Scan(
+		&rate.ID,
+		&rate.Name,
+		&rate.Rate,
+		&rate.CreatedAt,
+		&rate.UpdatedAt,
+	); err != nil {
+		fmt.Println("error is while selecting tax rate by id", err.Error())
+		return models.TaxRate{}, err
+	}
+	return rate, nil
+}
+
+func (t *taxRateRepo) GetList(ctx context.Context, request models.GetListRequest) (models.TaxRatesResponse, error) {
+	var (
+		count  int
+		rates  = []models.TaxRate{}
+		filter string
+		args   []interface{}
+		offset = (request.Page - 1) * request.Limit
+	)
+
+	if request.Search != "" {
+		args = append(args, "%"+request.Search+"%")
+		filter += fmt.Sprintf(` and name ilike $%d`, len(args))
+	}
+
+	if err := t.db.QueryRow(ctx, `select count(1) from tax_rates where deleted_at is null `+filter, args...).Scan(&count); err != nil {
+		fmt.Println("error is while selecting count of tax rates", err.Error())
+		return models.TaxRatesResponse{}, err
+	}
+
+	query := `select id, name, rate, created_at, updated_at from tax_rates where deleted_at is null ` + filter +
+		fmt.Sprintf(` order by rate, name LIMIT $%d OFFSET $%d`, len(args)+1, len(args)+2)
+
+	rows, err := t.db.Query(ctx, query, append(args, request.Limit, offset)...)
+	if err != nil {
+		fmt.Println("error is while selecting tax rates", err.Error())
+		return models.TaxRatesResponse{}, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		rate := models.TaxRate{}
+		if err := rows.Scan(
+			&rate.ID,
+			&rate.Name,
+			&rate.Rate,
+			&rate.CreatedAt,
+			&rate.UpdatedAt,
+		); err != nil {
+			fmt.Println("error is while scanning tax rates", err.Error())
+			return models.TaxRatesResponse{}, err
+		}
+		rates = append(rates, rate)
+	}
+
+	return models.TaxRatesResponse{
+		TaxRates: rates,
+		Count:    count,
+	}, nil
+}
+
+// Update changes the rate for sales checked out from now on, sold receipts keep the rate they were taxed at.
+func (t *taxRateRepo) Update(ctx context.Context, rate models.UpdateTaxRate) (string, error) {
+	tag, err := t.db.Exec(ctx, `update tax_rates set name = $1, rate = $2, updated_at = now() where id = $3 and deleted_at is null`,
+		rate.Name, rate.Rate, rate.ID)
+	if err != nil {
+		fmt.Println("error is while updating tax rate", err.Error())
+		return "", err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return "", pgx.ErrNoRows
+	}
+
+	return rate.ID, nil
+}
+
+// Delete removes the rate, products and categories which had it are taxed as if they had no rate of their own.
+func (t *taxRateRepo) Delete(ctx context.Context, id string) error {
+	tag, err := t.db.Exec(ctx, `update tax_rates set deleted_at = now(), updated_at = now() where id = $1 and deleted_at is null`, id)
+	if err != nil {
+		fmt.Println("error is while deleting tax rate", err.Error())
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+
+	return nil
+}
+
+func (t *taxRateRepo) SetProductTaxRate(ctx context.Context, request models.SetTaxRate) error {
+	return t.setTaxRate(ctx, "products", request)
+}
+
+func (t *taxRateRepo) SetCategoryTaxRate(ctx context.Context, request models.SetTaxRate) error {
+	return t.setTaxRate(ctx, "categories", request)
+}
+
+func (t *taxRateRepo) setTaxRate(ctx context.Context, table string, request models.SetTaxRate) error {
+	tag, err := t.db.Exec(ctx, `update `+table+` set tax_rate_id = nullif($1, '')::uuid, updated_at = now()
+					where id = $2 and deleted_at is null`, request.TaxRateID, request.ID)
+	if err != nil {
+		fmt.Println("error is while setting tax rate of "+table, err.Error())
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+
+	return nil
+}
+
+// GetProductTaxRate returns the rate the product is taxed at now, a zero rate when none applies.
+func (t *taxRateRepo) GetProductTaxRate(ctx context.Context, productID string) (models.ProductTaxRate, error) {
+	var exists bool
+	if err := t.db.QueryRow(ctx, `select exists (select 1 from products where id = $1 and deleted_at is null)`, productID).Scan(&exists); err != nil {
+		fmt.Println("error is while selecting product", err.Error())
+		return models.ProductTaxRate{}, err
+	}
+
+	if !exists {
+		return models.ProductTaxRate{}, pgx.ErrNoRows
+	}
+
+	rate := models.ProductTaxRate{ProductID: productID}
+	if err := t.db.QueryRow(ctx, taxRateOf("$1::uuid"), productID).Scan(
+		&rate.TaxRateID,
+		&rate.Name,
+		&rate.Rate,
+		&rate.Source,
+	); err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		fmt.Println("error is while selecting product tax rate", err.Error())
+		return models.ProductTaxRate{}, err
+	}
+
+	return rate, nil
+}
+
+// taxRateOf selects the id, name, rate and source of the tax rate of the product given by the expression:
+// the rate of the product, of the product it is a variant of, or of the nearest category up the tree
+// of the product or its parent. Deleted rates are skipped, no row is selected when no rate applies.
+func taxRateOf(product string) string {
+	return `select r.id::text, r.name, r.rate, o.source from (
+				select p.tax_rate_id, 'product' as source, 0 as depth from products p where p.id = ` + product + `
+				union all
+				select pp.tax_rate_id, 'parent', 1 from products p join products pp on pp.id = p.parent_id where p.id = ` + product + `
+				union all
+				select chain.tax_rate_id, 'category', chain.depth from (
+					with recursive up as (
+						select c.id, c.parent_id, c.tax_rate_id, 2 as depth from categories c
+							where c.deleted_at is null and c.id = (select coalesce(p.category_id, pp.category_id) from products p
+								left join products pp on pp.id = p.parent_id where p.id = ` + product + `)
+						union all
+						-- the depth stops at a parent loop which may be left in data saved before moves were checked
+						select c.id, c.parent_id, c.tax_rate_id, up.depth + 1 from categories c
+							join up on c.id = up.parent_id where c.deleted_at is null and up.depth < 100
+					) select tax_rate_id, depth from up
+				) chain
+			) o join tax_rates r on r.id = o.tax_rate_id and r.deleted_at is null
+				order by o.depth limit 1`
+}
@@ -7,7 +7,6 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 	"sell/api/models"
 	"sell/storage"
-	"strconv"
 )
 
 type transactionRepo struct {
@@ -70,11 +69,11 @@ func (t transactionRepo) GetList(ctx context.Context, request models.Transaction
 
 	countQuery = `select count(1) from transactions where deleted_at is null `
 	if fromAmount != 0 && toAmount != 0 {
-		countQuery += fmt.Sprintf(` and amount between %f and %f`, fromAmount, toAmount)
+		countQuery += fmt.Sprintf(` and amount between %s and %s`, fromAmount.String(), toAmount.String())
 	} else if fromAmount != 0 {
-		countQuery += ` and amount >= ` + strconv.FormatFloat(fromAmount, 'f', 2, 64)
+		countQuery += ` and amount >= ` + fromAmount.String()
 	} else {
-		countQuery += ` and amount <= ` + strconv.FormatFloat(toAmount, 'f', 2, 64)
+		countQuery += ` and amount <= ` + toAmount.String()
 
 	}
 	if err := t.db.QueryRow(ctx, countQuery).Scan(&count); err != nil {
@@ -86,11 +85,11 @@ func (t transactionRepo) GetList(ctx context.Context, request models.Transaction
        						description, created_at, updated_at from transactions where deleted_at is null `
 
 	if fromAmount != 0 && toAmount != 0 {
-		query += fmt.Sprintf(` and amount between %f and %f  order by amount asc, `, fromAmount, toAmount)
+		query += fmt.Sprintf(` and amount between %s and %s  order by amount asc, `, fromAmount.String(), toAmount.String())
 	} else if fromAmount != 0 {
-		query += ` and amount >= ` + strconv.FormatFloat(fromAmount, 'f', 2, 64) + `  order by amount asc, `
+		query += ` and amount >= ` + fromAmount.String() + `  order by amount asc, `
 	} else {
-		query += ` and amount <= ` + strconv.FormatFloat(toAmount, 'f', 2, 64) + ` order by amount asc, `
+		query += ` and amount <= ` + toAmount.String() + ` order by amount asc, `
 
 	}
 
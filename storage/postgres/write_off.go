@@ -0,0 +1,383 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"sell/api/models"
+	"sell/pkg/money"
+	"sell/storage"
+)
+
+type writeOffRepo struct {
+	db *pgxpool.Pool
+}
+
+func NewWriteOffRepo(db *pgxpool.Pool) storage.IWriteOffStorage {
+	return &writeOffRepo{db: db}
+}
+
+func (w *writeOffRepo) Create(ctx context.Context, writeOff models.CreateWriteOff) (_ string, err error) {
+	id := uuid.New()
+
+	tx, err := w.db.Begin(ctx)
+	if err != nil {
+		fmt.Println("error is while beginning transaction", err.Error())
+		return "", err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+			return
+		}
+		err = tx.Commit(ctx)
+	}()
+
+	query := `insert into write_offs (id, branch_id, reason, comment, created_by)
+						values($1, $2, $3, $4, nullif($5, '')::uuid)`
+	if _, err = tx.Exec(ctx, query,
+		id,
+		writeOff.BranchID,
+		writeOff.Reason,
+		writeOff.Comment,
+		writeOff.CreatedBy,
+	); err != nil {
+		fmt.Println("error is while inserting write off", err.Error())
+		return "", err
+	}
+
+	var tag pgconn.CommandTag
+	for _, product := range writeOff.Products {
+		// the line keeps the product price of the branch at the moment of writing off
+		tag, err = tx.Exec(ctx, `insert into write_off_products (id, write_off_id, product_id, price, quantity)
+					select $1, $2, p.id, `+effectivePrice("$5::uuid", "now()")+`, $3 from `+productsJoin+` where p.id = $4 and p.deleted_at is null`,
+			uuid.New(),
+			id,
+			product.Quantity,
+			product.ProductID,
+			writeOff.BranchID,
+		)
+		if err != nil {
+			fmt.Println("error is while inserting write off product", err.Error())
+			return "", err
+		}
+		if tag.RowsAffected() == 0 {
+			err = fmt.Errorf("product %s not found", product.ProductID)
+			return "", err
+		}
+	}
+
+	if _, err = tx.Exec(ctx, `update write_offs set price = (
+			select coalesce(sum(round(price * quantity, 2)), 0) from write_off_products where write_off_id = $1 and deleted_at is null
+		) where id = $1`, id); err != nil {
+		fmt.Println("error is while updating write off price", err.Error())
+		return "", err
+	}
+
+	return id.String(), nil
+}
+
+func (w *writeOffRepo) GetByID(ctx context.Context, id string) (models.WriteOff, error) {
+	writeOff := models.WriteOff{}
+
+	query := `select id, branch_id, reason, status, price, coalesce(comment, ''), coalesce(created_by::text, ''),
+       				coalesce(approved_by::text, ''), approved_at, created_at, updated_at
+						from write_offs where id = $1 and deleted_at is null`
+	if err := w.db.QueryRow(ctx, query, id).Scan(
+		&writeOff.ID,
+		&writeOff.BranchID,
+		&writeOff.Reason,
+		&writeOff.Status,
+		&writeOff.Price,
+		&writeOff.Comment,
+		&writeOff.CreatedBy,
+		&writeOff.ApprovedBy,
+		&writeOff.ApprovedAt,
+		&writeOff.CreatedAt,
+		&writeOff.UpdatedAt,
+	); err != nil {
+		fmt.Println("error is while selecting write off by id", err.Error())
+		return models.WriteOff{}, err
+	}
+
+	rows, err := w.db.Query(ctx, `select id, write_off_id, product_id, price, quantity, created_at
+						from write_off_products where write_off_id = $1 and deleted_at is null order by created_at`, id)
+	if err != nil {
+		fmt.Println("error is while selecting write off products", err.Error())
+		return models.WriteOff{}, err
+	}
+	defer rows.Close()
+
+	writeOff.Products = []models.WriteOffProduct{}
+	for rows.Next() {
+		product := models.WriteOffProduct{}
+		if err := rows.Scan(
+			&product.ID,
+			&product.WriteOffID,
+			&product.ProductID,
+			&product.Price,
+			&product.Quantity,
+			&product.CreatedAt,
+		); err != nil {
+			fmt.Println("error is while scanning write off products", err.Error())
+			return models.WriteOff{}, err
+		}
+		writeOff.Products = append(writeOff.Products, product)
+	}
+
+	return writeOff, nil
+}
+
+func (w *writeOffRepo) GetList(ctx context.Context, request models.WriteOffGetListRequest) (models.WriteOffResponse, error) {
+	var (
+		count     int
+		writeOffs = []models.WriteOff{}
+		filter    string
+		args      []interface{}
+		offset    = (request.Page - 1) * request.Limit
+	)
+
+	if request.BranchID != "" {
+		args = append(args, request.BranchID)
+		filter += fmt.Sprintf(` and branch_id = $%d`, len(args))
+	}
+
+	if request.Reason != "" {
+		args = append(args, request.Reason)
+		filter += fmt.Sprintf(` and reason = $%d`, len(args))
+	}
+
+	if request.Status != "" {
+		args = append(args, request.Status)
+		filter += fmt.Sprintf(` and status = $%d`, len(args))
+	}
+
+	countQuery := `select count(1) from write_offs where deleted_at is null ` + filter
+	if err := w.db.QueryRow(ctx, countQuery, args...).Scan(&count); err != nil {
+		fmt.Println("error is while selecting count of write offs", err.Error())
+		return models.WriteOffResponse{}, err
+	}
+
+	query := `select id, branch_id, reason, status, price, coalesce(comment, ''), coalesce(created_by::text, ''),
+       				coalesce(approved_by::text, ''), approved_at, created_at, updated_at
+						from write_offs where deleted_at is null ` + filter +
+		fmt.Sprintf(` order by created_at desc LIMIT $%d OFFSET $%d`, len(args)+1, len(args)+2)
+
+	rows, err := w.db.Query(ctx, query, append(args, request.Limit, offset)...)
+	if err != nil {
+		fmt.Println("error is while selecting write offs", err.Error())
+		return models.WriteOffResponse{}, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		writeOff := models.WriteOff{}
+		if err := rows.Scan(
+			&writeOff.ID,
+			&writeOff.BranchID,
+			&writeOff.Reason,
+			&writeOff.Status,
+			&writeOff.Price,
+			&writeOff.Comment,
+			&writeOff.CreatedBy,
+			&writeOff.ApprovedBy,
+			&writeOff.ApprovedAt,
+			&writeOff.CreatedAt,
+			&writeOff.UpdatedAt,
+		); err != nil {
+			fmt.Println("error is while scanning write offs", err.Error())
+			return models.WriteOffResponse{}, err
+		}
+		writeOffs = append(writeOffs, writeOff)
+	}
+
+	return models.WriteOffResponse{
+		WriteOffs: writeOffs,
+		Count:     count,
+	}, nil
+}
+
+// Approve deducts every line of a pending write-off from the branch repository
+// and records a minus repository transaction tagged with the write-off reason.
+func (w *writeOffRepo) Approve(ctx context.Context, request models.ApproveWriteOff) (err error) {
+	tx, err := w.db.Begin(ctx)
+	if err != nil {
+		fmt.Println("error is while beginning transaction", err.Error())
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+			return
+		}
+		err = tx.Commit(ctx)
+	}()
+
+	var branchID, reason, status string
+	if err = tx.QueryRow(ctx, `select branch_id, reason, status from write_offs
+                              where id = $1 and deleted_at is null for update`, request.ID).Scan(
+		&branchID,
+		&reason,
+		&status,
+	); err != nil {
+		fmt.Println("error is while selecting write off for update", err.Error())
+		return err
+	}
+
+	if status != "pending" {
+		err = storage.ErrWriteOffNotPending
+		return err
+	}
+
+	rows, err := tx.Query(ctx, `select product_id, price, quantity from write_off_products
+                                 where write_off_id = $1 and deleted_at is null`, request.ID)
+	if err != nil {
+		fmt.Println("error is while selecting write off products", err.Error())
+		return err
+	}
+
+	products := []models.WriteOffProduct{}
+	for rows.Next() {
+		product := models.WriteOffProduct{}
+		if err = rows.Scan(&product.ProductID, &product.Price, &product.Quantity); err != nil {
+			rows.Close()
+			fmt.Println("error is while scanning write off products", err.Error())
+			return err
+		}
+		products = append(products, product)
+	}
+	rows.Close()
+
+	var tag pgconn.CommandTag
+	for _, product := range products {
+		tag, err = tx.Exec(ctx, `update repositories set count = count - $1, updated_at = now()
+                    where id = (select id from repositories where branch_id = $2 and product_id = $3
+                                   and deleted_at is null and count >= $1 order by created_at limit 1)`,
+			product.Quantity,
+			branchID,
+			product.ProductID,
+		)
+		if err != nil {
+			fmt.Println("error is while updating repository count", err.Error())
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			err = storage.ErrNotEnoughProduct
+			return err
+		}
+
+		var price money.Money
+		if price, err = product.Price.Mul(product.Quantity); err != nil {
+			return err
+		}
+
+		if _, err = tx.Exec(ctx, `insert into repository_transactions
+    				(id, branch_id, product_id, repository_transaction_type, reason, source_type, source_id, price, quantity)
+						values($1, $2, $3, 'minus', $4, 'write_off', $5, $6, $7)`,
+			uuid.New(),
+			branchID,
+			product.ProductID,
+			reason,
+			request.ID,
+			price,
+			product.Quantity,
+		); err != nil {
+			fmt.Println("error is while inserting repository transaction", err.Error())
+			return err
+		}
+
+		if err = deductBatches(ctx, tx, branchID, product.ProductID, product.Quantity); err != nil {
+			return err
+		}
+	}
+
+	if _, err = tx.Exec(ctx, `update write_offs set status = 'approved', approved_by = $1, approved_at = now(),
+                      updated_at = now() where id = $2`, request.ApprovedBy, request.ID); err != nil {
+		fmt.Println("error is while approving write off", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (w *writeOffRepo) Reject(ctx context.Context, request models.ApproveWriteOff) error {
+	tag, err := w.db.Exec(ctx, `update write_offs set status = 'rejected', approved_by = $1, approved_at = now(),
+                      updated_at = now() where id = $2 and status = 'pending' and deleted_at is null`, request.ApprovedBy, request.ID)
+	if err != nil {
+		fmt.Println("error is while rejecting write off", err.Error())
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return storage.ErrWriteOffNotPending
+	}
+
+	return nil
+}
+
+func (w *writeOffRepo) Delete(ctx context.Context, id string) error {
+	tag, err := w.db.Exec(ctx, `update write_offs set deleted_at = now() where id = $1 and status = 'pending'`, id)
+	if err != nil {
+		fmt.Println("error is while deleting write off", err.Error())
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return errors.New("only pending write-offs can be deleted")
+	}
+
+	return nil
+}
+
+// Report groups approved write-offs by reason, filtered by approval date.
+func (w *writeOffRepo) Report(ctx context.Context, request models.WriteOffReportRequest) (models.WriteOffReport, error) {
+	var (
+		report = models.WriteOffReport{Reasons: []models.WriteOffReportRow{}}
+		filter string
+		args   []interface{}
+	)
+
+	if request.BranchID != "" {
+		args = append(args, request.BranchID)
+		filter += fmt.Sprintf(` and w.branch_id = $%d`, len(args))
+	}
+
+	if request.FromDate != "" {
+		args = append(args, request.FromDate)
+		filter += fmt.Sprintf(` and w.approved_at >= $%d::date`, len(args))
+	}
+
+	if request.ToDate != "" {
+		args = append(args, request.ToDate)
+		filter += fmt.Sprintf(` and w.approved_at < $%d::date + 1`, len(args))
+	}
+
+	query := `select w.reason, count(distinct w.id), coalesce(sum(p.quantity), 0), coalesce(sum(round(p.price * p.quantity, 2)), 0)
+					from write_offs w
+					    join write_off_products p on p.write_off_id = w.id and p.deleted_at is null
+							where w.deleted_at is null and w.status = 'approved' ` + filter + `
+								group by w.reason order by w.reason`
+
+	rows, err := w.db.Query(ctx, query, args...)
+	if err != nil {
+		fmt.Println("error is while selecting write off report", err.Error())
+		return models.WriteOffReport{}, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		row := models.WriteOffReportRow{}
+		if err := rows.Scan(&row.Reason, &row.Documents, &row.Quantity, &row.Price); err != nil {
+			fmt.Println("error is while scanning write off report", err.Error())
+			return models.WriteOffReport{}, err
+		}
+		report.TotalQuantity += row.Quantity
+		report.TotalPrice += row.Price
+		report.Reasons = append(report.Reasons, row)
+	}
+
+	return report, nil
+}
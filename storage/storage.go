@@ -2,9 +2,31 @@ package storage
 
 import (
 	"context"
+	"errors"
 	"sell/api/models"
 )
 
+var (
+	ErrNotEnoughProduct    = errors.New("not enough product in repository")
+	ErrWriteOffNotPending  = errors.New("write-off is already approved or rejected")
+	ErrPurchaseOrderClosed = errors.New("purchase order is already received or cancelled")
+	ErrIncomeFinished      = errors.New("income is already finished")
+	ErrEmptyIncome         = errors.New("income has no products")
+	ErrVariantParent       = errors.New("a variant can not have variants")
+	ErrPriceStarted        = errors.New("price has already started, only scheduled prices can be cancelled")
+	ErrCategoryCycle       = errors.New("a category can not be moved under itself or its subcategory")
+	ErrCategoryNotEmpty    = errors.New("category has subcategories or products")
+	ErrRateStarted         = errors.New("exchange rate has already started, only scheduled rates can be cancelled")
+	ErrSaleNotRefundable   = errors.New("only successful sales can be refunded")
+	ErrRefundQuantity      = errors.New("refund quantity is more than what is left of the sold quantity")
+	ErrFiscalSent          = errors.New("the document is already registered with the fiscal module")
+	ErrRepositoryExists    = errors.New("the branch already has a repository of the product")
+	ErrBarcodeExists       = errors.New("the barcode is already given to another product")
+	ErrFractionalStock     = errors.New("the unit or precision does not fit the fractional stock of the product")
+	ErrSaleNotOpen         = errors.New("the sale is already finished or canceled")
+	ErrMovementReversed    = errors.New("the movement is already reversed")
+)
+
 type IStorage interface {
 	Close()
 	StaffTariff() IStaffTariffRepo
@@ -19,6 +41,16 @@ type IStorage interface {
 	Transaction() ITransactionStorage
 	Income() IIncomeStorage
 	IncomeProducts() IIncomeProductsStorage
+	WriteOff() IWriteOffStorage
+	Batch() IBatchStorage
+	Supplier() ISupplierStorage
+	PurchaseOrder() IPurchaseOrderStorage
+	BarcodeFormat() IBarcodeFormatStorage
+	ProductPrice() IProductPriceStorage
+	ExchangeRate() IExchangeRateStorage
+	TaxRate() ITaxRateStorage
+	Refund() IRefundStorage
+	Fiscal() IFiscalStorage
 }
 
 type IStaffTariffRepo interface {
@@ -45,7 +77,12 @@ type IRepositoryRepo interface {
 	GetByID(context.Context, models.PrimaryKey) (models.Repository, error)
 	GetList(context.Context, models.GetListRequest) (models.RepositoriesResponse, error)
 	Update(context.Context, models.UpdateRepository) (string, error)
+	Adjust(context.Context, models.UpdateRepository) (string, error)
+	UpdateLevels(context.Context, models.UpdateRepositoryLevels) (string, error)
 	Delete(context.Context, string) error
+	GetLowStock(context.Context, models.StockLevelRequest) (models.LowStockResponse, error)
+	GetReorderSuggestions(context.Context, models.StockLevelRequest) (models.ReorderSuggestionsResponse, error)
+	Reconcile(context.Context, models.ReconcileRequest) (models.ReconcileResponse, error)
 }
 
 type IBasketRepo interface {
@@ -59,9 +96,11 @@ type IBasketRepo interface {
 type IRepositoryTransactionRepo interface {
 	Create(context.Context, models.CreateRepositoryTransaction) (string, error)
 	GetByID(context.Context, models.PrimaryKey) (models.RepositoryTransaction, error)
-	GetList(context.Context, models.GetListRequest) (models.RepositoryTransactionsResponse, error)
+	GetList(context.Context, models.RepositoryTransactionGetListRequest) (models.RepositoryTransactionsResponse, error)
 	Update(context.Context, models.UpdateRepositoryTransaction) (string, error)
 	Delete(context.Context, string) error
+	History(context.Context, models.MovementHistoryRequest) (models.MovementHistory, error)
+	Snapshot(context.Context, models.StockSnapshotRequest) (models.StockSnapshot, error)
 }
 
 type ICategory interface {
@@ -69,7 +108,9 @@ type ICategory interface {
 	GetByID(context.Context, string) (models.Category, error)
 	GetList(context.Context, models.GetListRequest) (models.CategoryResponse, error)
 	Update(context.Context, models.UpdateCategory) (string, error)
-	Delete(context.Context, string) error
+	Delete(ctx context.Context, id, mode string) error
+	Move(ctx context.Context, id, parentID string) error
+	Tree(ctx context.Context, rootID string) ([]models.CategoryTree, error)
 }
 
 type IProducts interface {
@@ -78,6 +119,18 @@ type IProducts interface {
 	GetList(context.Context, models.ProductGetListRequest) (models.ProductResponse, error)
 	Update(context.Context, models.UpdateProduct) (string, error)
 	Delete(context.Context, string) error
+	AddBarcode(context.Context, models.CreateProductBarcode) (string, error)
+	DeleteBarcode(ctx context.Context, productID, barcodeID string) error
+	GetByBarcode(context.Context, string) (models.ProductBarcode, error)
+	AddUnit(context.Context, models.CreateProductUnit) (string, error)
+	DeleteUnit(ctx context.Context, productID, unitID string) error
+	CreateVariant(context.Context, models.CreateProductVariant) (string, error)
+	Import(ctx context.Context, rows []models.ProductImportRow, dryRun bool) (models.ProductImportResponse, error)
+	Export(context.Context) ([]models.ProductExportRow, error)
+	Search(context.Context, models.ProductSearchRequest) (models.ProductSearchResponse, error)
+	AddImage(context.Context, models.CreateProductImage) (string, error)
+	GetImages(ctx context.Context, productID string) ([]models.ProductImage, error)
+	DeleteImage(ctx context.Context, productID, imageID string) (models.ProductImage, error)
 }
 
 type IBranchStorage interface {
@@ -95,6 +148,11 @@ type ISaleStorage interface {
 	Update(context.Context, models.UpdateSale) (string, error)
 	Delete(context.Context, string) error
 	UpdatePrice(context.Context, models.SaleRequest) (string, error)
+	Receipt(context.Context, string) (models.Receipt, error)
+	AddPayment(context.Context, models.CreateSalePayment) (string, error)
+	GetPayments(context.Context, string) (models.SalePayments, error)
+	Checkout(context.Context, string) (models.SaleTax, error)
+	TaxReport(context.Context, models.TaxReportRequest) (models.TaxReport, error)
 }
 
 type ITransactionStorage interface {
@@ -111,6 +169,7 @@ type IIncomeStorage interface {
 	GetList(context.Context, models.IncomeGetListRequest) (models.IncomeResponse, error)
 	Update(context.Context, models.UpdateIncome) (string, error)
 	Delete(context.Context, string) error
+	Finish(context.Context, string) error
 }
 
 type IIncomeProductsStorage interface {
@@ -120,3 +179,85 @@ type IIncomeProductsStorage interface {
 	Update(context.Context, models.UpdateIncomeProduct) (string, error)
 	Delete(context.Context, string) error
 }
+
+type IWriteOffStorage interface {
+	Create(context.Context, models.CreateWriteOff) (string, error)
+	GetByID(context.Context, string) (models.WriteOff, error)
+	GetList(context.Context, models.WriteOffGetListRequest) (models.WriteOffResponse, error)
+	Approve(context.Context, models.ApproveWriteOff) error
+	Reject(context.Context, models.ApproveWriteOff) error
+	Delete(context.Context, string) error
+	Report(context.Context, models.WriteOffReportRequest) (models.WriteOffReport, error)
+}
+
+type IBatchStorage interface {
+	Create(context.Context, models.CreateBatch) (string, error)
+	GetList(context.Context, models.BatchGetListRequest) (models.BatchResponse, error)
+	GetExpiring(context.Context, models.ExpiringBatchesRequest) (models.ExpiringBatchesResponse, error)
+}
+
+type ISupplierStorage interface {
+	Create(context.Context, models.CreateSupplier) (string, error)
+	GetByID(context.Context, string) (models.Supplier, error)
+	GetList(context.Context, models.GetListRequest) (models.SupplierResponse, error)
+	Update(context.Context, models.UpdateSupplier) (string, error)
+	Delete(context.Context, string) error
+	CreatePayment(context.Context, models.CreateSupplierPayment) (string, error)
+	GetPaymentList(context.Context, models.SupplierPaymentGetListRequest) (models.SupplierPaymentResponse, error)
+}
+
+type IPurchaseOrderStorage interface {
+	Create(context.Context, models.CreatePurchaseOrder) (string, error)
+	GetByID(context.Context, string) (models.PurchaseOrder, error)
+	GetList(context.Context, models.PurchaseOrderGetListRequest) (models.PurchaseOrderResponse, error)
+	Cancel(context.Context, string) error
+	Receive(context.Context, models.ReceivePurchaseOrder) (string, error)
+	GetDiscrepancies(context.Context, string) (models.PurchaseOrderDiscrepancies, error)
+}
+
+type IBarcodeFormatStorage interface {
+	Create(context.Context, models.CreateBarcodeFormat) (string, error)
+	GetByID(context.Context, string) (models.BarcodeFormat, error)
+	GetList(context.Context) (models.BarcodeFormatsResponse, error)
+	Delete(context.Context, string) error
+}
+
+type IProductPriceStorage interface {
+	Create(context.Context, models.CreateProductPrice) (string, error)
+	GetList(context.Context, models.ProductPriceGetListRequest) (models.ProductPricesResponse, error)
+	Delete(ctx context.Context, productID, id string) error
+	GetEffective(context.Context, models.EffectivePriceRequest) (models.EffectivePrice, error)
+}
+
+type IExchangeRateStorage interface {
+	Create(context.Context, models.CreateExchangeRate) (string, error)
+	GetList(context.Context, models.ExchangeRateGetListRequest) (models.ExchangeRatesResponse, error)
+	Delete(context.Context, string) error
+	GetEffective(context.Context, models.EffectiveRateRequest) (models.ExchangeRate, error)
+}
+
+type ITaxRateStorage interface {
+	Create(context.Context, models.CreateTaxRate) (string, error)
+	GetByID(context.Context, string) (models.TaxRate, error)
+	GetList(context.Context, models.GetListRequest) (models.TaxRatesResponse, error)
+	Update(context.Context, models.UpdateTaxRate) (string, error)
+	Delete(context.Context, string) error
+	SetProductTaxRate(context.Context, models.SetTaxRate) error
+	SetCategoryTaxRate(context.Context, models.SetTaxRate) error
+	GetProductTaxRate(context.Context, string) (models.ProductTaxRate, error)
+}
+
+type IRefundStorage interface {
+	Create(context.Context, models.CreateRefund) (string, error)
+	GetByID(context.Context, string) (models.Refund, error)
+	GetList(context.Context, models.RefundGetListRequest) (models.RefundsResponse, error)
+}
+
+type IFiscalStorage interface {
+	Claim(context.Context, models.ClaimFiscalEntries) ([]models.FiscalEntry, error)
+	Done(context.Context, models.FiscalResult) error
+	Fail(context.Context, models.FiscalFailure) error
+	Postpone(context.Context, models.FiscalFailure) error
+	Retry(context.Context, string) error
+	GetList(context.Context, models.FiscalEntryGetListRequest) (models.FiscalEntriesResponse, error)
+}
@@ -0,0 +1,622 @@
+// Package storagetest holds cases every storage.IStorage must pass the same way. The in-memory store runs
+// them in its tests, the postgres store runs them when a test database is given, so the two can not drift apart.
+package storagetest
+
+import (
+	"context"
+	"errors"
+	"sell/api/models"
+	"sell/pkg/money"
+	"sell/storage"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Run runs every case against store. Cases create their own branches, products and staff with fresh names
+// and barcodes, so the store may hold other data and the cases may run again on the same database.
+func Run(t *testing.T, store storage.IStorage) {
+	t.Run("RepositoryPerBranch", func(t *testing.T) { testRepositoryPerBranch(t, store) })
+	t.Run("BarcodeOfDeletedProduct", func(t *testing.T) { testBarcodeOfDeletedProduct(t, store) })
+	t.Run("BarcodeOfDeletedCategory", func(t *testing.T) { testBarcodeOfDeletedCategory(t, store) })
+	t.Run("UpdateProductUnit", func(t *testing.T) { testUpdateProductUnit(t, store) })
+	t.Run("CategoryCycle", func(t *testing.T) { testCategoryCycle(t, store) })
+	t.Run("Checkout", func(t *testing.T) { testCheckout(t, store) })
+	t.Run("CheckoutShortage", func(t *testing.T) { testCheckoutShortage(t, store) })
+	t.Run("IncomeListFilter", func(t *testing.T) { testIncomeListFilter(t, store) })
+	t.Run("ReverseMovement", func(t *testing.T) { testReverseMovement(t, store) })
+	t.Run("BranchPriceOverride", func(t *testing.T) { testBranchPriceOverride(t, store) })
+}
+
+// fixture is a branch with a product priced 12 000 in a category of its own.
+type fixture struct {
+	branchID   string
+	categoryID string
+	productID  string
+}
+
+func newFixture(t *testing.T, store storage.IStorage) fixture {
+	t.Helper()
+	ctx := context.Background()
+
+	branchID, err := store.Branch().Create(ctx, models.CreateBranch{Name: "Chilonzor " + fresh(), Address: "Bunyodkor 1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	categoryID, err := store.Category().Create(ctx, models.CreateCategory{Name: "Dairy " + fresh()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return fixture{
+		branchID:   branchID,
+		categoryID: categoryID,
+		productID:  createProduct(t, store, categoryID, "piece", 0, fresh()),
+	}
+}
+
+func createProduct(t *testing.T, store storage.IStorage, categoryID, unit string, precision int, barcode string) string {
+	t.Helper()
+
+	id, err := store.Product().Create(context.Background(), models.CreateProduct{
+		Name:       "Milk " + fresh(),
+		Price:      12000 * money.Unit,
+		Barcode:    barcode,
+		Unit:       unit,
+		Precision:  &precision,
+		CategoryID: categoryID,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return id
+}
+
+func createRepository(t *testing.T, store storage.IStorage, branchID, productID string, count float64) string {
+	t.Helper()
+
+	id, err := store.Repository().Create(context.Background(), models.CreateRepository{
+		ProductID: productID,
+		BranchID:  branchID,
+		Count:     count,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return id
+}
+
+func repositoryCount(t *testing.T, store storage.IStorage, id string) float64 {
+	t.Helper()
+
+	repository, err := store.Repository().GetByID(context.Background(), models.PrimaryKey{ID: id})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return repository.Count
+}
+
+// fresh returns a text no other row has, short enough for logins.
+func fresh() string {
+	return uuid.NewString()[:13]
+}
+
+func testRepositoryPerBranch(t *testing.T, store storage.IStorage) {
+	ctx := context.Background()
+	shop := newFixture(t, store)
+
+	id := createRepository(t, store, shop.branchID, shop.productID, 5)
+	if _, err := store.Repository().Create(ctx, models.CreateRepository{
+		ProductID: shop.productID,
+		BranchID:  shop.branchID,
+		Count:     3,
+	}); !errors.Is(err, storage.ErrRepositoryExists) {
+		t.Fatalf("second repository of the product in the branch: %v, want %v", err, storage.ErrRepositoryExists)
+	}
+
+	if err := store.Repository().Delete(ctx, id); err != nil {
+		t.Fatal(err)
+	}
+
+	today := time.Now()
+	history, err := store.RTransaction().History(ctx, models.MovementHistoryRequest{
+		ProductID: shop.productID,
+		BranchID:  shop.branchID,
+		FromDate:  today.AddDate(0, 0, -1).Format("2006-01-02"),
+		ToDate:    today.AddDate(0, 0, 1).Format("2006-01-02"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history.Movements) != 2 || history.ClosingBalance != 0 {
+		t.Fatalf("ledger has %d movements closing at %g after a deleted repository, want 2 closing at 0",
+			len(history.Movements), history.ClosingBalance)
+	}
+
+	// the deleted repository leaves room for a new one
+	createRepository(t, store, shop.branchID, shop.productID, 3)
+}
+
+func testBarcodeOfDeletedProduct(t *testing.T, store storage.IStorage) {
+	ctx := context.Background()
+	shop := newFixture(t, store)
+
+	barcode := fresh()
+	first := createProduct(t, store, shop.categoryID, "piece", 0, barcode)
+
+	if _, err := store.Product().Create(ctx, models.CreateProduct{
+		Name:       "Kefir " + fresh(),
+		Price:      9000 * money.Unit,
+		Barcode:    barcode,
+		Unit:       "piece",
+		CategoryID: shop.categoryID,
+	}); !errors.Is(err, storage.ErrBarcodeExists) {
+		t.Fatalf("second product with barcode %s: %v, want %v", barcode, err, storage.ErrBarcodeExists)
+	}
+
+	if _, err := store.Product().AddBarcode(ctx, models.CreateProductBarcode{
+		ProductID: shop.productID,
+		Barcode:   barcode,
+		Quantity:  1,
+	}); !errors.Is(err, storage.ErrBarcodeExists) {
+		t.Fatalf("barcode %s added to another product: %v, want %v", barcode, err, storage.ErrBarcodeExists)
+	}
+
+	if err := store.Product().Delete(ctx, first); err != nil {
+		t.Fatal(err)
+	}
+
+	// the barcode of a deleted product can be given to another one
+	createProduct(t, store, shop.categoryID, "piece", 0, barcode)
+}
+
+func testBarcodeOfDeletedCategory(t *testing.T, store storage.IStorage) {
+	ctx := context.Background()
+	shop := newFixture(t, store)
+
+	childID, err := store.Category().Create(ctx, models.CreateCategory{Name: "Yogurt " + fresh(), ParentID: shop.categoryID})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	barcode, extra := fresh(), fresh()
+	productID := createProduct(t, store, childID, "piece", 0, barcode)
+	if _, err := store.Product().AddBarcode(ctx, models.CreateProductBarcode{
+		ProductID: productID,
+		Barcode:   extra,
+		Quantity:  6,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.Category().Delete(ctx, shop.categoryID, "cascade"); err != nil {
+		t.Fatal(err)
+	}
+
+	// the barcodes of the products deleted with the subtree can be given to other products
+	other := newFixture(t, store)
+	createProduct(t, store, other.categoryID, "piece", 0, barcode)
+	createProduct(t, store, other.categoryID, "piece", 0, extra)
+}
+
+func testUpdateProductUnit(t *testing.T, store storage.IStorage) {
+	ctx := context.Background()
+	shop := newFixture(t, store)
+
+	productID := createProduct(t, store, shop.categoryID, "kg", 3, fresh())
+	createRepository(t, store, shop.branchID, productID, 1.25)
+
+	update := models.UpdateProduct{
+		ID:         productID,
+		Name:       "Gouda " + fresh(),
+		Price:      90000 * money.Unit,
+		CategoryID: shop.categoryID,
+	}
+	if _, err := store.Product().Update(ctx, update); err != nil {
+		t.Fatal(err)
+	}
+
+	product, err := store.Product().GetByID(ctx, productID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if product.Unit != "kg" || product.Precision != 3 {
+		t.Fatalf("product is sold by %s with precision %d after an update without them, want kg with precision 3",
+			product.Unit, product.Precision)
+	}
+
+	update.Unit = "piece"
+	if _, err := store.Product().Update(ctx, update); !errors.Is(err, storage.ErrFractionalStock) {
+		t.Fatalf("unit changed under fractional stock: %v, want %v", err, storage.ErrFractionalStock)
+	}
+
+	precision := 1
+	update.Unit, update.Precision = "", &precision
+	if _, err := store.Product().Update(ctx, update); !errors.Is(err, storage.ErrFractionalStock) {
+		t.Fatalf("precision 1 for a stock of 1.25: %v, want %v", err, storage.ErrFractionalStock)
+	}
+
+	precision = 2
+	if _, err := store.Product().Update(ctx, update); err != nil {
+		t.Fatalf("precision 2 for a stock of 1.25: %v", err)
+	}
+}
+
+func testCategoryCycle(t *testing.T, store storage.IStorage) {
+	ctx := context.Background()
+
+	parentID, err := store.Category().Create(ctx, models.CreateCategory{Name: "Food " + fresh()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	childID, err := store.Category().Create(ctx, models.CreateCategory{Name: "Dairy " + fresh(), ParentID: parentID})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.Category().Move(ctx, parentID, childID); !errors.Is(err, storage.ErrCategoryCycle) {
+		t.Fatalf("category moved under its subcategory: %v, want %v", err, storage.ErrCategoryCycle)
+	}
+	if err := store.Category().Move(ctx, parentID, parentID); !errors.Is(err, storage.ErrCategoryCycle) {
+		t.Fatalf("category moved under itself: %v, want %v", err, storage.ErrCategoryCycle)
+	}
+}
+
+// openSale starts a cash sale of quantity of the product of the fixture by a cashier with a fixed tariff of 1 000
+// and a shop assistant with a tariff of 10 percent.
+func openSale(t *testing.T, store storage.IStorage, shop fixture, quantity float64) (sale models.Sale, cashierID, shopAssistantID string) {
+	t.Helper()
+	ctx := context.Background()
+
+	staff := func(tariff models.CreateStaffTariff, staffType string) string {
+		tariffID, err := store.StaffTariff().Create(ctx, tariff)
+		if err != nil {
+			t.Fatal(err)
+		}
+		id, err := store.Staff().Create(ctx, models.CreateStaff{
+			BranchID:  shop.branchID,
+			TariffID:  tariffID,
+			StaffType: staffType,
+			Name:      "Aziz",
+			BirthDate: "1995-04-12",
+			Login:     fresh(),
+			Password:  "secret123",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return id
+	}
+
+	cashierID = staff(models.CreateStaffTariff{
+		Name:          "cashier " + fresh(),
+		TariffType:    "fixed",
+		AmountForCash: 1000 * money.Unit,
+		AmountForCard: 500 * money.Unit,
+	}, "cashier")
+	shopAssistantID = staff(models.CreateStaffTariff{
+		Name:          "assistant " + fresh(),
+		TariffType:    "percent",
+		AmountForCash: 10 * money.Unit,
+		AmountForCard: 5 * money.Unit,
+	}, "shop_assistant")
+
+	id, err := store.Sale().Create(ctx, models.CreateSale{
+		BranchID:        shop.branchID,
+		ShopAssistantID: shopAssistantID,
+		CashierID:       cashierID,
+		PaymentType:     "cash",
+		Currency:        "UZS",
+		TaxIncluded:     true,
+		Status:          "in_process",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	price, err := (12000 * money.Unit).Mul(quantity)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := store.Basket().Create(ctx, models.CreateBasket{
+		SaleID:    id,
+		ProductID: shop.productID,
+		Quantity:  quantity,
+		Price:     price,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	sale, err = store.Sale().GetByID(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sale, cashierID, shopAssistantID
+}
+
+func staffBalance(t *testing.T, store storage.IStorage, id string) money.Money {
+	t.Helper()
+
+	staff, err := store.Staff().StaffByID(context.Background(), models.PrimaryKey{ID: id})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return staff.Balance
+}
+
+func testCheckout(t *testing.T, store storage.IStorage) {
+	ctx := context.Background()
+	shop := newFixture(t, store)
+	repositoryID := createRepository(t, store, shop.branchID, shop.productID, 10)
+
+	sale, cashierID, shopAssistantID := openSale(t, store, shop, 5)
+
+	tax, err := store.Sale().Checkout(ctx, sale.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tax.Total != 60000*money.Unit {
+		t.Fatalf("sale total is %s, want 60000.00", tax.Total)
+	}
+
+	if _, err := store.Sale().Checkout(ctx, sale.ID); !errors.Is(err, storage.ErrSaleNotOpen) {
+		t.Fatalf("sale checked out twice: %v, want %v", err, storage.ErrSaleNotOpen)
+	}
+
+	sale, err = store.Sale().GetByID(ctx, sale.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sale.Status != "success" || sale.Price != 60000*money.Unit {
+		t.Fatalf("sale is %s for %s, want success for 60000.00", sale.Status, sale.Price)
+	}
+
+	if count := repositoryCount(t, store, repositoryID); count != 5 {
+		t.Fatalf("repository has %g after the sale, want 5", count)
+	}
+
+	// the cashier and the shop assistant each get 1 000 plus 10 percent of the sale
+	for _, id := range []string{cashierID, shopAssistantID} {
+		if balance := staffBalance(t, store, id); balance != 7000*money.Unit {
+			t.Fatalf("staff balance is %s after the sale, want 7000.00", balance)
+		}
+	}
+
+	entries, err := store.Fiscal().Claim(ctx, models.ClaimFiscalEntries{
+		DocumentType: "sale",
+		DocumentID:   sale.ID,
+		Limit:        1,
+		Lease:        time.Minute,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("fiscal outbox has %d entries of the sale, want 1", len(entries))
+	}
+
+	// an entry which could not be sent yet is due again without using up an attempt
+	if err := store.Fiscal().Postpone(ctx, models.FiscalFailure{EntryID: entries[0].ID, Error: "waiting"}); err != nil {
+		t.Fatal(err)
+	}
+	entries, err = store.Fiscal().Claim(ctx, models.ClaimFiscalEntries{ID: entries[0].ID, Limit: 1, Lease: time.Minute})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Attempts != 1 {
+		t.Fatalf("postponed entry claimed again is %+v, want it due with 1 attempt", entries)
+	}
+}
+
+func testCheckoutShortage(t *testing.T, store storage.IStorage) {
+	ctx := context.Background()
+	shop := newFixture(t, store)
+	repositoryID := createRepository(t, store, shop.branchID, shop.productID, 3)
+
+	sale, cashierID, _ := openSale(t, store, shop, 5)
+
+	if _, err := store.Sale().Checkout(ctx, sale.ID); !errors.Is(err, storage.ErrNotEnoughProduct) {
+		t.Fatalf("sale of 5 from a repository of 3: %v, want %v", err, storage.ErrNotEnoughProduct)
+	}
+
+	sale, err := store.Sale().GetByID(ctx, sale.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sale.Status != "in_process" {
+		t.Fatalf("sale short of stock is %s, want in_process", sale.Status)
+	}
+	if count := repositoryCount(t, store, repositoryID); count != 3 {
+		t.Fatalf("repository has %g after a sale short of stock, want 3", count)
+	}
+	if balance := staffBalance(t, store, cashierID); balance != 0 {
+		t.Fatalf("cashier balance is %s after a sale short of stock, want 0", balance)
+	}
+
+	entries, err := store.Fiscal().Claim(ctx, models.ClaimFiscalEntries{
+		DocumentType: "sale",
+		DocumentID:   sale.ID,
+		Limit:        1,
+		Lease:        time.Minute,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Fatalf("fiscal outbox has %d entries of a sale short of stock, want 0", len(entries))
+	}
+}
+
+func testIncomeListFilter(t *testing.T, store storage.IStorage) {
+	ctx := context.Background()
+	shop := newFixture(t, store)
+
+	for i := 0; i < 2; i++ {
+		if _, err := store.Income().Create(ctx, models.CreateIncome{
+			BranchID:     shop.branchID,
+			Currency:     "UZS",
+			ExchangeRate: money.OneRate,
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		status string
+		want   int
+	}{
+		{"in_process", 2},
+		{"finished", 0},
+		{"in_process' or '1'='1", 0}, // the filter is a value, not a part of the query
+	}
+
+	for _, test := range tests {
+		incomes, err := store.Income().GetList(ctx, models.IncomeGetListRequest{
+			Page:     1,
+			Limit:    10,
+			BranchID: shop.branchID,
+			Status:   test.status,
+		})
+		if err != nil {
+			t.Fatalf("incomes of status %q: %v", test.status, err)
+		}
+		if incomes.Count != test.want || len(incomes.Incomes) != test.want {
+			t.Errorf("%d incomes of status %q, %d on the page, want %d", incomes.Count, test.status, len(incomes.Incomes), test.want)
+		}
+	}
+}
+
+func testReverseMovement(t *testing.T, store storage.IStorage) {
+	ctx := context.Background()
+	shop := newFixture(t, store)
+
+	repositoryID := createRepository(t, store, shop.branchID, shop.productID, 5)
+	id, err := store.RTransaction().Create(ctx, models.CreateRepositoryTransaction{
+		BranchID:                  shop.branchID,
+		ProductID:                 shop.productID,
+		RepositoryTransactionType: "plus",
+		Reason:                    "adjustment",
+		SourceType:                "adjustment",
+		Quantity:                  2,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the correction reverses the 2 and posts 3 instead
+	corrected, err := store.RTransaction().Update(ctx, models.UpdateRepositoryTransaction{
+		ID:                        id,
+		BranchID:                  shop.branchID,
+		ProductID:                 shop.productID,
+		RepositoryTransactionType: "plus",
+		Reason:                    "adjustment",
+		SourceType:                "adjustment",
+		Quantity:                  3,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.RTransaction().GetByID(ctx, models.PrimaryKey{ID: id}); err != nil {
+		t.Fatalf("corrected movement is gone from the ledger: %v", err)
+	}
+	if err := store.RTransaction().Delete(ctx, id); !errors.Is(err, storage.ErrMovementReversed) {
+		t.Fatalf("second reversal of a movement: %v, want %v", err, storage.ErrMovementReversed)
+	}
+
+	reconcile, err := store.Repository().Reconcile(ctx, models.ReconcileRequest{BranchID: shop.branchID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reconcile.Count != 1 || reconcile.Discrepancies[0].ExpectedCount != 8 || reconcile.Fixed {
+		t.Fatalf("reconcile after the correction: %+v, want one discrepancy expecting 8 and nothing fixed", reconcile)
+	}
+
+	// the ledger is trusted and the count follows it
+	reconcile, err = store.Repository().Reconcile(ctx, models.ReconcileRequest{BranchID: shop.branchID, Mode: "counts"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reconcile.Fixed || reconcile.Count != 1 {
+		t.Fatalf("reconcile of counts: %+v, want one discrepancy fixed", reconcile)
+	}
+	if count := repositoryCount(t, store, repositoryID); count != 8 {
+		t.Fatalf("repository count is %g after the counts were set from the ledger, want 8", count)
+	}
+
+	// cancelling the correction takes the ledger back to the first 5, the counts trusted this time
+	if err := store.RTransaction().Delete(ctx, corrected); err != nil {
+		t.Fatal(err)
+	}
+	reconcile, err = store.Repository().Reconcile(ctx, models.ReconcileRequest{BranchID: shop.branchID, Mode: "ledger"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reconcile.Fixed || reconcile.Count != 1 || reconcile.Discrepancies[0].Difference != 3 {
+		t.Fatalf("reconcile of the ledger: %+v, want a difference of 3 fixed", reconcile)
+	}
+	if count := repositoryCount(t, store, repositoryID); count != 8 {
+		t.Fatalf("repository count is %g after the ledger was fixed, want 8", count)
+	}
+
+	reconcile, err = store.Repository().Reconcile(ctx, models.ReconcileRequest{BranchID: shop.branchID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reconcile.Count != 0 {
+		t.Fatalf("%d discrepancies left after reconcile, want none", reconcile.Count)
+	}
+}
+
+func testBranchPriceOverride(t *testing.T, store storage.IStorage) {
+	ctx := context.Background()
+	shop := newFixture(t, store)
+	now := time.Now()
+	// the prices are scheduled after the price the product was created with, the storage takes
+	// the start like the handler gives it, a local timestamp
+	startsAt := func(hours int) string {
+		return now.Add(time.Duration(hours) * time.Hour).Format("2006-01-02 15:04:05.999999")
+	}
+
+	prices := []models.CreateProductPrice{
+		{Price: 12500 * money.Unit, StartsAt: startsAt(1)},
+		{BranchID: shop.branchID, Price: 11000 * money.Unit, StartsAt: startsAt(2)},
+		// a branch price and a price for all branches starting at once, the branch wins
+		{BranchID: shop.branchID, Price: 11500 * money.Unit, StartsAt: startsAt(3)},
+		{Price: 13500 * money.Unit, StartsAt: startsAt(3)},
+		// the later price for all branches ends the override
+		{Price: 14000 * money.Unit, StartsAt: startsAt(4)},
+	}
+	for _, price := range prices {
+		price.ProductID = shop.productID
+		if _, err := store.ProductPrice().Create(ctx, price); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		at   time.Time
+		want money.Money
+	}{
+		{now.Add(90 * time.Minute), 12500 * money.Unit},
+		{now.Add(150 * time.Minute), 11000 * money.Unit},
+		{now.Add(210 * time.Minute), 11500 * money.Unit},
+		{now.Add(5 * time.Hour), 14000 * money.Unit},
+	}
+
+	for _, test := range tests {
+		price, err := store.ProductPrice().GetEffective(ctx, models.EffectivePriceRequest{
+			ProductID: shop.productID,
+			BranchID:  shop.branchID,
+			At:        test.at,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if price.Price != test.want {
+			t.Errorf("price in the branch at %s is %s, want %s", test.at.Format(time.RFC3339), price.Price, test.want)
+		}
+	}
+}